@@ -0,0 +1,284 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/grpccommon"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtcdc"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+	"vitess.io/vitess/go/vt/vttls"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	server             string
+	name               string
+	topicPrefix        string
+	keyspace           string
+	shards             []string
+	tables             []string
+	tabletType         = "replica"
+	initialSnapshot    = true
+	format             = "json"
+	sink               = "stdout"
+	fileSinkDir        string
+	kafkaBrokers       []string
+	kafkaTimeout       = 30 * time.Second
+	kafkaRetries       = 5
+	kafkaTLS           bool
+	kafkaTLSCA         string
+	kafkaTLSCert       string
+	kafkaTLSKey        string
+	kafkaTLSServerName string
+	kafkaSASLMechanism string
+	kafkaSASLUser      string
+	kafkaPasswordFile  string
+	tombstonesOnDelete = true
+	checkpointInterval = time.Second
+	heartbeatInterval  = 10 * time.Second
+	resetCheckpoint    bool
+	retryDelay         = 5 * time.Second
+
+	Main = &cobra.Command{
+		Use:   "vtcdc",
+		Short: "vtcdc streams the changes of a keyspace from vtgate as Debezium compatible change events.",
+		Long: `vtcdc streams the changes of a keyspace from vtgate as Debezium compatible change events.
+
+vtcdc consumes a VStream and writes one change event per changed row, in JSON
+or Avro, to a sink: stdout, NDJSON files or a Kafka cluster. Each table has its
+own topic, <topic-prefix>.<keyspace>.<table>, and the key of each event holds
+the primary key of the row.
+
+Unless --initial-snapshot=false, the existing rows are copied first and emitted
+as read ("r") events. The position of the connector is checkpointed in the
+global topo under its --name, and a restarted connector resumes from there.
+Events are delivered at least once: after a restart, the events written since
+the last checkpoint are written again.`,
+		Example: `vtcdc --server vtgate:15991 --topo_implementation etcd2 --topo_global_server_address etcd:2379 --topo_global_root /vitess/global \
+	--name commerce-cdc --keyspace commerce --sink kafka --kafka-brokers kafka:9092
+
+vtcdc --server vtgate:15991 --name commerce-cdc --keyspace commerce --tables customer,corder --sink file --file-sink-dir /tmp/cdc`,
+		Args:    cobra.NoArgs,
+		Version: servenv.AppVersion.String(),
+		RunE:    run,
+	}
+)
+
+func InitializeFlags() {
+	servenv.MoveFlagsToCobraCommand(Main)
+
+	Main.Flags().StringVar(&server, "server", server, "vtgate server to connect to")
+	Main.Flags().StringVar(&name, "name", name, "name of the connector, which identifies its checkpoint in the topo")
+	Main.Flags().StringVar(&topicPrefix, "topic-prefix", topicPrefix, "prefix of the topics, which are <topic-prefix>.<keyspace>.<table>. Defaults to the name of the connector.")
+	Main.Flags().StringVar(&keyspace, "keyspace", keyspace, "keyspace to stream the changes of")
+	Main.Flags().StringSliceVar(&shards, "shards", shards, "shards to stream the changes of. Defaults to all the shards of the keyspace.")
+	Main.Flags().StringSliceVar(&tables, "tables", tables, "tables to stream the changes of, as names or /regular expressions/. Defaults to all tables.")
+	Main.Flags().StringVar(&tabletType, "tablet-type", tabletType, "type of the tablets to stream from")
+	Main.Flags().BoolVar(&initialSnapshot, "initial-snapshot", initialSnapshot, "copy the existing rows before streaming changes, when starting without a checkpoint")
+	Main.Flags().StringVar(&format, "format", format, "format of the change events: json or avro")
+	Main.Flags().StringVar(&sink, "sink", sink, "where to write the change events: stdout, file or kafka")
+	Main.Flags().StringVar(&fileSinkDir, "file-sink-dir", fileSinkDir, "directory of the NDJSON files of the file sink, one per topic")
+	Main.Flags().StringSliceVar(&kafkaBrokers, "kafka-brokers", kafkaBrokers, "bootstrap brokers of the kafka sink, as host:port")
+	Main.Flags().DurationVar(&kafkaTimeout, "kafka-timeout", kafkaTimeout, "timeout of the delivery of each record to kafka, retries included")
+	Main.Flags().IntVar(&kafkaRetries, "kafka-retries", kafkaRetries, "number of times a write to kafka is retried on errors such as a leader change")
+	Main.Flags().BoolVar(&kafkaTLS, "kafka-tls", kafkaTLS, "connect to the kafka brokers with TLS")
+	Main.Flags().StringVar(&kafkaTLSCA, "kafka-tls-ca", kafkaTLSCA, "CA to verify the certificates of the kafka brokers with, instead of the system CAs")
+	Main.Flags().StringVar(&kafkaTLSCert, "kafka-tls-cert", kafkaTLSCert, "client certificate to authenticate to the kafka brokers with")
+	Main.Flags().StringVar(&kafkaTLSKey, "kafka-tls-key", kafkaTLSKey, "key of the client certificate to authenticate to the kafka brokers with")
+	Main.Flags().StringVar(&kafkaTLSServerName, "kafka-tls-server-name", kafkaTLSServerName, "server name to verify the certificates of the kafka brokers against, instead of their host names")
+	Main.Flags().StringVar(&kafkaSASLMechanism, "kafka-sasl-mechanism", kafkaSASLMechanism, "SASL mechanism to authenticate to the kafka brokers with: PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512")
+	Main.Flags().StringVar(&kafkaSASLUser, "kafka-sasl-user", kafkaSASLUser, "SASL user to authenticate to the kafka brokers as")
+	Main.Flags().StringVar(&kafkaPasswordFile, "kafka-sasl-password-file", kafkaPasswordFile, "file holding the password of the SASL user")
+	Main.Flags().BoolVar(&tombstonesOnDelete, "tombstones-on-delete", tombstonesOnDelete, "follow each delete event with a tombstone, so that compacted topics drop the row")
+	Main.Flags().DurationVar(&checkpointInterval, "checkpoint-interval", checkpointInterval, "minimum time between checkpoints of the position")
+	Main.Flags().DurationVar(&heartbeatInterval, "heartbeat-interval", heartbeatInterval, "interval of the VStream heartbeats, which let an idle connector checkpoint")
+	Main.Flags().BoolVar(&resetCheckpoint, "reset-checkpoint", resetCheckpoint, "delete the checkpoint of the connector and start over")
+	Main.Flags().DurationVar(&retryDelay, "retry-delay", retryDelay, "time to wait before restarting a failed VStream")
+
+	acl.RegisterFlags(Main.Flags())
+	grpccommon.RegisterFlags(Main.Flags())
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	defer logutil.Flush()
+
+	if name == "" {
+		return errors.New("--name is required")
+	}
+	if keyspace == "" {
+		return errors.New("--keyspace is required")
+	}
+	if topicPrefix == "" {
+		topicPrefix = name
+	}
+	tt, err := topoproto.ParseTabletType(tabletType)
+	if err != nil {
+		return err
+	}
+	encoder, err := vtcdc.NewEncoder(format, topicPrefix)
+	if err != nil {
+		return err
+	}
+	out, err := newSink(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	ts := topo.Open()
+	defer ts.Close()
+	checkpoints := vtcdc.NewCheckpointStore(ts, name)
+	if resetCheckpoint {
+		if err := checkpoints.Delete(ctx); err != nil {
+			return fmt.Errorf("cannot reset the checkpoint: %w", err)
+		}
+	}
+
+	conn, err := vtgateconn.Dial(ctx, server)
+	if err != nil {
+		return fmt.Errorf("cannot connect to vtgate %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	connector := vtcdc.NewConnector(vtcdc.Config{
+		Name:               name,
+		TopicPrefix:        topicPrefix,
+		Version:            servenv.AppVersion.Version(),
+		TabletType:         tt,
+		Filter:             newFilter(),
+		Flags:              &vtgatepb.VStreamFlags{HeartbeatInterval: uint32(heartbeatInterval.Seconds())},
+		StartPosition:      startPosition(),
+		TombstonesOnDelete: tombstonesOnDelete,
+		CheckpointInterval: checkpointInterval,
+	}, conn, encoder, out, checkpoints)
+	defer func() {
+		closeCtx, closeCancel := context.WithTimeout(context.Background(), kafkaTimeout)
+		defer closeCancel()
+		if err := connector.Close(closeCtx); err != nil {
+			log.Errorf("Error closing connector %s: %v", name, err)
+		}
+	}()
+
+	for {
+		err := connector.Run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			log.Infof("VStream of connector %s ended at %v", name, connector.Position())
+			return nil
+		}
+		log.Errorf("VStream of connector %s failed, restarting in %v: %v", name, retryDelay, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+func newSink(cmd *cobra.Command) (vtcdc.Sink, error) {
+	switch sink {
+	case "stdout":
+		return vtcdc.NewWriterSink(cmd.OutOrStdout()), nil
+	case "file":
+		if fileSinkDir == "" {
+			return nil, errors.New("--file-sink-dir is required with the file sink")
+		}
+		return vtcdc.NewFileSink(fileSinkDir)
+	case "kafka":
+		return newKafkaSink()
+	default:
+		return nil, fmt.Errorf("unknown sink %q, expected stdout, file or kafka", sink)
+	}
+}
+
+func newKafkaSink() (vtcdc.Sink, error) {
+	cfg := vtcdc.KafkaConfig{
+		Brokers:       kafkaBrokers,
+		ClientID:      name,
+		Timeout:       kafkaTimeout,
+		Retries:       kafkaRetries,
+		SASLMechanism: kafkaSASLMechanism,
+		SASLUser:      kafkaSASLUser,
+	}
+	if kafkaTLS {
+		tlsConfig, err := vttls.ClientConfig(vttls.VerifyIdentity, kafkaTLSCert, kafkaTLSKey, kafkaTLSCA, "", kafkaTLSServerName, tls.VersionTLS12)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the TLS configuration of the kafka sink: %w", err)
+		}
+		cfg.TLS = tlsConfig
+	}
+	if kafkaPasswordFile != "" {
+		password, err := os.ReadFile(kafkaPasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the SASL password of the kafka sink: %w", err)
+		}
+		cfg.SASLPassword = strings.TrimSpace(string(password))
+	}
+	return vtcdc.NewKafkaSink(cfg)
+}
+
+func newFilter() *binlogdatapb.Filter {
+	filter := &binlogdatapb.Filter{}
+	if len(tables) == 0 {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: "/.*/"})
+	}
+	for _, table := range tables {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: table})
+	}
+	return filter
+}
+
+// startPosition returns the position to start at without a checkpoint: an
+// empty position copies the tables first, "current" skips the copy.
+func startPosition() *binlogdatapb.VGtid {
+	gtid := "current"
+	if initialSnapshot {
+		gtid = ""
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if len(shards) == 0 {
+		vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{Keyspace: keyspace, Gtid: gtid})
+	}
+	for _, shard := range shards {
+		vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{Keyspace: keyspace, Shard: shard, Gtid: gtid})
+	}
+	return vgtid
+}
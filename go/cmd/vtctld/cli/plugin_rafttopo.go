@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+// This plugin imports rafttopo to register the raft implementation of TopoServer,
+// and runs a member of the raft topo cluster when --topo-raft-node-id is set.
+
+import (
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/rafttopo"
+)
+
+func init() {
+	// The member is started before the topo server is opened, so that
+	// vtctld can use it.
+	servenv.OnInit(func() {
+		node, err := rafttopo.StartNodeFromFlags()
+		if err != nil {
+			log.Exitf("failed to start the raft topo member: %v", err)
+		}
+		if node != nil {
+			servenv.OnClose(node.Close)
+		}
+	})
+}
@@ -60,6 +60,19 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetBackups,
 	}
+	// RecoverTables makes a RecoverTables gRPC call to a vtctld.
+	RecoverTables = &cobra.Command{
+		Use:   "RecoverTables --keyspace <keyspace> --scratch-keyspace <keyspace> --tables <table>,... {--restore-to-pos <pos>|--restore-to-timestamp <timestamp>} [--allowed-backup-engines=enginename,] [--dry-run] <tablet_alias>",
+		Short: "Runs a point in time recovery of the given tables of a keyspace on a tablet of a SNAPSHOT keyspace, which then serves them as RDONLY.",
+		Long: `Runs a point in time recovery of the given tables of a keyspace on a tablet of a SNAPSHOT keyspace, which then serves them as RDONLY.
+
+The tablet is restored from a full backup of the base keyspace, after which only the changes to the given tables
+are applied from the incremental backups, up to the given position or timestamp. The scratch keyspace must have been
+created with --type=SNAPSHOT and --base-keyspace set to the keyspace of the tables.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRecoverTables,
+	}
 	// RemoveBackup makes a RemoveBackup gRPC call to a vtctld.
 	RemoveBackup = &cobra.Command{
 		Use:                   "RemoveBackup <keyspace/shard> <backup name>",
@@ -228,6 +241,63 @@ func commandRemoveBackup(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+var recoverTablesOptions = struct {
+	Keyspace             string
+	ScratchKeyspace      string
+	Tables               []string
+	AllowedBackupEngines []string
+	RestoreToPos         string
+	RestoreToTimestamp   string
+	DryRun               bool
+}{}
+
+func commandRecoverTables(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if recoverTablesOptions.RestoreToPos != "" && recoverTablesOptions.RestoreToTimestamp != "" {
+		return fmt.Errorf("--restore-to-pos and --restore-to-timestamp are mutually exclusive")
+	}
+
+	var restoreToTimestamp time.Time
+	if recoverTablesOptions.RestoreToTimestamp != "" {
+		restoreToTimestamp, err = mysqlctl.ParseRFC3339(recoverTablesOptions.RestoreToTimestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	stream, err := client.RecoverTables(commandCtx, &vtctldatapb.RecoverTablesRequest{
+		Keyspace:             recoverTablesOptions.Keyspace,
+		ScratchKeyspace:      recoverTablesOptions.ScratchKeyspace,
+		TabletAlias:          alias,
+		Tables:               recoverTablesOptions.Tables,
+		RestoreToPos:         recoverTablesOptions.RestoreToPos,
+		RestoreToTimestamp:   protoutil.TimeToProto(restoreToTimestamp),
+		AllowedBackupEngines: recoverTablesOptions.AllowedBackupEngines,
+		DryRun:               recoverTablesOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		switch err {
+		case nil:
+			fmt.Printf("%s/%s (%s): %v\n", resp.ScratchKeyspace, resp.Shard, topoproto.TabletAliasString(resp.TabletAlias), resp.Event)
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
 var restoreFromBackupOptions = struct {
 	BackupTimestamp      string
 	AllowedBackupEngines []string
@@ -312,6 +382,18 @@ func init() {
 	GetBackups.Flags().BoolVarP(&getBackupsOptions.OutputJSON, "json", "j", false, "Output backup info in JSON format rather than a list of backups.")
 	Root.AddCommand(GetBackups)
 
+	RecoverTables.Flags().StringVar(&recoverTablesOptions.Keyspace, "keyspace", "", "The keyspace of the tables to recover.")
+	RecoverTables.Flags().StringVar(&recoverTablesOptions.ScratchKeyspace, "scratch-keyspace", "", "The SNAPSHOT keyspace, based on --keyspace, of the tablet to recover the tables on.")
+	RecoverTables.Flags().StringSliceVar(&recoverTablesOptions.Tables, "tables", nil, "The tables to recover.")
+	RecoverTables.Flags().StringSliceVar(&recoverTablesOptions.AllowedBackupEngines, "allowed-backup-engines", recoverTablesOptions.AllowedBackupEngines, "if set, only backups taken with the specified engines are eligible to be restored")
+	RecoverTables.Flags().StringVar(&recoverTablesOptions.RestoreToPos, "restore-to-pos", "", "Recover the tables up to, and including, the given position.")
+	RecoverTables.Flags().StringVar(&recoverTablesOptions.RestoreToTimestamp, "restore-to-timestamp", "", "Recover the tables up to, and excluding, the given timestamp in RFC3339 format (`2006-01-02T15:04:05Z07:00`).")
+	RecoverTables.Flags().BoolVar(&recoverTablesOptions.DryRun, "dry-run", false, "Only validate restore steps, do not actually restore data")
+	RecoverTables.MarkFlagRequired("keyspace")
+	RecoverTables.MarkFlagRequired("scratch-keyspace")
+	RecoverTables.MarkFlagRequired("tables")
+	Root.AddCommand(RecoverTables)
+
 	Root.AddCommand(RemoveBackup)
 
 	RestoreFromBackup.Flags().StringVarP(&restoreFromBackupOptions.BackupTimestamp, "backup-timestamp", "t", "", "Use the backup taken at, or closest before, this timestamp. Omit to use the latest backup. Timestamp format is \"YYYY-mm-DD.HHMMSS\".")
@@ -19,6 +19,7 @@ package command
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/constants/sidecar"
+	"vitess.io/vitess/go/json2"
 	"vitess.io/vitess/go/mysql"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
@@ -61,6 +63,29 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandDeleteKeyspace,
 	}
+	// DisableVtorcRecoveries makes a DisableVtorcRecoveries gRPC call to a vtctld.
+	DisableVtorcRecoveries = &cobra.Command{
+		Use:   "DisableVtorcRecoveries [--shard <shard>] [--analysis-code <code>] [--duration <duration>] [--reason <reason>] <keyspace>",
+		Short: "Stops VTOrc from running recoveries for the specified keyspace, one of its shards, or a single analysis code.",
+		Long: `Stops VTOrc from running recoveries for the specified keyspace, one of its shards, or a single analysis code.
+
+The disable is stored in the keyspace record, so it applies to every VTOrc instance and survives VTOrc restarts.
+It lasts until the given duration passes, or until EnableVtorcRecoveries is called with the same shard and analysis code.
+
+To stop VTOrc from running DeadPrimary recoveries on customer/-80 for the next two hours, you would use the following command:
+DisableVtorcRecoveries --shard '-80' --analysis-code 'DeadPrimary' --duration 2h customer`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDisableVtorcRecoveries,
+	}
+	// EnableVtorcRecoveries makes an EnableVtorcRecoveries gRPC call to a vtctld.
+	EnableVtorcRecoveries = &cobra.Command{
+		Use:                   "EnableVtorcRecoveries [--shard <shard>] [--analysis-code <code>] <keyspace>",
+		Short:                 "Removes a VTOrc recovery disable added by DisableVtorcRecoveries with the same shard and analysis code.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandEnableVtorcRecoveries,
+	}
 	// FindAllShardsInKeyspace makes a FindAllShardsInKeyspace gRPC call to a vtctld.
 	FindAllShardsInKeyspace = &cobra.Command{
 		Use:                   "FindAllShardsInKeyspace <keyspace>",
@@ -98,14 +123,19 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 	}
 	// SetKeyspaceDurabilityPolicy makes a SetKeyspaceDurabilityPolicy gRPC call to a vtcltd.
 	SetKeyspaceDurabilityPolicy = &cobra.Command{
-		Use:   "SetKeyspaceDurabilityPolicy [--durability-policy=policy_name] <keyspace name>",
+		Use:   "SetKeyspaceDurabilityPolicy [--durability-policy=policy_name] [--durability-policy-config CONFIG | --durability-policy-config-file CONFIG_FILE] <keyspace name>",
 		Short: "Sets the durability-policy used by the specified keyspace.",
 		Long: `Sets the durability-policy used by the specified keyspace. 
 Durability policy governs the durability of the keyspace by describing which tablets should be sending semi-sync acknowledgements to the primary.
 Possible values include 'semi_sync', 'none' and others as dictated by registered plugins.
 
 To set the durability policy of customer keyspace to semi_sync, you would use the following command:
-SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
+SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer
+
+The 'quorum' durability policy is declared with a JSON config instead, whose ack rule says how many acks the primary needs from a group of cells.
+Semi-sync counts the acks of all the ackers together, so a quorum mixing acks from several groups of cells is not supported.
+The config is validated against the tablets of every shard in the keyspace. To require 1 ack from another region than the one of the primary, you would use:
+SetKeyspaceDurabilityPolicy --durability-policy-config='{"regions": [{"name": "east", "cells": ["zone1", "zone2"]}, {"name": "west", "cells": ["zone3"]}], "acks": {"scope": "OTHER_REGION", "count": 1}}' customer`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandSetKeyspaceDurabilityPolicy,
@@ -230,6 +260,72 @@ func commandDeleteKeyspace(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var disableVtorcRecoveriesOptions = struct {
+	Shard        string
+	AnalysisCode string
+	Duration     time.Duration
+	Reason       string
+}{}
+
+func commandDisableVtorcRecoveries(cmd *cobra.Command, args []string) error {
+	if disableVtorcRecoveriesOptions.Duration < 0 {
+		return fmt.Errorf("--duration must not be negative, got %v", disableVtorcRecoveriesOptions.Duration)
+	}
+
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.DisableVtorcRecoveriesRequest{
+		Keyspace:     keyspace,
+		Shard:        disableVtorcRecoveriesOptions.Shard,
+		AnalysisCode: disableVtorcRecoveriesOptions.AnalysisCode,
+		Reason:       disableVtorcRecoveriesOptions.Reason,
+	}
+	if disableVtorcRecoveriesOptions.Duration > 0 {
+		req.Duration = protoutil.DurationToProto(disableVtorcRecoveriesOptions.Duration)
+	}
+
+	resp, err := client.DisableVtorcRecoveries(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var enableVtorcRecoveriesOptions = struct {
+	Shard        string
+	AnalysisCode string
+}{}
+
+func commandEnableVtorcRecoveries(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.EnableVtorcRecoveries(commandCtx, &vtctldatapb.EnableVtorcRecoveriesRequest{
+		Keyspace:     keyspace,
+		Shard:        enableVtorcRecoveriesOptions.Shard,
+		AnalysisCode: enableVtorcRecoveriesOptions.AnalysisCode,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandFindAllShardsInKeyspace(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -318,16 +414,51 @@ func commandRemoveKeyspaceCell(cmd *cobra.Command, args []string) error {
 }
 
 var setKeyspaceDurabilityPolicyOptions = struct {
-	DurabilityPolicy string
+	DurabilityPolicy               string
+	DurabilityPolicyConfig         string
+	DurabilityPolicyConfigFilePath string
 }{}
 
 func commandSetKeyspaceDurabilityPolicy(cmd *cobra.Command, args []string) error {
 	keyspace := cmd.Flags().Arg(0)
+
+	if setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfig != "" && setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfigFilePath != "" {
+		return errors.New("cannot pass both --durability-policy-config and --durability-policy-config-file")
+	}
+
 	cli.FinishedParsing(cmd)
 
+	durabilityPolicy := setKeyspaceDurabilityPolicyOptions.DurabilityPolicy
+	var configBytes []byte
+	switch {
+	case setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfigFilePath != "":
+		data, err := os.ReadFile(setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfigFilePath)
+		if err != nil {
+			return err
+		}
+
+		configBytes = data
+	case setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfig != "":
+		configBytes = []byte(setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfig)
+	}
+
+	var config *topodatapb.DurabilityPolicyConfig
+	if configBytes != nil {
+		config = &topodatapb.DurabilityPolicyConfig{}
+		if err := json2.UnmarshalPB(configBytes, config); err != nil {
+			return err
+		}
+
+		// A config declares the quorum policy, so the default policy name doesn't apply.
+		if !cmd.Flags().Changed("durability-policy") {
+			durabilityPolicy = policy.DurabilityQuorum
+		}
+	}
+
 	resp, err := client.SetKeyspaceDurabilityPolicy(commandCtx, &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
-		Keyspace:         keyspace,
-		DurabilityPolicy: setKeyspaceDurabilityPolicyOptions.DurabilityPolicy,
+		Keyspace:               keyspace,
+		DurabilityPolicy:       durabilityPolicy,
+		DurabilityPolicyConfig: config,
 	})
 	if err != nil {
 		return err
@@ -377,6 +508,16 @@ func init() {
 	DeleteKeyspace.Flags().BoolVarP(&deleteKeyspaceOptions.Force, "force", "f", false, "Delete the keyspace even if it cannot be locked; this should only be used for cleanup operations.")
 	Root.AddCommand(DeleteKeyspace)
 
+	DisableVtorcRecoveries.Flags().StringVar(&disableVtorcRecoveriesOptions.Shard, "shard", "", "Only disable recoveries for this shard of the keyspace.")
+	DisableVtorcRecoveries.Flags().StringVar(&disableVtorcRecoveriesOptions.AnalysisCode, "analysis-code", "", "Only disable recoveries for this VTOrc analysis code, such as DeadPrimary.")
+	DisableVtorcRecoveries.Flags().DurationVar(&disableVtorcRecoveriesOptions.Duration, "duration", 0, "How long recoveries stay disabled. If zero, they stay disabled until EnableVtorcRecoveries is called.")
+	DisableVtorcRecoveries.Flags().StringVar(&disableVtorcRecoveriesOptions.Reason, "reason", "", "Why recoveries are being disabled. Stored with the disable and written to the VTOrc audit log.")
+	Root.AddCommand(DisableVtorcRecoveries)
+
+	EnableVtorcRecoveries.Flags().StringVar(&enableVtorcRecoveriesOptions.Shard, "shard", "", "The shard the recoveries were disabled for, if any.")
+	EnableVtorcRecoveries.Flags().StringVar(&enableVtorcRecoveriesOptions.AnalysisCode, "analysis-code", "", "The analysis code the recoveries were disabled for, if any.")
+	Root.AddCommand(EnableVtorcRecoveries)
+
 	Root.AddCommand(FindAllShardsInKeyspace)
 	Root.AddCommand(GetKeyspace)
 	Root.AddCommand(GetKeyspaces)
@@ -386,6 +527,8 @@ func init() {
 	Root.AddCommand(RemoveKeyspaceCell)
 
 	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", policy.DurabilityNone, "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins.")
+	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfig, "durability-policy-config", "", "Config of the 'quorum' durability policy, specified as JSON.")
+	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicyConfigFilePath, "durability-policy-config-file", "", "Path to a file containing the config of the 'quorum' durability policy, specified as JSON.")
 	Root.AddCommand(SetKeyspaceDurabilityPolicy)
 
 	Root.AddCommand(ValidateVersionKeyspace)
@@ -199,6 +199,7 @@ var plannedReparentShardOptions = struct {
 	WaitReplicasTimeout     time.Duration
 	TolerableReplicationLag time.Duration
 	AllowCrossCellPromotion bool
+	DrainTimeout            time.Duration
 }{}
 
 func commandPlannedReparentShard(cmd *cobra.Command, args []string) error {
@@ -245,6 +246,7 @@ func commandPlannedReparentShard(cmd *cobra.Command, args []string) error {
 		WaitReplicasTimeout:     protoutil.DurationToProto(plannedReparentShardOptions.WaitReplicasTimeout),
 		TolerableReplicationLag: protoutil.DurationToProto(plannedReparentShardOptions.TolerableReplicationLag),
 		AllowCrossCellPromotion: plannedReparentShardOptions.AllowCrossCellPromotion,
+		DrainTimeout:            protoutil.DurationToProto(plannedReparentShardOptions.DrainTimeout),
 	})
 	if err != nil {
 		return err
@@ -322,6 +324,7 @@ func init() {
 	PlannedReparentShard.Flags().StringVar(&plannedReparentShardOptions.AvoidPrimaryAliasStr, "avoid-primary", "", "Alias of a tablet that should not be the primary; i.e. \"reparent to any other tablet if this one is the primary\".")
 	PlannedReparentShard.Flags().StringVar(&plannedReparentShardOptions.ExpectedPrimaryAliasStr, "expected-primary", "", "Alias of a tablet that must be the current primary in order for the reparent to be processed.")
 	PlannedReparentShard.Flags().BoolVar(&plannedReparentShardOptions.AllowCrossCellPromotion, "allow-cross-cell-promotion", false, "Allow cross cell promotion")
+	PlannedReparentShard.Flags().DurationVar(&plannedReparentShardOptions.DrainTimeout, "drain-timeout", 0, "If set, the current primary stops starting new transactions and waits up to this long for in-flight ones to finish before it is demoted. VTGates buffer new transactions meanwhile.")
 	Root.AddCommand(PlannedReparentShard)
 
 	Root.AddCommand(ReparentTablet)
@@ -45,6 +45,7 @@ import (
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/migrate"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/mount"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/movetables"
+	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/replicate"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/reshard"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/vdiff"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/workflow"
@@ -52,6 +53,8 @@ import (
 	// These imports register the topo factories to use when --server=internal.
 	_ "vitess.io/vitess/go/vt/topo/consultopo"
 	_ "vitess.io/vitess/go/vt/topo/etcd2topo"
+	_ "vitess.io/vitess/go/vt/topo/proxytopo"
+	_ "vitess.io/vitess/go/vt/topo/rafttopo"
 	_ "vitess.io/vitess/go/vt/topo/zk2topo"
 )
 
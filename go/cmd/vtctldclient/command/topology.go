@@ -25,11 +25,54 @@ import (
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topotools"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 var (
+	// DiffTopo compares a topo snapshot with another snapshot or with the
+	// live topology.
+	DiffTopo = &cobra.Command{
+		Use:   "DiffTopo <file> [<file>]",
+		Short: "Compares a topo snapshot with another snapshot, or with the live topology server.",
+		Long: `Compares a topo snapshot written by ExportTopo with another snapshot, or with the live topology server if only one file is given.
+
+Files that Vitess knows how to decode are compared field by field, so files that only differ in their encoding are not reported.
+Added files are prefixed with '+', removed files with '-' and changed files with '~'.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.RangeArgs(1, 2),
+		RunE:                  commandDiffTopo,
+	}
+
+	// ExportTopo makes an ExportTopo gRPC call to a vtctld.
+	ExportTopo = &cobra.Command{
+		Use:   "ExportTopo [--cells <cell1,cell2,...>] <file>",
+		Short: "Exports the global and cell topology trees to a snapshot file.",
+		Long: `Exports every persistent file in the global topology server and in the given cells to a gzip-compressed snapshot file.
+Ephemeral entries, such as locks and elections, are not exported.
+
+The snapshot can be compared with DiffTopo and restored with RestoreTopo.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandExportTopo,
+	}
+
+	// RestoreTopo makes a RestoreTopo gRPC call to a vtctld.
+	RestoreTopo = &cobra.Command{
+		Use:   "RestoreTopo [--keyspaces <keyspace1,keyspace2,...>] [--vschema-only] [--include-shards] [--cells <cell1,cell2,...>] [--dry-run] <file>",
+		Short: "Restores keyspaces or VSchemas from a topo snapshot file.",
+		Long: `Restores the Keyspace and VSchema records of keyspaces, and the routing rules, from a snapshot written by ExportTopo.
+
+Shard records are only restored with --include-shards, and keep the primary of existing shards. Cell files are never written:
+the SrvKeyspace of the restored keyspaces is rebuilt instead, and the ShardReplication records are left to the tablets.
+Only files whose contents differ from the live topology server are written, and files that are not in the snapshot are left alone.
+The SrvVSchema is rebuilt in every cell if any VSchema or routing rules were restored.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRestoreTopo,
+	}
+
 	// GetTopologyPath makes a GetTopologyPath gRPC call to a vtctld.
 	GetTopologyPath = &cobra.Command{
 		Use:                   "GetTopologyPath <path>",
@@ -56,6 +99,152 @@ var (
 	}
 )
 
+func readTopoSnapshot(file string) (*vtctldatapb.TopoSnapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshot, err := topotools.ReadTopoSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return snapshot, nil
+}
+
+func commandDiffTopo(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	from, err := readTopoSnapshot(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var to *vtctldatapb.TopoSnapshot
+	if cmd.Flags().NArg() > 1 {
+		to, err = readTopoSnapshot(cmd.Flags().Arg(1))
+		if err != nil {
+			return err
+		}
+	} else {
+		resp, err := client.ExportTopo(commandCtx, &vtctldatapb.ExportTopoRequest{
+			Cells: from.Cells,
+		})
+		if err != nil {
+			return err
+		}
+		to = resp.Snapshot
+	}
+
+	diffs, err := topotools.DiffTopoSnapshots(from, to)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("No differences found.")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		var prefix string
+		switch diff.Change {
+		case topotools.TopoFileAdded:
+			prefix = "+"
+		case topotools.TopoFileRemoved:
+			prefix = "-"
+		default:
+			prefix = "~"
+		}
+		fmt.Printf("%s %s:%s\n", prefix, diff.Cell, diff.Path)
+		if diff.Change == topotools.TopoFileChanged && len(diff.Fields) == 0 {
+			fmt.Println("    contents changed")
+		}
+		for _, field := range diff.Fields {
+			fmt.Printf("    %s\n", field)
+		}
+	}
+
+	return nil
+}
+
+var exportTopoOptions = struct {
+	Cells []string
+}{}
+
+func commandExportTopo(cmd *cobra.Command, args []string) error {
+	file := cmd.Flags().Arg(0)
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ExportTopo(commandCtx, &vtctldatapb.ExportTopoRequest{
+		Cells: exportTopoOptions.Cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := topotools.WriteTopoSnapshot(f, resp.Snapshot); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d files from the global cell and cells %s to %s\n", len(resp.Snapshot.Files), strings.Join(resp.Snapshot.Cells, ","), file)
+	return nil
+}
+
+var restoreTopoOptions = struct {
+	Keyspaces     []string
+	VSchemaOnly   bool
+	IncludeShards bool
+	Cells         []string
+	DryRun        bool
+}{}
+
+func commandRestoreTopo(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	snapshot, err := readTopoSnapshot(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.RestoreTopo(commandCtx, &vtctldatapb.RestoreTopoRequest{
+		Snapshot:      snapshot,
+		Keyspaces:     restoreTopoOptions.Keyspaces,
+		VschemaOnly:   restoreTopoOptions.VSchemaOnly,
+		IncludeShards: restoreTopoOptions.IncludeShards,
+		Cells:         restoreTopoOptions.Cells,
+		DryRun:        restoreTopoOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Files) == 0 {
+		fmt.Println("The topology already matches the snapshot.")
+		return nil
+	}
+
+	if restoreTopoOptions.DryRun {
+		fmt.Println("The following files would be restored:")
+	} else {
+		fmt.Println("Restored the following files:")
+	}
+	for _, file := range resp.Files {
+		fmt.Printf("    %s:%s\n", file.Cell, file.Path)
+	}
+
+	return nil
+}
+
 var getTopologyPathOptions = struct {
 	// The version of the key/path to get. If not specified, the latest/current
 	// version is returned.
@@ -127,6 +316,18 @@ func commandWriteTopologyPath(cmd *cobra.Command, args []string) error {
 }
 
 func init() {
+	Root.AddCommand(DiffTopo)
+
+	ExportTopo.Flags().StringSliceVarP(&exportTopoOptions.Cells, "cells", "c", nil, "Cells to export in addition to the global cell. If empty, all known cells are exported.")
+	Root.AddCommand(ExportTopo)
+
+	RestoreTopo.Flags().StringSliceVar(&restoreTopoOptions.Keyspaces, "keyspaces", nil, "Keyspaces to restore. If empty, every keyspace in the snapshot is restored, along with the routing rules.")
+	RestoreTopo.Flags().BoolVar(&restoreTopoOptions.VSchemaOnly, "vschema-only", false, "Only restore the VSchema of each keyspace.")
+	RestoreTopo.Flags().BoolVar(&restoreTopoOptions.IncludeShards, "include-shards", false, "Also restore the Shard records of each keyspace, keeping the primary of existing shards.")
+	RestoreTopo.Flags().StringSliceVarP(&restoreTopoOptions.Cells, "cells", "c", nil, "Cells in which the SrvKeyspace of the restored keyspaces is rebuilt. If empty, it is rebuilt in every cell in the snapshot.")
+	RestoreTopo.Flags().BoolVar(&restoreTopoOptions.DryRun, "dry-run", false, "Only report the files that would be restored.")
+	Root.AddCommand(RestoreTopo)
+
 	GetTopologyPath.Flags().Int64Var(&getTopologyPathOptions.version, "version", getTopologyPathOptions.version, "The version of the path's key to get. If not specified, the latest version is returned.")
 	GetTopologyPath.Flags().BoolVar(&getTopologyPathOptions.dataAsJSON, "data-as-json", getTopologyPathOptions.dataAsJSON, "If true, only the data is output and it is in JSON format rather than prototext.")
 	Root.AddCommand(GetTopologyPath)
@@ -98,6 +98,7 @@ func commandSwitchTraffic(cmd *cobra.Command, args []string) error {
 		TabletTypes:               SwitchTrafficOptions.TabletTypes,
 		MaxReplicationLagAllowed:  protoutil.DurationToProto(SwitchTrafficOptions.MaxReplicationLagAllowed),
 		Timeout:                   protoutil.DurationToProto(SwitchTrafficOptions.Timeout),
+		MaxVdiffAge:               protoutil.DurationToProto(SwitchTrafficOptions.MaxVDiffAge),
 		DryRun:                    SwitchTrafficOptions.DryRun,
 		EnableReverseReplication:  SwitchTrafficOptions.EnableReverseReplication,
 		InitializeTargetSequences: SwitchTrafficOptions.InitializeTargetSequences,
@@ -121,6 +122,19 @@ func commandSwitchTraffic(cmd *cobra.Command, args []string) error {
 			for _, line := range resp.DryRunResults {
 				tout.WriteString(line + "\n")
 			}
+			if report := resp.ReadinessReport; report != nil {
+				tout.WriteString(fmt.Sprintf("\nReadiness report: ready=%t\n", report.Ready))
+				for _, check := range report.Checks {
+					result := "passed"
+					if !check.Passed {
+						result = "failed"
+					}
+					tout.WriteString(fmt.Sprintf("  %s: %s\n", check.Name, result))
+					for _, problem := range check.Problems {
+						tout.WriteString(fmt.Sprintf("    - %s\n", problem))
+					}
+				}
+			}
 		} else {
 			tout.WriteString(fmt.Sprintf("Start State: %s\n", resp.StartState))
 			tout.WriteString(fmt.Sprintf("Current State: %s\n", resp.CurrentState))
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+	"vitess.io/vitess/go/protoutil"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// replicate is the base command for all actions related to the replicate command.
+	replicate = &cobra.Command{
+		Use:                   "Replicate --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Replicate is used to continuously replicate tables from an external cluster into the current cluster.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"replicate"},
+		Args:                  cobra.ExactArgs(1),
+	}
+)
+
+var createOptions = struct {
+	MountName      string
+	SourceKeyspace string
+	AllTables      bool
+	IncludeTables  []string
+	ExcludeTables  []string
+	SourceTimeZone string
+	NoCopy         bool
+}{}
+
+var createCommand = &cobra.Command{
+	Use:                   "create",
+	Short:                 "Create and optionally run a Replicate VReplication workflow.",
+	Example:               `vtctldclient --server localhost:15999 replicate --workflow east2west --target-keyspace commerce create --source-keyspace commerce --mount-name east --tablet-types replica`,
+	SilenceUsage:          true,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"Create"},
+	Args:                  cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// Either specific tables or the all tables flags are required.
+		if !cmd.Flags().Lookup("tables").Changed && !cmd.Flags().Lookup("all-tables").Changed {
+			return fmt.Errorf("tables or all-tables are required to specify which tables to replicate")
+		}
+		if err := common.ParseAndValidateCreateOptions(cmd); err != nil {
+			return err
+		}
+		return nil
+	},
+	RunE: commandCreate,
+}
+
+func commandCreate(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	tsp := common.GetTabletSelectionPreference(cmd)
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.ReplicateCreateRequest{
+		Workflow:                  common.BaseOptions.Workflow,
+		TargetKeyspace:            common.BaseOptions.TargetKeyspace,
+		SourceKeyspace:            createOptions.SourceKeyspace,
+		MountName:                 createOptions.MountName,
+		SourceTimeZone:            createOptions.SourceTimeZone,
+		Cells:                     common.CreateOptions.Cells,
+		TabletTypes:               common.CreateOptions.TabletTypes,
+		TabletSelectionPreference: tsp,
+		AllTables:                 createOptions.AllTables,
+		IncludeTables:             createOptions.IncludeTables,
+		ExcludeTables:             createOptions.ExcludeTables,
+		OnDdl:                     common.CreateOptions.OnDDL,
+		DeferSecondaryKeys:        common.CreateOptions.DeferSecondaryKeys,
+		AutoStart:                 common.CreateOptions.AutoStart,
+		NoCopy:                    createOptions.NoCopy,
+	}
+
+	resp, err := common.GetClient().ReplicateCreate(common.GetCommandCtx(), req)
+	if err != nil {
+		return err
+	}
+	if err = common.OutputStatusResponse(resp, format); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func addCreateFlags(cmd *cobra.Command) {
+	common.AddCommonCreateFlags(cmd)
+	cmd.Flags().StringVar(&createOptions.SourceKeyspace, "source-keyspace", "", "Keyspace where the tables are being replicated from.")
+	cmd.MarkFlagRequired("source-keyspace")
+	cmd.Flags().StringVar(&createOptions.MountName, "mount-name", "", "Name external cluster is mounted as.")
+	cmd.MarkFlagRequired("mount-name")
+	cmd.Flags().StringVar(&createOptions.SourceTimeZone, "source-time-zone", "", "Specifying this causes any DATETIME fields to be converted from the given time zone into UTC.")
+	cmd.Flags().BoolVar(&createOptions.AllTables, "all-tables", false, "Replicate all tables from the source.")
+	cmd.Flags().StringSliceVar(&createOptions.IncludeTables, "tables", nil, "Source tables to replicate.")
+	cmd.Flags().StringSliceVar(&createOptions.ExcludeTables, "exclude-tables", nil, "Source tables to exclude from replicating.")
+	cmd.Flags().BoolVar(&createOptions.NoCopy, "no-copy", false, "Do not copy the tables and start replicating from the current source position. Use this when the target tables already contain the data, e.g. when setting up the reverse direction of a bidirectional replication.")
+}
+
+var promoteOptions = struct {
+	Timeout time.Duration
+	Force   bool
+}{}
+
+var promoteCommand = &cobra.Command{
+	Use:                   "promote",
+	Short:                 "Wait for a Replicate VReplication workflow to catch up with its source and then stop it.",
+	Example:               `vtctldclient --server localhost:15999 replicate --workflow east2west --target-keyspace commerce promote --timeout 1m`,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"Promote"},
+	Args:                  cobra.NoArgs,
+	RunE:                  commandPromote,
+}
+
+func commandPromote(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.ReplicatePromoteRequest{
+		Workflow:       common.BaseOptions.Workflow,
+		TargetKeyspace: common.BaseOptions.TargetKeyspace,
+		Timeout:        protoutil.DurationToProto(promoteOptions.Timeout),
+		Force:          promoteOptions.Force,
+	}
+	resp, err := common.GetClient().ReplicatePromote(common.GetCommandCtx(), req)
+	if err != nil {
+		return err
+	}
+
+	var output []byte
+	if format == "json" {
+		output, err = cli.MarshalJSONPretty(resp)
+		if err != nil {
+			return err
+		}
+	} else {
+		output = []byte(resp.Summary + "\n")
+	}
+	fmt.Println(string(output))
+
+	return nil
+}
+
+var cancelOptions = struct {
+	KeepData bool
+}{}
+
+func registerCommands(root *cobra.Command) {
+	common.AddCommonFlags(replicate)
+	root.AddCommand(replicate)
+	addCreateFlags(createCommand)
+	replicate.AddCommand(createCommand)
+	opts := &common.SubCommandsOpts{
+		SubCommand: "Replicate",
+		Workflow:   "east2west",
+	}
+	replicate.AddCommand(common.GetShowCommand(opts))
+	replicate.AddCommand(common.GetStatusCommand(opts))
+	replicate.AddCommand(common.GetStartCommand(opts))
+	replicate.AddCommand(common.GetStopCommand(opts))
+
+	promoteCommand.Flags().DurationVar(&promoteOptions.Timeout, "timeout", 30*time.Second, "How long to wait for the workflow to catch up with its source.")
+	promoteCommand.Flags().BoolVar(&promoteOptions.Force, "force", false, "Stop the workflow even if it did not catch up with its source within the timeout.")
+	replicate.AddCommand(promoteCommand)
+
+	cancel := common.GetCancelCommand(opts)
+	// The target tables of a Replicate workflow are production tables, which
+	// may be written to directly, so they are only dropped on request. The
+	// flag has its own variable as common.CancelOptions.KeepData defaults to
+	// false for the other workflow types.
+	cancel.Flags().BoolVar(&cancelOptions.KeepData, "keep-data", true, "Keep the replicated table data in the target keyspace. Set to false to drop the target tables.")
+	cancel.PreRun = func(cmd *cobra.Command, args []string) {
+		common.CancelOptions.KeepData = cancelOptions.KeepData
+	}
+	replicate.AddCommand(cancel)
+}
+
+func init() {
+	common.RegisterCommandHandler("Replicate", registerCommands)
+}
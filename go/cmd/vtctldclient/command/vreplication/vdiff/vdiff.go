@@ -69,6 +69,10 @@ var (
 		MaxDiffDuration             time.Duration
 		RowDiffColumnTruncateAt     int64
 		AutoStart                   bool
+		Checksum                    bool
+		SamplePct                   int64
+		ChunkSize                   int64
+		ChecksumAlgorithm           string
 	}{}
 
 	deleteOptions = struct {
@@ -127,6 +131,17 @@ var (
 		if createOptions.MaxExtraRowsToCompare < 0 {
 			return fmt.Errorf("--max-extra-rows-to-compare must not be a negative value")
 		}
+		if createOptions.SamplePct < 1 || createOptions.SamplePct > 100 {
+			return fmt.Errorf("--sample-pct must be between 1 and 100")
+		}
+		if createOptions.ChunkSize < 1 {
+			return fmt.Errorf("--chunk-size must be a positive value")
+		}
+		switch createOptions.ChecksumAlgorithm {
+		case vdiff.ChecksumAlgorithmCRC32, vdiff.ChecksumAlgorithmMD5:
+		default:
+			return fmt.Errorf("--checksum-algorithm must be %s or %s", vdiff.ChecksumAlgorithmCRC32, vdiff.ChecksumAlgorithmMD5)
+		}
 		return nil
 	}
 
@@ -300,6 +315,10 @@ func commandCreate(cmd *cobra.Command, args []string) error {
 		MaxDiffDuration:             protoutil.DurationToProto(createOptions.MaxDiffDuration),
 		RowDiffColumnTruncateAt:     createOptions.RowDiffColumnTruncateAt,
 		AutoStart:                   &createOptions.AutoStart,
+		Checksum:                    createOptions.Checksum,
+		SamplePct:                   createOptions.SamplePct,
+		ChunkSize:                   createOptions.ChunkSize,
+		ChecksumAlgorithm:           createOptions.ChecksumAlgorithm,
 	})
 
 	if err != nil {
@@ -683,6 +702,10 @@ func registerCommands(root *cobra.Command) {
 	create.Flags().DurationVar(&createOptions.MaxDiffDuration, "max-diff-duration", 0, "How long should an individual table diff run before being stopped and restarted in order to lessen the impact on tablets due to holding open database snapshots for long periods of time (0 is the default and means no time limit).")
 	create.Flags().Int64Var(&createOptions.RowDiffColumnTruncateAt, "row-diff-column-truncate-at", 128, "When showing row differences, truncate the non Primary Key column values to this length. A value less than 1 means do not truncate.")
 	create.Flags().BoolVar(&createOptions.AutoStart, "auto-start", true, "Start the vdiff upon creation. When false, the vdiff will be created but will not run until resumed.")
+	create.Flags().BoolVar(&createOptions.Checksum, "checksum", false, "Split the tables into primary key ranges of --chunk-size rows and compare checksums of the ranges, computed by MySQL on the source and target, only diffing the rows of the ranges whose checksums do not match.")
+	create.Flags().Int64Var(&createOptions.SamplePct, "sample-pct", 100, "Split the tables into primary key ranges of --chunk-size rows and only diff this percentage of the ranges, picked at random. This gives a quick, statistical, check of the data.")
+	create.Flags().Int64Var(&createOptions.ChunkSize, "chunk-size", vdiff.DefaultChunkSize, "The number of rows in each primary key range used by --checksum and --sample-pct.")
+	create.Flags().StringVar(&createOptions.ChecksumAlgorithm, "checksum-algorithm", vdiff.ChecksumAlgorithmCRC32, "The hash function used by MySQL to checksum the rows with --checksum: crc32 or md5.")
 	base.AddCommand(create)
 
 	base.AddCommand(delete)
@@ -44,6 +44,9 @@ var (
 	//go:embed vtaclcheck.txt
 	vtaclcheckTxt string
 
+	//go:embed vtcdc.txt
+	vtcdcTxt string
+
 	//go:embed vtcombo.txt
 	vtcomboTxt string
 
@@ -95,6 +98,7 @@ var (
 		"topo2topo":        topo2topoTxt,
 		"vtaclcheck":       vtaclcheckTxt,
 		"vtbackup":         vtbackupTxt,
+		"vtcdc":            vtcdcTxt,
 		"vtcombo":          vtcomboTxt,
 		"vtctlclient":      vtctlclientTxt,
 		"vtctld":           vtctldTxt,
@@ -44,6 +44,10 @@ var (
 	// GoVtTopoConsultopoPort is used by the go/vt/topo/consultopo package.
 	// Takes four ports.
 	GoVtTopoConsultopoPort = GoVtTopoZk2topoPort + 3
+
+	// GoVtTopoRafttopoPort is used by the go/vt/topo/rafttopo package.
+	// Takes six ports.
+	GoVtTopoRafttopoPort = GoVtTopoConsultopoPort + 4
 )
 
 // Zookeeper server ID definitions. Unit tests may run at the
@@ -187,6 +187,9 @@ type shardState struct {
 	waitForReparent      bool
 	externallyReparented int64
 	currentPrimary       *topodatapb.TabletAlias
+	// draining is set while the primary drains its transactions
+	// before a planned reparent.
+	draining bool
 }
 
 // Subscribe returns a channel that will receive any KeyspaceEvents for all keyspaces in the
@@ -371,6 +374,12 @@ func (kss *keyspaceState) onHealthCheck(th *TabletHealth) {
 		kss.shards[th.Target.Shard] = sstate
 	}
 
+	// A primary that stops draining while it is still serving has abandoned the planned
+	// reparent it was draining for, so there is no reparent left to wait for.
+	drainAborted := sstate.draining && th.Serving && !th.Stats.GetDraining() &&
+		topoproto.TabletAliasEqual(sstate.currentPrimary, th.Tablet.Alias)
+	sstate.draining = th.Serving && th.Stats.GetDraining()
+
 	// if the shard went from serving to not serving, or the other way around, the keyspace
 	// is undergoing an availability event
 	if sstate.serving != th.Serving {
@@ -381,8 +390,9 @@ func (kss *keyspaceState) onHealthCheck(th *TabletHealth) {
 			// we should check if the primary term start time is greater than the externally reparented time.
 			// We mark the shard serving only if it is. This is required so that we don't prematurely stop
 			// buffering for PRS, or TabletExternallyReparented, after seeing a serving healthcheck from the
-			// same old primary tablet that has already been turned read-only.
-			if th.PrimaryTermStartTime > sstate.externallyReparented {
+			// same old primary tablet that has already been turned read-only. The exception is a
+			// primary that stopped draining, since the reparent was abandoned before its demotion.
+			if th.PrimaryTermStartTime > sstate.externallyReparented || drainAborted {
 				sstate.waitForReparent = false
 				sstate.serving = true
 			}
@@ -58,6 +58,7 @@ var (
 		"vtadmin",
 		"vtbackup",
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtctl",
 		"vtctlclient",
@@ -150,6 +150,9 @@ type RestoreParams struct {
 	MysqlShutdownTimeout time.Duration
 	// AllowedBackupEngines if present will filter out any backups taken with engines not included in the list
 	AllowedBackupEngines []string
+	// Tables, if present, limits the row changes applied by a point in time recovery to those of the named tables
+	// of DbName. The full backup still restores all the tables.
+	Tables []string
 }
 
 func (p *RestoreParams) Copy() RestoreParams {
@@ -169,6 +172,7 @@ func (p *RestoreParams) Copy() RestoreParams {
 		DryRun:               p.DryRun,
 		Stats:                p.Stats,
 		MysqlShutdownTimeout: p.MysqlShutdownTimeout,
+		Tables:               p.Tables,
 	}
 }
 
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// binlogTableMapRegexp matches the comment mysqlbinlog writes for a Table_map event, e.g.:
+// #250101 10:00:00 server id 1  end_log_pos 366 CRC32 0x3c7e1e0a 	Table_map: `vt_commerce`.`customer` mapped to number 91
+var binlogTableMapRegexp = regexp.MustCompile("Table_map: `((?:[^`]|``)*)`\\.`((?:[^`]|``)*)` mapped to number")
+
+// filterBinlogTables copies the output of mysqlbinlog from r to w, leaving out the row events of the tables
+// not in tables of database. mysqlbinlog writes the row events of a statement, along with their Table_map
+// events, as a single BINLOG statement, which is left out if none of the tables it maps is in tables of
+// database. Everything else, including DDLs and the transaction boundaries, is copied as is: the transactions
+// of filtered out row events are still applied, empty, so that the GTID position of the server is the one of
+// the binlog.
+func filterBinlogTables(r io.Reader, w io.Writer, database string, tables []string) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	var (
+		// mapped is set when a table was mapped since the last BINLOG statement,
+		// and keep when one of them is in tables of database.
+		mapped     bool
+		keep       bool
+		inBinlog   bool
+		skipBinlog bool
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			switch {
+			case inBinlog:
+				// Nothing to do, the line is part of the BINLOG statement.
+			case strings.HasPrefix(line, "BINLOG '"):
+				inBinlog = true
+				// A BINLOG statement without Table_map events, such as the one of the
+				// Format_description event, is always applied.
+				skipBinlog = mapped && !keep
+				mapped, keep = false, false
+			default:
+				if m := binlogTableMapRegexp.FindStringSubmatch(line); m != nil {
+					mapped = true
+					if unquoteBinlogName(m[1]) == database && slices.Contains(tables, unquoteBinlogName(m[2])) {
+						keep = true
+					}
+				}
+			}
+			if !inBinlog || !skipBinlog {
+				if _, err := writer.WriteString(line); err != nil {
+					return err
+				}
+			}
+			if inBinlog && strings.HasSuffix(strings.TrimRight(line, "\r\n"), "'/*!*/;") {
+				inBinlog = false
+			}
+		}
+		if err == io.EOF {
+			return writer.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unquoteBinlogName unescapes the backticks of a name quoted by mysqlbinlog.
+func unquoteBinlogName(name string) string {
+	return strings.ReplaceAll(name, "``", "`")
+}
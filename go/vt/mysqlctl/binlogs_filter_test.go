@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBinlogOutput = `# The proper term is pseudo_replica_mode, but we use this compatibility alias
+# to make the statement usable on server versions 8.0.24 and older.
+/*!50530 SET @@SESSION.PSEUDO_SLAVE_MODE=1*/;
+DELIMITER /*!*/;
+# at 4
+#250101 10:00:00 server id 1  end_log_pos 126 CRC32 0x5ea9fc09 	Start: binlog v 4, server v 8.0.40 created 250101 10:00:00
+BINLOG '
+AAAAAA8BAAAAegAAAH4AAAABAAQAOC4wLjQwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA
+'/*!*/;
+# at 197
+#250101 10:00:01 server id 1  end_log_pos 276 CRC32 0x8f1c0a2e 	GTID	last_committed=0	sequence_number=1
+SET @@SESSION.GTID_NEXT= '16b1039f-0000-0000-0000-000000000000:1'/*!*/;
+BEGIN
+/*!*/;
+# at 353
+#250101 10:00:01 server id 1  end_log_pos 412 CRC32 0x3c7e1e0a 	Table_map: ` + "`vt_commerce`.`customer`" + ` mapped to number 91
+# at 412
+#250101 10:00:01 server id 1  end_log_pos 470 CRC32 0x7a2b1c3d 	Write_rows: table id 91 flags: STMT_END_F
+BINLOG '
+customer1
+customer2
+'/*!*/;
+COMMIT/*!*/;
+# at 501
+#250101 10:00:02 server id 1  end_log_pos 580 CRC32 0x8f1c0a2f 	GTID	last_committed=1	sequence_number=2
+SET @@SESSION.GTID_NEXT= '16b1039f-0000-0000-0000-000000000000:2'/*!*/;
+BEGIN
+/*!*/;
+# at 657
+#250101 10:00:02 server id 1  end_log_pos 716 CRC32 0x3c7e1e0b 	Table_map: ` + "`vt_commerce`.`corder`" + ` mapped to number 92
+# at 716
+#250101 10:00:02 server id 1  end_log_pos 774 CRC32 0x7a2b1c3e 	Update_rows: table id 92 flags: STMT_END_F
+BINLOG '
+corder1
+'/*!*/;
+COMMIT/*!*/;
+# at 805
+#250101 10:00:03 server id 1  end_log_pos 884 CRC32 0x8f1c0a30 	GTID	last_committed=2	sequence_number=3
+SET @@SESSION.GTID_NEXT= '16b1039f-0000-0000-0000-000000000000:3'/*!*/;
+alter table customer add column email varchar(128)
+/*!*/;
+SET @@SESSION.GTID_NEXT= 'AUTOMATIC' /* added by mysqlbinlog */ /*!*/;
+DELIMITER ;
+`
+
+func TestFilterBinlogTables(t *testing.T) {
+	tcases := []struct {
+		name     string
+		database string
+		tables   []string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "customer",
+			tables:   []string{"customer"},
+			contains: []string{"AAAAAA8BAAAAegAAAH4A", "customer1", "customer2", "alter table customer", "000000000000:2'"},
+			excludes: []string{"corder1"},
+		},
+		{
+			name:     "corder",
+			tables:   []string{"corder"},
+			contains: []string{"AAAAAA8BAAAAegAAAH4A", "corder1", "alter table customer", "000000000000:1'"},
+			excludes: []string{"customer1", "customer2"},
+		},
+		{
+			name:     "both",
+			tables:   []string{"corder", "customer"},
+			contains: []string{"customer1", "customer2", "corder1"},
+		},
+		{
+			name:     "other database",
+			database: "vt_other",
+			tables:   []string{"corder", "customer"},
+			contains: []string{"AAAAAA8BAAAAegAAAH4A", "BEGIN", "COMMIT", "alter table customer"},
+			excludes: []string{"customer1", "customer2", "corder1"},
+		},
+		{
+			name:     "none",
+			tables:   []string{"product"},
+			contains: []string{"AAAAAA8BAAAAegAAAH4A", "BEGIN", "COMMIT", "DELIMITER ;"},
+			excludes: []string{"customer1", "customer2", "corder1"},
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			database := tcase.database
+			if database == "" {
+				database = "vt_commerce"
+			}
+			var out bytes.Buffer
+			err := filterBinlogTables(strings.NewReader(testBinlogOutput), &out, database, tcase.tables)
+			require.NoError(t, err)
+			for _, s := range tcase.contains {
+				assert.Contains(t, out.String(), s)
+			}
+			for _, s := range tcase.excludes {
+				assert.NotContains(t, out.String(), s)
+			}
+		})
+	}
+}
+
+func TestFilterBinlogTablesQuotedName(t *testing.T) {
+	input := "#250101 10:00:02 server id 1  end_log_pos 716 CRC32 0x3c7e1e0b \tTable_map: `vt_commerce`.`odd``name` mapped to number 92\nBINLOG '\nrows\n'/*!*/;\n"
+	var out bytes.Buffer
+	require.NoError(t, filterBinlogTables(strings.NewReader(input), &out, "vt_commerce", []string{"odd`name"}))
+	assert.Equal(t, input, out.String())
+
+	out.Reset()
+	require.NoError(t, filterBinlogTables(strings.NewReader(input), &out, "vt_commerce", []string{"odd"}))
+	assert.NotContains(t, out.String(), "rows")
+}
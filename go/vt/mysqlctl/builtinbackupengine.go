@@ -1086,6 +1086,8 @@ func (be *BuiltinBackupEngine) executeRestoreIncrementalBackup(ctx context.Conte
 		req := &mysqlctlpb.ApplyBinlogFileRequest{
 			BinlogFileName:        binlogFile,
 			BinlogRestoreDatetime: protoutil.TimeToProto(params.RestoreToTimestamp),
+			Tables:                params.Tables,
+			Database:              params.DbName,
 		}
 		if params.RestoreToPos.GTIDSet != nil {
 			req.BinlogRestorePosition = params.RestoreToPos.GTIDSet.String()
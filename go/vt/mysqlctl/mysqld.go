@@ -1425,6 +1425,7 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, req *mysqlctlpb.Apply
 		}
 	}
 	var mysqlErrFile *os.File
+	var mysqlStdin io.WriteCloser
 	{
 		name, err := binaryPath(dir, "mysql")
 		if err != nil {
@@ -1474,7 +1475,15 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, req *mysqlctlpb.Apply
 		mysqlCmd = exec.Command(name, args...)
 		mysqlCmd.Dir = dir
 		mysqlCmd.Env = env
-		mysqlCmd.Stdin = pipe // piped from mysqlbinlog
+		if len(req.Tables) == 0 {
+			mysqlCmd.Stdin = pipe // piped from mysqlbinlog
+		} else {
+			// piped from mysqlbinlog through filterBinlogTables
+			mysqlStdin, err = mysqlCmd.StdinPipe()
+			if err != nil {
+				return err
+			}
+		}
 
 		mysqlCmd.Stderr = mysqlErrFile
 		log.Infof("ApplyBinlogFile: running mysql command: %#v with errfile=%v", mysqlCmd, mysqlErrFile.Name())
@@ -1484,8 +1493,21 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, req *mysqlctlpb.Apply
 		return err
 	}
 	if err := mysqlCmd.Start(); err != nil {
+		killAndWait(mysqlbinlogCmd)
 		return vterrors.Wrapf(err, "failed to start mysql")
 	}
+	if mysqlStdin != nil {
+		// The filter must be done reading from mysqlbinlog before we wait on it.
+		if err := filterBinlogTables(pipe, mysqlStdin, req.Database, req.Tables); err != nil {
+			// mysql is killed before its input ends, so that it doesn't apply the
+			// partial transaction it may have been sent, and mysqlbinlog may still
+			// be writing.
+			killAndWait(mysqlCmd)
+			killAndWait(mysqlbinlogCmd)
+			return vterrors.Wrapf(err, "failed to filter binlog tables")
+		}
+		mysqlStdin.Close()
+	}
 	// Wait for both to complete:
 	if err := mysqlbinlogCmd.Wait(); err != nil {
 		if mysqlbinlogErrFile != nil {
@@ -1508,6 +1530,15 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, req *mysqlctlpb.Apply
 	return nil
 }
 
+// killAndWait kills a started command and waits for it to exit, so that it
+// isn't left behind as a zombie.
+func killAndWait(cmd *exec.Cmd) {
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		log.Warningf("failed to kill %v: %v", cmd.Path, err)
+	}
+	_ = cmd.Wait()
+}
+
 // parseBinlogEntryTimestamp attempts to extract a timestamp from a binlog entry.
 func parseBinlogEntryTimestamp(logEntry string) (t time.Time, err error) {
 	if len(logEntry) == 0 {
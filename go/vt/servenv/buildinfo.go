@@ -91,6 +91,11 @@ func (v *versionInfo) String() string {
 		v.version, jenkins, v.buildGitRev, v.buildGitBranch, v.buildTimePretty, v.buildUser, v.buildHost, v.goVersion, v.goOS, v.goArch)
 }
 
+// Version returns the Vitess release version, e.g. "23.0.0".
+func (v *versionInfo) Version() string {
+	return v.version
+}
+
 func (v *versionInfo) MySQLVersion() string {
 	return mySQLServerVersion
 }
@@ -500,6 +500,7 @@ func init() {
 func RegisterFlagsForTopoBinaries(registerFlags func(fs *pflag.FlagSet)) {
 	topoBinaries := []string{
 		"vtbackup",
+		"vtcdc",
 		"vtcombo",
 		"vtctl",
 		"vtctld",
@@ -0,0 +1,25 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+CREATE TABLE IF NOT EXISTS vreplication_online_ddl
+(
+    `vrepl_id`       int              NOT NULL,
+    `table_name`     varbinary(128)   NOT NULL,
+    `migration_uuid` varchar(64)      NOT NULL,
+    `pos`            varbinary(10000) NOT NULL,
+    `created_at`     timestamp        NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`vrepl_id`, `table_name`)
+) ENGINE = InnoDB CHARSET = utf8mb4
@@ -0,0 +1,389 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// AuditPath is the directory of the global cell the topo audit sink writes
+// its records to. Mutations of files under it are never audited.
+const AuditPath = "audit"
+
+// DefaultAuditPaths are the paths audited by default: the records of the
+// global cell that are changed by operators. The tablet records and the
+// serving graph, which change all the time, are left out.
+var DefaultAuditPaths = []string{
+	path.Join(KeyspacesPath, "*", KeyspaceFile),
+	path.Join(KeyspacesPath, "*", VSchemaFile),
+	path.Join(KeyspacesPath, "*", ShardsPath, "*", ShardFile),
+	CellsPath,
+	CellsAliasesPath,
+	RoutingRulesFile,
+	ShardRoutingRulesFile,
+	MirrorRulesFile,
+	RoutingRulesPath,
+	ExternalClustersFile,
+}
+
+// Audit operations.
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+)
+
+var (
+	topoAuditRecords = stats.NewCountersWithSingleLabel(
+		"TopologyAuditRecords",
+		"Number of topo mutations written to the audit sink, per operation",
+		"Operation")
+
+	topoAuditFailures = stats.NewCounter(
+		"TopologyAuditFailures",
+		"Number of topo mutations that couldn't be written to the audit sink")
+
+	topoAuditQueueLength = stats.NewGauge(
+		"TopologyAuditQueueLength",
+		"Number of audit records waiting to be written to the audit sink")
+)
+
+// AuditRecord describes a single mutation of a topo file.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Cell      string    `json:"cell"`
+	Path      string    `json:"path"`
+	Operation string    `json:"operation"`
+	// Version is the version of the file after a create or an update.
+	Version string `json:"version,omitempty"`
+	// Caller is the identity of the caller, taken from the effective caller
+	// ID, the gRPC static auth username or the client certificate, in that
+	// order.
+	Caller string `json:"caller,omitempty"`
+	// Peer is the address of the gRPC client that made the call.
+	Peer string `json:"peer,omitempty"`
+	// Method is the gRPC method that made the call.
+	Method string `json:"method,omitempty"`
+	// Reason is the reason given with WithAuditReason, or else the actions
+	// of the locks held by the caller.
+	Reason string `json:"reason,omitempty"`
+	// OldValue and NewValue are the decoded contents of the file, as JSON.
+	// Files that aren't known topo protobufs are shown as a JSON string.
+	// OldValue is only set if old values are audited, see
+	// Server.SetAuditOldValues.
+	OldValue json.RawMessage `json:"old_value,omitempty"`
+	NewValue json.RawMessage `json:"new_value,omitempty"`
+}
+
+// AuditSink receives the audit records of the topo mutations.
+type AuditSink interface {
+	// Write writes a single record. Errors are logged and counted, but
+	// don't fail the mutation, which has already been applied.
+	Write(ctx context.Context, record *AuditRecord) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// auditor holds the audit sink of a Server, and what it audits. It is
+// shared by the connections of all the cells.
+type auditor struct {
+	sink atomic.Pointer[AuditSink]
+	// paths are the patterns of the audited paths. DefaultAuditPaths are
+	// used if it is nil.
+	paths atomic.Pointer[[]string]
+	// oldValues is set if the records carry the old value of the files,
+	// which costs a read before every update and delete.
+	oldValues atomic.Bool
+}
+
+func (a *auditor) getSink() AuditSink {
+	if sink := a.sink.Load(); sink != nil {
+		return *sink
+	}
+	return nil
+}
+
+func (a *auditor) audits(filePath string) bool {
+	patterns := DefaultAuditPaths
+	if paths := a.paths.Load(); paths != nil {
+		patterns = *paths
+	}
+	// A pattern matching a directory matches all the files under it.
+	for p := filePath; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetAuditPaths sets the paths whose mutations are audited, in every cell.
+// Each pattern is matched with path.Match against the path of the files
+// and of their parent directories, so a pattern naming a directory audits
+// all the files under it. It returns an error if a pattern is malformed.
+func (ts *Server) SetAuditPaths(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid topo audit path %q: %w", pattern, err)
+		}
+	}
+	patterns = slices.Clone(patterns)
+	ts.auditor.paths.Store(&patterns)
+	return nil
+}
+
+// SetAuditOldValues sets whether the audit records carry the old value of
+// the updated and deleted files. Doing so costs a read of the file before
+// every audited update and delete, and an update that creates the file is
+// only recorded as a create if old values are audited.
+func (ts *Server) SetAuditOldValues(enabled bool) {
+	ts.auditor.oldValues.Store(enabled)
+}
+
+// SetAuditSink sets the sink receiving the audit records of all the
+// mutations made through this Server. A nil sink disables auditing.
+// The previous sink, if any, is closed.
+func (ts *Server) SetAuditSink(sink AuditSink) {
+	var old *AuditSink
+	if sink == nil {
+		old = ts.auditor.sink.Swap(nil)
+	} else {
+		old = ts.auditor.sink.Swap(&sink)
+	}
+	if old != nil {
+		if err := (*old).Close(); err != nil {
+			log.Warningf("failed to close topo audit sink: %v", err)
+		}
+	}
+}
+
+type auditReasonKeyType int
+
+var auditReasonKey auditReasonKeyType
+
+type auditDisabledKeyType int
+
+var auditDisabledKey auditDisabledKeyType
+
+// WithAuditReason returns a context whose topo mutations are audited with
+// the given reason.
+func WithAuditReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, auditReasonKey, reason)
+}
+
+// withoutAudit returns a context whose topo mutations are not audited. It
+// is used by the sinks that write to the topo.
+func withoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditDisabledKey, true)
+}
+
+// auditConn is a Conn wrapper that writes an audit record for every
+// successful mutation made through it.
+type auditConn struct {
+	Conn
+	cell    string
+	auditor *auditor
+}
+
+func newAuditConn(cell string, conn Conn, a *auditor) Conn {
+	return &auditConn{
+		Conn:    conn,
+		cell:    cell,
+		auditor: a,
+	}
+}
+
+func (ac *auditConn) sinkFor(ctx context.Context, filePath string) AuditSink {
+	sink := ac.auditor.getSink()
+	if sink == nil {
+		return nil
+	}
+	if disabled, _ := ctx.Value(auditDisabledKey).(bool); disabled {
+		return nil
+	}
+	p := strings.TrimPrefix(filePath, "/")
+	if p == AuditPath || strings.HasPrefix(p, AuditPath+"/") || !ac.auditor.audits(p) {
+		return nil
+	}
+	return sink
+}
+
+// Create is part of the Conn interface.
+func (ac *auditConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	version, err := ac.Conn.Create(ctx, filePath, contents)
+	if err == nil {
+		if sink := ac.sinkFor(ctx, filePath); sink != nil {
+			ac.write(ctx, sink, AuditOperationCreate, filePath, nil, contents, version)
+		}
+	}
+	return version, err
+}
+
+// Update is part of the Conn interface.
+func (ac *auditConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	sink := ac.sinkFor(ctx, filePath)
+	if sink == nil {
+		return ac.Conn.Update(ctx, filePath, contents, version)
+	}
+
+	// The old value is read right before the update, so a concurrent
+	// unconditional update may be missed. Conditional updates fail if the
+	// file changed in between.
+	operation := AuditOperationUpdate
+	var old []byte
+	if ac.auditor.oldValues.Load() {
+		var err error
+		old, _, err = ac.Conn.Get(ctx, filePath)
+		if IsErrType(err, NoNode) {
+			operation = AuditOperationCreate
+		}
+	}
+	newVersion, err := ac.Conn.Update(ctx, filePath, contents, version)
+	if err == nil {
+		ac.write(ctx, sink, operation, filePath, old, contents, newVersion)
+	}
+	return newVersion, err
+}
+
+// Delete is part of the Conn interface.
+func (ac *auditConn) Delete(ctx context.Context, filePath string, version Version) error {
+	sink := ac.sinkFor(ctx, filePath)
+	if sink == nil {
+		return ac.Conn.Delete(ctx, filePath, version)
+	}
+
+	var old []byte
+	if ac.auditor.oldValues.Load() {
+		old, _, _ = ac.Conn.Get(ctx, filePath)
+	}
+	err := ac.Conn.Delete(ctx, filePath, version)
+	if err == nil {
+		ac.write(ctx, sink, AuditOperationDelete, filePath, old, nil, nil)
+	}
+	return err
+}
+
+func (ac *auditConn) write(ctx context.Context, sink AuditSink, operation, filePath string, oldContents, newContents []byte, version Version) {
+	record := &AuditRecord{
+		Time:      time.Now(),
+		Cell:      ac.cell,
+		Path:      strings.TrimPrefix(filePath, "/"),
+		Operation: operation,
+		Caller:    auditCaller(ctx),
+		Reason:    auditReason(ctx),
+		OldValue:  auditValue(filePath, oldContents),
+		NewValue:  auditValue(filePath, newContents),
+	}
+	if version != nil {
+		record.Version = version.String()
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		record.Peer = p.Addr.String()
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		record.Method = method
+	}
+
+	// The mutation is done, so the record is written even if the caller
+	// has gone away in the meantime.
+	ctx, cancel := context.WithTimeout(withoutAudit(context.WithoutCancel(ctx)), RemoteOperationTimeout)
+	defer cancel()
+	if err := sink.Write(ctx, record); err != nil {
+		topoAuditFailures.Add(1)
+		log.Errorf("failed to write topo audit record for %s %s in cell %s: %v", operation, record.Path, ac.cell, err)
+		return
+	}
+	topoAuditRecords.Add(operation, 1)
+}
+
+// auditCaller returns the identity of the caller found in ctx.
+func auditCaller(ctx context.Context) string {
+	if ef := callerid.EffectiveCallerIDFromContext(ctx); ef.GetPrincipal() != "" {
+		return ef.GetPrincipal()
+	}
+	if username := servenv.StaticAuthUsernameFromContext(ctx); username != "" {
+		return username
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+		}
+	}
+	if im := callerid.ImmediateCallerIDFromContext(ctx); im.GetUsername() != "" {
+		return im.GetUsername()
+	}
+	return ""
+}
+
+// auditReason returns the reason set with WithAuditReason, or else the
+// actions of the locks held in ctx.
+func auditReason(ctx context.Context) string {
+	if reason, ok := ctx.Value(auditReasonKey).(string); ok {
+		return reason
+	}
+	i, ok := ctx.Value(locksKey).(*locksInfo)
+	if !ok {
+		return ""
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var actions []string
+	for _, li := range i.info {
+		if li.actionNode != nil && !slices.Contains(actions, li.actionNode.Action) {
+			actions = append(actions, li.actionNode.Action)
+		}
+	}
+	sort.Strings(actions)
+	return strings.Join(actions, ", ")
+}
+
+// auditValue decodes the contents of a topo file as JSON.
+func auditValue(filePath string, contents []byte) json.RawMessage {
+	if contents == nil {
+		return nil
+	}
+	if p := ProtoForPath(path.Join("/", filePath)); p != nil {
+		if err := proto.Unmarshal(contents, p); err == nil {
+			if data, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(p); err == nil {
+				return data
+			}
+		}
+	}
+	data, _ := json.Marshal(string(contents))
+	return data
+}
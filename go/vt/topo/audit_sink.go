@@ -0,0 +1,401 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	// auditSinkName is the name of the audit sink to use. Auditing is
+	// disabled if it is empty.
+	auditSinkName string
+
+	// auditPaths are the patterns of the audited paths.
+	auditPaths = slices.Clone(DefaultAuditPaths)
+
+	// auditOldValues is set if the records carry the old value of the
+	// updated and deleted files.
+	auditOldValues bool
+
+	// auditQueueSize is the number of records that can wait to be written
+	// to the audit sink. Records are dropped when the queue is full.
+	auditQueueSize = 1000
+
+	// auditRetention is how long the topo audit sink keeps its records.
+	auditRetention = 7 * 24 * time.Hour
+
+	// auditFile is the file the file audit sink appends to.
+	auditFile string
+
+	// auditWebhookURL is the URL the webhook audit sink posts to.
+	auditWebhookURL string
+
+	// auditWebhookTimeout is the timeout of each post of the webhook
+	// audit sink.
+	auditWebhookTimeout = 5 * time.Second
+
+	// auditSinkFactories has the factories for the audit sinks.
+	auditSinkFactories = map[string]AuditSinkFactory{
+		"file":    newFileAuditSinkFromFlags,
+		"topo":    newTopoAuditSink,
+		"webhook": newWebhookAuditSinkFromFlags,
+	}
+)
+
+func init() {
+	for _, cmd := range FlagBinaries {
+		servenv.OnParseFor(cmd, registerTopoAuditFlags)
+	}
+}
+
+func registerTopoAuditFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&auditSinkName, "topo-audit-sink", auditSinkName, fmt.Sprintf("Where to write an audit record of the topo mutations made by this process under --topo-audit-paths. One of: %s. Auditing is disabled if empty.", strings.Join(auditSinkNames(), ", ")))
+	fs.StringSliceVar(&auditPaths, "topo-audit-paths", auditPaths, "The paths whose mutations are audited, in every cell. Each pattern is matched against the path of the files and of their parent directories, with '*' matching a single path element.")
+	fs.BoolVar(&auditOldValues, "topo-audit-old-values", auditOldValues, "Whether the topo audit records carry the old value of the updated and deleted files. This costs a read of the file before every audited update and delete.")
+	fs.IntVar(&auditQueueSize, "topo-audit-queue-size", auditQueueSize, "The number of topo audit records that can wait to be written to the sink. Records are dropped, and counted as failures, when the queue is full.")
+	fs.StringVar(&auditFile, "topo-audit-file", auditFile, "The file the file topo audit sink appends its records to, one JSON document per line.")
+	fs.StringVar(&auditWebhookURL, "topo-audit-webhook-url", auditWebhookURL, "The URL the webhook topo audit sink posts each record to, as a JSON document.")
+	fs.DurationVar(&auditWebhookTimeout, "topo-audit-webhook-timeout", auditWebhookTimeout, "Timeout for posting a record to the topo audit webhook.")
+	fs.DurationVar(&auditRetention, "topo-audit-retention", auditRetention, "How long the topo audit sink of --topo-audit-sink=topo keeps its records in the global topo. Records are kept forever if 0.")
+}
+
+// AuditSinkFactory creates an audit sink for the given Server.
+type AuditSinkFactory func(ts *Server) (AuditSink, error)
+
+// RegisterAuditSink registers an audit sink that can be selected with
+// --topo-audit-sink. If a sink with that name already exists, it
+// log.Fatals out. Call this in the 'init' function of the sink module.
+func RegisterAuditSink(name string, factory AuditSinkFactory) {
+	if auditSinkFactories[name] != nil {
+		log.Fatalf("Duplicate topo audit sink registration for %v", name)
+	}
+	auditSinkFactories[name] = factory
+}
+
+func auditSinkNames() []string {
+	names := make([]string, 0, len(auditSinkFactories))
+	for name := range auditSinkFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openAuditSinkFromFlags sets the audit sink selected with --topo-audit-sink.
+// The records are written to it in the background, through a queue of
+// --topo-audit-queue-size records.
+func (ts *Server) openAuditSinkFromFlags() error {
+	if auditSinkName == "" {
+		return nil
+	}
+	if err := ts.SetAuditPaths(auditPaths); err != nil {
+		return err
+	}
+	ts.SetAuditOldValues(auditOldValues)
+	factory, ok := auditSinkFactories[auditSinkName]
+	if !ok {
+		return fmt.Errorf("unknown topo audit sink %q, expected one of: %s", auditSinkName, strings.Join(auditSinkNames(), ", "))
+	}
+	sink, err := factory(ts)
+	if err != nil {
+		return fmt.Errorf("failed to create topo audit sink %q: %w", auditSinkName, err)
+	}
+	ts.SetAuditSink(NewQueuedAuditSink(sink, auditQueueSize))
+	return nil
+}
+
+// QueuedAuditSink writes the records to another sink in the background, so
+// the mutations don't wait for them. The records are dropped when its
+// queue is full.
+type QueuedAuditSink struct {
+	sink  AuditSink
+	queue chan *AuditRecord
+	done  chan struct{}
+
+	// mu protects closed, so no record is queued once queue is closed.
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewQueuedAuditSink returns a sink writing to sink in the background,
+// with a queue of size records.
+func NewQueuedAuditSink(sink AuditSink, size int) *QueuedAuditSink {
+	qas := &QueuedAuditSink{
+		sink:  sink,
+		queue: make(chan *AuditRecord, size),
+		done:  make(chan struct{}),
+	}
+	go qas.run()
+	return qas
+}
+
+func (qas *QueuedAuditSink) run() {
+	defer close(qas.done)
+	for record := range qas.queue {
+		topoAuditQueueLength.Add(-1)
+		ctx, cancel := context.WithTimeout(withoutAudit(context.Background()), RemoteOperationTimeout)
+		if err := qas.sink.Write(ctx, record); err != nil {
+			topoAuditFailures.Add(1)
+			log.Errorf("failed to write topo audit record for %s %s in cell %s: %v", record.Operation, record.Path, record.Cell, err)
+		}
+		cancel()
+	}
+}
+
+// Write is part of the AuditSink interface. It returns an error if the
+// record can't be queued.
+func (qas *QueuedAuditSink) Write(ctx context.Context, record *AuditRecord) error {
+	qas.mu.Lock()
+	defer qas.mu.Unlock()
+	if qas.closed {
+		return fmt.Errorf("topo audit sink is closed")
+	}
+	select {
+	case qas.queue <- record:
+		topoAuditQueueLength.Add(1)
+		return nil
+	default:
+		return fmt.Errorf("topo audit queue is full")
+	}
+}
+
+// Close is part of the AuditSink interface. It waits for the queued
+// records to be written before closing the underlying sink.
+func (qas *QueuedAuditSink) Close() error {
+	qas.mu.Lock()
+	if qas.closed {
+		qas.mu.Unlock()
+		return nil
+	}
+	qas.closed = true
+	close(qas.queue)
+	qas.mu.Unlock()
+
+	<-qas.done
+	return qas.sink.Close()
+}
+
+// FileAuditSink appends the records to a file, one JSON document per line.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditSink returns a sink appending to the file at the given path,
+// which is created if needed.
+func NewFileAuditSink(filePath string) (*FileAuditSink, error) {
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: f}, nil
+}
+
+func newFileAuditSinkFromFlags(*Server) (AuditSink, error) {
+	if auditFile == "" {
+		return nil, fmt.Errorf("--topo-audit-file must be set")
+	}
+	return NewFileAuditSink(auditFile)
+}
+
+// Write is part of the AuditSink interface.
+func (fs *FileAuditSink) Write(ctx context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, err := fs.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return fs.file.Sync()
+}
+
+// Close is part of the AuditSink interface.
+func (fs *FileAuditSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.file.Close()
+}
+
+// auditRecordTimeFormat is the format of the time at the start of the
+// names of the files written by the topo audit sink.
+const auditRecordTimeFormat = "20060102T150405.000000000Z"
+
+// auditExpireInterval is how often the topo audit sink deletes its expired
+// records.
+const auditExpireInterval = time.Hour
+
+// TopoAuditSink writes each record as a JSON file under AuditPath in the
+// global cell. The file names sort in the order the records were written
+// by each process. Records older than the retention are deleted, by any
+// of the processes writing to the sink, at most every hour.
+type TopoAuditSink struct {
+	ts        *Server
+	id        string
+	retention time.Duration
+
+	// mu protects nextExpire.
+	mu         sync.Mutex
+	nextExpire time.Time
+}
+
+func newTopoAuditSink(ts *Server) (AuditSink, error) {
+	return NewTopoAuditSink(ts, auditRetention), nil
+}
+
+// NewTopoAuditSink returns a sink writing to the global cell of ts, which
+// keeps the records for the given retention, or forever if it is 0.
+func NewTopoAuditSink(ts *Server, retention time.Duration) *TopoAuditSink {
+	id := make([]byte, 4)
+	_, _ = rand.Read(id)
+	return &TopoAuditSink{
+		ts:        ts,
+		id:        hex.EncodeToString(id),
+		retention: retention,
+	}
+}
+
+// Write is part of the AuditSink interface.
+func (tas *TopoAuditSink) Write(ctx context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s", record.Time.UTC().Format(auditRecordTimeFormat), tas.id)
+	if _, err := tas.ts.globalCell.Create(withoutAudit(ctx), path.Join(AuditPath, name), data); err != nil {
+		return err
+	}
+
+	if tas.retention > 0 && tas.expireDue() {
+		if err := tas.Expire(ctx, time.Now().Add(-tas.retention)); err != nil {
+			log.Warningf("failed to delete expired topo audit records: %v", err)
+		}
+	}
+	return nil
+}
+
+// expireDue returns true if the expired records should be deleted now.
+func (tas *TopoAuditSink) expireDue() bool {
+	tas.mu.Lock()
+	defer tas.mu.Unlock()
+	now := time.Now()
+	if now.Before(tas.nextExpire) {
+		return false
+	}
+	tas.nextExpire = now.Add(auditExpireInterval)
+	return true
+}
+
+// Expire deletes the records written before the given time.
+func (tas *TopoAuditSink) Expire(ctx context.Context, before time.Time) error {
+	ctx = withoutAudit(ctx)
+	entries, err := tas.ts.globalCell.ListDir(ctx, AuditPath, false)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		recordTime, _, _ := strings.Cut(entry.Name, "-")
+		t, err := time.Parse(auditRecordTimeFormat, recordTime)
+		if err != nil || !t.Before(before) {
+			continue
+		}
+		// Another process may be deleting the same records.
+		if err := tas.ts.globalCell.Delete(ctx, path.Join(AuditPath, entry.Name), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is part of the AuditSink interface.
+func (tas *TopoAuditSink) Close() error {
+	return nil
+}
+
+// WebhookAuditSink posts each record to a URL as a JSON document.
+type WebhookAuditSink struct {
+	url     string
+	timeout time.Duration
+}
+
+// NewWebhookAuditSink returns a sink posting to url.
+func NewWebhookAuditSink(url string, timeout time.Duration) *WebhookAuditSink {
+	return &WebhookAuditSink{
+		url:     url,
+		timeout: timeout,
+	}
+}
+
+func newWebhookAuditSinkFromFlags(*Server) (AuditSink, error) {
+	if auditWebhookURL == "" {
+		return nil, fmt.Errorf("--topo-audit-webhook-url must be set")
+	}
+	return NewWebhookAuditSink(auditWebhookURL, auditWebhookTimeout), nil
+}
+
+// Write is part of the AuditSink interface.
+func (was *WebhookAuditSink) Write(ctx context.Context, record *AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, was.timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, was.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
+
+// Close is part of the AuditSink interface.
+func (was *WebhookAuditSink) Close() error {
+	return nil
+}
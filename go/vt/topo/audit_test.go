@@ -0,0 +1,319 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// fakeAuditSink keeps the records it is given in memory.
+type fakeAuditSink struct {
+	mu      sync.Mutex
+	records []*topo.AuditRecord
+	closed  bool
+}
+
+func (fas *fakeAuditSink) Write(ctx context.Context, record *topo.AuditRecord) error {
+	fas.mu.Lock()
+	defer fas.mu.Unlock()
+	fas.records = append(fas.records, record)
+	return nil
+}
+
+func (fas *fakeAuditSink) Close() error {
+	fas.mu.Lock()
+	defer fas.mu.Unlock()
+	fas.closed = true
+	return nil
+}
+
+func (fas *fakeAuditSink) take() []*topo.AuditRecord {
+	fas.mu.Lock()
+	defer fas.mu.Unlock()
+	records := fas.records
+	fas.records = nil
+	return records
+}
+
+func TestAuditMutations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// Nothing is audited without a sink.
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	sink := &fakeAuditSink{}
+	ts.SetAuditSink(sink)
+	ts.SetAuditOldValues(true)
+	require.NoError(t, ts.SetAuditPaths(append(topo.DefaultAuditPaths, "custom*")))
+
+	callerCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("alice", "", ""), nil)
+	reasonCtx := topo.WithAuditReason(callerCtx, "add t1")
+	require.NoError(t, ts.SaveVSchema(reasonCtx, &topo.KeyspaceVSchemaInfo{
+		Name: "ks",
+		Keyspace: &vschemapb.Keyspace{
+			Tables: map[string]*vschemapb.Table{"t1": {}},
+		},
+	}))
+	records := sink.take()
+	require.Len(t, records, 1)
+	assert.Equal(t, topo.GlobalCell, records[0].Cell)
+	assert.Equal(t, "keyspaces/ks/VSchema", records[0].Path)
+	assert.Equal(t, topo.AuditOperationCreate, records[0].Operation)
+	assert.Equal(t, "alice", records[0].Caller)
+	assert.Equal(t, "add t1", records[0].Reason)
+	assert.NotEmpty(t, records[0].Version)
+	assert.Nil(t, records[0].OldValue)
+	assert.JSONEq(t, `{"tables":{"t1":{}}}`, string(records[0].NewValue))
+
+	// Without an explicit reason, the actions of the locks held are used.
+	lockCtx, unlock, err := ts.LockKeyspace(callerCtx, "ks", "SetKeyspaceDurabilityPolicy")
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(lockCtx, "ks")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+	records = sink.take()
+	require.Len(t, records, 1)
+	assert.Equal(t, "keyspaces/ks/Keyspace", records[0].Path)
+	assert.Equal(t, topo.AuditOperationUpdate, records[0].Operation)
+	assert.Equal(t, "SetKeyspaceDurabilityPolicy", records[0].Reason)
+	assert.JSONEq(t, `{}`, string(records[0].OldValue))
+	assert.JSONEq(t, `{"durability_policy":"semi_sync"}`, string(records[0].NewValue))
+
+	// Cell files are audited with their cell, and files that aren't topo
+	// protobufs are shown as strings.
+	conn, err := ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "custom", []byte("value"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Delete(ctx, "custom", nil))
+	records = sink.take()
+	require.Len(t, records, 2)
+	assert.Equal(t, "zone1", records[0].Cell)
+	assert.JSONEq(t, `"value"`, string(records[0].NewValue))
+	assert.Equal(t, topo.AuditOperationDelete, records[1].Operation)
+	assert.JSONEq(t, `"value"`, string(records[1].OldValue))
+	assert.Nil(t, records[1].NewValue)
+
+	// Failed mutations aren't audited.
+	_, err = conn.Create(ctx, "custom2", []byte("value"))
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "custom2", []byte("value"))
+	require.Error(t, err)
+	assert.Len(t, sink.take(), 1)
+
+	ts.SetAuditSink(nil)
+	assert.True(t, sink.closed)
+	require.NoError(t, conn.Delete(ctx, "custom2", nil))
+	assert.Empty(t, sink.take())
+}
+
+func TestAuditPaths(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	sink := &fakeAuditSink{}
+	ts.SetAuditSink(sink)
+	defer ts.SetAuditSink(nil)
+
+	// The tablet records and the serving graph aren't audited by default,
+	// nor are the old values.
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+	tablet := &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_REPLICA,
+	}
+	require.NoError(t, ts.CreateTablet(ctx, tablet))
+	_, err := ts.UpdateTabletFields(ctx, tablet.Alias, func(tablet *topodatapb.Tablet) error {
+		tablet.Type = topodatapb.TabletType_RDONLY
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks", &topodatapb.SrvKeyspace{}))
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "SetKeyspaceDurabilityPolicy")
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(lockCtx, "ks")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, record := range sink.take() {
+		paths = append(paths, record.Operation+" "+record.Path)
+		assert.Nil(t, record.OldValue)
+	}
+	assert.Equal(t, []string{
+		"create keyspaces/ks/Keyspace",
+		"create keyspaces/ks/shards/0/Shard",
+		"update keyspaces/ks/Keyspace",
+	}, paths)
+
+	// A pattern naming a directory audits the files under it.
+	require.NoError(t, ts.SetAuditPaths([]string{"tablets"}))
+	require.NoError(t, ts.DeleteTablet(ctx, tablet.Alias))
+	records := sink.take()
+	require.Len(t, records, 1)
+	assert.Equal(t, "tablets/zone1-0000000100/Tablet", records[0].Path)
+
+	assert.Error(t, ts.SetAuditPaths([]string{"["}))
+}
+
+func TestAuditSinks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	t.Run("file", func(t *testing.T) {
+		auditFile := filepath.Join(t.TempDir(), "audit.log")
+		sink, err := topo.NewFileAuditSink(auditFile)
+		require.NoError(t, err)
+		ts.SetAuditSink(sink)
+		defer ts.SetAuditSink(nil)
+
+		require.NoError(t, ts.CreateKeyspace(ctx, "file", &topodatapb.Keyspace{}))
+		require.NoError(t, ts.DeleteKeyspace(ctx, "file"))
+
+		f, err := os.Open(auditFile)
+		require.NoError(t, err)
+		defer f.Close()
+		var operations []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record topo.AuditRecord
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			operations = append(operations, record.Operation+" "+record.Path)
+		}
+		assert.Equal(t, []string{"create keyspaces/file/Keyspace", "delete keyspaces/file/Keyspace"}, operations)
+	})
+
+	t.Run("topo", func(t *testing.T) {
+		sink := topo.NewTopoAuditSink(ts, time.Hour)
+		ts.SetAuditSink(sink)
+		defer ts.SetAuditSink(nil)
+
+		require.NoError(t, ts.CreateKeyspace(ctx, "topo", &topodatapb.Keyspace{}))
+
+		conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+		require.NoError(t, err)
+		entries, err := conn.ListDir(ctx, topo.AuditPath, false)
+		require.NoError(t, err)
+		// Writing the record doesn't audit itself.
+		require.Len(t, entries, 1)
+		data, _, err := conn.Get(ctx, topo.AuditPath+"/"+entries[0].Name)
+		require.NoError(t, err)
+		var record topo.AuditRecord
+		require.NoError(t, json.Unmarshal(data, &record))
+		assert.Equal(t, "keyspaces/topo/Keyspace", record.Path)
+
+		// Only the records written before the given time are deleted.
+		require.NoError(t, sink.Expire(ctx, record.Time))
+		entries, err = conn.ListDir(ctx, topo.AuditPath, false)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.NoError(t, sink.Expire(ctx, record.Time.Add(time.Nanosecond)))
+		_, err = conn.ListDir(ctx, topo.AuditPath, false)
+		assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		records := make(chan *topo.AuditRecord, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var record topo.AuditRecord
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+			records <- &record
+		}))
+		defer server.Close()
+		ts.SetAuditSink(topo.NewWebhookAuditSink(server.URL, 5*time.Second))
+		defer ts.SetAuditSink(nil)
+
+		require.NoError(t, ts.CreateKeyspace(ctx, "webhook", &topodatapb.Keyspace{}))
+		record := <-records
+		assert.Equal(t, "keyspaces/webhook/Keyspace", record.Path)
+		assert.Equal(t, topo.AuditOperationCreate, record.Operation)
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		blocked := make(chan struct{})
+		sink := &blockingAuditSink{unblock: blocked, started: make(chan struct{})}
+		queued := topo.NewQueuedAuditSink(sink, 1)
+		ts.SetAuditSink(queued)
+
+		// The first record is being written and the second one is queued,
+		// so the third one is dropped. None of them block the mutations.
+		require.NoError(t, ts.CreateKeyspace(ctx, "queued1", &topodatapb.Keyspace{}))
+		<-sink.started
+		require.NoError(t, ts.CreateKeyspace(ctx, "queued2", &topodatapb.Keyspace{}))
+		require.NoError(t, ts.CreateKeyspace(ctx, "queued3", &topodatapb.Keyspace{}))
+
+		// Closing the sink writes the queued records.
+		close(blocked)
+		ts.SetAuditSink(nil)
+		assert.True(t, sink.closed)
+		var paths []string
+		for _, record := range sink.take() {
+			paths = append(paths, record.Path)
+		}
+		assert.Equal(t, []string{"keyspaces/queued1/Keyspace", "keyspaces/queued2/Keyspace"}, paths)
+		assert.Error(t, queued.Write(ctx, &topo.AuditRecord{}))
+	})
+}
+
+// blockingAuditSink is a fakeAuditSink whose writes wait for unblock to be
+// closed. started is closed once the first write starts.
+type blockingAuditSink struct {
+	fakeAuditSink
+	unblock chan struct{}
+	started chan struct{}
+	once    sync.Once
+}
+
+func (bas *blockingAuditSink) Write(ctx context.Context, record *topo.AuditRecord) error {
+	bas.once.Do(func() { close(bas.started) })
+	<-bas.unblock
+	return bas.fakeAuditSink.Write(ctx, record)
+}
@@ -28,48 +28,52 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 )
 
-// DecodeContent uses the filename to imply a type, and proto-decodes
-// the right object, then echoes it as a string.
-func DecodeContent(filename string, data []byte, json bool) (string, error) {
+// ProtoForPath uses the filename to imply a type, and returns a new
+// empty object of that type, or nil if the file is not a known topo
+// protobuf.
+func ProtoForPath(filename string) proto.Message {
 	name := path.Base(filename)
 	dir := path.Dir(filename)
-	var p proto.Message
 	switch name {
 	case CellInfoFile:
-		p = new(topodatapb.CellInfo)
+		return new(topodatapb.CellInfo)
 	case KeyspaceFile:
-		p = new(topodatapb.Keyspace)
+		return new(topodatapb.Keyspace)
 	case ShardFile:
-		p = new(topodatapb.Shard)
+		return new(topodatapb.Shard)
 	case VSchemaFile:
-		p = new(vschemapb.Keyspace)
+		return new(vschemapb.Keyspace)
 	case ShardReplicationFile:
-		p = new(topodatapb.ShardReplication)
+		return new(topodatapb.ShardReplication)
 	case TabletFile:
-		p = new(topodatapb.Tablet)
+		return new(topodatapb.Tablet)
 	case SrvVSchemaFile:
-		p = new(vschemapb.SrvVSchema)
+		return new(vschemapb.SrvVSchema)
 	case SrvKeyspaceFile:
-		p = new(topodatapb.SrvKeyspace)
+		return new(topodatapb.SrvKeyspace)
 	case RoutingRulesFile:
-		p = new(vschemapb.RoutingRules)
+		return new(vschemapb.RoutingRules)
 	case CommonRoutingRulesFile:
-		switch path.Base(dir) {
-		case "keyspace":
-			p = new(vschemapb.KeyspaceRoutingRules)
+		if path.Base(dir) == "keyspace" {
+			return new(vschemapb.KeyspaceRoutingRules)
 		}
-	default:
-		switch dir {
-		case "/" + GetExternalVitessClusterDir():
-			p = new(topodatapb.ExternalVitessCluster)
-		default:
-		}
-		if p == nil {
-			if json {
-				return "", fmt.Errorf("unknown topo protobuf type for %v", name)
-			}
-			return string(data), nil
+		return nil
+	}
+	if dir == "/"+GetExternalVitessClusterDir() {
+		return new(topodatapb.ExternalVitessCluster)
+	}
+	return nil
+}
+
+// DecodeContent uses the filename to imply a type, and proto-decodes
+// the right object, then echoes it as a string.
+func DecodeContent(filename string, data []byte, json bool) (string, error) {
+	p := ProtoForPath(filename)
+	if p == nil {
+		if json {
+			return "", fmt.Errorf("unknown topo protobuf type for %v", path.Base(filename))
 		}
+		return string(data), nil
 	}
 
 	if err := proto.Unmarshal(data, p); err != nil {
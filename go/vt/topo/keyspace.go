@@ -21,11 +21,13 @@ import (
 	"path"
 	"sort"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -61,6 +63,46 @@ func (ki *KeyspaceInfo) SetKeyspaceName(name string) {
 	ki.keyspace = name
 }
 
+// VtorcRecoveryDisableExpired returns true if the given VTOrc recovery
+// disable has an expire time that is not after now.
+func VtorcRecoveryDisableExpired(disable *topodatapb.VtorcRecoveryDisable, now time.Time) bool {
+	if disable.ExpireTime == nil {
+		return false
+	}
+	return !protoutil.TimeFromProto(disable.ExpireTime).After(now)
+}
+
+// SetVtorcRecoveryDisable adds the given VTOrc recovery disable to the keyspace,
+// replacing any existing disable for the same shard and analysis code. Disables
+// that have expired by now are dropped.
+func (ki *KeyspaceInfo) SetVtorcRecoveryDisable(disable *topodatapb.VtorcRecoveryDisable, now time.Time) {
+	ki.RemoveVtorcRecoveryDisable(disable.Shard, disable.AnalysisCode, now)
+	ki.VtorcRecoveryDisables = append(ki.VtorcRecoveryDisables, disable)
+}
+
+// RemoveVtorcRecoveryDisable removes the VTOrc recovery disable for the given
+// shard and analysis code, along with any disables that have expired by now.
+// It returns true if a disable for the shard and analysis code was found.
+func (ki *KeyspaceInfo) RemoveVtorcRecoveryDisable(shard, analysisCode string, now time.Time) bool {
+	found := false
+	disables := ki.VtorcRecoveryDisables[:0]
+	for _, disable := range ki.VtorcRecoveryDisables {
+		if disable.Shard == shard && disable.AnalysisCode == analysisCode {
+			found = true
+			continue
+		}
+		if VtorcRecoveryDisableExpired(disable, now) {
+			continue
+		}
+		disables = append(disables, disable)
+	}
+	if len(disables) == 0 {
+		disables = nil
+	}
+	ki.VtorcRecoveryDisables = disables
+	return found
+}
+
 // ValidateKeyspaceName checks if the provided name is a valid name for a
 // keyspace.
 func ValidateKeyspaceName(name string) error {
@@ -124,18 +166,19 @@ func (ts *Server) GetKeyspace(ctx context.Context, keyspace string) (*KeyspaceIn
 	}, nil
 }
 
-// GetKeyspaceDurability reads the given keyspace and returns its durabilty policy
-func (ts *Server) GetKeyspaceDurability(ctx context.Context, keyspace string) (string, error) {
+// GetKeyspaceDurability reads the given keyspace and returns its durabilty policy,
+// along with the config of the policy if it is declared rather than named.
+func (ts *Server) GetKeyspaceDurability(ctx context.Context, keyspace string) (string, *topodatapb.DurabilityPolicyConfig, error) {
 	keyspaceInfo, err := ts.GetKeyspace(ctx, keyspace)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	// Get the durability policy from the keyspace information
 	// If it is unspecified, use the default durability which is "none" for backward compatibility
 	if keyspaceInfo.GetDurabilityPolicy() != "" {
-		return keyspaceInfo.GetDurabilityPolicy(), nil
+		return keyspaceInfo.GetDurabilityPolicy(), keyspaceInfo.GetDurabilityPolicyConfig(), nil
 	}
-	return "none", nil
+	return "none", nil, nil
 }
 
 func (ts *Server) GetSidecarDBName(ctx context.Context, keyspace string) (string, error) {
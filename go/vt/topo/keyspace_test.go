@@ -19,9 +19,11 @@ package topo_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/protoutil"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
@@ -169,3 +171,42 @@ func TestDeleteOrphanedKeyspaceFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestVtorcRecoveryDisables(t *testing.T) {
+	now := time.Now()
+	expired := &topodatapb.VtorcRecoveryDisable{
+		AnalysisCode: "ReplicationStopped",
+		ExpireTime:   protoutil.TimeToProto(now.Add(-time.Minute)),
+	}
+	active := &topodatapb.VtorcRecoveryDisable{
+		Shard:      "-80",
+		ExpireTime: protoutil.TimeToProto(now.Add(time.Minute)),
+	}
+	require.True(t, topo.VtorcRecoveryDisableExpired(expired, now))
+	require.False(t, topo.VtorcRecoveryDisableExpired(active, now))
+	require.False(t, topo.VtorcRecoveryDisableExpired(&topodatapb.VtorcRecoveryDisable{}, now))
+
+	ki := &topo.KeyspaceInfo{
+		Keyspace: &topodatapb.Keyspace{
+			VtorcRecoveryDisables: []*topodatapb.VtorcRecoveryDisable{expired, active},
+		},
+	}
+
+	// Setting a disable drops the expired ones and replaces the one for the same shard and analysis code.
+	replacement := &topodatapb.VtorcRecoveryDisable{
+		Shard:  "-80",
+		Reason: "replacement",
+	}
+	ki.SetVtorcRecoveryDisable(replacement, now)
+	require.Equal(t, []*topodatapb.VtorcRecoveryDisable{replacement}, ki.VtorcRecoveryDisables)
+
+	keyspaceWide := &topodatapb.VtorcRecoveryDisable{}
+	ki.SetVtorcRecoveryDisable(keyspaceWide, now)
+	require.Equal(t, []*topodatapb.VtorcRecoveryDisable{replacement, keyspaceWide}, ki.VtorcRecoveryDisables)
+
+	require.False(t, ki.RemoveVtorcRecoveryDisable("-80", "DeadPrimary", now))
+	require.True(t, ki.RemoveVtorcRecoveryDisable("-80", "", now))
+	require.Equal(t, []*topodatapb.VtorcRecoveryDisable{keyspaceWide}, ki.VtorcRecoveryDisables)
+	require.True(t, ki.RemoveVtorcRecoveryDisable("", "", now))
+	require.Nil(t, ki.VtorcRecoveryDisables)
+}
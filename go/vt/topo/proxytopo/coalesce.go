@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var (
+	proxyReads = stats.NewCountersWithMultiLabels(
+		"TopoProxyReads",
+		"Number of reads sent by the topo proxy to the topo server",
+		[]string{"Operation", "Cell"})
+
+	proxyCoalescedReads = stats.NewCountersWithMultiLabels(
+		"TopoProxyCoalescedReads",
+		"Number of reads served by the topo proxy with the result of an identical read of another client",
+		[]string{"Operation", "Cell"})
+)
+
+// readKey identifies identical reads.
+type readKey struct {
+	operation string
+	cell      string
+	path      string
+	// arg has the other arguments of the read, if any.
+	arg string
+}
+
+// coalescer runs the identical reads that arrive at the same time only
+// once. A read that arrives while the same read is running can't use its
+// result, since it may not see the changes made just before it arrived.
+// So it waits for the running read to finish, and then runs once for all
+// the reads that arrived in the meantime.
+type coalescer struct {
+	mu    sync.Mutex
+	reads map[readKey]*coalescedRead
+}
+
+// coalescedRead is the state of a read that is running.
+type coalescedRead struct {
+	running *readCall
+	// next is the read that runs after the running one, if any.
+	next *readCall
+}
+
+// readCall is one run of a read.
+type readCall struct {
+	read    func(ctx context.Context) (any, error)
+	waiters int
+	done    chan struct{}
+	result  any
+	err     error
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{
+		reads: make(map[readKey]*coalescedRead),
+	}
+}
+
+// do runs read, or waits for an identical read that runs after the call
+// to do. The read doesn't use ctx, since it's shared with other callers,
+// but do returns when ctx is done.
+func (c *coalescer) do(ctx context.Context, key readKey, read func(ctx context.Context) (any, error)) (any, error) {
+	c.mu.Lock()
+	cr, ok := c.reads[key]
+	var call *readCall
+	switch {
+	case !ok:
+		call = &readCall{read: read, done: make(chan struct{})}
+		c.reads[key] = &coalescedRead{running: call}
+		go c.run(key, call)
+	case cr.next == nil:
+		call = &readCall{read: read, done: make(chan struct{})}
+		cr.next = call
+	default:
+		call = cr.next
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run runs a read, and then the next one, until there is no next read.
+func (c *coalescer) run(key readKey, call *readCall) {
+	for call != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		proxyReads.Add([]string{key.operation, key.cell}, 1)
+		call.result, call.err = call.read(ctx)
+		cancel()
+
+		c.mu.Lock()
+		if call.waiters > 1 {
+			proxyCoalescedReads.Add([]string{key.operation, key.cell}, int64(call.waiters-1))
+		}
+		close(call.done)
+		cr := c.reads[key]
+		call = cr.next
+		if call == nil {
+			delete(c.reads, key)
+		} else {
+			cr.running, cr.next = call, nil
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// NewLeaderParticipation is part of the topo.Conn interface.
+// Leader elections aren't supported through the proxy.
+func (s *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	return nil, topo.NewError(topo.NoImplementation, "leader election through the topo proxy")
+}
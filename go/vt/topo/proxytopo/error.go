@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// errWatcherTooSlow is returned to the watchers that don't read the
+// changes fast enough.
+var errWatcherTooSlow = errors.New("watcher is too slow")
+
+// toGRPCError converts an error of the topo server behind the proxy into
+// a gRPC error.
+func toGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case topo.IsErrType(err, topo.NodeExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case topo.IsErrType(err, topo.NoNode):
+		return status.Error(codes.NotFound, err.Error())
+	case topo.IsErrType(err, topo.NodeNotEmpty):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case topo.IsErrType(err, topo.BadVersion):
+		return status.Error(codes.Aborted, err.Error())
+	case topo.IsErrType(err, topo.Timeout), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case topo.IsErrType(err, topo.Interrupted), errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case topo.IsErrType(err, topo.NoImplementation):
+		return status.Error(codes.Unimplemented, err.Error())
+	case topo.IsErrType(err, topo.ResourceExhausted), errors.Is(err, errWatcherTooSlow):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// convertError converts an error returned by the proxy into a topo error.
+func convertError(err error, nodePath string) error {
+	if err == nil {
+		return nil
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.AlreadyExists:
+			return topo.NewError(topo.NodeExists, nodePath)
+		case codes.NotFound:
+			return topo.NewError(topo.NoNode, nodePath)
+		case codes.FailedPrecondition:
+			return topo.NewError(topo.NodeNotEmpty, nodePath)
+		case codes.Aborted:
+			return topo.NewError(topo.BadVersion, nodePath)
+		case codes.Canceled:
+			return topo.NewError(topo.Interrupted, nodePath)
+		case codes.DeadlineExceeded, codes.Unavailable:
+			// Unavailable is returned when the proxy can't be reached,
+			// which is a timeout for the callers.
+			return topo.NewError(topo.Timeout, nodePath)
+		case codes.Unimplemented:
+			return topo.NewError(topo.NoImplementation, nodePath)
+		case codes.ResourceExhausted:
+			return topo.NewError(topo.ResourceExhausted, nodePath)
+		default:
+			return err
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return topo.NewError(topo.Interrupted, nodePath)
+	case errors.Is(err, context.DeadlineExceeded):
+		return topo.NewError(topo.Timeout, nodePath)
+	default:
+		return err
+	}
+}
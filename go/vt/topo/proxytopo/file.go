@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	topoproxydatapb "vitess.io/vitess/go/vt/proto/topoproxydata"
+)
+
+// Create is part of the topo.Conn interface.
+func (s *Server) Create(ctx context.Context, filePath string, contents []byte) (topo.Version, error) {
+	resp, err := s.client.Create(ctx, &topoproxydatapb.CreateRequest{
+		Cell:     s.cell,
+		Path:     filePath,
+		Contents: contents,
+	})
+	if err != nil {
+		return nil, convertError(err, filePath)
+	}
+	return ProxyVersion(resp.Version), nil
+}
+
+// Update is part of the topo.Conn interface.
+func (s *Server) Update(ctx context.Context, filePath string, contents []byte, version topo.Version) (topo.Version, error) {
+	resp, err := s.client.Update(ctx, &topoproxydatapb.UpdateRequest{
+		Cell:     s.cell,
+		Path:     filePath,
+		Contents: contents,
+		Version:  versionString(version),
+	})
+	if err != nil {
+		return nil, convertError(err, filePath)
+	}
+	return ProxyVersion(resp.Version), nil
+}
+
+// Get is part of the topo.Conn interface.
+func (s *Server) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	resp, err := s.client.Get(ctx, &topoproxydatapb.GetRequest{
+		Cell: s.cell,
+		Path: filePath,
+	})
+	if err != nil {
+		return nil, nil, convertError(err, filePath)
+	}
+	return resp.Contents, ProxyVersion(resp.Version), nil
+}
+
+// GetVersion is part of the topo.Conn interface.
+func (s *Server) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	resp, err := s.client.GetVersion(ctx, &topoproxydatapb.GetVersionRequest{
+		Cell:    s.cell,
+		Path:    filePath,
+		Version: version,
+	})
+	if err != nil {
+		return nil, convertError(err, filePath)
+	}
+	return resp.Contents, nil
+}
+
+// List is part of the topo.Conn interface.
+func (s *Server) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
+	resp, err := s.client.List(ctx, &topoproxydatapb.ListRequest{
+		Cell:       s.cell,
+		PathPrefix: filePathPrefix,
+	})
+	if err != nil {
+		return []topo.KVInfo{}, convertError(err, filePathPrefix)
+	}
+	results := make([]topo.KVInfo, len(resp.Kvs))
+	for n, kv := range resp.Kvs {
+		results[n].Key = kv.Key
+		results[n].Value = kv.Value
+		results[n].Version = ProxyVersion(kv.Version)
+	}
+	return results, nil
+}
+
+// Delete is part of the topo.Conn interface.
+func (s *Server) Delete(ctx context.Context, filePath string, version topo.Version) error {
+	_, err := s.client.Delete(ctx, &topoproxydatapb.DeleteRequest{
+		Cell:    s.cell,
+		Path:    filePath,
+		Version: versionString(version),
+	})
+	return convertError(err, filePath)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topoproxydatapb "vitess.io/vitess/go/vt/proto/topoproxydata"
+	topoproxyservicepb "vitess.io/vitess/go/vt/proto/topoproxyservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// TryLock is part of the topo.Conn interface.
+func (s *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	return s.lock(ctx, &topoproxydatapb.LockAcquire{
+		Path:     dirPath,
+		Contents: contents,
+		Type:     topoproxydatapb.LockAcquire_NON_BLOCKING,
+	})
+}
+
+// Lock is part of the topo.Conn interface.
+func (s *Server) Lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	return s.lock(ctx, &topoproxydatapb.LockAcquire{
+		Path:     dirPath,
+		Contents: contents,
+		Type:     topoproxydatapb.LockAcquire_BLOCKING,
+	})
+}
+
+// LockWithTTL is part of the topo.Conn interface.
+func (s *Server) LockWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	return s.lock(ctx, &topoproxydatapb.LockAcquire{
+		Path:     dirPath,
+		Contents: contents,
+		Type:     topoproxydatapb.LockAcquire_BLOCKING,
+		TtlMs:    ttl.Milliseconds(),
+	})
+}
+
+// LockName is part of the topo.Conn interface.
+func (s *Server) LockName(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	return s.lock(ctx, &topoproxydatapb.LockAcquire{
+		Path:     dirPath,
+		Contents: contents,
+		Type:     topoproxydatapb.LockAcquire_NAMED,
+	})
+}
+
+// lock takes the lock through a Lock stream. The proxy holds the lock
+// until the stream ends, so the stream doesn't use ctx, which is only
+// used to wait for the lock.
+func (s *Server) lock(ctx context.Context, acquire *topoproxydatapb.LockAcquire) (topo.LockDescriptor, error) {
+	acquire.Cell = s.cell
+	if deadline, ok := ctx.Deadline(); ok {
+		acquire.TimeoutMs = max(time.Until(deadline).Milliseconds(), 1)
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, streamCancel)
+	stream, err := s.client.Lock(streamCtx)
+	if err == nil {
+		err = send(stream, &topoproxydatapb.LockRequest{Acquire: acquire})
+	}
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if !stop() {
+		// ctx is done, and the stream was canceled.
+		err = ctx.Err()
+	}
+	if err != nil {
+		streamCancel()
+		return nil, convertError(err, acquire.Path)
+	}
+	return &proxyLockDescriptor{
+		stream: stream,
+		cancel: streamCancel,
+		path:   acquire.Path,
+	}, nil
+}
+
+// send sends a request on a Lock stream. When the stream is broken, Send
+// returns io.EOF, and the error of the stream is returned by Recv.
+func send(stream topoproxyservicepb.TopoProxy_LockClient, req *topoproxydatapb.LockRequest) error {
+	err := stream.Send(req)
+	if err == io.EOF {
+		_, err = stream.Recv()
+	}
+	return err
+}
+
+// errUnlocked is returned by roundTrip once the lock was released.
+var errUnlocked = errors.New("lock already released")
+
+// proxyLockDescriptor implements topo.LockDescriptor.
+type proxyLockDescriptor struct {
+	// mu serializes the requests on the stream.
+	mu     sync.Mutex
+	stream topoproxyservicepb.TopoProxy_LockClient
+	cancel context.CancelFunc
+	path   string
+	// unlocked is set once Unlock was called.
+	unlocked bool
+}
+
+// Check is part of the topo.LockDescriptor interface.
+func (ld *proxyLockDescriptor) Check(ctx context.Context) error {
+	err := ld.roundTrip(ctx, &topoproxydatapb.LockRequest{Check: true})
+	if err == io.EOF || err == errUnlocked {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "lock %v is not held by the topo proxy anymore", ld.path)
+	}
+	return convertError(err, ld.path)
+}
+
+// Unlock is part of the topo.LockDescriptor interface.
+func (ld *proxyLockDescriptor) Unlock(ctx context.Context) error {
+	defer ld.cancel()
+
+	// The proxy ends the stream once the lock is released.
+	err := ld.roundTrip(ctx, &topoproxydatapb.LockRequest{Unlock: true})
+	switch err {
+	case io.EOF:
+		return nil
+	case errUnlocked:
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "lock %v was already released", ld.path)
+	}
+	return convertError(err, ld.path)
+}
+
+// roundTrip sends a request and waits for its response. The stream is
+// canceled if ctx is done first, which releases the lock.
+func (ld *proxyLockDescriptor) roundTrip(ctx context.Context, req *topoproxydatapb.LockRequest) error {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	if ld.unlocked {
+		return errUnlocked
+	}
+	ld.unlocked = req.Unlock
+	stop := context.AfterFunc(ctx, ld.cancel)
+	defer stop()
+	if err := send(ld.stream, req); err != nil {
+		return err
+	}
+	_, err := ld.stream.Recv()
+	return err
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+
+	topoproxydatapb "vitess.io/vitess/go/vt/proto/topoproxydata"
+	topoproxyservicepb "vitess.io/vitess/go/vt/proto/topoproxyservice"
+)
+
+// Proxy serves the topo.Conn API of all the cells of a topo server to the
+// proxytopo clients.
+type Proxy struct {
+	topoproxyservicepb.UnimplementedTopoProxyServer
+
+	ts    *topo.Server
+	reads *coalescer
+
+	mu      sync.Mutex
+	watches map[watchKey]*sharedWatch
+}
+
+// NewProxy returns a Proxy for the given topo server.
+func NewProxy(ts *topo.Server) *Proxy {
+	return &Proxy{
+		ts:      ts,
+		reads:   newCoalescer(),
+		watches: make(map[watchKey]*sharedWatch),
+	}
+}
+
+// StartProxy registers a Proxy for the given topo server with the gRPC
+// server.
+func StartProxy(s *grpc.Server, ts *topo.Server) {
+	topoproxyservicepb.RegisterTopoProxyServer(s, NewProxy(ts))
+}
+
+// ListDir is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) ListDir(ctx context.Context, req *topoproxydatapb.ListDirRequest) (*topoproxydatapb.ListDirResponse, error) {
+	key := readKey{operation: "ListDir", cell: req.Cell, path: req.Path}
+	if req.Full {
+		key.arg = "full"
+	}
+	result, err := p.reads.do(ctx, key, func(ctx context.Context) (any, error) {
+		conn, err := p.ts.ConnForCell(ctx, req.Cell)
+		if err != nil {
+			return nil, err
+		}
+		return conn.ListDir(ctx, req.Path, req.Full)
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	entries := result.([]topo.DirEntry)
+	resp := &topoproxydatapb.ListDirResponse{
+		Entries: make([]*topoproxydatapb.DirEntry, len(entries)),
+	}
+	for i, e := range entries {
+		resp.Entries[i] = &topoproxydatapb.DirEntry{
+			Name:      e.Name,
+			Ephemeral: e.Ephemeral,
+		}
+		if e.Type == topo.TypeFile {
+			resp.Entries[i].Type = topoproxydatapb.DirEntry_FILE
+		}
+	}
+	return resp, nil
+}
+
+// Create is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) Create(ctx context.Context, req *topoproxydatapb.CreateRequest) (*topoproxydatapb.CreateResponse, error) {
+	conn, err := p.ts.ConnForCell(ctx, req.Cell)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	version, err := conn.Create(ctx, req.Path, req.Contents)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &topoproxydatapb.CreateResponse{Version: version.String()}, nil
+}
+
+// Update is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) Update(ctx context.Context, req *topoproxydatapb.UpdateRequest) (*topoproxydatapb.UpdateResponse, error) {
+	conn, err := p.ts.ConnForCell(ctx, req.Cell)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	current, err := currentVersion(ctx, conn, req.Path, req.Version)
+	if topo.IsErrType(err, topo.NoNode) {
+		// An update of a version of a file that doesn't exist fails like
+		// an update of the wrong version.
+		err = topo.NewError(topo.BadVersion, req.Path)
+	}
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	version, err := conn.Update(ctx, req.Path, req.Contents, current)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &topoproxydatapb.UpdateResponse{Version: version.String()}, nil
+}
+
+// Get is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) Get(ctx context.Context, req *topoproxydatapb.GetRequest) (*topoproxydatapb.GetResponse, error) {
+	result, err := p.reads.do(ctx, readKey{operation: "Get", cell: req.Cell, path: req.Path}, func(ctx context.Context) (any, error) {
+		conn, err := p.ts.ConnForCell(ctx, req.Cell)
+		if err != nil {
+			return nil, err
+		}
+		contents, version, err := conn.Get(ctx, req.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &topoproxydatapb.GetResponse{Contents: contents, Version: version.String()}, nil
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return result.(*topoproxydatapb.GetResponse), nil
+}
+
+// GetVersion is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) GetVersion(ctx context.Context, req *topoproxydatapb.GetVersionRequest) (*topoproxydatapb.GetVersionResponse, error) {
+	conn, err := p.ts.ConnForCell(ctx, req.Cell)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	contents, err := conn.GetVersion(ctx, req.Path, req.Version)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &topoproxydatapb.GetVersionResponse{Contents: contents}, nil
+}
+
+// List is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) List(ctx context.Context, req *topoproxydatapb.ListRequest) (*topoproxydatapb.ListResponse, error) {
+	result, err := p.reads.do(ctx, readKey{operation: "List", cell: req.Cell, path: req.PathPrefix}, func(ctx context.Context) (any, error) {
+		conn, err := p.ts.ConnForCell(ctx, req.Cell)
+		if err != nil {
+			return nil, err
+		}
+		kvs, err := conn.List(ctx, req.PathPrefix)
+		if err != nil {
+			return nil, err
+		}
+		resp := &topoproxydatapb.ListResponse{
+			Kvs: make([]*topoproxydatapb.KeyValue, len(kvs)),
+		}
+		for i, kv := range kvs {
+			resp.Kvs[i] = &topoproxydatapb.KeyValue{
+				Key:     kv.Key,
+				Value:   kv.Value,
+				Version: kv.Version.String(),
+			}
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return result.(*topoproxydatapb.ListResponse), nil
+}
+
+// Delete is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) Delete(ctx context.Context, req *topoproxydatapb.DeleteRequest) (*topoproxydatapb.DeleteResponse, error) {
+	conn, err := p.ts.ConnForCell(ctx, req.Cell)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	current, err := currentVersion(ctx, conn, req.Path, req.Version)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	if err := conn.Delete(ctx, req.Path, current); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &topoproxydatapb.DeleteResponse{}, nil
+}
+
+// currentVersion returns the current version of a file, if its text
+// representation is the expected one. The clients only have the text
+// representation of the versions, while the topo server needs its own
+// version type. The topo server still checks that the version is current
+// when the file is changed. It returns nil for an empty version.
+func currentVersion(ctx context.Context, conn topo.Conn, filePath, version string) (topo.Version, error) {
+	if version == "" {
+		return nil, nil
+	}
+	_, current, err := conn.Get(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if current.String() != version {
+		return nil, topo.NewError(topo.BadVersion, filePath)
+	}
+	return current, nil
+}
+
+// Lock is part of the topoproxyservicepb.TopoProxyServer interface.
+// The lock is held until the client unlocks it, or the stream ends.
+func (p *Proxy) Lock(stream topoproxyservicepb.TopoProxy_LockServer) error {
+	ctx := stream.Context()
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	acquire := req.Acquire
+	if acquire == nil {
+		return status.Error(codes.InvalidArgument, "the first request of a lock stream must acquire the lock")
+	}
+
+	ld, err := p.acquireLock(ctx, acquire)
+	if err != nil {
+		return toGRPCError(err)
+	}
+	unlocked := false
+	defer func() {
+		if unlocked {
+			return
+		}
+		// The client went away, release the lock.
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		defer cancel()
+		if err := ld.Unlock(ctx); err != nil {
+			log.Warningf("Failed to release the lock on %v in cell %v: %v", acquire.Path, acquire.Cell, err)
+		}
+	}()
+	if err := stream.Send(&topoproxydatapb.LockResponse{}); err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch {
+		case req.Check:
+			if err := ld.Check(ctx); err != nil {
+				return toGRPCError(err)
+			}
+			if err := stream.Send(&topoproxydatapb.LockResponse{}); err != nil {
+				return err
+			}
+		case req.Unlock:
+			unlocked = true
+			return toGRPCError(ld.Unlock(ctx))
+		default:
+			return status.Error(codes.InvalidArgument, "the lock is already acquired")
+		}
+	}
+}
+
+// acquireLock takes the lock requested by a client.
+func (p *Proxy) acquireLock(ctx context.Context, acquire *topoproxydatapb.LockAcquire) (topo.LockDescriptor, error) {
+	conn, err := p.ts.ConnForCell(ctx, acquire.Cell)
+	if err != nil {
+		return nil, err
+	}
+	if acquire.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(acquire.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+	switch acquire.Type {
+	case topoproxydatapb.LockAcquire_NON_BLOCKING:
+		return conn.TryLock(ctx, acquire.Path, acquire.Contents)
+	case topoproxydatapb.LockAcquire_NAMED:
+		return conn.LockName(ctx, acquire.Path, acquire.Contents)
+	default:
+		if acquire.TtlMs > 0 {
+			return conn.LockWithTTL(ctx, acquire.Path, acquire.Contents, time.Duration(acquire.TtlMs)*time.Millisecond)
+		}
+		return conn.Lock(ctx, acquire.Path, acquire.Contents)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/test"
+)
+
+// startProxy starts a Proxy for the given topo server, and returns its
+// address.
+func startProxy(t *testing.T, ts *topo.Server) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	StartProxy(s, ts)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+	return listener.Addr().String()
+}
+
+// openClient opens a topo server that goes through the proxy.
+func openClient(t *testing.T, proxyAddr string) *topo.Server {
+	ts, err := topo.NewWithFactory(&Factory{}, proxyAddr, "/vitess/global")
+	require.NoError(t, err)
+	return ts
+}
+
+func TestProxyTopo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	test.TopoServerTestSuite(t, ctx, func() *topo.Server {
+		upstream := memorytopo.NewServer(ctx, test.LocalCellName)
+		return openClient(t, startProxy(t, upstream))
+	}, []string{"checkTryLock", "checkShardWithLock", "checkElection", "checkWaitForNewLeader"})
+}
+
+func TestProxySharedWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	upstream, factory := memorytopo.NewServerAndFactory(ctx, test.LocalCellName)
+	proxyAddr := startProxy(t, upstream)
+	upstreamConn, err := upstream.ConnForCell(ctx, test.LocalCellName)
+	require.NoError(t, err)
+	_, err = upstreamConn.Create(ctx, "file", []byte("a"))
+	require.NoError(t, err)
+
+	// All the clients share the same watch of the topo server.
+	var allChanges []<-chan *topo.WatchData
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	for range 3 {
+		conn, err := openClient(t, proxyAddr).ConnForCell(ctx, test.LocalCellName)
+		require.NoError(t, err)
+		current, changes, err := conn.Watch(watchCtx, "file")
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(current.Contents))
+		allChanges = append(allChanges, changes)
+	}
+	assert.EqualValues(t, 1, factory.GetCallStats().Counts()["Watch"])
+
+	_, err = upstreamConn.Update(ctx, "file", []byte("b"), nil)
+	require.NoError(t, err)
+	for _, changes := range allChanges {
+		wd := <-changes
+		require.NoError(t, wd.Err)
+		assert.Equal(t, "b", string(wd.Contents))
+	}
+
+	// The watch of the topo server stops with the last client.
+	watchCancel()
+	for _, changes := range allChanges {
+		for wd := range changes {
+			assert.True(t, topo.IsErrType(wd.Err, topo.Interrupted), "unexpected error: %v", wd.Err)
+		}
+	}
+	assert.Eventually(t, func() bool {
+		return proxyWatches.Counts()["Watch.test"] == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A new client starts a new watch, with the current value.
+	conn, err := openClient(t, proxyAddr).ConnForCell(ctx, test.LocalCellName)
+	require.NoError(t, err)
+	current, _, err := conn.Watch(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(current.Contents))
+	assert.EqualValues(t, 2, factory.GetCallStats().Counts()["Watch"])
+}
+
+func TestProxyWatchDeletedFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	upstream := memorytopo.NewServer(ctx, test.LocalCellName)
+	proxyAddr := startProxy(t, upstream)
+	upstreamConn, err := upstream.ConnForCell(ctx, test.LocalCellName)
+	require.NoError(t, err)
+	_, err = upstreamConn.Create(ctx, "dir/file", []byte("a"))
+	require.NoError(t, err)
+	// The memory topo removes the watches of the directories that become
+	// empty, so keep another file in it.
+	_, err = upstreamConn.Create(ctx, "dir/other", []byte("a"))
+	require.NoError(t, err)
+
+	conn, err := openClient(t, proxyAddr).ConnForCell(ctx, test.LocalCellName)
+	require.NoError(t, err)
+	_, changes, err := conn.Watch(ctx, "dir/file")
+	require.NoError(t, err)
+	initial, recursiveChanges, err := conn.WatchRecursive(ctx, "dir")
+	require.NoError(t, err)
+	require.Len(t, initial, 2)
+	assert.Equal(t, "a", string(initial[0].Contents))
+
+	require.NoError(t, upstreamConn.Delete(ctx, "dir/file", nil))
+
+	// The watch of the file stops, the recursive watch goes on.
+	wd := <-changes
+	assert.True(t, topo.IsErrType(wd.Err, topo.NoNode), "unexpected error: %v", wd.Err)
+	_, ok := <-changes
+	assert.False(t, ok)
+	wdr := <-recursiveChanges
+	assert.True(t, topo.IsErrType(wdr.Err, topo.NoNode), "unexpected error: %v", wdr.Err)
+
+	_, err = upstreamConn.Create(ctx, "dir/file", []byte("b"))
+	require.NoError(t, err)
+	wdr = <-recursiveChanges
+	require.NoError(t, wdr.Err)
+	assert.Equal(t, "b", string(wdr.Contents))
+}
+
+func TestProxyLockReleasedWithClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	upstream := memorytopo.NewServer(ctx, test.LocalCellName)
+	proxyAddr := startProxy(t, upstream)
+	_, err := upstream.GetOrCreateShard(ctx, "ks", "0")
+	require.NoError(t, err)
+
+	client := openClient(t, proxyAddr)
+	_, _, err = client.LockShard(ctx, "ks", "0", "test")
+	require.NoError(t, err)
+
+	// The lock is released when the client goes away without unlocking.
+	client.Close()
+	lockCtx, lockCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer lockCancel()
+	_, unlock, err := openClient(t, proxyAddr).LockShard(lockCtx, "ks", "0", "test")
+	require.NoError(t, err)
+	var unlockErr error
+	unlock(&unlockErr)
+	require.NoError(t, unlockErr)
+}
+
+func TestCoalescer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newCoalescer()
+	key := readKey{operation: "Get", cell: "test", path: "file"}
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	read := func(ctx context.Context) (any, error) {
+		n := runs.Add(1)
+		<-release
+		return n, nil
+	}
+
+	// The first read runs right away.
+	var wg sync.WaitGroup
+	results := make(chan any, 10)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result, err := c.do(ctx, key, read)
+		assert.NoError(t, err)
+		results <- result
+	}()
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// The reads that arrive while it runs share the next run.
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := c.do(ctx, key, read)
+			assert.NoError(t, err)
+			results <- result
+		}()
+	}
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.reads[key].next != nil && c.reads[key].next.waiters == 5
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	counts := make(map[any]int)
+	for result := range results {
+		counts[result]++
+	}
+	assert.Equal(t, map[any]int{int32(1): 1, int32(2): 5}, counts)
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.reads) == 0
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+
+	topoproxydatapb "vitess.io/vitess/go/vt/proto/topoproxydata"
+	topoproxyservicepb "vitess.io/vitess/go/vt/proto/topoproxyservice"
+)
+
+// watchBufferSize is the number of changes buffered for each client of a
+// shared watch. The clients that fall further behind are disconnected.
+const watchBufferSize = 100
+
+var (
+	proxyWatches = stats.NewGaugesWithMultiLabels(
+		"TopoProxyWatches",
+		"Number of watches of the topo server shared by the topo proxy clients",
+		[]string{"Type", "Cell"})
+
+	proxyWatchClients = stats.NewGaugesWithMultiLabels(
+		"TopoProxyWatchClients",
+		"Number of topo proxy clients of the shared watches",
+		[]string{"Type", "Cell"})
+)
+
+// watchKey identifies a shared watch.
+type watchKey struct {
+	cell      string
+	path      string
+	recursive bool
+}
+
+// statsLabels returns the labels of the watch metrics.
+func (key watchKey) statsLabels() []string {
+	if key.recursive {
+		return []string{"WatchRecursive", key.cell}
+	}
+	return []string{"Watch", key.cell}
+}
+
+// sharedWatch is a watch of the topo server shared by all the clients
+// watching the same path. The watch of the topo server is started by the
+// first client, and stopped when the last client goes away.
+type sharedWatch struct {
+	key watchKey
+
+	mu sync.Mutex
+	// cancel stops the watch of the topo server. It is nil until the
+	// watch is started.
+	cancel context.CancelFunc
+	// stopped is set once the watch is removed from the proxy. A new
+	// watch is then started for the new clients.
+	stopped bool
+	// files has the current values of the watched files, by path. A
+	// watch of a single file has a single entry with an empty path.
+	files   map[string]*topo.WatchDataRecursive
+	clients map[*watchClient]bool
+}
+
+// watchClient is a client of a shared watch.
+type watchClient struct {
+	changes chan *topo.WatchDataRecursive
+	// done is closed when the shared watch stops with err.
+	done chan struct{}
+	err  error
+}
+
+// next returns the next change, or the error that stopped the watch.
+func (c *watchClient) next(ctx context.Context) (*topo.WatchDataRecursive, error) {
+	select {
+	case wd := <-c.changes:
+		return wd, nil
+	case <-c.done:
+		return nil, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Watch is part of the topoproxyservicepb.TopoProxyServer interface.
+func (p *Proxy) Watch(req *topoproxydatapb.WatchRequest, stream topoproxyservicepb.TopoProxy_WatchServer) error {
+	ctx := stream.Context()
+	w, client, initial, err := p.subscribe(ctx, watchKey{cell: req.Cell, path: req.Path})
+	if err != nil {
+		return toGRPCError(err)
+	}
+	defer p.unsubscribe(w, client)
+
+	for _, wd := range initial {
+		if err := stream.Send(&topoproxydatapb.WatchResponse{
+			Contents: wd.Contents,
+			Version:  wd.Version.String(),
+		}); err != nil {
+			return err
+		}
+	}
+	for {
+		wd, err := client.next(ctx)
+		if err != nil {
+			return toGRPCError(err)
+		}
+		if err := stream.Send(&topoproxydatapb.WatchResponse{
+			Contents: wd.Contents,
+			Version:  wd.Version.String(),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// WatchRecursive is part of the topoproxyservicepb.TopoProxyServer
+// interface.
+func (p *Proxy) WatchRecursive(req *topoproxydatapb.WatchRecursiveRequest, stream topoproxyservicepb.TopoProxy_WatchRecursiveServer) error {
+	ctx := stream.Context()
+	w, client, initial, err := p.subscribe(ctx, watchKey{cell: req.Cell, path: req.Path, recursive: true})
+	if err != nil {
+		return toGRPCError(err)
+	}
+	defer p.unsubscribe(w, client)
+
+	// The first response is sent even if there are no files, so that the
+	// client knows the watch started.
+	resp := &topoproxydatapb.WatchRecursiveResponse{}
+	for _, wd := range initial {
+		resp.Events = append(resp.Events, toWatchRecursiveEvent(wd))
+	}
+	for {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+
+		wd, err := client.next(ctx)
+		if err != nil {
+			return toGRPCError(err)
+		}
+		resp = &topoproxydatapb.WatchRecursiveResponse{
+			Events: []*topoproxydatapb.WatchRecursiveEvent{toWatchRecursiveEvent(wd)},
+		}
+		// Send the changes that are already buffered at once.
+		for len(resp.Events) < watchBufferSize && len(client.changes) > 0 {
+			resp.Events = append(resp.Events, toWatchRecursiveEvent(<-client.changes))
+		}
+	}
+}
+
+// toWatchRecursiveEvent converts a change of a recursive watch.
+func toWatchRecursiveEvent(wd *topo.WatchDataRecursive) *topoproxydatapb.WatchRecursiveEvent {
+	if wd.Err != nil {
+		// Only deletions are sent to the clients.
+		return &topoproxydatapb.WatchRecursiveEvent{
+			Path:    wd.Path,
+			Deleted: true,
+		}
+	}
+	return &topoproxydatapb.WatchRecursiveEvent{
+		Path:     wd.Path,
+		Contents: wd.Contents,
+		Version:  wd.Version.String(),
+	}
+}
+
+// subscribe adds a client to the shared watch of the given path, and
+// starts the watch of the topo server if it's the first client. It
+// returns the current values of the watched files.
+func (p *Proxy) subscribe(ctx context.Context, key watchKey) (*sharedWatch, *watchClient, []*topo.WatchDataRecursive, error) {
+	for {
+		p.mu.Lock()
+		w, ok := p.watches[key]
+		if !ok {
+			w = &sharedWatch{
+				key:     key,
+				clients: make(map[*watchClient]bool),
+			}
+			p.watches[key] = w
+		}
+		p.mu.Unlock()
+
+		w.mu.Lock()
+		if w.stopped {
+			// The watch stopped since we got it, try again.
+			w.mu.Unlock()
+			continue
+		}
+		if w.cancel == nil {
+			if err := p.startWatch(ctx, w); err != nil {
+				p.stopWatchLocked(w)
+				w.mu.Unlock()
+				return nil, nil, nil, err
+			}
+		}
+
+		client := &watchClient{
+			changes: make(chan *topo.WatchDataRecursive, watchBufferSize),
+			done:    make(chan struct{}),
+		}
+		w.clients[client] = true
+		proxyWatchClients.Add(key.statsLabels(), 1)
+		initial := make([]*topo.WatchDataRecursive, 0, len(w.files))
+		for _, wd := range w.files {
+			initial = append(initial, wd)
+		}
+		w.mu.Unlock()
+
+		sort.Slice(initial, func(i, j int) bool { return initial[i].Path < initial[j].Path })
+		return w, client, initial, nil
+	}
+}
+
+// startWatch starts the watch of the topo server, and the goroutine that
+// sends its changes to the clients. w.mu must be held.
+func (p *Proxy) startWatch(ctx context.Context, w *sharedWatch) error {
+	conn, err := p.ts.ConnForCell(ctx, w.key.cell)
+	if err != nil {
+		return err
+	}
+
+	// The watch is shared, so it doesn't use the context of the client.
+	watchCtx, cancel := context.WithCancel(context.Background())
+	w.files = make(map[string]*topo.WatchDataRecursive)
+	if w.key.recursive {
+		initial, changes, err := conn.WatchRecursive(watchCtx, w.key.path)
+		if err != nil {
+			cancel()
+			return err
+		}
+		for _, wd := range initial {
+			w.files[wd.Path] = wd
+		}
+		go p.forwardChanges(w, changes)
+	} else {
+		current, changes, err := conn.Watch(watchCtx, w.key.path)
+		if err != nil {
+			cancel()
+			return err
+		}
+		w.files[""] = &topo.WatchDataRecursive{WatchData: *current}
+		recursiveChanges := make(chan *topo.WatchDataRecursive)
+		go func() {
+			defer close(recursiveChanges)
+			for wd := range changes {
+				recursiveChanges <- &topo.WatchDataRecursive{WatchData: *wd}
+			}
+		}()
+		go p.forwardChanges(w, recursiveChanges)
+	}
+	w.cancel = cancel
+	proxyWatches.Add(w.key.statsLabels(), 1)
+	return nil
+}
+
+// forwardChanges sends the changes of the watch of the topo server to the
+// clients, until the watch stops.
+func (p *Proxy) forwardChanges(w *sharedWatch, changes <-chan *topo.WatchDataRecursive) {
+	for wd := range changes {
+		w.mu.Lock()
+		if w.stopped {
+			// The last client went away, drain the changes.
+			w.mu.Unlock()
+			continue
+		}
+
+		if wd.Err != nil && (!w.key.recursive || !topo.IsErrType(wd.Err, topo.NoNode) || wd.Path == "") {
+			// The watch of the topo server stopped, which also
+			// stops the watch of the clients.
+			for client := range w.clients {
+				client.err = wd.Err
+				close(client.done)
+			}
+			p.stopWatchLocked(w)
+			w.mu.Unlock()
+			continue
+		}
+
+		if wd.Err != nil {
+			// A file under the watched path was deleted.
+			delete(w.files, wd.Path)
+		} else {
+			w.files[wd.Path] = wd
+		}
+		for client := range w.clients {
+			select {
+			case client.changes <- wd:
+			default:
+				// The client is too slow, disconnect it.
+				client.err = errWatcherTooSlow
+				close(client.done)
+				p.removeClientLocked(w, client)
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+// unsubscribe removes a client from a shared watch.
+func (p *Proxy) unsubscribe(w *sharedWatch, client *watchClient) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p.removeClientLocked(w, client)
+}
+
+// removeClientLocked removes a client from a shared watch, and stops the
+// watch if it was the last client. w.mu must be held.
+func (p *Proxy) removeClientLocked(w *sharedWatch, client *watchClient) {
+	if !w.clients[client] {
+		return
+	}
+	delete(w.clients, client)
+	proxyWatchClients.Add(w.key.statsLabels(), -1)
+	if len(w.clients) == 0 {
+		p.stopWatchLocked(w)
+	}
+}
+
+// stopWatchLocked removes a shared watch from the proxy, and stops the
+// watch of the topo server. w.mu must be held.
+func (p *Proxy) stopWatchLocked(w *sharedWatch) {
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	for client := range w.clients {
+		delete(w.clients, client)
+		proxyWatchClients.Add(w.key.statsLabels(), -1)
+	}
+	if w.cancel != nil {
+		w.cancel()
+		proxyWatches.Add(w.key.statsLabels(), -1)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.watches[w.key] == w {
+		delete(p.watches, w.key)
+	}
+}
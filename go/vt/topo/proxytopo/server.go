@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package proxytopo implements a caching topo proxy, and the topo.Server
+implementation the clients use to connect through it.
+
+The proxy serves the topo.Conn API of all the cells of its own topo
+server over gRPC. It shares one watch of the topo server between all the
+clients watching the same path, and coalesces the identical reads that
+run at the same time, so that large fleets of vttablets and vtgates don't
+overload the topo server.
+
+The clients use --topo_implementation proxy, with the address of the
+proxy as the global topo server address. The connections to the other
+cells go through the same proxy: the cell addresses and roots found in
+the global topo server are only used by the proxy.
+
+Like etcd2topo, we follow these conventions within this package:
+
+  - Call convertError(err) on any errors returned by the proxy.
+    Functions defined in this package can be assumed to have already
+    converted errors as necessary.
+*/
+package proxytopo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topoproxyservicepb "vitess.io/vitess/go/vt/proto/topoproxyservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	clientCertPath string
+	clientKeyPath  string
+	serverCAPath   string
+	serverName     string
+)
+
+func init() {
+	for _, cmd := range topo.FlagBinaries {
+		servenv.OnParseFor(cmd, registerProxyTopoFlags)
+	}
+	topo.RegisterFactory("proxy", &Factory{})
+}
+
+func registerProxyTopoFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&clientCertPath, "topo-proxy-tls-cert", clientCertPath, "The client certificate to use to connect to the topo proxy. TLS is used when set with --topo-proxy-tls-key.")
+	fs.StringVar(&clientKeyPath, "topo-proxy-tls-key", clientKeyPath, "The client key to use to connect to the topo proxy.")
+	fs.StringVar(&serverCAPath, "topo-proxy-tls-ca", serverCAPath, "The CA to use to validate the certificate of the topo proxy. TLS is used when set.")
+	fs.StringVar(&serverName, "topo-proxy-tls-server-name", serverName, "The server name to use to validate the certificate of the topo proxy.")
+}
+
+// Factory is the proxy topo.Factory implementation.
+//
+// All the cells are served by the proxy at the global topo server
+// address, so the Factory remembers it when the global cell is created.
+type Factory struct {
+	mu         sync.Mutex
+	serverAddr string
+}
+
+// HasGlobalReadOnlyCell is part of the topo.Factory interface.
+func (f *Factory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return false
+}
+
+// Create is part of the topo.Factory interface.
+func (f *Factory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if cell == topo.GlobalCell {
+		f.serverAddr = serverAddr
+	}
+	if f.serverAddr == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the global cell must be opened before cell %v", cell)
+	}
+	return NewServer(f.serverAddr, cell)
+}
+
+// Server is the implementation of topo.Server for the topo proxy. Each
+// Server is the connection to one cell of the topo server behind the
+// proxy.
+type Server struct {
+	conn   *grpc.ClientConn
+	client topoproxyservicepb.TopoProxyClient
+
+	// cell is the cell of the topo server behind the proxy.
+	cell string
+}
+
+// NewServer returns a new proxytopo.Server for the given cell, connected
+// to the proxy at serverAddr.
+func NewServer(serverAddr, cell string) (*Server, error) {
+	opt, err := grpcclient.SecureDialOption(clientCertPath, clientKeyPath, serverCAPath, "", serverName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpcclient.DialContext(context.Background(), serverAddr, grpcclient.FailFast(true), opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		conn:   conn,
+		client: topoproxyservicepb.NewTopoProxyClient(conn),
+		cell:   cell,
+	}, nil
+}
+
+// Close implements topo.Server.Close.
+// It will nil out the client, so any attempt to re-use this server will
+// panic.
+func (s *Server) Close() {
+	s.conn.Close()
+	s.conn = nil
+	s.client = nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// ProxyVersion is the version of a file of the topo server behind the
+// proxy. It has the text representation of the version returned by that
+// server.
+type ProxyVersion string
+
+// String is part of the topo.Version interface.
+func (v ProxyVersion) String() string {
+	return string(v)
+}
+
+// versionString returns the text representation of a version, or an
+// empty string if it's nil.
+func versionString(version topo.Version) string {
+	if version == nil {
+		return ""
+	}
+	return version.String()
+}
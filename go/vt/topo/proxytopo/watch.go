@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxytopo
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	topoproxydatapb "vitess.io/vitess/go/vt/proto/topoproxydata"
+)
+
+// Watch is part of the topo.Conn interface.
+func (s *Server) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
+	stream, err := s.client.Watch(ctx, &topoproxydatapb.WatchRequest{
+		Cell: s.cell,
+		Path: filePath,
+	})
+	if err != nil {
+		return nil, nil, convertError(err, filePath)
+	}
+
+	// The first response is the current value of the file. The errors of
+	// the proxy are only returned when receiving.
+	initial, err := stream.Recv()
+	if err != nil {
+		return nil, nil, convertError(err, filePath)
+	}
+	wd := &topo.WatchData{
+		Contents: initial.Contents,
+		Version:  ProxyVersion(initial.Version),
+	}
+
+	notifications := make(chan *topo.WatchData, 10)
+	go func() {
+		defer close(notifications)
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				// This includes context cancellation errors, and the
+				// deletion of the file.
+				notifications <- &topo.WatchData{
+					Err: convertError(err, filePath),
+				}
+				return
+			}
+			notifications <- &topo.WatchData{
+				Contents: resp.Contents,
+				Version:  ProxyVersion(resp.Version),
+			}
+		}
+	}()
+
+	return wd, notifications, nil
+}
+
+// WatchRecursive is part of the topo.Conn interface.
+func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
+	stream, err := s.client.WatchRecursive(ctx, &topoproxydatapb.WatchRecursiveRequest{
+		Cell: s.cell,
+		Path: dirpath,
+	})
+	if err != nil {
+		return nil, nil, convertError(err, dirpath)
+	}
+
+	// The first response has the current values of the files.
+	initial, err := stream.Recv()
+	if err != nil {
+		return nil, nil, convertError(err, dirpath)
+	}
+	var initialwd []*topo.WatchDataRecursive
+	for _, ev := range initial.Events {
+		initialwd = append(initialwd, toWatchDataRecursive(ev))
+	}
+
+	notifications := make(chan *topo.WatchDataRecursive, 10)
+	go func() {
+		defer close(notifications)
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				// This includes context cancellation errors.
+				notifications <- &topo.WatchDataRecursive{
+					WatchData: topo.WatchData{Err: convertError(err, dirpath)},
+				}
+				return
+			}
+			for _, ev := range resp.Events {
+				notifications <- toWatchDataRecursive(ev)
+			}
+		}
+	}()
+
+	return initialwd, notifications, nil
+}
+
+// toWatchDataRecursive converts an event sent by the proxy.
+func toWatchDataRecursive(ev *topoproxydatapb.WatchRecursiveEvent) *topo.WatchDataRecursive {
+	if ev.Deleted {
+		return &topo.WatchDataRecursive{
+			Path: ev.Path,
+			WatchData: topo.WatchData{
+				Err: topo.NewError(topo.NoNode, ev.Path),
+			},
+		}
+	}
+	return &topo.WatchDataRecursive{
+		Path: ev.Path,
+		WatchData: topo.WatchData{
+			Contents: ev.Contents,
+			Version:  ProxyVersion(ev.Version),
+		},
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+)
+
+// ListDir is part of the topo.Conn interface.
+func (s *Server) ListDir(ctx context.Context, dirPath string, full bool) ([]topo.DirEntry, error) {
+	nodePath := path.Join(s.root, dirPath) + "/"
+	if nodePath == "//" {
+		// Special case where s.root is "/", dirPath is empty,
+		// we would end up with "//". in that case, we want "/".
+		nodePath = "/"
+	}
+	resp, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{
+		Key:      nodePath,
+		Prefix:   true,
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, convertError(err, dirPath)
+	}
+	if len(resp.Kvs) == 0 {
+		// No key starts with this prefix, means the directory
+		// doesn't exist.
+		return nil, topo.NewError(topo.NoNode, nodePath)
+	}
+
+	prefixLen := len(nodePath)
+	var result []topo.DirEntry
+	for _, kv := range resp.Kvs {
+		// Remove the prefix, base path.
+		p := kv.Key[prefixLen:]
+
+		// Keep only the part until the first '/'.
+		t := topo.TypeFile
+		if i := strings.Index(p, "/"); i >= 0 {
+			p = p[:i]
+			t = topo.TypeDirectory
+		}
+
+		// Remove duplicates, add to list.
+		if len(result) == 0 || result[len(result)-1].Name != p {
+			e := topo.DirEntry{
+				Name: p,
+			}
+			if full {
+				e.Type = t
+				if kv.Lease != 0 {
+					// Only locks have a lease associated with them.
+					e.Ephemeral = true
+				}
+			}
+			result = append(result, e)
+		}
+	}
+
+	// Keys are sorted, but a directory name is followed by a '/', so
+	// "a/b" comes after "a.b". Sort the entries by name.
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"path"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+)
+
+// Create is part of the topo.Conn interface.
+func (s *Server) Create(ctx context.Context, filePath string, contents []byte) (topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	var resp *rafttopodatapb.PutResponse
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		resp, err = client.Put(ctx, &rafttopodatapb.PutRequest{
+			Key:        nodePath,
+			Value:      contents,
+			CreateOnly: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	return RaftVersion(resp.Revision), nil
+}
+
+// Update is part of the topo.Conn interface.
+func (s *Server) Update(ctx context.Context, filePath string, contents []byte, version topo.Version) (topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	req := &rafttopodatapb.PutRequest{
+		Key:   nodePath,
+		Value: contents,
+	}
+	if version != nil {
+		// The put fails if the current file revision isn't what we expect.
+		req.ExpectedModRevision = int64(version.(RaftVersion))
+	}
+	var resp *rafttopodatapb.PutResponse
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		resp, err = client.Put(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	return RaftVersion(resp.Revision), nil
+}
+
+// Get is part of the topo.Conn interface.
+func (s *Server) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	resp, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{Key: nodePath})
+	if err != nil {
+		return nil, nil, convertError(err, nodePath)
+	}
+	if len(resp.Kvs) != 1 {
+		return nil, nil, topo.NewError(topo.NoNode, nodePath)
+	}
+	return resp.Kvs[0].Value, RaftVersion(resp.Kvs[0].ModRevision), nil
+}
+
+// GetVersion is part of the topo.Conn interface.
+// Only the versions still in the history of the store can be read.
+func (s *Server) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	resp, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{
+		Key:         nodePath,
+		ModRevision: version,
+	})
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	if len(resp.Kvs) != 1 {
+		return nil, topo.NewError(topo.NoNode, nodePath)
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// List is part of the topo.Conn interface.
+func (s *Server) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
+	nodePathPrefix := path.Join(s.root, filePathPrefix)
+
+	resp, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{
+		Key:    nodePathPrefix,
+		Prefix: true,
+	})
+	if err != nil {
+		return []topo.KVInfo{}, convertError(err, nodePathPrefix)
+	}
+	if len(resp.Kvs) == 0 {
+		return []topo.KVInfo{}, topo.NewError(topo.NoNode, nodePathPrefix)
+	}
+	results := make([]topo.KVInfo, len(resp.Kvs))
+	for n, kv := range resp.Kvs {
+		results[n].Key = []byte(kv.Key)
+		results[n].Value = kv.Value
+		results[n].Version = RaftVersion(kv.ModRevision)
+	}
+	return results, nil
+}
+
+// Delete is part of the topo.Conn interface.
+func (s *Server) Delete(ctx context.Context, filePath string, version topo.Version) error {
+	nodePath := path.Join(s.root, filePath)
+
+	req := &rafttopodatapb.DeleteRequest{
+		Key: nodePath,
+	}
+	if version != nil {
+		// The delete fails if the current file revision isn't what we
+		// expect.
+		req.ExpectedModRevision = int64(version.(RaftVersion))
+	}
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) error {
+		_, err := client.Delete(ctx, req)
+		return err
+	})
+	return convertError(err, nodePath)
+}
+
+// rangeKeys runs a Range request on the leader. The returned error isn't
+// converted.
+func (s *Server) rangeKeys(ctx context.Context, req *rafttopodatapb.RangeRequest) (*rafttopodatapb.RangeResponse, error) {
+	var resp *rafttopodatapb.RangeResponse
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		resp, err = client.Range(ctx, req)
+		return err
+	})
+	return resp, err
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+)
+
+// TryLock is part of the topo.Conn interface.
+func (s *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	// We list all the entries under dirPath
+	entries, err := s.ListDir(ctx, dirPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// If there is a folder '/locks' with some entries in it then we can assume that someone else already has a lock.
+	// Throw error in this case
+	for _, e := range entries {
+		if e.Name == locksPath && e.Type == topo.TypeDirectory && e.Ephemeral {
+			return nil, topo.NewError(topo.NodeExists, fmt.Sprintf("lock already exists at path %s", dirPath))
+		}
+	}
+
+	// everything is good let's acquire the lock.
+	return s.lock(ctx, dirPath, contents, leaseTTL)
+}
+
+// Lock is part of the topo.Conn interface.
+func (s *Server) Lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	// We list the directory first to make sure it exists.
+	if _, err := s.ListDir(ctx, dirPath, false /*full*/); err != nil {
+		return nil, err
+	}
+
+	return s.lock(ctx, dirPath, contents, leaseTTL)
+}
+
+// LockWithTTL is part of the topo.Conn interface.
+func (s *Server) LockWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	// We list the directory first to make sure it exists.
+	if _, err := s.ListDir(ctx, dirPath, false /*full*/); err != nil {
+		return nil, err
+	}
+
+	return s.lock(ctx, dirPath, contents, int(ttl.Seconds()))
+}
+
+// LockName is part of the topo.Conn interface.
+func (s *Server) LockName(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	return s.lock(ctx, dirPath, contents, int(topo.NamedLockTTL.Seconds()))
+}
+
+// lock is used by both Lock() and primary election.
+// It creates a file attached to a new lease in the locks directory, and
+// waits until all the older files are gone.
+func (s *Server) lock(ctx context.Context, nodePath, contents string, ttl int) (topo.LockDescriptor, error) {
+	nodePath = path.Join(s.root, nodePath, locksPath)
+
+	// Get a lease, and keep it alive until the lock is released.
+	var grant *rafttopodatapb.LeaseGrantResponse
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		grant, err = client.LeaseGrant(ctx, &rafttopodatapb.LeaseGrantRequest{TtlSeconds: int64(max(ttl, 1))})
+		return err
+	})
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	ld := &raftLockDescriptor{
+		s:       s,
+		leaseID: grant.Id,
+		stop:    make(chan struct{}),
+	}
+	go ld.keepAlive(time.Duration(max(ttl, 1)) * time.Second)
+
+	// Create an ephemeral file in the locks directory. Use the lease ID as
+	// the file name, so it's guaranteed unique.
+	key := fmt.Sprintf("%v/%v", nodePath, grant.Id)
+	var put *rafttopodatapb.PutResponse
+	err = s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		put, err = client.Put(ctx, &rafttopodatapb.PutRequest{
+			Key:        key,
+			Value:      []byte(contents),
+			Lease:      grant.Id,
+			CreateOnly: true,
+		})
+		return err
+	})
+	if err == nil {
+		// Wait until all older files in the locks directory are gone.
+		var done bool
+		for !done && err == nil {
+			done, err = s.waitOnLastRev(ctx, nodePath, put.Revision)
+		}
+		if done {
+			return ld, nil
+		}
+	}
+
+	// We failed to get the lock. Revoke our lease, this will delete the
+	// file.
+	if uerr := ld.Unlock(context.Background()); uerr != nil {
+		log.Warningf("Revoke(%d) failed, may have left %v behind: %v", grant.Id, key, uerr)
+	}
+	return nil, convertError(err, nodePath)
+}
+
+// waitOnLastRev waits on the newest file of the provided directory that
+// was created before the provided revision. It returns true only if there
+// is no more other older files. The returned error isn't converted.
+func (s *Server) waitOnLastRev(ctx context.Context, nodePath string, revision int64) (bool, error) {
+	// Get the file that is blocking us, if any.
+	resp, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{
+		Key:      nodePath + "/",
+		Prefix:   true,
+		KeysOnly: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	var blocking *rafttopodatapb.KeyValue
+	for _, kv := range resp.Kvs {
+		if kv.CreateRevision < revision && (blocking == nil || kv.CreateRevision > blocking.CreateRevision) {
+			blocking = kv
+		}
+	}
+	if blocking == nil {
+		// No older file, we're done waiting.
+		return true, nil
+	}
+
+	// Wait for the blocking file to be deleted. Cancel the watch when we
+	// exit this function.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, wresp, err := s.startWatch(ctx, &rafttopodatapb.WatchRequest{
+		Key:           blocking.Key,
+		StartRevision: resp.Revision,
+	})
+	if err != nil {
+		return false, err
+	}
+	for {
+		for _, ev := range wresp.Events {
+			if ev.Type == rafttopodatapb.Event_DELETE {
+				// There might still be older files, but not this one.
+				return false, nil
+			}
+		}
+		wresp, err = stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			// The watch stopped, we're not sure if there are more items.
+			return false, nil
+		}
+	}
+}
+
+// raftLockDescriptor implements topo.LockDescriptor.
+type raftLockDescriptor struct {
+	s       *Server
+	leaseID int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// keepAlive keeps the lease alive until the lock is released.
+func (ld *raftLockDescriptor) keepAlive(ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ld.stop:
+			return
+		case <-ld.s.running:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+		err := ld.Check(ctx)
+		cancel()
+		if topo.IsErrType(err, topo.NoNode) {
+			// The lease expired, there is nothing to keep alive.
+			return
+		}
+	}
+}
+
+// Check is part of the topo.LockDescriptor interface.
+// We renew the lease to make sure it is still active and well.
+func (ld *raftLockDescriptor) Check(ctx context.Context) error {
+	err := ld.s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) error {
+		_, err := client.LeaseKeepAlive(ctx, &rafttopodatapb.LeaseKeepAliveRequest{Id: ld.leaseID})
+		return err
+	})
+	return convertError(err, "lease")
+}
+
+// Unlock is part of the topo.LockDescriptor interface.
+func (ld *raftLockDescriptor) Unlock(ctx context.Context) error {
+	ld.stopOnce.Do(func() {
+		close(ld.stop)
+	})
+	err := ld.s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) error {
+		_, err := client.LeaseRevoke(ctx, &rafttopodatapb.LeaseRevokeRequest{Id: ld.leaseID})
+		return err
+	})
+	return convertError(err, "lease")
+}
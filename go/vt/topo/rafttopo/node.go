@@ -0,0 +1,571 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+)
+
+var (
+	nodeID         string
+	dataDir        string
+	peerAddress    string
+	clientAddress  string
+	initialCluster string
+)
+
+const (
+	// applyTimeout is the maximum time to wait for a command to be
+	// committed when the request has no deadline.
+	applyTimeout = 10 * time.Second
+
+	// leaseCheckInterval is how often the leader revokes the expired leases.
+	leaseCheckInterval = 500 * time.Millisecond
+)
+
+func init() {
+	servenv.OnParseFor("vtctld", registerNodeFlags)
+}
+
+func registerNodeFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&nodeID, "topo-raft-node-id", nodeID, "ID of the member of the embedded raft topo cluster to run in this process. The member is only started if set.")
+	fs.StringVar(&dataDir, "topo-raft-data-dir", dataDir, "Directory where the member of the raft topo cluster stores its log and snapshots.")
+	fs.StringVar(&peerAddress, "topo-raft-peer-address", peerAddress, "Address, as host:port, on which the member of the raft topo cluster listens for the other members.")
+	fs.StringVar(&clientAddress, "topo-raft-client-address", clientAddress, "Address, as host:port, on which the member of the raft topo cluster listens for topo clients. The client addresses of all the members are used as the topo server address.")
+	fs.StringVar(&initialCluster, "topo-raft-initial-cluster", initialCluster, "Members of the raft topo cluster when it is first started, as a comma-separated list of id=host:port peer addresses. Ignored once the member has data.")
+}
+
+// NodeConfig is the configuration of a member of a raft topo cluster.
+type NodeConfig struct {
+	// ID is the unique ID of the member in the cluster.
+	ID string
+	// DataDir is the directory of the raft log and snapshots.
+	DataDir string
+	// PeerAddress is the address the member listens on for the other
+	// members. It must be reachable by them.
+	PeerAddress string
+	// ClientAddress is the address the member listens on for topo clients.
+	ClientAddress string
+	// InitialCluster maps the IDs of the members of the cluster to their
+	// peer addresses. It is used to bootstrap the cluster when the member
+	// has no data.
+	InitialCluster map[string]string
+
+	// raftConfig overrides the raft timeouts in tests.
+	raftConfig func(*raft.Config)
+}
+
+// ParseInitialCluster parses a comma-separated list of id=host:port peer
+// addresses.
+func ParseInitialCluster(value string) (map[string]string, error) {
+	cluster := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		id, address, ok := strings.Cut(member, "=")
+		if !ok || id == "" || address == "" {
+			return nil, fmt.Errorf("invalid raft topo member %q, expected id=host:port", member)
+		}
+		if _, ok := cluster[id]; ok {
+			return nil, fmt.Errorf("duplicate raft topo member %q", id)
+		}
+		cluster[id] = address
+	}
+	return cluster, nil
+}
+
+// StartNodeFromFlags starts the member of the raft topo cluster configured
+// by the --topo-raft-* flags. It returns nil if --topo-raft-node-id isn't
+// set.
+func StartNodeFromFlags() (*Node, error) {
+	if nodeID == "" {
+		return nil, nil
+	}
+	cluster, err := ParseInitialCluster(initialCluster)
+	if err != nil {
+		return nil, err
+	}
+	return StartNode(&NodeConfig{
+		ID:             nodeID,
+		DataDir:        dataDir,
+		PeerAddress:    peerAddress,
+		ClientAddress:  clientAddress,
+		InitialCluster: cluster,
+	})
+}
+
+// Node is a member of a raft topo cluster. It replicates the topo store
+// with the other members, and serves it to the topo clients when it is the
+// leader.
+type Node struct {
+	rafttoposervicepb.UnimplementedRaftTopoServer
+
+	store      *store
+	raft       *raft.Raft
+	boltStore  *raftboltdb.BoltStore
+	transport  *raft.NetworkTransport
+	grpcServer *grpc.Server
+	listener   net.Listener
+
+	mu sync.Mutex
+	// leaderCtx is set while the member is the leader, and has applied all
+	// the commands of the previous leaders. It is canceled when the member
+	// loses the leadership.
+	leaderCtx    context.Context
+	leaderCancel context.CancelFunc
+	// leaseDeadlines is the expiration time of the leases. It is only
+	// maintained by the leader.
+	leaseDeadlines map[int64]time.Time
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// StartNode starts a member of a raft topo cluster.
+func StartNode(config *NodeConfig) (*Node, error) {
+	if config.ID == "" || config.DataDir == "" || config.PeerAddress == "" || config.ClientAddress == "" {
+		return nil, errors.New("the ID, data directory, peer address and client address of the raft topo member are required")
+	}
+	if err := os.MkdirAll(config.DataDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:   "rafttopo",
+		Output: logWriter{},
+		Level:  hclog.Info,
+	})
+	n := &Node{
+		store:          newStore(),
+		leaseDeadlines: make(map[int64]time.Time),
+		closed:         make(chan struct{}),
+	}
+
+	var err error
+	defer func() {
+		if err != nil {
+			n.close()
+		}
+	}()
+	n.boltStore, err = raftboltdb.NewBoltStore(filepath.Join(config.DataDir, "raft.db"))
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(config.DataDir, 2, logger)
+	if err != nil {
+		return nil, err
+	}
+	advertise, err := net.ResolveTCPAddr("tcp", config.PeerAddress)
+	if err != nil {
+		return nil, err
+	}
+	n.transport, err = raft.NewTCPTransportWithLogger(config.PeerAddress, advertise, 3, 10*time.Second, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyCh := make(chan bool, 16)
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(config.ID)
+	raftConfig.Logger = logger
+	raftConfig.NotifyCh = notifyCh
+	if config.raftConfig != nil {
+		config.raftConfig(raftConfig)
+	}
+
+	hasState, err := raft.HasExistingState(n.boltStore, n.boltStore, snapshots)
+	if err != nil {
+		return nil, err
+	}
+	n.raft, err = raft.NewRaft(raftConfig, n.store, n.boltStore, n.boltStore, snapshots, n.transport)
+	if err != nil {
+		return nil, err
+	}
+	if !hasState {
+		if len(config.InitialCluster) == 0 {
+			err = fmt.Errorf("raft topo member %v has no data, and no initial cluster", config.ID)
+			return nil, err
+		}
+		var configuration raft.Configuration
+		for id, address := range config.InitialCluster {
+			configuration.Servers = append(configuration.Servers, raft.Server{
+				ID:      raft.ServerID(id),
+				Address: raft.ServerAddress(address),
+			})
+		}
+		if err = n.raft.BootstrapCluster(configuration).Error(); err != nil {
+			return nil, err
+		}
+	}
+
+	n.listener, err = net.Listen("tcp", config.ClientAddress)
+	if err != nil {
+		return nil, err
+	}
+	n.grpcServer = grpc.NewServer()
+	rafttoposervicepb.RegisterRaftTopoServer(n.grpcServer, n)
+
+	n.wg.Add(3)
+	go func() {
+		defer n.wg.Done()
+		if err := n.grpcServer.Serve(n.listener); err != nil {
+			log.Errorf("raft topo member %v stopped serving: %v", config.ID, err)
+		}
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.watchLeadership(notifyCh)
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.expireLeases()
+	}()
+	return n, nil
+}
+
+// ClientAddress returns the address the member listens on for topo clients.
+func (n *Node) ClientAddress() string {
+	return n.listener.Addr().String()
+}
+
+// Close stops the member.
+func (n *Node) Close() {
+	close(n.closed)
+	n.close()
+	n.wg.Wait()
+}
+
+func (n *Node) close() {
+	if n.grpcServer != nil {
+		n.grpcServer.Stop()
+	} else if n.listener != nil {
+		n.listener.Close()
+	}
+	if n.raft != nil {
+		if err := n.raft.Shutdown().Error(); err != nil {
+			log.Warningf("failed to shut down the raft topo member: %v", err)
+		}
+	}
+	if n.transport != nil {
+		n.transport.Close()
+	}
+	if n.boltStore != nil {
+		n.boltStore.Close()
+	}
+	n.loseLeadership()
+}
+
+// watchLeadership tracks the leadership of the member.
+func (n *Node) watchLeadership(notifyCh <-chan bool) {
+	for {
+		select {
+		case <-n.closed:
+			return
+		case isLeader := <-notifyCh:
+			if !isLeader {
+				n.loseLeadership()
+				continue
+			}
+			// Make sure all the commands of the previous leaders are
+			// applied before serving reads.
+			if err := n.raft.Barrier(applyTimeout).Error(); err != nil {
+				log.Warningf("raft topo member failed to apply the log after becoming the leader: %v", err)
+				continue
+			}
+			n.gainLeadership()
+		}
+	}
+}
+
+func (n *Node) gainLeadership() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.leaderCancel != nil {
+		n.leaderCancel()
+	}
+	n.leaderCtx, n.leaderCancel = context.WithCancel(context.Background())
+	// The previous leader kept the leases alive, so give their holders a
+	// full TTL to reach us.
+	now := time.Now()
+	n.leaseDeadlines = make(map[int64]time.Time)
+	for id, ttl := range n.store.leaseTTLs() {
+		n.leaseDeadlines[id] = now.Add(time.Duration(ttl) * time.Second)
+	}
+}
+
+func (n *Node) loseLeadership() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.leaderCancel != nil {
+		n.leaderCancel()
+	}
+	n.leaderCtx, n.leaderCancel = nil, nil
+	n.leaseDeadlines = make(map[int64]time.Time)
+}
+
+// leaderContext returns a context that is canceled when the member loses
+// the leadership, or errNotLeader if it isn't the leader.
+func (n *Node) leaderContext() (context.Context, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.leaderCtx == nil {
+		return nil, errNotLeader
+	}
+	return n.leaderCtx, nil
+}
+
+// verifyLeader makes sure the member is still the leader, so that its
+// reads are up to date.
+func (n *Node) verifyLeader() error {
+	if _, err := n.leaderContext(); err != nil {
+		return err
+	}
+	if err := n.raft.VerifyLeader().Error(); err != nil {
+		return errNotLeader
+	}
+	return nil
+}
+
+// apply commits a command to the raft log, and returns the result of
+// applying it to the store.
+func (n *Node) apply(ctx context.Context, cmd *rafttopodatapb.Command) (*applyResult, error) {
+	if _, err := n.leaderContext(); err != nil {
+		return nil, err
+	}
+	data, err := cmd.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	timeout := applyTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	future := n.raft.Apply(data, timeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipLost) {
+			return nil, errNotLeader
+		}
+		return nil, err
+	}
+	result := future.Response().(*applyResult)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return result, nil
+}
+
+// expireLeases revokes the leases that weren't kept alive.
+func (n *Node) expireLeases() {
+	ticker := time.NewTicker(leaseCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.closed:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, err := n.leaderContext()
+		if err != nil {
+			continue
+		}
+		var expired []int64
+		now := time.Now()
+		n.mu.Lock()
+		for id, deadline := range n.leaseDeadlines {
+			if now.After(deadline) {
+				expired = append(expired, id)
+			}
+		}
+		n.mu.Unlock()
+
+		for _, id := range expired {
+			_, err := n.apply(ctx, &rafttopodatapb.Command{
+				LeaseRevoke: &rafttopodatapb.LeaseRevokeRequest{Id: id},
+			})
+			if err != nil && !errors.Is(err, errLeaseNotFound) {
+				log.Warningf("failed to revoke expired raft topo lease %v: %v", id, err)
+				continue
+			}
+			n.mu.Lock()
+			delete(n.leaseDeadlines, id)
+			n.mu.Unlock()
+		}
+	}
+}
+
+// Range is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) Range(ctx context.Context, req *rafttopodatapb.RangeRequest) (*rafttopodatapb.RangeResponse, error) {
+	if err := n.verifyLeader(); err != nil {
+		return nil, toGRPCError(err)
+	}
+	if req.ModRevision != 0 {
+		kv, err := n.store.getAtRevision(req.Key, req.ModRevision)
+		if err != nil {
+			return nil, toGRPCError(err)
+		}
+		return &rafttopodatapb.RangeResponse{
+			Revision: req.ModRevision,
+			Kvs:      []*rafttopodatapb.KeyValue{kv},
+		}, nil
+	}
+	kvs, revision := n.store.get(req.Key, req.Prefix, req.KeysOnly)
+	return &rafttopodatapb.RangeResponse{
+		Revision: revision,
+		Kvs:      kvs,
+	}, nil
+}
+
+// Put is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) Put(ctx context.Context, req *rafttopodatapb.PutRequest) (*rafttopodatapb.PutResponse, error) {
+	result, err := n.apply(ctx, &rafttopodatapb.Command{Put: req})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &rafttopodatapb.PutResponse{Revision: result.revision}, nil
+}
+
+// Delete is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) Delete(ctx context.Context, req *rafttopodatapb.DeleteRequest) (*rafttopodatapb.DeleteResponse, error) {
+	result, err := n.apply(ctx, &rafttopodatapb.Command{Delete: req})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &rafttopodatapb.DeleteResponse{Revision: result.revision}, nil
+}
+
+// LeaseGrant is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) LeaseGrant(ctx context.Context, req *rafttopodatapb.LeaseGrantRequest) (*rafttopodatapb.LeaseGrantResponse, error) {
+	if req.TtlSeconds <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid lease TTL %v", req.TtlSeconds)
+	}
+	result, err := n.apply(ctx, &rafttopodatapb.Command{LeaseGrant: req})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	n.mu.Lock()
+	if n.leaderCtx != nil {
+		n.leaseDeadlines[result.leaseID] = time.Now().Add(time.Duration(req.TtlSeconds) * time.Second)
+	}
+	n.mu.Unlock()
+	return &rafttopodatapb.LeaseGrantResponse{Id: result.leaseID}, nil
+}
+
+// LeaseKeepAlive is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) LeaseKeepAlive(ctx context.Context, req *rafttopodatapb.LeaseKeepAliveRequest) (*rafttopodatapb.LeaseKeepAliveResponse, error) {
+	if err := n.verifyLeader(); err != nil {
+		return nil, toGRPCError(err)
+	}
+	ttl, ok := n.store.hasLease(req.Id)
+	if !ok {
+		return nil, toGRPCError(errLeaseNotFound)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.leaderCtx == nil {
+		return nil, toGRPCError(errNotLeader)
+	}
+	n.leaseDeadlines[req.Id] = time.Now().Add(time.Duration(ttl) * time.Second)
+	return &rafttopodatapb.LeaseKeepAliveResponse{}, nil
+}
+
+// LeaseRevoke is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) LeaseRevoke(ctx context.Context, req *rafttopodatapb.LeaseRevokeRequest) (*rafttopodatapb.LeaseRevokeResponse, error) {
+	if _, err := n.apply(ctx, &rafttopodatapb.Command{LeaseRevoke: req}); err != nil {
+		return nil, toGRPCError(err)
+	}
+	n.mu.Lock()
+	delete(n.leaseDeadlines, req.Id)
+	n.mu.Unlock()
+	return &rafttopodatapb.LeaseRevokeResponse{}, nil
+}
+
+// Watch is part of the rafttoposervicepb.RaftTopoServer interface.
+func (n *Node) Watch(req *rafttopodatapb.WatchRequest, stream rafttoposervicepb.RaftTopo_WatchServer) error {
+	if err := n.verifyLeader(); err != nil {
+		return toGRPCError(err)
+	}
+	leaderCtx, err := n.leaderContext()
+	if err != nil {
+		return toGRPCError(err)
+	}
+	events, w, err := n.store.watch(req.Key, req.Prefix, req.StartRevision)
+	if err != nil {
+		return toGRPCError(err)
+	}
+	defer n.store.cancelWatcher(w)
+
+	// Always send a first response, so the client knows the watch started.
+	if err := stream.Send(&rafttopodatapb.WatchResponse{Events: events}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return toGRPCError(stream.Context().Err())
+		case <-leaderCtx.Done():
+			// Only the leader serves watches, the client has to watch again
+			// on the new leader.
+			return toGRPCError(errNotLeader)
+		case <-n.closed:
+			return toGRPCError(errNotLeader)
+		case events, ok := <-w.events:
+			if !ok {
+				return toGRPCError(w.err)
+			}
+			if err := stream.Send(&rafttopodatapb.WatchResponse{Events: events}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// logWriter writes the logs of the raft library to the Vitess logs.
+type logWriter struct{}
+
+// Write is part of the io.Writer interface.
+func (logWriter) Write(p []byte) (int, error) {
+	log.Info(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
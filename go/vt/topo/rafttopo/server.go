@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package rafttopo implements topo.Server with an embedded raft cluster as
+the backend, so that Vitess doesn't need an external topo server.
+
+The members of the raft cluster run inside vtctld processes, configured
+with the --topo-raft-* flags. Each member stores the raft log and
+snapshots in its data directory, and replicates a key/value store that
+works like etcd: every change increments the revision of the store, the
+mod revision of a key is its version, and keys can be attached to leases
+that are deleted when the lease expires.
+
+Only the leader of the raft cluster serves the topo clients. The topo
+server address is the comma-separated list of the client addresses of the
+members, and the clients send their requests to the member they last saw
+as the leader, trying the other members when it isn't the leader anymore.
+
+Like etcd2topo, we follow these conventions within this package:
+
+  - Call convertError(err) on any errors returned by the members.
+    Functions defined in this package can be assumed to have already
+    converted errors as necessary.
+*/
+package rafttopo
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+)
+
+// retryInterval is the time to wait before trying the members again, when
+// none of them is the leader.
+const retryInterval = 100 * time.Millisecond
+
+var (
+	leaseTTL = 30 // This is the default used for all non-named locks
+)
+
+func init() {
+	for _, cmd := range topo.FlagBinaries {
+		servenv.OnParseFor(cmd, registerRaftTopoFlags)
+	}
+	topo.RegisterFactory("raft", Factory{})
+}
+
+func registerRaftTopoFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&leaseTTL, "topo-raft-lease-ttl", leaseTTL, "Lease TTL, in seconds, for locks and leader election in the raft topo server. The client keeps the lease alive until the lock is released.")
+}
+
+// Factory is the raft topo.Factory implementation.
+type Factory struct{}
+
+// HasGlobalReadOnlyCell is part of the topo.Factory interface.
+func (f Factory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return false
+}
+
+// Create is part of the topo.Factory interface.
+func (f Factory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	return NewServer(serverAddr, root)
+}
+
+// Server is the implementation of topo.Server for the raft topo cluster.
+type Server struct {
+	conns   []*grpc.ClientConn
+	clients []rafttoposervicepb.RaftTopoClient
+
+	// leader is the index of the member last seen as the leader.
+	leader atomic.Int32
+
+	// root is the root path for this client.
+	root string
+
+	running chan struct{}
+}
+
+// NewServer returns a new rafttopo.Server, connected to the members of the
+// raft cluster at the given comma-separated client addresses.
+func NewServer(serverAddr, root string) (*Server, error) {
+	s := &Server{
+		root:    root,
+		running: make(chan struct{}),
+	}
+	for _, addr := range strings.Split(serverAddr, ",") {
+		// Fail fast, so that the members that are down are skipped.
+		conn, err := grpcclient.DialContext(context.Background(), strings.TrimSpace(addr), grpcclient.FailFast(true), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			for _, conn := range s.conns {
+				conn.Close()
+			}
+			return nil, err
+		}
+		s.conns = append(s.conns, conn)
+		s.clients = append(s.clients, rafttoposervicepb.NewRaftTopoClient(conn))
+	}
+	return s, nil
+}
+
+// Close implements topo.Server.Close.
+// It will nil out the clients, so any attempt to re-use this server will
+// panic.
+func (s *Server) Close() {
+	close(s.running)
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.clients = nil
+}
+
+// call runs f against the leader of the raft cluster. The members that
+// aren't the leader return UNAVAILABLE, so it tries all the members, and
+// tries them again until one becomes the leader or ctx expires. If ctx
+// doesn't have a deadline, it stops retrying after
+// topo.RemoteOperationTimeout. The returned error isn't converted.
+func (s *Server) call(ctx context.Context, f func(rafttoposervicepb.RaftTopoClient) error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(topo.RemoteOperationTimeout)
+	}
+	for {
+		var err error
+		leader := int(s.leader.Load())
+		for i := range s.clients {
+			member := (leader + i) % len(s.clients)
+			err = f(s.clients[member])
+			if status.Code(err) != codes.Unavailable {
+				s.leader.Store(int32(member))
+				return err
+			}
+		}
+
+		// None of the members is the leader, maybe because of an election.
+		if time.Now().Add(retryInterval).After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.running:
+			return err
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/testfiles"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/test"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// testCluster is a raft topo cluster running in the test process.
+type testCluster struct {
+	t       *testing.T
+	configs []*NodeConfig
+	nodes   []*Node
+}
+
+// startCluster starts a cluster of three members.
+func startCluster(t *testing.T) *testCluster {
+	c := &testCluster{t: t}
+	initialCluster := make(map[string]string)
+	for i := range 3 {
+		id := fmt.Sprintf("member%v", i)
+		config := &NodeConfig{
+			ID:            id,
+			DataDir:       t.TempDir(),
+			PeerAddress:   fmt.Sprintf("127.0.0.1:%v", testfiles.GoVtTopoRafttopoPort+2*i),
+			ClientAddress: fmt.Sprintf("127.0.0.1:%v", testfiles.GoVtTopoRafttopoPort+2*i+1),
+			// Elect the leaders faster than the defaults.
+			raftConfig: func(config *raft.Config) {
+				config.HeartbeatTimeout = 200 * time.Millisecond
+				config.ElectionTimeout = 200 * time.Millisecond
+				config.LeaderLeaseTimeout = 100 * time.Millisecond
+			},
+		}
+		initialCluster[id] = config.PeerAddress
+		c.configs = append(c.configs, config)
+	}
+	for _, config := range c.configs {
+		config.InitialCluster = initialCluster
+		node, err := StartNode(config)
+		require.NoError(t, err)
+		c.nodes = append(c.nodes, node)
+	}
+	t.Cleanup(func() {
+		for _, node := range c.nodes {
+			if node != nil {
+				node.Close()
+			}
+		}
+	})
+	c.waitForLeader()
+	return c
+}
+
+// serverAddr returns the topo server address of the cluster.
+func (c *testCluster) serverAddr() string {
+	var addrs []string
+	for _, config := range c.configs {
+		addrs = append(addrs, config.ClientAddress)
+	}
+	return strings.Join(addrs, ",")
+}
+
+// waitForLeader waits until a member is ready to serve, and returns its
+// index.
+func (c *testCluster) waitForLeader() int {
+	var leader int
+	require.Eventually(c.t, func() bool {
+		for i, node := range c.nodes {
+			if node == nil {
+				continue
+			}
+			if _, err := node.leaderContext(); err == nil {
+				leader = i
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+	return leader
+}
+
+// stop stops a member.
+func (c *testCluster) stop(i int) {
+	c.nodes[i].Close()
+	c.nodes[i] = nil
+}
+
+// restart starts a stopped member again, with its data.
+func (c *testCluster) restart(i int) {
+	node, err := StartNode(c.configs[i])
+	require.NoError(c.t, err)
+	c.nodes[i] = node
+}
+
+func TestRaftTopo(t *testing.T) {
+	c := startCluster(t)
+	serverAddr := c.serverAddr()
+
+	testIndex := 0
+	newServer := func() *topo.Server {
+		// Each test will use its own sub-directories.
+		testRoot := fmt.Sprintf("/test-%v", testIndex)
+		testIndex++
+
+		// Create the server on the new root.
+		ts, err := topo.OpenServer("raft", serverAddr, path.Join(testRoot, topo.GlobalCell))
+		require.NoError(t, err)
+
+		// Create the CellInfo.
+		err = ts.CreateCellInfo(context.Background(), test.LocalCellName, &topodatapb.CellInfo{
+			ServerAddress: serverAddr,
+			Root:          path.Join(testRoot, test.LocalCellName),
+		})
+		require.NoError(t, err)
+		return ts
+	}
+
+	// Run the TopoServerTestSuite tests.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	test.TopoServerTestSuite(t, ctx, func() *topo.Server {
+		return newServer()
+	}, []string{})
+
+	// Run raft-specific tests.
+	ts := newServer()
+	defer ts.Close()
+	testKeyspaceLock(t, ts)
+	testGetVersion(t, ts)
+}
+
+// testKeyspaceLock tests the lease keep alive of the locks.
+func testKeyspaceLock(t *testing.T, ts *topo.Server) {
+	ctx := context.Background()
+	keyspacePath := path.Join(topo.KeyspacesPath, "test_keyspace")
+	err := ts.CreateKeyspace(ctx, "test_keyspace", &topodatapb.Keyspace{})
+	require.NoError(t, err)
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+
+	// Short TTL, make sure it doesn't expire.
+	defer func(ttl int) {
+		leaseTTL = ttl
+	}(leaseTTL)
+	leaseTTL = 1
+	lockDescriptor, err := conn.Lock(ctx, keyspacePath, "short ttl")
+	require.NoError(t, err)
+	time.Sleep(2 * time.Second)
+	require.NoError(t, lockDescriptor.Check(ctx))
+	require.NoError(t, lockDescriptor.Unlock(ctx))
+	assert.True(t, topo.IsErrType(lockDescriptor.Check(ctx), topo.NoNode))
+}
+
+// testGetVersion tests reading the previous versions of a file.
+func testGetVersion(t *testing.T, ts *topo.Server) {
+	ctx := context.Background()
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+
+	v1, err := conn.Create(ctx, "versioned", []byte("1"))
+	require.NoError(t, err)
+	v2, err := conn.Update(ctx, "versioned", []byte("2"), v1)
+	require.NoError(t, err)
+
+	contents, err := conn.GetVersion(ctx, "versioned", int64(v1.(RaftVersion)))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), contents)
+	contents, err = conn.GetVersion(ctx, "versioned", int64(v2.(RaftVersion)))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2"), contents)
+	_, err = conn.GetVersion(ctx, "versioned", int64(v2.(RaftVersion))+1000)
+	assert.Error(t, err)
+}
+
+// TestRaftTopoLeaseExpiry tests that the leases that aren't kept alive
+// expire, and delete their keys.
+func TestRaftTopoLeaseExpiry(t *testing.T) {
+	c := startCluster(t)
+	s, err := NewServer(c.serverAddr(), "/")
+	require.NoError(t, err)
+	defer s.Close()
+	ctx := context.Background()
+
+	var grant *rafttopodatapb.LeaseGrantResponse
+	err = s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		grant, err = client.LeaseGrant(ctx, &rafttopodatapb.LeaseGrantRequest{TtlSeconds: 1})
+		return err
+	})
+	require.NoError(t, err)
+	err = s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) error {
+		_, err := client.Put(ctx, &rafttopodatapb.PutRequest{Key: "/ephemeral", Lease: grant.Id})
+		return err
+	})
+	require.NoError(t, err)
+
+	_, _, err = s.Get(ctx, "ephemeral")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, _, err := s.Get(ctx, "ephemeral")
+		return topo.IsErrType(err, topo.NoNode)
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+// TestRaftTopoFailover tests that the clients and their watches move to
+// the new leader when the leader stops, and that a restarted member
+// catches up.
+func TestRaftTopoFailover(t *testing.T) {
+	c := startCluster(t)
+	s, err := NewServer(c.serverAddr(), "/root")
+	require.NoError(t, err)
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	version, err := s.Create(ctx, "file", []byte("1"))
+	require.NoError(t, err)
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	current, changes, err := s.Watch(watchCtx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), current.Contents)
+
+	leader := c.waitForLeader()
+	c.stop(leader)
+	newLeader := c.waitForLeader()
+	assert.NotEqual(t, leader, newLeader)
+
+	// The client finds the new leader.
+	contents, _, err := s.Get(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), contents)
+	_, err = s.Update(ctx, "file", []byte("2"), version)
+	require.NoError(t, err)
+
+	// The watch is restarted on the new leader.
+	wd := <-changes
+	require.NoError(t, wd.Err)
+	assert.Equal(t, []byte("2"), wd.Contents)
+
+	// The stopped member catches up when it's restarted, and has the data
+	// when it becomes the leader.
+	c.restart(leader)
+	for i := range c.nodes {
+		if i != leader {
+			c.stop(i)
+			break
+		}
+	}
+	require.Eventually(t, func() bool {
+		kvs, _ := c.nodes[leader].store.get("/root/file", false, false)
+		return len(kvs) == 1 && string(kvs[0].Value) == "2"
+	}, 10*time.Second, 10*time.Millisecond)
+	contents, _, err = s.Get(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2"), contents)
+
+	watchCancel()
+	for wd := range changes {
+		if wd.Err != nil {
+			assert.True(t, topo.IsErrType(wd.Err, topo.Interrupted), wd.Err)
+		}
+	}
+}
+
+func TestParseInitialCluster(t *testing.T) {
+	cluster, err := ParseInitialCluster("a=host1:1, b=host2:2,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "host1:1", "b": "host2:2"}, cluster)
+
+	_, err = ParseInitialCluster("a=host1:1,a=host2:2")
+	assert.ErrorContains(t, err, "duplicate")
+	_, err = ParseInitialCluster("host1:1")
+	assert.ErrorContains(t, err, "expected id=host:port")
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/raft"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+)
+
+// historySize is the number of events the store keeps in memory, to
+// resume watches and to read previous versions of the keys.
+var historySize = 10000
+
+// watcherBufferSize is the number of batches of events a watcher can
+// lag behind before it is canceled.
+const watcherBufferSize = 100
+
+// Errors returned by the store.
+var (
+	errKeyExists      = errors.New("key already exists")
+	errNoKey          = errors.New("key doesn't exist")
+	errBadVersion     = errors.New("bad version")
+	errLeaseNotFound  = errors.New("lease not found")
+	errCompacted      = errors.New("revision was compacted")
+	errWatcherTooSlow = errors.New("watcher is too slow")
+	errBadCommand     = errors.New("bad command")
+)
+
+// applyResult is the response of store.Apply.
+type applyResult struct {
+	revision int64
+	leaseID  int64
+	err      error
+}
+
+// lease is a lease of the store, with the keys attached to it.
+type lease struct {
+	ttlSeconds int64
+	keys       map[string]struct{}
+}
+
+// watcher receives the events of a key, or of all the keys with a prefix.
+type watcher struct {
+	key    string
+	prefix bool
+	events chan []*rafttopodatapb.Event
+	// err is set before events is closed.
+	err error
+}
+
+func (w *watcher) matches(key string) bool {
+	if w.prefix {
+		return strings.HasPrefix(key, w.key)
+	}
+	return key == w.key
+}
+
+// store is the replicated state machine of the raft topo server. It is
+// a key/value store, where each change increments the revision of the
+// store, in the same way as etcd. It implements raft.FSM.
+type store struct {
+	mu sync.Mutex
+
+	revision    int64
+	lastLeaseID int64
+	kvs         map[string]*rafttopodatapb.KeyValue
+	leases      map[int64]*lease
+
+	// history has the last events of the store. Each event has its own
+	// revision, so history[i] has the revision historyStart+i.
+	history      []*rafttopodatapb.Event
+	historyStart int64
+
+	watchers map[*watcher]struct{}
+}
+
+func newStore() *store {
+	return &store{
+		kvs:          make(map[string]*rafttopodatapb.KeyValue),
+		leases:       make(map[int64]*lease),
+		historyStart: 1,
+		watchers:     make(map[*watcher]struct{}),
+	}
+}
+
+// Apply is part of the raft.FSM interface.
+func (s *store) Apply(log *raft.Log) any {
+	cmd := &rafttopodatapb.Command{}
+	if err := cmd.UnmarshalVT(log.Data); err != nil {
+		return &applyResult{err: err}
+	}
+	return s.applyCommand(cmd)
+}
+
+func (s *store) applyCommand(cmd *rafttopodatapb.Command) *applyResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case cmd.Put != nil:
+		return s.put(cmd.Put)
+	case cmd.Delete != nil:
+		return s.delete(cmd.Delete)
+	case cmd.LeaseGrant != nil:
+		s.lastLeaseID++
+		s.leases[s.lastLeaseID] = &lease{
+			ttlSeconds: cmd.LeaseGrant.TtlSeconds,
+			keys:       make(map[string]struct{}),
+		}
+		return &applyResult{revision: s.revision, leaseID: s.lastLeaseID}
+	case cmd.LeaseRevoke != nil:
+		return s.revokeLease(cmd.LeaseRevoke.Id)
+	}
+	return &applyResult{err: errBadCommand}
+}
+
+func (s *store) put(req *rafttopodatapb.PutRequest) *applyResult {
+	existing := s.kvs[req.Key]
+	if req.CreateOnly && existing != nil {
+		return &applyResult{err: errKeyExists}
+	}
+	if req.ExpectedModRevision != 0 && (existing == nil || existing.ModRevision != req.ExpectedModRevision) {
+		return &applyResult{err: errBadVersion}
+	}
+	if req.Lease != 0 && s.leases[req.Lease] == nil {
+		return &applyResult{err: errLeaseNotFound}
+	}
+
+	s.revision++
+	kv := &rafttopodatapb.KeyValue{
+		Key:            req.Key,
+		Value:          req.Value,
+		CreateRevision: s.revision,
+		ModRevision:    s.revision,
+		Lease:          req.Lease,
+	}
+	if existing != nil {
+		kv.CreateRevision = existing.CreateRevision
+		if l := s.leases[existing.Lease]; l != nil {
+			delete(l.keys, req.Key)
+		}
+	}
+	if l := s.leases[req.Lease]; l != nil {
+		l.keys[req.Key] = struct{}{}
+	}
+	s.kvs[req.Key] = kv
+	s.addEvent(&rafttopodatapb.Event{
+		Type: rafttopodatapb.Event_PUT,
+		Kv:   kv,
+	})
+	return &applyResult{revision: s.revision}
+}
+
+func (s *store) delete(req *rafttopodatapb.DeleteRequest) *applyResult {
+	existing := s.kvs[req.Key]
+	if existing == nil {
+		return &applyResult{err: errNoKey}
+	}
+	if req.ExpectedModRevision != 0 && existing.ModRevision != req.ExpectedModRevision {
+		return &applyResult{err: errBadVersion}
+	}
+	s.deleteKey(existing)
+	return &applyResult{revision: s.revision}
+}
+
+func (s *store) deleteKey(kv *rafttopodatapb.KeyValue) {
+	if l := s.leases[kv.Lease]; l != nil {
+		delete(l.keys, kv.Key)
+	}
+	delete(s.kvs, kv.Key)
+	s.revision++
+	s.addEvent(&rafttopodatapb.Event{
+		Type: rafttopodatapb.Event_DELETE,
+		Kv: &rafttopodatapb.KeyValue{
+			Key:         kv.Key,
+			ModRevision: s.revision,
+		},
+	})
+}
+
+func (s *store) revokeLease(id int64) *applyResult {
+	l := s.leases[id]
+	if l == nil {
+		return &applyResult{err: errLeaseNotFound}
+	}
+	// Delete the keys in order, so all the members have the same revisions.
+	keys := make([]string, 0, len(l.keys))
+	for key := range l.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.deleteKey(s.kvs[key])
+	}
+	delete(s.leases, id)
+	return &applyResult{revision: s.revision}
+}
+
+// addEvent adds an event to the history, and sends it to the watchers.
+func (s *store) addEvent(event *rafttopodatapb.Event) {
+	s.history = append(s.history, event)
+	if len(s.history) > historySize {
+		trimmed := len(s.history) - historySize
+		s.history = append(s.history[:0:0], s.history[trimmed:]...)
+		s.historyStart += int64(trimmed)
+	}
+
+	for w := range s.watchers {
+		if !w.matches(event.Kv.Key) {
+			continue
+		}
+		select {
+		case w.events <- []*rafttopodatapb.Event{event}:
+		default:
+			s.cancelWatcherLocked(w, errWatcherTooSlow)
+		}
+	}
+}
+
+// get returns a key, or all the keys with a prefix, sorted by key, along
+// with the revision of the store.
+func (s *store) get(key string, prefix, keysOnly bool) ([]*rafttopodatapb.KeyValue, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kvs []*rafttopodatapb.KeyValue
+	if !prefix {
+		if kv := s.kvs[key]; kv != nil {
+			kvs = append(kvs, kv)
+		}
+	} else {
+		for k, kv := range s.kvs {
+			if strings.HasPrefix(k, key) {
+				kvs = append(kvs, kv)
+			}
+		}
+		sort.Slice(kvs, func(i, j int) bool {
+			return kvs[i].Key < kvs[j].Key
+		})
+	}
+	if keysOnly {
+		for i, kv := range kvs {
+			kvs[i] = &rafttopodatapb.KeyValue{
+				Key:            kv.Key,
+				CreateRevision: kv.CreateRevision,
+				ModRevision:    kv.ModRevision,
+				Lease:          kv.Lease,
+			}
+		}
+	}
+	return kvs, s.revision
+}
+
+// getAtRevision returns a key as it was written at the given mod revision.
+// It returns errCompacted if the revision isn't in the history anymore.
+func (s *store) getAtRevision(key string, modRevision int64) (*rafttopodatapb.KeyValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kv := s.kvs[key]; kv != nil && kv.ModRevision == modRevision {
+		return kv, nil
+	}
+	if modRevision < s.historyStart || modRevision > s.revision {
+		return nil, errCompacted
+	}
+	event := s.history[modRevision-s.historyStart]
+	if event.Type != rafttopodatapb.Event_PUT || event.Kv.Key != key {
+		return nil, errNoKey
+	}
+	return event.Kv, nil
+}
+
+// hasLease returns the TTL of a lease, and whether it exists.
+func (s *store) hasLease(id int64) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.leases[id]
+	if l == nil {
+		return 0, false
+	}
+	return l.ttlSeconds, true
+}
+
+// leaseTTLs returns the TTL of all the leases.
+func (s *store) leaseTTLs() map[int64]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ttls := make(map[int64]int64, len(s.leases))
+	for id, l := range s.leases {
+		ttls[id] = l.ttlSeconds
+	}
+	return ttls
+}
+
+// watch returns the events of a key, or of all the keys with a prefix,
+// after the given revision, and a watcher to receive the next ones. It
+// returns errCompacted if the events after the revision aren't in the
+// history anymore.
+func (s *store) watch(key string, prefix bool, startRevision int64) ([]*rafttopodatapb.Event, *watcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if startRevision < s.historyStart-1 {
+		return nil, nil, errCompacted
+	}
+	w := &watcher{
+		key:    key,
+		prefix: prefix,
+		events: make(chan []*rafttopodatapb.Event, watcherBufferSize),
+	}
+	var events []*rafttopodatapb.Event
+	if startRevision < s.revision {
+		for _, event := range s.history[startRevision-s.historyStart+1:] {
+			if w.matches(event.Kv.Key) {
+				events = append(events, event)
+			}
+		}
+	}
+	s.watchers[w] = struct{}{}
+	return events, w, nil
+}
+
+// cancelWatcher stops sending events to a watcher.
+func (s *store) cancelWatcher(w *watcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelWatcherLocked(w, nil)
+}
+
+func (s *store) cancelWatcherLocked(w *watcher, err error) {
+	if _, ok := s.watchers[w]; !ok {
+		return
+	}
+	delete(s.watchers, w)
+	w.err = err
+	close(w.events)
+}
+
+// Snapshot is part of the raft.FSM interface.
+func (s *store) Snapshot() (raft.FSMSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := &rafttopodatapb.Snapshot{
+		Revision:    s.revision,
+		LastLeaseId: s.lastLeaseID,
+		Kvs:         make([]*rafttopodatapb.KeyValue, 0, len(s.kvs)),
+		Leases:      make([]*rafttopodatapb.Lease, 0, len(s.leases)),
+	}
+	// The key values are never modified once stored, so they can be
+	// shared with the snapshot.
+	for _, kv := range s.kvs {
+		snapshot.Kvs = append(snapshot.Kvs, kv)
+	}
+	for id, l := range s.leases {
+		snapshot.Leases = append(snapshot.Leases, &rafttopodatapb.Lease{
+			Id:         id,
+			TtlSeconds: l.ttlSeconds,
+		})
+	}
+	return &storeSnapshot{snapshot: snapshot}, nil
+}
+
+// Restore is part of the raft.FSM interface.
+func (s *store) Restore(reader io.ReadCloser) error {
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	snapshot := &rafttopodatapb.Snapshot{}
+	if err := snapshot.UnmarshalVT(data); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revision = snapshot.Revision
+	s.lastLeaseID = snapshot.LastLeaseId
+	s.kvs = make(map[string]*rafttopodatapb.KeyValue, len(snapshot.Kvs))
+	s.leases = make(map[int64]*lease, len(snapshot.Leases))
+	for _, l := range snapshot.Leases {
+		s.leases[l.Id] = &lease{
+			ttlSeconds: l.TtlSeconds,
+			keys:       make(map[string]struct{}),
+		}
+	}
+	for _, kv := range snapshot.Kvs {
+		s.kvs[kv.Key] = kv
+		if l := s.leases[kv.Lease]; l != nil {
+			l.keys[kv.Key] = struct{}{}
+		}
+	}
+	// The events between the previous state and the snapshot are lost.
+	s.history = nil
+	s.historyStart = s.revision + 1
+	for w := range s.watchers {
+		s.cancelWatcherLocked(w, errCompacted)
+	}
+	return nil
+}
+
+// storeSnapshot implements raft.FSMSnapshot.
+type storeSnapshot struct {
+	snapshot *rafttopodatapb.Snapshot
+}
+
+// Persist is part of the raft.FSMSnapshot interface.
+func (ss *storeSnapshot) Persist(sink raft.SnapshotSink) error {
+	data, err := ss.snapshot.MarshalVT()
+	if err != nil {
+		sink.Cancel()
+		return err
+	}
+	if _, err := sink.Write(data); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release is part of the raft.FSMSnapshot interface.
+func (ss *storeSnapshot) Release() {}
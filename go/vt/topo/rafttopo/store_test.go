@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+)
+
+func put(t *testing.T, s *store, req *rafttopodatapb.PutRequest) int64 {
+	result := s.applyCommand(&rafttopodatapb.Command{Put: req})
+	require.NoError(t, result.err)
+	return result.revision
+}
+
+func TestStorePutDelete(t *testing.T) {
+	s := newStore()
+
+	rev := put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("1"), CreateOnly: true})
+	assert.EqualValues(t, 1, rev)
+	result := s.applyCommand(&rafttopodatapb.Command{Put: &rafttopodatapb.PutRequest{Key: "/a", CreateOnly: true}})
+	assert.ErrorIs(t, result.err, errKeyExists)
+
+	result = s.applyCommand(&rafttopodatapb.Command{Put: &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("2"), ExpectedModRevision: 5}})
+	assert.ErrorIs(t, result.err, errBadVersion)
+	result = s.applyCommand(&rafttopodatapb.Command{Put: &rafttopodatapb.PutRequest{Key: "/b", ExpectedModRevision: 1}})
+	assert.ErrorIs(t, result.err, errBadVersion)
+	rev = put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("2"), ExpectedModRevision: 1})
+	assert.EqualValues(t, 2, rev)
+
+	kvs, revision := s.get("/a", false, false)
+	require.Len(t, kvs, 1)
+	assert.EqualValues(t, 2, revision)
+	assert.Equal(t, []byte("2"), kvs[0].Value)
+	assert.EqualValues(t, 1, kvs[0].CreateRevision)
+	assert.EqualValues(t, 2, kvs[0].ModRevision)
+
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/dir/c", Value: []byte("3")})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/dir/b", Value: []byte("4")})
+	kvs, _ = s.get("/dir/", true, true)
+	require.Len(t, kvs, 2)
+	assert.Equal(t, "/dir/b", kvs[0].Key)
+	assert.Equal(t, "/dir/c", kvs[1].Key)
+	assert.Nil(t, kvs[0].Value)
+
+	result = s.applyCommand(&rafttopodatapb.Command{Delete: &rafttopodatapb.DeleteRequest{Key: "/a", ExpectedModRevision: 1}})
+	assert.ErrorIs(t, result.err, errBadVersion)
+	result = s.applyCommand(&rafttopodatapb.Command{Delete: &rafttopodatapb.DeleteRequest{Key: "/a", ExpectedModRevision: 2}})
+	require.NoError(t, result.err)
+	assert.EqualValues(t, 5, result.revision)
+	result = s.applyCommand(&rafttopodatapb.Command{Delete: &rafttopodatapb.DeleteRequest{Key: "/a"}})
+	assert.ErrorIs(t, result.err, errNoKey)
+	kvs, _ = s.get("/a", false, false)
+	assert.Empty(t, kvs)
+}
+
+func TestStoreLeases(t *testing.T) {
+	s := newStore()
+
+	result := s.applyCommand(&rafttopodatapb.Command{LeaseGrant: &rafttopodatapb.LeaseGrantRequest{TtlSeconds: 10}})
+	require.NoError(t, result.err)
+	leaseID := result.leaseID
+	ttl, ok := s.hasLease(leaseID)
+	assert.True(t, ok)
+	assert.EqualValues(t, 10, ttl)
+
+	result = s.applyCommand(&rafttopodatapb.Command{Put: &rafttopodatapb.PutRequest{Key: "/lock/1", Lease: leaseID + 1}})
+	assert.ErrorIs(t, result.err, errLeaseNotFound)
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/lock/1", Lease: leaseID})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/lock/2", Lease: leaseID})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/other", Value: []byte("x")})
+
+	result = s.applyCommand(&rafttopodatapb.Command{LeaseRevoke: &rafttopodatapb.LeaseRevokeRequest{Id: leaseID}})
+	require.NoError(t, result.err)
+	// Each deleted key has its own revision.
+	assert.EqualValues(t, 5, result.revision)
+	kvs, _ := s.get("/", true, false)
+	require.Len(t, kvs, 1)
+	assert.Equal(t, "/other", kvs[0].Key)
+	_, ok = s.hasLease(leaseID)
+	assert.False(t, ok)
+
+	result = s.applyCommand(&rafttopodatapb.Command{LeaseRevoke: &rafttopodatapb.LeaseRevokeRequest{Id: leaseID}})
+	assert.ErrorIs(t, result.err, errLeaseNotFound)
+}
+
+func TestStoreHistory(t *testing.T) {
+	defer func(size int) {
+		historySize = size
+	}(historySize)
+	historySize = 3
+	s := newStore()
+
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("1")})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("2")})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/b", Value: []byte("3")})
+
+	kv, err := s.getAtRevision("/a", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), kv.Value)
+	_, err = s.getAtRevision("/a", 3)
+	assert.ErrorIs(t, err, errNoKey)
+
+	// The watch gets the events after the start revision.
+	events, w, err := s.watch("/a", false, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.EqualValues(t, 1, events[0].Kv.ModRevision)
+	assert.EqualValues(t, 2, events[1].Kv.ModRevision)
+
+	// Then the next ones.
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/b", Value: []byte("4")})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("5")})
+	require.Len(t, w.events, 1)
+	events = <-w.events
+	assert.Equal(t, []byte("5"), events[0].Kv.Value)
+	s.cancelWatcher(w)
+	_, ok := <-w.events
+	assert.False(t, ok)
+	assert.NoError(t, w.err)
+
+	// The first events were trimmed from the history.
+	_, err = s.getAtRevision("/a", 1)
+	assert.ErrorIs(t, err, errCompacted)
+	_, _, err = s.watch("/a", false, 1)
+	assert.ErrorIs(t, err, errCompacted)
+	events, w, err = s.watch("/", true, 2)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+	s.cancelWatcher(w)
+}
+
+func TestStoreSlowWatcher(t *testing.T) {
+	s := newStore()
+	_, w, err := s.watch("/a", false, 0)
+	require.NoError(t, err)
+	for i := 0; i <= watcherBufferSize; i++ {
+		put(t, s, &rafttopodatapb.PutRequest{Key: "/a"})
+	}
+	for range w.events {
+	}
+	assert.ErrorIs(t, w.err, errWatcherTooSlow)
+}
+
+// testSnapshotSink implements raft.SnapshotSink.
+type testSnapshotSink struct {
+	bytes.Buffer
+}
+
+func (testSnapshotSink) ID() string    { return "test" }
+func (testSnapshotSink) Cancel() error { return nil }
+func (testSnapshotSink) Close() error  { return nil }
+
+func TestStoreSnapshot(t *testing.T) {
+	s := newStore()
+	result := s.applyCommand(&rafttopodatapb.Command{LeaseGrant: &rafttopodatapb.LeaseGrantRequest{TtlSeconds: 10}})
+	require.NoError(t, result.err)
+	leaseID := result.leaseID
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/lock/1", Lease: leaseID})
+	put(t, s, &rafttopodatapb.PutRequest{Key: "/a", Value: []byte("1")})
+
+	snapshot, err := s.Snapshot()
+	require.NoError(t, err)
+	sink := &testSnapshotSink{}
+	require.NoError(t, snapshot.Persist(sink))
+	snapshot.Release()
+
+	restored := newStore()
+	_, w, err := restored.watch("/a", false, 0)
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(io.NopCloser(&sink.Buffer)))
+
+	// The watchers can't see the changes before the snapshot.
+	_, ok := <-w.events
+	assert.False(t, ok)
+	assert.ErrorIs(t, w.err, errCompacted)
+
+	kvs, revision := restored.get("/", true, false)
+	assert.EqualValues(t, 2, revision)
+	require.Len(t, kvs, 2)
+	assert.Equal(t, "/a", kvs[0].Key)
+	assert.Equal(t, []byte("1"), kvs[0].Value)
+
+	// The lease still owns its key.
+	result = restored.applyCommand(&rafttopodatapb.Command{LeaseRevoke: &rafttopodatapb.LeaseRevokeRequest{Id: leaseID}})
+	require.NoError(t, result.err)
+	kvs, _ = restored.get("/lock/", true, false)
+	assert.Empty(t, kvs)
+
+	// The next lease IDs don't reuse the old ones.
+	result = restored.applyCommand(&rafttopodatapb.Command{LeaseGrant: &rafttopodatapb.LeaseGrantRequest{TtlSeconds: 10}})
+	require.NoError(t, result.err)
+	assert.Greater(t, result.leaseID, leaseID)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rafttopo
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	rafttopodatapb "vitess.io/vitess/go/vt/proto/rafttopodata"
+	rafttoposervicepb "vitess.io/vitess/go/vt/proto/rafttoposervice"
+)
+
+// maxWatchRetryInterval is the maximum time to wait before restarting a
+// watch that failed.
+const maxWatchRetryInterval = 10 * time.Second
+
+// errClosed is returned by watch when the Server is closed.
+var errClosed = errors.New("raft topo server closed")
+
+// Watch is part of the topo.Conn interface.
+func (s *Server) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	// Get the initial version of the file
+	initialCtx, initialCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer initialCancel()
+	initial, err := s.rangeKeys(initialCtx, &rafttopodatapb.RangeRequest{Key: nodePath})
+	if err != nil {
+		// Generic error.
+		return nil, nil, convertError(err, nodePath)
+	}
+	if len(initial.Kvs) != 1 {
+		// Node doesn't exist.
+		return nil, nil, topo.NewError(topo.NoNode, nodePath)
+	}
+	wd := &topo.WatchData{
+		Contents: initial.Kvs[0].Value,
+		Version:  RaftVersion(initial.Kvs[0].ModRevision),
+	}
+
+	// Create the notifications channel, send updates to it. We start
+	// watching from the revision of the initial read.
+	notifications := make(chan *topo.WatchData, 10)
+	go func() {
+		defer close(notifications)
+
+		err := s.watch(ctx, nodePath, false, initial.Revision, func(ev *rafttopodatapb.Event) bool {
+			switch ev.Type {
+			case rafttopodatapb.Event_PUT:
+				notifications <- &topo.WatchData{
+					Contents: ev.Kv.Value,
+					Version:  RaftVersion(ev.Kv.ModRevision),
+				}
+				return true
+			case rafttopodatapb.Event_DELETE:
+				// Node is gone, send a final notice.
+				notifications <- &topo.WatchData{
+					Err: topo.NewError(topo.NoNode, nodePath),
+				}
+				return false
+			default:
+				notifications <- &topo.WatchData{
+					Err: vterrors.Errorf(vtrpc.Code_INTERNAL, "unexpected event received: %v", ev),
+				}
+				return false
+			}
+		})
+		if err != nil && !errors.Is(err, errClosed) {
+			// This includes context cancellation errors.
+			notifications <- &topo.WatchData{
+				Err: convertError(err, nodePath),
+			}
+		}
+	}()
+
+	return wd, notifications, nil
+}
+
+// WatchRecursive is part of the topo.Conn interface.
+func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
+	nodePath := path.Join(s.root, dirpath)
+	if !strings.HasSuffix(nodePath, "/") {
+		nodePath = nodePath + "/"
+	}
+
+	// Get the initial version of the files
+	initial, err := s.rangeKeys(ctx, &rafttopodatapb.RangeRequest{
+		Key:    nodePath,
+		Prefix: true,
+	})
+	if err != nil {
+		return nil, nil, convertError(err, nodePath)
+	}
+
+	var initialwd []*topo.WatchDataRecursive
+	for _, kv := range initial.Kvs {
+		initialwd = append(initialwd, &topo.WatchDataRecursive{
+			Path: kv.Key,
+			WatchData: topo.WatchData{
+				Contents: kv.Value,
+				Version:  RaftVersion(kv.ModRevision),
+			},
+		})
+	}
+
+	// Create the notifications channel, send updates to it. We start
+	// watching from the revision of the initial read.
+	notifications := make(chan *topo.WatchDataRecursive, 10)
+	go func() {
+		defer close(notifications)
+
+		err := s.watch(ctx, nodePath, true, initial.Revision, func(ev *rafttopodatapb.Event) bool {
+			switch ev.Type {
+			case rafttopodatapb.Event_PUT:
+				notifications <- &topo.WatchDataRecursive{
+					Path: ev.Kv.Key,
+					WatchData: topo.WatchData{
+						Contents: ev.Kv.Value,
+						Version:  RaftVersion(ev.Kv.ModRevision),
+					},
+				}
+			case rafttopodatapb.Event_DELETE:
+				notifications <- &topo.WatchDataRecursive{
+					Path: ev.Kv.Key,
+					WatchData: topo.WatchData{
+						Err: topo.NewError(topo.NoNode, nodePath),
+					},
+				}
+			}
+			return true
+		})
+		if err != nil && !errors.Is(err, errClosed) {
+			// This includes context cancellation errors.
+			notifications <- &topo.WatchDataRecursive{
+				WatchData: topo.WatchData{Err: convertError(err, nodePath)},
+			}
+		}
+	}()
+
+	return initialwd, notifications, nil
+}
+
+// watch calls onEvent for the changes of a key, or of all the keys with a
+// prefix, after the given revision, until onEvent returns false. The
+// watch is restarted from the last revision it received when the leader
+// changes. It returns the error that stopped the watch, not converted:
+// the context error if ctx is done, errClosed if the Server is closed, or
+// an OUT_OF_RANGE error if the events after the last revision aren't in
+// the history of the store anymore.
+func (s *Server) watch(ctx context.Context, key string, prefix bool, revision int64, onEvent func(*rafttopodatapb.Event) bool) error {
+	var retries int
+	for {
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		stream, wresp, err := s.startWatch(streamCtx, &rafttopodatapb.WatchRequest{
+			Key:           key,
+			Prefix:        prefix,
+			StartRevision: revision,
+		})
+		for err == nil {
+			retries = 0
+			for _, ev := range wresp.Events {
+				revision = ev.Kv.ModRevision
+				if !onEvent(ev) {
+					streamCancel()
+					return nil
+				}
+			}
+			wresp, err = stream.Recv()
+		}
+		streamCancel()
+
+		select {
+		case <-s.running:
+			return errClosed
+		default:
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if status.Code(err) == codes.OutOfRange {
+			return err
+		}
+
+		// The leader changed or the watch was too slow, watch again.
+		retries++
+		t := time.NewTimer(min(time.Duration(retries)*retryInterval, maxWatchRetryInterval))
+		select {
+		case <-t.C:
+		case <-s.running:
+			t.Stop()
+			return errClosed
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// startWatch starts a watch on the leader, and returns its first response.
+// The returned error isn't converted.
+func (s *Server) startWatch(ctx context.Context, req *rafttopodatapb.WatchRequest) (rafttoposervicepb.RaftTopo_WatchClient, *rafttopodatapb.WatchResponse, error) {
+	var stream rafttoposervicepb.RaftTopo_WatchClient
+	var first *rafttopodatapb.WatchResponse
+	err := s.call(ctx, func(client rafttoposervicepb.RaftTopoClient) (err error) {
+		stream, err = client.Watch(ctx, req)
+		if err != nil {
+			return err
+		}
+		// The errors of the member, like not being the leader, are only
+		// returned when receiving.
+		first, err = stream.Recv()
+		return err
+	})
+	return stream, first, err
+}
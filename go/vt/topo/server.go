@@ -140,6 +140,10 @@ type Server struct {
 	// It is set at construction time.
 	factory Factory
 
+	// auditor holds the audit sink shared by the connections of all
+	// the cells.
+	auditor *auditor
+
 	// mu protects the following fields.
 	mu sync.Mutex
 	// cellConns contains clients configured to talk to a list of
@@ -181,7 +185,7 @@ var (
 	}
 
 	FlagBinaries = []string{"vttablet", "vtctl", "vtctld", "vtcombo", "vtgate",
-		"vtorc", "vtbackup"}
+		"vtorc", "vtbackup", "vtcdc"}
 
 	// Default read concurrency to use in order to avoid overhwelming the topo server.
 	DefaultReadConcurrency int64 = 32
@@ -214,11 +218,12 @@ func RegisterFactory(name string, factory Factory) {
 // It also opens the global cell connection.
 func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error) {
 	globalReadSem := semaphore.NewWeighted(DefaultReadConcurrency)
+	audit := &auditor{}
 	conn, err := factory.Create(GlobalCell, serverAddress, root)
 	if err != nil {
 		return nil, err
 	}
-	conn = NewStatsConn(GlobalCell, conn, globalReadSem)
+	conn = NewStatsConn(GlobalCell, newAuditConn(GlobalCell, conn, audit), globalReadSem)
 
 	var connReadOnly Conn
 	if factory.HasGlobalReadOnlyCell(serverAddress, root) {
@@ -235,6 +240,7 @@ func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error
 		globalCell:         conn,
 		globalReadOnlyCell: connReadOnly,
 		factory:            factory,
+		auditor:            audit,
 		cellConns:          make(map[string]cellConn),
 	}, nil
 }
@@ -262,6 +268,9 @@ func Open() *Server {
 	if err != nil {
 		log.Exitf("Failed to open topo server (%v,%v,%v): %v", topoImplementation, topoGlobalServerAddress, topoGlobalRoot, err)
 	}
+	if err := ts.openAuditSinkFromFlags(); err != nil {
+		log.Exitf("Failed to open topo audit sink: %v", err)
+	}
 	return ts
 }
 
@@ -309,7 +318,7 @@ func (ts *Server) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	switch {
 	case err == nil:
 		cellReadSem := semaphore.NewWeighted(DefaultReadConcurrency)
-		conn = NewStatsConn(cell, conn, cellReadSem)
+		conn = NewStatsConn(cell, newAuditConn(cell, conn, ts.auditor), cellReadSem)
 		ts.cellConns[cell] = cellConn{ci, conn}
 		return conn, nil
 	case IsErrType(err, NoNode):
@@ -365,6 +374,7 @@ func (ts *Server) Close() {
 		cc.conn.Close()
 	}
 	ts.cellConns = make(map[string]cellConn)
+	ts.SetAuditSink(nil)
 }
 
 func (ts *Server) clearCellAliasesCache() {
@@ -0,0 +1,435 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// TopoSnapshotFormatVersion is the version of the topo snapshot format
+// written by this binary. Snapshots with a newer format are refused.
+const TopoSnapshotFormatVersion = 1
+
+// ExportTopo reads every persistent file in the global cell and in the given
+// cells into a snapshot. If cells is empty, all known cells are exported.
+// Ephemeral entries, such as locks and elections, are skipped.
+func ExportTopo(ctx context.Context, ts *topo.Server, cells []string) (*vtctldatapb.TopoSnapshot, error) {
+	if len(cells) == 0 {
+		var err error
+		cells, err = ts.GetKnownCells(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cells = slices.Clone(cells)
+	sort.Strings(cells)
+
+	snapshot := &vtctldatapb.TopoSnapshot{
+		FormatVersion: TopoSnapshotFormatVersion,
+		Time:          protoutil.TimeToProto(time.Now()),
+		Cells:         cells,
+	}
+	for _, cell := range append([]string{topo.GlobalCell}, cells...) {
+		conn, err := ts.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, err
+		}
+		files, err := exportCell(ctx, conn, cell, "/")
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to export cell %s", cell)
+		}
+		snapshot.Files = append(snapshot.Files, files...)
+	}
+	return snapshot, nil
+}
+
+// exportCell recursively reads the persistent files under dir.
+func exportCell(ctx context.Context, conn topo.Conn, cell string, dir string) ([]*vtctldatapb.TopoSnapshotFile, error) {
+	entries, err := conn.ListDir(ctx, dir, true /*full*/)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		// The directory was removed while we were walking the tree.
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var files []*vtctldatapb.TopoSnapshotFile
+	for _, entry := range entries {
+		if entry.Ephemeral {
+			continue
+		}
+		p := path.Join(dir, entry.Name)
+		if entry.Type == topo.TypeDirectory {
+			children, err := exportCell(ctx, conn, cell, p)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, children...)
+			continue
+		}
+
+		contents, version, err := conn.Get(ctx, p)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		files = append(files, &vtctldatapb.TopoSnapshotFile{
+			Cell:     cell,
+			Path:     strings.TrimPrefix(p, "/"),
+			Contents: contents,
+			Version:  version.String(),
+		})
+	}
+	return files, nil
+}
+
+// WriteTopoSnapshot writes a gzip-compressed snapshot to w.
+func WriteTopoSnapshot(w io.Writer, snapshot *vtctldatapb.TopoSnapshot) error {
+	data, err := proto.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadTopoSnapshot reads a snapshot written by WriteTopoSnapshot.
+func ReadTopoSnapshot(r io.Reader) (*vtctldatapb.TopoSnapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to read topo snapshot")
+	}
+	defer gz.Close()
+
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to read topo snapshot")
+	}
+	snapshot := &vtctldatapb.TopoSnapshot{}
+	if err := proto.Unmarshal(data, snapshot); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to decode topo snapshot")
+	}
+	if err := checkTopoSnapshotFormat(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func checkTopoSnapshotFormat(snapshot *vtctldatapb.TopoSnapshot) error {
+	if snapshot.GetFormatVersion() < 1 || snapshot.GetFormatVersion() > TopoSnapshotFormatVersion {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "unsupported topo snapshot format version %d, this binary supports up to %d", snapshot.GetFormatVersion(), TopoSnapshotFormatVersion)
+	}
+	return nil
+}
+
+// RestoreTopoOptions selects what RestoreTopo writes back from a snapshot.
+type RestoreTopoOptions struct {
+	// Keyspaces to restore. If empty, every keyspace in the snapshot is
+	// restored, along with the routing rules.
+	Keyspaces []string
+	// VSchemaOnly restores only the VSchema of each keyspace.
+	VSchemaOnly bool
+	// IncludeShards restores the Shard records of each keyspace too. The
+	// primary of a shard that exists in the topo is kept.
+	IncludeShards bool
+	// Cells in which the SrvKeyspace of the restored keyspaces is rebuilt.
+	// If empty, it is rebuilt in every cell in the snapshot.
+	Cells []string
+	// DryRun returns the files that would be written without writing them.
+	DryRun bool
+}
+
+// RestoreTopo writes the Keyspace and VSchema records of keyspaces, and the
+// routing rules, from a snapshot back into the topo. Shard records are only
+// restored with IncludeShards, and cell files are never written: the
+// SrvKeyspace is rebuilt from the restored records instead, and the
+// ShardReplication records are left to the tablets. Only files whose contents
+// differ from the live topo are written, and files that are not in the
+// snapshot are left alone. Existing keyspaces are locked while their files
+// are written, and the SrvVSchema is rebuilt in every cell if any VSchema or
+// routing rules changed. It returns the files that were written.
+func RestoreTopo(ctx context.Context, ts *topo.Server, snapshot *vtctldatapb.TopoSnapshot, opts RestoreTopoOptions) ([]*vtctldatapb.TopoSnapshotFile, error) {
+	if err := checkTopoSnapshotFormat(snapshot); err != nil {
+		return nil, err
+	}
+
+	routingRulesFiles := []string{topo.RoutingRulesFile, topo.ShardRoutingRulesFile, ts.GetKeyspaceRoutingRulesPath()}
+	var routingRules []*vtctldatapb.TopoSnapshotFile
+	filesByKeyspace := map[string][]*vtctldatapb.TopoSnapshotFile{}
+	for _, file := range snapshot.GetFiles() {
+		if file.GetCell() != topo.GlobalCell {
+			continue
+		}
+		if slices.Contains(routingRulesFiles, file.GetPath()) {
+			routingRules = append(routingRules, file)
+		} else if keyspace, _ := keyspaceForPath(file.GetPath()); keyspace != "" {
+			filesByKeyspace[keyspace] = append(filesByKeyspace[keyspace], file)
+		}
+	}
+
+	keyspaces := opts.Keyspaces
+	if len(keyspaces) == 0 {
+		for keyspace := range filesByKeyspace {
+			keyspaces = append(keyspaces, keyspace)
+		}
+	}
+	keyspaces = slices.Clone(keyspaces)
+	sort.Strings(keyspaces)
+	for _, keyspace := range keyspaces {
+		if _, ok := filesByKeyspace[keyspace]; !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "keyspace %s not found in topo snapshot", keyspace)
+		}
+	}
+
+	cells := opts.Cells
+	if len(cells) == 0 {
+		cells = snapshot.GetCells()
+	}
+	for _, cell := range cells {
+		if !slices.Contains(snapshot.GetCells(), cell) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "cell %s not found in topo snapshot", cell)
+		}
+	}
+
+	var (
+		written           []*vtctldatapb.TopoSnapshotFile
+		rebuildSrvVSchema bool
+	)
+	for _, keyspace := range keyspaces {
+		var files []*vtctldatapb.TopoSnapshotFile
+		for _, file := range filesByKeyspace[keyspace] {
+			_, rest := keyspaceForPath(file.GetPath())
+			switch {
+			case rest == topo.VSchemaFile:
+			case opts.VSchemaOnly:
+				continue
+			case rest == topo.KeyspaceFile:
+			case opts.IncludeShards && isShardFile(rest):
+			default:
+				continue
+			}
+			files = append(files, file)
+		}
+
+		changed, err := restoreKeyspaceFiles(ctx, ts, keyspace, files, opts.DryRun)
+		if err != nil {
+			return written, err
+		}
+		rebuildKeyspace := false
+		for _, file := range changed {
+			if _, rest := keyspaceForPath(file.GetPath()); rest == topo.VSchemaFile {
+				rebuildSrvVSchema = true
+			} else {
+				rebuildKeyspace = true
+			}
+		}
+		written = append(written, changed...)
+
+		if rebuildKeyspace && !opts.DryRun {
+			if err := RebuildKeyspace(ctx, logutil.NewConsoleLogger(), ts, keyspace, cells, false); err != nil {
+				return written, vterrors.Wrapf(err, "failed to rebuild SrvKeyspace of keyspace %s", keyspace)
+			}
+		}
+	}
+
+	if len(opts.Keyspaces) == 0 && !opts.VSchemaOnly {
+		changed, err := restoreRoutingRules(ctx, ts, routingRules, opts.DryRun)
+		if err != nil {
+			return written, err
+		}
+		rebuildSrvVSchema = rebuildSrvVSchema || len(changed) > 0
+		written = append(written, changed...)
+	}
+
+	if rebuildSrvVSchema && !opts.DryRun {
+		if err := ts.RebuildSrvVSchema(ctx, nil); err != nil {
+			return written, vterrors.Wrapf(err, "failed to rebuild SrvVSchema")
+		}
+	}
+	return written, nil
+}
+
+// restoreKeyspaceFiles writes the given global files of a single keyspace,
+// holding the keyspace lock if the keyspace already exists. Shard records
+// keep the primary of the live shard.
+func restoreKeyspaceFiles(ctx context.Context, ts *topo.Server, keyspace string, files []*vtctldatapb.TopoSnapshotFile, dryRun bool) (written []*vtctldatapb.TopoSnapshotFile, err error) {
+	if !dryRun {
+		_, err = ts.GetKeyspace(ctx, keyspace)
+		switch {
+		case err == nil:
+			var unlock func(*error)
+			ctx, unlock, err = ts.LockKeyspace(ctx, keyspace, "RestoreTopo")
+			if err != nil {
+				return nil, err
+			}
+			defer unlock(&err)
+		case topo.IsErrType(err, topo.NoNode):
+			// The keyspace is being recreated, so there is nothing to lock.
+			err = nil
+		default:
+			return nil, err
+		}
+	}
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if _, rest := keyspaceForPath(file.GetPath()); isShardFile(rest) {
+			if file, err = keepShardPrimary(ctx, conn, file); err != nil {
+				return written, err
+			}
+		}
+		changed, err := restoreFile(ctx, conn, file, dryRun)
+		if err != nil {
+			return written, err
+		}
+		if changed {
+			written = append(written, file)
+		}
+	}
+	return written, nil
+}
+
+// restoreRoutingRules writes the given routing rules files, holding the
+// routing rules lock if the keyspace routing rules already exist.
+func restoreRoutingRules(ctx context.Context, ts *topo.Server, files []*vtctldatapb.TopoSnapshotFile, dryRun bool) (written []*vtctldatapb.TopoSnapshotFile, err error) {
+	if !dryRun {
+		lockCtx, unlock, lockErr := ts.LockRoutingRules(ctx, "RestoreTopo")
+		switch {
+		case lockErr == nil:
+			ctx = lockCtx
+			defer unlock(&err)
+		case topo.IsErrType(lockErr, topo.NoNode):
+			// There are no keyspace routing rules yet, so there is nothing to lock.
+		default:
+			return nil, lockErr
+		}
+	}
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		changed, err := restoreFile(ctx, conn, file, dryRun)
+		if err != nil {
+			return written, err
+		}
+		if changed {
+			written = append(written, file)
+		}
+	}
+	return written, nil
+}
+
+// restoreFile writes a file unless its contents already match the live topo,
+// and returns whether it was, or in a dry run would be, written.
+func restoreFile(ctx context.Context, conn topo.Conn, file *vtctldatapb.TopoSnapshotFile, dryRun bool) (bool, error) {
+	current, _, err := conn.Get(ctx, file.GetPath())
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		// The file is missing and will be created.
+	case err != nil:
+		return false, err
+	case bytes.Equal(current, file.GetContents()):
+		return false, nil
+	}
+
+	if !dryRun {
+		if _, err := conn.Update(ctx, file.GetPath(), file.GetContents(), nil); err != nil {
+			return false, vterrors.Wrapf(err, "failed to write %s in cell %s", file.GetPath(), file.GetCell())
+		}
+	}
+	return true, nil
+}
+
+// keepShardPrimary returns the Shard record of the snapshot file with the
+// primary alias and term start time of the live shard, which the snapshot
+// can't know about. The file is returned as is if the shard doesn't exist.
+func keepShardPrimary(ctx context.Context, conn topo.Conn, file *vtctldatapb.TopoSnapshotFile) (*vtctldatapb.TopoSnapshotFile, error) {
+	current, _, err := conn.Get(ctx, file.GetPath())
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return file, nil
+	case err != nil:
+		return nil, err
+	}
+	live := &topodatapb.Shard{}
+	if err := live.UnmarshalVT(current); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to decode %s", file.GetPath())
+	}
+	shard := &topodatapb.Shard{}
+	if err := shard.UnmarshalVT(file.GetContents()); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to decode %s in topo snapshot", file.GetPath())
+	}
+	shard.PrimaryAlias = live.PrimaryAlias
+	shard.PrimaryTermStartTime = live.PrimaryTermStartTime
+	if proto.Equal(shard, live) {
+		// Keep the live contents, so that the file is not written.
+		return &vtctldatapb.TopoSnapshotFile{Cell: file.GetCell(), Path: file.GetPath(), Contents: current, Version: file.GetVersion()}, nil
+	}
+	contents, err := shard.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.TopoSnapshotFile{Cell: file.GetCell(), Path: file.GetPath(), Contents: contents, Version: file.GetVersion()}, nil
+}
+
+// isShardFile returns true if the path within a keyspace directory is the
+// Shard record of a shard.
+func isShardFile(rest string) bool {
+	parts := strings.Split(rest, "/")
+	return len(parts) == 3 && parts[0] == topo.ShardsPath && parts[2] == topo.ShardFile
+}
+
+// keyspaceForPath returns the keyspace a topo path belongs to, and the rest
+// of the path within the keyspace directory. The keyspace is empty for paths
+// outside of the keyspaces directory.
+func keyspaceForPath(filePath string) (keyspace string, rest string) {
+	parts := strings.SplitN(filePath, "/", 3)
+	if len(parts) < 3 || parts[0] != topo.KeyspacesPath {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
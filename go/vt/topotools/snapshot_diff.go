@@ -0,0 +1,229 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// TopoFileChange is the kind of difference found for a file when diffing two
+// topo snapshots.
+type TopoFileChange int
+
+const (
+	// TopoFileAdded means the file is only in the second snapshot.
+	TopoFileAdded TopoFileChange = iota
+	// TopoFileRemoved means the file is only in the first snapshot.
+	TopoFileRemoved
+	// TopoFileChanged means the file contents differ between the snapshots.
+	TopoFileChanged
+)
+
+func (c TopoFileChange) String() string {
+	switch c {
+	case TopoFileAdded:
+		return "added"
+	case TopoFileRemoved:
+		return "removed"
+	case TopoFileChanged:
+		return "changed"
+	}
+	return fmt.Sprintf("unknown(%d)", int(c))
+}
+
+// TopoFileDiff describes how a single file differs between two topo
+// snapshots.
+type TopoFileDiff struct {
+	Cell   string
+	Path   string
+	Change TopoFileChange
+	// Fields lists the changed fields as "field: old => new" for files that
+	// decode as a known topo protobuf. It is empty for other files.
+	Fields []string
+}
+
+// topoFileKey identifies a file across snapshots.
+type topoFileKey struct {
+	cell string
+	path string
+}
+
+// DiffTopoSnapshots compares two snapshots file by file. Files that Vitess
+// knows how to decode are compared semantically, so that files which only
+// differ in their encoding are not reported. The result is sorted by cell,
+// with the global cell first, and then by path.
+func DiffTopoSnapshots(from, to *vtctldatapb.TopoSnapshot) ([]*TopoFileDiff, error) {
+	fromFiles := map[topoFileKey]*vtctldatapb.TopoSnapshotFile{}
+	for _, file := range from.GetFiles() {
+		fromFiles[topoFileKey{file.GetCell(), file.GetPath()}] = file
+	}
+	toFiles := map[topoFileKey]*vtctldatapb.TopoSnapshotFile{}
+	for _, file := range to.GetFiles() {
+		toFiles[topoFileKey{file.GetCell(), file.GetPath()}] = file
+	}
+
+	var diffs []*TopoFileDiff
+	for key, fromFile := range fromFiles {
+		toFile, ok := toFiles[key]
+		if !ok {
+			diffs = append(diffs, &TopoFileDiff{Cell: key.cell, Path: key.path, Change: TopoFileRemoved})
+			continue
+		}
+		if bytes.Equal(fromFile.GetContents(), toFile.GetContents()) {
+			continue
+		}
+		fields, equal, err := diffTopoFileContents(key.path, fromFile.GetContents(), toFile.GetContents())
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			diffs = append(diffs, &TopoFileDiff{Cell: key.cell, Path: key.path, Change: TopoFileChanged, Fields: fields})
+		}
+	}
+	for key := range toFiles {
+		if _, ok := fromFiles[key]; !ok {
+			diffs = append(diffs, &TopoFileDiff{Cell: key.cell, Path: key.path, Change: TopoFileAdded})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Cell != diffs[j].Cell {
+			if diffs[i].Cell == topo.GlobalCell || diffs[j].Cell == topo.GlobalCell {
+				return diffs[i].Cell == topo.GlobalCell
+			}
+			return diffs[i].Cell < diffs[j].Cell
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// diffTopoFileContents decodes both versions of a file through the topo
+// protobuf types and returns the changed fields, and whether the two
+// versions are semantically equal. Files that are not known topo protobufs,
+// or that fail to decode, are reported as changed with no fields.
+func diffTopoFileContents(filePath string, from, to []byte) (fields []string, equal bool, err error) {
+	fromMsg := topo.ProtoForPath("/" + filePath)
+	if fromMsg == nil {
+		return nil, false, nil
+	}
+	toMsg := fromMsg.ProtoReflect().New().Interface()
+	if proto.Unmarshal(from, fromMsg) != nil || proto.Unmarshal(to, toMsg) != nil {
+		return nil, false, nil
+	}
+	if proto.Equal(fromMsg, toMsg) {
+		return nil, true, nil
+	}
+
+	fromValue, err := protoToJSONValue(fromMsg)
+	if err != nil {
+		return nil, false, err
+	}
+	toValue, err := protoToJSONValue(toMsg)
+	if err != nil {
+		return nil, false, err
+	}
+	diffJSONValues("", fromValue, toValue, &fields)
+	return fields, false, nil
+}
+
+func protoToJSONValue(m proto.Message) (any, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// diffJSONValues appends a "field: old => new" line to fields for every
+// leaf that differs between from and to. Missing values are shown as <none>.
+func diffJSONValues(field string, from, to any, fields *[]string) {
+	fromMap, fromIsMap := from.(map[string]any)
+	toMap, toIsMap := to.(map[string]any)
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for key := range fromMap {
+			keys = append(keys, key)
+		}
+		for key := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if field != "" {
+				child = field + "." + key
+			}
+			fromChild, fromOK := fromMap[key]
+			toChild, toOK := toMap[key]
+			switch {
+			case !fromOK:
+				*fields = append(*fields, fmt.Sprintf("%s: <none> => %s", child, jsonString(toChild)))
+			case !toOK:
+				*fields = append(*fields, fmt.Sprintf("%s: %s => <none>", child, jsonString(fromChild)))
+			default:
+				diffJSONValues(child, fromChild, toChild, fields)
+			}
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]any)
+	toList, toIsList := to.([]any)
+	if fromIsList && toIsList {
+		for i := 0; i < max(len(fromList), len(toList)); i++ {
+			child := fmt.Sprintf("%s[%d]", field, i)
+			switch {
+			case i >= len(fromList):
+				*fields = append(*fields, fmt.Sprintf("%s: <none> => %s", child, jsonString(toList[i])))
+			case i >= len(toList):
+				*fields = append(*fields, fmt.Sprintf("%s: %s => <none>", child, jsonString(fromList[i])))
+			default:
+				diffJSONValues(child, fromList[i], toList[i], fields)
+			}
+		}
+		return
+	}
+
+	if fromString, toString := jsonString(from), jsonString(to); fromString != toString {
+		*fields = append(*fields, fmt.Sprintf("%s: %s => %s", field, fromString, toString))
+	}
+}
+
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
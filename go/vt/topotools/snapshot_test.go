@@ -0,0 +1,271 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func setupSnapshotTopo(ctx context.Context, t *testing.T) *topo.Server {
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, &topo.KeyspaceVSchemaInfo{
+		Name: "ks",
+		Keyspace: &vschemapb.Keyspace{
+			Tables: map[string]*vschemapb.Table{"t1": {}},
+		},
+	}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_REPLICA,
+	}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "other", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t1", ToTables: []string{"ks.t1"}}},
+	}))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+	return ts
+}
+
+func findSnapshotFile(snapshot *vtctldatapb.TopoSnapshot, cell, filePath string) *vtctldatapb.TopoSnapshotFile {
+	for _, file := range snapshot.GetFiles() {
+		if file.Cell == cell && file.Path == filePath {
+			return file
+		}
+	}
+	return nil
+}
+
+func TestExportTopo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := setupSnapshotTopo(ctx, t)
+	defer ts.Close()
+
+	snapshot, err := ExportTopo(ctx, ts, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, TopoSnapshotFormatVersion, snapshot.FormatVersion)
+	assert.Equal(t, []string{"zone1", "zone2"}, snapshot.Cells)
+	for _, file := range []struct{ cell, path string }{
+		{topo.GlobalCell, "keyspaces/ks/Keyspace"},
+		{topo.GlobalCell, "keyspaces/ks/VSchema"},
+		{topo.GlobalCell, "keyspaces/ks/shards/0/Shard"},
+		{"zone1", "tablets/zone1-0000000100/Tablet"},
+		{"zone1", "keyspaces/ks/shards/0/ShardReplication"},
+		{"zone2", "SrvVSchema"},
+	} {
+		f := findSnapshotFile(snapshot, file.cell, file.path)
+		if assert.NotNil(t, f, "%s:%s", file.cell, file.path) {
+			assert.NotEmpty(t, f.Version)
+		}
+	}
+
+	snapshot, err = ExportTopo(ctx, ts, []string{"zone2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2"}, snapshot.Cells)
+	assert.Nil(t, findSnapshotFile(snapshot, "zone1", "tablets/zone1-0000000100/Tablet"))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTopoSnapshot(&buf, snapshot))
+	read, err := ReadTopoSnapshot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.String(), read.String())
+
+	buf.Reset()
+	snapshot.FormatVersion = TopoSnapshotFormatVersion + 1
+	require.NoError(t, WriteTopoSnapshot(&buf, snapshot))
+	_, err = ReadTopoSnapshot(&buf)
+	assert.ErrorContains(t, err, "unsupported topo snapshot format version")
+}
+
+func TestRestoreTopo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := setupSnapshotTopo(ctx, t)
+	defer ts.Close()
+
+	snapshot, err := ExportTopo(ctx, ts, nil)
+	require.NoError(t, err)
+
+	// Break the VSchema and the keyspace record.
+	vs, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	vs.Tables = nil
+	require.NoError(t, ts.SaveVSchema(ctx, vs))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "TestRestoreTopo")
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(lockCtx, "ks")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+
+	files, err := RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{VSchemaOnly: true, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "keyspaces/ks/VSchema", files[0].Path)
+	vs, err = ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, vs.Tables)
+
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"ks"}, VSchemaOnly: true})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	vs, err = ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.Contains(t, vs.Tables, "t1")
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone2")
+	require.NoError(t, err)
+	assert.Contains(t, srvVSchema.Keyspaces["ks"].Tables, "t1")
+	ki, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"ks"}})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "keyspaces/ks/Keyspace", files[0].Path)
+	ki, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, ki.DurabilityPolicy)
+	// The SrvKeyspace is rebuilt rather than restored.
+	_, err = ts.GetSrvKeyspace(ctx, "zone1", "ks")
+	require.NoError(t, err)
+
+	// Shards are only restored when asked to, and keep their live primary.
+	primaryAlias := &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}
+	_, err = ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primaryAlias
+		si.IsPrimaryServing = false
+		return nil
+	})
+	require.NoError(t, err)
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"ks"}})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"ks"}, IncludeShards: true})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "keyspaces/ks/shards/0/Shard", files[0].Path)
+	si, err := ts.GetShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	assert.True(t, si.IsPrimaryServing)
+	assert.True(t, proto.Equal(primaryAlias, si.PrimaryAlias), si.PrimaryAlias)
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"ks"}, IncludeShards: true})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	// The routing rules are restored with all the keyspaces.
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{}))
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, topo.RoutingRulesFile, files[0].Path)
+	srvVSchema, err = ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	require.Len(t, srvVSchema.RoutingRules.GetRules(), 1)
+	assert.Equal(t, "t1", srvVSchema.RoutingRules.Rules[0].FromTable)
+
+	// A deleted keyspace is recreated, but the ShardReplication records are
+	// left to the tablets.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	require.NoError(t, conn.Delete(ctx, path.Join(topo.KeyspacesPath, "ks", topo.ShardsPath, "0", topo.ShardFile), nil))
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	require.NoError(t, ts.DeleteShardReplication(ctx, "zone1", "ks", "0"))
+	files, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{IncludeShards: true})
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	_, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	_, err = ts.GetShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	_, err = ts.GetShardReplication(ctx, "zone1", "ks", "0")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	_, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Keyspaces: []string{"missing"}})
+	assert.ErrorContains(t, err, "keyspace missing not found in topo snapshot")
+	_, err = RestoreTopo(ctx, ts, snapshot, RestoreTopoOptions{Cells: []string{"zone3"}})
+	assert.ErrorContains(t, err, "cell zone3 not found in topo snapshot")
+}
+
+func TestDiffTopoSnapshots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := setupSnapshotTopo(ctx, t)
+	defer ts.Close()
+
+	from, err := ExportTopo(ctx, ts, nil)
+	require.NoError(t, err)
+
+	diffs, err := DiffTopoSnapshots(from, from)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	vs, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	vs.Sharded = true
+	vs.Tables["t2"] = &vschemapb.Table{Type: "reference"}
+	require.NoError(t, ts.SaveVSchema(ctx, vs))
+	require.NoError(t, ts.DeleteTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "added", &topodatapb.Keyspace{}))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "custom/file", []byte("custom"))
+	require.NoError(t, err)
+
+	to, err := ExportTopo(ctx, ts, nil)
+	require.NoError(t, err)
+	// Unknown files are compared byte by byte.
+	from.Files = append(from.Files, &vtctldatapb.TopoSnapshotFile{Cell: topo.GlobalCell, Path: "custom/file", Contents: []byte("old")})
+
+	diffs, err = DiffTopoSnapshots(from, to)
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+
+	assert.Equal(t, &TopoFileDiff{Cell: topo.GlobalCell, Path: "custom/file", Change: TopoFileChanged}, diffs[0])
+	assert.Equal(t, &TopoFileDiff{Cell: topo.GlobalCell, Path: "keyspaces/added/Keyspace", Change: TopoFileAdded}, diffs[1])
+	assert.Equal(t, &TopoFileDiff{
+		Cell:   topo.GlobalCell,
+		Path:   "keyspaces/ks/VSchema",
+		Change: TopoFileChanged,
+		Fields: []string{
+			`sharded: <none> => true`,
+			`tables.t2: <none> => {"type":"reference"}`,
+		},
+	}, diffs[2])
+	assert.Equal(t, &TopoFileDiff{Cell: "zone1", Path: "tablets/zone1-0000000100/Tablet", Change: TopoFileRemoved}, diffs[3])
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// avroSourceSchema is the schema of the source block, in parsing canonical
+// form.
+const avroSourceSchema = `{"name":"io.debezium.connector.vitess.Source","type":"record","fields":[` +
+	`{"name":"version","type":"string"},` +
+	`{"name":"connector","type":"string"},` +
+	`{"name":"name","type":"string"},` +
+	`{"name":"ts_ms","type":"long"},` +
+	`{"name":"snapshot","type":"string"},` +
+	`{"name":"keyspace","type":"string"},` +
+	`{"name":"shard","type":"string"},` +
+	`{"name":"table","type":"string"},` +
+	`{"name":"vgtid","type":"string"}]}`
+
+// AvroEncoder encodes change events with the Avro single object encoding:
+// each key and value starts with the 0xC3 0x01 marker and the little endian
+// CRC-64-AVRO fingerprint of its schema, followed by the Avro binary
+// encoding of the datum.
+//
+// The schemas follow the Debezium layout, an Envelope record with before,
+// after, source, op and ts_ms fields, and are in parsing canonical form.
+// As there is no schema registry, the encoder publishes each schema the
+// first time it is used to the <prefix>.schemas topic, keyed by the hex
+// fingerprint, so that consumers can resolve the fingerprints.
+type AvroEncoder struct {
+	schemaTopic string
+
+	mu      sync.Mutex
+	schemas map[*Table]*avroSchemas
+	// published holds the fingerprints of the schemas already published.
+	published map[uint64]bool
+}
+
+type avroSchemas struct {
+	key           string
+	keyPrint      uint64
+	envelope      string
+	envelopePrint uint64
+}
+
+// NewAvroEncoder returns an Avro encoder publishing its schemas to the
+// <topicPrefix>.schemas topic.
+func NewAvroEncoder(topicPrefix string) *AvroEncoder {
+	return &AvroEncoder{
+		schemaTopic: topicPrefix + ".schemas",
+		schemas:     make(map[*Table]*avroSchemas),
+		published:   make(map[uint64]bool),
+	}
+}
+
+// Encode is part of the Encoder interface.
+func (ae *AvroEncoder) Encode(ev *ChangeEvent) ([]*Record, error) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	var records []*Record
+	schemas, ok := ae.schemas[ev.Table]
+	if !ok {
+		schemas = newAvroSchemas(ev.Topic, ev.Table)
+		ae.schemas[ev.Table] = schemas
+	}
+	publish := func(schema string, fingerprint uint64) {
+		if schema == "" || ae.published[fingerprint] {
+			return
+		}
+		ae.published[fingerprint] = true
+		records = append(records, &Record{
+			Topic: ae.schemaTopic,
+			Key:   []byte(fmt.Sprintf("%016x", fingerprint)),
+			Value: []byte(schema),
+		})
+	}
+	publish(schemas.key, schemas.keyPrint)
+	publish(schemas.envelope, schemas.envelopePrint)
+
+	record := &Record{Topic: ev.Topic}
+	if key := ev.Key(); key != nil {
+		buf := avroHeader(schemas.keyPrint)
+		for i, value := range key {
+			field := ev.Table.Fields[ev.Table.PKColumns[i]]
+			var err error
+			if buf, err = appendAvroValue(buf, kindOf(field.Type), value); err != nil {
+				return nil, err
+			}
+		}
+		record.Key = buf
+	}
+
+	buf := avroHeader(schemas.envelopePrint)
+	var err error
+	for _, row := range [][]sqltypes.Value{ev.Before, ev.After} {
+		if row == nil {
+			buf = appendAvroLong(buf, 0)
+			continue
+		}
+		buf = appendAvroLong(buf, 1)
+		for i, value := range row {
+			if i >= len(ev.Table.Fields) {
+				return nil, fmt.Errorf("row of %s.%s has more values than fields", ev.Table.Keyspace, ev.Table.Name)
+			}
+			if value.IsNull() {
+				buf = appendAvroLong(buf, 0)
+				continue
+			}
+			buf = appendAvroLong(buf, 1)
+			if buf, err = appendAvroValue(buf, kindOf(ev.Table.Fields[i].Type), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	src := ev.Source
+	buf = appendAvroString(buf, src.Version)
+	buf = appendAvroString(buf, src.Connector)
+	buf = appendAvroString(buf, src.Name)
+	buf = appendAvroLong(buf, src.TsMs)
+	buf = appendAvroString(buf, src.Snapshot)
+	buf = appendAvroString(buf, src.Keyspace)
+	buf = appendAvroString(buf, src.Shard)
+	buf = appendAvroString(buf, src.Table)
+	buf = appendAvroString(buf, src.Vgtid)
+	buf = appendAvroString(buf, string(ev.Op))
+	buf = appendAvroLong(buf, 1)
+	buf = appendAvroLong(buf, ev.TsMs)
+	record.Value = buf
+
+	return append(records, record), nil
+}
+
+func newAvroSchemas(topic string, table *Table) *avroSchemas {
+	namespace := avroNamespace(topic)
+	schemas := &avroSchemas{}
+
+	var valueFields []string
+	for _, field := range table.Fields {
+		valueFields = append(valueFields, fmt.Sprintf(`{"name":%s,"type":["null",%q]}`, avroName(field.Name), avroType(kindOf(field.Type))))
+	}
+	value := fmt.Sprintf(`{"name":"%s.Value","type":"record","fields":[%s]}`, namespace, strings.Join(valueFields, ","))
+	schemas.envelope = fmt.Sprintf(`{"name":"%s.Envelope","type":"record","fields":[`+
+		`{"name":"before","type":["null",%s]},`+
+		`{"name":"after","type":["null","%s.Value"]},`+
+		`{"name":"source","type":%s},`+
+		`{"name":"op","type":"string"},`+
+		`{"name":"ts_ms","type":["null","long"]}]}`,
+		namespace, value, namespace, avroSourceSchema)
+	schemas.envelopePrint = avroFingerprint(schemas.envelope)
+
+	if len(table.PKColumns) > 0 {
+		var keyFields []string
+		for _, idx := range table.PKColumns {
+			field := table.Fields[idx]
+			keyFields = append(keyFields, fmt.Sprintf(`{"name":%s,"type":%q}`, avroName(field.Name), avroType(kindOf(field.Type))))
+		}
+		schemas.key = fmt.Sprintf(`{"name":"%s.Key","type":"record","fields":[%s]}`, namespace, strings.Join(keyFields, ","))
+		schemas.keyPrint = avroFingerprint(schemas.key)
+	}
+	return schemas
+}
+
+// avroNamespace turns a topic into a valid Avro namespace.
+func avroNamespace(topic string) string {
+	parts := strings.Split(topic, ".")
+	for i, part := range parts {
+		parts[i] = sanitizeAvroName(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// avroName returns the quoted Avro name for a column.
+func avroName(name string) string {
+	b, _ := json.Marshal(sanitizeAvroName(name))
+	return string(b)
+}
+
+// sanitizeAvroName replaces the characters which are not allowed in Avro
+// names, which must match [A-Za-z_][A-Za-z0-9_]*.
+func sanitizeAvroName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteByte('_')
+			}
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func avroType(kind valueKind) string {
+	switch kind {
+	case kindLong:
+		return "long"
+	case kindFloat:
+		return "float"
+	case kindDouble:
+		return "double"
+	case kindBytes:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+func avroHeader(fingerprint uint64) []byte {
+	buf := []byte{0xC3, 0x01}
+	return binary.LittleEndian.AppendUint64(buf, fingerprint)
+}
+
+func appendAvroValue(buf []byte, kind valueKind, value sqltypes.Value) ([]byte, error) {
+	switch kind {
+	case kindLong:
+		v, err := value.ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		return appendAvroLong(buf, v), nil
+	case kindFloat:
+		v, err := value.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v))), nil
+	case kindDouble:
+		v, err := value.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v)), nil
+	default:
+		buf = appendAvroLong(buf, int64(len(value.Raw())))
+		return append(buf, value.Raw()...), nil
+	}
+}
+
+// appendAvroLong appends the zig-zag varint encoding of v.
+func appendAvroLong(buf []byte, v int64) []byte {
+	return binary.AppendVarint(buf, v)
+}
+
+func appendAvroString(buf []byte, s string) []byte {
+	buf = appendAvroLong(buf, int64(len(s)))
+	return append(buf, s...)
+}
+
+const avroEmptyFingerprint = 0xc15d213aa4d7a795
+
+var avroFingerprintTable = func() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		fp := uint64(i)
+		for range 8 {
+			fp = (fp >> 1) ^ (avroEmptyFingerprint & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+// avroFingerprint returns the CRC-64-AVRO (Rabin) fingerprint of schema.
+func avroFingerprint(schema string) uint64 {
+	fp := uint64(avroEmptyFingerprint)
+	for i := 0; i < len(schema); i++ {
+		fp = (fp >> 8) ^ avroFingerprintTable[byte(fp)^schema[i]]
+	}
+	return fp
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestAvroFingerprint(t *testing.T) {
+	// Test vector of the Avro specification.
+	assert.Equal(t, uint64(7195948357588979594), avroFingerprint(`"null"`))
+}
+
+func TestAvroEncoder(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|name|score", "int64|varchar|float64")
+	fields[0].Flags = uint32(querypb.MySqlFlag_PRI_KEY_FLAG)
+	table := newTable("my-ks", "t", fields)
+	ev := &ChangeEvent{
+		Topic:  "cdc.my-ks.t",
+		Table:  table,
+		Op:     OpCreate,
+		After:  sqltypes.MakeTestResult(fields, "-1|ab|null").Rows[0],
+		Source: Source{Connector: ConnectorName, Name: "n", Snapshot: "false"},
+		TsMs:   3,
+	}
+
+	encoder := NewAvroEncoder("cdc")
+	records, err := encoder.Encode(ev)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	// The key and envelope schemas are published first.
+	schemas := make(map[string]string)
+	for _, record := range records[:2] {
+		assert.Equal(t, "cdc.schemas", record.Topic)
+		assert.True(t, json.Valid(record.Value), "%s", record.Value)
+		schemas[string(record.Key)] = string(record.Value)
+	}
+	assert.Equal(t, `{"name":"cdc.my_ks.t.Key","type":"record","fields":[{"name":"id","type":"long"}]}`,
+		schemas[fmt.Sprintf("%016x", binary.LittleEndian.Uint64(records[2].Key[2:10]))])
+	assert.Contains(t, schemas, fmt.Sprintf("%016x", binary.LittleEndian.Uint64(records[2].Value[2:10])))
+
+	record := records[2]
+	assert.Equal(t, "cdc.my-ks.t", record.Topic)
+	// id -1 is zig-zag encoded as 1.
+	assert.Equal(t, []byte{0xC3, 0x01}, record.Key[:2])
+	assert.Equal(t, []byte{0x01}, record.Key[10:])
+	assert.Equal(t, []byte{
+		0x00,       // before: null
+		0x02,       // after: Value
+		0x02, 0x01, // id: -1
+		0x02, 0x04, 'a', 'b', // name: "ab"
+		0x00, // score: null
+	}, record.Value[10:19])
+
+	// The schemas are published once.
+	records, err = encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
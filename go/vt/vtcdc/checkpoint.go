@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// CheckpointsPath is the path of the connector checkpoints in the global
+// topo.
+const CheckpointsPath = "vtcdc"
+
+// CheckpointStore saves the position of a connector in the global topo, so
+// that it resumes where it stopped when restarted.
+type CheckpointStore struct {
+	ts   *topo.Server
+	name string
+
+	// version is the topo version of the checkpoint, nil if there is none.
+	// It makes sure a single connector updates the checkpoint.
+	version topo.Version
+}
+
+// NewCheckpointStore returns the checkpoint store of the connector name.
+func NewCheckpointStore(ts *topo.Server, name string) *CheckpointStore {
+	return &CheckpointStore{
+		ts:   ts,
+		name: name,
+	}
+}
+
+func (cs *CheckpointStore) path() string {
+	return path.Join(CheckpointsPath, cs.name, "checkpoint")
+}
+
+// Load returns the saved position, or nil if there is none.
+func (cs *CheckpointStore) Load(ctx context.Context) (*binlogdatapb.VGtid, error) {
+	conn, err := cs.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	data, version, err := conn.Get(ctx, cs.path())
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			cs.version = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if err := vgtid.UnmarshalVT(data); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint of connector %s: %w", cs.name, err)
+	}
+	cs.version = version
+	return vgtid, nil
+}
+
+// Save saves the position. It fails if the checkpoint was changed since
+// it was last loaded or saved by this store, e.g. by another connector
+// running with the same name.
+func (cs *CheckpointStore) Save(ctx context.Context, vgtid *binlogdatapb.VGtid) error {
+	conn, err := cs.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	data, err := vgtid.MarshalVT()
+	if err != nil {
+		return err
+	}
+	var version topo.Version
+	if cs.version == nil {
+		version, err = conn.Create(ctx, cs.path(), data)
+	} else {
+		version, err = conn.Update(ctx, cs.path(), data, cs.version)
+	}
+	if err != nil {
+		if topo.IsErrType(err, topo.NodeExists) || topo.IsErrType(err, topo.BadVersion) {
+			return fmt.Errorf("checkpoint of connector %s was changed concurrently, is another connector running with the same name? %w", cs.name, err)
+		}
+		return err
+	}
+	cs.version = version
+	return nil
+}
+
+// Delete removes the checkpoint, so that the connector starts over.
+func (cs *CheckpointStore) Delete(ctx context.Context) error {
+	conn, err := cs.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	if err := conn.Delete(ctx, cs.path(), nil); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return err
+	}
+	cs.version = nil
+	return nil
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtcdc implements a change data capture connector: it consumes a
+// VStream from vtgate and writes Debezium compatible change events to a
+// sink, checkpointing its position in the topo.
+package vtcdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// ConnectorName is the connector name reported in the source block of the
+// change events.
+const ConnectorName = "vitess"
+
+// Streamer opens a VStream. *vtgateconn.VTGateConn implements it.
+type Streamer interface {
+	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+		filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error)
+}
+
+// Config configures a Connector.
+type Config struct {
+	// Name identifies the connector. It is the name of the checkpoint and
+	// is reported in the source block of the change events.
+	Name string
+	// TopicPrefix prefixes the topics, which are <prefix>.<keyspace>.<table>.
+	TopicPrefix string
+	// Version is reported in the source block of the change events.
+	Version string
+
+	TabletType topodatapb.TabletType
+	Filter     *binlogdatapb.Filter
+	Flags      *vtgatepb.VStreamFlags
+	// StartPosition is where the connector starts when there is no
+	// checkpoint. Shards with an empty position are copied first.
+	StartPosition *binlogdatapb.VGtid
+
+	// TombstonesOnDelete makes the connector follow each delete event with
+	// a tombstone, a record with the same key and no value, which lets
+	// Kafka compact away the row.
+	TombstonesOnDelete bool
+	// CheckpointInterval is the minimum time between checkpoints.
+	CheckpointInterval time.Duration
+}
+
+// Connector streams the changes of a VStream to a sink.
+type Connector struct {
+	cfg         Config
+	streamer    Streamer
+	encoder     Encoder
+	sink        Sink
+	checkpoints *CheckpointStore
+	now         func() time.Time
+
+	// pos is the position up to which all changes were written to the sink.
+	pos            *binlogdatapb.VGtid
+	lastCheckpoint time.Time
+	// checkpointed is set when pos has been saved.
+	checkpointed bool
+
+	tables map[string]*Table
+	tx     *transaction
+}
+
+// transaction buffers the changes of the transaction being streamed.
+type transaction struct {
+	changes []*ChangeEvent
+	vgtid   *binlogdatapb.VGtid
+}
+
+// NewConnector returns a connector. checkpoints may be nil, in which case
+// the connector always starts at cfg.StartPosition.
+func NewConnector(cfg Config, streamer Streamer, encoder Encoder, sink Sink, checkpoints *CheckpointStore) *Connector {
+	return &Connector{
+		cfg:         cfg,
+		streamer:    streamer,
+		encoder:     encoder,
+		sink:        sink,
+		checkpoints: checkpoints,
+		now:         time.Now,
+		tables:      make(map[string]*Table),
+	}
+}
+
+// Position returns the position up to which all changes were written to
+// the sink.
+func (c *Connector) Position() *binlogdatapb.VGtid {
+	return c.pos
+}
+
+// Run streams changes until ctx is done, the stream ends or fails. It can
+// be called again after an error, and then resumes at the last position
+// written to the sink.
+func (c *Connector) Run(ctx context.Context) error {
+	if c.pos == nil {
+		if err := c.loadPosition(ctx); err != nil {
+			return err
+		}
+	}
+	c.tables = make(map[string]*Table)
+	c.tx = nil
+
+	reader, err := c.streamer.VStream(ctx, c.cfg.TabletType, c.pos, c.cfg.Filter, c.cfg.Flags)
+	if err != nil {
+		return fmt.Errorf("cannot start VStream at %v: %w", c.pos, err)
+	}
+	for {
+		events, err := reader.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// Save the position the stream ended at.
+				return c.checkpoint(ctx, true)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		for _, event := range events {
+			if err := c.processEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Connector) loadPosition(ctx context.Context) error {
+	if c.checkpoints != nil {
+		vgtid, err := c.checkpoints.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot load the checkpoint of connector %s: %w", c.cfg.Name, err)
+		}
+		if vgtid != nil {
+			log.Infof("Connector %s resuming at checkpoint %v", c.cfg.Name, vgtid)
+			c.pos = vgtid
+			c.checkpointed = true
+			return nil
+		}
+	}
+	if c.cfg.StartPosition == nil || len(c.cfg.StartPosition.ShardGtids) == 0 {
+		return fmt.Errorf("connector %s has no checkpoint and no start position", c.cfg.Name)
+	}
+	log.Infof("Connector %s starting at %v", c.cfg.Name, c.cfg.StartPosition)
+	c.pos = c.cfg.StartPosition.CloneVT()
+	return nil
+}
+
+func (c *Connector) processEvent(ctx context.Context, event *binlogdatapb.VEvent) error {
+	switch event.Type {
+	case binlogdatapb.VEventType_BEGIN:
+		c.tx = &transaction{}
+	case binlogdatapb.VEventType_FIELD:
+		keyspace, name := splitTableName(event.FieldEvent.Keyspace, event.FieldEvent.TableName)
+		c.tables[event.FieldEvent.TableName] = newTable(keyspace, name, event.FieldEvent.Fields)
+	case binlogdatapb.VEventType_ROW:
+		return c.addRowEvent(event)
+	case binlogdatapb.VEventType_VGTID:
+		if c.tx != nil {
+			// Only move on once the transaction is written.
+			c.tx.vgtid = event.Vgtid
+			return nil
+		}
+		c.pos = event.Vgtid
+		c.checkpointed = false
+	case binlogdatapb.VEventType_COMMIT:
+		if c.tx == nil {
+			return nil
+		}
+		if err := c.commit(ctx); err != nil {
+			return err
+		}
+		return c.checkpoint(ctx, false)
+	case binlogdatapb.VEventType_COPY_COMPLETED:
+		if event.Keyspace == "" && event.Shard == "" {
+			log.Infof("Connector %s completed the copy of all tables", c.cfg.Name)
+			return c.checkpoint(ctx, true)
+		}
+	case binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_OTHER, binlogdatapb.VEventType_HEARTBEAT:
+		if c.tx == nil {
+			return c.checkpoint(ctx, false)
+		}
+	}
+	return nil
+}
+
+func (c *Connector) addRowEvent(event *binlogdatapb.VEvent) error {
+	table, ok := c.tables[event.RowEvent.TableName]
+	if !ok {
+		return fmt.Errorf("row event for table %s without field event", event.RowEvent.TableName)
+	}
+	if c.tx == nil {
+		c.tx = &transaction{}
+	}
+	// Rows read while copying a table carry no binlog timestamp.
+	snapshot := event.Timestamp == 0
+	for _, change := range event.RowEvent.RowChanges {
+		ev := &ChangeEvent{
+			Topic: c.topic(table),
+			Table: table,
+			Source: Source{
+				Version:   c.cfg.Version,
+				Connector: ConnectorName,
+				Name:      c.cfg.Name,
+				TsMs:      event.Timestamp * 1000,
+				Snapshot:  "false",
+				Keyspace:  table.Keyspace,
+				Shard:     event.RowEvent.Shard,
+				Table:     table.Name,
+			},
+		}
+		if change.Before != nil {
+			ev.Before = sqltypes.MakeRowTrusted(table.Fields, change.Before)
+		}
+		if change.After != nil {
+			ev.After = sqltypes.MakeRowTrusted(table.Fields, change.After)
+		}
+		switch {
+		case snapshot:
+			ev.Op = OpRead
+			ev.Source.Snapshot = "true"
+		case ev.Before == nil:
+			ev.Op = OpCreate
+		case ev.After == nil:
+			ev.Op = OpDelete
+		default:
+			ev.Op = OpUpdate
+		}
+		c.tx.changes = append(c.tx.changes, ev)
+	}
+	return nil
+}
+
+// commit writes the changes of the current transaction to the sink.
+func (c *Connector) commit(ctx context.Context) error {
+	tx := c.tx
+	c.tx = nil
+
+	vgtid := encodeVgtid(tx.vgtid)
+	tsMs := c.now().UnixMilli()
+	var records []*Record
+	for _, ev := range tx.changes {
+		ev.Source.Vgtid = vgtid
+		ev.TsMs = tsMs
+		evRecords, err := c.encoder.Encode(ev)
+		if err != nil {
+			return fmt.Errorf("cannot encode change of %s.%s: %w", ev.Table.Keyspace, ev.Table.Name, err)
+		}
+		records = append(records, evRecords...)
+		if ev.Op == OpDelete && c.cfg.TombstonesOnDelete && len(evRecords) > 0 {
+			if key := evRecords[len(evRecords)-1].Key; key != nil {
+				records = append(records, &Record{Topic: ev.Topic, Key: key})
+			}
+		}
+	}
+	if len(records) > 0 {
+		if err := c.sink.Write(ctx, records); err != nil {
+			return fmt.Errorf("cannot write to sink: %w", err)
+		}
+	}
+	if tx.vgtid != nil {
+		c.pos = tx.vgtid
+		c.checkpointed = false
+	}
+	return nil
+}
+
+// checkpoint saves the position if it changed and, unless force is set,
+// the checkpoint interval has elapsed.
+func (c *Connector) checkpoint(ctx context.Context, force bool) error {
+	if c.checkpoints == nil || c.checkpointed || c.pos == nil {
+		return nil
+	}
+	now := c.now()
+	if !force && now.Sub(c.lastCheckpoint) < c.cfg.CheckpointInterval {
+		return nil
+	}
+	if err := c.checkpoints.Save(ctx, c.pos); err != nil {
+		return err
+	}
+	c.lastCheckpoint = now
+	c.checkpointed = true
+	return nil
+}
+
+// Close saves the position and closes the sink.
+func (c *Connector) Close(ctx context.Context) error {
+	err := c.checkpoint(ctx, true)
+	if closeErr := c.sink.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *Connector) topic(table *Table) string {
+	return strings.Join([]string{c.cfg.TopicPrefix, table.Keyspace, table.Name}, ".")
+}
+
+// splitTableName splits the <keyspace>.<table> names vtgate gives tables.
+func splitTableName(keyspace, tableName string) (string, string) {
+	if prefix, name, ok := strings.Cut(tableName, "."); ok && (keyspace == "" || prefix == keyspace) {
+		return prefix, name
+	}
+	return keyspace, tableName
+}
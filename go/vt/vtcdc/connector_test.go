@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeStreamer replays batches of events and records the positions it was
+// started at.
+type fakeStreamer struct {
+	batches [][]*binlogdatapb.VEvent
+	starts  []*binlogdatapb.VGtid
+}
+
+func (fs *fakeStreamer) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error) {
+	fs.starts = append(fs.starts, vgtid.CloneVT())
+	return &fakeReader{batches: fs.batches}, nil
+}
+
+type fakeReader struct {
+	batches [][]*binlogdatapb.VEvent
+}
+
+func (fr *fakeReader) Recv() ([]*binlogdatapb.VEvent, error) {
+	if len(fr.batches) == 0 {
+		return nil, io.EOF
+	}
+	batch := fr.batches[0]
+	fr.batches = fr.batches[1:]
+	return batch, nil
+}
+
+func testVgtid(gtid string, tablePKs ...*binlogdatapb.TableLastPK) *binlogdatapb.VGtid {
+	return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+		Keyspace: "ks",
+		Shard:    "-80",
+		Gtid:     gtid,
+		TablePKs: tablePKs,
+	}}}
+}
+
+func rowEvent(timestamp int64, before, after *querypb.Row) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{
+		Type:      binlogdatapb.VEventType_ROW,
+		Timestamp: timestamp,
+		RowEvent: &binlogdatapb.RowEvent{
+			TableName:  "ks.customer",
+			Keyspace:   "ks",
+			Shard:      "-80",
+			RowChanges: []*binlogdatapb.RowChange{{Before: before, After: after}},
+		},
+	}
+}
+
+func TestConnectorFileSink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	fields := sqltypes.MakeTestFields("id|name|balance|photo", "int64|varchar|decimal|blob")
+	fields[0].Flags = uint32(querypb.MySqlFlag_PRI_KEY_FLAG | querypb.MySqlFlag_NOT_NULL_FLAG)
+	row := func(values string) *querypb.Row {
+		return sqltypes.RowToProto3(sqltypes.MakeTestResult(fields, values).Rows[0])
+	}
+	copyPos := testVgtid("", &binlogdatapb.TableLastPK{TableName: "customer"})
+	insertPos := testVgtid("MySQL56/00000000-0000-0000-0000-000000000001:1-10")
+	updatePos := testVgtid("MySQL56/00000000-0000-0000-0000-000000000001:1-11")
+
+	streamer := &fakeStreamer{batches: [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_BEGIN},
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.customer", Keyspace: "ks", Shard: "-80", Fields: fields}},
+		rowEvent(0, nil, row("1|alice|1.50|null")),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: copyPos},
+		{Type: binlogdatapb.VEventType_COMMIT},
+		{Type: binlogdatapb.VEventType_COPY_COMPLETED, Keyspace: "ks", Shard: "-80"},
+		{Type: binlogdatapb.VEventType_COPY_COMPLETED},
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN, Timestamp: 1700000000},
+		rowEvent(1700000000, nil, row("2|bob|0.00|AQI=")),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: insertPos},
+		{Type: binlogdatapb.VEventType_COMMIT, Timestamp: 1700000000},
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN, Timestamp: 1700000001},
+		rowEvent(1700000001, row("1|alice|1.50|null"), row("1|alice \"a\"|2.50|null")),
+		rowEvent(1700000001, row("2|bob|0.00|AQI="), nil),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: updatePos},
+		{Type: binlogdatapb.VEventType_COMMIT, Timestamp: 1700000001},
+	}}}
+
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir)
+	require.NoError(t, err)
+	cfg := Config{
+		Name:               "test",
+		TopicPrefix:        "cdc",
+		Version:            "23.0.0",
+		TabletType:         topodatapb.TabletType_REPLICA,
+		StartPosition:      testVgtid(""),
+		TombstonesOnDelete: true,
+		CheckpointInterval: time.Hour,
+	}
+	connector := NewConnector(cfg, streamer, &JSONEncoder{}, sink, NewCheckpointStore(ts, "test"))
+	connector.now = func() time.Time { return time.UnixMilli(1700000005000) }
+	require.NoError(t, connector.Run(ctx))
+	require.NoError(t, connector.Close(ctx))
+	assert.True(t, proto.Equal(testVgtid(""), streamer.starts[0]))
+
+	data, err := os.ReadFile(sink.Path("cdc.ks.customer"))
+	require.NoError(t, err)
+	source := func(tsMs, snapshot, pos string) string {
+		return `{"version":"23.0.0","connector":"vitess","name":"test","ts_ms":` + tsMs + `,"snapshot":"` + snapshot +
+			`","keyspace":"ks","shard":"-80","table":"customer","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"` + pos + `\"}]"}`
+	}
+	want := []string{
+		`{"key":{"id":1},"value":{"before":null,"after":{"id":1,"name":"alice","balance":"1.50","photo":null},"source":` +
+			source("0", "true", "") + `,"op":"r","ts_ms":1700000005000}}`,
+		`{"key":{"id":2},"value":{"before":null,"after":{"id":2,"name":"bob","balance":"0.00","photo":"QVFJPQ=="},"source":` +
+			source("1700000000000", "false", "MySQL56/00000000-0000-0000-0000-000000000001:1-10") + `,"op":"c","ts_ms":1700000005000}}`,
+		`{"key":{"id":1},"value":{"before":{"id":1,"name":"alice","balance":"1.50","photo":null},"after":{"id":1,"name":"alice \"a\"","balance":"2.50","photo":null},"source":` +
+			source("1700000001000", "false", "MySQL56/00000000-0000-0000-0000-000000000001:1-11") + `,"op":"u","ts_ms":1700000005000}}`,
+		`{"key":{"id":2},"value":{"before":{"id":2,"name":"bob","balance":"0.00","photo":"QVFJPQ=="},"after":null,"source":` +
+			source("1700000001000", "false", "MySQL56/00000000-0000-0000-0000-000000000001:1-11") + `,"op":"d","ts_ms":1700000005000}}`,
+		`{"key":{"id":2},"value":null}`,
+	}
+	assert.Equal(t, want, strings.Split(strings.TrimSpace(string(data)), "\n"))
+
+	// A new connector resumes at the checkpoint rather than the start position.
+	checkpoint, err := NewCheckpointStore(ts, "test").Load(ctx)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(updatePos, checkpoint), "checkpoint: %v", checkpoint)
+
+	streamer = &fakeStreamer{}
+	connector = NewConnector(cfg, streamer, &JSONEncoder{}, sink, NewCheckpointStore(ts, "test"))
+	require.NoError(t, connector.Run(ctx))
+	assert.True(t, proto.Equal(updatePos, streamer.starts[0]))
+}
+
+func TestConnectorResumesAfterError(t *testing.T) {
+	ctx := context.Background()
+	fields := sqltypes.MakeTestFields("id", "int64")
+	pos := testVgtid("MySQL56/00000000-0000-0000-0000-000000000001:1-10")
+	streamer := &fakeStreamer{batches: [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_BEGIN},
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.customer", Keyspace: "ks", Fields: fields}},
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: pos},
+		{Type: binlogdatapb.VEventType_COMMIT},
+		{Type: binlogdatapb.VEventType_BEGIN},
+		// The table of this row is unknown, which fails the stream in the
+		// middle of a transaction.
+		{Type: binlogdatapb.VEventType_ROW, Timestamp: 1, RowEvent: &binlogdatapb.RowEvent{TableName: "ks.unknown"}},
+	}}}
+	connector := NewConnector(Config{Name: "test", StartPosition: testVgtid("")}, streamer, &JSONEncoder{}, NewWriterSink(io.Discard), nil)
+	require.ErrorContains(t, connector.Run(ctx), "row event for table ks.unknown without field event")
+	assert.True(t, proto.Equal(pos, connector.Position()))
+
+	streamer.batches = nil
+	require.NoError(t, connector.Run(ctx))
+	assert.True(t, proto.Equal(pos, streamer.starts[1]))
+}
+
+func TestCheckpointStoreConcurrentConnectors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	first := NewCheckpointStore(ts, "test")
+	second := NewCheckpointStore(ts, "test")
+	_, err := first.Load(ctx)
+	require.NoError(t, err)
+	_, err = second.Load(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, first.Save(ctx, testVgtid("a")))
+	require.ErrorContains(t, second.Save(ctx, testVgtid("b")), "is another connector running with the same name?")
+	require.NoError(t, first.Save(ctx, testVgtid("c")))
+
+	require.NoError(t, second.Delete(ctx))
+	vgtid, err := second.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, vgtid)
+}
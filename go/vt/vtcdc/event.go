@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/json"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// Op is the Debezium operation of a change event.
+type Op string
+
+const (
+	// OpCreate is an insert.
+	OpCreate Op = "c"
+	// OpUpdate is an update.
+	OpUpdate Op = "u"
+	// OpDelete is a delete.
+	OpDelete Op = "d"
+	// OpRead is a row read while copying the initial snapshot of a table.
+	OpRead Op = "r"
+)
+
+// Table describes the columns of a streamed table, as sent by the last
+// field event for it.
+type Table struct {
+	Keyspace string
+	Name     string
+	Fields   []*querypb.Field
+	// PKColumns holds the indexes of the primary key columns in Fields.
+	PKColumns []int
+}
+
+func newTable(keyspace, name string, fields []*querypb.Field) *Table {
+	t := &Table{
+		Keyspace: keyspace,
+		Name:     name,
+		Fields:   fields,
+	}
+	for i, field := range fields {
+		if field.Flags&uint32(querypb.MySqlFlag_PRI_KEY_FLAG) != 0 {
+			t.PKColumns = append(t.PKColumns, i)
+		}
+	}
+	return t
+}
+
+// Source is the source block of a Debezium change event. Its fields follow
+// the ones of the Debezium Vitess connector.
+type Source struct {
+	Version   string
+	Connector string
+	Name      string
+	TsMs      int64
+	// Snapshot is "true" for rows read while copying a table and "false"
+	// otherwise.
+	Snapshot string
+	Keyspace string
+	Shard    string
+	Table    string
+	// Vgtid is the JSON encoded position of the transaction.
+	Vgtid string
+}
+
+// ChangeEvent is a change of a single row.
+type ChangeEvent struct {
+	Topic  string
+	Table  *Table
+	Op     Op
+	Before []sqltypes.Value
+	After  []sqltypes.Value
+	Source Source
+	// TsMs is the time the connector processed the event.
+	TsMs int64
+}
+
+// Key returns the values of the primary key columns of the row, or nil if
+// the table has no primary key.
+func (ev *ChangeEvent) Key() []sqltypes.Value {
+	if len(ev.Table.PKColumns) == 0 {
+		return nil
+	}
+	row := ev.After
+	if row == nil {
+		row = ev.Before
+	}
+	key := make([]sqltypes.Value, 0, len(ev.Table.PKColumns))
+	for _, idx := range ev.Table.PKColumns {
+		key = append(key, row[idx])
+	}
+	return key
+}
+
+type shardPosition struct {
+	Keyspace string `json:"keyspace"`
+	Shard    string `json:"shard"`
+	Gtid     string `json:"gtid"`
+}
+
+// encodeVgtid returns the JSON representation Debezium uses for a VGTID.
+// The copy state of the tables is left out.
+func encodeVgtid(vgtid *binlogdatapb.VGtid) string {
+	if vgtid == nil {
+		return ""
+	}
+	positions := make([]shardPosition, 0, len(vgtid.ShardGtids))
+	for _, sgtid := range vgtid.ShardGtids {
+		positions = append(positions, shardPosition{
+			Keyspace: sgtid.Keyspace,
+			Shard:    sgtid.Shard,
+			Gtid:     sgtid.Gtid,
+		})
+	}
+	b, _ := json.Marshal(positions)
+	return string(b)
+}
+
+// valueKind is how a column is represented in change events.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindLong
+	kindFloat
+	kindDouble
+	kindBytes
+)
+
+// kindOf maps a MySQL type to its change event representation. Decimals
+// and unsigned bigints are strings to stay exact, temporal types are strings
+// in the MySQL format.
+func kindOf(typ querypb.Type) valueKind {
+	switch {
+	case typ == sqltypes.Uint64:
+		return kindString
+	case sqltypes.IsIntegral(typ):
+		return kindLong
+	case typ == sqltypes.Float32:
+		return kindFloat
+	case typ == sqltypes.Float64:
+		return kindDouble
+	case typ == sqltypes.Decimal:
+		return kindString
+	case sqltypes.IsBinary(typ), typ == sqltypes.Bit, typ == sqltypes.Geometry:
+		return kindBytes
+	default:
+		return kindString
+	}
+}
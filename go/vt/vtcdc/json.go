@@ -0,0 +1,149 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// Encoder turns change events into records.
+type Encoder interface {
+	// Encode returns the records for ev. The record of the change event
+	// itself comes last: encoders may precede it with other records, such
+	// as the schema of a table seen for the first time.
+	Encode(ev *ChangeEvent) ([]*Record, error)
+}
+
+// NewEncoder returns the encoder for format, which is "json" or "avro".
+func NewEncoder(format, topicPrefix string) (Encoder, error) {
+	switch format {
+	case "json":
+		return &JSONEncoder{}, nil
+	case "avro":
+		return NewAvroEncoder(topicPrefix), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected json or avro", format)
+	}
+}
+
+// JSONEncoder encodes change events like the Debezium JSON converter with
+// schemas disabled: the key is an object of the primary key columns, and
+// the value is the change event envelope.
+type JSONEncoder struct{}
+
+// Encode is part of the Encoder interface.
+func (*JSONEncoder) Encode(ev *ChangeEvent) ([]*Record, error) {
+	record := &Record{Topic: ev.Topic}
+	if key := ev.Key(); key != nil {
+		var buf bytes.Buffer
+		if err := writeJSONRow(&buf, ev.Table, ev.Table.PKColumns, key); err != nil {
+			return nil, err
+		}
+		record.Key = buf.Bytes()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"before":`)
+	if err := writeJSONRow(&buf, ev.Table, nil, ev.Before); err != nil {
+		return nil, err
+	}
+	buf.WriteString(`,"after":`)
+	if err := writeJSONRow(&buf, ev.Table, nil, ev.After); err != nil {
+		return nil, err
+	}
+	buf.WriteString(`,"source":`)
+	source, err := json.Marshal(jsonSource(ev.Source))
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(source)
+	fmt.Fprintf(&buf, `,"op":%q,"ts_ms":%d}`, ev.Op, ev.TsMs)
+	record.Value = buf.Bytes()
+	return []*Record{record}, nil
+}
+
+// jsonSource gives Source the field names of Debezium.
+type jsonSource struct {
+	Version   string `json:"version"`
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	TsMs      int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	Keyspace  string `json:"keyspace"`
+	Shard     string `json:"shard"`
+	Table     string `json:"table"`
+	Vgtid     string `json:"vgtid"`
+}
+
+// writeJSONRow writes row as an object. If columns is set, row holds the
+// values of these columns of the table only.
+func writeJSONRow(buf *bytes.Buffer, table *Table, columns []int, row []sqltypes.Value) error {
+	if row == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	buf.WriteByte('{')
+	for i, value := range row {
+		idx := i
+		if columns != nil {
+			idx = columns[i]
+		}
+		if idx >= len(table.Fields) {
+			return fmt.Errorf("row of %s.%s has more values than fields", table.Keyspace, table.Name)
+		}
+		field := table.Fields[idx]
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		if err := writeJSONValue(buf, kindOf(field.Type), value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeJSONValue(buf *bytes.Buffer, kind valueKind, value sqltypes.Value) error {
+	if value.IsNull() {
+		buf.WriteString("null")
+		return nil
+	}
+	switch kind {
+	case kindLong, kindFloat, kindDouble:
+		buf.Write(value.Raw())
+	case kindBytes:
+		fmt.Fprintf(buf, "%q", base64.StdEncoding.EncodeToString(value.Raw()))
+	default:
+		s, err := json.Marshal(value.ToString())
+		if err != nil {
+			return err
+		}
+		buf.Write(s)
+	}
+	return nil
+}
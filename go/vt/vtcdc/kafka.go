@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// The SASL mechanisms supported by the Kafka sink.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// KafkaConfig configures a KafkaSink.
+type KafkaConfig struct {
+	// Brokers are the bootstrap brokers, as host:port.
+	Brokers  []string
+	ClientID string
+	// Timeout bounds the delivery of each record, retries included.
+	Timeout time.Duration
+	// Retries is how many times a record is retried on errors such as a
+	// leader change.
+	Retries int
+	// TLS, if set, is used for the connections to the brokers.
+	TLS *tls.Config
+	// SASLMechanism, if set, is the SASL mechanism used to authenticate
+	// to the brokers: PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512.
+	SASLMechanism string
+	SASLUser      string
+	SASLPassword  string
+}
+
+// KafkaSink produces the records to Kafka. The records are written with an
+// idempotent producer and acknowledged by all the in-sync replicas, so that
+// retries neither duplicate nor reorder them. The partition of a record is
+// chosen like the default Java partitioner does, with the murmur2 hash of its
+// key, so that all changes of a row go to the same partition in order. Topics
+// are expected to exist or to be auto-created by the brokers.
+type KafkaSink struct {
+	client *kgo.Client
+}
+
+// NewKafkaSink returns a sink producing to the brokers of cfg.
+func NewKafkaSink(cfg KafkaConfig) (*KafkaSink, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("no kafka brokers")
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "vtcdc"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ClientID(cfg.ClientID),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+		kgo.RecordDeliveryTimeout(cfg.Timeout),
+		kgo.RecordRetries(cfg.Retries),
+		kgo.AllowAutoTopicCreation(),
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, kgo.DialTLSConfig(cfg.TLS))
+	}
+	if cfg.SASLMechanism != "" {
+		mechanism, err := saslMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSink{client: client}, nil
+}
+
+func saslMechanism(cfg KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.SASLMechanism) {
+	case SASLPlain:
+		return plain.Auth{User: cfg.SASLUser, Pass: cfg.SASLPassword}.AsMechanism(), nil
+	case SASLScramSHA256:
+		return scram.Auth{User: cfg.SASLUser, Pass: cfg.SASLPassword}.AsSha256Mechanism(), nil
+	case SASLScramSHA512:
+		return scram.Auth{User: cfg.SASLUser, Pass: cfg.SASLPassword}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q, expected %s, %s or %s", cfg.SASLMechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+}
+
+// Write is part of the Sink interface.
+func (ks *KafkaSink) Write(ctx context.Context, records []*Record) error {
+	krecords := make([]*kgo.Record, len(records))
+	for i, record := range records {
+		krecords[i] = &kgo.Record{Topic: record.Topic, Key: record.Key, Value: record.Value}
+	}
+	for _, result := range ks.client.ProduceSync(ctx, krecords...) {
+		if result.Err != nil {
+			return fmt.Errorf("cannot produce to %s: %w", result.Record.Topic, result.Err)
+		}
+	}
+	return nil
+}
+
+// Close is part of the Sink interface.
+func (ks *KafkaSink) Close() error {
+	ks.client.Close()
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/twmb/franz-go/pkg/sasl"
+)
+
+var kafkaTestRecords = []*Record{
+	{Topic: "cdc.ks.t", Key: []byte(`{"id":1}`), Value: []byte("insert 1")},
+	{Topic: "cdc.ks.t", Key: []byte(`{"id":2}`), Value: []byte("insert 2")},
+	{Topic: "cdc.ks.t", Key: []byte(`{"id":1}`), Value: []byte("update 1")},
+	{Topic: "cdc.ks.t", Key: []byte(`{"id":1}`)},
+	{Topic: "cdc.ks.u", Value: []byte("no key")},
+}
+
+// consumeRecords reads the records produced to topics, and returns them per
+// topic and partition.
+func consumeRecords(t *testing.T, opts []kgo.Opt, count int, topics ...string) map[string]map[int32][]*Record {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	opts = append(opts, kgo.ConsumeTopics(topics...), kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()), kgo.FetchMaxWait(100*time.Millisecond))
+	client, err := kgo.NewClient(opts...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	records := make(map[string]map[int32][]*Record)
+	for n := 0; n < count; {
+		fetches := client.PollFetches(ctx)
+		require.NoError(t, ctx.Err())
+		fetches.EachRecord(func(r *kgo.Record) {
+			if records[r.Topic] == nil {
+				records[r.Topic] = make(map[int32][]*Record)
+			}
+			records[r.Topic][r.Partition] = append(records[r.Topic][r.Partition], &Record{Topic: r.Topic, Key: r.Key, Value: r.Value})
+			n++
+		})
+	}
+	return records
+}
+
+// checkKafkaTestRecords checks that kafkaTestRecords were produced once, and
+// that the changes of each row were produced to the same partition in order.
+func checkKafkaTestRecords(t *testing.T, records map[string]map[int32][]*Record, prefix string) {
+	var row1, row2, u []*Record
+	keyPartitions := make(map[string]map[int32]bool)
+	for partition, partitionRecords := range records[prefix+"cdc.ks.t"] {
+		for _, record := range partitionRecords {
+			if keyPartitions[string(record.Key)] == nil {
+				keyPartitions[string(record.Key)] = make(map[int32]bool)
+			}
+			keyPartitions[string(record.Key)][partition] = true
+			switch string(record.Key) {
+			case `{"id":1}`:
+				row1 = append(row1, record)
+			case `{"id":2}`:
+				row2 = append(row2, record)
+			}
+		}
+	}
+	for key, partitions := range keyPartitions {
+		assert.Len(t, partitions, 1, key)
+	}
+	for _, partitionRecords := range records[prefix+"cdc.ks.u"] {
+		u = append(u, partitionRecords...)
+	}
+	want := func(records ...*Record) []*Record {
+		var out []*Record
+		for _, record := range records {
+			out = append(out, &Record{Topic: prefix + record.Topic, Key: record.Key, Value: record.Value})
+		}
+		return out
+	}
+	assert.Equal(t, want(kafkaTestRecords[0], kafkaTestRecords[2], kafkaTestRecords[3]), row1)
+	assert.Equal(t, want(kafkaTestRecords[1]), row2)
+	assert.Equal(t, want(kafkaTestRecords[4]), u)
+	// Deletes are followed by tombstones, which must have a null value.
+	assert.Nil(t, row1[2].Value)
+}
+
+func TestKafkaSink(t *testing.T) {
+	ctx := context.Background()
+	cluster, err := kfake.NewCluster(
+		kfake.NumBrokers(3),
+		kfake.SeedTopics(3, "cdc.ks.t"),
+		kfake.AllowAutoTopicCreation(),
+		kfake.EnableSASL(),
+		kfake.Superuser(SASLScramSHA256, "vtcdc", "secret"),
+	)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	sink, err := NewKafkaSink(KafkaConfig{
+		Brokers:       cluster.ListenAddrs(),
+		Timeout:       10 * time.Second,
+		Retries:       5,
+		SASLMechanism: SASLScramSHA256,
+		SASLUser:      "vtcdc",
+		SASLPassword:  "secret",
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	// The first produce request fails as when too few replicas are in sync,
+	// and is retried.
+	var produceRequests int
+	cluster.ControlKey(kmsg.Produce.Int16(), func(req kmsg.Request) (kmsg.Response, error, bool) {
+		produceRequests++
+		return produceResponse(req.(*kmsg.ProduceRequest), kerr.NotEnoughReplicas.Code), nil, true
+	})
+	require.NoError(t, sink.Write(ctx, kafkaTestRecords))
+	assert.Equal(t, 1, produceRequests)
+
+	consumer := []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...), kgo.SASL(saslMechanismOrFail(t, SASLScramSHA256, "vtcdc", "secret"))}
+	checkKafkaTestRecords(t, consumeRecords(t, consumer, len(kafkaTestRecords), "cdc.ks.t", "cdc.ks.u"), "")
+
+	// Non retriable errors fail the write.
+	cluster.ControlKey(kmsg.Produce.Int16(), func(req kmsg.Request) (kmsg.Response, error, bool) {
+		return produceResponse(req.(*kmsg.ProduceRequest), kerr.InvalidRecord.Code), nil, true
+	})
+	require.ErrorContains(t, sink.Write(ctx, kafkaTestRecords[4:]), "cannot produce to cdc.ks.u: INVALID_RECORD")
+}
+
+func TestKafkaSinkAuthentication(t *testing.T) {
+	cluster, err := kfake.NewCluster(
+		kfake.NumBrokers(1),
+		kfake.AllowAutoTopicCreation(),
+		kfake.EnableSASL(),
+		kfake.Superuser(SASLPlain, "vtcdc", "secret"),
+	)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	_, err = NewKafkaSink(KafkaConfig{Brokers: cluster.ListenAddrs(), SASLMechanism: "GSSAPI"})
+	require.ErrorContains(t, err, `unknown SASL mechanism "GSSAPI"`)
+
+	sink, err := NewKafkaSink(KafkaConfig{
+		Brokers:       cluster.ListenAddrs(),
+		Timeout:       time.Second,
+		SASLMechanism: SASLPlain,
+		SASLUser:      "vtcdc",
+		SASLPassword:  "wrong",
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+	require.Error(t, sink.Write(context.Background(), kafkaTestRecords[4:]))
+}
+
+func saslMechanismOrFail(t *testing.T, mechanism, user, password string) sasl.Mechanism {
+	m, err := saslMechanism(KafkaConfig{SASLMechanism: mechanism, SASLUser: user, SASLPassword: password})
+	require.NoError(t, err)
+	return m
+}
+
+func produceResponse(req *kmsg.ProduceRequest, code int16) *kmsg.ProduceResponse {
+	resp := req.ResponseKind().(*kmsg.ProduceResponse)
+	for _, rt := range req.Topics {
+		st := kmsg.NewProduceResponseTopic()
+		st.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			sp := kmsg.NewProduceResponseTopicPartition()
+			sp.Partition = rp.Partition
+			sp.ErrorCode = code
+			st.Partitions = append(st.Partitions, sp)
+		}
+		resp.Topics = append(resp.Topics, st)
+	}
+	return resp
+}
+
+// TestKafkaSinkBroker produces to a real Kafka cluster. It requires the
+// $TEST_VTCDC_KAFKA_BROKERS environment variable to be set to a
+// comma-separated list of bootstrap brokers, and is skipped otherwise. The
+// brokers must allow the auto-creation of topics. The way to run this test is:
+// ```sh
+// $ TEST_VTCDC_KAFKA_BROKERS=localhost:9092 go test -v -count=1 -run TestKafkaSinkBroker ./go/vt/vtcdc/
+// ```
+func TestKafkaSinkBroker(t *testing.T) {
+	envName := "TEST_VTCDC_KAFKA_BROKERS"
+	brokersVar := os.Getenv(envName)
+	if brokersVar == "" {
+		t.Skipf("no kafka brokers specified in $%s", envName)
+	}
+	brokers := strings.Split(brokersVar, ",")
+	sink, err := NewKafkaSink(KafkaConfig{Brokers: brokers, Retries: 5})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	// Each run writes to its own topics.
+	prefix := fmt.Sprintf("vtcdc-test-%d.", time.Now().UnixNano())
+	var records []*Record
+	for _, record := range kafkaTestRecords {
+		records = append(records, &Record{Topic: prefix + record.Topic, Key: record.Key, Value: record.Value})
+	}
+	require.NoError(t, sink.Write(context.Background(), records))
+	checkKafkaTestRecords(t, consumeRecords(t, []kgo.Opt{kgo.SeedBrokers(brokers...)}, len(records), prefix+"cdc.ks.t", prefix+"cdc.ks.u"), prefix)
+}
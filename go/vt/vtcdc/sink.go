@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Record is a message written to a sink. It mirrors a Kafka record: the
+// topic selects the destination, and the key decides the partition and
+// therefore the ordering of the records.
+type Record struct {
+	Topic string
+	Key   []byte
+	// Value is nil for tombstones.
+	Value []byte
+}
+
+// Sink is the destination of the records produced by the connector.
+type Sink interface {
+	// Write writes the records in order. When it returns without an error,
+	// the records must be durable, as the connector may then checkpoint
+	// the position they were read at.
+	Write(ctx context.Context, records []*Record) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// line is the NDJSON representation of a record. JSON keys and values are
+// embedded as is, binary ones such as Avro are base64 encoded.
+type line struct {
+	Topic string `json:"topic,omitempty"`
+	Key   any    `json:"key"`
+	Value any    `json:"value"`
+}
+
+func payload(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	if json.Valid(b) {
+		return json.RawMessage(b)
+	}
+	return b
+}
+
+func marshalLine(record *Record, withTopic bool) ([]byte, error) {
+	l := line{Key: payload(record.Key), Value: payload(record.Value)}
+	if withTopic {
+		l.Topic = record.Topic
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// WriterSink writes the records of all topics to a single writer, one NDJSON
+// line per record. It is typically used with stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write is part of the Sink interface.
+func (ws *WriterSink) Write(ctx context.Context, records []*Record) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, record := range records {
+		b, err := marshalLine(record, true)
+		if err != nil {
+			return err
+		}
+		if _, err := ws.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is part of the Sink interface.
+func (ws *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink appends the records of each topic to its own NDJSON file,
+// <dir>/<topic>.ndjson, and syncs the files before returning from Write.
+type FileSink struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+// NewFileSink returns a sink writing to files in dir, which is created
+// if needed.
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSink{
+		dir:   dir,
+		files: make(map[string]*os.File),
+	}, nil
+}
+
+// Path returns the path of the file holding the records of topic.
+func (fs *FileSink) Path(topic string) string {
+	return filepath.Join(fs.dir, topic+".ndjson")
+}
+
+func (fs *FileSink) file(topic string) (*os.File, error) {
+	if f, ok := fs.files[topic]; ok {
+		return f, nil
+	}
+	f, err := os.OpenFile(fs.Path(topic), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	fs.files[topic] = f
+	return f, nil
+}
+
+// Write is part of the Sink interface.
+func (fs *FileSink) Write(ctx context.Context, records []*Record) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	writers := make(map[string]*bufio.Writer)
+	var topics []string
+	for _, record := range records {
+		w, ok := writers[record.Topic]
+		if !ok {
+			f, err := fs.file(record.Topic)
+			if err != nil {
+				return err
+			}
+			w = bufio.NewWriter(f)
+			writers[record.Topic] = w
+			topics = append(topics, record.Topic)
+		}
+		b, err := marshalLine(record, false)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	for _, topic := range topics {
+		if err := writers[topic].Flush(); err != nil {
+			return fmt.Errorf("cannot write to %s: %w", fs.Path(topic), err)
+		}
+		if err := fs.files[topic].Sync(); err != nil {
+			return fmt.Errorf("cannot sync %s: %w", fs.Path(topic), err)
+		}
+	}
+	return nil
+}
+
+// Close is part of the Sink interface.
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var firstErr error
+	for topic, f := range fs.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(fs.files, topic)
+	}
+	return firstErr
+}
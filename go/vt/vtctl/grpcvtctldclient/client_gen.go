@@ -254,6 +254,15 @@ func (client *gRPCVtctldClient) DeleteTablets(ctx context.Context, in *vtctldata
 	return client.c.DeleteTablets(ctx, in, opts...)
 }
 
+// DisableVtorcRecoveries is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DisableVtorcRecoveries(ctx context.Context, in *vtctldatapb.DisableVtorcRecoveriesRequest, opts ...grpc.CallOption) (*vtctldatapb.DisableVtorcRecoveriesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DisableVtorcRecoveries(ctx, in, opts...)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	if client.c == nil {
@@ -263,6 +272,15 @@ func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *
 	return client.c.EmergencyReparentShard(ctx, in, opts...)
 }
 
+// EnableVtorcRecoveries is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) EnableVtorcRecoveries(ctx context.Context, in *vtctldatapb.EnableVtorcRecoveriesRequest, opts ...grpc.CallOption) (*vtctldatapb.EnableVtorcRecoveriesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.EnableVtorcRecoveries(ctx, in, opts...)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	if client.c == nil {
@@ -299,6 +317,15 @@ func (client *gRPCVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in *
 	return client.c.ExecuteMultiFetchAsDBA(ctx, in, opts...)
 }
 
+// ExportTopo is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExportTopo(ctx context.Context, in *vtctldatapb.ExportTopoRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopoResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExportTopo(ctx, in, opts...)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	if client.c == nil {
@@ -740,6 +767,15 @@ func (client *gRPCVtctldClient) RebuildVSchemaGraph(ctx context.Context, in *vtc
 	return client.c.RebuildVSchemaGraph(ctx, in, opts...)
 }
 
+// RecoverTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RecoverTables(ctx context.Context, in *vtctldatapb.RecoverTablesRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RecoverTablesClient, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RecoverTables(ctx, in, opts...)
+}
+
 // RefreshState is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RefreshState(ctx context.Context, in *vtctldatapb.RefreshStateRequest, opts ...grpc.CallOption) (*vtctldatapb.RefreshStateResponse, error) {
 	if client.c == nil {
@@ -821,6 +857,24 @@ func (client *gRPCVtctldClient) ReparentTablet(ctx context.Context, in *vtctldat
 	return client.c.ReparentTablet(ctx, in, opts...)
 }
 
+// ReplicateCreate is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReplicateCreate(ctx context.Context, in *vtctldatapb.ReplicateCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReplicateCreate(ctx, in, opts...)
+}
+
+// ReplicatePromote is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReplicatePromote(ctx context.Context, in *vtctldatapb.ReplicatePromoteRequest, opts ...grpc.CallOption) (*vtctldatapb.ReplicatePromoteResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReplicatePromote(ctx, in, opts...)
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ReshardCreate(ctx context.Context, in *vtctldatapb.ReshardCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	if client.c == nil {
@@ -839,6 +893,15 @@ func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctl
 	return client.c.RestoreFromBackup(ctx, in, opts...)
 }
 
+// RestoreTopo is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RestoreTopo(ctx context.Context, in *vtctldatapb.RestoreTopoRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopoResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RestoreTopo(ctx, in, opts...)
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RetrySchemaMigration(ctx context.Context, in *vtctldatapb.RetrySchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.RetrySchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -676,12 +676,12 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 		return nil, err
 	}
 
-	durabilityName, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	durabilityName, durabilityConfig, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -1274,6 +1274,67 @@ func (s *VtctldServer) DeleteTablets(ctx context.Context, req *vtctldatapb.Delet
 	return &vtctldatapb.DeleteTabletsResponse{}, nil
 }
 
+// DisableVtorcRecoveries is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DisableVtorcRecoveries(ctx context.Context, req *vtctldatapb.DisableVtorcRecoveriesRequest) (resp *vtctldatapb.DisableVtorcRecoveriesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DisableVtorcRecoveries")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("analysis_code", req.AnalysisCode)
+
+	duration, ok, err := protoutil.DurationFromProto(req.Duration)
+	if err != nil {
+		return nil, err
+	}
+	if ok && duration <= 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "duration must be positive, got %v", duration)
+		return nil, err
+	}
+
+	span.Annotate("duration", duration.String())
+
+	if req.Shard != "" {
+		if _, err = s.ts.GetShard(ctx, req.Keyspace, req.Shard); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "DisableVtorcRecoveries")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	disable := &topodatapb.VtorcRecoveryDisable{
+		Shard:        req.Shard,
+		AnalysisCode: req.AnalysisCode,
+		Reason:       req.Reason,
+	}
+	if ok {
+		disable.ExpireTime = protoutil.TimeToProto(now.Add(duration))
+	}
+	ki.SetVtorcRecoveryDisable(disable, now)
+
+	if err = s.ts.UpdateKeyspace(ctx, ki); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.DisableVtorcRecoveriesResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
 // EmergencyReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldatapb.EmergencyReparentShardRequest) (resp *vtctldatapb.EmergencyReparentShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.EmergencyReparentShard")
@@ -1344,6 +1405,44 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 	return resp, err
 }
 
+// EnableVtorcRecoveries is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) EnableVtorcRecoveries(ctx context.Context, req *vtctldatapb.EnableVtorcRecoveriesRequest) (resp *vtctldatapb.EnableVtorcRecoveriesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.EnableVtorcRecoveries")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("analysis_code", req.AnalysisCode)
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "EnableVtorcRecoveries")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ki.RemoveVtorcRecoveryDisable(req.Shard, req.AnalysisCode, time.Now()) {
+		err = vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no VTOrc recovery disable found for keyspace %s, shard %q and analysis code %q", req.Keyspace, req.Shard, req.AnalysisCode)
+		return nil, err
+	}
+
+	if err = s.ts.UpdateKeyspace(ctx, ki); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.EnableVtorcRecoveriesResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ExecuteFetchAsApp(ctx context.Context, req *vtctldatapb.ExecuteFetchAsAppRequest) (resp *vtctldatapb.ExecuteFetchAsAppResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExecuteFetchAsApp")
@@ -1470,6 +1569,25 @@ func (s *VtctldServer) ExecuteHook(ctx context.Context, req *vtctldatapb.Execute
 	}}, nil
 }
 
+// ExportTopo is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExportTopo(ctx context.Context, req *vtctldatapb.ExportTopoRequest) (resp *vtctldatapb.ExportTopoResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExportTopo")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+
+	snapshot, err := topotools.ExportTopo(ctx, s.ts, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ExportTopoResponse{
+		Snapshot: snapshot,
+	}, nil
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest) (resp *vtctldatapb.FindAllShardsInKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.FindAllShardsInKeyspace")
@@ -2822,12 +2940,12 @@ func (s *VtctldServer) InitShardPrimaryLocked(
 	}
 	ev.ShardInfo = *shardInfo
 
-	durabilityName, err := s.ts.GetKeyspaceDurability(ctx, req.Keyspace)
+	durabilityName, durabilityConfig, err := s.ts.GetKeyspaceDurability(ctx, req.Keyspace)
 	if err != nil {
 		return err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return err
 	}
@@ -3280,10 +3398,15 @@ func (s *VtctldServer) PlannedReparentShard(ctx context.Context, req *vtctldatap
 	if err != nil {
 		return nil, err
 	}
+	drainTimeout, _, err := protoutil.DurationFromProto(req.DrainTimeout)
+	if err != nil {
+		return nil, err
+	}
 
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("shard", req.Shard)
 	span.Annotate("wait_replicas_timeout_sec", waitReplicasTimeout.Seconds())
+	span.Annotate("drain_timeout_sec", drainTimeout.Seconds())
 
 	if req.AvoidPrimary != nil {
 		span.Annotate("avoid_primary_alias", topoproto.TabletAliasString(req.AvoidPrimary))
@@ -3316,6 +3439,7 @@ func (s *VtctldServer) PlannedReparentShard(ctx context.Context, req *vtctldatap
 			WaitReplicasTimeout:     waitReplicasTimeout,
 			TolerableReplLag:        tolerableReplLag,
 			AllowCrossCellPromotion: req.AllowCrossCellPromotion,
+			DrainTimeout:            drainTimeout,
 		},
 	)
 
@@ -3376,6 +3500,104 @@ func (s *VtctldServer) RebuildVSchemaGraph(ctx context.Context, req *vtctldatapb
 	return &vtctldatapb.RebuildVSchemaGraphResponse{}, nil
 }
 
+// RecoverTables is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RecoverTables(req *vtctldatapb.RecoverTablesRequest, stream vtctlservicepb.Vtctld_RecoverTablesServer) (err error) {
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RecoverTables")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("scratch_keyspace", req.ScratchKeyspace)
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("tables", strings.Join(req.Tables, ","))
+	span.Annotate("dry_run", req.DryRun)
+
+	restoreToTimestamp := protoutil.TimeFromProto(req.RestoreToTimestamp).UTC()
+	switch {
+	case req.Keyspace == "" || req.ScratchKeyspace == "":
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace and scratch keyspace are required")
+	case req.TabletAlias == nil:
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "tablet alias is required")
+	case len(req.Tables) == 0:
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "at least one table is required")
+	case (req.RestoreToPos == "") == restoreToTimestamp.IsZero():
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "exactly one of restore-to-pos and restore-to-timestamp is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	ki, err := s.ts.GetKeyspace(ctx, req.ScratchKeyspace)
+	if err != nil {
+		return err
+	}
+	if ki.KeyspaceType != topodatapb.KeyspaceType_SNAPSHOT || ki.BaseKeyspace != req.Keyspace {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is not a SNAPSHOT keyspace of %s", req.ScratchKeyspace, req.Keyspace)
+		return err
+	}
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return err
+	}
+	if ti.Keyspace != req.ScratchKeyspace {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "tablet %s belongs to keyspace %s, not to %s",
+			topoproto.TabletAliasString(req.TabletAlias), ti.Keyspace, req.ScratchKeyspace)
+		return err
+	}
+
+	span.Annotate("shard", ti.Shard)
+
+	r := &tabletmanagerdatapb.RestoreFromBackupRequest{
+		RestoreToPos:         req.RestoreToPos,
+		RestoreToTimestamp:   req.RestoreToTimestamp,
+		DryRun:               req.DryRun,
+		AllowedBackupEngines: req.AllowedBackupEngines,
+		Tables:               req.Tables,
+	}
+	logStream, err := s.tmc.RestoreFromBackup(ctx, ti.Tablet, r)
+	if err != nil {
+		return err
+	}
+
+	logger := logutil.NewConsoleLogger()
+	for {
+		var event *logutilpb.Event
+		event, err = logStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		logutil.LogEvent(logger, event)
+		resp := &vtctldatapb.RecoverTablesResponse{
+			TabletAlias:     req.TabletAlias,
+			ScratchKeyspace: req.ScratchKeyspace,
+			Shard:           ti.Shard,
+			Event:           event,
+		}
+		if err = stream.Send(resp); err != nil {
+			logger.Errorf("failed to send stream response %+v: %v", resp, err)
+		}
+	}
+	if req.DryRun {
+		return nil
+	}
+
+	// A point in time recovery leaves the tablet DRAINED, with replication
+	// disabled. Serve it read-only.
+	if ti, err = s.ts.GetTablet(ctx, req.TabletAlias); err != nil {
+		return err
+	}
+	if err = s.tmc.ChangeType(ctx, ti.Tablet, topodatapb.TabletType_RDONLY, false); err != nil {
+		return err
+	}
+	// The other shards of the scratch keyspace may not be recovered.
+	err = topotools.RebuildKeyspace(ctx, logutil.NewCallbackLogger(func(e *logutilpb.Event) {}), s.ts, req.ScratchKeyspace, nil, true)
+	return err
+}
+
 // RefreshState is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) RefreshState(ctx context.Context, req *vtctldatapb.RefreshStateRequest) (resp *vtctldatapb.RefreshStateResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RefreshState")
@@ -3690,12 +3912,12 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 		return nil, err
 	}
 
-	durabilityName, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	durabilityName, durabilityConfig, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -3711,6 +3933,40 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
+// ReplicateCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReplicateCreate(ctx context.Context, req *vtctldatapb.ReplicateCreateRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReplicateCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("mount_name", req.MountName)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+	span.Annotate("no_copy", req.NoCopy)
+
+	resp, err = s.ws.ReplicateCreate(ctx, req)
+	return resp, err
+}
+
+// ReplicatePromote is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReplicatePromote(ctx context.Context, req *vtctldatapb.ReplicatePromoteRequest) (resp *vtctldatapb.ReplicatePromoteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReplicatePromote")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("force", req.Force)
+
+	resp, err = s.ws.ReplicatePromote(ctx, req)
+	return resp, err
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReshardCreate")
@@ -3808,6 +4064,39 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	}
 }
 
+// RestoreTopo is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RestoreTopo(ctx context.Context, req *vtctldatapb.RestoreTopoRequest) (resp *vtctldatapb.RestoreTopoResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RestoreTopo")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspaces", strings.Join(req.Keyspaces, ","))
+	span.Annotate("vschema_only", req.VschemaOnly)
+	span.Annotate("include_shards", req.IncludeShards)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("dry_run", req.DryRun)
+
+	if req.Snapshot == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "topo snapshot is required")
+	}
+
+	files, err := topotools.RestoreTopo(ctx, s.ts, req.Snapshot, topotools.RestoreTopoOptions{
+		Keyspaces:     req.Keyspaces,
+		VSchemaOnly:   req.VschemaOnly,
+		IncludeShards: req.IncludeShards,
+		Cells:         req.Cells,
+		DryRun:        req.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.RestoreTopoResponse{
+		Files: files,
+	}, nil
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RetrySchemaMigration(ctx context.Context, req *vtctldatapb.RetrySchemaMigrationRequest) (resp *vtctldatapb.RetrySchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RetrySchemaMigration")
@@ -3885,13 +4174,33 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 		return nil, err
 	}
 
-	policyValid := policy.CheckDurabilityPolicyExists(req.DurabilityPolicy)
-	if !policyValid {
-		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "durability policy <%v> is not a valid policy. Please register it as a policy first", req.DurabilityPolicy)
+	durabilityPolicy := req.DurabilityPolicy
+	if req.DurabilityPolicyConfig != nil && durabilityPolicy == "" {
+		durabilityPolicy = policy.DurabilityQuorum
+	}
+
+	switch {
+	case durabilityPolicy == policy.DurabilityQuorum:
+		var durability policy.Durabler
+		durability, err = policy.GetDurabilityPolicyWithConfig(durabilityPolicy, req.DurabilityPolicyConfig)
+		if err != nil {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid config for durability policy <%v>: %v", durabilityPolicy, err)
+			return nil, err
+		}
+
+		if err = s.validateKeyspaceDurability(ctx, req.Keyspace, durability); err != nil {
+			return nil, err
+		}
+	case req.DurabilityPolicyConfig != nil:
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "durability policy <%v> does not take a config, only <%v> does", durabilityPolicy, policy.DurabilityQuorum)
+		return nil, err
+	case !policy.CheckDurabilityPolicyExists(durabilityPolicy):
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "durability policy <%v> is not a valid policy. Please register it as a policy first", durabilityPolicy)
 		return nil, err
 	}
 
-	ki.DurabilityPolicy = req.DurabilityPolicy
+	ki.DurabilityPolicy = durabilityPolicy
+	ki.DurabilityPolicyConfig = req.DurabilityPolicyConfig
 
 	err = s.ts.UpdateKeyspace(ctx, ki)
 	if err != nil {
@@ -3903,6 +4212,36 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 	}, nil
 }
 
+// validateKeyspaceDurability checks that the durability policy can be kept by
+// the tablets of every shard in the keyspace.
+func (s *VtctldServer) validateKeyspaceDurability(ctx context.Context, keyspace string, durability policy.Durabler) error {
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot validate the durability policy against the tablets of %v/%v", keyspace, shard)
+		}
+
+		tablets := make([]*topodatapb.Tablet, 0, len(tabletMap))
+		for _, ti := range tabletMap {
+			tablets = append(tablets, ti.Tablet)
+		}
+		sort.Slice(tablets, func(i, j int) bool {
+			return topoproto.TabletAliasString(tablets[i].Alias) < topoproto.TabletAliasString(tablets[j].Alias)
+		})
+
+		if err := policy.ValidateDurabilityPolicy(durability, tablets); err != nil {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "durability policy cannot be kept in shard %v/%v: %v", keyspace, shard, err)
+		}
+	}
+
+	return nil
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctldatapb.SetShardIsPrimaryServingRequest) (resp *vtctldatapb.SetShardIsPrimaryServingResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardIsPrimaryServing")
@@ -4401,12 +4740,12 @@ func (s *VtctldServer) StartReplication(ctx context.Context, req *vtctldatapb.St
 		return nil, err
 	}
 
-	durabilityName, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	durabilityName, durabilityConfig, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -4506,12 +4845,12 @@ func (s *VtctldServer) TabletExternallyReparented(ctx context.Context, req *vtct
 
 	event.DispatchUpdate(ev, "starting external reparent")
 
-	durabilityName, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	durabilityName, durabilityConfig, err := s.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -4691,6 +4691,115 @@ func TestDeleteTablets(t *testing.T) {
 	}
 }
 
+func TestDisableVtorcRecoveries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		keyspace     *topodatapb.Keyspace
+		req          *vtctldatapb.DisableVtorcRecoveriesRequest
+		expected     []*topodatapb.VtorcRecoveryDisable
+		expectExpire bool
+		expectedErr  string
+	}{
+		{
+			name:     "keyspace",
+			keyspace: &topodatapb.Keyspace{},
+			req: &vtctldatapb.DisableVtorcRecoveriesRequest{
+				Keyspace: "testkeyspace",
+				Reason:   "maintenance",
+			},
+			expected: []*topodatapb.VtorcRecoveryDisable{{
+				Reason: "maintenance",
+			}},
+		},
+		{
+			name: "shard and analysis code with duration",
+			keyspace: &topodatapb.Keyspace{
+				VtorcRecoveryDisables: []*topodatapb.VtorcRecoveryDisable{{
+					Shard:        "-",
+					AnalysisCode: "DeadPrimary",
+					Reason:       "old",
+				}, {
+					AnalysisCode: "ReplicationStopped",
+					ExpireTime:   protoutil.TimeToProto(time.Now().Add(-time.Hour)),
+				}},
+			},
+			req: &vtctldatapb.DisableVtorcRecoveriesRequest{
+				Keyspace:     "testkeyspace",
+				Shard:        "-",
+				AnalysisCode: "DeadPrimary",
+				Duration:     protoutil.DurationToProto(time.Hour),
+			},
+			expected: []*topodatapb.VtorcRecoveryDisable{{
+				Shard:        "-",
+				AnalysisCode: "DeadPrimary",
+			}},
+			expectExpire: true,
+		},
+		{
+			name:     "shard not found",
+			keyspace: &topodatapb.Keyspace{},
+			req: &vtctldatapb.DisableVtorcRecoveriesRequest{
+				Keyspace: "testkeyspace",
+				Shard:    "-80",
+			},
+			expectedErr: "node doesn't exist: keyspaces/testkeyspace/shards/-80/Shard",
+		},
+		{
+			name:     "negative duration",
+			keyspace: &topodatapb.Keyspace{},
+			req: &vtctldatapb.DisableVtorcRecoveriesRequest{
+				Keyspace: "testkeyspace",
+				Duration: protoutil.DurationToProto(-time.Hour),
+			},
+			expectedErr: "duration must be positive, got -1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name:     "testkeyspace",
+				Keyspace: tt.keyspace,
+			})
+			testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+				Keyspace: "testkeyspace",
+				Name:     "-",
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.DisableVtorcRecoveries(ctx, tt.req)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ki, err := ts.GetKeyspace(ctx, "testkeyspace")
+			require.NoError(t, err)
+			utils.MustMatch(t, resp.Keyspace, ki.Keyspace)
+
+			disables := resp.Keyspace.VtorcRecoveryDisables
+			require.Len(t, disables, len(tt.expected))
+			if tt.expectExpire {
+				expireTime := protoutil.TimeFromProto(disables[0].ExpireTime)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), expireTime, time.Minute)
+				disables[0].ExpireTime = nil
+			}
+			utils.MustMatch(t, tt.expected, disables)
+		})
+	}
+}
+
 func TestEmergencyReparentShard(t *testing.T) {
 	t.Parallel()
 
@@ -4890,6 +4999,86 @@ func TestEmergencyReparentShard(t *testing.T) {
 	}
 }
 
+func TestEnableVtorcRecoveries(t *testing.T) {
+	t.Parallel()
+
+	keyspace := &topodatapb.Keyspace{
+		VtorcRecoveryDisables: []*topodatapb.VtorcRecoveryDisable{{
+			Reason: "keyspace",
+		}, {
+			Shard:        "-80",
+			AnalysisCode: "DeadPrimary",
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		req         *vtctldatapb.EnableVtorcRecoveriesRequest
+		expected    []*topodatapb.VtorcRecoveryDisable
+		expectedErr string
+	}{
+		{
+			name: "keyspace",
+			req: &vtctldatapb.EnableVtorcRecoveriesRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: []*topodatapb.VtorcRecoveryDisable{{
+				Shard:        "-80",
+				AnalysisCode: "DeadPrimary",
+			}},
+		},
+		{
+			name: "shard and analysis code",
+			req: &vtctldatapb.EnableVtorcRecoveriesRequest{
+				Keyspace:     "testkeyspace",
+				Shard:        "-80",
+				AnalysisCode: "DeadPrimary",
+			},
+			expected: []*topodatapb.VtorcRecoveryDisable{{
+				Reason: "keyspace",
+			}},
+		},
+		{
+			name: "not found",
+			req: &vtctldatapb.EnableVtorcRecoveriesRequest{
+				Keyspace: "testkeyspace",
+				Shard:    "-80",
+			},
+			expectedErr: `no VTOrc recovery disable found for keyspace testkeyspace, shard "-80" and analysis code ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name:     "testkeyspace",
+				Keyspace: keyspace.CloneVT(),
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.EnableVtorcRecoveries(ctx, tt.req)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp.Keyspace.VtorcRecoveryDisables)
+
+			ki, err := ts.GetKeyspace(ctx, "testkeyspace")
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, ki.VtorcRecoveryDisables)
+		})
+	}
+}
+
 func TestExecuteFetchAsApp(t *testing.T) {
 	t.Parallel()
 
@@ -5734,6 +5923,58 @@ func TestGetTransactionInfo(t *testing.T) {
 	}
 }
 
+func TestExportTopo(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone2", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	})
+
+	paths := func(snapshot *vtctldatapb.TopoSnapshot) []string {
+		var paths []string
+		for _, file := range snapshot.Files {
+			paths = append(paths, file.Cell+":"+file.Path)
+		}
+		return paths
+	}
+
+	resp, err := vtctld.ExportTopo(ctx, &vtctldatapb.ExportTopoRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone1", "zone2"}, resp.Snapshot.Cells)
+	assert.Subset(t, paths(resp.Snapshot), []string{
+		"global:keyspaces/testkeyspace/Keyspace",
+		"global:keyspaces/testkeyspace/shards/-/Shard",
+		"zone1:tablets/zone1-0000000100/Tablet",
+		"zone2:tablets/zone2-0000000200/Tablet",
+	})
+
+	resp, err = vtctld.ExportTopo(ctx, &vtctldatapb.ExportTopoRequest{Cells: []string{"zone2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2"}, resp.Snapshot.Cells)
+	assert.Contains(t, paths(resp.Snapshot), "zone2:tablets/zone2-0000000200/Tablet")
+	assert.NotContains(t, paths(resp.Snapshot), "zone1:tablets/zone1-0000000100/Tablet")
+
+	_, err = vtctld.ExportTopo(ctx, &vtctldatapb.ExportTopoRequest{Cells: []string{"zone3"}})
+	assert.Error(t, err)
+}
+
 func TestFindAllShardsInKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -9204,6 +9445,185 @@ func TestRebuildVSchemaGraph(t *testing.T) {
 	}
 }
 
+func TestRecoverTables(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scratchTablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "ks_recovery",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_DRAINED,
+	}
+	baseTablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  200,
+		},
+		Keyspace: "ks",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}
+
+	tests := []struct {
+		name         string
+		scratch      *topodatapb.Keyspace
+		req          *vtctldatapb.RecoverTablesRequest
+		expectedType topodatapb.TabletType
+		shouldErr    bool
+	}{
+		{
+			name: "ok",
+			scratch: &topodatapb.Keyspace{
+				KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "ks",
+			},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:        "ks",
+				ScratchKeyspace: "ks_recovery",
+				TabletAlias:     scratchTablet.Alias,
+				Tables:          []string{"customer"},
+				RestoreToPos:    "MySQL56/16b1039f-0000-0000-0000-000000000000:1-100",
+			},
+			expectedType: topodatapb.TabletType_RDONLY,
+		},
+		{
+			name: "dry run",
+			scratch: &topodatapb.Keyspace{
+				KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "ks",
+			},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:           "ks",
+				ScratchKeyspace:    "ks_recovery",
+				TabletAlias:        scratchTablet.Alias,
+				Tables:             []string{"customer"},
+				RestoreToTimestamp: protoutil.TimeToProto(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)),
+				DryRun:             true,
+			},
+			expectedType: topodatapb.TabletType_DRAINED,
+		},
+		{
+			name: "no tables",
+			scratch: &topodatapb.Keyspace{
+				KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "ks",
+			},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:        "ks",
+				ScratchKeyspace: "ks_recovery",
+				TabletAlias:     scratchTablet.Alias,
+				RestoreToPos:    "MySQL56/16b1039f-0000-0000-0000-000000000000:1-100",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no restore point",
+			scratch: &topodatapb.Keyspace{
+				KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "ks",
+			},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:        "ks",
+				ScratchKeyspace: "ks_recovery",
+				TabletAlias:     scratchTablet.Alias,
+				Tables:          []string{"customer"},
+			},
+			shouldErr: true,
+		},
+		{
+			name:    "not a snapshot keyspace",
+			scratch: &topodatapb.Keyspace{},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:        "ks",
+				ScratchKeyspace: "ks_recovery",
+				TabletAlias:     scratchTablet.Alias,
+				Tables:          []string{"customer"},
+				RestoreToPos:    "MySQL56/16b1039f-0000-0000-0000-000000000000:1-100",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet not in scratch keyspace",
+			scratch: &topodatapb.Keyspace{
+				KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+				BaseKeyspace: "ks",
+			},
+			req: &vtctldatapb.RecoverTablesRequest{
+				Keyspace:        "ks",
+				ScratchKeyspace: "ks_recovery",
+				TabletAlias:     baseTablet.Alias,
+				Tables:          []string{"customer"},
+				RestoreToPos:    "MySQL56/16b1039f-0000-0000-0000-000000000000:1-100",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := memorytopo.NewServer(ctx, "zone1")
+			defer ts.Close()
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name:     "ks",
+				Keyspace: &topodatapb.Keyspace{},
+			})
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name:     "ks_recovery",
+				Keyspace: tt.scratch,
+			})
+			testutil.AddTablets(ctx, t, ts, nil, proto.Clone(scratchTablet).(*topodatapb.Tablet), proto.Clone(baseTablet).(*topodatapb.Tablet))
+
+			tmc := &testutil.TabletManagerClient{
+				TopoServer: ts,
+				RestoreFromBackupResults: map[string]struct {
+					Events        []*logutilpb.Event
+					EventInterval time.Duration
+					EventJitter   time.Duration
+					ErrorAfter    time.Duration
+				}{
+					"zone1-0000000100": {
+						Events: []*logutilpb.Event{{}, {}, {}},
+					},
+				},
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			client := localvtctldclient.New(vtctld)
+			stream, err := client.RecoverTables(ctx, tt.req)
+			require.NoError(t, err)
+
+			var responses []*vtctldatapb.RecoverTablesResponse
+			for {
+				resp, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if tt.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				responses = append(responses, resp)
+			}
+			require.False(t, tt.shouldErr, "expected RecoverTables to fail")
+			require.Len(t, responses, 3)
+			for _, resp := range responses {
+				assert.Equal(t, "ks_recovery", resp.ScratchKeyspace)
+				assert.Equal(t, "-", resp.Shard)
+			}
+
+			ti, err := ts.GetTablet(ctx, scratchTablet.Alias)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedType, ti.Type)
+		})
+	}
+}
+
 func TestRefreshState(t *testing.T) {
 	t.Parallel()
 
@@ -11099,6 +11519,73 @@ func TestRestoreFromBackup(t *testing.T) {
 	}
 }
 
+func TestRestoreTopo(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+	err := ts.SaveVSchema(ctx, &topo.KeyspaceVSchemaInfo{
+		Name: "testkeyspace",
+		Keyspace: &vschemapb.Keyspace{
+			Tables: map[string]*vschemapb.Table{"t1": {}},
+		},
+	})
+	require.NoError(t, err)
+
+	exported, err := vtctld.ExportTopo(ctx, &vtctldatapb.ExportTopoRequest{})
+	require.NoError(t, err)
+
+	err = ts.SaveVSchema(ctx, &topo.KeyspaceVSchemaInfo{
+		Name:     "testkeyspace",
+		Keyspace: &vschemapb.Keyspace{},
+	})
+	require.NoError(t, err)
+
+	_, err = vtctld.RestoreTopo(ctx, &vtctldatapb.RestoreTopoRequest{})
+	assert.Error(t, err)
+
+	resp, err := vtctld.RestoreTopo(ctx, &vtctldatapb.RestoreTopoRequest{
+		Snapshot:    exported.Snapshot,
+		Keyspaces:   []string{"testkeyspace"},
+		VschemaOnly: true,
+		DryRun:      true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Files, 1)
+	assert.Equal(t, "keyspaces/testkeyspace/VSchema", resp.Files[0].Path)
+	vs, err := ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	assert.Empty(t, vs.Tables)
+
+	resp, err = vtctld.RestoreTopo(ctx, &vtctldatapb.RestoreTopoRequest{
+		Snapshot:  exported.Snapshot,
+		Keyspaces: []string{"testkeyspace"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Files, 1)
+	vs, err = ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	assert.Contains(t, vs.Tables, "t1")
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Contains(t, srvVSchema.Keyspaces["testkeyspace"].Tables, "t1")
+
+	_, err = vtctld.RestoreTopo(ctx, &vtctldatapb.RestoreTopoRequest{
+		Snapshot:  exported.Snapshot,
+		Keyspaces: []string{"otherkeyspace"},
+	})
+	assert.Error(t, err)
+}
+
 func TestRetrySchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -11408,9 +11895,26 @@ func TestRunHealthCheck(t *testing.T) {
 func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	t.Parallel()
 
+	quorumConfig := &topodatapb.DurabilityPolicyConfig{
+		Regions: []*topodatapb.DurabilityPolicyConfig_Region{
+			{Name: "east", Cells: []string{"zone1"}},
+			{Name: "west", Cells: []string{"zone2"}},
+		},
+		Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION, Count: 2},
+	}
+	tablet := func(cell string, uid uint32, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+		return &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: cell, Uid: uid},
+			Keyspace: "ks1",
+			Shard:    "-",
+			Type:     tabletType,
+		}
+	}
+
 	tests := []struct {
 		name        string
 		keyspaces   []*vtctldatapb.Keyspace
+		tablets     []*topodatapb.Tablet
 		req         *vtctldatapb.SetKeyspaceDurabilityPolicyRequest
 		expected    *vtctldatapb.SetKeyspaceDurabilityPolicyResponse
 		expectedErr string
@@ -11437,6 +11941,82 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "quorum policy",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			tablets: []*topodatapb.Tablet{
+				tablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				tablet("zone1", 101, topodatapb.TabletType_REPLICA),
+				tablet("zone2", 200, topodatapb.TabletType_REPLICA),
+				tablet("zone2", 201, topodatapb.TabletType_REPLICA),
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:               "ks1",
+				DurabilityPolicyConfig: quorumConfig,
+			},
+			expected: &vtctldatapb.SetKeyspaceDurabilityPolicyResponse{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy:       policy.DurabilityQuorum,
+					DurabilityPolicyConfig: quorumConfig,
+				},
+			},
+		},
+		{
+			name: "quorum policy cannot be kept by the tablets",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			tablets: []*topodatapb.Tablet{
+				tablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				tablet("zone1", 101, topodatapb.TabletType_REPLICA),
+				tablet("zone2", 200, topodatapb.TabletType_REPLICA),
+				tablet("zone2", 201, topodatapb.TabletType_RDONLY),
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:               "ks1",
+				DurabilityPolicy:       policy.DurabilityQuorum,
+				DurabilityPolicyConfig: quorumConfig,
+			},
+			expectedErr: "durability policy cannot be kept in shard ks1/-: tablet zone1-0000000100 cannot be promoted: requires 2 acks but only 1 tablets can ack\n" +
+				"tablet zone1-0000000101 cannot be promoted: requires 2 acks but only 1 tablets can ack",
+		},
+		{
+			name: "invalid quorum config",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:               "ks1",
+				DurabilityPolicyConfig: &topodatapb.DurabilityPolicyConfig{},
+			},
+			expectedErr: "invalid config for durability policy <quorum>: durability policy quorum requires at least one ack rule, use none for no semi-sync acks",
+		},
+		{
+			name: "config for a named policy",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:               "ks1",
+				DurabilityPolicy:       policy.DurabilitySemiSync,
+				DurabilityPolicyConfig: quorumConfig,
+			},
+			expectedErr: "durability policy <semi_sync> does not take a config, only <quorum> does",
+		},
 		{
 			name: "keyspace not found",
 			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
@@ -11467,8 +12047,9 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			ts := memorytopo.NewServer(ctx, "zone1")
+			ts := memorytopo.NewServer(ctx, "zone1", "zone2")
 			testutil.AddKeyspaces(ctx, t, ts, tt.keyspaces...)
+			testutil.AddTablets(ctx, t, ts, nil, tt.tablets...)
 
 			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(vtenv.NewTestEnv(), ts)
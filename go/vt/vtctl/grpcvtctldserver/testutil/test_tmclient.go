@@ -206,6 +206,13 @@ type TabletManagerClient struct {
 		Error  error
 	}
 	// keyed by tablet alias.
+	DrainPrimaryResults map[string]struct {
+		Transactions []*tabletmanagerdatapb.DrainedTransaction
+		Error        error
+	}
+	// keyed by tablet alias.
+	UndoDrainPrimaryResults map[string]error
+	// keyed by tablet alias.
 	ExecuteFetchAsAppDelays map[string]time.Duration
 	// keyed by tablet alias.
 	ExecuteFetchAsAppResults map[string]struct {
@@ -578,6 +585,42 @@ func (fake *TabletManagerClient) DemotePrimary(ctx context.Context, tablet *topo
 	return nil, assert.AnError
 }
 
+// DrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) DrainPrimary(ctx context.Context, tablet *topodatapb.Tablet, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	if fake.DrainPrimaryResults == nil {
+		return nil, assert.AnError
+	}
+
+	if tablet.Alias == nil {
+		return nil, assert.AnError
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.DrainPrimaryResults[key]; ok {
+		return result.Transactions, result.Error
+	}
+
+	return nil, assert.AnError
+}
+
+// UndoDrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) UndoDrainPrimary(ctx context.Context, tablet *topodatapb.Tablet) error {
+	if fake.UndoDrainPrimaryResults == nil {
+		return assert.AnError
+	}
+
+	if tablet.Alias == nil {
+		return assert.AnError
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.UndoDrainPrimaryResults[key]; ok {
+		return result
+	}
+
+	return assert.AnError
+}
+
 // ExecuteFetchAsApp is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsAppRequest) (*querypb.QueryResult, error) {
 	if fake.ExecuteFetchAsAppResults == nil {
@@ -246,11 +246,21 @@ func (client *localVtctldClient) DeleteTablets(ctx context.Context, in *vtctldat
 	return client.s.DeleteTablets(ctx, in)
 }
 
+// DisableVtorcRecoveries is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DisableVtorcRecoveries(ctx context.Context, in *vtctldatapb.DisableVtorcRecoveriesRequest, opts ...grpc.CallOption) (*vtctldatapb.DisableVtorcRecoveriesResponse, error) {
+	return client.s.DisableVtorcRecoveries(ctx, in)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	return client.s.EmergencyReparentShard(ctx, in)
 }
 
+// EnableVtorcRecoveries is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) EnableVtorcRecoveries(ctx context.Context, in *vtctldatapb.EnableVtorcRecoveriesRequest, opts ...grpc.CallOption) (*vtctldatapb.EnableVtorcRecoveriesResponse, error) {
+	return client.s.EnableVtorcRecoveries(ctx, in)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	return client.s.ExecuteFetchAsApp(ctx, in)
@@ -271,6 +281,11 @@ func (client *localVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in
 	return client.s.ExecuteMultiFetchAsDBA(ctx, in)
 }
 
+// ExportTopo is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExportTopo(ctx context.Context, in *vtctldatapb.ExportTopoRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopoResponse, error) {
+	return client.s.ExportTopo(ctx, in)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	return client.s.FindAllShardsInKeyspace(ctx, in)
@@ -516,6 +531,57 @@ func (client *localVtctldClient) RebuildVSchemaGraph(ctx context.Context, in *vt
 	return client.s.RebuildVSchemaGraph(ctx, in)
 }
 
+type recoverTablesStreamAdapter struct {
+	*grpcshim.BidiStream
+	ch chan *vtctldatapb.RecoverTablesResponse
+}
+
+func (stream *recoverTablesStreamAdapter) Recv() (*vtctldatapb.RecoverTablesResponse, error) {
+	select {
+	case <-stream.Context().Done():
+		return nil, stream.Context().Err()
+	case <-stream.Closed():
+		// Stream has been closed for future sends. If there are messages that
+		// have already been sent, receive them until there are no more. After
+		// all sent messages have been received, Recv will return the CloseErr.
+		select {
+		case msg := <-stream.ch:
+			return msg, nil
+		default:
+			return nil, stream.CloseErr()
+		}
+	case err := <-stream.ErrCh:
+		return nil, err
+	case msg := <-stream.ch:
+		return msg, nil
+	}
+}
+
+func (stream *recoverTablesStreamAdapter) Send(msg *vtctldatapb.RecoverTablesResponse) error {
+	select {
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-stream.Closed():
+		return grpcshim.ErrStreamClosed
+	case stream.ch <- msg:
+		return nil
+	}
+}
+
+// RecoverTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RecoverTables(ctx context.Context, in *vtctldatapb.RecoverTablesRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RecoverTablesClient, error) {
+	stream := &recoverTablesStreamAdapter{
+		BidiStream: grpcshim.NewBidiStream(ctx),
+		ch:         make(chan *vtctldatapb.RecoverTablesResponse, 1),
+	}
+	go func() {
+		err := client.s.RecoverTables(in, stream)
+		stream.CloseWithError(err)
+	}()
+
+	return stream, nil
+}
+
 // RefreshState is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RefreshState(ctx context.Context, in *vtctldatapb.RefreshStateRequest, opts ...grpc.CallOption) (*vtctldatapb.RefreshStateResponse, error) {
 	return client.s.RefreshState(ctx, in)
@@ -561,6 +627,16 @@ func (client *localVtctldClient) ReparentTablet(ctx context.Context, in *vtctlda
 	return client.s.ReparentTablet(ctx, in)
 }
 
+// ReplicateCreate is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReplicateCreate(ctx context.Context, in *vtctldatapb.ReplicateCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
+	return client.s.ReplicateCreate(ctx, in)
+}
+
+// ReplicatePromote is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReplicatePromote(ctx context.Context, in *vtctldatapb.ReplicatePromoteRequest, opts ...grpc.CallOption) (*vtctldatapb.ReplicatePromoteResponse, error) {
+	return client.s.ReplicatePromote(ctx, in)
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ReshardCreate(ctx context.Context, in *vtctldatapb.ReshardCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	return client.s.ReshardCreate(ctx, in)
@@ -617,6 +693,11 @@ func (client *localVtctldClient) RestoreFromBackup(ctx context.Context, in *vtct
 	return stream, nil
 }
 
+// RestoreTopo is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RestoreTopo(ctx context.Context, in *vtctldatapb.RestoreTopoRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopoResponse, error) {
+	return client.s.RestoreTopo(ctx, in)
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RetrySchemaMigration(ctx context.Context, in *vtctldatapb.RetrySchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.RetrySchemaMigrationResponse, error) {
 	return client.s.RetrySchemaMigration(ctx, in)
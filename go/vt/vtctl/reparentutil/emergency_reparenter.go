@@ -176,13 +176,13 @@ func (erp *EmergencyReparenter) reparentShardLocked(ctx context.Context, ev *eve
 		)
 	}
 
-	keyspaceDurability, err := erp.ts.GetKeyspaceDurability(ctx, keyspace)
+	keyspaceDurability, durabilityConfig, err := erp.ts.GetKeyspaceDurability(ctx, keyspace)
 	if err != nil {
 		return err
 	}
 
 	erp.logger.Infof("Getting a new durability policy for %v", keyspaceDurability)
-	opts.durability, err = policy.GetDurabilityPolicy(keyspaceDurability)
+	opts.durability, err = policy.GetDurabilityPolicyWithConfig(keyspaceDurability, durabilityConfig)
 	if err != nil {
 		return err
 	}
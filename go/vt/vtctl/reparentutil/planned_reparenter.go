@@ -27,6 +27,7 @@ import (
 
 	"vitess.io/vitess/go/event"
 	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/logutil"
@@ -66,6 +67,10 @@ type PlannedReparentOptions struct {
 	WaitReplicasTimeout     time.Duration
 	TolerableReplLag        time.Duration
 	AllowCrossCellPromotion bool
+	// DrainTimeout, if positive, is how long the current primary waits for its
+	// in-flight transactions to finish before it is demoted. It does not start
+	// new transactions in the meantime.
+	DrainTimeout time.Duration
 
 	// Private options managed internally. We use value-passing semantics to
 	// set these options inside a PlannedReparent without leaking these details
@@ -261,8 +266,22 @@ func (pr *PlannedReparenter) performGracefulPromotion(
 		return vterrors.Wrapf(err, "replication on primary-elect %v did not catch up in time; replication must be healthy to perform PlannedReparent", primaryElectAliasStr)
 	}
 
+	// If requested, let the in-flight transactions on the current primary
+	// finish before demoting it. VTGates buffer new transactions meanwhile.
+	drained := false
+	if opts.DrainTimeout > 0 {
+		if err := pr.drainPrimary(ctx, ev, currentPrimary, opts.DrainTimeout); err != nil {
+			pr.undoDrainPrimary(currentPrimary)
+			return err
+		}
+		drained = true
+	}
+
 	// Verify we still have the topology lock before doing the demotion.
 	if err := topo.CheckShardLocked(ctx, keyspace, shard); err != nil {
+		if drained {
+			pr.undoDrainPrimary(currentPrimary)
+		}
 		return vterrors.Wrap(err, lostTopologyLockMsg)
 	}
 
@@ -277,6 +296,9 @@ func (pr *PlannedReparenter) performGracefulPromotion(
 
 	primaryStatus, err := pr.tmc.DemotePrimary(demoteCtx, currentPrimary.Tablet)
 	if err != nil {
+		if drained {
+			pr.undoDrainPrimary(currentPrimary)
+		}
 		return vterrors.Wrapf(err, "failed to DemotePrimary on current primary %v: %v", currentPrimary.AliasString(), err)
 	}
 
@@ -316,6 +338,49 @@ func (pr *PlannedReparenter) performGracefulPromotion(
 	return nil
 }
 
+// drainPrimary stops the current primary from starting new transactions, and
+// waits up to timeout for the ones in flight to finish. The outcome of each
+// transaction is recorded in the reparent event log.
+func (pr *PlannedReparenter) drainPrimary(ctx context.Context, ev *events.Reparent, currentPrimary *topo.TabletInfo, timeout time.Duration) error {
+	pr.logger.Infof("draining current primary %v for up to %v", currentPrimary.AliasString(), timeout)
+	event.DispatchUpdate(ev, "draining old primary")
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, timeout+topo.RemoteOperationTimeout)
+	defer drainCancel()
+
+	txs, err := pr.tmc.DrainPrimary(drainCtx, currentPrimary.Tablet, timeout)
+	if err != nil {
+		return vterrors.Wrapf(err, "failed to DrainPrimary on current primary %v: %v", currentPrimary.AliasString(), err)
+	}
+
+	stillOpen := 0
+	for _, t := range txs {
+		outcome := t.Outcome
+		if outcome == "" {
+			outcome = "still open, it will finish or be rolled back during the demotion"
+			stillOpen++
+		}
+		msg := fmt.Sprintf("drained transaction %d of %q started at %v: %s",
+			t.TransactionId, t.Caller, protoutil.TimeFromProto(t.StartTime).UTC(), outcome)
+		pr.logger.Infof("%s", msg)
+		event.DispatchUpdate(ev, msg)
+	}
+	event.DispatchUpdate(ev, fmt.Sprintf("drained old primary: %d of %d transactions finished", len(txs)-stillOpen, len(txs)))
+	return nil
+}
+
+// undoDrainPrimary lets the current primary start new transactions again when
+// the reparent is abandoned after draining it, but before demoting it.
+func (pr *PlannedReparenter) undoDrainPrimary(currentPrimary *topo.TabletInfo) {
+	// The calling context may be done already, so we use a new one.
+	undoCtx, undoCancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer undoCancel()
+
+	if err := pr.tmc.UndoDrainPrimary(undoCtx, currentPrimary.Tablet); err != nil {
+		pr.logger.Warningf("encountered error while performing UndoDrainPrimary(%v): %v", currentPrimary.AliasString(), err)
+	}
+}
+
 func (pr *PlannedReparenter) performInitialPromotion(
 	ctx context.Context,
 	primaryElect *topodatapb.Tablet,
@@ -516,13 +581,13 @@ func (pr *PlannedReparenter) reparentShardLocked(
 		)
 	}
 
-	keyspaceDurability, err := pr.ts.GetKeyspaceDurability(ctx, keyspace)
+	keyspaceDurability, durabilityConfig, err := pr.ts.GetKeyspaceDurability(ctx, keyspace)
 	if err != nil {
 		return err
 	}
 
 	pr.logger.Infof("Getting a new durability policy for %v", keyspaceDurability)
-	opts.durability, err = policy.GetDurabilityPolicy(keyspaceDurability)
+	opts.durability, err = policy.GetDurabilityPolicyWithConfig(keyspaceDurability, durabilityConfig)
 	if err != nil {
 		return err
 	}
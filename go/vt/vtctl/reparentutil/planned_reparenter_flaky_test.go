@@ -40,6 +40,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vttime"
@@ -1286,6 +1287,121 @@ func TestPlannedReparenter_performGracefulPromotion(t *testing.T) {
 			opts:      PlannedReparentOptions{},
 			shouldErr: false,
 		},
+		{
+			name: "successful promotion after draining the current primary",
+			tmc: &testutil.TabletManagerClient{
+				DemotePrimaryResults: map[string]struct {
+					Status *replicationdatapb.PrimaryStatus
+					Error  error
+				}{
+					"zone1-0000000100": {
+						Status: &replicationdatapb.PrimaryStatus{
+							Position: "position1",
+						},
+						Error: nil,
+					},
+				},
+				DrainPrimaryResults: map[string]struct {
+					Transactions []*tabletmanagerdatapb.DrainedTransaction
+					Error        error
+				}{
+					"zone1-0000000100": {
+						Transactions: []*tabletmanagerdatapb.DrainedTransaction{
+							{TransactionId: 1, Caller: "app", Outcome: "commit"},
+							{TransactionId: 2, Caller: "app"},
+						},
+					},
+				},
+				PrimaryPositionResults: map[string]struct {
+					Position string
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Position: "MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-10",
+					},
+				},
+				SetReplicationSourceResults: map[string]error{
+					"zone1-0000000200": nil,
+				},
+				WaitForPositionResults: map[string]map[string]error{
+					"zone1-0000000200": {
+						"position1": nil,
+					},
+				},
+			},
+			ev:       &events.Reparent{},
+			keyspace: "testkeyspace",
+			shard:    "-",
+			currentPrimary: &topo.TabletInfo{
+				Tablet: &topodatapb.Tablet{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			primaryElect: &topodatapb.Tablet{
+				Alias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  200,
+				},
+			},
+			opts: PlannedReparentOptions{
+				DrainTimeout: time.Second,
+			},
+			shouldErr: false,
+		},
+		{
+			name: "failed to drain the current primary",
+			tmc: &testutil.TabletManagerClient{
+				DrainPrimaryResults: map[string]struct {
+					Transactions []*tabletmanagerdatapb.DrainedTransaction
+					Error        error
+				}{
+					"zone1-0000000100": {
+						Error: assert.AnError,
+					},
+				},
+				PrimaryPositionResults: map[string]struct {
+					Position string
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Position: "MySQL56/3E11FA47-71CA-11E1-9E33-C80AA9429562:1-10",
+					},
+				},
+				SetReplicationSourceResults: map[string]error{
+					"zone1-0000000200": nil,
+				},
+				UndoDrainPrimaryResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			ev:       &events.Reparent{},
+			keyspace: "testkeyspace",
+			shard:    "-",
+			currentPrimary: &topo.TabletInfo{
+				Tablet: &topodatapb.Tablet{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			primaryElect: &topodatapb.Tablet{
+				Alias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  200,
+				},
+			},
+			opts: PlannedReparentOptions{
+				DrainTimeout: time.Second,
+			},
+			shouldErr: true,
+			extraAssertions: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "failed to DrainPrimary")
+			},
+		},
 		{
 			name: "cannot get snapshot of current primary",
 			tmc: &testutil.TabletManagerClient{
@@ -50,6 +50,8 @@ const (
 	DurabilityCrossCellWithRdonlyAck = "cross_cell_with_rdonly_ack"
 	// DurabilityTest is the name of the durability policy that has no semi-sync setup but overrides the type for a specific tablet to prefer. It is only meant to be used for testing purposes!
 	DurabilityTest = "test"
+	// DurabilityQuorum is the name of the durability policy that is declared by a DurabilityPolicyConfig instead of being registered. See GetDurabilityPolicyWithConfig.
+	DurabilityQuorum = "quorum"
 )
 
 func init() {
@@ -142,6 +144,31 @@ func IsReplicaSemiSync(durability Durabler, primary, replica *topodatapb.Tablet)
 	return durability.IsReplicaSemiSync(primary, replica)
 }
 
+// PrimaryLossQuorum returns the replicas that have a say in deciding that the primary
+// has been lost, and how many of them must agree. If the primary requires semi-sync
+// acks, these are the replicas that can ack its writes, and the quorum is reached once
+// too few of them are left to ack a write, i.e. once the primary can no longer commit.
+// Otherwise all the given replicas have a say, and a majority of them is needed.
+// No voters are returned if no quorum can be reached.
+func PrimaryLossQuorum(durability Durabler, primary *topodatapb.Tablet, replicas []*topodatapb.Tablet) (voters []*topodatapb.Tablet, quorum int) {
+	ackers := SemiSyncAckers(durability, primary)
+	if ackers == 0 {
+		if len(replicas) == 0 {
+			return nil, 0
+		}
+		return replicas, len(replicas)/2 + 1
+	}
+	for _, replica := range replicas {
+		if IsReplicaSemiSync(durability, primary, replica) {
+			voters = append(voters, replica)
+		}
+	}
+	if len(voters) == 0 {
+		return nil, 0
+	}
+	return voters, max(len(voters)-ackers+1, 1)
+}
+
 //=======================================================================
 
 // durabilityNone has no semi-sync and returns NeutralPromoteRule for Primary and Replica tablet types, MustNotPromoteRule for everything else
@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+)
+
+// maxQuorumAckers is the largest number of acks a primary can wait for, which
+// is the limit of rpl_semi_sync_source_wait_for_replica_count in MySQL.
+const maxQuorumAckers = 65535
+
+// GetDurabilityPolicyWithConfig returns a new durability policy. The quorum
+// policy is built from the given config, while the registered policies are
+// looked up by name and ignore it.
+func GetDurabilityPolicyWithConfig(name string, config *topodatapb.DurabilityPolicyConfig) (Durabler, error) {
+	if name != DurabilityQuorum {
+		return GetDurabilityPolicy(name)
+	}
+	if err := ValidateDurabilityPolicyConfig(config); err != nil {
+		return nil, err
+	}
+	return newDurabilityQuorum(config), nil
+}
+
+// ValidateDurabilityPolicyConfig checks that the config declares a usable
+// quorum durability policy.
+func ValidateDurabilityPolicyConfig(config *topodatapb.DurabilityPolicyConfig) error {
+	if config == nil {
+		return fmt.Errorf("durability policy %v requires a config", DurabilityQuorum)
+	}
+	if config.Acks == nil {
+		return fmt.Errorf("durability policy %v requires an ack rule, use %v for no semi-sync acks", DurabilityQuorum, DurabilityNone)
+	}
+
+	regions := make(map[string]bool, len(config.Regions))
+	cellRegions := make(map[string]string)
+	for _, region := range config.Regions {
+		if region.Name == "" {
+			return errors.New("region names cannot be empty")
+		}
+		if regions[region.Name] {
+			return fmt.Errorf("region %v is declared more than once", region.Name)
+		}
+		regions[region.Name] = true
+		if len(region.Cells) == 0 {
+			return fmt.Errorf("region %v has no cells", region.Name)
+		}
+		for _, cell := range region.Cells {
+			if cell == "" {
+				return fmt.Errorf("region %v has an empty cell", region.Name)
+			}
+			if other, ok := cellRegions[cell]; ok {
+				return fmt.Errorf("cell %v is in both regions %v and %v", cell, other, region.Name)
+			}
+			cellRegions[cell] = region.Name
+		}
+	}
+
+	rule := config.Acks
+	if rule.Count == 0 {
+		return errors.New("ack rule requires no acks")
+	}
+	if rule.Count > maxQuorumAckers {
+		return fmt.Errorf("ack rule requires more than %d acks", maxQuorumAckers)
+	}
+	switch rule.Scope {
+	case topodatapb.DurabilityPolicyConfig_CELLS:
+		if len(rule.Cells) == 0 || slices.Contains(rule.Cells, "") {
+			return fmt.Errorf("ack rule with scope %v requires a list of cells", rule.Scope)
+		}
+	case topodatapb.DurabilityPolicyConfig_SAME_REGION, topodatapb.DurabilityPolicyConfig_OTHER_REGION:
+		if len(config.Regions) == 0 {
+			return fmt.Errorf("ack rule with scope %v requires regions", rule.Scope)
+		}
+	case topodatapb.DurabilityPolicyConfig_ANY_CELL, topodatapb.DurabilityPolicyConfig_SAME_CELL, topodatapb.DurabilityPolicyConfig_OTHER_CELL:
+	default:
+		return fmt.Errorf("ack rule has unknown scope %v", rule.Scope)
+	}
+	if rule.Scope != topodatapb.DurabilityPolicyConfig_CELLS && len(rule.Cells) > 0 {
+		return fmt.Errorf("ack rule with scope %v cannot list cells", rule.Scope)
+	}
+
+	if slices.Contains(config.PreferredCells, "") {
+		return errors.New("preferred cells cannot be empty")
+	}
+	return nil
+}
+
+// ValidateDurabilityPolicy checks that every tablet the durability policy lets
+// be promoted has enough tablets in the shard to ack its writes. tablets are
+// all the tablets of the shard.
+func ValidateDurabilityPolicy(durability Durabler, tablets []*topodatapb.Tablet) error {
+	var errs []error
+	for _, tablet := range tablets {
+		if PromotionRule(durability, tablet) == promotionrule.MustNot {
+			continue
+		}
+		var ackers []*topodatapb.Tablet
+		for _, replica := range tablets {
+			if !topoproto.TabletAliasEqual(tablet.Alias, replica.Alias) && IsReplicaSemiSync(durability, tablet, replica) {
+				ackers = append(ackers, replica)
+			}
+		}
+		if required := SemiSyncAckers(durability, tablet); len(ackers) < required {
+			errs = append(errs, fmt.Errorf("tablet %v cannot be promoted: requires %d acks but only %d tablets can ack", topoproto.TabletAliasString(tablet.Alias), required, len(ackers)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//=======================================================================
+
+// durabilityQuorum is declared by a DurabilityPolicyConfig. The primary waits for
+// the acks its ack rule requires, and only the tablets in scope of the rule send
+// acks. MySQL counts the acks without regard to where they come from, so the rule
+// is kept by choosing the ackers, and every tablet that can be promoted must have
+// enough ackers in scope.
+// It returns PreferPromoteRule for Primary and Replica tablet types in the preferred cells,
+// NeutralPromoteRule for the other Primary and Replica tablets, and MustNotPromoteRule
+// for everything else, including the tablets outside of every region if the
+// rules use regions.
+type durabilityQuorum struct {
+	config *topodatapb.DurabilityPolicyConfig
+	// regions maps each cell to its region.
+	regions map[string]string
+	// usesRegions is set if the rule is scoped by region.
+	usesRegions bool
+}
+
+func newDurabilityQuorum(config *topodatapb.DurabilityPolicyConfig) *durabilityQuorum {
+	d := &durabilityQuorum{
+		config:  config.CloneVT(),
+		regions: make(map[string]string),
+	}
+	for _, region := range config.Regions {
+		for _, cell := range region.Cells {
+			d.regions[cell] = region.Name
+		}
+	}
+	switch config.Acks.GetScope() {
+	case topodatapb.DurabilityPolicyConfig_SAME_REGION, topodatapb.DurabilityPolicyConfig_OTHER_REGION:
+		d.usesRegions = true
+	}
+	return d
+}
+
+// PromotionRule implements the Durabler interface
+func (d *durabilityQuorum) PromotionRule(tablet *topodatapb.Tablet) promotionrule.CandidatePromotionRule {
+	switch tablet.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+	default:
+		return promotionrule.MustNot
+	}
+	if d.usesRegions && d.regions[tablet.Alias.Cell] == "" {
+		return promotionrule.MustNot
+	}
+	if slices.Contains(d.config.PreferredCells, tablet.Alias.Cell) {
+		return promotionrule.Prefer
+	}
+	return promotionrule.Neutral
+}
+
+// SemiSyncAckers implements the Durabler interface
+func (d *durabilityQuorum) SemiSyncAckers(tablet *topodatapb.Tablet) int {
+	return int(d.config.Acks.GetCount())
+}
+
+// IsReplicaSemiSync implements the Durabler interface
+func (d *durabilityQuorum) IsReplicaSemiSync(primary, replica *topodatapb.Tablet) bool {
+	switch replica.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+	case topodatapb.TabletType_RDONLY:
+		if !d.config.RdonlyAcks {
+			return false
+		}
+	default:
+		return false
+	}
+	return d.inScope(d.config.Acks, primary, replica)
+}
+
+// inScope returns whether the replica can send the acks of the rule when
+// primary is the primary.
+func (d *durabilityQuorum) inScope(rule *topodatapb.DurabilityPolicyConfig_AckRule, primary, replica *topodatapb.Tablet) bool {
+	primaryCell, replicaCell := primary.Alias.Cell, replica.Alias.Cell
+	switch rule.Scope {
+	case topodatapb.DurabilityPolicyConfig_ANY_CELL:
+		return true
+	case topodatapb.DurabilityPolicyConfig_SAME_CELL:
+		return primaryCell == replicaCell
+	case topodatapb.DurabilityPolicyConfig_OTHER_CELL:
+		return primaryCell != replicaCell
+	case topodatapb.DurabilityPolicyConfig_SAME_REGION:
+		region := d.regions[primaryCell]
+		return region != "" && region == d.regions[replicaCell]
+	case topodatapb.DurabilityPolicyConfig_OTHER_REGION:
+		region, replicaRegion := d.regions[primaryCell], d.regions[replicaCell]
+		return region != "" && replicaRegion != "" && region != replicaRegion
+	case topodatapb.DurabilityPolicyConfig_CELLS:
+		return slices.Contains(rule.Cells, replicaCell)
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+)
+
+func quorumTablet(cell string, uid uint32, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+	return &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: cell,
+			Uid:  uid,
+		},
+		Type: tabletType,
+	}
+}
+
+func TestValidateDurabilityPolicyConfig(t *testing.T) {
+	regions := []*topodatapb.DurabilityPolicyConfig_Region{
+		{Name: "east", Cells: []string{"zone1", "zone2"}},
+		{Name: "west", Cells: []string{"zone3"}},
+	}
+	testcases := []struct {
+		name        string
+		config      *topodatapb.DurabilityPolicyConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Regions:        regions,
+				Acks:           &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION, Count: 1},
+				PreferredCells: []string{"zone1"},
+			},
+		}, {
+			name:        "no config",
+			expectedErr: "durability policy quorum requires a config",
+		}, {
+			name:        "no ack rule",
+			config:      &topodatapb.DurabilityPolicyConfig{Regions: regions},
+			expectedErr: "durability policy quorum requires an ack rule, use none for no semi-sync acks",
+		}, {
+			name: "duplicate region",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Regions: []*topodatapb.DurabilityPolicyConfig_Region{
+					{Name: "east", Cells: []string{"zone1"}},
+					{Name: "east", Cells: []string{"zone2"}},
+				},
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Count: 1},
+			},
+			expectedErr: "region east is declared more than once",
+		}, {
+			name: "cell in two regions",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Regions: []*topodatapb.DurabilityPolicyConfig_Region{
+					{Name: "east", Cells: []string{"zone1"}},
+					{Name: "west", Cells: []string{"zone1"}},
+				},
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Count: 1},
+			},
+			expectedErr: "cell zone1 is in both regions east and west",
+		}, {
+			name: "rule without acks",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_CELL},
+			},
+			expectedErr: "ack rule requires no acks",
+		}, {
+			name: "region scope without regions",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION, Count: 1},
+			},
+			expectedErr: "ack rule with scope OTHER_REGION requires regions",
+		}, {
+			name: "cells scope without cells",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_CELLS, Count: 1},
+			},
+			expectedErr: "ack rule with scope CELLS requires a list of cells",
+		}, {
+			name: "cells with another scope",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_SAME_CELL, Cells: []string{"zone1"}, Count: 1},
+			},
+			expectedErr: "ack rule with scope SAME_CELL cannot list cells",
+		}, {
+			name: "too many acks",
+			config: &topodatapb.DurabilityPolicyConfig{
+				Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Count: maxQuorumAckers + 1},
+			},
+			expectedErr: "ack rule requires more than 65535 acks",
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDurabilityPolicyConfig(tt.config)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestDurabilityQuorum(t *testing.T) {
+	_, err := GetDurabilityPolicy(DurabilityQuorum)
+	require.EqualError(t, err, "durability policy quorum not found")
+	_, err = GetDurabilityPolicyWithConfig(DurabilityQuorum, nil)
+	require.EqualError(t, err, "durability policy quorum requires a config")
+
+	// The config of named policies is ignored.
+	durability, err := GetDurabilityPolicyWithConfig(DurabilitySemiSync, &topodatapb.DurabilityPolicyConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &durabilitySemiSync{}, durability)
+
+	durability, err = GetDurabilityPolicyWithConfig(DurabilityQuorum, &topodatapb.DurabilityPolicyConfig{
+		Regions: []*topodatapb.DurabilityPolicyConfig_Region{
+			{Name: "east", Cells: []string{"zone1", "zone2"}},
+			{Name: "west", Cells: []string{"zone3"}},
+		},
+		Acks:           &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION, Count: 2},
+		PreferredCells: []string{"zone2"},
+	})
+	require.NoError(t, err)
+
+	primary := quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY)
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, primary))
+	assert.Equal(t, promotionrule.Prefer, PromotionRule(durability, quorumTablet("zone2", 200, topodatapb.TabletType_REPLICA)))
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, quorumTablet("zone1", 101, topodatapb.TabletType_RDONLY)))
+	// Tablets outside of every region cannot be promoted, as the region rules don't apply to them.
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, quorumTablet("zone4", 400, topodatapb.TabletType_REPLICA)))
+
+	assert.Equal(t, 2, SemiSyncAckers(durability, primary))
+
+	assert.False(t, IsReplicaSemiSync(durability, primary, quorumTablet("zone2", 200, topodatapb.TabletType_REPLICA)))
+	assert.True(t, IsReplicaSemiSync(durability, primary, quorumTablet("zone3", 300, topodatapb.TabletType_REPLICA)))
+	assert.False(t, IsReplicaSemiSync(durability, primary, quorumTablet("zone4", 400, topodatapb.TabletType_REPLICA)))
+	assert.False(t, IsReplicaSemiSync(durability, primary, quorumTablet("zone3", 301, topodatapb.TabletType_RDONLY)))
+	assert.False(t, IsReplicaSemiSync(durability, primary, quorumTablet("zone3", 302, topodatapb.TabletType_SPARE)))
+}
+
+func TestDurabilityQuorumScopes(t *testing.T) {
+	regions := []*topodatapb.DurabilityPolicyConfig_Region{
+		{Name: "east", Cells: []string{"zone1", "zone2"}},
+		{Name: "west", Cells: []string{"zone3"}},
+	}
+	primary := quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY)
+	sameCell := quorumTablet("zone1", 101, topodatapb.TabletType_REPLICA)
+	sameRegion := quorumTablet("zone2", 200, topodatapb.TabletType_REPLICA)
+	otherRegion := quorumTablet("zone3", 300, topodatapb.TabletType_REPLICA)
+	rdonly := quorumTablet("zone3", 301, topodatapb.TabletType_RDONLY)
+
+	testcases := []struct {
+		name       string
+		rule       *topodatapb.DurabilityPolicyConfig_AckRule
+		rdonlyAcks bool
+		ackers     []*topodatapb.Tablet
+	}{
+		{
+			name:   "any cell",
+			rule:   &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_ANY_CELL},
+			ackers: []*topodatapb.Tablet{sameCell, sameRegion, otherRegion},
+		}, {
+			name:   "same cell",
+			rule:   &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_SAME_CELL},
+			ackers: []*topodatapb.Tablet{sameCell},
+		}, {
+			name:   "other cell",
+			rule:   &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_CELL},
+			ackers: []*topodatapb.Tablet{sameRegion, otherRegion},
+		}, {
+			name:   "same region",
+			rule:   &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_SAME_REGION},
+			ackers: []*topodatapb.Tablet{sameCell, sameRegion},
+		}, {
+			name:       "other region with rdonly acks",
+			rule:       &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION},
+			rdonlyAcks: true,
+			ackers:     []*topodatapb.Tablet{otherRegion, rdonly},
+		}, {
+			name:   "cells",
+			rule:   &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_CELLS, Cells: []string{"zone2", "zone3"}},
+			ackers: []*topodatapb.Tablet{sameRegion, otherRegion},
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Count = 1
+			durability, err := GetDurabilityPolicyWithConfig(DurabilityQuorum, &topodatapb.DurabilityPolicyConfig{
+				Regions:    regions,
+				Acks:       tt.rule,
+				RdonlyAcks: tt.rdonlyAcks,
+			})
+			require.NoError(t, err)
+
+			var ackers []*topodatapb.Tablet
+			for _, replica := range []*topodatapb.Tablet{sameCell, sameRegion, otherRegion, rdonly} {
+				if IsReplicaSemiSync(durability, primary, replica) {
+					ackers = append(ackers, replica)
+				}
+			}
+			assert.Equal(t, tt.ackers, ackers)
+		})
+	}
+}
+
+func TestValidateDurabilityPolicy(t *testing.T) {
+	config := &topodatapb.DurabilityPolicyConfig{
+		Regions: []*topodatapb.DurabilityPolicyConfig_Region{
+			{Name: "east", Cells: []string{"zone1", "zone2"}},
+			{Name: "west", Cells: []string{"zone3"}},
+		},
+		Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_REGION, Count: 1},
+	}
+	quorum, err := GetDurabilityPolicyWithConfig(DurabilityQuorum, config)
+	require.NoError(t, err)
+	semiSync, err := GetDurabilityPolicy(DurabilitySemiSync)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name        string
+		durability  Durabler
+		tablets     []*topodatapb.Tablet
+		expectedErr string
+	}{
+		{
+			name:       "every tablet has enough ackers",
+			durability: quorum,
+			tablets: []*topodatapb.Tablet{
+				quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				quorumTablet("zone2", 200, topodatapb.TabletType_REPLICA),
+				quorumTablet("zone3", 300, topodatapb.TabletType_REPLICA),
+			},
+		}, {
+			name:       "not enough ackers",
+			durability: quorum,
+			tablets: []*topodatapb.Tablet{
+				quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				quorumTablet("zone2", 200, topodatapb.TabletType_REPLICA),
+				quorumTablet("zone3", 300, topodatapb.TabletType_RDONLY),
+			},
+			expectedErr: "tablet zone1-0000000100 cannot be promoted: requires 1 acks but only 0 tablets can ack\n" +
+				"tablet zone2-0000000200 cannot be promoted: requires 1 acks but only 0 tablets can ack",
+		}, {
+			// zone4-400 is outside of every region, so it neither acks nor
+			// needs ackers.
+			name:       "tablets that cannot be promoted are not validated",
+			durability: quorum,
+			tablets: []*topodatapb.Tablet{
+				quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				quorumTablet("zone3", 300, topodatapb.TabletType_REPLICA),
+				quorumTablet("zone4", 400, topodatapb.TabletType_REPLICA),
+			},
+		}, {
+			name:       "named policy",
+			durability: semiSync,
+			tablets: []*topodatapb.Tablet{
+				quorumTablet("zone1", 100, topodatapb.TabletType_PRIMARY),
+				quorumTablet("zone1", 101, topodatapb.TabletType_RDONLY),
+			},
+			expectedErr: "tablet zone1-0000000100 cannot be promoted: requires 1 acks but only 0 tablets can ack",
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDurabilityPolicy(tt.durability, tt.tablets)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
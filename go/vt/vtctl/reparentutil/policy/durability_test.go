@@ -331,3 +331,64 @@ func TestDurabilityTest(t *testing.T) {
 		})
 	}
 }
+
+func TestPrimaryLossQuorum(t *testing.T) {
+	newTablet := func(cell string, uid uint32, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+		return &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: cell,
+				Uid:  uid,
+			},
+			Type: tabletType,
+		}
+	}
+	primary := newTablet("cell1", 100, topodatapb.TabletType_PRIMARY)
+	replica1 := newTablet("cell1", 101, topodatapb.TabletType_REPLICA)
+	replica2 := newTablet("cell2", 102, topodatapb.TabletType_REPLICA)
+	replica3 := newTablet("cell2", 103, topodatapb.TabletType_REPLICA)
+	rdonly := newTablet("cell1", 104, topodatapb.TabletType_RDONLY)
+	replicas := []*topodatapb.Tablet{replica1, replica2, replica3, rdonly}
+
+	testcases := []struct {
+		durabilityPolicy string
+		replicas         []*topodatapb.Tablet
+		wantVoters       []*topodatapb.Tablet
+		wantQuorum       int
+	}{
+		{
+			durabilityPolicy: DurabilityNone,
+			replicas:         replicas,
+			wantVoters:       replicas,
+			wantQuorum:       3,
+		}, {
+			durabilityPolicy: DurabilityNone,
+			replicas:         nil,
+			wantVoters:       nil,
+			wantQuorum:       0,
+		}, {
+			durabilityPolicy: DurabilitySemiSync,
+			replicas:         replicas,
+			wantVoters:       []*topodatapb.Tablet{replica1, replica2, replica3},
+			wantQuorum:       3,
+		}, {
+			durabilityPolicy: DurabilityCrossCell,
+			replicas:         replicas,
+			wantVoters:       []*topodatapb.Tablet{replica2, replica3},
+			wantQuorum:       2,
+		}, {
+			durabilityPolicy: DurabilitySemiSync,
+			replicas:         []*topodatapb.Tablet{rdonly},
+			wantVoters:       nil,
+			wantQuorum:       0,
+		},
+	}
+	for _, tt := range testcases {
+		t.Run(tt.durabilityPolicy, func(t *testing.T) {
+			durability, err := GetDurabilityPolicy(tt.durabilityPolicy)
+			require.NoError(t, err)
+			voters, quorum := PrimaryLossQuorum(durability, primary, tt.replicas)
+			assert.Equal(t, tt.wantVoters, voters)
+			assert.Equal(t, tt.wantQuorum, quorum)
+		})
+	}
+}
@@ -137,12 +137,12 @@ func SetReplicationSource(ctx context.Context, ts *topo.Server, tmc tmclient.Tab
 		return nil
 	}
 
-	durabilityName, err := ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	durabilityName, durabilityConfig, err := ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 	if err != nil {
 		return err
 	}
 	log.Infof("Getting a new durability policy for %v", durabilityName)
-	durability, err := policy.GetDurabilityPolicy(durabilityName)
+	durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 	if err != nil {
 		return err
 	}
@@ -62,8 +62,8 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 	maxExtraRowsToCompare := subFlags.Int64("max_extra_rows_to_compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation.")
 
 	autoRetry := subFlags.Bool("auto-retry", true, "Should this vdiff automatically retry and continue in case of recoverable errors")
-	checksum := subFlags.Bool("checksum", false, "Use row-level checksums to compare, not yet implemented")
-	samplePct := subFlags.Int64("sample_pct", 100, "How many rows to sample, not yet implemented")
+	checksum := subFlags.Bool("checksum", false, "Compare checksums of primary key ranges, only diffing the rows of the ranges that do not match")
+	samplePct := subFlags.Int64("sample_pct", 100, "Only diff this percentage of the primary key ranges, picked at random")
 	verbose := subFlags.Bool("verbose", false, "Show verbose vdiff output in summaries")
 	wait := subFlags.Bool("wait", false, "When creating or resuming a vdiff, wait for it to finish before exiting")
 	waitUpdateInterval := subFlags.Duration("wait-update-interval", time.Duration(1*time.Minute), "When waiting on a vdiff to finish, check and display the current status this often")
@@ -347,8 +347,11 @@ func (tmc *testTMClient) ReadVReplicationWorkflow(ctx context.Context, tablet *t
 		}
 	}
 	workflowType := binlogdatapb.VReplicationWorkflowType_MoveTables
-	if strings.Contains(req.Workflow, "lookup") {
+	switch {
+	case strings.Contains(req.Workflow, "lookup"):
 		workflowType = binlogdatapb.VReplicationWorkflowType_CreateLookupIndex
+	case strings.Contains(req.Workflow, "replicate"):
+		workflowType = binlogdatapb.VReplicationWorkflowType_Replicate
 	}
 	res := &tabletmanagerdatapb.ReadVReplicationWorkflowResponse{
 		Workflow:     req.Workflow,
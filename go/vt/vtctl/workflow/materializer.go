@@ -74,6 +74,9 @@ type materializer struct {
 	isPartial             bool
 	primaryVindexesDiffer bool
 	workflowType          binlogdatapb.VReplicationWorkflowType
+	// noCopy is set when the target tables already contain the data and
+	// the streams start from the current source position without copying.
+	noCopy bool
 
 	env *vtenv.Environment
 }
@@ -246,8 +249,9 @@ func (mz *materializer) generateRule(ts *vtctldatapb.TableMaterializeSettings, t
 			subExprs = append(subExprs, mappedCol)
 		}
 		var vindexName string
-		if mz.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
-			// For a Migrate, if the TargetKeyspace name is different from the SourceKeyspace name, we need to use the
+		if mz.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate ||
+			mz.workflowType == binlogdatapb.VReplicationWorkflowType_Replicate {
+			// For a Migrate or Replicate, if the TargetKeyspace name is different from the SourceKeyspace name, we need to use the
 			// SourceKeyspace name to determine the vindex since the TargetKeyspace name is not known to the source.
 			// Note: it is expected that the source and target keyspaces have the same vindex name and data type.
 			keyspace := mz.ms.TargetKeyspace
@@ -297,8 +301,9 @@ func (mz *materializer) deploySchema() error {
 	}
 
 	// Check if any table being moved is already non-empty in the target keyspace.
-	// Skip this check for multi-tenant migrations.
-	if !mz.IsMultiTenantMigration() {
+	// Skip this check for multi-tenant migrations and for workflows which do
+	// not copy the data.
+	if !mz.IsMultiTenantMigration() && !mz.noCopy {
 		err := mz.validateEmptyTables()
 		if err != nil {
 			return vterrors.Wrap(err, "failed to validate that all target tables are empty")
@@ -35,11 +35,13 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 
 	"vitess.io/vitess/go/constants/sidecar"
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/ptr"
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -530,8 +532,11 @@ func (s *Server) getWorkflowState(ctx context.Context, targetKeyspace, workflowN
 			state.WritesSwitched = true
 		}
 	}
-	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+	switch ts.workflowType {
+	case binlogdatapb.VReplicationWorkflowType_Migrate:
 		state.WorkflowType = TypeMigrate
+	case binlogdatapb.VReplicationWorkflowType_Replicate:
+		state.WorkflowType = TypeReplicate
 	}
 
 	return ts, state, nil
@@ -1028,11 +1033,15 @@ func validateMaterializeSettings(ms *vtctldatapb.MaterializeSettings) error {
 // It passes the embedded TabletRequest object to the given keyspace's
 // target primary tablets that will be executing the workflow.
 func (s *Server) MoveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (res *vtctldatapb.WorkflowStatusResponse, err error) {
-	return s.moveTablesCreate(ctx, req, binlogdatapb.VReplicationWorkflowType_MoveTables)
+	return s.moveTablesCreate(ctx, req, binlogdatapb.VReplicationWorkflowType_MoveTables, false)
 }
 
+// moveTablesCreate creates the streams for MoveTables and the workflow types
+// built on top of it. When noCopy is set the target tables are expected to
+// already contain the data and the streams start from the current position
+// of the source shard primaries instead of copying the tables.
 func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest,
-	workflowType binlogdatapb.VReplicationWorkflowType,
+	workflowType binlogdatapb.VReplicationWorkflowType, noCopy bool,
 ) (res *vtctldatapb.WorkflowStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "workflow.Server.moveTablesCreate")
 	defer span.Finish()
@@ -1189,6 +1198,7 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		tmc:          s.tmc,
 		ms:           ms,
 		workflowType: workflowType,
+		noCopy:       noCopy,
 		env:          s.env,
 	}
 	err = mz.createWorkflowStreams(&tabletmanagerdatapb.CreateVReplicationWorkflowRequest{
@@ -1206,7 +1216,9 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 	}
 
 	isStandardMoveTables := func() bool {
-		return !mz.IsMultiTenantMigration() && !mz.isPartial
+		// Replicate workflows keep the target tables writable.
+		return !mz.IsMultiTenantMigration() && !mz.isPartial &&
+			workflowType != binlogdatapb.VReplicationWorkflowType_Replicate
 	}
 
 	ts, err := s.buildTrafficSwitcher(ctx, req.GetTargetKeyspace(), req.GetWorkflow())
@@ -1298,6 +1310,12 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		}
 	}
 
+	if noCopy {
+		if err := s.setStreamsToSourcePositions(ctx, ts); err != nil {
+			return nil, err
+		}
+	}
+
 	if req.AutoStart {
 		if err := mz.startStreams(ctx); err != nil {
 			return nil, err
@@ -1572,7 +1590,7 @@ func (s *Server) WorkflowDelete(ctx context.Context, req *vtctldatapb.WorkflowDe
 	}
 	defer workflowUnlock(&err)
 
-	if state.WorkflowType == TypeMigrate {
+	if state.WorkflowType == TypeMigrate || state.WorkflowType == TypeReplicate {
 		_, err := s.finalizeMigrateWorkflow(ctx, ts, "", true, req.GetKeepData(), req.GetKeepRoutingRules(), false)
 		return nil, err
 	}
@@ -1692,6 +1710,7 @@ func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowSt
 	if err != nil {
 		return nil, err
 	}
+	resp.CopyLimits = getCopyLimits(workflow.Options)
 	// The stream key is target keyspace/tablet alias, e.g. 0/test-0000000100.
 	// We sort the keys for intuitive and consistent output.
 	streamKeys := make([]string, 0, len(workflow.ShardStreams))
@@ -1749,9 +1768,10 @@ func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowSt
 
 // GetCopyProgress returns the progress of all tables being copied in the workflow.
 func (s *Server) GetCopyProgress(ctx context.Context, ts *trafficSwitcher, state *State) (*copyProgress, error) {
-	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate ||
+		ts.workflowType == binlogdatapb.VReplicationWorkflowType_Replicate {
 		// The logic below expects the source primaries to be in the same cluster as the target.
-		// For now we don't report progress for Migrate workflows.
+		// For now we don't report progress for Migrate and Replicate workflows.
 		return nil, nil
 	}
 	getTablesQuery := "select distinct table_name from _vt.copy_state cs, _vt.vreplication vr where vr.id = cs.vrepl_id and vr.id = %d"
@@ -2718,6 +2738,14 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 	if !set {
 		maxReplicationLagAllowed = DefaultTimeout
 	}
+	maxVDiffAge, set, err := protoutil.DurationFromProto(req.GetMaxVdiffAge())
+	if err != nil {
+		err = vterrors.Wrapf(err, "unable to parse MaxVdiffAge into a valid duration")
+		return nil, err
+	}
+	if !set {
+		maxVDiffAge = DefaultMaxVDiffAge
+	}
 	direction := TrafficSwitchDirection(req.Direction)
 	switchReplica, switchRdonly, switchPrimary, err = parseTabletTypes(req.TabletTypes)
 	if err != nil {
@@ -2728,8 +2756,8 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 		return nil, err
 	}
 
-	if startState.WorkflowType == TypeMigrate {
-		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid action for Migrate workflow: SwitchTraffic")
+	if startState.WorkflowType == TypeMigrate || startState.WorkflowType == TypeReplicate {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid action for %s workflow: SwitchTraffic", startState.WorkflowType)
 	}
 
 	if ts.IsMultiTenantMigration() {
@@ -2829,6 +2857,9 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 		resp.Summary = fmt.Sprintf("%s dry run results for workflow %s.%s at %v",
 			cmd, req.Keyspace, req.Workflow, time.Now().UTC().Format(time.RFC822))
 		resp.DryRunResults = dryRunResults
+		if switchPrimary && !writesAlreadySwitched {
+			resp.ReadinessReport = s.switchWritesReadiness(ctx, ts, req, maxVDiffAge)
+		}
 	} else {
 		s.Logger().Infof("%s done for workflow %s.%s", cmd, req.Keyspace, req.Workflow)
 		resp.Summary = fmt.Sprintf("%s was successful for workflow %s.%s", cmd, req.Keyspace, req.Workflow)
@@ -3501,7 +3532,119 @@ func (s *Server) MigrateCreate(ctx context.Context, req *vtctldatapb.MigrateCrea
 		AutoStart:                 req.AutoStart,
 		NoRoutingRules:            req.NoRoutingRules,
 	}
-	return s.moveTablesCreate(ctx, moveTablesCreateRequest, binlogdatapb.VReplicationWorkflowType_Migrate)
+	return s.moveTablesCreate(ctx, moveTablesCreateRequest, binlogdatapb.VReplicationWorkflowType_Migrate, false)
+}
+
+// ReplicateCreate creates a workflow which continuously replicates tables
+// from a keyspace in a mounted external cluster. Writes applied by the
+// workflow are tagged with the source position, which lets a Replicate
+// workflow running in the other direction skip them and avoid loops.
+func (s *Server) ReplicateCreate(ctx context.Context, req *vtctldatapb.ReplicateCreateRequest) (*vtctldatapb.WorkflowStatusResponse, error) {
+	if req.MountName == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a mount name is required for a Replicate workflow")
+	}
+	moveTablesCreateRequest := &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:                  req.Workflow,
+		SourceKeyspace:            req.SourceKeyspace,
+		TargetKeyspace:            req.TargetKeyspace,
+		ExternalClusterName:       req.MountName,
+		Cells:                     req.Cells,
+		TabletTypes:               req.TabletTypes,
+		TabletSelectionPreference: req.TabletSelectionPreference,
+		AllTables:                 req.AllTables,
+		IncludeTables:             req.IncludeTables,
+		ExcludeTables:             req.ExcludeTables,
+		SourceTimeZone:            req.SourceTimeZone,
+		OnDdl:                     req.OnDdl,
+		DeferSecondaryKeys:        req.DeferSecondaryKeys,
+		AutoStart:                 req.AutoStart,
+		NoRoutingRules:            true,
+	}
+	return s.moveTablesCreate(ctx, moveTablesCreateRequest, binlogdatapb.VReplicationWorkflowType_Replicate, req.NoCopy)
+}
+
+// ReplicatePromote waits for the streams of a Replicate workflow to reach
+// the current position of their source shard primaries and then stops them,
+// so that the target keyspace can take over the writes. If the streams do not
+// catch up within the timeout they are left running, unless Force is set.
+func (s *Server) ReplicatePromote(ctx context.Context, req *vtctldatapb.ReplicatePromoteRequest) (resp *vtctldatapb.ReplicatePromoteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReplicatePromote")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("force", req.Force)
+
+	timeout, set, err := protoutil.DurationFromProto(req.GetTimeout())
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "unable to parse Timeout into a valid duration")
+	}
+	if !set {
+		timeout = DefaultTimeout
+	}
+
+	ts, state, err := s.getWorkflowState(ctx, req.TargetKeyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if state.WorkflowType != TypeReplicate {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid action for %s workflow: ReplicatePromote", state.WorkflowType)
+	}
+
+	lockName := fmt.Sprintf("%s/%s", ts.TargetKeyspaceName(), ts.WorkflowName())
+	ctx, workflowUnlock, lockErr := s.ts.LockName(ctx, lockName, "ReplicatePromote")
+	if lockErr != nil {
+		return nil, vterrors.Wrapf(lockErr, "failed to lock the %s workflow", lockName)
+	}
+	defer workflowUnlock(&err)
+
+	if err := ts.gatherSourcePositions(ctx); err != nil {
+		return nil, err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	message := "promoted"
+	if err := ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		source := ts.Sources()[target.Sources[uid].Shard]
+		return ts.TabletManagerClient().VReplicationWaitForPos(waitCtx, target.GetPrimary().Tablet, uid, source.Position)
+	}); err != nil {
+		if !req.Force {
+			return nil, vterrors.Wrapf(err, "the %s workflow did not catch up with its source within %v", req.Workflow, timeout)
+		}
+		ts.Logger().Warningf("Promoting the %s workflow before it caught up with its source: %v", req.Workflow, err)
+		message = "promoted before catching up with the source"
+	}
+	if err := ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		_, err := ts.TabletManagerClient().VReplicationExec(ctx, target.GetPrimary().Tablet, binlogplayer.StopVReplication(uid, message))
+		return err
+	}); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to stop the streams for the %s workflow", req.Workflow)
+	}
+	return &vtctldatapb.ReplicatePromoteResponse{
+		Summary: fmt.Sprintf("Successfully promoted the %s workflow in the %s keyspace", req.Workflow, req.TargetKeyspace),
+	}, nil
+}
+
+// setStreamsToSourcePositions points every target stream at the current
+// position of its source shard primary, so that the streams skip the copy
+// phase and only replicate the changes made from now on.
+func (s *Server) setStreamsToSourcePositions(ctx context.Context, ts *trafficSwitcher) error {
+	if err := ts.gatherSourcePositions(ctx); err != nil {
+		return err
+	}
+	return ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		source := ts.Sources()[target.Sources[uid].Shard]
+		pos, err := replication.DecodePosition(source.Position)
+		if err != nil {
+			return err
+		}
+		query := binlogplayer.GenerateUpdatePos(uid, pos, time.Now().Unix(), 0, 0, false)
+		if _, err := ts.TabletManagerClient().VReplicationExec(ctx, target.GetPrimary().Tablet, query); err != nil {
+			return vterrors.Wrapf(err, "failed to set the start position for stream %d on %s", uid,
+				topoproto.TabletAliasString(target.GetPrimary().GetAlias()))
+		}
+		return nil
+	})
 }
 
 // getWorkflowStatus gets the overall status of the workflow by checking the status of all the streams. If all streams are not
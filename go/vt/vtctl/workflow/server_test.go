@@ -2355,6 +2355,7 @@ func TestWorkflowStatus(t *testing.T) {
 	assert.Equal(t, int64(100), stateTable2.RowsCopied)
 	assert.Equal(t, float32(50), stateTable1.RowsPercentage)
 	assert.Equal(t, float32(50), stateTable2.RowsPercentage)
+	assert.Nil(t, res.CopyLimits)
 }
 
 func TestDeleteShard(t *testing.T) {
@@ -2707,6 +2708,71 @@ func TestFinalizeMigrateWorkflow(t *testing.T) {
 	}
 }
 
+func TestReplicatePromote(t *testing.T) {
+	ctx := context.Background()
+
+	sourceKeyspace := &testKeyspace{"source_keyspace", []string{"-"}}
+	targetKeyspace := &testKeyspace{"target_keyspace", []string{"-80", "80-"}}
+	schema := map[string]*tabletmanagerdatapb.SchemaDefinition{
+		"t1": {
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:   "t1",
+					Schema: "CREATE TABLE t1 (id BIGINT, name VARCHAR(64), PRIMARY KEY (id))",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name          string
+		workflow      string
+		expectQueries []string
+		want          string
+		wantErr       string
+	}{
+		{
+			name:     "not a Replicate workflow",
+			workflow: "wf1",
+			wantErr:  "invalid action for MoveTables workflow: ReplicatePromote",
+		},
+		{
+			name:     "stops the streams once caught up",
+			workflow: "replicate1",
+			expectQueries: []string{
+				"update _vt.vreplication set state='Stopped', message='promoted' where id=1",
+			},
+			want: "Successfully promoted the replicate1 workflow in the target_keyspace keyspace",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			te := newTestEnv(t, ctx, defaultCellName, sourceKeyspace, targetKeyspace)
+			defer te.close()
+			te.tmc.schema = schema
+
+			for _, q := range tc.expectQueries {
+				te.tmc.expectVRQuery(200, q, nil)
+				te.tmc.expectVRQuery(210, q, nil)
+			}
+
+			res, err := te.ws.ReplicatePromote(ctx, &vtctldatapb.ReplicatePromoteRequest{
+				Workflow:       tc.workflow,
+				TargetKeyspace: targetKeyspace.KeyspaceName,
+			})
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, res.Summary)
+			assert.Empty(t, te.tmc.vrQueries[200])
+			assert.Empty(t, te.tmc.vrQueries[210])
+		})
+	}
+}
+
 func TestMaterializeAddTables(t *testing.T) {
 	ctx := context.Background()
 
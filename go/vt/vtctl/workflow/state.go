@@ -31,6 +31,7 @@ const (
 	MoveTablesWorkflow = VReplicationWorkflowType(iota)
 	ReshardWorkflow
 	MigrateWorkflow
+	ReplicateWorkflow
 )
 
 // Type is the type of a workflow as a string and maps directly
@@ -42,17 +43,20 @@ const (
 	TypeMoveTables Type = "MoveTables"
 	TypeReshard    Type = "Reshard"
 	TypeMigrate    Type = "Migrate"
+	TypeReplicate  Type = "Replicate"
 )
 
 var TypeStrMap = map[VReplicationWorkflowType]Type{
 	MoveTablesWorkflow: TypeMoveTables,
 	ReshardWorkflow:    TypeReshard,
 	MigrateWorkflow:    TypeMigrate,
+	ReplicateWorkflow:  TypeReplicate,
 }
 var TypeIntMap = map[Type]VReplicationWorkflowType{
 	TypeMoveTables: MoveTablesWorkflow,
 	TypeReshard:    ReshardWorkflow,
 	TypeMigrate:    MigrateWorkflow,
+	TypeReplicate:  ReplicateWorkflow,
 }
 
 // State represents the state of a workflow.
@@ -0,0 +1,345 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// DefaultMaxVDiffAge is the default maximum age of the last VDiff of a
+// workflow in the readiness report of a traffic switch dry run.
+const DefaultMaxVDiffAge = 24 * time.Hour
+
+// The names of the checks in the readiness report.
+const (
+	ReadinessCheckReverseReplication = "ReverseReplication"
+	ReadinessCheckSequences          = "Sequences"
+	ReadinessCheckDeniedTables       = "DeniedTables"
+	ReadinessCheckVDiff              = "VDiff"
+)
+
+// sqlGetLastVDiff returns the state, age in seconds and number of tables with
+// mismatches of the last VDiff of a workflow on a target shard.
+const sqlGetLastVDiff = "select vd.vdiff_uuid as vdiff_uuid, vd.state as state, timestampdiff(second, vd.completed_at, now()) as age, " +
+	"(select count(*) from _vt.vdiff_table as vdt where vdt.vdiff_id = vd.id and vdt.mismatch = 1) as mismatches " +
+	"from _vt.vdiff as vd where vd.keyspace = %a and vd.workflow = %a order by vd.id desc limit 1"
+
+// readinessCheck is a check of the readiness report, which returns the
+// problems it found.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) ([]string, error)
+}
+
+// switchWritesReadiness checks that writes can be switched for the workflow
+// and that the switch can be reversed: the reverse workflow can be created
+// and started, the sequence tables exist, the denied tables are in the state
+// expected before the switch, and a recent VDiff found no mismatches.
+func (s *Server) switchWritesReadiness(ctx context.Context, ts *trafficSwitcher, req *vtctldatapb.WorkflowSwitchTrafficRequest, maxVDiffAge time.Duration) *vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport {
+	checks := []readinessCheck{
+		{ReadinessCheckReverseReplication, func(ctx context.Context) ([]string, error) {
+			return s.checkReverseReplication(ctx, ts, req.EnableReverseReplication), nil
+		}},
+		{ReadinessCheckSequences, func(ctx context.Context) ([]string, error) {
+			return s.checkSequenceTables(ctx, ts)
+		}},
+		{ReadinessCheckDeniedTables, func(ctx context.Context) ([]string, error) {
+			return checkDeniedTables(ts), nil
+		}},
+		{ReadinessCheckVDiff, func(ctx context.Context) ([]string, error) {
+			return s.checkLastVDiff(ctx, ts, maxVDiffAge)
+		}},
+	}
+	report := &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+		Ready: true,
+	}
+	for _, check := range checks {
+		problems, err := check.check(ctx)
+		if err != nil {
+			problems = []string{fmt.Sprintf("failed to run the check: %v", err)}
+		}
+		report.Checks = append(report.Checks, &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+			Name:     check.name,
+			Passed:   len(problems) == 0,
+			Problems: problems,
+		})
+		report.Ready = report.Ready && len(problems) == 0
+	}
+	return report
+}
+
+// checkReverseReplication checks that the reverse streams can be created and
+// started, as a dry run of their creation: the positions they would start from
+// can be obtained from the target primaries, their binlog sources and the
+// statements creating them can be built, and the source primaries, where they
+// would run, exist and are reachable.
+func (s *Server) checkReverseReplication(ctx context.Context, ts *trafficSwitcher, enabled bool) []string {
+	if !enabled {
+		return []string{"reverse replication is not enabled, so traffic cannot be reversed after the switch"}
+	}
+	var (
+		mu       sync.Mutex
+		problems []string
+	)
+	addProblem := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	positions := make(map[string]string)
+	_ = ts.ForAllTargets(func(target *MigrationTarget) error {
+		shard, primary := target.GetShard().ShardName(), target.GetPrimary()
+		if primary == nil {
+			addProblem("target shard %s has no primary tablet", shard)
+			return nil
+		}
+		pos, err := s.tmc.PrimaryPosition(ctx, primary.Tablet)
+		if err == nil {
+			_, err = replication.DecodePosition(pos)
+		}
+		if err != nil {
+			addProblem("failed to get the position of the target primary %s: %v", primary.AliasString(), err)
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		positions[shard] = pos
+		return nil
+	})
+	_ = ts.ForAllSources(func(source *MigrationSource) error {
+		if primary := source.GetPrimary(); primary == nil {
+			addProblem("source shard %s has no primary tablet", source.GetShard().ShardName())
+		} else if _, err := s.tmc.PrimaryPosition(ctx, primary.Tablet); err != nil {
+			addProblem("failed to reach the source primary %s: %v", primary.AliasString(), err)
+		}
+		return nil
+	})
+	_ = ts.ForAllUIDs(func(target *MigrationTarget, uid int32) error {
+		shard := target.GetShard().ShardName()
+		source := ts.Sources()[target.Sources[uid].Shard]
+		pos, ok := positions[shard]
+		if source == nil || source.GetPrimary() == nil || !ok {
+			return nil
+		}
+		bls, err := ts.reverseBinlogSource(ctx, target, uid)
+		if err != nil {
+			addProblem("cannot create the reverse stream of stream %d on target shard %s: %v", uid, shard, err)
+			return nil
+		}
+		query := binlogplayer.CreateVReplicationState(ts.ReverseWorkflowName(), bls, pos,
+			binlogdatapb.VReplicationWorkflowState_Stopped, source.GetPrimary().DbName(), ts.workflowType, ts.workflowSubType)
+		if _, err := s.env.Parser().Parse(query); err != nil {
+			addProblem("cannot create the reverse stream of stream %d on target shard %s: %v", uid, shard, err)
+		}
+		for _, rule := range bls.Filter.Rules {
+			if !strings.HasPrefix(rule.Filter, "select ") {
+				continue
+			}
+			if _, err := s.env.Parser().Parse(rule.Filter); err != nil {
+				addProblem("the reverse stream of stream %d on target shard %s cannot filter table %s: %v", uid, shard, rule.Match, err)
+			}
+		}
+		return nil
+	})
+	slices.Sort(problems)
+	return problems
+}
+
+// checkSequenceTables checks that the backing tables of the sequences used by
+// the tables of the workflow exist, or can be created in the global keyspace
+// of the workflow.
+func (s *Server) checkSequenceTables(ctx context.Context, ts *trafficSwitcher) ([]string, error) {
+	if ts.MigrationType() != binlogdatapb.MigrationType_TABLES {
+		return nil, nil
+	}
+	vschema, err := s.ts.GetVSchema(ctx, ts.targetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if vschema == nil || len(vschema.Tables) == 0 {
+		return nil, nil
+	}
+	sequences, _, err := ts.findSequenceUsageInKeyspace(vschema.Keyspace)
+	if err != nil || len(sequences) == 0 {
+		return nil, err
+	}
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vschemas := make(map[string]*vschemapb.Keyspace, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		kvs, err := s.ts.GetVSchema(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		vschemas[keyspace] = kvs.Keyspace
+	}
+	hasSequence := func(kvs *vschemapb.Keyspace, table string) bool {
+		if kvs == nil || kvs.Sharded {
+			return false
+		}
+		for name, def := range kvs.Tables {
+			// The table name can be escaped in the vschema definition.
+			if unescaped, err := sqlescape.UnescapeID(name); err == nil && unescaped == table {
+				return def.GetType() == vindexes.TypeSequence
+			}
+		}
+		return false
+	}
+	var problems []string
+	for _, sm := range sequences {
+		found := false
+		if sm.backingTableKeyspace != "" {
+			found = hasSequence(vschemas[sm.backingTableKeyspace], sm.backingTableName)
+		} else {
+			for _, kvs := range vschemas {
+				if found = hasSequence(kvs, sm.backingTableName); found {
+					break
+				}
+			}
+		}
+		switch {
+		case found:
+		case sm.backingTableKeyspace == "" && ts.options.GetGlobalKeyspace() != "":
+			// It will be created in the global keyspace when the sequences
+			// are initialized.
+		default:
+			problems = append(problems, fmt.Sprintf("the sequence table %s used by table %s does not exist", sm.backingTableName, sm.usingTableName))
+		}
+	}
+	slices.Sort(problems)
+	return problems, nil
+}
+
+// checkDeniedTables checks that writes are still served by the source shards
+// and not by the target shards. For a MoveTables workflow the tables of the
+// workflow must be denied on the target shards and not on the source shards.
+// For a Reshard workflow the source shards must be serving and the target
+// shards not.
+func checkDeniedTables(ts *trafficSwitcher) []string {
+	var problems []string
+	switch ts.MigrationType() {
+	case binlogdatapb.MigrationType_TABLES:
+		// Multi-tenant and partial migrations don't use denied tables.
+		if ts.IsMultiTenantMigration() || ts.isPartialMigration {
+			return nil
+		}
+		for _, source := range ts.Sources() {
+			tc := source.GetShard().GetTabletControl(topodatapb.TabletType_PRIMARY)
+			if denied := slices.DeleteFunc(slices.Clone(ts.Tables()), func(table string) bool {
+				return tc == nil || !slices.Contains(tc.DeniedTables, table)
+			}); len(denied) > 0 {
+				problems = append(problems, fmt.Sprintf("tables %s are denied on source shard %s", strings.Join(denied, ","), source.GetShard().ShardName()))
+			}
+		}
+		for _, target := range ts.Targets() {
+			tc := target.GetShard().GetTabletControl(topodatapb.TabletType_PRIMARY)
+			if allowed := slices.DeleteFunc(slices.Clone(ts.Tables()), func(table string) bool {
+				return tc != nil && slices.Contains(tc.DeniedTables, table)
+			}); len(allowed) > 0 {
+				problems = append(problems, fmt.Sprintf("tables %s are not denied on target shard %s", strings.Join(allowed, ","), target.GetShard().ShardName()))
+			}
+		}
+	case binlogdatapb.MigrationType_SHARDS:
+		for _, source := range ts.Sources() {
+			if !source.GetShard().IsPrimaryServing {
+				problems = append(problems, fmt.Sprintf("source shard %s is not serving", source.GetShard().ShardName()))
+			}
+		}
+		for _, target := range ts.Targets() {
+			if target.GetShard().IsPrimaryServing {
+				problems = append(problems, fmt.Sprintf("target shard %s is already serving", target.GetShard().ShardName()))
+			}
+		}
+	}
+	slices.Sort(problems)
+	return problems
+}
+
+// checkLastVDiff checks that the last VDiff of the workflow on each target
+// shard completed no longer than maxAge ago and found no mismatches.
+func (s *Server) checkLastVDiff(ctx context.Context, ts *trafficSwitcher, maxAge time.Duration) ([]string, error) {
+	var (
+		mu       sync.Mutex
+		problems []string
+	)
+	err := ts.ForAllTargets(func(target *MigrationTarget) error {
+		query, err := sqlparser.ParseAndBind(sqlGetLastVDiff,
+			sqltypes.StringBindVariable(ts.targetKeyspace),
+			sqltypes.StringBindVariable(ts.workflow),
+		)
+		if err != nil {
+			return err
+		}
+		p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, target.GetPrimary().Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return err
+		}
+		var problem string
+		qr := sqltypes.Proto3ToResult(p3qr)
+		shard := target.GetShard().ShardName()
+		if len(qr.Rows) == 0 {
+			problem = fmt.Sprintf("no VDiff has been run on target shard %s", shard)
+		} else {
+			row := qr.Named().Row()
+			uuid, state := row.AsString("vdiff_uuid", ""), row.AsString("state", "")
+			age := time.Duration(row.AsInt64("age", 0)) * time.Second
+			switch {
+			case state != string(vdiff.CompletedState):
+				problem = fmt.Sprintf("the last VDiff %s on target shard %s is %s", uuid, shard, state)
+			case row.AsInt64("mismatches", 0) > 0:
+				problem = fmt.Sprintf("the last VDiff %s on target shard %s found mismatches in %d table(s)", uuid, shard, row.AsInt64("mismatches", 0))
+			case age > maxAge:
+				problem = fmt.Sprintf("the last VDiff %s on target shard %s completed %v ago, more than %v", uuid, shard, age, maxAge)
+			}
+		}
+		if problem != "" {
+			mu.Lock()
+			defer mu.Unlock()
+			problems = append(problems, problem)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(problems)
+	return problems, nil
+}
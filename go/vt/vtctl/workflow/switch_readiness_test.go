@@ -0,0 +1,293 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestSwitchTrafficReadinessReport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	workflowName := "wf1"
+	tableName := "t1"
+	sourceKeyspaceName := "sourceks"
+	targetKeyspaceName := "targetks"
+	vrID := 1
+	schema := map[string]*tabletmanagerdatapb.SchemaDefinition{
+		tableName: {
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:   tableName,
+					Schema: fmt.Sprintf("CREATE TABLE %s (id BIGINT, name VARCHAR(64), PRIMARY KEY (id))", tableName),
+				},
+			},
+		},
+	}
+	copyTableQR := &queryResult{
+		query: fmt.Sprintf("select vrepl_id, table_name, lastpk from _vt.copy_state where vrepl_id in (%d) and id in (select max(id) from _vt.copy_state where vrepl_id in (%d) group by vrepl_id, table_name)",
+			vrID, vrID),
+		result: &querypb.QueryResult{},
+	}
+	journalQR := &queryResult{
+		query:  "/select val from _vt.resharding_journal.*",
+		result: &querypb.QueryResult{},
+	}
+	lockTableQR := &queryResult{
+		query:  fmt.Sprintf("LOCK TABLES `%s` READ", tableName),
+		result: &querypb.QueryResult{},
+	}
+	lastVDiffQR := func(rows ...string) *queryResult {
+		query, err := sqlparser.ParseAndBind(sqlGetLastVDiff,
+			sqltypes.StringBindVariable(targetKeyspaceName),
+			sqltypes.StringBindVariable(workflowName),
+		)
+		require.NoError(t, err)
+		return &queryResult{
+			query: query,
+			result: sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+				"vdiff_uuid|state|age|mismatches", "varchar|varbinary|int64|int64"), rows...)),
+		}
+	}
+
+	testcases := []struct {
+		name                      string
+		denyTargetTables          bool
+		disableReverseReplication bool
+		targetPosition            string
+		lastVDiff                 []string
+		want                      *vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport
+	}{
+		{
+			name:             "ready",
+			denyTargetTables: true,
+			lastVDiff:        []string{"uuid1|completed|600|0"},
+			want: &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+				Ready: true,
+				Checks: []*vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+					{Name: ReadinessCheckReverseReplication, Passed: true},
+					{Name: ReadinessCheckSequences, Passed: true},
+					{Name: ReadinessCheckDeniedTables, Passed: true},
+					{Name: ReadinessCheckVDiff, Passed: true},
+				},
+			},
+		},
+		{
+			name:                      "not ready",
+			disableReverseReplication: true,
+			lastVDiff:                 []string{"uuid1|completed|600|1"},
+			want: &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+				Checks: []*vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+					{Name: ReadinessCheckReverseReplication, Problems: []string{
+						"reverse replication is not enabled, so traffic cannot be reversed after the switch",
+					}},
+					{Name: ReadinessCheckSequences, Passed: true},
+					{Name: ReadinessCheckDeniedTables, Problems: []string{
+						"tables t1 are not denied on target shard 0",
+					}},
+					{Name: ReadinessCheckVDiff, Problems: []string{
+						"the last VDiff uuid1 on target shard 0 found mismatches in 1 table(s)",
+					}},
+				},
+			},
+		},
+		{
+			name:             "reverse streams cannot start",
+			denyTargetTables: true,
+			targetPosition:   "MySQL56/invalid",
+			lastVDiff:        []string{"uuid1|completed|600|0"},
+			want: &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+				Checks: []*vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+					{Name: ReadinessCheckReverseReplication, Problems: []string{
+						"failed to get the position of the target primary cell-0000000200: Code: INTERNAL\ninvalid MySQL 5.6 GTID set (\"invalid\"): expected uuid:interval\n",
+					}},
+					{Name: ReadinessCheckSequences, Passed: true},
+					{Name: ReadinessCheckDeniedTables, Passed: true},
+					{Name: ReadinessCheckVDiff, Passed: true},
+				},
+			},
+		},
+		{
+			name:             "old vdiff",
+			denyTargetTables: true,
+			lastVDiff:        []string{"uuid1|completed|90000|0"},
+			want: &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+				Checks: []*vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+					{Name: ReadinessCheckReverseReplication, Passed: true},
+					{Name: ReadinessCheckSequences, Passed: true},
+					{Name: ReadinessCheckDeniedTables, Passed: true},
+					{Name: ReadinessCheckVDiff, Problems: []string{
+						"the last VDiff uuid1 on target shard 0 completed 25h0m0s ago, more than 24h0m0s",
+					}},
+				},
+			},
+		},
+		{
+			name:             "no vdiff",
+			denyTargetTables: true,
+			want: &vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessReport{
+				Checks: []*vtctldatapb.WorkflowSwitchTrafficResponse_ReadinessCheck{
+					{Name: ReadinessCheckReverseReplication, Passed: true},
+					{Name: ReadinessCheckSequences, Passed: true},
+					{Name: ReadinessCheckDeniedTables, Passed: true},
+					{Name: ReadinessCheckVDiff, Problems: []string{
+						"no VDiff has been run on target shard 0",
+					}},
+				},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sourceKeyspace := &testKeyspace{
+				KeyspaceName: sourceKeyspaceName,
+				ShardNames:   []string{"0"},
+			}
+			targetKeyspace := &testKeyspace{
+				KeyspaceName: targetKeyspaceName,
+				ShardNames:   []string{"0"},
+			}
+			env := newTestEnv(t, ctx, defaultCellName, sourceKeyspace, targetKeyspace)
+			defer env.close()
+			env.tmc.schema = schema
+			if tc.denyTargetTables {
+				lctx, unlock, err := env.ts.LockKeyspace(ctx, targetKeyspaceName, "test")
+				require.NoError(t, err)
+				_, err = env.ts.UpdateShardFields(lctx, targetKeyspaceName, "0", func(si *topo.ShardInfo) error {
+					return si.UpdateDeniedTables(lctx, topodatapb.TabletType_PRIMARY, nil, false, []string{tableName})
+				})
+				unlock(&err)
+				require.NoError(t, err)
+			}
+			if tc.targetPosition != "" {
+				env.tmc.primaryPositions[startingTargetTabletUID] = tc.targetPosition
+			}
+			env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspaceName, lastVDiffQR(tc.lastVDiff...))
+
+			ts, _, err := env.ws.getWorkflowState(ctx, targetKeyspaceName, workflowName)
+			require.NoError(t, err)
+			got := env.ws.switchWritesReadiness(ctx, ts, &vtctldatapb.WorkflowSwitchTrafficRequest{
+				EnableReverseReplication: !tc.disableReverseReplication,
+			}, DefaultMaxVDiffAge)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	// The report is part of the results of a dry run that switches writes.
+	t.Run("dry run", func(t *testing.T) {
+		env := newTestEnv(t, ctx, defaultCellName, &testKeyspace{
+			KeyspaceName: sourceKeyspaceName,
+			ShardNames:   []string{"0"},
+		}, &testKeyspace{
+			KeyspaceName: targetKeyspaceName,
+			ShardNames:   []string{"0"},
+		})
+		defer env.close()
+		env.tmc.schema = schema
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspaceName, copyTableQR)
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspaceName, journalQR)
+		// The tables are locked twice.
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspaceName, lockTableQR)
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(sourceKeyspaceName, lockTableQR)
+		env.tmc.expectVRQueryResultOnKeyspaceTablets(targetKeyspaceName, lastVDiffQR("uuid1|completed|600|0"))
+
+		got, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+			Keyspace:    targetKeyspaceName,
+			Workflow:    workflowName,
+			Direction:   int32(DirectionForward),
+			TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_PRIMARY},
+			DryRun:      true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, got.ReadinessReport)
+		require.False(t, got.ReadinessReport.Ready)
+		require.Len(t, got.ReadinessReport.Checks, 4)
+		require.Equal(t, ReadinessCheckReverseReplication, got.ReadinessReport.Checks[0].Name)
+		require.False(t, got.ReadinessReport.Checks[0].Passed)
+		require.True(t, got.ReadinessReport.Checks[3].Passed)
+	})
+}
+
+// TestLastVDiffQuery checks the query of the last VDiff against the schema of
+// the vdiff sidecar tables: its columns must exist, and the tables of a VDiff
+// must be joined on the id of the VDiff, which vdiff_table.vdiff_id holds.
+func TestLastVDiffQuery(t *testing.T) {
+	parser := sqlparser.NewTestParser()
+	columns := make(map[string][]string)
+	for _, table := range []string{"vdiff", "vdiff_table"} {
+		ddl, err := os.ReadFile(fmt.Sprintf("../../sidecardb/schema/vdiff/%s.sql", table))
+		require.NoError(t, err)
+		stmt, err := parser.Parse(string(ddl))
+		require.NoError(t, err)
+		create, ok := stmt.(*sqlparser.CreateTable)
+		require.True(t, ok)
+		for _, col := range create.TableSpec.Columns {
+			columns[table] = append(columns[table], col.Name.String())
+		}
+	}
+
+	query, err := sqlparser.ParseAndBind(sqlGetLastVDiff,
+		sqltypes.StringBindVariable("ks"),
+		sqltypes.StringBindVariable("wf"),
+	)
+	require.NoError(t, err)
+	stmt, err := parser.Parse(query)
+	require.NoError(t, err)
+	tables := make(map[string]string)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if ate, ok := node.(*sqlparser.AliasedTableExpr); ok {
+			tableName, ok := ate.Expr.(sqlparser.TableName)
+			require.True(t, ok)
+			tables[ate.As.String()] = tableName.Name.String()
+		}
+		return true, nil
+	}, stmt)
+	var joins []string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			table, ok := tables[node.Qualifier.Name.String()]
+			require.True(t, ok, "column %s is not qualified by a table of the query", sqlparser.String(node))
+			require.Contains(t, columns[table], node.Name.String(), "column %s does not exist in table %s", node.Name.String(), table)
+		case *sqlparser.ComparisonExpr:
+			left, lok := node.Left.(*sqlparser.ColName)
+			right, rok := node.Right.(*sqlparser.ColName)
+			if lok && rok {
+				joins = append(joins, fmt.Sprintf("%s.%s = %s.%s", tables[left.Qualifier.Name.String()], left.Name.String(),
+					tables[right.Qualifier.Name.String()], right.Name.String()))
+			}
+		}
+		return true, nil
+	}, stmt)
+	require.Equal(t, []string{"vdiff_table.vdiff_id = vdiff.id"}, joins)
+}
@@ -628,6 +628,22 @@ func encodeString(in string) string {
 	return sqltypes.EncodeStringSQL(in)
 }
 
+// getCopyLimits returns the copy phase rate limits and schedule that are
+// configured for the workflow, or nil if there are none.
+func getCopyLimits(options *vtctldatapb.WorkflowOptions) *vtctldatapb.WorkflowStatusResponse_CopyLimits {
+	config := options.GetConfig()
+	limits := &vtctldatapb.WorkflowStatusResponse_CopyLimits{
+		Schedule: config["vreplication-copy-phase-schedule"],
+	}
+	// The values were validated when they were set on the workflow.
+	limits.MaxRowsPerSecond, _ = strconv.ParseInt(config["vreplication-copy-phase-max-rows-per-second"], 10, 64)
+	limits.MaxBytesPerSecond, _ = strconv.ParseInt(config["vreplication-copy-phase-max-bytes-per-second"], 10, 64)
+	if limits.MaxRowsPerSecond <= 0 && limits.MaxBytesPerSecond <= 0 && limits.Schedule == "" {
+		return nil
+	}
+	return limits
+}
+
 func getRenameFileName(tableName string) string {
 	return fmt.Sprintf(renameTableTemplate, tableName)
 }
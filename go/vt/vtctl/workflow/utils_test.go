@@ -356,3 +356,25 @@ func TestLegacyBuildTargets(t *testing.T) {
 	assert.Equal(t, t2.Sources[2].Filter.Rules[0].Match, "t3")
 	assert.Equal(t, t2.Sources[2].Filter.Rules[1].Match, "t4")
 }
+
+func TestGetCopyLimits(t *testing.T) {
+	require.Nil(t, getCopyLimits(nil))
+	require.Nil(t, getCopyLimits(&vtctldata.WorkflowOptions{
+		Config: map[string]string{"vreplication-parallel-insert-workers": "4"},
+	}))
+	require.Nil(t, getCopyLimits(&vtctldata.WorkflowOptions{
+		Config: map[string]string{"vreplication-copy-phase-max-rows-per-second": "0"},
+	}))
+	limits := getCopyLimits(&vtctldata.WorkflowOptions{
+		Config: map[string]string{
+			"vreplication-copy-phase-max-rows-per-second":  "1000",
+			"vreplication-copy-phase-max-bytes-per-second": "1048576",
+			"vreplication-copy-phase-schedule":             "* 1-5 * * *",
+		},
+	})
+	require.Equal(t, &vtctldata.WorkflowStatusResponse_CopyLimits{
+		MaxRowsPerSecond:  1000,
+		MaxBytesPerSecond: 1048576,
+		Schedule:          "* 1-5 * * *",
+	}, limits)
+}
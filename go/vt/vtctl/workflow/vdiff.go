@@ -362,6 +362,10 @@ func (s *Server) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRe
 			UpdateTableStats:      req.UpdateTableStats,
 			MaxDiffSeconds:        req.MaxDiffDuration.Seconds,
 			AutoStart:             &autoStart,
+			Checksum:              req.Checksum,
+			SamplePct:             req.SamplePct,
+			ChunkSize:             req.ChunkSize,
+			ChecksumAlgorithm:     req.ChecksumAlgorithm,
 		},
 		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{
 			OnlyPks:                 req.OnlyPKs,
@@ -32,6 +32,9 @@ const (
 	// TxEngineClosed for transaction engine closed error
 	TxEngineClosed = "tx engine can't accept new connections in state %v"
 
+	// PrimaryDraining for new transactions on a primary that drains before a planned reparent
+	PrimaryDraining = "primary is draining, there may be a planned reparent in progress"
+
 	// PrimaryVindexNotSet is the error message to be used when there is no primary vindex found on a table
 	PrimaryVindexNotSet = "table '%s' does not have a primary vindex"
 
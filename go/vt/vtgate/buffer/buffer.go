@@ -70,6 +70,7 @@ const (
 	ClusterEventReshardingInProgress = "current keyspace is being resharded"
 	ClusterEventReparentInProgress   = "primary is not serving, there may be a reparent operation in progress"
 	ClusterEventMoveTables           = "disallowed due to rule"
+	ClusterEventPrimaryDraining      = vterrors.PrimaryDraining
 )
 
 var ClusterEvents []string
@@ -79,6 +80,7 @@ func init() {
 		ClusterEventReshardingInProgress,
 		ClusterEventReparentInProgress,
 		ClusterEventMoveTables,
+		ClusterEventPrimaryDraining,
 	}
 }
 
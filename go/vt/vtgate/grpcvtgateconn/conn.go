@@ -50,6 +50,7 @@ func init() {
 
 	for _, cmd := range []string{
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtcombo",
 		"vtctl",
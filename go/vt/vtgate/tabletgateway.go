@@ -270,6 +270,17 @@ func (gw *TabletGateway) DebugBalancerHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// opensStatefulConnection returns true if the named QueryService method
+// starts a new transaction or reserved connection on the tablet.
+func opensStatefulConnection(name string) bool {
+	switch name {
+	case "Begin", "BeginExecute", "BeginStreamExecute",
+		"ReserveBeginExecute", "ReserveBeginStreamExecute", "ReserveExecute", "ReserveStreamExecute":
+		return true
+	}
+	return false
+}
+
 // withRetry gets available connections and executes the action. If there are retryable errors,
 // it retries retryCount times before failing. It does not retry if the connection is in
 // the middle of a transaction. While returning the error check if it maybe a result of
@@ -279,7 +290,7 @@ func (gw *TabletGateway) DebugBalancerHandler(w http.ResponseWriter, r *http.Req
 // withRetry also adds shard information to errors returned from the inner QueryService, so
 // withShardError should not be combined with withRetry.
 func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target, _ queryservice.QueryService,
-	_ string, inTransaction bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
+	name string, inTransaction bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
 
 	// for transactions, we connect to a specific tablet instead of letting gateway choose one
 	if inTransaction && target.TabletType != topodatapb.TabletType_PRIMARY {
@@ -303,6 +314,7 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 	}
 
 	bufferedOnce := false
+	skippedDraining := false
 	for i := 0; i < gw.retryCount+1; i++ {
 		// Check if we should buffer PRIMARY queries which failed due to an ongoing failover.
 		// Note: We only buffer once and only "!inTransaction" queries i.e.
@@ -396,6 +408,15 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 			break
 		}
 
+		// A primary that drains before a planned reparent rejects new transactions.
+		// Buffer them once until the reparent is over instead of sending them there.
+		if gw.buffer != nil && !bufferedOnce && !skippedDraining && !inTransaction &&
+			target.TabletType == topodatapb.TabletType_PRIMARY && th.Stats.GetDraining() && opensStatefulConnection(name) {
+			skippedDraining = true
+			err = vterrors.New(vtrpcpb.Code_CLUSTER_EVENT, buffer.ClusterEventPrimaryDraining)
+			continue
+		}
+
 		tabletLastUsed = th.Tablet
 		// execute
 		if th.Conn == nil {
@@ -649,6 +649,8 @@ func (vs *vstream) streamFromTablet(ctx context.Context, sgtid *binlogdatapb.Sha
 				TablesToCopy: vs.flags.GetTablesToCopy(),
 			}
 		}
+		options.IncludeSchemaVersions = vs.flags.GetIncludeSchemaVersions()
+		options.SchemaSnapshot = vs.flags.GetSchemaSnapshot()
 
 		// Safe to access sgtid.Gtid here (because it can't change until streaming begins).
 		req := &binlogdatapb.VStreamRequest{
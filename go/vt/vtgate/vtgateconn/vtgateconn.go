@@ -43,6 +43,7 @@ func registerFlags(fs *pflag.FlagSet) {
 func init() {
 	servenv.OnParseFor("vttablet", registerFlags)
 	servenv.OnParseFor("vtclient", registerFlags)
+	servenv.OnParseFor("vtcdc", registerFlags)
 }
 
 // GetVTGateProtocol returns the protocol used to connect to vtgate as provided in the flag.
@@ -209,6 +209,78 @@ var (
 			Dynamic:  true,
 		},
 	)
+
+	enableUnreachablePrimaryRecovery = viperutil.Configure(
+		"enable-unreachable-primary-recovery",
+		viperutil.Options[bool]{
+			FlagName: "enable-unreachable-primary-recovery",
+			Default:  false,
+			Dynamic:  true,
+		},
+	)
+
+	allowUnfencedPrimaryRecovery = viperutil.Configure(
+		"allow-unfenced-primary-recovery",
+		viperutil.Options[bool]{
+			FlagName: "allow-unfenced-primary-recovery",
+			Default:  false,
+			Dynamic:  true,
+		},
+	)
+
+	enableReplicaRebuild = viperutil.Configure(
+		"enable-replica-rebuild",
+		viperutil.Options[bool]{
+			FlagName: "enable-replica-rebuild",
+			Default:  false,
+			Dynamic:  true,
+		},
+	)
+
+	replicaRebuildMaxConcurrent = viperutil.Configure(
+		"replica-rebuild-max-concurrent",
+		viperutil.Options[int]{
+			FlagName: "replica-rebuild-max-concurrent",
+			Default:  1,
+			Dynamic:  true,
+		},
+	)
+
+	replicaRebuildMinInterval = viperutil.Configure(
+		"replica-rebuild-min-interval",
+		viperutil.Options[time.Duration]{
+			FlagName: "replica-rebuild-min-interval",
+			Default:  1 * time.Hour,
+			Dynamic:  true,
+		},
+	)
+
+	replicaRebuildTimeout = viperutil.Configure(
+		"replica-rebuild-timeout",
+		viperutil.Options[time.Duration]{
+			FlagName: "replica-rebuild-timeout",
+			Default:  6 * time.Hour,
+			Dynamic:  true,
+		},
+	)
+
+	recoveryWebhookURL = viperutil.Configure(
+		"recovery-webhook-url",
+		viperutil.Options[string]{
+			FlagName: "recovery-webhook-url",
+			Default:  "",
+			Dynamic:  true,
+		},
+	)
+
+	recoveryWebhookTimeout = viperutil.Configure(
+		"recovery-webhook-timeout",
+		viperutil.Options[time.Duration]{
+			FlagName: "recovery-webhook-timeout",
+			Default:  10 * time.Second,
+			Dynamic:  true,
+		},
+	)
 )
 
 func init() {
@@ -236,6 +308,14 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.Bool("allow-emergency-reparent", ersEnabled.Default(), "Whether VTOrc should be allowed to run emergency reparent operation when it detects a dead primary")
 	fs.Bool("change-tablets-with-errant-gtid-to-drained", convertTabletsWithErrantGTIDs.Default(), "Whether VTOrc should be changing the type of tablets with errant GTIDs to DRAINED")
 	fs.Bool("enable-primary-disk-stalled-recovery", enablePrimaryDiskStalledRecovery.Default(), "Whether VTOrc should detect a stalled disk on the primary and failover")
+	fs.Bool("enable-unreachable-primary-recovery", enableUnreachablePrimaryRecovery.Default(), "Whether VTOrc should failover an unreachable primary once a quorum of its replicas, as defined by the durability policy, has lost its connection to it")
+	fs.Bool("allow-unfenced-primary-recovery", allowUnfencedPrimaryRecovery.Default(), "Whether VTOrc should failover an unreachable primary even if it failed to fence it, in which case the old primary may keep accepting writes until it learns that it was replaced")
+	fs.Bool("enable-replica-rebuild", enableReplicaRebuild.Default(), "Whether VTOrc should rebuild replicas with an unrecoverable replication error or errant GTIDs by restoring them from a backup")
+	fs.Int("replica-rebuild-max-concurrent", replicaRebuildMaxConcurrent.Default(), "Maximum number of replicas VTOrc rebuilds at the same time in a shard")
+	fs.Duration("replica-rebuild-min-interval", replicaRebuildMinInterval.Default(), "Minimum time between the start of two replica rebuilds in the same shard")
+	fs.Duration("replica-rebuild-timeout", replicaRebuildTimeout.Default(), "Maximum duration of a replica rebuild, including restoring the backup and catching up on replication")
+	fs.String("recovery-webhook-url", recoveryWebhookURL.Default(), "URL to which VTOrc posts every finished recovery, along with its steps, as a JSON document. Recoveries aren't exported if empty")
+	fs.Duration("recovery-webhook-timeout", recoveryWebhookTimeout.Default(), "Timeout of the requests to the recovery webhook")
 
 	viperutil.BindFlags(fs,
 		instancePollTime,
@@ -257,6 +337,14 @@ func registerFlags(fs *pflag.FlagSet) {
 		ersEnabled,
 		convertTabletsWithErrantGTIDs,
 		enablePrimaryDiskStalledRecovery,
+		enableUnreachablePrimaryRecovery,
+		allowUnfencedPrimaryRecovery,
+		enableReplicaRebuild,
+		replicaRebuildMaxConcurrent,
+		replicaRebuildMinInterval,
+		replicaRebuildTimeout,
+		recoveryWebhookURL,
+		recoveryWebhookTimeout,
 	)
 }
 
@@ -395,6 +483,76 @@ func GetStalledDiskPrimaryRecovery() bool {
 	return enablePrimaryDiskStalledRecovery.Get()
 }
 
+// GetUnreachablePrimaryRecovery reports whether VTOrc is allowed to failover an unreachable primary.
+func GetUnreachablePrimaryRecovery() bool {
+	return enableUnreachablePrimaryRecovery.Get()
+}
+
+// SetUnreachablePrimaryRecovery sets the value for the enableUnreachablePrimaryRecovery variable. This should only be used from tests.
+func SetUnreachablePrimaryRecovery(val bool) {
+	enableUnreachablePrimaryRecovery.Set(val)
+}
+
+// GetAllowUnfencedPrimaryRecovery reports whether VTOrc is allowed to failover an unreachable primary it failed to fence.
+func GetAllowUnfencedPrimaryRecovery() bool {
+	return allowUnfencedPrimaryRecovery.Get()
+}
+
+// SetAllowUnfencedPrimaryRecovery sets the value for the allowUnfencedPrimaryRecovery variable. This should only be used from tests.
+func SetAllowUnfencedPrimaryRecovery(val bool) {
+	allowUnfencedPrimaryRecovery.Set(val)
+}
+
+// GetReplicaRebuild reports whether VTOrc is allowed to rebuild replicas from a backup.
+func GetReplicaRebuild() bool {
+	return enableReplicaRebuild.Get()
+}
+
+// SetReplicaRebuild sets the value for the enableReplicaRebuild variable. This should only be used from tests.
+func SetReplicaRebuild(val bool) {
+	enableReplicaRebuild.Set(val)
+}
+
+// GetReplicaRebuildMaxConcurrent is a getter function.
+func GetReplicaRebuildMaxConcurrent() int {
+	return replicaRebuildMaxConcurrent.Get()
+}
+
+// SetReplicaRebuildMaxConcurrent is a setter function.
+func SetReplicaRebuildMaxConcurrent(v int) {
+	replicaRebuildMaxConcurrent.Set(v)
+}
+
+// GetReplicaRebuildMinInterval is a getter function.
+func GetReplicaRebuildMinInterval() time.Duration {
+	return replicaRebuildMinInterval.Get()
+}
+
+// SetReplicaRebuildMinInterval is a setter function.
+func SetReplicaRebuildMinInterval(v time.Duration) {
+	replicaRebuildMinInterval.Set(v)
+}
+
+// GetReplicaRebuildTimeout is a getter function.
+func GetReplicaRebuildTimeout() time.Duration {
+	return replicaRebuildTimeout.Get()
+}
+
+// GetRecoveryWebhookURL is a getter function.
+func GetRecoveryWebhookURL() string {
+	return recoveryWebhookURL.Get()
+}
+
+// SetRecoveryWebhookURL is a setter function.
+func SetRecoveryWebhookURL(v string) {
+	recoveryWebhookURL.Set(v)
+}
+
+// GetRecoveryWebhookTimeout is a getter function.
+func GetRecoveryWebhookTimeout() time.Duration {
+	return recoveryWebhookTimeout.Get()
+}
+
 // MarkConfigurationLoaded is called once configuration has first been loaded.
 // Listeners on ConfigurationLoaded will get a notification
 func MarkConfigurationLoaded() {
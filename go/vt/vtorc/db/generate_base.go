@@ -32,6 +32,8 @@ var TableNames = []string{
 	"vitess_tablet",
 	"vitess_keyspace",
 	"vitess_shard",
+	"vitess_keyspace_recovery_disable",
+	"replica_rebuild",
 }
 
 // vtorcBackend is a list of SQL statements required to build the vtorc backend
@@ -298,6 +300,7 @@ CREATE TABLE vitess_keyspace (
 	keyspace varchar(128) NOT NULL,
 	keyspace_type smallint(5) NOT NULL,
 	durability_policy varchar(512) NOT NULL,
+	durability_policy_config text NOT NULL DEFAULT '',
 	PRIMARY KEY (keyspace)
 )`,
 	`
@@ -312,6 +315,38 @@ CREATE TABLE vitess_shard (
 	PRIMARY KEY (keyspace, shard)
 )`,
 	`
+DROP TABLE IF EXISTS vitess_keyspace_recovery_disable
+`,
+	`
+CREATE TABLE vitess_keyspace_recovery_disable (
+	keyspace varchar(128) NOT NULL,
+	shard varchar(128) NOT NULL,
+	analysis varchar(128) NOT NULL,
+	expire_unix_timestamp bigint NOT NULL DEFAULT 0,
+	reason text NOT NULL DEFAULT '',
+	PRIMARY KEY (keyspace, shard, analysis)
+)`,
+	`
+DROP TABLE IF EXISTS replica_rebuild
+`,
+	`
+CREATE TABLE replica_rebuild (
+	rebuild_id integer,
+	alias varchar(256) NOT NULL,
+	keyspace varchar(128) NOT NULL,
+	shard varchar(128) NOT NULL,
+	analysis varchar(128) NOT NULL DEFAULT '',
+	original_tablet_type smallint NOT NULL,
+	start_timestamp timestamp NOT NULL DEFAULT (''),
+	end_timestamp timestamp NULL DEFAULT NULL,
+	is_successful tinyint NOT NULL DEFAULT 0,
+	message text NOT NULL DEFAULT '',
+	PRIMARY KEY (rebuild_id)
+)`,
+	`
+CREATE INDEX keyspace_shard_idx_replica_rebuild ON replica_rebuild (keyspace, shard)
+	`,
+	`
 CREATE INDEX source_host_port_idx_database_instance_database_instance on database_instance (source_host, source_port)
 	`,
 	`
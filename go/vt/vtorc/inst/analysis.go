@@ -45,6 +45,7 @@ const (
 	NotConnectedToPrimary                  AnalysisCode = "NotConnectedToPrimary"
 	ConnectedToWrongPrimary                AnalysisCode = "ConnectedToWrongPrimary"
 	ReplicationStopped                     AnalysisCode = "ReplicationStopped"
+	UnrecoverableReplicationError          AnalysisCode = "UnrecoverableReplicationError"
 	ReplicaSemiSyncMustBeSet               AnalysisCode = "ReplicaSemiSyncMustBeSet"
 	ReplicaSemiSyncMustNotBeSet            AnalysisCode = "ReplicaSemiSyncMustNotBeSet"
 	ReplicaMisconfigured                   AnalysisCode = "ReplicaMisconfigured"
@@ -105,6 +106,8 @@ type ReplicationAnalysis struct {
 	CountValidReplicas                        uint
 	CountValidReplicatingReplicas             uint
 	ReplicationStopped                        bool
+	LastSQLError                              string
+	LastIOError                               string
 	ErrantGTID                                string
 	ReplicaNetTimeout                         int32
 	HeartbeatInterval                         float64
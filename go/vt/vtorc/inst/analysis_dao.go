@@ -77,6 +77,7 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 		vitess_keyspace.keyspace AS keyspace,
 		vitess_keyspace.keyspace_type AS keyspace_type,
 		vitess_keyspace.durability_policy AS durability_policy,
+		vitess_keyspace.durability_policy_config AS durability_policy_config,
 		vitess_shard.primary_timestamp AS shard_primary_term_timestamp,
 		primary_instance.read_only AS read_only,
 		MIN(primary_instance.gtid_errant) AS gtid_errant,
@@ -137,6 +138,8 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 			primary_instance.replica_sql_running = 0
 			OR primary_instance.replica_io_running = 0
 		) AS replication_stopped,
+		MIN(primary_instance.last_sql_error) AS last_sql_error,
+		MIN(primary_instance.last_io_error) AS last_io_error,
 		MIN(
 			primary_instance.supports_oracle_gtid
 		) AS supports_oracle_gtid,
@@ -332,6 +335,8 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 		a.CountValidReplicas = m.GetUint("count_valid_replicas")
 		a.CountValidReplicatingReplicas = m.GetUint("count_valid_replicating_replicas")
 		a.ReplicationStopped = m.GetBool("replication_stopped")
+		a.LastSQLError = m.GetString("last_sql_error")
+		a.LastIOError = m.GetString("last_io_error")
 		a.ErrantGTID = m.GetString("gtid_errant")
 
 		countValidOracleGTIDReplicas := m.GetUint("count_valid_oracle_gtid_replicas")
@@ -385,7 +390,12 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 				log.Errorf("ignoring keyspace %v because no durability_policy is set. Please set it using SetKeyspaceDurabilityPolicy", a.AnalyzedKeyspace)
 				return nil
 			}
-			durability, err := policy.GetDurabilityPolicy(durabilityPolicy)
+			durabilityConfig, err := readDurabilityPolicyConfig(m.GetString("durability_policy_config"))
+			if err != nil {
+				log.Errorf("can't read the config of durability policy %v - %v. Skipping keyspace - %v.", durabilityPolicy, err, a.AnalyzedKeyspace)
+				return nil
+			}
+			durability, err := policy.GetDurabilityPolicyWithConfig(durabilityPolicy, durabilityConfig)
 			if err != nil {
 				log.Errorf("can't get the durability policy %v - %v. Skipping keyspace - %v.", durabilityPolicy, err, a.AnalyzedKeyspace)
 				return nil
@@ -492,6 +502,10 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 			a.Analysis = ConnectedToWrongPrimary
 			a.Description = "Connected to wrong primary"
 			//
+		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && a.ReplicationStopped && IsUnrecoverableReplicationError(a.LastSQLError, a.LastIOError) {
+			a.Analysis = UnrecoverableReplicationError
+			a.Description = "Replication is stopped with an error that restarting it can't fix"
+			//
 		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && a.ReplicationStopped {
 			a.Analysis = ReplicationStopped
 			a.Description = "Replication is stopped"
@@ -36,7 +36,18 @@ func AuditOperation(auditType string, tabletAlias string, message string) error
 	if tabletAlias != "" {
 		keyspace, shard, _ = GetKeyspaceShardName(tabletAlias)
 	}
+	return auditOperation(auditType, tabletAlias, keyspace, shard, message)
+}
+
+// AuditKeyspaceShardOperation creates and writes a new audit entry for an operation that
+// applies to a keyspace or shard rather than to a single tablet. An empty shard means the
+// operation applies to the whole keyspace.
+func AuditKeyspaceShardOperation(auditType string, keyspace string, shard string, message string) error {
+	return auditOperation(auditType, "", keyspace, shard, message)
+}
 
+// auditOperation writes the audit entry to the configured audit destinations.
+func auditOperation(auditType string, tabletAlias string, keyspace string, shard string, message string) error {
 	auditWrittenToFile := false
 	if config.GetAuditFileLocation() != "" {
 		auditWrittenToFile = true
@@ -125,6 +125,25 @@ func TestAuditOperation(t *testing.T) {
 		require.NoError(t, err)
 		require.Contains(t, string(fileContent), "\ttest-audit-operation\tzone-1-0000000100\t[ks:0]\ttest-message")
 	})
+
+	t.Run("audit keyspace and shard to File", func(t *testing.T) {
+		config.SetAuditToBackend(false)
+		config.SetAuditToSyslog(false)
+
+		file, err := os.CreateTemp("", "test-auditing-*")
+		require.NoError(t, err)
+		defer os.Remove(file.Name())
+		config.SetAuditFileLocation(file.Name())
+
+		err = AuditKeyspaceShardOperation(auditType, "ks2", "-80", message)
+		require.NoError(t, err)
+
+		// Give a little time for the write to succeed since it happens in a separate go-routine
+		time.Sleep(100 * time.Millisecond)
+		fileContent, err := os.ReadFile(file.Name())
+		require.NoError(t, err)
+		require.Contains(t, string(fileContent), "\ttest-audit-operation\t\t[ks2:-80]\ttest-message")
+	})
 }
 
 // audit presents a single audit entry (namely in the database)
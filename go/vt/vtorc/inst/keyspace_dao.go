@@ -18,7 +18,11 @@ package inst
 
 import (
 	"errors"
+	"time"
 
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/external/golib/sqlutils"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
@@ -38,7 +42,8 @@ func ReadKeyspace(keyspaceName string) (*topo.KeyspaceInfo, error) {
 	query := `
 		select
 			keyspace_type,
-			durability_policy
+			durability_policy,
+			durability_policy_config
 		from
 			vitess_keyspace
 		where keyspace=?
@@ -50,6 +55,11 @@ func ReadKeyspace(keyspaceName string) (*topo.KeyspaceInfo, error) {
 	err := db.QueryVTOrc(query, args, func(row sqlutils.RowMap) error {
 		keyspace.KeyspaceType = topodatapb.KeyspaceType(row.GetInt32("keyspace_type"))
 		keyspace.DurabilityPolicy = row.GetString("durability_policy")
+		config, err := readDurabilityPolicyConfig(row.GetString("durability_policy_config"))
+		if err != nil {
+			return err
+		}
+		keyspace.DurabilityPolicyConfig = config
 		keyspace.SetKeyspaceName(keyspaceName)
 		return nil
 	})
@@ -59,24 +69,121 @@ func ReadKeyspace(keyspaceName string) (*topo.KeyspaceInfo, error) {
 	if keyspace.KeyspaceName() == "" {
 		return nil, ErrKeyspaceNotFound
 	}
+	keyspace.VtorcRecoveryDisables, err = readKeyspaceRecoveryDisables(keyspaceName)
+	if err != nil {
+		return nil, err
+	}
 	return keyspace, nil
 }
 
+// readKeyspaceRecoveryDisables reads the VTOrc recovery disables stored for the keyspace.
+func readKeyspaceRecoveryDisables(keyspaceName string) (disables []*topodatapb.VtorcRecoveryDisable, err error) {
+	query := `
+		select
+			shard,
+			analysis,
+			expire_unix_timestamp,
+			reason
+		from
+			vitess_keyspace_recovery_disable
+		where keyspace=?
+		order by shard, analysis
+		`
+	err = db.QueryVTOrc(query, sqlutils.Args(keyspaceName), func(row sqlutils.RowMap) error {
+		disable := &topodatapb.VtorcRecoveryDisable{
+			Shard:        row.GetString("shard"),
+			AnalysisCode: row.GetString("analysis"),
+			Reason:       row.GetString("reason"),
+		}
+		if expire := row.GetInt64("expire_unix_timestamp"); expire > 0 {
+			disable.ExpireTime = protoutil.TimeToProto(time.Unix(expire, 0))
+		}
+		disables = append(disables, disable)
+		return nil
+	})
+	return disables, err
+}
+
 // SaveKeyspace saves the keyspace record against the keyspace name.
 func SaveKeyspace(keyspace *topo.KeyspaceInfo) error {
+	var config []byte
+	if keyspace.DurabilityPolicyConfig != nil {
+		var err error
+		config, err = prototext.Marshal(keyspace.DurabilityPolicyConfig)
+		if err != nil {
+			return err
+		}
+	}
 	_, err := db.ExecVTOrc(`
 		replace
 			into vitess_keyspace (
-				keyspace, keyspace_type, durability_policy
+				keyspace, keyspace_type, durability_policy, durability_policy_config
 			) values (
-				?, ?, ?
+				?, ?, ?, ?
 			)
 		`,
 		keyspace.KeyspaceName(),
 		int(keyspace.KeyspaceType),
 		keyspace.GetDurabilityPolicy(),
+		string(config),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return saveKeyspaceRecoveryDisables(keyspace)
+}
+
+// readDurabilityPolicyConfig parses a durability policy config saved by SaveKeyspace.
+// It returns nil if no config was saved.
+func readDurabilityPolicyConfig(str string) (*topodatapb.DurabilityPolicyConfig, error) {
+	if str == "" {
+		return nil, nil
+	}
+	config := &topodatapb.DurabilityPolicyConfig{}
+	opts := prototext.UnmarshalOptions{DiscardUnknown: true}
+	if err := opts.Unmarshal([]byte(str), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// saveKeyspaceRecoveryDisables replaces the VTOrc recovery disables stored for the keyspace
+// with the ones in the keyspace record.
+func saveKeyspaceRecoveryDisables(keyspace *topo.KeyspaceInfo) error {
+	_, err := db.ExecVTOrc(`
+		delete
+			from vitess_keyspace_recovery_disable
+		where keyspace=?
+		`,
+		keyspace.KeyspaceName(),
+	)
+	if err != nil {
+		return err
+	}
+	for _, disable := range keyspace.GetVtorcRecoveryDisables() {
+		var expire int64
+		if disable.ExpireTime != nil {
+			expire = protoutil.TimeFromProto(disable.ExpireTime).Unix()
+		}
+		_, err = db.ExecVTOrc(`
+			replace
+				into vitess_keyspace_recovery_disable (
+					keyspace, shard, analysis, expire_unix_timestamp, reason
+				) values (
+					?, ?, ?, ?, ?
+				)
+			`,
+			keyspace.KeyspaceName(),
+			disable.Shard,
+			disable.AnalysisCode,
+			expire,
+			disable.Reason,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // GetDurabilityPolicy gets the durability policy for the given keyspace.
@@ -85,5 +192,5 @@ func GetDurabilityPolicy(keyspace string) (policy.Durabler, error) {
 	if err != nil {
 		return nil, err
 	}
-	return policy.GetDurabilityPolicy(ki.DurabilityPolicy)
+	return policy.GetDurabilityPolicyWithConfig(ki.DurabilityPolicy, ki.DurabilityPolicyConfig)
 }
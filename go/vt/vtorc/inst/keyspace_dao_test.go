@@ -18,9 +18,12 @@ package inst
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/test/utils"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topotools"
@@ -80,6 +83,33 @@ func TestSaveAndReadKeyspace(t *testing.T) {
 				DurabilityPolicy: policy.DurabilityNone,
 			},
 			semiSyncAckersWanted: 0,
+		}, {
+			name:         "Success with recovery disables",
+			keyspaceName: "ks6",
+			keyspace: &topodatapb.Keyspace{
+				KeyspaceType:     topodatapb.KeyspaceType_NORMAL,
+				DurabilityPolicy: policy.DurabilityNone,
+				VtorcRecoveryDisables: []*topodatapb.VtorcRecoveryDisable{{
+					Reason: "keyspace maintenance",
+				}, {
+					Shard:        "-80",
+					AnalysisCode: "DeadPrimary",
+					ExpireTime:   protoutil.TimeToProto(time.Unix(2000000000, 0)),
+				}},
+			},
+			keyspaceWanted: nil,
+		}, {
+			name:         "Success with a durability policy config",
+			keyspaceName: "ks7",
+			keyspace: &topodatapb.Keyspace{
+				KeyspaceType:     topodatapb.KeyspaceType_NORMAL,
+				DurabilityPolicy: policy.DurabilityQuorum,
+				DurabilityPolicyConfig: &topodatapb.DurabilityPolicyConfig{
+					Acks: &topodatapb.DurabilityPolicyConfig_AckRule{Scope: topodatapb.DurabilityPolicyConfig_OTHER_CELL, Count: 3},
+				},
+			},
+			keyspaceWanted:       nil,
+			semiSyncAckersWanted: 3,
 		}, {
 			name:           "No keyspace found",
 			keyspaceName:   "ks5",
@@ -110,6 +140,7 @@ func TestSaveAndReadKeyspace(t *testing.T) {
 			}
 			require.NoError(t, err)
 			require.True(t, topotools.KeyspaceEquality(tt.keyspaceWanted, readKeyspaceInfo.Keyspace))
+			utils.MustMatch(t, tt.keyspaceWanted.VtorcRecoveryDisables, readKeyspaceInfo.VtorcRecoveryDisables)
 			require.Equal(t, tt.keyspaceName, readKeyspaceInfo.KeyspaceName())
 			if tt.keyspace.KeyspaceType == topodatapb.KeyspaceType_SNAPSHOT {
 				return
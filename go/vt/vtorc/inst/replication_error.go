@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"regexp"
+)
+
+var (
+	// unrecoverableSQLErrorRegexp matches the replication applier errors that restarting replication
+	// can't fix, because the replica's data has diverged from the primary's:
+	// ER_DUP_ENTRY (1062) when a row to insert already exists, and
+	// ER_KEY_NOT_FOUND (1032) when a row to update or delete is missing.
+	// MySQL 8.0 writes the error codes as MY-001062 and MY-001032.
+	unrecoverableSQLErrorRegexp = regexp.MustCompile(`Error_code: (MY-00)?(1062|1032)\b`)
+	// unrecoverableIOErrorRegexp matches the replication receiver errors that restarting replication
+	// can't fix. ER_SOURCE_FATAL_ERROR_READING_BINLOG (1236) is returned when the binary logs the replica
+	// needs have been purged from the primary.
+	unrecoverableIOErrorRegexp = regexp.MustCompile(`fatal error 1236 from (source|master)`)
+)
+
+// IsUnrecoverableReplicationError returns true if the given replication errors can't be fixed by
+// restarting replication, and the replica has to be rebuilt instead.
+func IsUnrecoverableReplicationError(lastSQLError string, lastIOError string) bool {
+	return unrecoverableSQLErrorRegexp.MatchString(lastSQLError) || unrecoverableIOErrorRegexp.MatchString(lastIOError)
+}
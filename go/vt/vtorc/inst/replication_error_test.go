@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUnrecoverableReplicationError(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastSQLError string
+		lastIOError  string
+		want         bool
+	}{
+		{
+			name: "No errors",
+			want: false,
+		}, {
+			name:         "Duplicate key",
+			lastSQLError: "Could not execute Write_rows event on table ks.t1; Duplicate entry '1' for key 'PRIMARY', Error_code: 1062; handler error HA_ERR_FOUND_DUPP_KEY; the event's master log mysql-bin.000001, end_log_pos 1234",
+			want:         true,
+		}, {
+			name:         "Missing row in MySQL 8.0",
+			lastSQLError: "Could not execute Update_rows event on table ks.t1; Can't find record in 't1', Error_code: MY-001032; handler error HA_ERR_KEY_NOT_FOUND; the event's source log binlog.000001, end_log_pos 1234",
+			want:         true,
+		}, {
+			name:         "Other applier error",
+			lastSQLError: "Error 'Table 'ks.t2' doesn't exist' on query. Default database: 'ks'. Query: 'insert into t2 values (1)', Error_code: 1146",
+			want:         false,
+		}, {
+			name:        "Purged binary logs",
+			lastIOError: "Got fatal error 1236 from source when reading data from binary log: 'Cannot replicate because the source purged required binary logs.'",
+			want:        true,
+		}, {
+			name:        "Connection error",
+			lastIOError: "error connecting to master 'vt_repl@localhost:3306' - retry-time: 10 retries: 1",
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsUnrecoverableReplicationError(tt.lastSQLError, tt.lastIOError))
+		})
+	}
+}
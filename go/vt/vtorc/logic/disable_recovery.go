@@ -28,16 +28,43 @@ package logic
 // but we won't be doing that many recoveries at once so the load
 // on this table is expected to be very low. It should be fine to
 // go to the database each time.
+//
+// Recoveries can also be disabled for a single keyspace, shard or
+// analysis code, optionally until an expiry time. These disables are
+// stored in the keyspace record in the topo server, so that they
+// survive VTOrc restarts and are shared by all VTOrc instances, and
+// are copied into vtorc.vitess_keyspace_recovery_disable whenever the
+// keyspace record is refreshed.
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/external/golib/sqlutils"
 	"vitess.io/vitess/go/vt/log"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
 )
 
+// ErrRecoveryDisableNotFound is returned when enabling recoveries that were not disabled.
+var ErrRecoveryDisableNotFound = errors.New("recovery disable not found")
+
+// RecoveryDisable is a recovery disable for a keyspace, shard or analysis code.
+type RecoveryDisable struct {
+	Keyspace string
+	// Shard is empty when the disable applies to all the shards of the keyspace.
+	Shard string
+	// Analysis is empty when the disable applies to all analysis codes.
+	Analysis inst.AnalysisCode
+	// ExpireTime is the zero time when the disable never expires.
+	ExpireTime time.Time
+	Reason     string
+}
+
 // IsRecoveryDisabled returns true if Recoveries are disabled globally
 func IsRecoveryDisabled() (disabled bool, err error) {
 	query := `SELECT
@@ -78,3 +105,146 @@ func EnableRecovery() error {
 			disable_recovery >= 0`)
 	return err
 }
+
+// IsRecoveryDisabledForAnalysis returns true if recoveries for the given analysis
+// are disabled for the keyspace and shard, by a disable that has not expired.
+func IsRecoveryDisabledForAnalysis(keyspace string, shard string, analysis inst.AnalysisCode) (disabled bool, err error) {
+	query := `SELECT
+		COUNT(*) AS mycount
+	FROM
+		vitess_keyspace_recovery_disable
+	WHERE
+		keyspace = ?
+		AND (shard = '' OR shard = ?)
+		AND (analysis = '' OR analysis = ?)
+		AND (expire_unix_timestamp = 0 OR expire_unix_timestamp > ?)
+	`
+	args := sqlutils.Args(keyspace, shard, string(analysis), time.Now().Unix())
+	err = db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		disabled = m.GetInt("mycount") > 0
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("recovery.IsRecoveryDisabledForAnalysis(): %v", err)
+		log.Errorf(errMsg)
+		err = errors.New(errMsg)
+	}
+	return disabled, err
+}
+
+// ReadRecoveryDisables returns the recovery disables that have not expired for the
+// given keyspace, or for all keyspaces if keyspace is empty.
+func ReadRecoveryDisables(keyspace string) ([]*RecoveryDisable, error) {
+	query := `SELECT
+		keyspace,
+		shard,
+		analysis,
+		expire_unix_timestamp,
+		reason
+	FROM
+		vitess_keyspace_recovery_disable
+	WHERE
+		(? = '' OR keyspace = ?)
+		AND (expire_unix_timestamp = 0 OR expire_unix_timestamp > ?)
+	ORDER BY
+		keyspace, shard, analysis
+	`
+	var disables []*RecoveryDisable
+	args := sqlutils.Args(keyspace, keyspace, time.Now().Unix())
+	err := db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		disable := &RecoveryDisable{
+			Keyspace: m.GetString("keyspace"),
+			Shard:    m.GetString("shard"),
+			Analysis: inst.AnalysisCode(m.GetString("analysis")),
+			Reason:   m.GetString("reason"),
+		}
+		if expire := m.GetInt64("expire_unix_timestamp"); expire > 0 {
+			disable.ExpireTime = time.Unix(expire, 0)
+		}
+		disables = append(disables, disable)
+		return nil
+	})
+	return disables, err
+}
+
+// DisableKeyspaceShardRecovery disables recoveries for the keyspace, or for one of its
+// shards if shard is set, and for one analysis code if analysis is set. The disable
+// expires after the given duration, or never if the duration is zero.
+func DisableKeyspaceShardRecovery(ctx context.Context, keyspace string, shard string, analysis inst.AnalysisCode, duration time.Duration, reason string) (err error) {
+	if duration < 0 {
+		return fmt.Errorf("invalid duration %v: must not be negative", duration)
+	}
+	if shard != "" {
+		if _, err = ts.GetShard(ctx, keyspace, shard); err != nil {
+			return err
+		}
+	}
+
+	ctx, unlock, err := ts.LockKeyspace(ctx, keyspace, "DisableKeyspaceShardRecovery")
+	if err != nil {
+		return err
+	}
+	defer unlock(&err)
+
+	ki, err := ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	disable := &topodatapb.VtorcRecoveryDisable{
+		Shard:        shard,
+		AnalysisCode: string(analysis),
+		Reason:       reason,
+	}
+	if duration > 0 {
+		disable.ExpireTime = protoutil.TimeToProto(now.Add(duration))
+	}
+	ki.SetVtorcRecoveryDisable(disable, now)
+	if err = ts.UpdateKeyspace(ctx, ki); err != nil {
+		return err
+	}
+	if err = inst.SaveKeyspace(ki); err != nil {
+		return err
+	}
+
+	message := "recoveries disabled"
+	if analysis != "" {
+		message += fmt.Sprintf(" for analysis %s", analysis)
+	}
+	if duration > 0 {
+		message += fmt.Sprintf(" until %v", now.Add(duration).UTC().Format(time.RFC3339))
+	}
+	if reason != "" {
+		message += fmt.Sprintf(": %s", reason)
+	}
+	return inst.AuditKeyspaceShardOperation("disable-recovery", keyspace, shard, message)
+}
+
+// EnableKeyspaceShardRecovery removes the recovery disable added by DisableKeyspaceShardRecovery
+// for the same keyspace, shard and analysis code.
+func EnableKeyspaceShardRecovery(ctx context.Context, keyspace string, shard string, analysis inst.AnalysisCode) (err error) {
+	ctx, unlock, err := ts.LockKeyspace(ctx, keyspace, "EnableKeyspaceShardRecovery")
+	if err != nil {
+		return err
+	}
+	defer unlock(&err)
+
+	ki, err := ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	if !ki.RemoveVtorcRecoveryDisable(shard, string(analysis), time.Now()) {
+		return ErrRecoveryDisableNotFound
+	}
+	if err = ts.UpdateKeyspace(ctx, ki); err != nil {
+		return err
+	}
+	if err = inst.SaveKeyspace(ki); err != nil {
+		return err
+	}
+	message := "recoveries enabled"
+	if analysis != "" {
+		message += fmt.Sprintf(" for analysis %s", analysis)
+	}
+	return inst.AuditKeyspaceShardOperation("enable-recovery", keyspace, shard, message)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestKeyspaceShardRecoveryDisables(t *testing.T) {
+	// Store the old flags and restore on test completion
+	oldTs := ts
+	defer func() {
+		ts = oldTs
+	}()
+
+	db.ClearVTOrcDatabase()
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-80"))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "80-"))
+
+	// Recoveries are enabled to begin with.
+	disabled, err := IsRecoveryDisabledForAnalysis("ks", "-80", inst.DeadPrimary)
+	require.NoError(t, err)
+	require.False(t, disabled)
+
+	// Disabling recoveries for a shard that doesn't exist fails.
+	err = DisableKeyspaceShardRecovery(ctx, "ks", "-40", inst.DeadPrimary, 0, "")
+	require.ErrorContains(t, err, "node doesn't exist")
+
+	// Disable DeadPrimary recoveries on ks/-80 for an hour.
+	err = DisableKeyspaceShardRecovery(ctx, "ks", "-80", inst.DeadPrimary, time.Hour, "maintenance")
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		shard    string
+		analysis inst.AnalysisCode
+		disabled bool
+	}{
+		{"-80", inst.DeadPrimary, true},
+		{"-80", inst.ReplicationStopped, false},
+		{"80-", inst.DeadPrimary, false},
+	} {
+		disabled, err = IsRecoveryDisabledForAnalysis("ks", tc.shard, tc.analysis)
+		require.NoError(t, err)
+		require.Equal(t, tc.disabled, disabled, "%s %s", tc.shard, tc.analysis)
+	}
+
+	// The disable is stored in the keyspace record.
+	ki, err := ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, ki.VtorcRecoveryDisables, 1)
+	require.Equal(t, "maintenance", ki.VtorcRecoveryDisables[0].Reason)
+
+	disables, err := ReadRecoveryDisables("")
+	require.NoError(t, err)
+	require.Len(t, disables, 1)
+	require.Equal(t, "ks", disables[0].Keyspace)
+	require.Equal(t, "-80", disables[0].Shard)
+	require.Equal(t, inst.DeadPrimary, disables[0].Analysis)
+	require.WithinDuration(t, time.Now().Add(time.Hour), disables[0].ExpireTime, time.Minute)
+
+	// Disable all recoveries for the keyspace.
+	err = DisableKeyspaceShardRecovery(ctx, "ks", "", "", 0, "")
+	require.NoError(t, err)
+	disabled, err = IsRecoveryDisabledForAnalysis("ks", "80-", inst.ReplicationStopped)
+	require.NoError(t, err)
+	require.True(t, disabled)
+	disables, err = ReadRecoveryDisables("ks")
+	require.NoError(t, err)
+	require.Len(t, disables, 2)
+	require.True(t, disables[0].ExpireTime.IsZero())
+
+	// Enabling recoveries removes the matching disable only.
+	err = EnableKeyspaceShardRecovery(ctx, "ks", "", "")
+	require.NoError(t, err)
+	disabled, err = IsRecoveryDisabledForAnalysis("ks", "80-", inst.ReplicationStopped)
+	require.NoError(t, err)
+	require.False(t, disabled)
+	disabled, err = IsRecoveryDisabledForAnalysis("ks", "-80", inst.DeadPrimary)
+	require.NoError(t, err)
+	require.True(t, disabled)
+
+	err = EnableKeyspaceShardRecovery(ctx, "ks", "80-", "")
+	require.ErrorIs(t, err, ErrRecoveryDisableNotFound)
+
+	// An expired disable added to the keyspace record outside of VTOrc has no effect.
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "test")
+	require.NoError(t, err)
+	ki, err = ts.GetKeyspace(lockCtx, "ks")
+	require.NoError(t, err)
+	ki.VtorcRecoveryDisables = append(ki.VtorcRecoveryDisables, &topodatapb.VtorcRecoveryDisable{
+		Shard:      "80-",
+		ExpireTime: protoutil.TimeToProto(time.Now().Add(-time.Minute)),
+	})
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+	require.NoError(t, refreshKeyspace("ks"))
+	disabled, err = IsRecoveryDisabledForAnalysis("ks", "80-", inst.DeadPrimary)
+	require.NoError(t, err)
+	require.False(t, disabled)
+	disables, err = ReadRecoveryDisables("ks")
+	require.NoError(t, err)
+	require.Len(t, disables, 1)
+}
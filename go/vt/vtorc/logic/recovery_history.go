@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// recoveryWebhookFailures counts the recoveries that couldn't be posted to the recovery webhook.
+var recoveryWebhookFailures = stats.NewCounter("RecoveryWebhookFailures", "Number of recoveries that couldn't be posted to the recovery webhook")
+
+const (
+	// DefaultHistoryLimit is the number of recoveries or detections read when the filter has no limit.
+	DefaultHistoryLimit = 100
+	// MaxHistoryLimit is the largest number of recoveries or detections that can be read at once.
+	MaxHistoryLimit = 1000
+)
+
+// HistoryFilter filters the recoveries and detections read from the VTOrc database.
+// Empty fields don't filter anything.
+type HistoryFilter struct {
+	Keyspace string
+	Shard    string
+	Analysis inst.AnalysisCode
+	Since    time.Time
+	Until    time.Time
+	// BeforeID only matches the rows with a lower ID. Since the rows are read latest first, passing the
+	// ID of the last row read reads the next page.
+	BeforeID int64
+	// Limit is the largest number of rows read. DefaultHistoryLimit is used if it is 0.
+	Limit int
+}
+
+// whereClause returns the WHERE clause matching the filter, using the given columns as the ID and
+// the timestamp of the rows.
+func (filter *HistoryFilter) whereClause(idColumn string, timestampColumn string) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, idColumn+" < ?")
+		args = append(args, filter.BeforeID)
+	}
+	if filter.Keyspace != "" {
+		conditions = append(conditions, "keyspace = ?")
+		args = append(args, filter.Keyspace)
+	}
+	if filter.Shard != "" {
+		conditions = append(conditions, "shard = ?")
+		args = append(args, filter.Shard)
+	}
+	if filter.Analysis != "" {
+		conditions = append(conditions, "analysis = ?")
+		args = append(args, string(filter.Analysis))
+	}
+	// The timestamps are stored in UTC, and compare as strings.
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, timestampColumn+" >= ?")
+		args = append(args, filter.Since.UTC().Format(sqlutils.DateTimeFormat))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, timestampColumn+" < ?")
+		args = append(args, filter.Until.UTC().Format(sqlutils.DateTimeFormat))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// limitClause returns the LIMIT clause of the filter.
+func (filter *HistoryFilter) limitClause() string {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	return fmt.Sprintf("LIMIT %d", limit)
+}
+
+// RecoveryHistory is a recovery VTOrc ran, with the steps it took. It is what the recovery
+// history API returns and what is posted to the recovery webhook.
+type RecoveryHistory struct {
+	RecoveryID     int64
+	DetectionID    int64
+	TabletAlias    string
+	Keyspace       string
+	Shard          string
+	Analysis       inst.AnalysisCode
+	StartTimestamp time.Time
+	// EndTimestamp is zero while the recovery is running.
+	EndTimestamp   time.Time
+	Duration       time.Duration
+	IsSuccessful   bool
+	SuccessorAlias string
+	Errors         []string
+	Steps          []*RecoveryHistoryStep
+}
+
+// RecoveryHistoryStep is a step of a recovery.
+type RecoveryHistoryStep struct {
+	Timestamp time.Time
+	// Elapsed is the time since the start of the recovery.
+	Elapsed time.Duration
+	// Duration is the time since the previous step, or since the start of the recovery for the first step.
+	Duration time.Duration
+	Message  string
+}
+
+// Detection is a problem detected by VTOrc.
+type Detection struct {
+	DetectionID int64
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	Analysis    inst.AnalysisCode
+	Timestamp   time.Time
+	// RecoveryIDs are the recoveries that were run for the problem.
+	RecoveryIDs []int64
+}
+
+// newRecoveryHistory returns the history of the given recovery with the given steps.
+func newRecoveryHistory(topologyRecovery *TopologyRecovery, steps []*TopologyRecoveryStep) *RecoveryHistory {
+	history := &RecoveryHistory{
+		RecoveryID:     topologyRecovery.ID,
+		DetectionID:    topologyRecovery.DetectionID,
+		TabletAlias:    topologyRecovery.AnalysisEntry.AnalyzedInstanceAlias,
+		Keyspace:       topologyRecovery.AnalysisEntry.ClusterDetails.Keyspace,
+		Shard:          topologyRecovery.AnalysisEntry.ClusterDetails.Shard,
+		Analysis:       topologyRecovery.AnalysisEntry.Analysis,
+		StartTimestamp: parseTimestamp(topologyRecovery.RecoveryStartTimestamp),
+		EndTimestamp:   parseTimestamp(topologyRecovery.RecoveryEndTimestamp),
+		IsSuccessful:   topologyRecovery.IsSuccessful,
+		SuccessorAlias: topologyRecovery.SuccessorAlias,
+		Errors:         []string{},
+		Steps:          []*RecoveryHistoryStep{},
+	}
+	for _, recoveryError := range topologyRecovery.AllErrors {
+		if recoveryError != "" {
+			history.Errors = append(history.Errors, recoveryError)
+		}
+	}
+	if !history.EndTimestamp.IsZero() {
+		history.Duration = history.EndTimestamp.Sub(history.StartTimestamp)
+	}
+	previous := history.StartTimestamp
+	for _, step := range steps {
+		timestamp := parseTimestamp(step.AuditAt)
+		history.Steps = append(history.Steps, &RecoveryHistoryStep{
+			Timestamp: timestamp,
+			Elapsed:   timestamp.Sub(history.StartTimestamp),
+			Duration:  timestamp.Sub(previous),
+			Message:   step.Message,
+		})
+		previous = timestamp
+	}
+	return history
+}
+
+// parseTimestamp parses a timestamp read from the VTOrc database, which the driver returns either in RFC 3339
+// or in the datetime format. It returns the zero time for empty timestamps.
+func parseTimestamp(timestamp string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, sqlutils.DateTimeFormat} {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// ReadRecoveryHistory reads the recoveries matching the given filter, latest first, along with their steps.
+// It reads at most the limit of the filter.
+func ReadRecoveryHistory(filter *HistoryFilter) ([]*RecoveryHistory, error) {
+	whereClause, args := filter.whereClause("recovery_id", "start_recovery")
+	recoveries, err := readRecoveries(whereClause, filter.limitClause(), args)
+	if err != nil {
+		return nil, err
+	}
+	stepsByRecovery, err := readTopologyRecoverySteps(whereClause+" ORDER BY recovery_id DESC "+filter.limitClause(), args)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*RecoveryHistory, 0, len(recoveries))
+	for _, recovery := range recoveries {
+		res = append(res, newRecoveryHistory(recovery, stepsByRecovery[recovery.ID]))
+	}
+	return res, nil
+}
+
+// readRecoveryHistory reads the recovery with the given ID along with its steps.
+func readRecoveryHistory(recoveryID int64) (*RecoveryHistory, error) {
+	whereClause, args := `WHERE recovery_id = ?`, sqlutils.Args(recoveryID)
+	recoveries, err := readRecoveries(whereClause, ``, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(recoveries) == 0 {
+		return nil, fmt.Errorf("recovery %d not found", recoveryID)
+	}
+	stepsByRecovery, err := readTopologyRecoverySteps(whereClause, args)
+	if err != nil {
+		return nil, err
+	}
+	return newRecoveryHistory(recoveries[0], stepsByRecovery[recoveryID]), nil
+}
+
+// exportRecovery posts the given finished recovery to the recovery webhook, if one is configured.
+// Failures are logged and counted, since exporting a recovery must not get in the way of recovering.
+func exportRecovery(recoveryID int64) {
+	url := config.GetRecoveryWebhookURL()
+	if url == "" {
+		return
+	}
+	if err := postRecovery(url, recoveryID); err != nil {
+		recoveryWebhookFailures.Add(1)
+		log.Errorf("Failed to post recovery %d to the recovery webhook: %v", recoveryID, err)
+	}
+}
+
+// postRecovery posts the recovery with the given ID to the given URL as a JSON document.
+func postRecovery(url string, recoveryID int64) error {
+	history, err := readRecoveryHistory(recoveryID)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetRecoveryWebhookTimeout())
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
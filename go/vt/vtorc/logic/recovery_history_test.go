@@ -0,0 +1,215 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// writeTestRecovery writes a detection and a finished recovery for the given analysis, with the given steps.
+func writeTestRecovery(t *testing.T, alias string, keyspace string, shard string, analysis inst.AnalysisCode, steps ...string) *TopologyRecovery {
+	analysisEntry := &inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: alias,
+		Analysis:              analysis,
+		ClusterDetails: inst.ClusterInfo{
+			Keyspace: keyspace,
+			Shard:    shard,
+		},
+	}
+	require.NoError(t, InsertRecoveryDetection(analysisEntry))
+	topologyRecovery, err := AttemptRecoveryRegistration(analysisEntry)
+	require.NoError(t, err)
+	for _, step := range steps {
+		require.NoError(t, AuditTopologyRecovery(topologyRecovery, step))
+	}
+	_ = topologyRecovery.AddError(io.ErrUnexpectedEOF)
+	topologyRecovery.SuccessorAlias = "zone1-0000000102"
+	topologyRecovery.IsSuccessful = true
+	require.NoError(t, writeResolveRecovery(topologyRecovery))
+	return topologyRecovery
+}
+
+func TestReadRecoveryHistory(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+
+	deadPrimary := writeTestRecovery(t, "zone1-0000000101", "ks", "0", inst.DeadPrimary, "step 1", "step 2")
+	replicaIsWritable := writeTestRecovery(t, "zone1-0000000103", "ks", "-80", inst.ReplicaIsWritable)
+
+	recoveries, err := ReadRecoveryHistory(&HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, recoveries, 2)
+	// Latest first.
+	require.EqualValues(t, replicaIsWritable.ID, recoveries[0].RecoveryID)
+	require.EqualValues(t, deadPrimary.ID, recoveries[1].RecoveryID)
+
+	recovery := recoveries[1]
+	require.EqualValues(t, deadPrimary.AnalysisEntry.RecoveryId, recovery.DetectionID)
+	require.Equal(t, "zone1-0000000101", recovery.TabletAlias)
+	require.Equal(t, "ks", recovery.Keyspace)
+	require.Equal(t, "0", recovery.Shard)
+	require.Equal(t, inst.DeadPrimary, recovery.Analysis)
+	require.True(t, recovery.IsSuccessful)
+	require.Equal(t, "zone1-0000000102", recovery.SuccessorAlias)
+	require.Equal(t, []string{io.ErrUnexpectedEOF.Error()}, recovery.Errors)
+	require.False(t, recovery.StartTimestamp.IsZero())
+	require.False(t, recovery.EndTimestamp.IsZero())
+	require.GreaterOrEqual(t, recovery.Duration, time.Duration(0))
+	require.Len(t, recovery.Steps, 2)
+	require.Equal(t, "step 1", recovery.Steps[0].Message)
+	require.Equal(t, "step 2", recovery.Steps[1].Message)
+	require.Equal(t, recovery.Steps[1].Elapsed, recovery.Steps[0].Duration+recovery.Steps[1].Duration)
+	require.Empty(t, recoveries[0].Steps)
+
+	tests := []struct {
+		name    string
+		filter  *HistoryFilter
+		wantIDs []int64
+	}{
+		{
+			name:    "Keyspace and shard",
+			filter:  &HistoryFilter{Keyspace: "ks", Shard: "-80"},
+			wantIDs: []int64{replicaIsWritable.ID},
+		}, {
+			name:    "Analysis",
+			filter:  &HistoryFilter{Analysis: inst.DeadPrimary},
+			wantIDs: []int64{deadPrimary.ID},
+		}, {
+			name:    "Since",
+			filter:  &HistoryFilter{Since: time.Now().Add(-time.Hour)},
+			wantIDs: []int64{replicaIsWritable.ID, deadPrimary.ID},
+		}, {
+			name:   "Until",
+			filter: &HistoryFilter{Until: time.Now().Add(-time.Hour)},
+		}, {
+			name:    "Limit",
+			filter:  &HistoryFilter{Limit: 1},
+			wantIDs: []int64{replicaIsWritable.ID},
+		}, {
+			name:    "Next page",
+			filter:  &HistoryFilter{Limit: 1, BeforeID: replicaIsWritable.ID},
+			wantIDs: []int64{deadPrimary.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recoveries, err := ReadRecoveryHistory(tt.filter)
+			require.NoError(t, err)
+			var ids []int64
+			for _, recovery := range recoveries {
+				ids = append(ids, recovery.RecoveryID)
+				// The steps are read for the recoveries of the page.
+				if recovery.RecoveryID == deadPrimary.ID {
+					require.Len(t, recovery.Steps, 2)
+				}
+			}
+			require.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestReadDetections(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+
+	recovery := writeTestRecovery(t, "zone1-0000000101", "ks", "0", inst.DeadPrimary)
+	// A detection that wasn't recovered.
+	analysisEntry := &inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: "zone1-0000000103",
+		Analysis:              inst.ReplicationStopped,
+		ClusterDetails: inst.ClusterInfo{
+			Keyspace: "ks",
+			Shard:    "0",
+		},
+	}
+	require.NoError(t, InsertRecoveryDetection(analysisEntry))
+
+	detections, err := ReadDetections(&HistoryFilter{Keyspace: "ks"})
+	require.NoError(t, err)
+	require.Len(t, detections, 2)
+	require.EqualValues(t, analysisEntry.RecoveryId, detections[0].DetectionID)
+	require.Equal(t, "zone1-0000000103", detections[0].TabletAlias)
+	require.Equal(t, inst.ReplicationStopped, detections[0].Analysis)
+	require.Empty(t, detections[0].RecoveryIDs)
+	require.False(t, detections[0].Timestamp.IsZero())
+	require.EqualValues(t, recovery.AnalysisEntry.RecoveryId, detections[1].DetectionID)
+	require.Equal(t, []int64{recovery.ID}, detections[1].RecoveryIDs)
+
+	detections, err = ReadDetections(&HistoryFilter{Analysis: inst.DeadPrimary})
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	require.Equal(t, []int64{recovery.ID}, detections[0].RecoveryIDs)
+
+	// The detections are paged latest first.
+	detections, err = ReadDetections(&HistoryFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	require.EqualValues(t, analysisEntry.RecoveryId, detections[0].DetectionID)
+	detections, err = ReadDetections(&HistoryFilter{Limit: 1, BeforeID: analysisEntry.RecoveryId})
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	require.Equal(t, []int64{recovery.ID}, detections[0].RecoveryIDs)
+
+	detections, err = ReadDetections(&HistoryFilter{Keyspace: "ks2"})
+	require.NoError(t, err)
+	require.Empty(t, detections)
+}
+
+func TestExportRecovery(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	oldURL := config.GetRecoveryWebhookURL()
+	defer config.SetRecoveryWebhookURL(oldURL)
+
+	received := make(chan *RecoveryHistory, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var history RecoveryHistory
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&history))
+		received <- &history
+	}))
+	defer webhook.Close()
+	config.SetRecoveryWebhookURL(webhook.URL)
+
+	recovery := writeTestRecovery(t, "zone1-0000000101", "ks", "0", inst.DeadPrimary, "step 1")
+	exportRecovery(recovery.ID)
+	history := <-received
+	require.EqualValues(t, recovery.ID, history.RecoveryID)
+	require.Equal(t, inst.DeadPrimary, history.Analysis)
+	require.Equal(t, "zone1-0000000102", history.SuccessorAlias)
+	require.Len(t, history.Steps, 1)
+	require.Equal(t, "step 1", history.Steps[0].Message)
+
+	failures := recoveryWebhookFailures.Get()
+	failingWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingWebhook.Close()
+	config.SetRecoveryWebhookURL(failingWebhook.URL)
+	require.ErrorContains(t, postRecovery(failingWebhook.URL, recovery.ID), "unexpected status 503 Service Unavailable")
+	exportRecovery(recovery.ID)
+	require.Equal(t, failures+1, recoveryWebhookFailures.Get())
+}
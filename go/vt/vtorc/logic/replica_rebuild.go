@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/log"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/policy"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// replicaCatchUpPollInterval is the interval at which a rebuilt replica is checked for having caught up with its primary.
+var replicaCatchUpPollInterval = 5 * time.Second
+
+// replicaRebuildBudgetMu makes checking the budget of a shard and recording a new rebuild atomic, since the
+// recoveries of several replicas of a shard run concurrently.
+var replicaRebuildBudgetMu sync.Mutex
+
+// checkReplicaRebuildBudget returns an error if VTOrc can't start another replica rebuild in the given shard,
+// either because too many rebuilds are running or because the last one started too recently.
+func checkReplicaRebuildBudget(keyspace string, shard string) error {
+	running, recent, err := countReplicaRebuilds(keyspace, shard)
+	if err != nil {
+		return err
+	}
+	if maxConcurrent := config.GetReplicaRebuildMaxConcurrent(); running >= maxConcurrent {
+		return fmt.Errorf("%d replica rebuilds are running in %v/%v, the maximum is %d", running, keyspace, shard, maxConcurrent)
+	}
+	if minInterval := config.GetReplicaRebuildMinInterval(); minInterval > 0 && recent > 0 {
+		return fmt.Errorf("a replica rebuild was started in %v/%v less than %v ago", keyspace, shard, minInterval)
+	}
+	return nil
+}
+
+// reserveReplicaRebuild records the start of the given replica rebuild if it fits in the budget of its shard,
+// and returns an error otherwise.
+func reserveReplicaRebuild(rebuild *ReplicaRebuild) error {
+	replicaRebuildBudgetMu.Lock()
+	defer replicaRebuildBudgetMu.Unlock()
+	if err := checkReplicaRebuildBudget(rebuild.Keyspace, rebuild.Shard); err != nil {
+		return err
+	}
+	return writeReplicaRebuildStart(rebuild)
+}
+
+// rebuildReplica rebuilds a replica that has an unrecoverable replication error from a backup. The replica is
+// changed to DRAINED and then restored in the background, so that the shard isn't locked and other recoveries
+// can run for as long as the restore takes.
+func rebuildReplica(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+	// The budget is checked again when the rebuild is recorded, this only avoids registering a recovery.
+	if err := checkReplicaRebuildBudget(analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard); err != nil {
+		logger.Infof("Not rebuilding replica %s: %v", analysisEntry.AnalyzedInstanceAlias, err)
+		return false, nil, nil
+	}
+
+	topologyRecovery, err = AttemptRecoveryRegistration(analysisEntry)
+	if topologyRecovery == nil {
+		message := fmt.Sprintf("found an active or recent recovery on %+v. Will not issue another rebuildReplica.", analysisEntry.AnalyzedInstanceAlias)
+		logger.Warning(message)
+		_ = AuditTopologyRecovery(topologyRecovery, message)
+		return false, nil, err
+	}
+	logger.Infof("Analysis: %v, will rebuild replica %+v from a backup", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
+	// This has to be done in the end; whether successful or not, we should mark that the recovery is done.
+	// So that after the active period passes, we are able to run other recoveries.
+	defer func() {
+		_ = resolveRecovery(topologyRecovery, nil)
+	}()
+
+	analyzedTablet, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		logger.Errorf("Failed to read instance %s, aborting recovery", analysisEntry.AnalyzedInstanceAlias)
+		return false, topologyRecovery, err
+	}
+
+	primaryTablet, err := shardPrimary(analyzedTablet.Keyspace, analyzedTablet.Shard)
+	if err != nil {
+		logger.Info("Could not compute primary for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
+		return false, topologyRecovery, err
+	}
+
+	durabilityPolicy, err := inst.GetDurabilityPolicy(analyzedTablet.Keyspace)
+	if err != nil {
+		logger.Info("Could not read the durability policy for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
+		return false, topologyRecovery, err
+	}
+	semiSync := policy.IsReplicaSemiSync(durabilityPolicy, primaryTablet, analyzedTablet)
+
+	rebuild := &ReplicaRebuild{
+		TabletAlias:        analysisEntry.AnalyzedInstanceAlias,
+		Keyspace:           analyzedTablet.Keyspace,
+		Shard:              analyzedTablet.Shard,
+		Analysis:           analysisEntry.Analysis,
+		OriginalTabletType: analyzedTablet.Type,
+	}
+	if err := reserveReplicaRebuild(rebuild); err != nil {
+		logger.Infof("Not rebuilding replica %s: %v", analysisEntry.AnalyzedInstanceAlias, err)
+		return false, topologyRecovery, nil
+	}
+
+	if err := changeTabletType(ctx, analyzedTablet, topodatapb.TabletType_DRAINED, semiSync); err != nil {
+		endReplicaRebuild(rebuild, fmt.Errorf("failed to change the tablet type to DRAINED: %w", err))
+		return true, topologyRecovery, err
+	}
+	_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("changed %s to DRAINED, restoring it from a backup", rebuild.TabletAlias))
+
+	go runReplicaRebuild(shutdownCtx, rebuild, analyzedTablet, semiSync)
+	return true, topologyRecovery, nil
+}
+
+// runReplicaRebuild restores the given drained replica from a backup, waits for it to catch up with its
+// primary and changes it back to its original type. If any of this fails, including the given context being
+// canceled because VTOrc shuts down, the replica is left DRAINED.
+func runReplicaRebuild(ctx context.Context, rebuild *ReplicaRebuild, tablet *topodatapb.Tablet, semiSync bool) {
+	ctx, cancel := context.WithTimeout(ctx, config.GetReplicaRebuildTimeout())
+	defer cancel()
+
+	if err := restoreFromBackup(ctx, tablet); err != nil {
+		endReplicaRebuild(rebuild, fmt.Errorf("failed to restore from a backup: %w", err))
+		return
+	}
+	if err := waitForReplicaCatchUp(ctx, tablet); err != nil {
+		endReplicaRebuild(rebuild, fmt.Errorf("failed to catch up with the primary: %w", err))
+		return
+	}
+	if err := changeTabletType(ctx, tablet, rebuild.OriginalTabletType, semiSync); err != nil {
+		endReplicaRebuild(rebuild, fmt.Errorf("failed to change the tablet type back to %v: %w", rebuild.OriginalTabletType, err))
+		return
+	}
+	endReplicaRebuild(rebuild, nil)
+}
+
+// endReplicaRebuild records the end of the given replica rebuild and writes it to the audit log.
+func endReplicaRebuild(rebuild *ReplicaRebuild, err error) {
+	rebuild.IsSuccessful = err == nil
+	if err != nil {
+		rebuild.Message = err.Error()
+		log.Errorf("Rebuild of replica %s failed: %v", rebuild.TabletAlias, err)
+	} else {
+		rebuild.Message = fmt.Sprintf("restored from a backup and changed back to %v", rebuild.OriginalTabletType)
+		log.Infof("Rebuild of replica %s succeeded", rebuild.TabletAlias)
+	}
+	_ = writeReplicaRebuildEnd(rebuild)
+	_ = inst.AuditOperation("replica-rebuild", rebuild.TabletAlias, rebuild.Message)
+}
+
+// restoreFromBackup restores the given tablet from its latest backup, and returns once the restore is done.
+func restoreFromBackup(ctx context.Context, tablet *topodatapb.Tablet) error {
+	stream, err := tmc.RestoreFromBackup(ctx, tablet, &tabletmanagerdatapb.RestoreFromBackupRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Recv()
+		switch err {
+		case nil:
+			log.Infof("Restore of %s: %v", topoproto.TabletAliasString(tablet.Alias), event.GetValue())
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// waitForReplicaCatchUp waits until the replication of the given tablet is running and lags behind its primary
+// by no more than the reasonable replication lag.
+func waitForReplicaCatchUp(ctx context.Context, tablet *topodatapb.Tablet) error {
+	ticker := time.NewTicker(replicaCatchUpPollInterval)
+	defer ticker.Stop()
+	for {
+		caughtUp, err := replicaCaughtUp(ctx, tablet)
+		if err != nil {
+			log.Warningf("Failed to get the replication status of %s: %v", topoproto.TabletAliasString(tablet.Alias), err)
+		}
+		if caughtUp {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// replicaCaughtUp returns true if the replication of the given tablet is running and lags behind its primary
+// by no more than the reasonable replication lag.
+func replicaCaughtUp(ctx context.Context, tablet *topodatapb.Tablet) (bool, error) {
+	statusCtx, cancel := context.WithTimeout(ctx, config.GetWaitReplicasTimeout())
+	defer cancel()
+	status, err := tmc.ReplicationStatus(statusCtx, tablet)
+	if err != nil {
+		return false, err
+	}
+	replicationStatus := replication.ProtoToReplicationStatus(status)
+	return replicationStatus.Healthy() && !replicationStatus.ReplicationLagUnknown &&
+		int64(replicationStatus.ReplicationLagSeconds) <= config.GetReasonableReplicationLagSeconds(), nil
+}
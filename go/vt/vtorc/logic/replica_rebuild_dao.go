@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// ReplicaRebuild is a rebuild of a replica from a backup, started by VTOrc.
+type ReplicaRebuild struct {
+	ID                 int64
+	TabletAlias        string
+	Keyspace           string
+	Shard              string
+	Analysis           inst.AnalysisCode
+	OriginalTabletType topodatapb.TabletType
+	StartTimestamp     string
+	EndTimestamp       string
+	IsSuccessful       bool
+	Message            string
+}
+
+// writeReplicaRebuildStart records the start of the given replica rebuild and sets its ID.
+func writeReplicaRebuildStart(rebuild *ReplicaRebuild) error {
+	sqlResult, err := db.ExecVTOrc(`INSERT
+		INTO replica_rebuild (
+			alias,
+			keyspace,
+			shard,
+			analysis,
+			original_tablet_type,
+			start_timestamp
+		) VALUES (
+			?,
+			?,
+			?,
+			?,
+			?,
+			DATETIME('now')
+		)`,
+		rebuild.TabletAlias,
+		rebuild.Keyspace,
+		rebuild.Shard,
+		string(rebuild.Analysis),
+		int(rebuild.OriginalTabletType),
+	)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	rebuild.ID, err = sqlResult.LastInsertId()
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// writeReplicaRebuildEnd records the end of the given replica rebuild.
+func writeReplicaRebuildEnd(rebuild *ReplicaRebuild) error {
+	_, err := db.ExecVTOrc(`UPDATE replica_rebuild
+		SET
+			end_timestamp = DATETIME('now'),
+			is_successful = ?,
+			message = ?
+		WHERE
+			rebuild_id = ?
+		`,
+		rebuild.IsSuccessful,
+		rebuild.Message,
+		rebuild.ID,
+	)
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// readReplicaRebuilds reads the replica rebuilds of the given shard, latest first.
+func readReplicaRebuilds(keyspace string, shard string) ([]*ReplicaRebuild, error) {
+	var res []*ReplicaRebuild
+	query := `SELECT
+			rebuild_id,
+			alias,
+			keyspace,
+			shard,
+			analysis,
+			original_tablet_type,
+			start_timestamp,
+			IFNULL(end_timestamp, '') AS end_timestamp,
+			is_successful,
+			message
+		FROM
+			replica_rebuild
+		WHERE
+			keyspace = ?
+			AND shard = ?
+		ORDER BY rebuild_id DESC
+		`
+	err := db.QueryVTOrc(query, sqlutils.Args(keyspace, shard), func(m sqlutils.RowMap) error {
+		res = append(res, &ReplicaRebuild{
+			ID:                 m.GetInt64("rebuild_id"),
+			TabletAlias:        m.GetString("alias"),
+			Keyspace:           m.GetString("keyspace"),
+			Shard:              m.GetString("shard"),
+			Analysis:           inst.AnalysisCode(m.GetString("analysis")),
+			OriginalTabletType: topodatapb.TabletType(m.GetInt("original_tablet_type")),
+			StartTimestamp:     m.GetString("start_timestamp"),
+			EndTimestamp:       m.GetString("end_timestamp"),
+			IsSuccessful:       m.GetBool("is_successful"),
+			Message:            m.GetString("message"),
+		})
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return res, err
+}
+
+// countReplicaRebuilds returns the number of replica rebuilds of the given shard that are still running,
+// and the number of those that were started less than the minimum interval between rebuilds ago.
+// Rebuilds that were started longer than the rebuild timeout ago are not running anymore, even if their
+// end was never recorded, e.g. because VTOrc restarted.
+func countReplicaRebuilds(keyspace string, shard string) (running int, recent int, err error) {
+	query := `SELECT
+			IFNULL(
+				SUM(
+					end_timestamp IS NULL
+					AND start_timestamp >= DATETIME('now', PRINTF('-%d SECOND', ?))
+				),
+				0
+			) AS running_count,
+			IFNULL(
+				SUM(
+					start_timestamp >= DATETIME('now', PRINTF('-%d SECOND', ?))
+				),
+				0
+			) AS recent_count
+		FROM
+			replica_rebuild
+		WHERE
+			keyspace = ?
+			AND shard = ?
+		`
+	args := sqlutils.Args(int(config.GetReplicaRebuildTimeout().Seconds()), int(config.GetReplicaRebuildMinInterval().Seconds()), keyspace, shard)
+	err = db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		running = m.GetInt("running_count")
+		recent = m.GetInt("recent_count")
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return running, recent, err
+}
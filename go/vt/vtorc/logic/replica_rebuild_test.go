@@ -0,0 +1,265 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/log"
+	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/policy"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestCheckReplicaRebuildBudget(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	oldMaxConcurrent := config.GetReplicaRebuildMaxConcurrent()
+	oldMinInterval := config.GetReplicaRebuildMinInterval()
+	defer func() {
+		config.SetReplicaRebuildMaxConcurrent(oldMaxConcurrent)
+		config.SetReplicaRebuildMinInterval(oldMinInterval)
+	}()
+	config.SetReplicaRebuildMaxConcurrent(1)
+	config.SetReplicaRebuildMinInterval(time.Hour)
+
+	// No rebuilds yet.
+	require.NoError(t, checkReplicaRebuildBudget("ks", "0"))
+
+	rebuild := &ReplicaRebuild{
+		TabletAlias:        "zone1-0000000101",
+		Keyspace:           "ks",
+		Shard:              "0",
+		Analysis:           inst.UnrecoverableReplicationError,
+		OriginalTabletType: topodatapb.TabletType_REPLICA,
+	}
+	require.NoError(t, writeReplicaRebuildStart(rebuild))
+	require.ErrorContains(t, checkReplicaRebuildBudget("ks", "0"), "1 replica rebuilds are running in ks/0, the maximum is 1")
+	// Other shards have their own budget.
+	require.NoError(t, checkReplicaRebuildBudget("ks", "-80"))
+
+	config.SetReplicaRebuildMaxConcurrent(2)
+	require.ErrorContains(t, checkReplicaRebuildBudget("ks", "0"), "a replica rebuild was started in ks/0 less than 1h0m0s ago")
+
+	config.SetReplicaRebuildMinInterval(0)
+	require.NoError(t, checkReplicaRebuildBudget("ks", "0"))
+
+	rebuild.IsSuccessful = true
+	require.NoError(t, writeReplicaRebuildEnd(rebuild))
+	config.SetReplicaRebuildMaxConcurrent(1)
+	require.NoError(t, checkReplicaRebuildBudget("ks", "0"))
+}
+
+func TestReserveReplicaRebuildConcurrently(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	oldMaxConcurrent := config.GetReplicaRebuildMaxConcurrent()
+	oldMinInterval := config.GetReplicaRebuildMinInterval()
+	defer func() {
+		config.SetReplicaRebuildMaxConcurrent(oldMaxConcurrent)
+		config.SetReplicaRebuildMinInterval(oldMinInterval)
+	}()
+	config.SetReplicaRebuildMaxConcurrent(1)
+	config.SetReplicaRebuildMinInterval(0)
+
+	// The recoveries of the replicas of a shard run concurrently, and only one of them gets the budget.
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = reserveReplicaRebuild(&ReplicaRebuild{
+				TabletAlias:        fmt.Sprintf("zone1-%010d", 101+i),
+				Keyspace:           "ks",
+				Shard:              "0",
+				Analysis:           inst.UnrecoverableReplicationError,
+				OriginalTabletType: topodatapb.TabletType_REPLICA,
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	reserved := 0
+	for _, err := range errs {
+		if err == nil {
+			reserved++
+			continue
+		}
+		require.ErrorContains(t, err, "1 replica rebuilds are running in ks/0, the maximum is 1")
+	}
+	require.Equal(t, 1, reserved)
+	running, _, err := countReplicaRebuilds("ks", "0")
+	require.NoError(t, err)
+	require.Equal(t, 1, running)
+}
+
+func TestRebuildReplica(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	oldTs, oldTmc := ts, tmc
+	oldPollInterval := replicaCatchUpPollInterval
+	defer func() {
+		ts, tmc = oldTs, oldTmc
+		replicaCatchUpPollInterval = oldPollInterval
+	}()
+	replicaCatchUpPollInterval = 10 * time.Millisecond
+	ts = memorytopo.NewServer(ctx, "zone1")
+	fakeTmc := &testutil.TabletManagerClient{
+		TopoServer: ts,
+		RestoreFromBackupResults: map[string]struct {
+			Events        []*logutilpb.Event
+			EventInterval time.Duration
+			EventJitter   time.Duration
+			ErrorAfter    time.Duration
+		}{
+			"zone1-0000000101": {
+				Events:        []*logutilpb.Event{{Value: "restoring"}, {Value: "restored"}},
+				EventInterval: time.Millisecond,
+				EventJitter:   time.Millisecond,
+			},
+		},
+		ReplicationStatusResults: map[string]struct {
+			Position *replicationdatapb.Status
+			Error    error
+		}{
+			"zone1-0000000101": {
+				Position: &replicationdatapb.Status{
+					IoState:               int32(replication.ReplicationStateRunning),
+					SqlState:              int32(replication.ReplicationStateRunning),
+					ReplicationLagSeconds: 1,
+				},
+			},
+		},
+	}
+	tmc = fakeTmc
+
+	keyspaceInfo := &topo.KeyspaceInfo{
+		Keyspace: &topodatapb.Keyspace{
+			DurabilityPolicy: policy.DurabilityNone,
+		},
+	}
+	keyspaceInfo.SetKeyspaceName("ks")
+	require.NoError(t, inst.SaveKeyspace(keyspaceInfo))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+
+	primary := &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname:      "localhost",
+		MysqlHostname: "localhost",
+		MysqlPort:     1200,
+		Keyspace:      "ks",
+		Shard:         "0",
+		Type:          topodatapb.TabletType_PRIMARY,
+	}
+	replica := &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Hostname:      "localhost",
+		MysqlHostname: "localhost",
+		MysqlPort:     1201,
+		Keyspace:      "ks",
+		Shard:         "0",
+		Type:          topodatapb.TabletType_RDONLY,
+	}
+	for _, tablet := range []*topodatapb.Tablet{primary, replica} {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+		require.NoError(t, inst.SaveTablet(tablet))
+	}
+
+	analysisEntry := &inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: topoproto.TabletAliasString(replica.Alias),
+		AnalyzedKeyspace:      "ks",
+		AnalyzedShard:         "0",
+		Analysis:              inst.UnrecoverableReplicationError,
+		ClusterDetails: inst.ClusterInfo{
+			Keyspace: "ks",
+			Shard:    "0",
+		},
+	}
+	recoveryAttempted, topologyRecovery, err := rebuildReplica(ctx, analysisEntry, log.NewPrefixedLogger("prefix"))
+	require.NoError(t, err)
+	require.True(t, recoveryAttempted)
+	require.NotNil(t, topologyRecovery)
+
+	require.Eventually(t, func() bool {
+		rebuilds, err := readReplicaRebuilds("ks", "0")
+		require.NoError(t, err)
+		require.Len(t, rebuilds, 1)
+		return rebuilds[0].EndTimestamp != ""
+	}, 5*time.Second, 10*time.Millisecond)
+
+	rebuilds, err := readReplicaRebuilds("ks", "0")
+	require.NoError(t, err)
+	require.True(t, rebuilds[0].IsSuccessful, rebuilds[0].Message)
+	require.Equal(t, "zone1-0000000101", rebuilds[0].TabletAlias)
+	require.Equal(t, topodatapb.TabletType_RDONLY, rebuilds[0].OriginalTabletType)
+	tablet, err := ts.GetTablet(ctx, replica.Alias)
+	require.NoError(t, err)
+	require.Equal(t, topodatapb.TabletType_RDONLY, tablet.Type)
+
+	// A replica that lags more than the reasonable replication lag hasn't caught up.
+	fakeTmc.ReplicationStatusResults["zone1-0000000101"].Position.ReplicationLagSeconds = uint32(config.GetReasonableReplicationLagSeconds() + 1)
+	caughtUp, err := replicaCaughtUp(ctx, replica)
+	require.NoError(t, err)
+	require.False(t, caughtUp)
+
+	// A rebuild whose context is canceled, because VTOrc shuts down, fails and leaves the replica DRAINED.
+	_, err = ts.UpdateTabletFields(ctx, replica.Alias, func(tablet *topodatapb.Tablet) error {
+		tablet.Type = topodatapb.TabletType_DRAINED
+		return nil
+	})
+	require.NoError(t, err)
+	rebuild := &ReplicaRebuild{
+		TabletAlias:        "zone1-0000000101",
+		Keyspace:           "ks",
+		Shard:              "0",
+		Analysis:           inst.UnrecoverableReplicationError,
+		OriginalTabletType: topodatapb.TabletType_RDONLY,
+	}
+	require.NoError(t, writeReplicaRebuildStart(rebuild))
+	canceledCtx, cancelRebuild := context.WithCancel(ctx)
+	cancelRebuild()
+	runReplicaRebuild(canceledCtx, rebuild, replica, false)
+	rebuilds, err = readReplicaRebuilds("ks", "0")
+	require.NoError(t, err)
+	require.Len(t, rebuilds, 2)
+	require.NotEmpty(t, rebuilds[0].EndTimestamp)
+	require.False(t, rebuilds[0].IsSuccessful)
+	require.Contains(t, rebuilds[0].Message, context.Canceled.Error())
+	tablet, err = ts.GetTablet(ctx, replica.Alias)
+	require.NoError(t, err)
+	require.Equal(t, topodatapb.TabletType_DRAINED, tablet.Type)
+}
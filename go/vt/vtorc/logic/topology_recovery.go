@@ -22,13 +22,16 @@ import (
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
@@ -48,6 +51,8 @@ const (
 	FixPrimaryRecoveryName                           string = "FixPrimary"
 	FixReplicaRecoveryName                           string = "FixReplica"
 	RecoverErrantGTIDDetectedName                    string = "RecoverErrantGTIDDetected"
+	RecoverUnreachablePrimaryRecoveryName            string = "RecoverUnreachablePrimary"
+	RebuildReplicaRecoveryName                       string = "RebuildReplica"
 )
 
 var (
@@ -57,6 +62,8 @@ var (
 		ElectNewPrimaryRecoveryName,
 		FixPrimaryRecoveryName,
 		FixReplicaRecoveryName,
+		RecoverUnreachablePrimaryRecoveryName,
+		RebuildReplicaRecoveryName,
 	}
 
 	countPendingRecoveries = stats.NewGauge("PendingRecoveries", "Count of the number of pending recoveries")
@@ -105,6 +112,8 @@ const (
 	fixPrimaryFunc
 	fixReplicaFunc
 	recoverErrantGTIDDetectedFunc
+	recoverUnreachablePrimaryFunc
+	rebuildReplicaFunc
 )
 
 // TopologyRecovery represents an entry in the topology_recovery table
@@ -220,7 +229,11 @@ func resolveRecovery(topologyRecovery *TopologyRecovery, successorInstance *inst
 		topologyRecovery.SuccessorAlias = successorInstance.InstanceAlias
 		topologyRecovery.IsSuccessful = true
 	}
-	return writeResolveRecovery(topologyRecovery)
+	if err := writeResolveRecovery(topologyRecovery); err != nil {
+		return err
+	}
+	go exportRecovery(topologyRecovery.ID)
+	return nil
 }
 
 // recoverPrimaryHasPrimary resets the replication on the primary instance
@@ -252,8 +265,8 @@ func recoverPrimaryHasPrimary(ctx context.Context, analysisEntry *inst.Replicati
 }
 
 // runEmergencyReparentOp runs a recovery for which we have to run ERS. Here waitForAllTablets is a boolean telling ERS whether it should wait for all the tablets
-// or is it okay to skip 1.
-func runEmergencyReparentOp(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, recoveryName string, waitForAllTablets bool, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+// or is it okay to skip 1. If fencePrimary is set, the analyzed primary is fenced before running ERS.
+func runEmergencyReparentOp(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, recoveryName string, waitForAllTablets bool, fencePrimary bool, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
 	// Read the tablet information from the database to find the shard and keyspace of the tablet
 	tablet, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
 	if err != nil {
@@ -276,6 +289,12 @@ func runEmergencyReparentOp(ctx context.Context, analysisEntry *inst.Replication
 		_ = resolveRecovery(topologyRecovery, promotedReplica)
 	}()
 
+	if fencePrimary {
+		if err := fenceUnreachablePrimary(ctx, tablet, topologyRecovery, logger); err != nil {
+			return false, topologyRecovery, err
+		}
+	}
+
 	ev, err := reparentutil.NewEmergencyReparenter(ts, tmc, logutil.NewCallbackLogger(func(event *logutilpb.Event) {
 		level := event.GetLevel()
 		value := event.GetValue()
@@ -313,12 +332,171 @@ func runEmergencyReparentOp(ctx context.Context, analysisEntry *inst.Replication
 // recoverDeadPrimary checks a given analysis, decides whether to take action, and possibly takes action
 // Returns true when action was taken.
 func recoverDeadPrimary(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
-	return runEmergencyReparentOp(ctx, analysisEntry, "RecoverDeadPrimary", false, logger)
+	return runEmergencyReparentOp(ctx, analysisEntry, "RecoverDeadPrimary", false, false, logger)
 }
 
 // recoverPrimaryTabletDeleted tries to run a recovery for the case where the primary tablet has been deleted.
 func recoverPrimaryTabletDeleted(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
-	return runEmergencyReparentOp(ctx, analysisEntry, "PrimaryTabletDeleted", true, logger)
+	return runEmergencyReparentOp(ctx, analysisEntry, "PrimaryTabletDeleted", true, false, logger)
+}
+
+// recoverUnreachablePrimary runs a recovery for a primary that VTOrc can't reach. It only fails over
+// the primary if a quorum of its replicas, as defined by the durability policy, has lost its replication
+// connection to the primary too. The primary might still be accepting writes, so it is fenced before ERS is run.
+func recoverUnreachablePrimary(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, logger *log.PrefixedLogger) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+	primary, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		logger.Errorf("Failed to read instance %s, aborting recovery", analysisEntry.AnalyzedInstanceAlias)
+		return false, nil, err
+	}
+	lost, err := replicasLostPrimary(ctx, primary, logger)
+	if err != nil {
+		logger.Errorf("Failed to find out if the replicas have lost primary %s, aborting recovery: %v", analysisEntry.AnalyzedInstanceAlias, err)
+		return false, nil, err
+	}
+	if !lost {
+		logger.Infof("Not enough replicas have lost their connection to primary %s, not recovering", analysisEntry.AnalyzedInstanceAlias)
+		return false, nil, nil
+	}
+	return runEmergencyReparentOp(ctx, analysisEntry, RecoverUnreachablePrimaryRecoveryName, false, true, logger)
+}
+
+// replicasLostPrimary returns true if a quorum of the replicas of the given primary, as defined by the
+// durability policy of its keyspace, has lost its replication connection to it. The replication status of
+// the replicas is read from their tablet managers, since VTOrc itself can't reach the primary.
+func replicasLostPrimary(ctx context.Context, primary *topodatapb.Tablet, logger *log.PrefixedLogger) (bool, error) {
+	durability, err := inst.GetDurabilityPolicy(primary.Keyspace)
+	if err != nil {
+		return false, err
+	}
+	replicas, err := shardReplicas(ctx, primary)
+	if err != nil {
+		return false, err
+	}
+	voters, quorum := policy.PrimaryLossQuorum(durability, primary, replicas)
+	if len(voters) == 0 {
+		logger.Infof("No replicas can vote on whether primary %s is lost", topoproto.TabletAliasString(primary.Alias))
+		return false, nil
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, config.GetWaitReplicasTimeout())
+	defer cancel()
+	var lostCount atomic.Int32
+	var wg sync.WaitGroup
+	for _, voter := range voters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := tmc.ReplicationStatus(statusCtx, voter)
+			if err != nil {
+				// A replica we can't reach has no say.
+				logger.Warningf("Failed to get the replication status of %s: %v", topoproto.TabletAliasString(voter.Alias), err)
+				return
+			}
+			replicationStatus := replication.ProtoToReplicationStatus(status)
+			// A replica whose IO thread was stopped without an error has not lost the primary.
+			if !replicationStatus.IOHealthy() && replicationStatus.LastIOError != "" {
+				lostCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	logger.Infof("%d of %d replicas have lost their connection to primary %s, %d are needed", lostCount.Load(), len(voters), topoproto.TabletAliasString(primary.Alias), quorum)
+	return int(lostCount.Load()) >= quorum, nil
+}
+
+// shardReplicas returns the REPLICA and RDONLY tablets of the shard of the given primary.
+func shardReplicas(ctx context.Context, primary *topodatapb.Tablet) ([]*topodatapb.Tablet, error) {
+	tabletMap, err := ts.GetTabletMapForShard(ctx, primary.Keyspace, primary.Shard)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []*topodatapb.Tablet
+	for _, tabletInfo := range tabletMap {
+		if topoproto.TabletAliasEqual(tabletInfo.Alias, primary.Alias) {
+			continue
+		}
+		if tabletInfo.Type != topodatapb.TabletType_REPLICA && tabletInfo.Type != topodatapb.TabletType_RDONLY {
+			continue
+		}
+		replicas = append(replicas, tabletInfo.Tablet)
+	}
+	return replicas, nil
+}
+
+// fenceUnreachablePrimary makes sure that the given primary stops accepting writes before it is replaced.
+// If the primary requires semi-sync acks, the IO thread of the replicas that can ack its writes is stopped,
+// as ERS does, and the primary is fenced once too few of them are left to ack a write. The primary is also
+// demoted, which usually fails since VTOrc can't reach it, and only fences a primary without semi-sync.
+// If the primary can't be fenced, it may still be accepting writes that would be lost by the failover, so
+// an error is returned to abort the recovery, unless --allow-unfenced-primary-recovery is set. In that case
+// the failure is audited and the recovery goes on: when the old primary reconnects to the topo server, it
+// sees that it has been replaced and demotes itself.
+func fenceUnreachablePrimary(ctx context.Context, primary *topodatapb.Tablet, topologyRecovery *TopologyRecovery, logger *log.PrefixedLogger) error {
+	primaryAlias := topoproto.TabletAliasString(primary.Alias)
+	durability, err := inst.GetDurabilityPolicy(primary.Keyspace)
+	if err != nil {
+		return err
+	}
+	var ackers []*topodatapb.Tablet
+	var quorum int
+	if policy.SemiSyncAckers(durability, primary) > 0 {
+		replicas, err := shardReplicas(ctx, primary)
+		if err != nil {
+			return err
+		}
+		ackers, quorum = policy.PrimaryLossQuorum(durability, primary, replicas)
+	}
+
+	fenceCtx, cancel := context.WithTimeout(ctx, config.GetWaitReplicasTimeout())
+	defer cancel()
+	var stoppedCount atomic.Int32
+	var demoteErr error
+	var wg sync.WaitGroup
+	for _, acker := range ackers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tmc.StopReplicationAndGetStatus(fenceCtx, acker, replicationdatapb.StopReplicationMode_IOTHREADONLY); err != nil {
+				logger.Warningf("Failed to stop replication on %s: %v", topoproto.TabletAliasString(acker.Alias), err)
+				return
+			}
+			stoppedCount.Add(1)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, demoteErr = tmc.DemotePrimary(fenceCtx, primary)
+	}()
+	wg.Wait()
+
+	switch {
+	case len(ackers) > 0 && int(stoppedCount.Load()) >= quorum:
+		_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("fenced primary %s by stopping replication on %d of its %d semi-sync replicas", primaryAlias, stoppedCount.Load(), len(ackers)))
+		if demoteErr != nil {
+			logger.Infof("Failed to demote primary %s, which is fenced: %v", primaryAlias, demoteErr)
+		}
+		return nil
+	case demoteErr == nil:
+		_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("fenced primary %s", primaryAlias))
+		return nil
+	}
+	err = demoteErr
+	if len(ackers) > 0 {
+		err = fmt.Errorf("stopped replication on %d of its semi-sync replicas, %d are needed, and failed to demote it: %w", stoppedCount.Load(), quorum, demoteErr)
+	}
+	if !config.GetAllowUnfencedPrimaryRecovery() {
+		message := fmt.Sprintf("failed to fence primary %s, aborting the failover: %v", primaryAlias, err)
+		logger.Error(message)
+		_ = AuditTopologyRecovery(topologyRecovery, message)
+		return fmt.Errorf("failed to fence primary %s: %w", primaryAlias, err)
+	}
+	message := fmt.Sprintf("failed to fence primary %s, going on with the failover: %v", primaryAlias, err)
+	logger.Warning(message)
+	_ = AuditTopologyRecovery(topologyRecovery, message)
+	return nil
 }
 
 func postErsCompletion(topologyRecovery *TopologyRecovery, analysisEntry *inst.ReplicationAnalysis, recoveryName string, promotedReplica *inst.Instance) {
@@ -361,6 +539,10 @@ func getCheckAndRecoverFunctionCode(analysisCode inst.AnalysisCode, tabletAlias
 		}
 		return recoverPrimaryTabletDeletedFunc
 	case inst.ErrantGTIDDetected:
+		// Rebuilding the replica from a backup gets rid of the errant GTIDs.
+		if config.GetReplicaRebuild() {
+			return rebuildReplicaFunc
+		}
 		if !config.ConvertTabletWithErrantGTIDs() {
 			log.Infof("VTOrc not configured to do anything on detecting errant GTIDs, skipping recovering %v", analysisCode)
 			return noRecoveryFunc
@@ -378,13 +560,21 @@ func getCheckAndRecoverFunctionCode(analysisCode inst.AnalysisCode, tabletAlias
 	case inst.NotConnectedToPrimary, inst.ConnectedToWrongPrimary, inst.ReplicationStopped, inst.ReplicaIsWritable,
 		inst.ReplicaSemiSyncMustBeSet, inst.ReplicaSemiSyncMustNotBeSet, inst.ReplicaMisconfigured:
 		return fixReplicaFunc
+	case inst.UnrecoverableReplicationError:
+		// Unless configured to rebuild the replica, we try restarting replication as we always did.
+		if !config.GetReplicaRebuild() {
+			return fixReplicaFunc
+		}
+		return rebuildReplicaFunc
+	case inst.UnreachablePrimary, inst.UnreachablePrimaryWithLaggingReplicas:
+		// Unless configured to, we only record the problem.
+		if !config.GetUnreachablePrimaryRecovery() || !config.ERSEnabled() {
+			return recoverGenericProblemFunc
+		}
+		return recoverUnreachablePrimaryFunc
 	// primary, non actionable
 	case inst.DeadPrimaryAndReplicas:
 		return recoverGenericProblemFunc
-	case inst.UnreachablePrimary:
-		return recoverGenericProblemFunc
-	case inst.UnreachablePrimaryWithLaggingReplicas:
-		return recoverGenericProblemFunc
 	case inst.AllPrimaryReplicasNotReplicating:
 		return recoverGenericProblemFunc
 	case inst.AllPrimaryReplicasNotReplicatingOrDead:
@@ -421,6 +611,10 @@ func hasActionableRecovery(recoveryFunctionCode recoveryFunction) bool {
 		return true
 	case recoverErrantGTIDDetectedFunc:
 		return true
+	case recoverUnreachablePrimaryFunc:
+		return true
+	case rebuildReplicaFunc:
+		return true
 	default:
 		return false
 	}
@@ -451,6 +645,10 @@ func getCheckAndRecoverFunction(recoveryFunctionCode recoveryFunction) (
 		return fixReplica
 	case recoverErrantGTIDDetectedFunc:
 		return recoverErrantGTIDDetected
+	case recoverUnreachablePrimaryFunc:
+		return recoverUnreachablePrimary
+	case rebuildReplicaFunc:
+		return rebuildReplica
 	default:
 		return nil
 	}
@@ -480,6 +678,10 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 		return FixReplicaRecoveryName
 	case recoverErrantGTIDDetectedFunc:
 		return RecoverErrantGTIDDetectedName
+	case recoverUnreachablePrimaryFunc:
+		return RecoverUnreachablePrimaryRecoveryName
+	case rebuildReplicaFunc:
+		return RebuildReplicaRecoveryName
 	default:
 		return ""
 	}
@@ -488,7 +690,7 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 // isClusterWideRecovery returns whether the given recovery is a cluster-wide recovery or not
 func isClusterWideRecovery(recoveryFunctionCode recoveryFunction) bool {
 	switch recoveryFunctionCode {
-	case recoverDeadPrimaryFunc, electNewPrimaryFunc, recoverPrimaryTabletDeletedFunc:
+	case recoverDeadPrimaryFunc, electNewPrimaryFunc, recoverPrimaryTabletDeletedFunc, recoverUnreachablePrimaryFunc:
 		return true
 	default:
 		return false
@@ -502,6 +704,22 @@ func analysisEntriesHaveSameRecovery(prevAnalysis, newAnalysis *inst.Replication
 	return prevRecoveryFunctionCode == newRecoveryFunctionCode
 }
 
+// isRecoveryDisabledForAnalysisEntry returns true if recoveries are disabled for the keyspace,
+// shard or analysis code of the given analysis entry.
+func isRecoveryDisabledForAnalysisEntry(analysisEntry *inst.ReplicationAnalysis, logger *log.PrefixedLogger) bool {
+	disabled, err := IsRecoveryDisabledForAnalysis(analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard, analysisEntry.Analysis)
+	if err != nil {
+		// Unexpected. Shouldn't get this
+		logger.Errorf("Unable to determine if recovery is disabled for the keyspace or shard, still attempting to recover: %v", err)
+		return false
+	}
+	if disabled {
+		logger.Infof("CheckAndRecover: Tablet: %+v: NOT Recovering host (disabled for %s/%s)",
+			analysisEntry.AnalyzedInstanceAlias, analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard)
+	}
+	return disabled
+}
+
 // executeCheckAndRecoverFunction will choose the correct check & recovery function based on analysis.
 // It executes the function synchronously
 func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (err error) {
@@ -551,6 +769,11 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 		return err
 	}
 
+	// Check for recovery being disabled for the keyspace, shard or analysis
+	if isRecoveryDisabledForAnalysisEntry(analysisEntry, logger) {
+		return nil
+	}
+
 	// We lock the shard here and then refresh the tablets information
 	ctx, unlock, err := LockShard(context.Background(), analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard,
 		getLockAction(analysisEntry.AnalyzedInstanceAlias, analysisEntry.Analysis),
@@ -577,12 +800,16 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 			logger.Errorf("Failed to refresh keyspace and shard, aborting recovery: %v", err)
 			return err
 		}
+		// The refreshed keyspace record could have disabled recoveries since we last checked.
+		if isRecoveryDisabledForAnalysisEntry(analysisEntry, logger) {
+			return nil
+		}
 		// If we are about to run a cluster-wide recovery, it is imperative to first refresh all the tablets
 		// of a shard because a new tablet could have been promoted, and we need to have this visibility before we
 		// run a cluster operation of our own.
 		if isClusterWideRecovery(checkAndRecoverFunctionCode) {
 			var tabletsToIgnore []string
-			if checkAndRecoverFunctionCode == recoverDeadPrimaryFunc {
+			if checkAndRecoverFunctionCode == recoverDeadPrimaryFunc || checkAndRecoverFunctionCode == recoverUnreachablePrimaryFunc {
 				tabletsToIgnore = append(tabletsToIgnore, analysisEntry.AnalyzedInstanceAlias)
 			}
 			// We ignore the dead primary tablet because it is going to be unreachable. If all the other tablets aren't able to reach this tablet either,
@@ -76,7 +76,7 @@ func writeTopologyRecovery(topologyRecovery *TopologyRecovery) (*TopologyRecover
 		) VALUES (
 			?,
 			?,
-			DATETIME('now'),
+			STRFTIME('%Y-%m-%d %H:%M:%f', 'now'),
 			?,
 			?,
 			?,
@@ -87,7 +87,6 @@ func writeTopologyRecovery(topologyRecovery *TopologyRecovery) (*TopologyRecover
 		string(analysisEntry.Analysis),
 		analysisEntry.ClusterDetails.Keyspace,
 		analysisEntry.ClusterDetails.Shard,
-		analysisEntry.AnalyzedInstanceAlias,
 		analysisEntry.RecoveryId,
 	)
 	if err != nil {
@@ -140,7 +139,7 @@ func writeResolveRecovery(topologyRecovery *TopologyRecovery) error {
 			is_successful = ?,
 			successor_alias = ?,
 			all_errors = ?,
-			end_recovery = DATETIME('now')
+			end_recovery = STRFTIME('%Y-%m-%d %H:%M:%f', 'now')
 		WHERE
 			recovery_id = ?
 		`,
@@ -241,7 +240,7 @@ func writeTopologyRecoveryStep(topologyRecoveryStep *TopologyRecoveryStep) error
 		) VALUES (
 			?,
 			?,
-			DATETIME('now'),
+			STRFTIME('%Y-%m-%d %H:%M:%f', 'now'),
 			?
 		)`,
 		sqlutils.NilIfZero(topologyRecoveryStep.ID),
@@ -273,3 +272,116 @@ func ExpireTopologyRecoveryHistory() error {
 func ExpireTopologyRecoveryStepsHistory() error {
 	return inst.ExpireTableData("topology_recovery_steps", "audit_at")
 }
+
+// readTopologyRecoverySteps reads the steps of the recoveries matching the given WHERE clause,
+// which can be followed by ORDER BY and LIMIT clauses, in the order they were taken, keyed by recovery ID.
+func readTopologyRecoverySteps(whereClause string, args []any) (map[int64][]*TopologyRecoveryStep, error) {
+	res := make(map[int64][]*TopologyRecoveryStep)
+	query := fmt.Sprintf(`SELECT
+			recovery_step_id,
+			recovery_id,
+			audit_at,
+			message
+		FROM
+			topology_recovery_steps
+		WHERE
+			recovery_id IN (
+				SELECT
+					recovery_id
+				FROM
+					topology_recovery
+				%s
+			)
+		ORDER BY recovery_step_id ASC
+		`,
+		whereClause,
+	)
+	err := db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		step := &TopologyRecoveryStep{
+			ID:         m.GetInt64("recovery_step_id"),
+			RecoveryID: m.GetInt64("recovery_id"),
+			AuditAt:    m.GetString("audit_at"),
+			Message:    m.GetString("message"),
+		}
+		res[step.RecoveryID] = append(res[step.RecoveryID], step)
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return res, err
+}
+
+// ReadDetections reads the problems detected by VTOrc that match the given filter, latest first,
+// along with the recoveries that were run for each of them. It reads at most the limit of the filter.
+func ReadDetections(filter *HistoryFilter) ([]*Detection, error) {
+	whereClause, args := filter.whereClause("detection_id", "detection_timestamp")
+	limitClause := filter.limitClause()
+	res := []*Detection{}
+	detectionsByID := make(map[int64]*Detection)
+	query := fmt.Sprintf(`SELECT
+			detection_id,
+			alias,
+			analysis,
+			keyspace,
+			shard,
+			detection_timestamp
+		FROM
+			recovery_detection
+		%s
+		ORDER BY detection_id DESC
+		%s
+		`,
+		whereClause,
+		limitClause,
+	)
+	err := db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		detection := &Detection{
+			DetectionID: m.GetInt64("detection_id"),
+			TabletAlias: m.GetString("alias"),
+			Analysis:    inst.AnalysisCode(m.GetString("analysis")),
+			Keyspace:    m.GetString("keyspace"),
+			Shard:       m.GetString("shard"),
+			Timestamp:   parseTimestamp(m.GetString("detection_timestamp")),
+			RecoveryIDs: []int64{},
+		}
+		res = append(res, detection)
+		detectionsByID[detection.DetectionID] = detection
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`SELECT
+			recovery_id,
+			detection_id
+		FROM
+			topology_recovery
+		WHERE
+			detection_id IN (
+				SELECT
+					detection_id
+				FROM
+					recovery_detection
+				%s
+				ORDER BY detection_id DESC
+				%s
+			)
+		ORDER BY recovery_id ASC
+		`,
+		whereClause,
+		limitClause,
+	)
+	err = db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		if detection, ok := detectionsByID[m.GetInt64("detection_id")]; ok {
+			detection.RecoveryIDs = append(detection.RecoveryIDs, m.GetInt64("recovery_id"))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return res, err
+}
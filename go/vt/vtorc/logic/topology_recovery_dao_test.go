@@ -50,6 +50,7 @@ func TestTopologyRecovery(t *testing.T) {
 		AnalyzedShard:    shard,
 		Analysis:         inst.ReplicaIsWritable,
 		IsReadOnly:       false,
+		RecoveryId:       7,
 	}
 	topologyRecovery := NewTopologyRecovery(replicationAnalysis)
 
@@ -67,6 +68,16 @@ func TestTopologyRecovery(t *testing.T) {
 		// Assert that the ID field matches the one that we just wrote
 		require.EqualValues(t, topologyRecovery.ID, recoveries[0].ID)
 	})
+
+	t.Run("detection id is persisted", func(t *testing.T) {
+		var detectionID int64
+		err := db.QueryVTOrc("select detection_id from topology_recovery where recovery_id = ?", sqlutils.Args(topologyRecovery.ID), func(row sqlutils.RowMap) error {
+			detectionID = row.GetInt64("detection_id")
+			return nil
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 7, detectionID)
+	})
 }
 
 func TestExpireTableData(t *testing.T) {
@@ -22,11 +22,17 @@ import (
 
 	"vitess.io/vitess/go/vt/log"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/replication"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/policy"
 	"vitess.io/vitess/go/vt/vtorc/config"
 	"vitess.io/vitess/go/vt/vtorc/db"
 	"vitess.io/vitess/go/vt/vtorc/inst"
@@ -218,6 +224,8 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 		name                         string
 		ersEnabled                   bool
 		convertTabletWithErrantGTIDs bool
+		unreachablePrimaryRecovery   bool
+		replicaRebuild               bool
 		analysisCode                 inst.AnalysisCode
 		wantRecoveryFunction         recoveryFunction
 	}{
@@ -293,6 +301,43 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			convertTabletWithErrantGTIDs: false,
 			analysisCode:                 inst.ErrantGTIDDetected,
 			wantRecoveryFunction:         noRecoveryFunc,
+		}, {
+			name:                       "UnreachablePrimary with --enable-unreachable-primary-recovery",
+			ersEnabled:                 true,
+			unreachablePrimaryRecovery: true,
+			analysisCode:               inst.UnreachablePrimary,
+			wantRecoveryFunction:       recoverUnreachablePrimaryFunc,
+		}, {
+			name:                       "UnreachablePrimaryWithLaggingReplicas with --enable-unreachable-primary-recovery",
+			ersEnabled:                 true,
+			unreachablePrimaryRecovery: true,
+			analysisCode:               inst.UnreachablePrimaryWithLaggingReplicas,
+			wantRecoveryFunction:       recoverUnreachablePrimaryFunc,
+		}, {
+			name:                       "UnreachablePrimary with --enable-unreachable-primary-recovery and ERS disabled",
+			ersEnabled:                 false,
+			unreachablePrimaryRecovery: true,
+			analysisCode:               inst.UnreachablePrimary,
+			wantRecoveryFunction:       recoverGenericProblemFunc,
+		}, {
+			name:                 "UnreachablePrimary",
+			ersEnabled:           true,
+			analysisCode:         inst.UnreachablePrimary,
+			wantRecoveryFunction: recoverGenericProblemFunc,
+		}, {
+			name:                 "UnrecoverableReplicationError",
+			analysisCode:         inst.UnrecoverableReplicationError,
+			wantRecoveryFunction: fixReplicaFunc,
+		}, {
+			name:                 "UnrecoverableReplicationError with --enable-replica-rebuild",
+			replicaRebuild:       true,
+			analysisCode:         inst.UnrecoverableReplicationError,
+			wantRecoveryFunction: rebuildReplicaFunc,
+		}, {
+			name:                 "ErrantGTIDDetected with --enable-replica-rebuild",
+			replicaRebuild:       true,
+			analysisCode:         inst.ErrantGTIDDetected,
+			wantRecoveryFunction: rebuildReplicaFunc,
 		},
 	}
 
@@ -306,8 +351,258 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			config.SetConvertTabletWithErrantGTIDs(tt.convertTabletWithErrantGTIDs)
 			defer config.SetConvertTabletWithErrantGTIDs(convertErrantVal)
 
+			unreachablePrimaryVal := config.GetUnreachablePrimaryRecovery()
+			config.SetUnreachablePrimaryRecovery(tt.unreachablePrimaryRecovery)
+			defer config.SetUnreachablePrimaryRecovery(unreachablePrimaryVal)
+
+			replicaRebuildVal := config.GetReplicaRebuild()
+			config.SetReplicaRebuild(tt.replicaRebuild)
+			defer config.SetReplicaRebuild(replicaRebuildVal)
+
 			gotFunc := getCheckAndRecoverFunctionCode(tt.analysisCode, "")
 			require.EqualValues(t, tt.wantRecoveryFunction, gotFunc)
 		})
 	}
 }
+
+func TestReplicasLostPrimary(t *testing.T) {
+	lostStatus := &replicationdatapb.Status{
+		IoState:     int32(replication.ReplicationStateConnecting),
+		LastIoError: "error reconnecting to source",
+	}
+	healthyStatus := &replicationdatapb.Status{
+		IoState: int32(replication.ReplicationStateRunning),
+	}
+	stoppedStatus := &replicationdatapb.Status{
+		IoState: int32(replication.ReplicationStateStopped),
+	}
+	type statusResult = struct {
+		Position *replicationdatapb.Status
+		Error    error
+	}
+
+	tests := []struct {
+		name       string
+		durability string
+		statuses   map[string]statusResult
+		wantLost   bool
+	}{
+		{
+			name:       "Majority of replicas lost the primary",
+			durability: policy.DurabilityNone,
+			statuses: map[string]statusResult{
+				"zone1-0000000101": {Position: lostStatus},
+				"zone1-0000000102": {Position: lostStatus},
+				"zone1-0000000103": {Position: healthyStatus},
+			},
+			wantLost: true,
+		}, {
+			name:       "Minority of replicas lost the primary",
+			durability: policy.DurabilityNone,
+			statuses: map[string]statusResult{
+				"zone1-0000000101": {Position: lostStatus},
+				"zone1-0000000102": {Position: healthyStatus},
+				"zone1-0000000103": {Position: healthyStatus},
+			},
+			wantLost: false,
+		}, {
+			name:       "Stopped replication and unreachable replicas don't vote",
+			durability: policy.DurabilityNone,
+			statuses: map[string]statusResult{
+				"zone1-0000000101": {Position: lostStatus},
+				"zone1-0000000102": {Position: stoppedStatus},
+				"zone1-0000000103": {Error: assert.AnError},
+			},
+			wantLost: false,
+		}, {
+			name:       "RDONLY replicas don't vote with semi-sync durability",
+			durability: policy.DurabilitySemiSync,
+			statuses: map[string]statusResult{
+				"zone1-0000000101": {Position: lostStatus},
+				"zone1-0000000102": {Position: healthyStatus},
+				"zone1-0000000103": {Position: lostStatus},
+			},
+			wantLost: false,
+		}, {
+			name:       "Semi-sync replicas lost the primary",
+			durability: policy.DurabilitySemiSync,
+			statuses: map[string]statusResult{
+				"zone1-0000000101": {Position: lostStatus},
+				"zone1-0000000102": {Position: lostStatus},
+				"zone1-0000000103": {Position: healthyStatus},
+			},
+			wantLost: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			oldTs, oldTmc := ts, tmc
+			defer func() {
+				ts, tmc = oldTs, oldTmc
+				db.ClearVTOrcDatabase()
+			}()
+			ts = memorytopo.NewServer(ctx, "zone1")
+			tmc = &testutil.TabletManagerClient{
+				ReplicationStatusResults: tt.statuses,
+			}
+
+			keyspaceInfo := &topo.KeyspaceInfo{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: tt.durability,
+				},
+			}
+			keyspaceInfo.SetKeyspaceName("ks")
+			require.NoError(t, inst.SaveKeyspace(keyspaceInfo))
+
+			primary := &topodatapb.Tablet{
+				Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+				Keyspace: "ks",
+				Shard:    "0",
+				Type:     topodatapb.TabletType_PRIMARY,
+			}
+			tablets := []*topodatapb.Tablet{primary}
+			for uid := uint32(101); uid <= 103; uid++ {
+				tabletType := topodatapb.TabletType_REPLICA
+				if uid == 103 {
+					tabletType = topodatapb.TabletType_RDONLY
+				}
+				tablets = append(tablets, &topodatapb.Tablet{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uid},
+					Keyspace: "ks",
+					Shard:    "0",
+					Type:     tabletType,
+				})
+			}
+			require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+			require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+			for _, tablet := range tablets {
+				require.NoError(t, ts.CreateTablet(ctx, tablet))
+			}
+
+			lost, err := replicasLostPrimary(ctx, primary, log.NewPrefixedLogger("prefix"))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLost, lost)
+		})
+	}
+}
+
+func TestFenceUnreachablePrimary(t *testing.T) {
+	type demoteResult = struct {
+		Status *replicationdatapb.PrimaryStatus
+		Error  error
+	}
+	type stopResult = struct {
+		StopStatus *replicationdatapb.StopReplicationStatus
+		Error      error
+	}
+
+	tests := []struct {
+		name           string
+		durability     string
+		demoteErr      error
+		stopResults    map[string]stopResult
+		allowUnfenced  bool
+		wantErrContain string
+	}{
+		{
+			name:       "Fenced primary",
+			durability: policy.DurabilityNone,
+		}, {
+			name:           "Unfenced primary aborts the recovery",
+			durability:     policy.DurabilityNone,
+			demoteErr:      assert.AnError,
+			wantErrContain: "failed to fence primary zone1-0000000100",
+		}, {
+			name:          "Unfenced primary with --allow-unfenced-primary-recovery",
+			durability:    policy.DurabilityNone,
+			demoteErr:     assert.AnError,
+			allowUnfenced: true,
+		}, {
+			// The failover goes on: the primary can't commit without the acks of its replicas.
+			name:       "Primary fenced by its semi-sync replicas when it can't be demoted",
+			durability: policy.DurabilitySemiSync,
+			demoteErr:  assert.AnError,
+			stopResults: map[string]stopResult{
+				"zone1-0000000101": {StopStatus: &replicationdatapb.StopReplicationStatus{}},
+				"zone1-0000000102": {StopStatus: &replicationdatapb.StopReplicationStatus{}},
+			},
+		}, {
+			name:       "Too few semi-sync replicas stopped",
+			durability: policy.DurabilitySemiSync,
+			demoteErr:  assert.AnError,
+			stopResults: map[string]stopResult{
+				"zone1-0000000101": {StopStatus: &replicationdatapb.StopReplicationStatus{}},
+				"zone1-0000000102": {Error: assert.AnError},
+			},
+			wantErrContain: "stopped replication on 1 of its semi-sync replicas, 2 are needed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			oldTs, oldTmc := ts, tmc
+			defer func() {
+				ts, tmc = oldTs, oldTmc
+				db.ClearVTOrcDatabase()
+			}()
+			ts = memorytopo.NewServer(ctx, "zone1")
+			tmc = &testutil.TabletManagerClient{
+				DemotePrimaryResults: map[string]demoteResult{
+					"zone1-0000000100": {Status: &replicationdatapb.PrimaryStatus{}, Error: tt.demoteErr},
+				},
+				StopReplicationAndGetStatusResults: tt.stopResults,
+			}
+			allowUnfenced := config.GetAllowUnfencedPrimaryRecovery()
+			config.SetAllowUnfencedPrimaryRecovery(tt.allowUnfenced)
+			defer config.SetAllowUnfencedPrimaryRecovery(allowUnfenced)
+
+			keyspaceInfo := &topo.KeyspaceInfo{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: tt.durability,
+				},
+			}
+			keyspaceInfo.SetKeyspaceName("ks")
+			require.NoError(t, inst.SaveKeyspace(keyspaceInfo))
+
+			primary := &topodatapb.Tablet{
+				Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+				Keyspace: "ks",
+				Shard:    "0",
+				Type:     topodatapb.TabletType_PRIMARY,
+			}
+			tablets := []*topodatapb.Tablet{primary}
+			for uid := uint32(101); uid <= 103; uid++ {
+				tabletType := topodatapb.TabletType_REPLICA
+				if uid == 103 {
+					tabletType = topodatapb.TabletType_RDONLY
+				}
+				tablets = append(tablets, &topodatapb.Tablet{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uid},
+					Keyspace: "ks",
+					Shard:    "0",
+					Type:     tabletType,
+				})
+			}
+			require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+			require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+			for _, tablet := range tablets {
+				require.NoError(t, ts.CreateTablet(ctx, tablet))
+			}
+
+			err := fenceUnreachablePrimary(ctx, primary, nil, log.NewPrefixedLogger("prefix"))
+			if tt.wantErrContain != "" {
+				require.ErrorContains(t, err, tt.wantErrContain)
+				require.ErrorIs(t, err, assert.AnError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
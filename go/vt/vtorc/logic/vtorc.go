@@ -50,6 +50,10 @@ var snapshotDiscoveryKeys chan string
 var snapshotDiscoveryKeysMutex sync.Mutex
 var hasReceivedSIGTERM int32
 
+// shutdownCtx is canceled when VTOrc shuts down. Operations that outlive the recovery that started them
+// derive their context from it.
+var shutdownCtx, cancelShutdownCtx = context.WithCancel(context.Background())
+
 var (
 	discoveriesCounter                 = stats.NewCounter("DiscoveriesAttempt", "Number of discoveries attempted")
 	failedDiscoveriesCounter           = stats.NewCounter("DiscoveriesFail", "Number of failed discoveries")
@@ -85,6 +89,7 @@ func init() {
 func closeVTOrc() {
 	log.Infof("Starting VTOrc shutdown")
 	atomic.StoreInt32(&hasReceivedSIGTERM, 1)
+	cancelShutdownCtx()
 	discoveryMetrics.StopAutoExpiration()
 	// Poke other go routines to stop cleanly here ...
 	_ = inst.AuditOperation("shutdown", "", "Triggered via SIGTERM")
@@ -19,6 +19,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -45,6 +46,11 @@ const (
 	errantGTIDsAPI                = "/api/errant-gtids"
 	disableGlobalRecoveriesAPI    = "/api/disable-global-recoveries"
 	enableGlobalRecoveriesAPI     = "/api/enable-global-recoveries"
+	disableRecoveriesAPI          = "/api/disable-recoveries"
+	enableRecoveriesAPI           = "/api/enable-recoveries"
+	recoveryDisablesAPI           = "/api/recovery-disables"
+	recoveriesAPI                 = "/api/recoveries"
+	detectionsAPI                 = "/api/detections"
 	replicationAnalysisAPI        = "/api/replication-analysis"
 	databaseStateAPI              = "/api/database-state"
 	configAPI                     = "/api/config"
@@ -53,6 +59,11 @@ const (
 
 	shardWithoutKeyspaceFilteringErrorStr = "Filtering by shard without keyspace isn't supported"
 	notAValidValueForSeconds              = "Invalid value for seconds"
+	notAValidValueForDuration             = "Invalid value for duration"
+	keyspaceRequiredErrorStr              = "Keyspace is required"
+	notAValidValueForTimestamp            = "Invalid value for timestamp, expected RFC 3339"
+	notAValidValueForLimit                = "Invalid value for limit"
+	notAValidValueForBeforeID             = "Invalid value for before_id"
 )
 
 var (
@@ -62,6 +73,11 @@ var (
 		errantGTIDsAPI,
 		disableGlobalRecoveriesAPI,
 		enableGlobalRecoveriesAPI,
+		disableRecoveriesAPI,
+		enableRecoveriesAPI,
+		recoveryDisablesAPI,
+		recoveriesAPI,
+		detectionsAPI,
 		replicationAnalysisAPI,
 		databaseStateAPI,
 		configAPI,
@@ -83,6 +99,16 @@ func (v *vtorcAPI) ServeHTTP(response http.ResponseWriter, request *http.Request
 		disableGlobalRecoveriesAPIHandler(response)
 	case enableGlobalRecoveriesAPI:
 		enableGlobalRecoveriesAPIHandler(response)
+	case disableRecoveriesAPI:
+		disableRecoveriesAPIHandler(response, request)
+	case enableRecoveriesAPI:
+		enableRecoveriesAPIHandler(response, request)
+	case recoveryDisablesAPI:
+		recoveryDisablesAPIHandler(response, request)
+	case recoveriesAPI:
+		recoveriesAPIHandler(response, request)
+	case detectionsAPI:
+		detectionsAPIHandler(response, request)
 	case healthAPI:
 		healthAPIHandler(response, request)
 	case problemsAPI:
@@ -111,7 +137,11 @@ func getACLPermissionLevelForAPI(apiEndpoint string) string {
 		return acl.MONITORING
 	case disableGlobalRecoveriesAPI, enableGlobalRecoveriesAPI:
 		return acl.ADMIN
-	case replicationAnalysisAPI, configAPI:
+	case disableRecoveriesAPI, enableRecoveriesAPI:
+		return acl.ADMIN
+	case replicationAnalysisAPI, configAPI, recoveryDisablesAPI:
+		return acl.MONITORING
+	case recoveriesAPI, detectionsAPI:
 		return acl.MONITORING
 	case healthAPI, databaseStateAPI:
 		return acl.MONITORING
@@ -241,6 +271,133 @@ func enableGlobalRecoveriesAPIHandler(response http.ResponseWriter) {
 	writePlainTextResponse(response, "Global recoveries enabled", http.StatusOK)
 }
 
+// disableRecoveriesAPIHandler is the handler for the disableRecoveriesAPI endpoint
+func disableRecoveriesAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// Recoveries are disabled for the keyspace, or only for the shard and analysis code if they are provided.
+	keyspace := request.URL.Query().Get("keyspace")
+	shard := request.URL.Query().Get("shard")
+	analysis := inst.AnalysisCode(request.URL.Query().Get("analysis"))
+	reason := request.URL.Query().Get("reason")
+	if keyspace == "" {
+		http.Error(response, keyspaceRequiredErrorStr, http.StatusBadRequest)
+		return
+	}
+	// The disable never expires unless a duration is provided.
+	var duration time.Duration
+	if qDuration := request.URL.Query().Get("duration"); qDuration != "" {
+		var err error
+		duration, err = time.ParseDuration(qDuration)
+		if err != nil || duration < 0 {
+			http.Error(response, notAValidValueForDuration, http.StatusBadRequest)
+			return
+		}
+	}
+	err := logic.DisableKeyspaceShardRecovery(request.Context(), keyspace, shard, analysis, duration, reason)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writePlainTextResponse(response, "Recoveries disabled", http.StatusOK)
+}
+
+// enableRecoveriesAPIHandler is the handler for the enableRecoveriesAPI endpoint
+func enableRecoveriesAPIHandler(response http.ResponseWriter, request *http.Request) {
+	keyspace := request.URL.Query().Get("keyspace")
+	shard := request.URL.Query().Get("shard")
+	analysis := inst.AnalysisCode(request.URL.Query().Get("analysis"))
+	if keyspace == "" {
+		http.Error(response, keyspaceRequiredErrorStr, http.StatusBadRequest)
+		return
+	}
+	err := logic.EnableKeyspaceShardRecovery(request.Context(), keyspace, shard, analysis)
+	if errors.Is(err, logic.ErrRecoveryDisableNotFound) {
+		http.Error(response, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writePlainTextResponse(response, "Recoveries enabled", http.StatusOK)
+}
+
+// recoveryDisablesAPIHandler is the handler for the recoveryDisablesAPI endpoint
+func recoveryDisablesAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api also supports filtering by keyspace.
+	keyspace := request.URL.Query().Get("keyspace")
+	disables, err := logic.ReadRecoveryDisables(keyspace)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, disables)
+}
+
+// recoveriesAPIHandler is the handler for the recoveriesAPI endpoint
+func recoveriesAPIHandler(response http.ResponseWriter, request *http.Request) {
+	filter, errStr := parseHistoryFilter(request)
+	if errStr != "" {
+		http.Error(response, errStr, http.StatusBadRequest)
+		return
+	}
+	recoveries, err := logic.ReadRecoveryHistory(filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, recoveries)
+}
+
+// detectionsAPIHandler is the handler for the detectionsAPI endpoint
+func detectionsAPIHandler(response http.ResponseWriter, request *http.Request) {
+	filter, errStr := parseHistoryFilter(request)
+	if errStr != "" {
+		http.Error(response, errStr, http.StatusBadRequest)
+		return
+	}
+	detections, err := logic.ReadDetections(filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, detections)
+}
+
+// parseHistoryFilter reads the filter of the recoveriesAPI and detectionsAPI endpoints from the query parameters.
+// It returns an error message if the parameters are invalid.
+func parseHistoryFilter(request *http.Request) (*logic.HistoryFilter, string) {
+	filter := &logic.HistoryFilter{
+		Keyspace: request.URL.Query().Get("keyspace"),
+		Shard:    request.URL.Query().Get("shard"),
+		Analysis: inst.AnalysisCode(request.URL.Query().Get("analysis")),
+	}
+	if filter.Shard != "" && filter.Keyspace == "" {
+		return nil, shardWithoutKeyspaceFilteringErrorStr
+	}
+	var err error
+	if since := request.URL.Query().Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, notAValidValueForTimestamp
+		}
+	}
+	if until := request.URL.Query().Get("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, notAValidValueForTimestamp
+		}
+	}
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 || filter.Limit > logic.MaxHistoryLimit {
+			return nil, notAValidValueForLimit
+		}
+	}
+	if beforeID := request.URL.Query().Get("before_id"); beforeID != "" {
+		if filter.BeforeID, err = strconv.ParseInt(beforeID, 10, 64); err != nil || filter.BeforeID <= 0 {
+			return nil, notAValidValueForBeforeID
+		}
+	}
+	return filter, ""
+}
+
 // replicationAnalysisAPIHandler is the handler for the replicationAnalysisAPI endpoint
 func replicationAnalysisAPIHandler(response http.ResponseWriter, request *http.Request) {
 	// This api also supports filtering by shard and keyspace provided.
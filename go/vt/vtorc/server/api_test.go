@@ -1,11 +1,15 @@
 package server
 
 import (
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/logic"
 )
 
 func TestGetACLPermissionLevelForAPI(t *testing.T) {
@@ -25,6 +29,21 @@ func TestGetACLPermissionLevelForAPI(t *testing.T) {
 		}, {
 			apiEndpoint: enableGlobalRecoveriesAPI,
 			want:        acl.ADMIN,
+		}, {
+			apiEndpoint: disableRecoveriesAPI,
+			want:        acl.ADMIN,
+		}, {
+			apiEndpoint: enableRecoveriesAPI,
+			want:        acl.ADMIN,
+		}, {
+			apiEndpoint: recoveryDisablesAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: recoveriesAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: detectionsAPI,
+			want:        acl.MONITORING,
 		}, {
 			apiEndpoint: replicationAnalysisAPI,
 			want:        acl.MONITORING,
@@ -46,3 +65,62 @@ func TestGetACLPermissionLevelForAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestParseHistoryFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantFilter *logic.HistoryFilter
+		wantErr    string
+	}{
+		{
+			name:       "No filter",
+			url:        "/api/recoveries",
+			wantFilter: &logic.HistoryFilter{},
+		}, {
+			name: "All filters",
+			url:  "/api/recoveries?keyspace=ks&shard=-80&analysis=DeadPrimary&since=2025-01-02T03:04:05Z&until=2025-01-03T00:00:00%2B02:00&limit=10&before_id=42",
+			wantFilter: &logic.HistoryFilter{
+				Keyspace: "ks",
+				Shard:    "-80",
+				Analysis: inst.DeadPrimary,
+				Since:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				Until:    time.Date(2025, 1, 3, 0, 0, 0, 0, time.FixedZone("", 2*60*60)),
+				Limit:    10,
+				BeforeID: 42,
+			},
+		}, {
+			name:    "Shard without keyspace",
+			url:     "/api/detections?shard=-80",
+			wantErr: shardWithoutKeyspaceFilteringErrorStr,
+		}, {
+			name:    "Invalid timestamp",
+			url:     "/api/detections?since=yesterday",
+			wantErr: notAValidValueForTimestamp,
+		}, {
+			name:    "Limit too large",
+			url:     "/api/recoveries?limit=1001",
+			wantErr: notAValidValueForLimit,
+		}, {
+			name:    "Invalid before_id",
+			url:     "/api/detections?before_id=abc",
+			wantErr: notAValidValueForBeforeID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, errStr := parseHistoryFilter(httptest.NewRequest("GET", tt.url, nil))
+			require.Equal(t, tt.wantErr, errStr)
+			if tt.wantErr != "" {
+				return
+			}
+			require.Equal(t, tt.wantFilter.Keyspace, filter.Keyspace)
+			require.Equal(t, tt.wantFilter.Shard, filter.Shard)
+			require.Equal(t, tt.wantFilter.Analysis, filter.Analysis)
+			require.True(t, tt.wantFilter.Since.Equal(filter.Since))
+			require.True(t, tt.wantFilter.Until.Equal(filter.Until))
+			require.Equal(t, tt.wantFilter.Limit, filter.Limit)
+			require.Equal(t, tt.wantFilter.BeforeID, filter.BeforeID)
+		})
+	}
+}
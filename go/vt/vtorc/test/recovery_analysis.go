@@ -58,6 +58,8 @@ type InfoForRecoveryAnalysis struct {
 	CountValidReplicatingReplicas             uint
 	CountDowntimedReplicas                    uint
 	ReplicationStopped                        int
+	LastSQLError                              string
+	LastIOError                               string
 	IsDowntimed                               int
 	DowntimeEndTimestamp                      string
 	DowntimeRemainingSeconds                  int
@@ -118,6 +120,8 @@ func (info *InfoForRecoveryAnalysis) ConvertToRowMap() sqlutils.RowMap {
 	rowMap["is_invalid"] = sqlutils.CellData{String: fmt.Sprintf("%v", info.IsInvalid), Valid: true}
 	rowMap["is_last_check_valid"] = sqlutils.CellData{String: fmt.Sprintf("%v", info.LastCheckValid), Valid: true}
 	rowMap["is_primary"] = sqlutils.CellData{String: fmt.Sprintf("%v", info.IsPrimary), Valid: true}
+	rowMap["last_io_error"] = sqlutils.CellData{String: info.LastIOError, Valid: true}
+	rowMap["last_sql_error"] = sqlutils.CellData{String: info.LastSQLError, Valid: true}
 	rowMap["is_stale_binlog_coordinates"] = sqlutils.CellData{String: fmt.Sprintf("%v", info.IsStaleBinlogCoordinates), Valid: true}
 	rowMap["keyspace_type"] = sqlutils.CellData{String: fmt.Sprintf("%v", info.KeyspaceType), Valid: true}
 	rowMap["keyspace"] = sqlutils.CellData{String: info.Keyspace, Valid: true}
@@ -49,9 +49,16 @@ type VReplicationConfig struct {
 	HeartbeatUpdateInterval int
 	StoreCompressedGTID     bool
 	ParallelInsertWorkers   int
+	ParallelApplyWorkers    int
 	TabletTypesStr          string
 	EnableHttpLog           bool // Enable the /debug/vrlog endpoint
 
+	// CopyPhaseMaxRowsPerSecond and CopyPhaseMaxBytesPerSecond limit the rate at which rows are written in the copy
+	// phase, if positive. CopyPhaseSchedule is a cron-style schedule of when the copy phase may run, see CopySchedule.
+	CopyPhaseMaxRowsPerSecond  int64
+	CopyPhaseMaxBytesPerSecond int64
+	CopyPhaseSchedule          string
+
 	// Config parameters applicable to the source side (vstreamer)
 	// The coresponding Override fields are used to determine if the user has provided a value for the parameter so
 	// that they can be sent in the VStreamer API calls to the source.
@@ -94,9 +101,14 @@ func GetVReplicationConfigDefaults(useCached bool) *VReplicationConfig {
 		HeartbeatUpdateInterval: vreplicationHeartbeatUpdateInterval,
 		StoreCompressedGTID:     vreplicationStoreCompressedGTID,
 		ParallelInsertWorkers:   vreplicationParallelInsertWorkers,
+		ParallelApplyWorkers:    vreplicationParallelApplyWorkers,
 		TabletTypesStr:          vreplicationTabletTypesStr,
 		EnableHttpLog:           vreplicationEnableHttpLog,
 
+		CopyPhaseMaxRowsPerSecond:  vreplicationCopyPhaseMaxRowsPerSecond,
+		CopyPhaseMaxBytesPerSecond: vreplicationCopyPhaseMaxBytesPerSecond,
+		CopyPhaseSchedule:          vreplicationCopyPhaseSchedule,
+
 		VStreamPacketSizeOverride:              false,
 		VStreamPacketSize:                      VStreamerDefaultPacketSize,
 		VStreamDynamicPacketSizeOverride:       false,
@@ -220,6 +232,35 @@ func NewVReplicationConfig(overrides map[string]string) (*VReplicationConfig, er
 			} else {
 				c.ParallelInsertWorkers = value
 			}
+		case "vreplication-parallel-apply-workers":
+			value, err := strconv.Atoi(v)
+			if err != nil {
+				errors = append(errors, getError(k, v))
+			} else {
+				c.ParallelApplyWorkers = value
+			}
+		case "vreplication-copy-phase-max-rows-per-second":
+			value, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errors = append(errors, getError(k, v))
+			} else {
+				c.CopyPhaseMaxRowsPerSecond = value
+			}
+		case "vreplication-copy-phase-max-bytes-per-second":
+			value, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errors = append(errors, getError(k, v))
+			} else {
+				c.CopyPhaseMaxBytesPerSecond = value
+			}
+		case "vreplication-copy-phase-schedule":
+			if v == "" {
+				c.CopyPhaseSchedule = v
+			} else if _, err := ParseCopySchedule(v); err != nil {
+				errors = append(errors, getError(k, v))
+			} else {
+				c.CopyPhaseSchedule = v
+			}
 		case "vstream_packet_size":
 			value, err := strconv.Atoi(v)
 			if err != nil {
@@ -259,21 +300,25 @@ func NewVReplicationConfig(overrides map[string]string) (*VReplicationConfig, er
 // keys are one of those that are supported.
 func (c VReplicationConfig) Map() map[string]string {
 	return map[string]string{
-		"vreplication_experimental_flags":         strconv.FormatInt(c.ExperimentalFlags, 10),
-		"vreplication_net_read_timeout":           strconv.Itoa(c.NetReadTimeout),
-		"vreplication_net_write_timeout":          strconv.Itoa(c.NetWriteTimeout),
-		"vreplication_copy_phase_duration":        c.CopyPhaseDuration.String(),
-		"vreplication_retry_delay":                c.RetryDelay.String(),
-		"vreplication_max_time_to_retry_on_error": c.MaxTimeToRetryError.String(),
-		"relay_log_max_size":                      strconv.Itoa(c.RelayLogMaxSize),
-		"relay_log_max_items":                     strconv.Itoa(c.RelayLogMaxItems),
-		"vreplication_replica_lag_tolerance":      c.ReplicaLagTolerance.String(),
-		"vreplication_heartbeat_update_interval":  strconv.Itoa(c.HeartbeatUpdateInterval),
-		"vreplication_store_compressed_gtid":      strconv.FormatBool(c.StoreCompressedGTID),
-		"vreplication-parallel-insert-workers":    strconv.Itoa(c.ParallelInsertWorkers),
-		"vstream_packet_size":                     strconv.Itoa(c.VStreamPacketSize),
-		"vstream_dynamic_packet_size":             strconv.FormatBool(c.VStreamDynamicPacketSize),
-		"vstream_binlog_rotation_threshold":       strconv.FormatInt(c.VStreamBinlogRotationThreshold, 10),
+		"vreplication_experimental_flags":              strconv.FormatInt(c.ExperimentalFlags, 10),
+		"vreplication_net_read_timeout":                strconv.Itoa(c.NetReadTimeout),
+		"vreplication_net_write_timeout":               strconv.Itoa(c.NetWriteTimeout),
+		"vreplication_copy_phase_duration":             c.CopyPhaseDuration.String(),
+		"vreplication_retry_delay":                     c.RetryDelay.String(),
+		"vreplication_max_time_to_retry_on_error":      c.MaxTimeToRetryError.String(),
+		"relay_log_max_size":                           strconv.Itoa(c.RelayLogMaxSize),
+		"relay_log_max_items":                          strconv.Itoa(c.RelayLogMaxItems),
+		"vreplication_replica_lag_tolerance":           c.ReplicaLagTolerance.String(),
+		"vreplication_heartbeat_update_interval":       strconv.Itoa(c.HeartbeatUpdateInterval),
+		"vreplication_store_compressed_gtid":           strconv.FormatBool(c.StoreCompressedGTID),
+		"vreplication-parallel-insert-workers":         strconv.Itoa(c.ParallelInsertWorkers),
+		"vreplication-parallel-apply-workers":          strconv.Itoa(c.ParallelApplyWorkers),
+		"vreplication-copy-phase-max-rows-per-second":  strconv.FormatInt(c.CopyPhaseMaxRowsPerSecond, 10),
+		"vreplication-copy-phase-max-bytes-per-second": strconv.FormatInt(c.CopyPhaseMaxBytesPerSecond, 10),
+		"vreplication-copy-phase-schedule":             c.CopyPhaseSchedule,
+		"vstream_packet_size":                          strconv.Itoa(c.VStreamPacketSize),
+		"vstream_dynamic_packet_size":                  strconv.FormatBool(c.VStreamDynamicPacketSize),
+		"vstream_binlog_rotation_threshold":            strconv.FormatInt(c.VStreamBinlogRotationThreshold, 10),
 	}
 }
 
@@ -36,21 +36,25 @@ func TestNewVReplicationConfig(t *testing.T) {
 		{
 			name: "Valid values",
 			config: map[string]string{
-				"vreplication_experimental_flags":         "3",
-				"vreplication_net_read_timeout":           "100",
-				"vreplication_net_write_timeout":          "200",
-				"vreplication_copy_phase_duration":        "2h",
-				"vreplication_retry_delay":                "10s",
-				"vreplication_max_time_to_retry_on_error": "1h",
-				"relay_log_max_size":                      "500000",
-				"relay_log_max_items":                     "10000",
-				"vreplication_replica_lag_tolerance":      "2m",
-				"vreplication_heartbeat_update_interval":  "2",
-				"vreplication_store_compressed_gtid":      "true",
-				"vreplication-parallel-insert-workers":    "4",
-				"vstream_packet_size":                     "1024",
-				"vstream_dynamic_packet_size":             "false",
-				"vstream_binlog_rotation_threshold":       "2048",
+				"vreplication_experimental_flags":              "3",
+				"vreplication_net_read_timeout":                "100",
+				"vreplication_net_write_timeout":               "200",
+				"vreplication_copy_phase_duration":             "2h",
+				"vreplication_retry_delay":                     "10s",
+				"vreplication_max_time_to_retry_on_error":      "1h",
+				"relay_log_max_size":                           "500000",
+				"relay_log_max_items":                          "10000",
+				"vreplication_replica_lag_tolerance":           "2m",
+				"vreplication_heartbeat_update_interval":       "2",
+				"vreplication_store_compressed_gtid":           "true",
+				"vreplication-parallel-insert-workers":         "4",
+				"vreplication-parallel-apply-workers":          "8",
+				"vreplication-copy-phase-max-rows-per-second":  "1000",
+				"vreplication-copy-phase-max-bytes-per-second": "1048576",
+				"vreplication-copy-phase-schedule":             "* 1-5 * * *",
+				"vstream_packet_size":                          "1024",
+				"vstream_dynamic_packet_size":                  "false",
+				"vstream_binlog_rotation_threshold":            "2048",
 			},
 			wantErr: 0,
 			want: &VReplicationConfig{
@@ -66,6 +70,10 @@ func TestNewVReplicationConfig(t *testing.T) {
 				HeartbeatUpdateInterval:                2,
 				StoreCompressedGTID:                    true,
 				ParallelInsertWorkers:                  4,
+				ParallelApplyWorkers:                   8,
+				CopyPhaseMaxRowsPerSecond:              1000,
+				CopyPhaseMaxBytesPerSecond:             1048576,
+				CopyPhaseSchedule:                      "* 1-5 * * *",
 				VStreamPacketSize:                      1024,
 				VStreamDynamicPacketSize:               false,
 				VStreamBinlogRotationThreshold:         2048,
@@ -78,23 +86,27 @@ func TestNewVReplicationConfig(t *testing.T) {
 		{
 			name: "Invalid values",
 			config: map[string]string{
-				"vreplication_experimental_flags":         "invalid",
-				"vreplication_net_read_timeout":           "100.0",
-				"vreplication_net_write_timeout":          "invalid",
-				"vreplication_copy_phase_duration":        "invalid",
-				"vreplication_retry_delay":                "invalid",
-				"vreplication_max_time_to_retry_on_error": "invalid",
-				"relay_log_max_size":                      "invalid",
-				"relay_log_max_items":                     "invalid",
-				"vreplication_replica_lag_tolerance":      "invalid",
-				"vreplication_heartbeat_update_interval":  "invalid",
-				"vreplication_store_compressed_gtid":      "nottrue",
-				"vreplication-parallel-insert-workers":    "invalid",
-				"vstream_packet_size":                     "invalid",
-				"vstream_dynamic_packet_size":             "waar",
-				"vstream_binlog_rotation_threshold":       "invalid",
+				"vreplication_experimental_flags":              "invalid",
+				"vreplication_net_read_timeout":                "100.0",
+				"vreplication_net_write_timeout":               "invalid",
+				"vreplication_copy_phase_duration":             "invalid",
+				"vreplication_retry_delay":                     "invalid",
+				"vreplication_max_time_to_retry_on_error":      "invalid",
+				"relay_log_max_size":                           "invalid",
+				"relay_log_max_items":                          "invalid",
+				"vreplication_replica_lag_tolerance":           "invalid",
+				"vreplication_heartbeat_update_interval":       "invalid",
+				"vreplication_store_compressed_gtid":           "nottrue",
+				"vreplication-parallel-insert-workers":         "invalid",
+				"vreplication-parallel-apply-workers":          "invalid",
+				"vreplication-copy-phase-max-rows-per-second":  "invalid",
+				"vreplication-copy-phase-max-bytes-per-second": "1.5",
+				"vreplication-copy-phase-schedule":             "* 25 * * *",
+				"vstream_packet_size":                          "invalid",
+				"vstream_dynamic_packet_size":                  "waar",
+				"vstream_binlog_rotation_threshold":            "invalid",
 			},
-			wantErr: 15,
+			wantErr: 19,
 		},
 		{
 			name: "Partial values",
@@ -118,6 +130,10 @@ func TestNewVReplicationConfig(t *testing.T) {
 				HeartbeatUpdateInterval:          DefaultVReplicationConfig.HeartbeatUpdateInterval,
 				StoreCompressedGTID:              !DefaultVReplicationConfig.StoreCompressedGTID,
 				ParallelInsertWorkers:            DefaultVReplicationConfig.ParallelInsertWorkers,
+				ParallelApplyWorkers:             DefaultVReplicationConfig.ParallelApplyWorkers,
+				CopyPhaseMaxRowsPerSecond:        DefaultVReplicationConfig.CopyPhaseMaxRowsPerSecond,
+				CopyPhaseMaxBytesPerSecond:       DefaultVReplicationConfig.CopyPhaseMaxBytesPerSecond,
+				CopyPhaseSchedule:                DefaultVReplicationConfig.CopyPhaseSchedule,
 				VStreamPacketSize:                DefaultVReplicationConfig.VStreamPacketSize,
 				VStreamDynamicPacketSize:         !DefaultVReplicationConfig.VStreamDynamicPacketSize,
 				VStreamBinlogRotationThreshold:   DefaultVReplicationConfig.VStreamBinlogRotationThreshold,
@@ -30,6 +30,9 @@ const (
 	VReplicationExperimentalFlagOptimizeInserts           = int64(1)
 	VReplicationExperimentalFlagAllowNoBlobBinlogRowImage = int64(2)
 	VReplicationExperimentalFlagVPlayerBatching           = int64(4)
+	// VReplicationExperimentalFlagEvaluateExpressions evaluates the non-trivial select
+	// expressions of filter rules in vttablet rather than in the target MySQL.
+	VReplicationExperimentalFlagEvaluateExpressions = int64(8)
 )
 
 var (
@@ -51,6 +54,11 @@ var (
 
 	vreplicationStoreCompressedGTID   = false
 	vreplicationParallelInsertWorkers = 1
+	vreplicationParallelApplyWorkers  = 1
+
+	vreplicationCopyPhaseMaxRowsPerSecond  = int64(0)
+	vreplicationCopyPhaseMaxBytesPerSecond = int64(0)
+	vreplicationCopyPhaseSchedule          = ""
 
 	// VStreamerBinlogRotationThreshold is the threshold, above which we rotate binlogs, before taking a GTID snapshot
 	VStreamerBinlogRotationThreshold = int64(64 * 1024 * 1024) // 64MiB
@@ -97,6 +105,11 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&vreplicationStoreCompressedGTID, "vreplication_store_compressed_gtid", vreplicationStoreCompressedGTID, "Store compressed gtids in the pos column of the sidecar database's vreplication table")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationParallelApplyWorkers, "vreplication-parallel-apply-workers", vreplicationParallelApplyWorkers, "Number of connections used to apply non-conflicting transactions concurrently during the running phase. Set <= 1 to apply transactions one at a time.")
+
+	fs.Int64Var(&vreplicationCopyPhaseMaxRowsPerSecond, "vreplication-copy-phase-max-rows-per-second", vreplicationCopyPhaseMaxRowsPerSecond, "Maximum number of rows per second that a workflow writes in the copy phase. Set <= 0 for no limit.")
+	fs.Int64Var(&vreplicationCopyPhaseMaxBytesPerSecond, "vreplication-copy-phase-max-bytes-per-second", vreplicationCopyPhaseMaxBytesPerSecond, "Maximum number of bytes of rows per second that a workflow writes in the copy phase. Set <= 0 for no limit.")
+	fs.StringVar(&vreplicationCopyPhaseSchedule, "vreplication-copy-phase-schedule", vreplicationCopyPhaseSchedule, "Cron-style schedule, in UTC, of when the copy phase of workflows may run, e.g. '* 1-5 * * *' for 01:00 to 06:00. Empty to always allow copying.")
 
 	fs.Uint64Var(&mysql.ZstdInMemoryDecompressorMaxSize, "binlog-in-memory-decompressor-max-size", mysql.ZstdInMemoryDecompressorMaxSize, "This value sets the uncompressed transaction payload size at which we switch from in-memory buffer based decompression to the slower streaming mode.")
 
@@ -0,0 +1,169 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttablet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleField is the range of values of a field of a schedule.
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []scheduleField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// CopySchedule is a cron-style schedule of when the copy phase of a workflow
+// may run. It has the five fields of a crontab entry: minute, hour, day of
+// month, month and day of week, in UTC. A field is either `*` or a comma
+// separated list of values and ranges, each optionally followed by a `/step`.
+// As in cron, if both the day of month and the day of week are restricted,
+// a day matching either of them is allowed. E.g. `* 1-5 * * *` allows
+// copying from 01:00 to 06:00 UTC.
+type CopySchedule struct {
+	expr string
+	// fields holds a bit for each allowed value of each field.
+	fields [5]uint64
+	// anyDayOfMonth and anyDayOfWeek are set if the field is `*`.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// ParseCopySchedule parses a cron-style copy schedule.
+func ParseCopySchedule(expr string) (*CopySchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(scheduleFields) {
+		return nil, fmt.Errorf("invalid schedule %q: expected %d fields, got %d", expr, len(scheduleFields), len(parts))
+	}
+	s := &CopySchedule{
+		expr:          expr,
+		anyDayOfMonth: parts[2] == "*",
+		anyDayOfWeek:  parts[4] == "*",
+	}
+	for i, part := range parts {
+		bits, err := parseScheduleField(part, scheduleFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+		}
+		s.fields[i] = bits
+	}
+	// Sunday is both 0 and 7.
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	return s, nil
+}
+
+func parseScheduleField(part string, field scheduleField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(part, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepStr, field.name)
+			}
+		}
+		first, last := field.min, field.max
+		if rng != "*" {
+			lo, hi, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = strconv.Atoi(lo); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", lo, field.name)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(hi); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", hi, field.name)
+				}
+			} else if hasStep {
+				last = field.max
+			}
+		}
+		if first < field.min || last > field.max || first > last {
+			return 0, fmt.Errorf("invalid range %q in %s field, values must be within %d-%d", rng, field.name, field.min, field.max)
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (s *CopySchedule) String() string {
+	return s.expr
+}
+
+// Allows returns true if copying is allowed at the time.
+func (s *CopySchedule) Allows(t time.Time) bool {
+	t = t.UTC()
+	return s.has(3, int(t.Month())) && s.allowsDay(t) && s.has(1, t.Hour()) && s.has(0, t.Minute())
+}
+
+func (s *CopySchedule) has(field, v int) bool {
+	return s.fields[field]&(1<<v) != 0
+}
+
+func (s *CopySchedule) allowsDay(t time.Time) bool {
+	dayOfMonth, dayOfWeek := s.has(2, t.Day()), s.has(4, int(t.Weekday()))
+	switch {
+	case s.anyDayOfMonth && s.anyDayOfWeek:
+		return true
+	case s.anyDayOfMonth:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
+
+// Next returns the start of the first minute, at or after the time, at which
+// copying is allowed. It returns the zero time if copying is not allowed
+// within the next four years.
+func (s *CopySchedule) Next(t time.Time) time.Time {
+	t = t.UTC()
+	next := t.Truncate(time.Minute)
+	if next.Before(t) {
+		next = next.Add(time.Minute)
+	}
+	for end := next.AddDate(4, 0, 0); next.Before(end); {
+		switch {
+		case !s.has(3, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.allowsDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.has(1, next.Hour()):
+			next = next.Truncate(time.Hour).Add(time.Hour)
+		case !s.has(0, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
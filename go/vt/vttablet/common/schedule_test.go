@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttablet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCopySchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 1-5 * * 1-5"},
+		{expr: "0,30 22 1,15 1-12/2 7"},
+		{expr: "* * * *", wantErr: "expected 5 fields, got 4"},
+		{expr: "60 * * * *", wantErr: "values must be within 0-59"},
+		{expr: "* 5-1 * * *", wantErr: "invalid range"},
+		{expr: "* * 0 * *", wantErr: "values must be within 1-31"},
+		{expr: "*/0 * * * *", wantErr: "invalid step"},
+		{expr: "a * * * *", wantErr: "invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCopySchedule(tt.expr)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCopySchedule(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse(time.DateTime, s)
+		require.NoError(t, err)
+		return tm
+	}
+	tests := []struct {
+		name    string
+		expr    string
+		t       string
+		allowed bool
+		next    string
+	}{
+		{
+			name:    "inside the window",
+			expr:    "* 1-5 * * *",
+			t:       "2025-03-04 01:30:00",
+			allowed: true,
+			next:    "2025-03-04 01:30:00",
+		},
+		{
+			name: "before the window",
+			expr: "* 1-5 * * *",
+			t:    "2025-03-04 00:59:30",
+			next: "2025-03-04 01:00:00",
+		},
+		{
+			name: "after the window",
+			expr: "* 1-5 * * *",
+			t:    "2025-03-04 06:00:00",
+			next: "2025-03-05 01:00:00",
+		},
+		{
+			name: "weekends only",
+			expr: "* * * * 6,0",
+			// 2025-03-04 is a Tuesday.
+			t:    "2025-03-04 12:00:00",
+			next: "2025-03-08 00:00:00",
+		},
+		{
+			name:    "sunday as 7",
+			expr:    "* * * * 7",
+			t:       "2025-03-09 12:00:00",
+			allowed: true,
+			next:    "2025-03-09 12:00:00",
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 10 * 6",
+			t:    "2025-03-04 12:00:00",
+			next: "2025-03-08 00:00:00",
+		},
+		{
+			name: "next month",
+			expr: "*/30 2 1 * *",
+			t:    "2025-03-04 12:00:00",
+			next: "2025-04-01 02:00:00",
+		},
+		{
+			name: "never",
+			expr: "* * 31 2 *",
+			t:    "2025-03-04 12:00:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCopySchedule(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.allowed, s.Allows(at(tt.t)))
+			if tt.next == "" {
+				require.True(t, s.Next(at(tt.t)).IsZero())
+			} else {
+				require.Equal(t, at(tt.next), s.Next(at(tt.t)))
+			}
+		})
+	}
+}
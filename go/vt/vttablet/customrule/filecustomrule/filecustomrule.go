@@ -99,10 +99,12 @@ func (fcr *FileCustomRule) Open(qsc tabletserver.Controller, rulePath string) er
 	if err != nil {
 		return err
 	}
+	// Push query rules to vttablet
+	if err := qsc.SetQueryRules(FileCustomRuleSource, qrs.Copy()); err != nil {
+		return err
+	}
 	fcr.currentRuleSetTimestamp = time.Now().Unix()
 	fcr.currentRuleSet = qrs.Copy()
-	// Push query rules to vttablet
-	qsc.SetQueryRules(FileCustomRuleSource, qrs.Copy())
 	log.Infof("Custom rule loaded from file: %s", fcr.path)
 	return nil
 }
@@ -132,8 +132,10 @@ func (cr *topoCustomRule) apply(wd *topo.WatchData) error {
 	}
 
 	if !reflect.DeepEqual(cr.qrs, qrs) {
+		if err := cr.qsc.SetQueryRules(topoCustomRuleSource, qrs); err != nil {
+			return fmt.Errorf("error applying query rules: %v, original data '%s' version %v", err, wd.Contents, wd.Version)
+		}
 		cr.qrs = qrs.Copy()
-		cr.qsc.SetQueryRules(topoCustomRuleSource, qrs)
 		log.Infof("Custom rule version %v fetched from topo and applied to vttablet", wd.Version)
 	}
 
@@ -372,6 +372,16 @@ func (client *FakeTabletManagerClient) ReadReparentJournalInfo(ctx context.Conte
 	return 10, nil
 }
 
+// DrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) DrainPrimary(ctx context.Context, tablet *topodatapb.Tablet, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	return nil, nil
+}
+
+// UndoDrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) UndoDrainPrimary(ctx context.Context, tablet *topodatapb.Tablet) error {
+	return nil
+}
+
 // DemotePrimary is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) DemotePrimary(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.PrimaryStatus, error) {
 	return nil, nil
@@ -27,6 +27,7 @@ import (
 	"google.golang.org/grpc"
 
 	"vitess.io/vitess/go/netutil"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/grpcclient"
 	"vitess.io/vitess/go/vt/hook"
@@ -1171,6 +1172,33 @@ func (client *Client) InitReplica(ctx context.Context, tablet *topodatapb.Tablet
 	return err
 }
 
+// DrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (client *Client) DrainPrimary(ctx context.Context, tablet *topodatapb.Tablet, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	response, err := c.DrainPrimary(ctx, &tabletmanagerdatapb.DrainPrimaryRequest{
+		Timeout: protoutil.DurationToProto(timeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.Transactions, nil
+}
+
+// UndoDrainPrimary is part of the tmclient.TabletManagerClient interface.
+func (client *Client) UndoDrainPrimary(ctx context.Context, tablet *topodatapb.Tablet) error {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	_, err = c.UndoDrainPrimary(ctx, &tabletmanagerdatapb.UndoDrainPrimaryRequest{})
+	return err
+}
+
 // DemotePrimary is part of the tmclient.TabletManagerClient interface.
 func (client *Client) DemotePrimary(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.PrimaryStatus, error) {
 	c, closer, err := client.dialer.dial(ctx, tablet)
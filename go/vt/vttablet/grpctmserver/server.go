@@ -22,6 +22,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
 	"vitess.io/vitess/go/vt/hook"
@@ -596,6 +597,29 @@ func (s *server) InitReplica(ctx context.Context, request *tabletmanagerdatapb.I
 	return response, s.tm.InitReplica(ctx, request.Parent, request.ReplicationPosition, request.TimeCreatedNs, request.GetSemiSync())
 }
 
+func (s *server) DrainPrimary(ctx context.Context, request *tabletmanagerdatapb.DrainPrimaryRequest) (response *tabletmanagerdatapb.DrainPrimaryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "DrainPrimary", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.DrainPrimaryResponse{}
+	timeout, _, err := protoutil.DurationFromProto(request.Timeout)
+	if err != nil {
+		return response, vterrors.Wrap(err, "invalid timeout")
+	}
+	txs, err := s.tm.DrainPrimary(ctx, timeout)
+	if err == nil {
+		response.Transactions = txs
+	}
+	return response, err
+}
+
+func (s *server) UndoDrainPrimary(ctx context.Context, request *tabletmanagerdatapb.UndoDrainPrimaryRequest) (response *tabletmanagerdatapb.UndoDrainPrimaryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "UndoDrainPrimary", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.UndoDrainPrimaryResponse{}
+	err = s.tm.UndoDrainPrimary(ctx)
+	return response, err
+}
+
 func (s *server) DemotePrimary(ctx context.Context, request *tabletmanagerdatapb.DemotePrimaryRequest) (response *tabletmanagerdatapb.DemotePrimaryResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "DemotePrimary", request, response, true /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
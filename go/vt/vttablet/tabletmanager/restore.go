@@ -199,6 +199,7 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		Stats:                backupstats.RestoreStats(),
 		MysqlShutdownTimeout: mysqlShutdownTimeout,
 		AllowedBackupEngines: request.AllowedBackupEngines,
+		Tables:               request.Tables,
 	}
 	restoreToTimestamp := protoutil.TimeFromProto(request.RestoreToTimestamp).UTC()
 	if request.RestoreToPos != "" && !restoreToTimestamp.IsZero() {
@@ -215,6 +216,9 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		// Restore to given timestamp
 		params.RestoreToTimestamp = restoreToTimestamp
 	}
+	if len(params.Tables) > 0 && !params.IsIncrementalRecovery() {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "restoring a subset of tables requires --restore-to-pos or --restore-to-timestamp")
+	}
 	params.Logger.Infof("Restore: original tablet type=%v", originalType)
 
 	// Check whether we're going to restore before changing to RESTORE type,
@@ -146,6 +146,10 @@ type RPCTM interface {
 
 	InitReplica(ctx context.Context, parent *topodatapb.TabletAlias, replicationPosition string, timeCreatedNS int64, semiSync bool) error
 
+	DrainPrimary(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error)
+
+	UndoDrainPrimary(ctx context.Context) error
+
 	DemotePrimary(ctx context.Context) (*replicationdatapb.PrimaryStatus, error)
 
 	UndoDemotePrimary(ctx context.Context, semiSync bool) error
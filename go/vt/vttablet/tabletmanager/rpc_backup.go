@@ -133,12 +133,12 @@ func (tm *TabletManager) Backup(ctx context.Context, logger logutil.Logger, req
 				return
 			}
 
-			durabilityName, err := tm.TopoServer.GetKeyspaceDurability(bgCtx, tablet.Keyspace)
+			durabilityName, durabilityConfig, err := tm.TopoServer.GetKeyspaceDurability(bgCtx, tablet.Keyspace)
 			if err != nil {
 				l.Errorf("Failed to get durability policy, error: %v", err)
 				return
 			}
-			durability, err := policy.GetDurabilityPolicy(durabilityName)
+			durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 			if err != nil {
 				l.Errorf("Failed to get durability with name %v, error: %v", durabilityName, err)
 			}
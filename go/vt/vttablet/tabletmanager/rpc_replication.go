@@ -36,6 +36,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver"
 
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -298,6 +299,9 @@ func (tm *TabletManager) StartReplication(ctx context.Context, semiSync bool) er
 	}
 	defer tm.unlock()
 
+	// Start accepting new transactions again if DrainPrimary was called.
+	tm.QueryServiceControl.StopDraining()
+
 	semiSyncAction, err := tm.convertBoolToSemiSyncAction(ctx, semiSync)
 	if err != nil {
 		return err
@@ -504,6 +508,37 @@ func (tm *TabletManager) InitReplica(ctx context.Context, parent *topodatapb.Tab
 	return tm.MysqlDaemon.WaitForReparentJournal(ctx, timeCreatedNS)
 }
 
+// DrainPrimary stops a PRIMARY tablet from starting new transactions ahead of
+// a planned reparent, and waits up to timeout for the open ones to finish.
+// The tablet keeps draining until its query service changes state, for
+// example when it is demoted, or until UndoDrainPrimary or StartReplication
+// is called because the reparent was abandoned.
+func (tm *TabletManager) DrainPrimary(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	log.Infof("DrainPrimary")
+	if err := tm.waitForGrantsToHaveApplied(ctx); err != nil {
+		return nil, err
+	}
+	if err := tm.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer tm.unlock()
+
+	return tm.QueryServiceControl.DrainTransactions(ctx, timeout)
+}
+
+// UndoDrainPrimary reverts a previous call to DrainPrimary, letting the tablet
+// start new transactions again.
+func (tm *TabletManager) UndoDrainPrimary(ctx context.Context) error {
+	log.Infof("UndoDrainPrimary")
+	if err := tm.lock(ctx); err != nil {
+		return err
+	}
+	defer tm.unlock()
+
+	tm.QueryServiceControl.StopDraining()
+	return nil
+}
+
 // DemotePrimary prepares a PRIMARY tablet to give up leadership to another tablet.
 //
 // It attempts to idempotently ensure the following guarantees upon returning
@@ -48,6 +48,11 @@ type primitiveExecutor struct {
 	resultch chan *sqltypes.Result
 	err      error
 
+	// pastEnd, if set, ends the rows of the executor before the first row it
+	// returns true for.
+	pastEnd func(row []sqltypes.Value) bool
+	ended   bool
+
 	name string // for debug purposes only
 }
 
@@ -78,6 +83,9 @@ func newPrimitiveExecutor(ctx context.Context, prim vtgateEngine.Primitive, name
 // next gets the next row in the stream for this shard, if there's currently no rows to process in the stream then wait on the
 // result channel for the shard streamer to produce them.
 func (pe *primitiveExecutor) next() ([]sqltypes.Value, error) {
+	if pe.ended {
+		return nil, nil
+	}
 	for len(pe.rows) == 0 {
 		qr, ok := <-pe.resultch
 		if !ok {
@@ -87,6 +95,10 @@ func (pe *primitiveExecutor) next() ([]sqltypes.Value, error) {
 	}
 
 	row := pe.rows[0]
+	if pe.pastEnd != nil && pe.pastEnd(row) {
+		pe.ended = true
+		return nil, nil
+	}
 	pe.rows = pe.rows[1:]
 	return row, nil
 }
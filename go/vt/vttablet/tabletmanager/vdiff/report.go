@@ -40,6 +40,17 @@ type DiffReport struct {
 	ExtraRowsSource int64
 	ExtraRowsTarget int64
 
+	// Mode is empty when every row was diffed. Otherwise it is "checksum",
+	// "sample" or "checksum,sample", and the table was split into primary
+	// key ranges, or chunks, that were checksummed or sampled before their
+	// rows were diffed.
+	Mode             string `json:"Mode,omitempty"`
+	Chunks           int64  `json:"Chunks,omitempty"`
+	MatchingChunks   int64  `json:"MatchingChunks,omitempty"`
+	MismatchedChunks int64  `json:"MismatchedChunks,omitempty"`
+	SkippedChunks    int64  `json:"SkippedChunks,omitempty"`
+	ChecksummedRows  int64  `json:"ChecksummedRows,omitempty"`
+
 	// actual data for a few sample rows
 	ExtraRowsSourceDiffs []*RowDiff      `json:"ExtraRowsSourceSample,omitempty"`
 	ExtraRowsTargetDiffs []*RowDiff      `json:"ExtraRowsTargetSample,omitempty"`
@@ -0,0 +1,565 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	checksumMode = "checksum"
+	sampleMode   = "sample"
+
+	ChecksumAlgorithmCRC32 = "crc32"
+	ChecksumAlgorithmMD5   = "md5"
+
+	// DefaultChunkSize is the default number of rows in a chunk.
+	DefaultChunkSize = int64(100_000)
+)
+
+// pkChunk is a range of primary key values: the rows after start, up to and including end. The rows
+// hold the primary key values at their index in the select of the table plan, and a nil start or end
+// is the start or end of the table.
+type pkChunk struct {
+	start, end []sqltypes.Value
+}
+
+// tableChunker splits a table into chunks, in the checksum and sample modes.
+type tableChunker struct {
+	td *tableDiffer
+
+	checksum  bool
+	sample    bool
+	samplePct int64
+	size      int64
+	algorithm string
+
+	// sourceSelect and targetSelect are the queries whose rows are
+	// checksummed, and sourcePKs and targetPKs their PK expressions.
+	sourceSelect, targetSelect *sqlparser.Select
+	sourcePKs, targetPKs       []sqlparser.Expr
+
+	// restartWorkflow, once the target streams of the workflow were stopped
+	// for the first diffed chunk, restarts them.
+	restartWorkflow func()
+}
+
+// newTableChunker returns the chunker of the table, or nil when every row of the table is to be
+// diffed, because neither the checksum nor the sample mode was requested or because the table
+// cannot be split into chunks.
+func newTableChunker(td *tableDiffer) (*tableChunker, error) {
+	opts := td.wd.opts.CoreOptions
+	tc := &tableChunker{
+		td:        td,
+		checksum:  opts.GetChecksum(),
+		sample:    opts.GetSamplePct() > 0 && opts.GetSamplePct() < 100,
+		samplePct: opts.GetSamplePct(),
+		size:      opts.GetChunkSize(),
+		algorithm: strings.ToLower(opts.GetChecksumAlgorithm()),
+	}
+	if !tc.checksum && !tc.sample {
+		return nil, nil
+	}
+	if tc.size <= 0 {
+		tc.size = DefaultChunkSize
+	}
+	switch tc.algorithm {
+	case "":
+		tc.algorithm = ChecksumAlgorithmCRC32
+	case ChecksumAlgorithmCRC32, ChecksumAlgorithmMD5:
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown checksum algorithm %q", tc.algorithm)
+	}
+	if reason := tc.parse(); reason != "" {
+		log.Infof("Diffing every row of table %s for vdiff %s as it cannot be split into chunks: %s", td.table.Name, td.wd.ct.uuid, reason)
+		return nil, nil
+	}
+	if tc.checksum {
+		if reason := tc.checksummable(); reason != "" {
+			log.Infof("Not checksumming table %s for vdiff %s: %s", td.table.Name, td.wd.ct.uuid, reason)
+			tc.checksum = false
+		}
+	}
+	if !tc.checksum && !tc.sample {
+		return nil, nil
+	}
+	return tc, nil
+}
+
+// mode returns the value of DiffReport.Mode.
+func (tc *tableChunker) mode() string {
+	var modes []string
+	if tc.checksum {
+		modes = append(modes, checksumMode)
+	}
+	if tc.sample {
+		modes = append(modes, sampleMode)
+	}
+	return strings.Join(modes, ",")
+}
+
+// parse parses the queries of the table plan, returning why the table cannot be split into chunks
+// if it cannot.
+func (tc *tableChunker) parse() string {
+	tp := tc.td.tablePlan
+	switch {
+	case len(tp.aggregates) > 0:
+		return "the filter aggregates rows"
+	case len(tp.pkCols) == 0 || !slices.Equal(tp.pkCols, tp.sourcePkCols):
+		return "the source and target primary keys differ"
+	case tc.td.wd.ct.sourceTimeZone != "":
+		return "the workflow converts time zones"
+	}
+	for _, pk := range tp.comparePKs {
+		nullable := true
+		for _, field := range tp.table.Fields {
+			if strings.EqualFold(field.Name, pk.colName) {
+				nullable = field.Flags&uint32(querypb.MySqlFlag_NOT_NULL_FLAG) == 0
+				break
+			}
+		}
+		if nullable {
+			return fmt.Sprintf("primary key column %s is nullable", pk.colName)
+		}
+	}
+
+	parser := tc.td.wd.ct.vde.parser
+	for _, q := range []struct {
+		query  string
+		sel    **sqlparser.Select
+		pkExpr *[]sqlparser.Expr
+	}{
+		{tp.sourceQuery, &tc.sourceSelect, &tc.sourcePKs},
+		{tp.targetQuery, &tc.targetSelect, &tc.targetPKs},
+	} {
+		stmt, err := parser.Parse(q.query)
+		if err != nil {
+			return err.Error()
+		}
+		sel, ok := stmt.(*sqlparser.Select)
+		if !ok {
+			return fmt.Sprintf("unexpected query %s", q.query)
+		}
+		for _, pkI := range tp.pkCols {
+			expr := sel.SelectExprs.Exprs[pkI].(*sqlparser.AliasedExpr).Expr
+			if _, ok := expr.(*sqlparser.ColName); !ok {
+				return fmt.Sprintf("primary key expression %s is not a column", sqlparser.String(expr))
+			}
+			*q.pkExpr = append(*q.pkExpr, expr)
+		}
+		*q.sel = sel
+	}
+	return ""
+}
+
+// checksummable checks that MySQL can run the source query, returning why it cannot if it cannot.
+// The in_keyrange() filters that keep every row of the source shards are removed from the query.
+func (tc *tableChunker) checksummable() string {
+	var sourceKeyRanges []*topodatapb.KeyRange
+	for shard := range tc.td.wd.ct.sources {
+		_, kr, err := topo.ValidateShardName(shard)
+		if err != nil {
+			return err.Error()
+		}
+		sourceKeyRanges = append(sourceKeyRanges, kr)
+	}
+	coversSources := func(inKeyRange *sqlparser.FuncExpr) bool {
+		if len(inKeyRange.Exprs) == 0 {
+			return false
+		}
+		lit, ok := inKeyRange.Exprs[len(inKeyRange.Exprs)-1].(*sqlparser.Literal)
+		if !ok {
+			return false
+		}
+		krs, err := key.ParseShardingSpec(lit.Val)
+		if err != nil || len(krs) != 1 {
+			return false
+		}
+		for _, skr := range sourceKeyRanges {
+			if !key.KeyRangeContainsKeyRange(krs[0], skr) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if where := tc.sourceSelect.Where; where != nil {
+		var filter sqlparser.Expr
+		for _, expr := range sqlparser.SplitAndExpression(nil, where.Expr) {
+			if fn, ok := expr.(*sqlparser.FuncExpr); ok && fn.Name.EqualString("in_keyrange") {
+				if !coversSources(fn) {
+					return "the source shards are filtered by key range"
+				}
+				continue
+			}
+			filter = sqlparser.AndExpressions(filter, expr)
+		}
+		tc.sourceSelect.Where = sqlparser.NewWhere(sqlparser.WhereClause, filter)
+	}
+	var reason string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if fn, ok := node.(*sqlparser.FuncExpr); ok && (fn.Name.EqualString("in_keyrange") || fn.Name.EqualString("keyspace_id")) {
+			reason = fmt.Sprintf("the source query uses %s()", fn.Name.String())
+			return false, nil
+		}
+		return true, nil
+	}, tc.sourceSelect)
+	return reason
+}
+
+// chunkRow returns a row of the table plan holding the given primary key values.
+func (tc *tableChunker) chunkRow(pkValues []sqltypes.Value) []sqltypes.Value {
+	row := make([]sqltypes.Value, len(tc.td.tablePlan.compareCols))
+	for i, pkI := range tc.td.tablePlan.pkCols {
+		row[pkI] = pkValues[i]
+	}
+	return row
+}
+
+// writeWhere writes the where clause selecting the rows of the chunk that match the filter.
+func (tc *tableChunker) writeWhere(buf *sqlparser.TrackedBuffer, pks []sqlparser.Expr, chunk *pkChunk, filter *sqlparser.Where) {
+	sep := " where "
+	writeRange := func(row []sqltypes.Value, op string) {
+		buf.WriteString(sep)
+		buf.WriteByte('(')
+		for i, pk := range pks {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.Myprintf("%v", pk)
+		}
+		buf.WriteString(") " + op + " (")
+		for i, pkI := range tc.td.tablePlan.pkCols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			row[pkI].EncodeSQL(buf)
+		}
+		buf.WriteByte(')')
+		sep = " and "
+	}
+	if chunk.start != nil {
+		writeRange(chunk.start, ">")
+	}
+	if chunk.end != nil {
+		writeRange(chunk.end, "<=")
+	}
+	if filter != nil && filter.Expr != nil {
+		buf.Myprintf("%s(%v)", sep, filter.Expr)
+	}
+}
+
+// nextChunkEnd returns the end of the chunk starting at start, which is nil for the last chunk.
+// The chunks are computed on this tablet.
+func (tc *tableChunker) nextChunkEnd(dbClient binlogplayer.DBClient, start []sqltypes.Value) ([]sqltypes.Value, error) {
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.WriteString("select ")
+	for i, pk := range tc.targetPKs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.Myprintf("%v", pk)
+	}
+	buf.Myprintf(" from %v", sqlparser.TableName{
+		Name:      sqlparser.NewIdentifierCS(tc.td.table.Name),
+		Qualifier: sqlparser.NewIdentifierCS(tc.td.wd.ct.vde.dbName),
+	})
+	tc.writeWhere(buf, tc.targetPKs, &pkChunk{start: start}, tc.targetSelect.Where)
+	buf.Myprintf(" order by ")
+	for i, pk := range tc.targetPKs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.Myprintf("%v", pk)
+	}
+	buf.Myprintf(" limit 1 offset %d", tc.size-1)
+	qr, err := dbClient.ExecuteFetch(buf.String(), 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) == 0 {
+		return nil, nil
+	}
+	return tc.chunkRow(qr.Rows[0]), nil
+}
+
+// checksumQuery returns the query counting and checksumming the rows of the chunk for the select.
+func (tc *tableChunker) checksumQuery(sel *sqlparser.Select, pks []sqlparser.Expr, chunk *pkChunk) string {
+	hashStart, hashEnd := "crc32(", ")"
+	if tc.algorithm == ChecksumAlgorithmMD5 {
+		// The first 64 bits of the MD5 hash.
+		hashStart, hashEnd = "cast(conv(left(md5(", "), 16), 16, 10) as unsigned)"
+	}
+	exprs := make([]sqlparser.Expr, 0, len(sel.SelectExprs.Exprs))
+	for _, selExpr := range sel.SelectExprs.Exprs {
+		exprs = append(exprs, selExpr.(*sqlparser.AliasedExpr).Expr)
+	}
+	buf := sqlparser.NewTrackedBuffer(nil)
+	// concat_ws() skips NULLs, so the NULL columns are marked at the end.
+	buf.WriteString("select count(*) as row_count, bit_xor(" + hashStart + "concat_ws('#'")
+	for _, expr := range exprs {
+		buf.Myprintf(", %v", expr)
+	}
+	buf.WriteString(", concat(")
+	for i, expr := range exprs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.Myprintf("isnull(%v)", expr)
+	}
+	buf.WriteString("))" + hashEnd + ") as checksum")
+	buf.Myprintf(" from %v", sqlparser.TableExprs(sel.From))
+	tc.writeWhere(buf, pks, chunk, sel.Where)
+	return buf.String()
+}
+
+// checksumChunk compares the checksums of the rows of the chunk on the sources and on the target,
+// returning the number of rows of the chunk on the target. The workflow must be stopped: the sources
+// are checksummed at their current positions, and the target once its streams reached them.
+func (tc *tableChunker) checksumChunk(ctx context.Context, chunk *pkChunk) (bool, int64, error) {
+	td := tc.td
+	defer td.wd.ct.TableDiffPhaseTimings.Record(fmt.Sprintf("%s.%s", td.table.Name, checksummingChunks), time.Now())
+	ctx, cancel := context.WithTimeout(ctx, BackgroundOperationTimeout)
+	defer cancel()
+	vdiffEngine := td.wd.ct.vde
+	vdiffEngine.snapshotMu.Lock()
+	defer vdiffEngine.snapshotMu.Unlock()
+
+	execute := func(tablet *topodatapb.Tablet, query string) (int64, uint64, error) {
+		res, err := td.wd.ct.tmc.ExecuteFetchAsApp(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsAppRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return 0, 0, vterrors.Wrapf(err, "failed to checksum table %s on tablet %s", td.table.Name, topoproto.TabletAliasString(tablet.Alias))
+		}
+		qr := sqltypes.Proto3ToResult(res)
+		if len(qr.Rows) != 1 {
+			return 0, 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for query %s: %+v", query, qr)
+		}
+		rows, err := qr.Rows[0][0].ToInt64()
+		if err != nil {
+			return 0, 0, err
+		}
+		checksum, err := qr.Rows[0][1].ToUint64()
+		if err != nil {
+			return 0, 0, err
+		}
+		return rows, checksum, nil
+	}
+
+	var (
+		mu             sync.Mutex
+		sourceRows     int64
+		sourceChecksum uint64
+	)
+	sourceQuery := tc.checksumQuery(tc.sourceSelect, tc.sourcePKs, chunk)
+	if err := td.forEachSource(func(source *migrationSource) error {
+		// A row of the chunk changed on the source after its position is read only makes the
+		// checksums differ, and the chunk is then diffed row by row on a consistent snapshot.
+		pos, err := td.wd.ct.tmc.PrimaryPosition(ctx, source.tablet)
+		if err != nil {
+			return vterrors.Wrapf(err, "failed to read the position of tablet %s", topoproto.TabletAliasString(source.tablet.Alias))
+		}
+		source.snapshotPosition = pos
+		rows, checksum, err := execute(source.tablet, sourceQuery)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		sourceRows += rows
+		sourceChecksum ^= checksum
+		return nil
+	}); err != nil {
+		return false, 0, err
+	}
+	if err := td.syncTargetStreams(ctx); err != nil {
+		return false, 0, err
+	}
+	targetRows, targetChecksum, err := execute(td.wd.ct.targetShardStreamer.tablet, tc.checksumQuery(tc.targetSelect, tc.targetPKs, chunk))
+	if err != nil {
+		return false, 0, err
+	}
+	return sourceRows == targetRows && sourceChecksum == targetChecksum, targetRows, nil
+}
+
+// stopWorkflow stops the target streams of the workflow, and waits for the sources to reach their
+// positions, when the first chunk is checksummed or diffed. The target streams are then advanced to
+// the source positions of each checksummed chunk and to the source snapshots of each diffed chunk,
+// and restarted once the table diff is done.
+func (tc *tableChunker) stopWorkflow(ctx context.Context) error {
+	if tc.restartWorkflow != nil {
+		return nil
+	}
+	td := tc.td
+	vdiffEngine := td.wd.ct.vde
+	vdiffEngine.snapshotMu.Lock()
+	defer vdiffEngine.snapshotMu.Unlock()
+
+	ctx, restartWorkflow, err := td.stopWorkflow(ctx)
+	if err != nil {
+		return err
+	}
+	tc.restartWorkflow = restartWorkflow
+	return td.syncSourceStreams(ctx)
+}
+
+// startChunkStreams starts the data streams of the sources and of the target from the start of
+// the chunk.
+func (tc *tableChunker) startChunkStreams(ctx context.Context, chunk *pkChunk) error {
+	td := tc.td
+	defer td.wd.ct.TableDiffPhaseTimings.Record(fmt.Sprintf("%s.%s", td.table.Name, initializing), time.Now())
+	vdiffEngine := td.wd.ct.vde
+	vdiffEngine.snapshotMu.Lock()
+	defer vdiffEngine.snapshotMu.Unlock()
+
+	td.lastSourcePK, td.lastTargetPK = nil, nil
+	if chunk.start != nil {
+		lastPK := td.lastPKFromRow(chunk.start)
+		td.lastSourcePK, td.lastTargetPK = lastPK.Target, lastPK.Target
+	}
+	return td.startDataStreams(ctx)
+}
+
+// diffChunk diffs the rows of the chunk, like a table diff that starts after the start of the chunk
+// and stops at its end.
+func (tc *tableChunker) diffChunk(ctx context.Context, chunk *pkChunk) (*DiffReport, error) {
+	td := tc.td
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		td.wgShardStreamers.Wait()
+		td.chunkEnd = nil
+	}()
+
+	if err := tc.startChunkStreams(ctx, chunk); err != nil {
+		return nil, err
+	}
+	td.chunkEnd = chunk.end
+	return td.diff(ctx, td.wd.opts.CoreOptions, td.wd.opts.ReportOptions, nil)
+}
+
+// diff diffs the table chunk by chunk, resuming after the last diffed chunk. In the sample mode, the
+// chunks are picked at random. In the checksum mode, the rows of the chunks that have the same
+// checksum on the sources and on the target match, and only the rows of the other chunks are diffed.
+func (tc *tableChunker) diff(ctx context.Context, dbClient binlogplayer.DBClient) (*DiffReport, error) {
+	td := tc.td
+	dr, _, err := td.getTableState(dbClient)
+	if err != nil {
+		return nil, err
+	}
+	dr.Mode = tc.mode()
+
+	var start []sqltypes.Value
+	if td.lastTargetPK != nil {
+		if lastPK := sqltypes.Proto3ToResult(td.lastTargetPK); len(lastPK.Rows) == 1 {
+			start = tc.chunkRow(lastPK.Rows[0])
+		}
+	}
+	if err := td.selectTablets(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if tc.restartWorkflow != nil {
+			tc.restartWorkflow()
+			tc.restartWorkflow = nil
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+		case <-td.wd.ct.done:
+			return nil, ErrVDiffStoppedByUser
+		default:
+		}
+
+		end, err := tc.nextChunkEnd(dbClient, start)
+		if err != nil {
+			return nil, err
+		}
+		chunk := &pkChunk{start: start, end: end}
+		dr.Chunks++
+
+		diffRows := true
+		if tc.sample && rand.Int64N(100) >= tc.samplePct {
+			dr.SkippedChunks++
+			diffRows = false
+		} else if tc.checksum {
+			if err := tc.stopWorkflow(ctx); err != nil {
+				return nil, err
+			}
+			match, rows, err := tc.checksumChunk(ctx, chunk)
+			if err != nil {
+				return nil, err
+			}
+			if match {
+				dr.MatchingChunks++
+				dr.ChecksummedRows += rows
+				dr.ProcessedRows += rows
+				dr.MatchingRows += rows
+				diffRows = false
+			}
+		}
+		if diffRows {
+			// The chunk diff starts from the saved report.
+			if err := td.updateTableProgress(dbClient, dr, start); err != nil {
+				return nil, err
+			}
+			if err := tc.stopWorkflow(ctx); err != nil {
+				return nil, err
+			}
+			differences := dr.MismatchedRows + dr.ExtraRowsSource + dr.ExtraRowsTarget
+			if dr, err = tc.diffChunk(ctx, chunk); err != nil {
+				return nil, err
+			}
+			if dr.MismatchedRows+dr.ExtraRowsSource+dr.ExtraRowsTarget > differences {
+				dr.MismatchedChunks++
+			} else {
+				dr.MatchingChunks++
+			}
+		}
+		if end == nil {
+			return dr, nil
+		}
+		if err := td.updateTableProgress(dbClient, dr, end); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+}
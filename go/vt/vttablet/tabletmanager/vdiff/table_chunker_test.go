@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func newTestChunkedTableDiffer(sourceQuery string, coreOptions *tabletmanagerdatapb.VDiffCoreOptions, sourceShards ...string) *tableDiffer {
+	ct := &controller{
+		uuid:    "f4b5ffa4-4ab2-11ee-9e2d-f2d0a8d3e4b2",
+		sources: make(map[string]*migrationSource),
+		vde: &Engine{
+			dbName: "vt_customer",
+			parser: sqlparser.NewTestParser(),
+		},
+	}
+	for _, shard := range sourceShards {
+		ct.sources[shard] = &migrationSource{shardStreamer: &shardStreamer{shard: shard}}
+	}
+	return &tableDiffer{
+		wd: &workflowDiffer{
+			ct:   ct,
+			opts: &tabletmanagerdatapb.VDiffOptions{CoreOptions: coreOptions},
+		},
+		table: &tabletmanagerdatapb.TableDefinition{Name: "customer"},
+		tablePlan: &tablePlan{
+			sourceQuery:  sourceQuery,
+			targetQuery:  "select cid, email from customer order by cid asc",
+			compareCols:  []compareColInfo{{colIndex: 0, colName: "cid", isPK: true}, {colIndex: 1, colName: "email"}},
+			comparePKs:   []compareColInfo{{colIndex: 0, colName: "cid", isPK: true}},
+			pkCols:       []int{0},
+			sourcePkCols: []int{0},
+			table: &tabletmanagerdatapb.TableDefinition{
+				Name: "customer",
+				Fields: []*querypb.Field{
+					{Name: "cid", Type: sqltypes.Int64, Flags: uint32(querypb.MySqlFlag_NOT_NULL_FLAG)},
+					{Name: "email", Type: sqltypes.VarChar},
+				},
+			},
+		},
+	}
+}
+
+func TestNewTableChunker(t *testing.T) {
+	testCases := []struct {
+		name         string
+		sourceQuery  string
+		sourceShards []string
+		options      *tabletmanagerdatapb.VDiffCoreOptions
+		nullablePK   bool
+		wantMode     string
+		wantWhere    string
+	}{
+		{
+			name:        "no chunks",
+			sourceQuery: "select cid, email from customer order by cid asc",
+			options:     &tabletmanagerdatapb.VDiffCoreOptions{SamplePct: 100},
+		},
+		{
+			name:        "checksum",
+			sourceQuery: "select cid, email from customer order by cid asc",
+			options:     &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true},
+			wantMode:    "checksum",
+		},
+		{
+			name:        "checksum and sample",
+			sourceQuery: "select cid, email from customer order by cid asc",
+			options:     &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true, SamplePct: 10},
+			wantMode:    "checksum,sample",
+		},
+		{
+			name:        "nullable primary key",
+			sourceQuery: "select cid, email from customer order by cid asc",
+			options:     &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true, SamplePct: 10},
+			nullablePK:  true,
+		},
+		{
+			name:         "key range of a source shard",
+			sourceQuery:  "select cid, email from customer where in_keyrange(cid, 'customer.hash', '-80') order by cid asc",
+			sourceShards: []string{"0"},
+			options:      &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true, SamplePct: 10},
+			wantMode:     "sample",
+		},
+		{
+			name:         "key range of the source shards",
+			sourceQuery:  "select cid, email from customer where in_keyrange('-80') and email != 'x' order by cid asc",
+			sourceShards: []string{"-40", "40-80"},
+			options:      &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true},
+			wantMode:     "checksum",
+			wantWhere:    " where email != 'x'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			td := newTestChunkedTableDiffer(tc.sourceQuery, tc.options, tc.sourceShards...)
+			if tc.nullablePK {
+				td.tablePlan.table.Fields[0].Flags = 0
+			}
+			chunker, err := newTableChunker(td)
+			require.NoError(t, err)
+			if tc.wantMode == "" {
+				require.Nil(t, chunker)
+				return
+			}
+			require.NotNil(t, chunker)
+			assert.Equal(t, tc.wantMode, chunker.mode())
+			assert.Equal(t, DefaultChunkSize, chunker.size)
+			assert.Equal(t, ChecksumAlgorithmCRC32, chunker.algorithm)
+			if chunker.checksum {
+				assert.Equal(t, tc.wantWhere, sqlparser.String(chunker.sourceSelect.Where))
+			}
+		})
+	}
+
+	td := newTestChunkedTableDiffer("select cid, email from customer", &tabletmanagerdatapb.VDiffCoreOptions{Checksum: true, ChecksumAlgorithm: "xxhash"})
+	_, err := newTableChunker(td)
+	require.ErrorContains(t, err, "unknown checksum algorithm")
+}
+
+func TestTableChunkerQueries(t *testing.T) {
+	td := newTestChunkedTableDiffer("select cid, email from customer where email != 'x' order by cid asc",
+		&tabletmanagerdatapb.VDiffCoreOptions{Checksum: true, ChunkSize: 1000}, "0")
+	chunker, err := newTableChunker(td)
+	require.NoError(t, err)
+	require.NotNil(t, chunker)
+
+	row := func(cid int64) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(cid), {}}
+	}
+	assert.Equal(t, "select count(*) as row_count, bit_xor(crc32(concat_ws('#', cid, email, concat(isnull(cid), isnull(email))))) as checksum from customer where (cid) <= (1000) and (email != 'x')",
+		chunker.checksumQuery(chunker.sourceSelect, chunker.sourcePKs, &pkChunk{end: row(1000)}))
+	assert.Equal(t, "select count(*) as row_count, bit_xor(crc32(concat_ws('#', cid, email, concat(isnull(cid), isnull(email))))) as checksum from customer where (cid) > (1000) and (cid) <= (2000)",
+		chunker.checksumQuery(chunker.targetSelect, chunker.targetPKs, &pkChunk{start: row(1000), end: row(2000)}))
+
+	chunker.algorithm = ChecksumAlgorithmMD5
+	assert.Equal(t, "select count(*) as row_count, bit_xor(cast(conv(left(md5(concat_ws('#', cid, email, concat(isnull(cid), isnull(email)))), 16), 16, 10) as unsigned)) as checksum from customer where (cid) > (2000)",
+		chunker.checksumQuery(chunker.targetSelect, chunker.targetPKs, &pkChunk{start: row(2000)}))
+
+	dbc := binlogplayer.NewMockDBClient(t)
+	dbc.ExpectRequest("select cid from vt_customer.customer order by cid limit 1 offset 999",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("cid", "int64"), "1000"), nil)
+	dbc.ExpectRequest("select cid from vt_customer.customer where (cid) > (1000) order by cid limit 1 offset 999",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("cid", "int64")), nil)
+	end, err := chunker.nextChunkEnd(dbc, nil)
+	require.NoError(t, err)
+	assert.Equal(t, row(1000), end)
+	end, err = chunker.nextChunkEnd(dbc, end)
+	require.NoError(t, err)
+	assert.Nil(t, end)
+	dbc.Wait()
+}
@@ -63,6 +63,7 @@ const (
 	startingTargets        = tableDiffPhase("starting_target_data_streams")
 	restartingVreplication = tableDiffPhase("restarting_vreplication_streams")
 	diffingTable           = tableDiffPhase("diffing_table")
+	checksummingChunks     = tableDiffPhase("checksumming_chunks")
 )
 
 // how long to wait for background operations to complete
@@ -93,6 +94,8 @@ type tableDiffer struct {
 	table        *tabletmanagerdatapb.TableDefinition
 	lastSourcePK *querypb.QueryResult
 	lastTargetPK *querypb.QueryResult
+	// chunkEnd, when diffing a chunk, is the row holding the last PK of the chunk.
+	chunkEnd []sqltypes.Value
 
 	// wgShardStreamers is used, with a cancellable context, to wait for all shard streamers
 	// to finish after each diff is complete.
@@ -112,9 +115,27 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 	vdiffEngine.snapshotMu.Lock()
 	defer vdiffEngine.snapshotMu.Unlock()
 
+	ctx, restartWorkflow, err := td.stopWorkflow(ctx)
+	if err != nil {
+		return err
+	}
+	defer restartWorkflow()
+
+	if err := td.selectTablets(ctx); err != nil {
+		return err
+	}
+	if err := td.syncSourceStreams(ctx); err != nil {
+		return err
+	}
+	return td.startDataStreams(ctx)
+}
+
+// stopWorkflow locks the workflow and stops its target streams, returning the context holding the
+// lock and a function that restarts the streams and unlocks the workflow.
+func (td *tableDiffer) stopWorkflow(ctx context.Context) (context.Context, func(), error) {
 	dbClient := td.wd.ct.dbClientFactory()
 	if err := dbClient.Connect(); err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer dbClient.Close()
 
@@ -124,21 +145,22 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 	ctx, unlock, lockErr := td.wd.ct.ts.LockName(ctx, lockName, "vdiff")
 	if lockErr != nil {
 		log.Errorf("Locking workfkow %s failed: %v", lockName, lockErr)
-		return lockErr
+		return nil, nil, lockErr
 	}
 
 	var err error
-	defer func() {
+	unlockWorkflow := func() {
 		unlock(&err)
 		if err != nil {
 			log.Errorf("Unlocking workflow %s failed: %v", lockName, err)
 		}
-	}()
-
+	}
 	if err := td.stopTargetVReplicationStreams(ctx, dbClient); err != nil {
-		return err
+		unlockWorkflow()
+		return nil, nil, err
 	}
-	defer func() {
+	return ctx, func() {
+		defer unlockWorkflow()
 		// We use a new context as we want to reset the state even
 		// when the parent context has timed out or been canceled.
 		log.Infof("Restarting the %q VReplication workflow on target tablets in keyspace %q",
@@ -148,16 +170,15 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 		if err := td.restartTargetVReplicationStreams(restartCtx); err != nil {
 			log.Errorf("error restarting target streams: %v", err)
 		}
-	}()
+	}, nil
+}
 
+// startDataStreams starts the data streams of the sources from the last source PK, synchronizes
+// the stopped target streams of the workflow with the source snapshots, and starts the data stream
+// of the target from the last target PK.
+func (td *tableDiffer) startDataStreams(ctx context.Context) error {
 	td.shardStreamsCtx, td.shardStreamsCancel = context.WithCancel(ctx)
 
-	if err := td.selectTablets(ctx); err != nil {
-		return err
-	}
-	if err := td.syncSourceStreams(ctx); err != nil {
-		return err
-	}
 	if err := td.startSourceDataStreams(td.shardStreamsCtx); err != nil {
 		return err
 	}
@@ -501,36 +522,21 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 	// We need to continue were we left off when appropriate. This can be an
 	// auto-retry on error, or a manual retry via the resume command.
 	// Otherwise the existing state will be empty and we start from scratch.
-	query, err := sqlparser.ParseAndBind(sqlGetVDiffTable,
-		sqltypes.Int64BindVariable(td.wd.ct.id),
-		sqltypes.StringBindVariable(td.table.Name),
-	)
+	dr, mismatch, err := td.getTableState(dbClient)
 	if err != nil {
 		return nil, err
 	}
-	cs, err := dbClient.ExecuteFetch(query, -1)
-	if err != nil {
-		return nil, err
-	}
-	if len(cs.Rows) == 0 {
-		return nil, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	} else if len(cs.Rows) > 1 {
-		return nil, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	}
-	curState := cs.Named().Row()
-	mismatch := curState.AsBool("mismatch", false)
-	dr := &DiffReport{}
-	if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
-		if err = json.Unmarshal(rpt, dr); err != nil {
-			return nil, err
-		}
-	}
-	dr.TableName = td.table.Name
 
 	sourceExecutor := newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
 	targetExecutor := newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
+	if td.chunkEnd != nil {
+		pastEnd := func(row []sqltypes.Value) bool {
+			c, err := td.compare(row, td.chunkEnd, td.tablePlan.comparePKs, false)
+			return err == nil && c > 0
+		}
+		sourceExecutor.pastEnd = pastEnd
+		targetExecutor.pastEnd = pastEnd
+	}
 	var sourceRow, lastProcessedRow, targetRow []sqltypes.Value
 	advanceSource := true
 	advanceTarget := true
@@ -694,6 +700,38 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 	}
 }
 
+// getTableState returns the saved report of the table diff, and whether a mismatch was flagged.
+func (td *tableDiffer) getTableState(dbClient binlogplayer.DBClient) (*DiffReport, bool, error) {
+	query, err := sqlparser.ParseAndBind(sqlGetVDiffTable,
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	cs, err := dbClient.ExecuteFetch(query, -1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(cs.Rows) == 0 {
+		return nil, false, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
+			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+	} else if len(cs.Rows) > 1 {
+		return nil, false, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
+			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+	}
+	curState := cs.Named().Row()
+	mismatch := curState.AsBool("mismatch", false)
+	dr := &DiffReport{}
+	if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
+		if err = json.Unmarshal(rpt, dr); err != nil {
+			return nil, false, err
+		}
+	}
+	dr.TableName = td.table.Name
+	return dr, mismatch, nil
+}
+
 func (td *tableDiffer) compare(sourceRow, targetRow []sqltypes.Value, cols []compareColInfo, compareOnlyNonPKs bool) (int, error) {
 	for _, col := range cols {
 		if col.isPK && compareOnlyNonPKs {
@@ -232,7 +232,16 @@ func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.D
 		return err
 	}
 
-	for {
+	tc, err := newTableChunker(td)
+	if err != nil {
+		return err
+	}
+	if tc != nil {
+		if diffReport, diffErr = tc.diff(ctx, dbClient); diffErr != nil {
+			return diffErr
+		}
+	}
+	for diffReport == nil {
 		select {
 		case <-ctx.Done():
 			return vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+// copyLimitWindow is the interval over which the copy phase rate limits are
+// averaged.
+const copyLimitWindow = 10 * time.Second
+
+// errCopyPaused is returned by the copyLimiter when the copy schedule no longer
+// allows copying once the rate limits are met.
+var errCopyPaused = errors.New("copy phase paused by the copy schedule")
+
+// copyLimiter enforces the copy phase rate limits and schedule of a workflow.
+// The rows written by the vcopier, and by the vplayer while catching up in the
+// copy phase, count towards the rate limits.
+type copyLimiter struct {
+	maxRowsPerSecond  int64
+	maxBytesPerSecond int64
+	schedule          *vttablet.CopySchedule
+
+	windowStart time.Time
+	rows, bytes int64
+}
+
+func newCopyLimiter(config *vttablet.VReplicationConfig) (*copyLimiter, error) {
+	cl := &copyLimiter{
+		maxRowsPerSecond:  config.CopyPhaseMaxRowsPerSecond,
+		maxBytesPerSecond: config.CopyPhaseMaxBytesPerSecond,
+	}
+	if config.CopyPhaseSchedule != "" {
+		schedule, err := vttablet.ParseCopySchedule(config.CopyPhaseSchedule)
+		if err != nil {
+			return nil, err
+		}
+		cl.schedule = schedule
+	}
+	return cl, nil
+}
+
+// allowed returns true if the copy schedule allows copying at the time.
+func (cl *copyLimiter) allowed(t time.Time) bool {
+	return cl.schedule == nil || cl.schedule.Allows(t)
+}
+
+// nextAllowed returns the next time at which the copy schedule allows copying.
+func (cl *copyLimiter) nextAllowed(t time.Time) (time.Time, error) {
+	if cl.schedule == nil {
+		return t, nil
+	}
+	next := cl.schedule.Next(t)
+	if next.IsZero() {
+		return next, fmt.Errorf("the copy schedule %q never allows copying", cl.schedule)
+	}
+	return next, nil
+}
+
+// limit waits until the rows and bytes written so far are within the rate
+// limits, and then accounts for the rows and bytes about to be written. It
+// returns errCopyPaused if the copy schedule does not allow copying after the
+// wait.
+func (cl *copyLimiter) limit(ctx context.Context, rows, bytes int64) error {
+	if cl.maxRowsPerSecond <= 0 && cl.maxBytesPerSecond <= 0 {
+		return nil
+	}
+	now := time.Now()
+	wait := cl.delay(now)
+	if wait <= 0 && now.Sub(cl.windowStart) > copyLimitWindow {
+		cl.windowStart, cl.rows, cl.bytes = now, 0, 0
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if !cl.allowed(time.Now()) {
+			return errCopyPaused
+		}
+	}
+	cl.rows += rows
+	cl.bytes += bytes
+	return nil
+}
+
+// limitRowEvent calls limit for the row changes of the event.
+func (cl *copyLimiter) limitRowEvent(ctx context.Context, rowEvent *binlogdatapb.RowEvent) error {
+	if cl.maxRowsPerSecond <= 0 && cl.maxBytesPerSecond <= 0 {
+		return nil
+	}
+	var bytes int64
+	for _, change := range rowEvent.RowChanges {
+		if change.After != nil {
+			bytes += int64(len(change.After.Values))
+		} else if change.Before != nil {
+			bytes += int64(len(change.Before.Values))
+		}
+	}
+	return cl.limit(ctx, int64(len(rowEvent.RowChanges)), bytes)
+}
+
+// rowsSize returns the size in bytes of the values of the rows.
+func rowsSize(rows []*querypb.Row) int64 {
+	var size int64
+	for _, row := range rows {
+		size += int64(len(row.Values))
+	}
+	return size
+}
+
+// delay returns how long to wait at the time for the rows and bytes written
+// in the current window to be within the rate limits.
+func (cl *copyLimiter) delay(now time.Time) time.Duration {
+	var d time.Duration
+	if cl.maxRowsPerSecond > 0 {
+		d = max(d, time.Duration(float64(cl.rows)/float64(cl.maxRowsPerSecond)*float64(time.Second)))
+	}
+	if cl.maxBytesPerSecond > 0 {
+		d = max(d, time.Duration(float64(cl.bytes)/float64(cl.maxBytesPerSecond)*float64(time.Second)))
+	}
+	return d - now.Sub(cl.windowStart)
+}
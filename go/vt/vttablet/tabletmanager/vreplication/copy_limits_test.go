@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+func TestCopyLimiterDelay(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name              string
+		maxRowsPerSecond  int64
+		maxBytesPerSecond int64
+		rows, bytes       int64
+		elapsed           time.Duration
+		want              time.Duration
+	}{
+		{
+			name:             "rows within the limit",
+			maxRowsPerSecond: 100,
+			rows:             100,
+			elapsed:          time.Second,
+			want:             0,
+		},
+		{
+			name:             "rows over the limit",
+			maxRowsPerSecond: 100,
+			rows:             300,
+			elapsed:          time.Second,
+			want:             2 * time.Second,
+		},
+		{
+			name:              "bytes over the limit",
+			maxRowsPerSecond:  1000,
+			maxBytesPerSecond: 1000,
+			rows:              10,
+			bytes:             5000,
+			want:              5 * time.Second,
+		},
+		{
+			name:              "the most restrictive limit applies",
+			maxRowsPerSecond:  10,
+			maxBytesPerSecond: 1000,
+			rows:              50,
+			bytes:             2000,
+			elapsed:           time.Second,
+			want:              4 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := &copyLimiter{
+				maxRowsPerSecond:  tt.maxRowsPerSecond,
+				maxBytesPerSecond: tt.maxBytesPerSecond,
+				windowStart:       start,
+				rows:              tt.rows,
+				bytes:             tt.bytes,
+			}
+			require.Equal(t, tt.want, max(0, cl.delay(start.Add(tt.elapsed))))
+		})
+	}
+}
+
+func TestCopyLimiter(t *testing.T) {
+	cl, err := newCopyLimiter(&vttablet.VReplicationConfig{})
+	require.NoError(t, err)
+	require.True(t, cl.allowed(time.Now()))
+	// Without limits, there is never a wait, even with a canceled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, cl.limit(ctx, 1e9, 1e12))
+
+	cl, err = newCopyLimiter(&vttablet.VReplicationConfig{
+		CopyPhaseMaxRowsPerSecond: 1,
+		CopyPhaseSchedule:         "* 1-5 * * *",
+	})
+	require.NoError(t, err)
+	require.True(t, cl.allowed(time.Date(2025, 3, 4, 2, 0, 0, 0, time.UTC)))
+	require.False(t, cl.allowed(time.Date(2025, 3, 4, 7, 0, 0, 0, time.UTC)))
+	next, err := cl.nextAllowed(time.Date(2025, 3, 4, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 3, 5, 1, 0, 0, 0, time.UTC), next)
+
+	// The first row is written right away, the next ones have to wait for it
+	// to be within the limit.
+	require.NoError(t, cl.limit(ctx, 1, 10))
+	require.ErrorIs(t, cl.limit(ctx, 10, 100), context.Canceled)
+	require.ErrorIs(t, cl.limitRowEvent(ctx, &binlogdatapb.RowEvent{
+		RowChanges: []*binlogdatapb.RowChange{
+			{After: &querypb.Row{Values: []byte("abc")}},
+		},
+	}), context.Canceled)
+	require.Equal(t, int64(1), cl.rows)
+	require.Equal(t, int64(10), cl.bytes)
+
+	_, err = newCopyLimiter(&vttablet.VReplicationConfig{CopyPhaseSchedule: "* 24 * * *"})
+	require.Error(t, err)
+	cl, err = newCopyLimiter(&vttablet.VReplicationConfig{CopyPhaseSchedule: "* * 30 2 *"})
+	require.NoError(t, err)
+	_, err = cl.nextAllowed(time.Now())
+	require.ErrorContains(t, err, "never allows copying")
+
+	// Once the rate limits are met, the copy is paused if the copy schedule
+	// does not allow copying anymore.
+	cl, err = newCopyLimiter(&vttablet.VReplicationConfig{
+		CopyPhaseMaxRowsPerSecond: 1000,
+		CopyPhaseSchedule:         "* * 30 2 *",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cl.limit(context.Background(), 1, 10))
+	require.ErrorIs(t, cl.limit(context.Background(), 1, 10), errCopyPaused)
+}
@@ -38,6 +38,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
@@ -108,6 +109,10 @@ type Engine struct {
 
 	throttlerClient *throttle.Client
 
+	// queryService is the query service of the tablet, through which the DDLs
+	// of workflows with the ONLINE DDL action are submitted to Online DDL.
+	queryService queryservice.QueryService
+
 	// This should only be set in Test Engines in order to short
 	// circuit functions as needed in unit tests. It's automatically
 	// enabled in NewSimpleTestEngine. This should NOT be used in
@@ -161,6 +166,23 @@ func (vre *Engine) InitDBConfig(dbcfgs *dbconfigs.DBConfigs) {
 	vre.dbName = dbcfgs.DBName
 }
 
+// InitQueryService sets the query service of the tablet.
+func (vre *Engine) InitQueryService(qs queryservice.QueryService) {
+	vre.queryService = qs
+}
+
+// submitOnlineDDL submits the statement, which is annotated with the Online DDL
+// directives, to the Online DDL executor of the tablet.
+func (vre *Engine) submitOnlineDDL(sql string) error {
+	if vre.queryService == nil {
+		return vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "Online DDL is not available on this tablet")
+	}
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), onlineDDLSubmitTimeout)
+	defer cancel()
+	_, err := vre.queryService.Execute(ctx, nil, sql, nil, 0, 0, nil)
+	return err
+}
+
 // NewTestEngine creates a new Engine for testing.
 func NewTestEngine(ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaemon, dbClientFactoryFiltered func() binlogplayer.DBClient, dbClientFactoryDba func() binlogplayer.DBClient, dbname string, externalConfig map[string]*dbconfigs.DBConfigs) *Engine {
 	env := vtenv.NewTestEnv()
@@ -18,11 +18,14 @@ package vreplication
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"vitess.io/vitess/go/bytes2"
 	"vitess.io/vitess/go/mysql/collations"
@@ -35,6 +38,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	vttablet "vitess.io/vitess/go/vt/vttablet/common"
@@ -64,6 +68,7 @@ type ReplicatorPlan struct {
 	ColInfoMap     map[string][]*ColumnInfo
 	stats          *binlogplayer.Stats
 	Source         *binlogdatapb.BinlogSource
+	env            *vtenv.Environment
 	collationEnv   *collations.Environment
 	workflowConfig *vttablet.VReplicationConfig
 }
@@ -110,6 +115,7 @@ func (rp *ReplicatorPlan) buildFromFields(tableName string, lastpk *sqltypes.Res
 		colInfos:       rp.ColInfoMap[tableName],
 		stats:          rp.stats,
 		source:         rp.Source,
+		env:            rp.env,
 		collationEnv:   rp.collationEnv,
 		workflowConfig: rp.workflowConfig,
 	}
@@ -225,6 +231,13 @@ type TablePlan struct {
 	PartialInserts map[string]*sqlparser.ParsedQuery
 	// PartialUpdates are same as PartialInserts, but for update statements
 	PartialUpdates map[string]*sqlparser.ParsedQuery
+	// partialMu protects PartialInserts and PartialUpdates, which are
+	// accessed concurrently when the vplayer applies transactions in parallel.
+	// It is a pointer because the caches are shared by copies of the plan.
+	partialMu *sync.Mutex
+	// evalExprs are the column expressions evaluated by vttablet. Their
+	// results are bound before the row change is applied.
+	evalExprs []*colExpr
 
 	CollationEnv   *collations.Environment
 	WorkflowConfig *vttablet.VReplicationConfig
@@ -265,6 +278,12 @@ func (tp *TablePlan) applyBulkInsert(sqlbuffer *bytes2.Buffer, rows []*querypb.R
 		if i > 0 {
 			sqlbuffer.WriteString(", ")
 		}
+		if len(tp.evalExprs) > 0 {
+			if err := tp.appendEvaluatedRow(sqlbuffer, row); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		if err := tp.appendFromRow(sqlbuffer, row); err != nil {
 			return nil, err
 		}
@@ -382,6 +401,9 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 			}
 			bindvars["b_"+field.Name] = bindVar
 		}
+		if err := tp.bindEvalExprs(bindvars, "b_", rowChange.DataColumns); err != nil {
+			return nil, err
+		}
 	}
 	if rowChange.After != nil {
 		jsonIndex := 0
@@ -432,6 +454,9 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 			}
 			bindvars["a_"+field.Name] = bindVar
 		}
+		if err := tp.bindEvalExprs(bindvars, "a_", rowChange.DataColumns); err != nil {
+			return nil, err
+		}
 	}
 	switch {
 	case !before && after:
@@ -653,6 +678,9 @@ func (tp *TablePlan) applyBulkInsertChanges(rowInserts []*binlogdatapb.RowChange
 			}
 			bindvars["a_"+field.Name] = bindVar
 		}
+		if err := tp.bindEvalExprs(bindvars, "a_", nil); err != nil {
+			return nil, err
+		}
 		if err := tp.BulkInsertValues.Append(rowValues, bindvars, nil); err != nil {
 			return nil, err
 		}
@@ -796,3 +824,87 @@ func (tp *TablePlan) appendFromRow(buf *bytes2.Buffer, row *querypb.Row) error {
 	buf.WriteString(tp.BulkInsertValues.Query[offsetQuery:])
 	return nil
 }
+
+// appendEvaluatedRow is the counterpart of appendFromRow for plans which have
+// expressions evaluated by vttablet. The values of those expressions are not
+// part of the row, so the bind locations can't be filled positionally and the
+// row is bound by name instead.
+func (tp *TablePlan) appendEvaluatedRow(buf *bytes2.Buffer, row *querypb.Row) error {
+	bindvars := make(map[string]*querypb.BindVariable, len(tp.Fields)+len(tp.evalExprs))
+	vals := sqltypes.MakeRowTrusted(tp.Fields, row)
+	for i, field := range tp.Fields {
+		val := &vals[i]
+		if field.Type == querypb.Type_JSON && !val.IsNull() {
+			var err error
+			if val, err = vjson.MarshalSQLValue(val.Raw()); err != nil {
+				return err
+			}
+		}
+		bindVar, err := tp.bindFieldVal(field, val)
+		if err != nil {
+			return err
+		}
+		bindvars["a_"+field.Name] = bindVar
+	}
+	if err := tp.bindEvalExprs(bindvars, "a_", nil); err != nil {
+		return err
+	}
+	values, err := tp.BulkInsertValues.GenerateQuery(bindvars, nil)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(values)
+	return nil
+}
+
+// bindEvalExprs evaluates the expressions of the plan which are computed by
+// vttablet against the row image identified by prefix ("b_" for the before
+// image and "a_" for the after image), and adds their results to bindvars.
+// dataColumns is the bitmap of the columns present in a partial row image, if
+// any. An expression can only be evaluated if every column it references is
+// present in the image: binding a missing column as NULL would silently
+// write a wrong value to the target.
+func (tp *TablePlan) bindEvalExprs(bindvars map[string]*querypb.BindVariable, prefix string, dataColumns *binlogdatapb.RowChange_Bitmap) error {
+	if len(tp.evalExprs) == 0 {
+		return nil
+	}
+	tpb := tp.TablePlanBuilder
+	env := evalengine.NewExpressionEnv(context.Background(), nil, evalengine.NewEmptyVCursor(tpb.env, time.Local))
+	for _, cexpr := range tp.evalExprs {
+		env.Row = env.Row[:0]
+		for _, ref := range cexpr.evalRefs {
+			bv, ok := bindvars[prefix+ref]
+			if !ok || bv.Type == querypb.Type_EXPRESSION || !tp.hasColumnData(dataColumns, ref) {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION,
+					"cannot evaluate expression for %s.%s: column %s is not present in the row image, columns computed by vttablet require binlog_row_image=FULL and binlog_row_value_options=''",
+					tp.TargetName, cexpr.colName.String(), ref)
+			}
+			val, err := sqltypes.BindVariableToValue(bv)
+			if err != nil {
+				return err
+			}
+			env.Row = append(env.Row, val)
+		}
+		result, err := env.Evaluate(cexpr.eval)
+		if err != nil {
+			return vterrors.Wrapf(err, "failed to evaluate expression for %s.%s", tp.TargetName, cexpr.colName.String())
+		}
+		name := cexpr.expr.(*sqlparser.ColName).Name.String()
+		bindvars[prefix+name] = sqltypes.ValueBindVariable(result.Value(tpb.collationEnv.DefaultConnectionCharset()))
+	}
+	return nil
+}
+
+// hasColumnData returns true if the named column is present in a row image
+// whose data columns bitmap is dataColumns. A nil bitmap denotes a full image.
+func (tp *TablePlan) hasColumnData(dataColumns *binlogdatapb.RowChange_Bitmap, name string) bool {
+	if dataColumns == nil || dataColumns.Count == 0 {
+		return true
+	}
+	for i, field := range tp.Fields {
+		if field.Name == name {
+			return isBitSet(dataColumns.Cols, i)
+		}
+	}
+	return false
+}
@@ -25,10 +25,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/bytes2"
-	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -743,7 +743,7 @@ func TestBuildPlayerPlan(t *testing.T) {
 		vr := &vreplicator{
 			workflowConfig: vttablet.DefaultVReplicationConfig,
 		}
-		plan, err := vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+		plan, err := vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 		gotErr := ""
 		if err != nil {
 			gotErr = err.Error()
@@ -752,7 +752,7 @@ func TestBuildPlayerPlan(t *testing.T) {
 		gotPlan, _ := json.Marshal(plan)
 		wantPlan, _ := json.Marshal(tcase.plan)
 		require.Equal(t, string(wantPlan), string(gotPlan), "Filter(%v):\n%s, want\n%s", tcase.input, gotPlan, wantPlan)
-		plan, err = vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, copyState, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+		plan, err = vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, copyState, binlogplayer.NewStats(), vtenv.NewTestEnv())
 		if err != nil {
 			continue
 		}
@@ -783,7 +783,7 @@ func TestBuildPlayerPlanNoDup(t *testing.T) {
 	vr := &vreplicator{
 		workflowConfig: vttablet.DefaultVReplicationConfig,
 	}
-	_, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+	_, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 	want := "more than one target for source table t"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("buildReplicatorPlan err: %v, must contain: %v", err, want)
@@ -807,7 +807,7 @@ func TestBuildPlayerPlanExclude(t *testing.T) {
 	vr := &vreplicator{
 		workflowConfig: vttablet.DefaultVReplicationConfig,
 	}
-	plan, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+	plan, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 	assert.NoError(t, err)
 
 	want := &TestReplicatorPlan{
@@ -831,6 +831,91 @@ func TestBuildPlayerPlanExclude(t *testing.T) {
 	assert.Equal(t, string(gotPlan), string(wantPlan))
 }
 
+func TestBuildPlayerPlanEvalExprs(t *testing.T) {
+	PrimaryKeyInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "id", IsPK: true}},
+	}
+	input := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "t1",
+			Filter: "select id, sha2(concat('salt', email), 256) as email, null as ssn, left(name, 1) as name, " +
+				"case when age < 18 then 'minor' else 'adult' end as age from t1",
+		}},
+	}
+	config := vttablet.GetDefaultVReplicationConfig()
+	config.ExperimentalFlags |= vttablet.VReplicationExperimentalFlagEvaluateExpressions
+	vr := &vreplicator{
+		workflowConfig: config,
+	}
+	plan, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	require.Equal(t, "select id, email, `name`, age from t1", plan.VStreamFilter.Rules[0].Filter)
+
+	tp := plan.TargetTables["t1"]
+	require.Len(t, tp.evalExprs, 4)
+	require.Equal(t, "insert into t1(id,email,ssn,`name`,age) values (:a_id,:a__vt_eval_email,:a__vt_eval_ssn,:a__vt_eval_name,:a__vt_eval_age)", tp.Insert.Query)
+	require.Equal(t, "update t1 set email=:a__vt_eval_email, ssn=:a__vt_eval_ssn, `name`=:a__vt_eval_name, age=:a__vt_eval_age where id=:b_id", tp.Update.Query)
+
+	tp, err = plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t1",
+		Fields: sqltypes.MakeTestFields(
+			"id|email|name|age",
+			"int64|varchar|varchar|int64",
+		),
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	executor := func(query string) (*sqltypes.Result, error) {
+		queries = append(queries, query)
+		return &sqltypes.Result{}, nil
+	}
+	row := sqltypes.RowToProto3([]sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewVarChar("jane@example.com"),
+		sqltypes.NewVarChar("Jane"),
+		sqltypes.NewInt64(16),
+	})
+	_, err = tp.applyChange(&binlogdatapb.RowChange{After: row}, executor)
+	require.NoError(t, err)
+	_, err = tp.applyBulkInsert(&bytes2.Buffer{}, []*querypb.Row{row}, executor)
+	require.NoError(t, err)
+
+	updated := sqltypes.RowToProto3([]sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewVarChar("jane@example.com"),
+		sqltypes.NULL,
+		sqltypes.NewInt64(18),
+	})
+	_, err = tp.applyChange(&binlogdatapb.RowChange{Before: row, After: updated}, executor)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"insert into t1(id,email,ssn,`name`,age) values (1,'2e8a628b9d63eec579c8f56615556cc4752ed969e560a5f417d83643933a7194',null,'J','minor')",
+		"insert into t1(id,email,ssn,`name`,age) values (1,'2e8a628b9d63eec579c8f56615556cc4752ed969e560a5f417d83643933a7194',null,'J','minor')",
+		"update t1 set email='2e8a628b9d63eec579c8f56615556cc4752ed969e560a5f417d83643933a7194', ssn=null, `name`=null, age='adult' where id=1",
+	}, queries)
+
+	// A partial row image which lacks a referenced column is rejected
+	// instead of evaluating the expression with a NULL.
+	queries = nil
+	_, err = tp.applyChange(&binlogdatapb.RowChange{
+		Before: row,
+		After:  updated,
+		DataColumns: &binlogdatapb.RowChange_Bitmap{
+			Count: 4,
+			Cols:  []byte{0b1011},
+		},
+	}, executor)
+	require.ErrorContains(t, err, "cannot evaluate expression for t1.name: column name is not present in the row image")
+	require.Empty(t, queries)
+
+	// Expressions which can't be evaluated by vttablet are rejected rather
+	// than silently sent to the target.
+	input.Rules[0].Filter = "select id, foo(email) as email from t1"
+	_, err = vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.ErrorContains(t, err, "unsupported expression for evaluation in vttablet: foo(email)")
+}
+
 func TestAppendFromRow(t *testing.T) {
 	testCases := []struct {
 		name    string
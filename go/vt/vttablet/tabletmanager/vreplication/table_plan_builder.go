@@ -19,8 +19,10 @@ package vreplication
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
@@ -28,7 +30,9 @@ import (
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -44,6 +48,11 @@ import (
 // TODO(sougou): support this on vstreamer side also.
 const ExcludeStr = "exclude"
 
+// evalBindVarPrefix is prepended to the target column name to build the bind
+// variable that holds the value of an expression evaluated by vttablet. It keeps
+// that bind variable from clashing with the ones built from the source fields.
+const evalBindVarPrefix = "_vt_eval_"
+
 // tablePlanBuilder contains the metadata needed for building a TablePlan.
 type tablePlanBuilder struct {
 	name       sqlparser.IdentifierCS
@@ -61,6 +70,7 @@ type tablePlanBuilder struct {
 	source            *binlogdatapb.BinlogSource
 	pkIndices         []bool
 
+	env            *vtenv.Environment
 	collationEnv   *collations.Environment
 	workflowConfig *vttablet.VReplicationConfig
 }
@@ -79,6 +89,12 @@ type colExpr struct {
 	expr sqlparser.Expr
 	// references contains all the column names referenced in the expression.
 	references map[string]bool
+	// eval is set if the expression is evaluated by vttablet instead of the
+	// target MySQL. In that case, expr is a column named after the bind
+	// variable holding the result, and evalRefs lists the referenced columns
+	// in the order in which they're passed to eval as a row.
+	eval     evalengine.Expr
+	evalRefs []string
 
 	isGrouped   bool
 	isPK        bool
@@ -133,7 +149,7 @@ const (
 // The TablePlan built is a partial plan. The full plan for a table is built
 // when we receive field information from events or rows sent by the source.
 // buildExecutionPlan is the function that builds the full plan.
-func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, colInfoMap map[string][]*ColumnInfo, copyState map[string]*sqltypes.Result, stats *binlogplayer.Stats, collationEnv *collations.Environment, parser *sqlparser.Parser) (*ReplicatorPlan, error) {
+func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, colInfoMap map[string][]*ColumnInfo, copyState map[string]*sqltypes.Result, stats *binlogplayer.Stats, env *vtenv.Environment) (*ReplicatorPlan, error) {
 	filter := source.Filter
 	plan := &ReplicatorPlan{
 		VStreamFilter:  &binlogdatapb.Filter{FieldEventMode: filter.FieldEventMode},
@@ -142,7 +158,8 @@ func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, co
 		ColInfoMap:     colInfoMap,
 		stats:          stats,
 		Source:         source,
-		collationEnv:   collationEnv,
+		env:            env,
+		collationEnv:   env.CollationEnv(),
 		workflowConfig: vr.workflowConfig,
 	}
 	for tableName := range colInfoMap {
@@ -162,7 +179,7 @@ func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, co
 		if !ok {
 			return nil, fmt.Errorf("table %s not found in schema", tableName)
 		}
-		tablePlan, err := buildTablePlan(tableName, rule, colInfos, lastpk, stats, source, collationEnv, parser, vr.workflowConfig)
+		tablePlan, err := buildTablePlan(tableName, rule, colInfos, lastpk, stats, source, env, vr.workflowConfig)
 		if err != nil {
 			return nil, vterrors.Wrapf(err, "failed to build table replication plan for %s table", tableName)
 		}
@@ -202,8 +219,8 @@ func MatchTable(tableName string, filter *binlogdatapb.Filter) (*binlogdatapb.Ru
 }
 
 func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*ColumnInfo, lastpk *sqltypes.Result,
-	stats *binlogplayer.Stats, source *binlogdatapb.BinlogSource, collationEnv *collations.Environment,
-	parser *sqlparser.Parser, workflowConfig *vttablet.VReplicationConfig) (*TablePlan, error) {
+	stats *binlogplayer.Stats, source *binlogdatapb.BinlogSource, env *vtenv.Environment,
+	workflowConfig *vttablet.VReplicationConfig) (*TablePlan, error) {
 
 	planError := func(err error, query string) error {
 		// Use the error string here to ensure things are uniform across
@@ -226,7 +243,7 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 	case filter == ExcludeStr:
 		return nil, nil
 	}
-	sel, fromTable, err := analyzeSelectFrom(query, parser)
+	sel, fromTable, err := analyzeSelectFrom(query, env.Parser())
 	if err != nil {
 		return nil, planError(err, query)
 	}
@@ -251,7 +268,7 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 			Stats:            stats,
 			ConvertCharset:   rule.ConvertCharset,
 			ConvertIntToEnum: rule.ConvertIntToEnum,
-			CollationEnv:     collationEnv,
+			CollationEnv:     env.CollationEnv(),
 			WorkflowConfig:   workflowConfig,
 		}
 
@@ -268,7 +285,8 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 		colInfos:       colInfos,
 		stats:          stats,
 		source:         source,
-		collationEnv:   collationEnv,
+		env:            env,
+		collationEnv:   env.CollationEnv(),
 		workflowConfig: workflowConfig,
 	}
 
@@ -356,6 +374,13 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 
 	bvf := &bindvarFormatter{}
 
+	var evalExprs []*colExpr
+	for _, cexpr := range tpb.colExprs {
+		if cexpr.eval != nil {
+			evalExprs = append(evalExprs, cexpr)
+		}
+	}
+
 	fieldsToSkip := make(map[string]bool)
 	for _, colInfo := range tpb.colInfos {
 		if colInfo.IsGenerated {
@@ -380,6 +405,8 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		TablePlanBuilder:        tpb,
 		PartialInserts:          make(map[string]*sqlparser.ParsedQuery, 0),
 		PartialUpdates:          make(map[string]*sqlparser.ParsedQuery, 0),
+		partialMu:               &sync.Mutex{},
+		evalExprs:               evalExprs,
 		CollationEnv:            tpb.collationEnv,
 		WorkflowConfig:          tpb.workflowConfig,
 	}
@@ -530,10 +557,45 @@ func (tpb *tablePlanBuilder) analyzeExpr(selExpr sqlparser.SelectExpr) (*colExpr
 	if err != nil {
 		return nil, err
 	}
+	if _, ok := aliased.Expr.(*sqlparser.ColName); !ok && tpb.workflowConfig.ExperimentalFlags&vttablet.VReplicationExperimentalFlagEvaluateExpressions != 0 {
+		if err := tpb.compileExpr(cexpr, aliased.Expr); err != nil {
+			return nil, err
+		}
+		return cexpr, nil
+	}
 	cexpr.expr = aliased.Expr
 	return cexpr, nil
 }
 
+// compileExpr translates a select expression so that it can be evaluated by
+// vttablet for every row image, using the evalengine. The target MySQL then
+// only receives the computed value, which allows masking or tokenizing
+// columns without the source values ever reaching the target.
+func (tpb *tablePlanBuilder) compileExpr(cexpr *colExpr, expr sqlparser.Expr) error {
+	refs := make([]string, 0, len(cexpr.references))
+	for ref := range cexpr.references {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	eval, err := evalengine.Translate(expr, &evalengine.Config{
+		ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+			if idx := slices.Index(refs, col.Name.String()); idx >= 0 {
+				return idx, nil
+			}
+			return 0, fmt.Errorf("unknown column: %v", sqlparser.String(col))
+		},
+		Collation:   tpb.collationEnv.DefaultConnectionCharset(),
+		Environment: tpb.env,
+	})
+	if err != nil {
+		return fmt.Errorf("unsupported expression for evaluation in vttablet: %v: %v", sqlparser.String(expr), err)
+	}
+	cexpr.eval = eval
+	cexpr.evalRefs = refs
+	cexpr.expr = &sqlparser.ColName{Name: sqlparser.NewIdentifierCI(evalBindVarPrefix + cexpr.colName.String())}
+	return nil
+}
+
 // addCol adds the specified column to the send query
 // if it's not already present.
 func (tpb *tablePlanBuilder) addCol(ident sqlparser.IdentifierCI) {
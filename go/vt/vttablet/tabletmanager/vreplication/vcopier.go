@@ -18,6 +18,7 @@ package vreplication
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -220,7 +221,7 @@ func newVCopierCopyWorker(
 func (vc *vcopier) initTablesForCopy(ctx context.Context) error {
 	defer vc.vr.dbClient.Rollback()
 
-	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env.CollationEnv(), vc.vr.vre.env.Parser())
+	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env)
 	if err != nil {
 		return err
 	}
@@ -314,6 +315,9 @@ func (vc *vcopier) copyNext(ctx context.Context, settings binlogplayer.VRSetting
 	if len(copyState) == 0 {
 		return fmt.Errorf("unexpected: there are no tables to copy")
 	}
+	if now := time.Now(); !vc.vr.copyLimiter.allowed(now) {
+		return vc.waitForCopySchedule(ctx, copyState, now)
+	}
 	if err := vc.catchup(ctx, copyState); err != nil {
 		return err
 	}
@@ -371,6 +375,36 @@ func (vc *vcopier) catchup(ctx context.Context, copyState map[string]*sqltypes.R
 	}
 }
 
+// waitForCopySchedule waits until the copy schedule of the workflow allows
+// copying again. In the meantime, the tables that have already been copied
+// are kept up to date.
+func (vc *vcopier) waitForCopySchedule(ctx context.Context, copyState map[string]*sqltypes.Result, now time.Time) error {
+	next, err := vc.vr.copyLimiter.nextAllowed(now)
+	if err != nil {
+		return err
+	}
+	if err := vc.vr.setMessage(fmt.Sprintf("Copy phase paused by the copy schedule %q until %s", vc.vr.copyLimiter.schedule, next.Format(time.RFC3339))); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithDeadline(ctx, next)
+	defer cancel()
+
+	settings, err := binlogplayer.ReadVRSettings(vc.vr.dbClient, vc.vr.id)
+	if err != nil {
+		return err
+	}
+	if settings.StartPos.IsZero() {
+		// Nothing has been copied yet, so there is nothing to keep up to date.
+		<-ctx.Done()
+	} else if err := newVPlayer(vc.vr, settings, copyState, replication.Position{}, "catchup").play(ctx); err != nil && ctx.Err() == nil {
+		return err
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return vc.vr.setMessage("Copy phase resumed by the copy schedule")
+	}
+	return nil
+}
+
 // copyTable performs the synchronized copy of the next set of rows from
 // the current table being copied. Each packet received is transactionally
 // committed with the lastpk. This allows for consistent resumability.
@@ -381,7 +415,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 
 	log.Infof("Copying table %s, lastpk: %v", tableName, copyState[tableName])
 
-	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env.CollationEnv(), vc.vr.vre.env.Parser())
+	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env)
 	if err != nil {
 		return err
 	}
@@ -457,6 +491,12 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 				return io.EOF
 			default:
 			}
+			if !vc.vr.copyLimiter.allowed(time.Now()) {
+				// Stop copying, the next copyNext waits for the copy schedule
+				// to allow copying again.
+				cancel()
+				return io.EOF
+			}
 			if rows.Throttled {
 				_ = vc.vr.updateTimeThrottled(throttlerapp.RowStreamerName, rows.ThrottledReason)
 				return nil
@@ -503,6 +543,15 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		if len(rows.Rows) == 0 {
 			return nil
 		}
+		if err := vc.vr.copyLimiter.limit(ctx, int64(len(rows.Rows)), rowsSize(rows.Rows)); err != nil {
+			if errors.Is(err, errCopyPaused) {
+				// Stop copying, the next copyNext waits for the copy schedule
+				// to allow copying again.
+				cancel()
+				return io.EOF
+			}
+			return err
+		}
 
 		// Clone rows, since pointer values will change while async work is
 		// happening. Can skip this when there's no parallelism.
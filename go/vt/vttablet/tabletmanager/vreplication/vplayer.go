@@ -67,6 +67,18 @@ type vplayer struct {
 	// If the VPlayer is in batch mode, we accumulate each transaction's statements
 	// that are then sent as a single multi-statement protocol request to the database.
 	batchMode bool
+	// parallelWorkers is the number of connections over which transactions are
+	// applied concurrently in the running phase. If it is zero, the transactions
+	// are applied one at a time.
+	parallelWorkers int
+	// parallel is set while the vplayer applies transactions concurrently.
+	parallel *parallelApplier
+	// origin is set for Replicate workflows to skip the transactions which
+	// originated in the target shard.
+	origin *originFilter
+	// onlineDDL is set in the running phase of workflows with the ONLINE DDL
+	// action to hold back the changes of the tables altered by Online DDL.
+	onlineDDL *onlineDDLPauses
 
 	pos replication.Position
 	// unsavedEvent is set any time we skip an event without
@@ -167,6 +179,17 @@ func newVPlayer(vr *vreplicator, settings binlogplayer.VRSettings, copyState map
 		vr.dbClient.maxBatchSize = maxAllowedPacket
 	}
 
+	// Transactions are only applied concurrently in the running phase, and not
+	// if we have to stop at a position. Replicate workflows have to see the
+	// origin of each transaction before applying it, and workflows with the
+	// ONLINE DDL action filter the changes by position, so they do not use it.
+	parallelWorkers := 0
+	if len(copyState) == 0 && settings.StopPos.IsZero() && vr.workflowConfig.ParallelApplyWorkers > 1 &&
+		vr.WorkflowType != int32(binlogdatapb.VReplicationWorkflowType_Replicate) &&
+		vr.source.OnDdl != binlogdatapb.OnDDLAction_ONLINE {
+		parallelWorkers = vr.workflowConfig.ParallelApplyWorkers
+	}
+
 	return &vplayer{
 		vr:               vr,
 		startPos:         settings.StartPos,
@@ -181,6 +204,7 @@ func newVPlayer(vr *vreplicator, settings binlogplayer.VRSettings, copyState map
 		query:            queryFunc,
 		commit:           commitFunc,
 		batchMode:        batchMode,
+		parallelWorkers:  parallelWorkers,
 	}
 }
 
@@ -194,7 +218,7 @@ func (vp *vplayer) play(ctx context.Context) error {
 		return nil
 	}
 
-	plan, err := vp.vr.buildReplicatorPlan(vp.vr.source, vp.vr.colInfoMap, vp.copyState, vp.vr.stats, vp.vr.vre.env.CollationEnv(), vp.vr.vre.env.Parser())
+	plan, err := vp.vr.buildReplicatorPlan(vp.vr.source, vp.vr.colInfoMap, vp.copyState, vp.vr.stats, vp.vr.vre.env)
 	if err != nil {
 		vp.vr.stats.ErrorCounts.Add([]string{"Plan"}, 1)
 		return err
@@ -210,6 +234,29 @@ func (vp *vplayer) play(ctx context.Context) error {
 		}
 	}
 
+	if vp.vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_Replicate) {
+		vp.origin, err = newOriginFilter(vp.vr.dbClient)
+		if err != nil {
+			return vterrors.Wrap(err, "failed to load the local GTIDs of the Replicate workflow")
+		}
+	}
+
+	if vp.vr.source.OnDdl == binlogdatapb.OnDDLAction_ONLINE && len(vp.copyState) == 0 {
+		var failure string
+		vp.onlineDDL, failure, err = newOnlineDDLPauses(vp.vr.dbClient, vp.vr.id)
+		if err != nil {
+			return vterrors.Wrap(err, "failed to load the tables held back for Online DDL")
+		}
+		if failure != "" {
+			return vp.vr.setState(binlogdatapb.VReplicationWorkflowState_Error, failure)
+		}
+		if start := vp.onlineDDL.startReplay(vp.startPos); !start.IsZero() {
+			log.Infof("Replaying the changes of the tables altered by Online DDL from %v to %v", start, vp.startPos)
+			vp.startPos = start
+			vp.pos = start
+		}
+	}
+
 	return vp.fetchAndApply(ctx)
 }
 
@@ -220,17 +267,7 @@ func (vp *vplayer) play(ctx context.Context) error {
 // - If unset (0), foreign key checks are enabled.
 // updateFKCheck also updates the state for the first row event that this vplayer, and hence the db connection, sees.
 func (vp *vplayer) updateFKCheck(ctx context.Context, flags2 uint32) error {
-	mustUpdate := false
-	if vp.vr.WorkflowSubType == int32(binlogdatapb.VReplicationWorkflowSubType_AtomicCopy) {
-		// If this is an atomic copy, we must update the foreign_key_checks state even when the vplayer runs during
-		// the copy phase, i.e., for catchup and fastforward.
-		mustUpdate = true
-	} else if vp.vr.state == binlogdatapb.VReplicationWorkflowState_Running {
-		// If the vreplication workflow is in Running state, we must update the foreign_key_checks
-		// state for all workflow types.
-		mustUpdate = true
-	}
-	if !mustUpdate {
+	if !vp.mustUpdateFKCheck() {
 		return nil
 	}
 	dbForeignKeyChecksEnabled := !(flags2&NoForeignKeyCheckFlagBitmask == NoForeignKeyCheckFlagBitmask)
@@ -251,6 +288,18 @@ func (vp *vplayer) updateFKCheck(ctx context.Context, flags2 uint32) error {
 	return nil
 }
 
+// mustUpdateFKCheck returns true if the foreign_key_checks state of the source must be applied.
+func (vp *vplayer) mustUpdateFKCheck() bool {
+	if vp.vr.WorkflowSubType == int32(binlogdatapb.VReplicationWorkflowSubType_AtomicCopy) {
+		// If this is an atomic copy, we must update the foreign_key_checks state even when the vplayer runs during
+		// the copy phase, i.e., for catchup and fastforward.
+		return true
+	}
+	// If the vreplication workflow is in Running state, we must update the foreign_key_checks
+	// state for all workflow types.
+	return vp.vr.state == binlogdatapb.VReplicationWorkflowState_Running
+}
+
 // fetchAndApply performs the fetching and application of the binlogs.
 // This is done by two different threads. The fetcher thread pulls
 // events from the vstreamer and adds them to the relayLog.
@@ -274,6 +323,9 @@ func (vp *vplayer) fetchAndApply(ctx context.Context) (err error) {
 		vstreamOptions := &binlogdatapb.VStreamOptions{
 			ConfigOverrides: vp.vr.workflowConfig.Overrides,
 		}
+		if vp.origin != nil {
+			vstreamOptions.InternalTables = []string{originTable}
+		}
 		streamErr <- vp.vr.sourceVStreamer.VStream(ctx, replication.EncodePosition(vp.startPos), nil,
 			vp.replicatorPlan.VStreamFilter, func(events []*binlogdatapb.VEvent) error {
 				return relay.Send(events)
@@ -346,17 +398,7 @@ func (vp *vplayer) applyRowEvent(ctx context.Context, rowEvent *binlogdatapb.Row
 	if tplan == nil {
 		return fmt.Errorf("unexpected event on table %s", rowEvent.TableName)
 	}
-	applyFunc := func(sql string) (*sqltypes.Result, error) {
-		start := time.Now()
-		qr, err := vp.query(ctx, sql)
-		vp.vr.stats.QueryCount.Add(vp.phase, 1)
-		vp.vr.stats.QueryTimings.Record(vp.phase, start)
-		if vp.vr.workflowConfig.EnableHttpLog {
-			stats := NewVrLogStats("ROWCHANGE", start)
-			stats.Send(sql)
-		}
-		return qr, err
-	}
+	applyFunc := vp.rowChangeExecutor(ctx, vp.query)
 
 	if vp.batchMode && len(rowEvent.RowChanges) > 1 {
 		// If we have multiple delete row events for a table with a single PK column
@@ -384,6 +426,40 @@ func (vp *vplayer) applyRowEvent(ctx context.Context, rowEvent *binlogdatapb.Row
 	return nil
 }
 
+// rowChangeExecutor returns the function which executes the statements of
+// row changes using query.
+func (vp *vplayer) rowChangeExecutor(ctx context.Context, query func(ctx context.Context, sql string) (*sqltypes.Result, error)) func(string) (*sqltypes.Result, error) {
+	return func(sql string) (*sqltypes.Result, error) {
+		start := time.Now()
+		qr, err := query(ctx, sql)
+		vp.vr.stats.QueryCount.Add(vp.phase, 1)
+		vp.vr.stats.QueryTimings.Record(vp.phase, start)
+		if vp.vr.workflowConfig.EnableHttpLog {
+			stats := NewVrLogStats("ROWCHANGE", start)
+			stats.Send(sql)
+		}
+		return qr, err
+	}
+}
+
+// begin starts a transaction on the target if one is not already open. For
+// Replicate workflows, the transaction is tagged with the source position
+// before any change is applied, see originFilter.
+func (vp *vplayer) begin(ctx context.Context) error {
+	if err := vp.vr.dbClient.Begin(); err != nil {
+		return err
+	}
+	if vp.origin == nil || vp.origin.tagged {
+		return nil
+	}
+	update := binlogplayer.GenerateUpdatePos(vp.vr.id, vp.pos, time.Now().Unix(), 0, vp.vr.stats.CopyRowCount.Get(), vp.vr.workflowConfig.StoreCompressedGTID)
+	if _, err := vp.query(ctx, update); err != nil {
+		return fmt.Errorf("error %v tagging transaction with the source position", err)
+	}
+	vp.origin.tagged = true
+	return nil
+}
+
 // updatePos should get called at a minimum of vreplicationMinimumHeartbeatUpdateInterval.
 func (vp *vplayer) updatePos(ctx context.Context, ts int64) (posReached bool, err error) {
 	update := binlogplayer.GenerateUpdatePos(vp.vr.id, vp.pos, time.Now().Unix(), ts, vp.vr.stats.CopyRowCount.Get(), vp.vr.workflowConfig.StoreCompressedGTID)
@@ -480,6 +556,18 @@ func (vp *vplayer) recordHeartbeat() error {
 func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	defer vp.vr.dbClient.Rollback()
 
+	if vp.parallelWorkers > 1 {
+		pa, err := newParallelApplier(ctx, vp, vp.parallelWorkers)
+		if err != nil {
+			return err
+		}
+		vp.parallel = pa
+		defer func() {
+			pa.close()
+			vp.parallel = nil
+		}()
+	}
+
 	estimateLag := func() {
 		behind := time.Now().UnixNano() - vp.lastTimestampNs - vp.timeOffsetNs
 		vp.vr.stats.ReplicationLagSeconds.Store(behind / 1e9)
@@ -496,6 +584,25 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if vp.parallel != nil {
+			if err := vp.parallel.error(); err != nil {
+				vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+				log.Errorf("Error applying transactions in parallel: %s", err.Error())
+				return err
+			}
+		}
+		if vp.onlineDDL != nil && !vp.vr.dbClient.InTransaction {
+			failure, err := vp.onlineDDL.check()
+			if err != nil {
+				return err
+			}
+			if failure != "" {
+				if err := vp.vr.setState(binlogdatapb.VReplicationWorkflowState_Error, failure); err != nil {
+					return err
+				}
+				return io.EOF
+			}
+		}
 		// Check throttler.
 		if checkResult, ok := vp.vr.vre.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, throttlerapp.Name(vp.throttlerAppName)); !ok {
 			_ = vp.vr.updateTimeThrottled(throttlerapp.VPlayerName, checkResult.Summary())
@@ -515,6 +622,13 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 		// In both cases, now > timeLastSaved. If so, the GTID of the last unsavedEvent
 		// must be saved.
 		if time.Since(vp.timeLastSaved) >= idleTimeout && vp.unsavedEvent != nil {
+			if vp.parallel != nil {
+				// The position must not be saved before the previous
+				// transactions are committed.
+				if err := vp.parallel.drain(); err != nil {
+					return err
+				}
+			}
 			posReached, err := vp.updatePos(ctx, vp.unsavedEvent.Timestamp)
 			if err != nil {
 				return err
@@ -540,7 +654,7 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 						lagSecs = event.CurrentTime/1e9 - event.Timestamp
 					}
 				}
-				mustSave := false
+				mustSave, groupNext := false, false
 				switch event.Type {
 				case binlogdatapb.VEventType_COMMIT:
 					// If we've reached the stop position, we must save the current commit
@@ -555,11 +669,18 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 					// applying the next set of events as part of the current transaction. This approach
 					// also handles the case where the last transaction is partial. In that case,
 					// we only group the transactions with commits we've seen so far.
-					if hasAnotherCommit(items, i, j+1) {
+					groupNext = hasAnotherCommit(items, i, j+1)
+					if groupNext && vp.parallel == nil {
 						continue
 					}
 				}
-				if err := vp.applyEvent(ctx, event, mustSave); err != nil {
+				var err error
+				if vp.parallel != nil {
+					err = vp.parallel.applyEvent(ctx, event, groupNext)
+				} else {
+					err = vp.applyEvent(ctx, event, mustSave)
+				}
+				if err != nil {
 					if err != io.EOF {
 						vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
 						var table, tableLogMsg, gtidLogMsg string
@@ -653,12 +774,33 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 		vp.pos = pos
 		// A new position should not be saved until a saveable event occurs.
 		vp.unsavedEvent = nil
+		if vp.onlineDDL != nil {
+			if err := vp.onlineDDL.endReplay(ctx, vp.query, pos); err != nil {
+				return err
+			}
+		}
 		if vp.stopPos.IsZero() {
 			return nil
 		}
 	case binlogdatapb.VEventType_BEGIN:
-		// No-op: begin is called as needed.
+		// Begin is called as needed.
+		if vp.origin != nil {
+			vp.origin.reset()
+		}
 	case binlogdatapb.VEventType_COMMIT:
+		if vp.origin != nil {
+			vp.origin.reset()
+		}
+		if vp.onlineDDL != nil && vp.onlineDDL.replaying(vp.pos) {
+			// The position of the stream is already past this transaction.
+			if !vp.vr.dbClient.InTransaction {
+				return nil
+			}
+			if err := vp.onlineDDL.saveReplayPos(ctx, vp.query, vp.pos); err != nil {
+				return err
+			}
+			return vp.commit()
+		}
 		if mustSave {
 			if err := vp.vr.dbClient.Begin(); err != nil {
 				return err
@@ -681,6 +823,16 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			return io.EOF
 		}
 	case binlogdatapb.VEventType_FIELD:
+		if vp.origin != nil && event.FieldEvent.IsInternalTable {
+			if event.FieldEvent.TableName == originTable {
+				vp.origin.setFields(event.FieldEvent.Fields)
+			}
+			return nil
+		}
+		if vp.onlineDDL != nil && vp.onlineDDL.paused(event.FieldEvent.TableName) {
+			// The table on the target still has the old schema.
+			return nil
+		}
 		if err := vp.vr.dbClient.Begin(); err != nil {
 			return err
 		}
@@ -702,8 +854,14 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 		}
 		// If the event is for one of the AWS RDS "special" or pt-table-checksum tables, we skip
 		if !strings.Contains(sql, " mysql.rds_") && !strings.Contains(sql, " percona.checksums") {
+			if vp.origin != nil && vp.origin.skip {
+				return nil
+			}
+			if vp.onlineDDL != nil && vp.onlineDDL.replaying(vp.pos) {
+				return nil
+			}
 			// This is a player using statement based replication
-			if err := vp.vr.dbClient.Begin(); err != nil {
+			if err := vp.begin(ctx); err != nil {
 				return err
 			}
 			if err := vp.applyStmtEvent(ctx, event); err != nil {
@@ -714,8 +872,29 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			}
 		}
 	case binlogdatapb.VEventType_ROW:
+		if vp.origin != nil {
+			if event.RowEvent.IsInternalTable {
+				if event.RowEvent.TableName == originTable {
+					return vp.origin.checkRowEvent(event.RowEvent)
+				}
+				return nil
+			}
+			if vp.origin.skip {
+				return nil
+			}
+		}
+		if vp.onlineDDL != nil && !vp.onlineDDL.applies(event.RowEvent.TableName, vp.pos) {
+			return nil
+		}
+		if len(vp.copyState) != 0 {
+			// The copy schedule is only enforced by the vcopier, before catching up
+			// and while copying.
+			if err := vp.vr.copyLimiter.limitRowEvent(ctx, event.RowEvent); err != nil && !errors.Is(err, errCopyPaused) {
+				return err
+			}
+		}
 		// This player is configured for row based replication
-		if err := vp.vr.dbClient.Begin(); err != nil {
+		if err := vp.begin(ctx); err != nil {
 			return err
 		}
 		if err := vp.applyRowEvent(ctx, event.RowEvent); err != nil {
@@ -733,6 +912,9 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			log.Errorf("internal error: vplayer is in a transaction on event: %v", event)
 			return fmt.Errorf("internal error: vplayer is in a transaction on event: %v", event)
 		}
+		if vp.onlineDDL != nil && vp.onlineDDL.replaying(vp.pos) {
+			return nil
+		}
 		// Just update the position.
 		posReached, err := vp.updatePos(ctx, event.Timestamp)
 		if err != nil {
@@ -747,6 +929,10 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			log.Errorf("internal error: vplayer is in a transaction on event: %v", event)
 			return fmt.Errorf("internal error: vplayer is in a transaction on event: %v", event)
 		}
+		if vp.onlineDDL != nil && vp.onlineDDL.replaying(vp.pos) {
+			// The DDL was handled before the stream was restarted.
+			return nil
+		}
 		vp.vr.stats.DDLEventActions.Add(vp.vr.source.OnDdl.String(), 1) // Record the DDL handling
 		switch vp.vr.source.OnDdl {
 		case binlogdatapb.OnDDLAction_IGNORE:
@@ -804,6 +990,25 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			if posReached {
 				return io.EOF
 			}
+		case binlogdatapb.OnDDLAction_ONLINE:
+			if err := vp.applyOnlineDDL(ctx, event); err != nil {
+				return err
+			}
+			if stats != nil {
+				stats.Send(fmt.Sprintf("%v", event.Statement))
+			}
+			posReached, err := vp.updatePos(ctx, event.Timestamp)
+			if err != nil {
+				return err
+			}
+			if vp.vr.dbClient.InTransaction {
+				if err := vp.commit(); err != nil {
+					return err
+				}
+			}
+			if posReached {
+				return io.EOF
+			}
 		}
 	case binlogdatapb.VEventType_JOURNAL:
 		if vp.vr.dbClient.InTransaction {
@@ -3830,6 +3830,126 @@ func expectJSON(t *testing.T, table string, values [][]string, id int, exec func
 	}
 }
 
+// TestPlayerParallelApply tests that transactions applied over several
+// connections end up with the same data and position as applying them in
+// order.
+func TestPlayerParallelApply(t *testing.T) {
+	oldParallelApplyWorkers := vttablet.DefaultVReplicationConfig.ParallelApplyWorkers
+	vttablet.DefaultVReplicationConfig.ParallelApplyWorkers = 4
+	defer func() {
+		vttablet.DefaultVReplicationConfig.ParallelApplyWorkers = oldParallelApplyWorkers
+	}()
+	doNotLogDBQueries = true
+	defer func() { doNotLogDBQueries = false }()
+
+	defer deleteTablet(addTablet(100))
+	execStatements(t, []string{
+		"create table t1(id int, val varchar(128), primary key(id))",
+		fmt.Sprintf("create table %s.t1(id int, val varchar(128), primary key(id))", vrepldb),
+		"create table t2(id int, uk int, primary key(id), unique key(uk))",
+		fmt.Sprintf("create table %s.t2(id int, uk int, primary key(id), unique key(uk))", vrepldb),
+		"create table t3(id int, primary key(id))",
+		fmt.Sprintf("create table %s.t3(id int, primary key(id))", vrepldb),
+		"create table t4(id int, t3_id int, primary key(id), foreign key (t3_id) references t3(id))",
+		fmt.Sprintf("create table %s.t4(id int, t3_id int, primary key(id), foreign key (t3_id) references t3(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		fmt.Sprintf("drop table %s.t1", vrepldb),
+		"drop table t2",
+		fmt.Sprintf("drop table %s.t2", vrepldb),
+		"drop table t4",
+		fmt.Sprintf("drop table %s.t4", vrepldb),
+		"drop table t3",
+		fmt.Sprintf("drop table %s.t3", vrepldb),
+	})
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/.*",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_EXEC,
+	}
+	cancel, id := startVReplication(t, bls, "")
+	defer cancel()
+
+	var queries []string
+	var want [][]string
+	for i := 1; i <= 20; i++ {
+		queries = append(queries, fmt.Sprintf("insert into t1 values(%d, 'aaa')", i))
+	}
+	// Conflicting transactions must be applied in order.
+	for i := 1; i <= 20; i++ {
+		queries = append(queries, fmt.Sprintf("update t1 set val='bbb%d' where id=%d", i, i%5+1))
+	}
+	for i := 1; i <= 20; i++ {
+		val := "aaa"
+		if i <= 5 {
+			val = fmt.Sprintf("bbb%d", 15+(i+3)%5+1)
+		}
+		want = append(want, []string{strconv.Itoa(i), val})
+	}
+	// These transactions only conflict through the unique key: applied out
+	// of order, an insert fails with a duplicate key error.
+	queries = append(queries,
+		"insert into t2 values(1, 1)",
+		"delete from t2 where id=1",
+		"insert into t2 values(2, 1)",
+		"update t2 set uk=2 where id=2",
+		"insert into t2 values(3, 1)",
+	)
+	// These transactions only conflict through the foreign key: applied out
+	// of order, a child row is inserted before its parent row, or a parent
+	// row is deleted before its child row.
+	queries = append(queries,
+		"insert into t3 values(1)",
+		"insert into t4 values(1, 1)",
+		"delete from t4 where id=1",
+		"delete from t3 where id=1",
+		"insert into t3 values(2)",
+		"insert into t4 values(2, 2)",
+	)
+	execStatements(t, queries)
+	expectData(t, "t1", want)
+	expectData(t, "t2", [][]string{
+		{"2", "2"},
+		{"3", "1"},
+	})
+	expectData(t, "t3", [][]string{
+		{"2"},
+	})
+	expectData(t, "t4", [][]string{
+		{"2", "2"},
+	})
+
+	// A DDL is applied once the previous transactions are committed.
+	execStatements(t, []string{
+		"alter table t1 add column val2 varchar(128)",
+		"insert into t1 values(21, 'aaa', 'ccc')",
+	})
+	wantPos, err := binlogplayer.DecodePosition(primaryPosition(t))
+	require.NoError(t, err)
+	want = append(want, []string{"21", "aaa", "ccc"})
+	for i := range 20 {
+		want[i] = append(want[i], "")
+	}
+	customExpectData(t, "t1", want, env.Mysqld.FetchSuperQuery)
+
+	require.Eventually(t, func() bool {
+		qr, err := env.Mysqld.FetchSuperQuery(context.Background(), fmt.Sprintf("select pos from _vt.vreplication where id = %d", id))
+		require.NoError(t, err)
+		require.Len(t, qr.Rows, 1)
+		pos, err := binlogplayer.DecodePosition(qr.Rows[0][0].ToString())
+		require.NoError(t, err)
+		return pos.AtLeast(wantPos)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
 func startVReplication(t *testing.T, bls *binlogdatapb.BinlogSource, pos string) (cancelFunc func(), id int) {
 	t.Helper()
 
@@ -0,0 +1,332 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+const (
+	// onlineDDLCheckInterval is how often the vplayer checks the Online DDL
+	// migrations of the tables whose changes it holds back.
+	onlineDDLCheckInterval = 5 * time.Second
+	// onlineDDLSubmitTimeout is the timeout for submitting a migration.
+	onlineDDLSubmitTimeout = 30 * time.Second
+
+	sqlSelectOnlineDDLPauses = "select table_name, migration_uuid, pos from _vt.vreplication_online_ddl where vrepl_id=%a"
+	sqlInsertOnlineDDLPause  = "insert into _vt.vreplication_online_ddl(vrepl_id, table_name, migration_uuid, pos) values (%a, %a, %a, %a)"
+	sqlUpdateOnlineDDLPause  = "update _vt.vreplication_online_ddl set pos=%a where vrepl_id=%a and table_name=%a"
+	sqlDeleteOnlineDDLPause  = "delete from _vt.vreplication_online_ddl where vrepl_id=%a and table_name=%a"
+	sqlSelectMigrationStatus = "select migration_status from _vt.schema_migrations where migration_uuid=%a"
+)
+
+// errOnlineDDLComplete is returned by the vplayer when a migration it waited
+// for is complete, so that it gets restarted with the new schema of the table.
+var errOnlineDDLComplete = errors.New("online DDL migration complete")
+
+// onlineDDLPause is a table whose changes are held back while an Online DDL
+// migration alters it.
+type onlineDDLPause struct {
+	uuid string
+	// pos is the position up to which the changes of the table are applied.
+	pos replication.Position
+	// complete is set once the migration is complete, and the changes of the
+	// table after pos are replayed.
+	complete bool
+}
+
+// onlineDDLPauses implements the ONLINE DDL action of the running phase.
+//
+// An ALTER TABLE of a table of the workflow is submitted to the Online DDL
+// scheduler of the target with the vitess strategy, and the changes of the
+// table are skipped while the migration runs. The other tables keep
+// replicating. The table is recorded in _vt.vreplication_online_ddl along with
+// the position of the DDL, in the same transaction as the position of the
+// stream.
+//
+// Once the migration is complete, the vplayer is restarted from the position
+// of the DDL. Up to the position of the stream, it only applies the changes of
+// the altered table, and records how far it got in the same transactions.
+// Then the table is removed from _vt.vreplication_online_ddl, and the stream
+// continues as usual.
+type onlineDDLPauses struct {
+	dbClient *vdbClient
+	vrID     int32
+
+	tables map[string]*onlineDDLPause
+	// appliedPos is set while the changes of completed tables are replayed.
+	// It is the position up to which the changes of the other tables are
+	// applied.
+	appliedPos replication.Position
+	lastCheck  time.Time
+}
+
+// newOnlineDDLPauses loads the tables whose changes are held back by the
+// stream, along with the state of their migrations. If a migration is not
+// going to complete, the returned message tells why.
+func newOnlineDDLPauses(dbClient *vdbClient, vrID int32) (*onlineDDLPauses, string, error) {
+	p := &onlineDDLPauses{
+		dbClient:  dbClient,
+		vrID:      vrID,
+		tables:    make(map[string]*onlineDDLPause),
+		lastCheck: time.Now(),
+	}
+	query, err := sqlparser.ParseAndBind(sqlSelectOnlineDDLPauses, sqltypes.Int32BindVariable(vrID))
+	if err != nil {
+		return nil, "", err
+	}
+	qr, err := dbClient.ExecuteFetch(query, maxRows)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, row := range qr.Rows {
+		pos, err := binlogplayer.DecodePosition(row[2].ToString())
+		if err != nil {
+			return nil, "", err
+		}
+		p.tables[row[0].ToString()] = &onlineDDLPause{uuid: row[1].ToString(), pos: pos}
+	}
+	_, failure, err := p.checkMigrations()
+	if err != nil {
+		return nil, "", err
+	}
+	return p, failure, nil
+}
+
+// checkMigrations marks the tables whose migrations are complete. If a
+// migration failed or was cancelled, the returned message tells which.
+func (p *onlineDDLPauses) checkMigrations() (completed bool, failure string, err error) {
+	for table, pause := range p.tables {
+		if pause.complete {
+			continue
+		}
+		query, err := sqlparser.ParseAndBind(sqlSelectMigrationStatus, sqltypes.StringBindVariable(pause.uuid))
+		if err != nil {
+			return false, "", err
+		}
+		qr, err := p.dbClient.ExecuteFetch(query, 1)
+		if err != nil {
+			return false, "", err
+		}
+		if len(qr.Rows) == 0 {
+			return false, fmt.Sprintf("Online DDL migration %s of table %s not found", pause.uuid, table), nil
+		}
+		switch status := schema.OnlineDDLStatus(qr.Rows[0][0].ToString()); status {
+		case schema.OnlineDDLStatusComplete:
+			log.Infof("Online DDL migration %s of table %s is complete, replaying the changes of the table", pause.uuid, table)
+			pause.complete = true
+			completed = true
+		case schema.OnlineDDLStatusFailed, schema.OnlineDDLStatusCancelled:
+			return false, fmt.Sprintf("Online DDL migration %s of table %s is %s", pause.uuid, table, status), nil
+		}
+	}
+	return completed, "", nil
+}
+
+// check periodically checks the migrations of the held back tables. It returns
+// errOnlineDDLComplete once one of them is complete.
+func (p *onlineDDLPauses) check() (failure string, err error) {
+	if time.Since(p.lastCheck) < onlineDDLCheckInterval {
+		return "", nil
+	}
+	p.lastCheck = time.Now()
+	completed, failure, err := p.checkMigrations()
+	if err != nil || failure != "" {
+		return failure, err
+	}
+	if completed {
+		return "", errOnlineDDLComplete
+	}
+	return "", nil
+}
+
+// startReplay returns the position from which the changes of the completed
+// tables have to be replayed, if any, given the position of the stream.
+func (p *onlineDDLPauses) startReplay(pos replication.Position) replication.Position {
+	var start replication.Position
+	for _, pause := range p.tables {
+		if !pause.complete {
+			continue
+		}
+		if start.IsZero() || start.AtLeast(pause.pos) {
+			start = pause.pos
+		}
+	}
+	if !start.IsZero() {
+		p.appliedPos = pos
+	}
+	return start
+}
+
+// replaying reports whether the changes at the position are only applied for
+// the completed tables.
+func (p *onlineDDLPauses) replaying(pos replication.Position) bool {
+	return !p.appliedPos.IsZero() && p.appliedPos.AtLeast(pos)
+}
+
+// paused reports whether the changes of the table are held back.
+func (p *onlineDDLPauses) paused(table string) bool {
+	pause := p.tables[table]
+	return pause != nil && !pause.complete
+}
+
+// applies reports whether the changes of the table at the position have to
+// be applied.
+func (p *onlineDDLPauses) applies(table string, pos replication.Position) bool {
+	pause := p.tables[table]
+	if p.replaying(pos) {
+		return pause != nil && pause.complete && !pause.pos.AtLeast(pos)
+	}
+	return pause == nil || pause.complete
+}
+
+// saveReplayPos records the position up to which the changes of the completed
+// tables are replayed.
+func (p *onlineDDLPauses) saveReplayPos(ctx context.Context, query func(ctx context.Context, sql string) (*sqltypes.Result, error), pos replication.Position) error {
+	for table, pause := range p.tables {
+		if !pause.complete || pause.pos.AtLeast(pos) {
+			continue
+		}
+		update, err := sqlparser.ParseAndBind(sqlUpdateOnlineDDLPause,
+			sqltypes.StringBindVariable(replication.EncodePosition(pos)),
+			sqltypes.Int32BindVariable(p.vrID),
+			sqltypes.StringBindVariable(table),
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := query(ctx, update); err != nil {
+			return err
+		}
+		pause.pos = pos
+	}
+	return nil
+}
+
+// endReplay releases the completed tables once the stream gets past the
+// position up to which the changes of the other tables were applied.
+func (p *onlineDDLPauses) endReplay(ctx context.Context, query func(ctx context.Context, sql string) (*sqltypes.Result, error), pos replication.Position) error {
+	if p.appliedPos.IsZero() || p.appliedPos.AtLeast(pos) {
+		return nil
+	}
+	for table, pause := range p.tables {
+		if !pause.complete {
+			continue
+		}
+		del, err := sqlparser.ParseAndBind(sqlDeleteOnlineDDLPause, sqltypes.Int32BindVariable(p.vrID), sqltypes.StringBindVariable(table))
+		if err != nil {
+			return err
+		}
+		if _, err := query(ctx, del); err != nil {
+			return err
+		}
+		delete(p.tables, table)
+	}
+	p.appliedPos = replication.Position{}
+	return nil
+}
+
+// pause holds back the changes of the table after the position.
+func (p *onlineDDLPauses) pause(ctx context.Context, query func(ctx context.Context, sql string) (*sqltypes.Result, error), table, uuid string, pos replication.Position) error {
+	insert, err := sqlparser.ParseAndBind(sqlInsertOnlineDDLPause,
+		sqltypes.Int32BindVariable(p.vrID),
+		sqltypes.StringBindVariable(table),
+		sqltypes.StringBindVariable(uuid),
+		sqltypes.StringBindVariable(replication.EncodePosition(pos)),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := query(ctx, insert); err != nil {
+		return err
+	}
+	p.tables[table] = &onlineDDLPause{uuid: uuid, pos: pos}
+	return nil
+}
+
+// onlineDDLUUID returns the UUID of the migration for the DDL at the position.
+// A stream that is restarted before the table is held back submits the same
+// migration again, while a later identical DDL gets a migration of its own.
+func onlineDDLUUID(workflow, statement string, pos replication.Position) string {
+	sum := sha256.Sum256([]byte(workflow + "\n" + replication.EncodePosition(pos) + "\n" + statement))
+	h := hex.EncodeToString(sum[:16])
+	return fmt.Sprintf("%s_%s_%s_%s_%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+// applyOnlineDDL handles a DDL with the ONLINE action. In the copy phase, and
+// for DDLs other than an ALTER TABLE of a table of the workflow, the DDL is
+// applied directly, as with EXEC. Otherwise, the DDL is submitted to Online
+// DDL, and the changes of the table are held back until the migration is
+// complete.
+func (vp *vplayer) applyOnlineDDL(ctx context.Context, event *binlogdatapb.VEvent) error {
+	var alter *sqlparser.AlterTable
+	if vp.onlineDDL != nil {
+		stmt, err := vp.vr.vre.env.Parser().Parse(event.Statement)
+		if err != nil {
+			return err
+		}
+		alter, _ = stmt.(*sqlparser.AlterTable)
+	}
+	if alter == nil || vp.replicatorPlan.TablePlans[alter.Table.Name.String()] == nil {
+		_, err := vp.query(ctx, event.Statement)
+		return err
+	}
+	table := alter.Table.Name.String()
+	if vp.onlineDDL.paused(table) {
+		// The changes of the table up to the DDL are not applied yet. The
+		// stream stops before the DDL, and can be started again once the
+		// migration is complete.
+		if err := vp.vr.setState(binlogdatapb.VReplicationWorkflowState_Error,
+			fmt.Sprintf("DDL %s on table %s while its Online DDL migration %s is not complete", event.Statement, table, vp.onlineDDL.tables[table].uuid)); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	// The target database is the one of the tablet.
+	alter.Table.Qualifier = sqlparser.NewIdentifierCS("")
+	uuid := onlineDDLUUID(vp.vr.WorkflowName, event.Statement, vp.pos)
+	// Online DDL considers a migration with the context and the statement of a
+	// complete one as complete, so each DDL gets a context of its own.
+	onlineDDL, err := schema.NewOnlineDDL("", table, sqlparser.String(alter), schema.NewDDLStrategySetting(schema.DDLStrategyVitess, ""),
+		fmt.Sprintf("vreplication:%s:%s", vp.vr.WorkflowName, uuid), uuid, vp.vr.vre.env.Parser())
+	if err != nil {
+		return err
+	}
+	if err := vp.vr.vre.submitOnlineDDL(onlineDDL.SQL); err != nil {
+		return fmt.Errorf("failed to submit Online DDL migration %s of table %s: %w", uuid, table, err)
+	}
+	log.Infof("Submitted Online DDL migration %s of table %s for workflow %s", uuid, table, vp.vr.WorkflowName)
+	if err := vp.vr.dbClient.Begin(); err != nil {
+		return err
+	}
+	return vp.onlineDDL.pause(ctx, vp.query, table, uuid, vp.pos)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestOnlineDDLUUID(t *testing.T) {
+	pos, err := binlogplayer.DecodePosition("MySQL56/00000000-0000-0000-0000-000000000001:1-10")
+	require.NoError(t, err)
+	uuid := onlineDDLUUID("wf", "alter table t1 add column c2 int", pos)
+	require.True(t, schema.IsOnlineDDLUUID(uuid), uuid)
+	require.Equal(t, uuid, onlineDDLUUID("wf", "alter table t1 add column c2 int", pos))
+	require.NotEqual(t, uuid, onlineDDLUUID("wf2", "alter table t1 add column c2 int", pos))
+	require.NotEqual(t, uuid, onlineDDLUUID("wf", "alter table t1 add column c3 int", pos))
+
+	// The same DDL run again, e.g. after the first one was reverted, is a
+	// migration of its own.
+	repeated, err := binlogplayer.DecodePosition("MySQL56/00000000-0000-0000-0000-000000000001:1-20")
+	require.NoError(t, err)
+	require.NotEqual(t, uuid, onlineDDLUUID("wf", "alter table t1 add column c2 int", repeated))
+}
+
+func TestOnlineDDLPauses(t *testing.T) {
+	const sid = "00000000-0000-0000-0000-000000000001"
+	position := func(t *testing.T, gtids string) replication.Position {
+		pos, err := binlogplayer.DecodePosition("MySQL56/" + sid + ":" + gtids)
+		require.NoError(t, err)
+		return pos
+	}
+
+	dbc := binlogplayer.NewMockDBClient(t)
+	dbc.ExpectRequest("select table_name, migration_uuid, pos from _vt.vreplication_online_ddl where vrepl_id=1",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|migration_uuid|pos", "varbinary|varchar|varbinary"),
+			"t1|uuid1|MySQL56/"+sid+":1-10",
+			"t2|uuid2|MySQL56/"+sid+":1-20",
+		), nil)
+	dbc.ExpectRequestRE("select migration_status from _vt.schema_migrations where migration_uuid='uuid[12]'",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("migration_status", "varchar"), "complete"), nil)
+	dbc.ExpectRequestRE("select migration_status from _vt.schema_migrations where migration_uuid='uuid[12]'",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("migration_status", "varchar"), "running"), nil)
+	p, failure, err := newOnlineDDLPauses(newVDBClient(dbc, binlogplayer.NewStats(), 10), 1)
+	require.NoError(t, err)
+	require.Empty(t, failure)
+	dbc.Wait()
+
+	// Whichever migration is complete, the changes of its table are replayed
+	// from the position of its DDL up to the position of the stream.
+	complete, running := "t1", "t2"
+	if p.tables["t2"].complete {
+		complete, running = "t2", "t1"
+	}
+	require.True(t, p.paused(running))
+	require.False(t, p.paused(complete))
+	require.False(t, p.paused("t3"))
+	start := p.startReplay(position(t, "1-30"))
+	require.Equal(t, p.tables[complete].pos, start)
+
+	replayed := position(t, "1-25")
+	require.True(t, p.replaying(replayed))
+	require.True(t, p.applies(complete, replayed))
+	require.False(t, p.applies(running, replayed))
+	require.False(t, p.applies("t3", replayed))
+
+	var queries []string
+	query := func(ctx context.Context, sql string) (*sqltypes.Result, error) {
+		queries = append(queries, sql)
+		return &sqltypes.Result{}, nil
+	}
+	ctx := context.Background()
+	require.NoError(t, p.saveReplayPos(ctx, query, replayed))
+	require.Equal(t, []string{"update _vt.vreplication_online_ddl set pos='MySQL56/" + sid + ":1-25' where vrepl_id=1 and table_name='" + complete + "'"}, queries)
+	require.False(t, p.applies(complete, replayed))
+
+	queries = nil
+	require.NoError(t, p.endReplay(ctx, query, position(t, "1-30")))
+	require.Empty(t, queries)
+	next := position(t, "1-31")
+	require.NoError(t, p.endReplay(ctx, query, next))
+	require.Equal(t, []string{"delete from _vt.vreplication_online_ddl where vrepl_id=1 and table_name='" + complete + "'"}, queries)
+	require.False(t, p.replaying(next))
+	require.True(t, p.applies(complete, next))
+	require.False(t, p.applies(running, next))
+	require.True(t, p.applies("t3", next))
+
+	queries = nil
+	require.NoError(t, p.pause(ctx, query, "t3", "uuid3", next))
+	require.Equal(t, []string{"insert into _vt.vreplication_online_ddl(vrepl_id, table_name, migration_uuid, pos) values (1, 't3', 'uuid3', 'MySQL56/" + sid + ":1-31')"}, queries)
+	require.True(t, p.paused("t3"))
+	require.False(t, p.applies("t3", position(t, "1-32")))
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const (
+	// originTable is the sidecar table whose row events tell which workflow
+	// a replicated transaction was applied by.
+	originTable = "vreplication"
+
+	sqlSelectLocalGTIDs = "select @@global.gtid_executed"
+)
+
+// originFilter keeps a Replicate workflow from applying transactions which
+// another Replicate workflow copied from this cluster to the source, so that
+// two clusters can replicate the same tables to each other without echoing
+// writes back and forth.
+//
+// Every transaction a Replicate workflow applies starts with an update of its
+// _vt.vreplication row, which records the source position and thereby tags
+// the transaction with the position of the source right after it. The vplayer
+// streams the _vt.vreplication row events of the source along with the data,
+// and skips the rest of a transaction whose tag is contained in the GTIDs
+// executed by the local shard, as the transaction originated here.
+//
+// Matching the whole position rather than its server UUIDs matters when the
+// local shard shares server UUIDs with other clusters, e.g. when it was
+// seeded from a backup of the source or is part of a chain of clusters: the
+// tag of a transaction which originated elsewhere then still contains GTIDs
+// which the local shard never executed.
+type originFilter struct {
+	// localGTIDs holds the GTIDs executed by the local shard, as of the last
+	// call to loadLocalGTIDs.
+	localGTIDs     replication.Mysql56GTIDSet
+	loadLocalGTIDs func() (replication.Mysql56GTIDSet, error)
+
+	// fields holds the fields of the source _vt.vreplication row events.
+	fields          []*querypb.Field
+	workflowTypeIdx int
+	posIdx          int
+
+	// skip is set when the current transaction originated in the local shard.
+	skip bool
+	// tagged is set once the current transaction has been tagged.
+	tagged bool
+}
+
+func newOriginFilter(dbClient *vdbClient) (*originFilter, error) {
+	of := &originFilter{
+		loadLocalGTIDs: func() (replication.Mysql56GTIDSet, error) {
+			qr, err := dbClient.ExecuteFetch(sqlSelectLocalGTIDs, 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(qr.Rows) != 1 {
+				return nil, fmt.Errorf("unexpected result for %s: %v", sqlSelectLocalGTIDs, qr.Rows)
+			}
+			return replication.ParseMysql56GTIDSet(qr.Rows[0][0].ToString())
+		},
+		workflowTypeIdx: -1,
+		posIdx:          -1,
+	}
+	var err error
+	if of.localGTIDs, err = of.loadLocalGTIDs(); err != nil {
+		return nil, err
+	}
+	return of, nil
+}
+
+// reset is called at the boundaries of the source transactions.
+func (of *originFilter) reset() {
+	of.skip = false
+	of.tagged = false
+}
+
+// setFields records the layout of the source _vt.vreplication row events.
+func (of *originFilter) setFields(fields []*querypb.Field) {
+	of.fields = fields
+	of.workflowTypeIdx, of.posIdx = -1, -1
+	for i, field := range fields {
+		switch field.Name {
+		case "workflow_type":
+			of.workflowTypeIdx = i
+		case "pos":
+			of.posIdx = i
+		}
+	}
+}
+
+// checkRowEvent marks the current transaction to be skipped if the row event
+// tags it as applied by a Replicate workflow streaming from the local shard.
+func (of *originFilter) checkRowEvent(rowEvent *binlogdatapb.RowEvent) error {
+	if of.workflowTypeIdx < 0 || of.posIdx < 0 {
+		return nil
+	}
+	for _, change := range rowEvent.RowChanges {
+		if change.After == nil {
+			continue
+		}
+		row := sqltypes.MakeRowTrusted(of.fields, change.After)
+		if len(row) <= of.workflowTypeIdx || len(row) <= of.posIdx {
+			continue
+		}
+		workflowType, err := strconv.ParseInt(row[of.workflowTypeIdx].ToString(), 10, 32)
+		if err != nil {
+			return err
+		}
+		if binlogdatapb.VReplicationWorkflowType(workflowType) != binlogdatapb.VReplicationWorkflowType_Replicate {
+			continue
+		}
+		pos, err := binlogplayer.DecodePosition(row[of.posIdx].ToString())
+		if err != nil {
+			return err
+		}
+		local, err := of.isLocal(pos)
+		if err != nil {
+			return err
+		}
+		if local {
+			of.skip = true
+			return nil
+		}
+	}
+	return nil
+}
+
+// isLocal returns true if pos is contained in the GTIDs executed by the local
+// shard. The local GTIDs are reloaded before concluding otherwise, as the
+// transaction may have been executed after they were last loaded.
+func (of *originFilter) isLocal(pos replication.Position) (bool, error) {
+	gtidSet, ok := pos.GTIDSet.(replication.Mysql56GTIDSet)
+	if !ok || len(gtidSet) == 0 {
+		return false, nil
+	}
+	if of.localGTIDs.Contains(gtidSet) {
+		return true, nil
+	}
+	localGTIDs, err := of.loadLocalGTIDs()
+	if err != nil {
+		return false, err
+	}
+	of.localGTIDs = localGTIDs
+	return of.localGTIDs.Contains(gtidSet), nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestOriginFilter(t *testing.T) {
+	const (
+		localUUID  = "00000000-0000-0000-0000-000000000001"
+		remoteUUID = "00000000-0000-0000-0000-000000000002"
+	)
+	// The local shard was seeded from a backup of the remote one, so both
+	// server UUIDs are part of its executed GTIDs.
+	localGTIDs := "MySQL56/" + remoteUUID + ":1-5," + localUUID + ":1-10"
+
+	fields := sqltypes.MakeTestFields("id|pos|workflow_type", "int32|varbinary|int32")
+	rowEvent := func(pos string, workflowType binlogdatapb.VReplicationWorkflowType) *binlogdatapb.RowEvent {
+		row := sqltypes.MakeTestResult(fields, fmt.Sprintf("1|%s|%d", pos, workflowType)).Rows[0]
+		return &binlogdatapb.RowEvent{
+			TableName:       originTable,
+			IsInternalTable: true,
+			RowChanges:      []*binlogdatapb.RowChange{{After: sqltypes.RowToProto3(row)}},
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		event    *binlogdatapb.RowEvent
+		reloaded string
+		wantSkip bool
+	}{
+		{
+			name:     "replicated from the local shard",
+			event:    rowEvent("MySQL56/"+remoteUUID+":1-5,"+localUUID+":1-10", binlogdatapb.VReplicationWorkflowType_Replicate),
+			wantSkip: true,
+		},
+		{
+			name:     "replicated from the local shard after the local GTIDs were loaded",
+			event:    rowEvent("MySQL56/"+remoteUUID+":1-5,"+localUUID+":1-12", binlogdatapb.VReplicationWorkflowType_Replicate),
+			reloaded: "MySQL56/" + remoteUUID + ":1-5," + localUUID + ":1-12",
+			wantSkip: true,
+		},
+		{
+			name:     "replicated from another shard sharing server UUIDs with the local one",
+			event:    rowEvent("MySQL56/"+remoteUUID+":1-8", binlogdatapb.VReplicationWorkflowType_Replicate),
+			reloaded: localGTIDs,
+		},
+		{
+			name:     "replicated from another shard",
+			event:    rowEvent("MySQL56/00000000-0000-0000-0000-000000000003:1-10", binlogdatapb.VReplicationWorkflowType_Replicate),
+			reloaded: localGTIDs,
+		},
+		{
+			name:  "applied by another workflow type",
+			event: rowEvent("MySQL56/"+localUUID+":1-10", binlogdatapb.VReplicationWorkflowType_MoveTables),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			initial, err := binlogplayer.DecodePosition(localGTIDs)
+			require.NoError(t, err)
+			var reloaded string
+			of := &originFilter{
+				localGTIDs: initial.GTIDSet.(replication.Mysql56GTIDSet),
+				loadLocalGTIDs: func() (replication.Mysql56GTIDSet, error) {
+					reloaded = tc.reloaded
+					pos, err := binlogplayer.DecodePosition(tc.reloaded)
+					if err != nil {
+						return nil, err
+					}
+					return pos.GTIDSet.(replication.Mysql56GTIDSet), nil
+				},
+			}
+			of.setFields(fields)
+			require.NoError(t, of.checkRowEvent(tc.event))
+			require.Equal(t, tc.wantSkip, of.skip)
+			require.Equal(t, tc.reloaded, reloaded)
+			of.reset()
+			require.False(t, of.skip)
+		})
+	}
+}
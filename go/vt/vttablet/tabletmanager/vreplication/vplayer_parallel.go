@@ -0,0 +1,713 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/collations/colldata"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var errParallelApplierClosed = errors.New("parallel applier closed")
+
+const (
+	sqlSelectUniqueKeyTables = "select distinct table_name from information_schema.statistics where table_schema=%s and non_unique=0 and index_name!='PRIMARY'"
+	sqlSelectForeignKeys     = "select table_name, referenced_table_name from information_schema.referential_constraints where constraint_schema=%s and unique_constraint_schema=%s"
+)
+
+// parallelApplier applies the transactions of the running phase concurrently
+// over several connections. The binlog events do not tell which transactions
+// depend on each other, so the applier detects conflicts itself: two
+// transactions conflict if they change a row with the same primary key.
+// Applied out of order, changes of different rows can still fail with a
+// duplicate key error on a secondary unique key, e.g. when a row is deleted
+// and another one is inserted with its unique value, or with a foreign key
+// error, e.g. when a child row is inserted before its parent row. So, the
+// transactions which change a table with a secondary unique key conflict
+// with each other, as do those which change tables linked by a foreign key.
+// Conflicting transactions are never applied at the same time, and a
+// transaction whose changes cannot be keyed, such as a statement based one,
+// is applied on its own.
+//
+// The transactions are committed in the order of the source binlog, and each
+// commit saves the position of its transaction in _vt.vreplication. So, the
+// saved position is always consistent with the applied data, and a restarted
+// workflow resumes where the last committed transaction left off.
+//
+// Because transactions wait for their turn to commit while holding their row
+// locks, a later transaction may block an earlier one through a lock that is
+// not covered by the row keys, e.g. a gap lock. If a
+// transaction runs into a lock wait timeout or a deadlock, it and all the
+// later in-flight transactions are rolled back and re-applied in commit order.
+// A transaction re-applied in commit order can still run into the locks of
+// other sessions, in which case it is rolled back and re-applied from its
+// start until it commits.
+//
+// Events which are not part of a row based transaction, such as DDLs, are
+// applied by the vplayer on its own connection once all in-flight
+// transactions are committed.
+type parallelApplier struct {
+	vp *vplayer
+	// conns holds the idle connections.
+	conns    chan *parallelConn
+	allConns []*parallelConn
+	wg       sync.WaitGroup
+	stop     func() bool
+
+	// These are only accessed by the vplayer goroutine.
+	// cur is the transaction that is being read from the relay log.
+	cur *parallelTrx
+	// held is the last complete transaction. It is held back until the next
+	// transaction is complete in order to group them if they conflict.
+	held     *parallelTrx
+	pkFields map[*TablePlan][]int
+	// tableKeys has the keys which all the transactions that change a table
+	// hold, by table. See parallelTableKeys.
+	tableKeys map[string][]string
+
+	mu   sync.Mutex
+	cond sync.Cond
+	// seq is the sequence number of the last dispatched transaction.
+	seq int64
+	// committed is the sequence number of the last committed transaction.
+	committed int64
+	// inflight has the dispatched transactions that are not committed yet.
+	inflight map[int64]*parallelTrx
+	// rowKeys has the row keys of the in-flight transactions.
+	rowKeys map[string]struct{}
+	// exclusive is set while a transaction that must be applied on its own
+	// is in flight.
+	exclusive bool
+	// serializing counts the in-flight transactions which are re-applied in
+	// commit order after a lock wait.
+	serializing int
+	// lastSaved is the time the last transaction was committed.
+	lastSaved time.Time
+	err       error
+}
+
+// parallelConn is a connection of the parallelApplier along with its session state.
+type parallelConn struct {
+	*vdbClient
+	// foreignKeyChecksEnabled is the current value of @@session.foreign_key_checks.
+	// New connections start with foreign key checks disabled.
+	foreignKeyChecksEnabled bool
+}
+
+// parallelTrx is a transaction, or a group of conflicting transactions,
+// applied by the parallelApplier.
+type parallelTrx struct {
+	seq    int64
+	events []parallelEvent
+	// rowKeys identify the rows changed by the transaction.
+	rowKeys map[string]struct{}
+	// exclusive is set if the transaction must be applied on its own.
+	exclusive bool
+	// updateFKChecks is set if the foreign key checks of the source must be
+	// honored. See vplayer.updateFKCheck.
+	updateFKChecks bool
+	// pos and timestamp are those of the last source transaction.
+	pos       replication.Position
+	timestamp int64
+	// retry is set if the transaction must be rolled back and re-applied
+	// once all previous transactions are committed.
+	retry bool
+	// serialized is set while the transaction is re-applied in commit order.
+	serialized bool
+}
+
+// parallelEvent is an event of a parallelTrx. For row events, tplan is the
+// plan of the table at the time the event was read.
+type parallelEvent struct {
+	event *binlogdatapb.VEvent
+	tplan *TablePlan
+}
+
+func newParallelApplier(ctx context.Context, vp *vplayer, workers int) (*parallelApplier, error) {
+	pa := &parallelApplier{
+		vp:        vp,
+		conns:     make(chan *parallelConn, workers),
+		pkFields:  make(map[*TablePlan][]int),
+		inflight:  make(map[int64]*parallelTrx),
+		rowKeys:   make(map[string]struct{}),
+		lastSaved: vp.timeLastSaved,
+	}
+	pa.cond.L = &pa.mu
+	for range workers {
+		dbClient, err := vp.vr.newClientConnection(ctx)
+		if err != nil {
+			pa.close()
+			return nil, fmt.Errorf("failed to create new db client: %s", err.Error())
+		}
+		conn := &parallelConn{vdbClient: dbClient}
+		pa.allConns = append(pa.allConns, conn)
+		pa.conns <- conn
+	}
+	if err := pa.loadTableKeys(); err != nil {
+		pa.close()
+		return nil, err
+	}
+	pa.stop = context.AfterFunc(ctx, func() {
+		pa.fail(ctx.Err())
+	})
+	log.Infof("VReplication player id: %v applies transactions over %d connections", vp.vr.id, workers)
+	return pa, nil
+}
+
+// loadTableKeys reads the secondary unique keys and the foreign keys of the
+// tables of the target database.
+func (pa *parallelApplier) loadTableKeys() error {
+	dbClient := pa.vp.vr.dbClient
+	dbName := encodeString(dbClient.DBName())
+	uniqueKeyTables, err := dbClient.Execute(fmt.Sprintf(sqlSelectUniqueKeyTables, dbName))
+	if err != nil {
+		return fmt.Errorf("failed to read the unique keys of the tables: %w", err)
+	}
+	foreignKeys, err := dbClient.Execute(fmt.Sprintf(sqlSelectForeignKeys, dbName, dbName))
+	if err != nil {
+		return fmt.Errorf("failed to read the foreign keys of the tables: %w", err)
+	}
+	pa.tableKeys = parallelTableKeys(uniqueKeyTables, foreignKeys)
+	return nil
+}
+
+// parallelTableKeys returns the keys of the tables whose transactions cannot
+// be applied concurrently, given the tables with a secondary unique key and
+// the child and parent tables of the foreign keys. A table with a secondary
+// unique key has a key of its own, and the child and parent tables of a
+// foreign key share the key of the parent table. The keys start with a zero
+// byte, so they never collide with a row key.
+func parallelTableKeys(uniqueKeyTables, foreignKeys *sqltypes.Result) map[string][]string {
+	tableKeys := make(map[string][]string)
+	add := func(table, keyTable string) {
+		key := "\x00" + keyTable
+		if !slices.Contains(tableKeys[table], key) {
+			tableKeys[table] = append(tableKeys[table], key)
+		}
+	}
+	for _, row := range uniqueKeyTables.Rows {
+		table := row[0].ToString()
+		add(table, table)
+	}
+	for _, row := range foreignKeys.Rows {
+		child, parent := row[0].ToString(), row[1].ToString()
+		add(child, parent)
+		add(parent, parent)
+	}
+	return tableKeys
+}
+
+// close stops the applier. Closing the connections aborts the statements
+// of the in-flight transactions, which are rolled back.
+func (pa *parallelApplier) close() {
+	if pa.stop != nil {
+		pa.stop()
+	}
+	pa.fail(errParallelApplierClosed)
+	for _, conn := range pa.allConns {
+		conn.Close()
+	}
+	pa.wg.Wait()
+}
+
+// fail records the first error of the applier and wakes up all waiters.
+func (pa *parallelApplier) fail(err error) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	if pa.err == nil {
+		pa.err = err
+	}
+	pa.cond.Broadcast()
+}
+
+// error returns the error which stopped the applier, if any.
+func (pa *parallelApplier) error() error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.err
+}
+
+// applyEvent is the parallel counterpart of vplayer.applyEvent. The events of
+// a row based transaction are collected and the transaction is dispatched to
+// a connection once its commit is read. groupNext is set for a commit if the
+// relay log has another commit after it.
+func (pa *parallelApplier) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, groupNext bool) error {
+	vp := pa.vp
+	switch event.Type {
+	case binlogdatapb.VEventType_GTID:
+		pos, err := binlogplayer.DecodePosition(event.Gtid)
+		if err != nil {
+			return err
+		}
+		vp.pos = pos
+		// A new position should not be saved until a saveable event occurs.
+		vp.unsavedEvent = nil
+	case binlogdatapb.VEventType_BEGIN:
+		// No-op: the transaction is started by its first change.
+	case binlogdatapb.VEventType_FIELD:
+		tplan, err := vp.replicatorPlan.buildExecutionPlan(event.FieldEvent)
+		if err != nil {
+			return err
+		}
+		// The keys of the rows of the new plan are computed afresh.
+		if old := vp.tablePlans[event.FieldEvent.TableName]; old != nil {
+			delete(pa.pkFields, old)
+		}
+		vp.tablePlans[event.FieldEvent.TableName] = tplan
+	case binlogdatapb.VEventType_ROW:
+		tplan := vp.tablePlans[event.RowEvent.TableName]
+		if tplan == nil {
+			return fmt.Errorf("unexpected event on table %s", event.RowEvent.TableName)
+		}
+		trx := pa.current()
+		trx.events = append(trx.events, parallelEvent{event: event, tplan: tplan})
+		if trx.exclusive {
+			return nil
+		}
+		for _, change := range event.RowEvent.RowChanges {
+			if !pa.addRowKey(trx, tplan, change.Before) || !pa.addRowKey(trx, tplan, change.After) {
+				trx.exclusive = true
+				trx.rowKeys = nil
+				return nil
+			}
+		}
+		for _, key := range pa.tableKeys[tplan.TargetName] {
+			trx.rowKeys[key] = struct{}{}
+		}
+	case binlogdatapb.VEventType_INSERT, binlogdatapb.VEventType_DELETE, binlogdatapb.VEventType_UPDATE,
+		binlogdatapb.VEventType_REPLACE, binlogdatapb.VEventType_SAVEPOINT:
+		sql := event.Statement
+		if sql == "" {
+			sql = event.Dml
+		}
+		// If the event is for one of the AWS RDS "special" or pt-table-checksum tables, we skip
+		if strings.Contains(sql, " mysql.rds_") || strings.Contains(sql, " percona.checksums") {
+			return nil
+		}
+		if event.Type != binlogdatapb.VEventType_SAVEPOINT && !vp.canAcceptStmtEvents {
+			return fmt.Errorf("filter rules are not supported for SBR replication: %v", vp.vr.source.Filter.GetRules())
+		}
+		trx := pa.current()
+		trx.events = append(trx.events, parallelEvent{event: event})
+		if event.Type != binlogdatapb.VEventType_SAVEPOINT {
+			// The rows changed by a statement are unknown.
+			trx.exclusive = true
+			trx.rowKeys = nil
+		}
+	case binlogdatapb.VEventType_COMMIT:
+		trx := pa.cur
+		pa.cur = nil
+		if trx == nil {
+			// We're skipping an empty transaction. We may have to save the position on inactivity.
+			vp.unsavedEvent = event
+		} else {
+			trx.pos = vp.pos
+			trx.timestamp = event.Timestamp
+			switch {
+			case pa.held == nil:
+				pa.held = trx
+			case pa.held.conflictsWith(trx):
+				// Like the serial apply, which commits all the transactions of the
+				// relay log at once, we commit conflicting transactions together as
+				// they cannot be applied concurrently anyway.
+				pa.held.merge(trx)
+			default:
+				if err := pa.dispatch(ctx, pa.held); err != nil {
+					return err
+				}
+				pa.held = trx
+			}
+		}
+		if !groupNext && pa.held != nil {
+			held := pa.held
+			pa.held = nil
+			return pa.dispatch(ctx, held)
+		}
+	case binlogdatapb.VEventType_HEARTBEAT:
+		if event.Throttled {
+			if err := vp.vr.updateTimeThrottled(throttlerapp.VStreamerName, event.ThrottledReason); err != nil {
+				return err
+			}
+		}
+		if pa.cur == nil {
+			vp.numAccumulatedHeartbeats++
+			if err := vp.recordHeartbeat(); err != nil {
+				return err
+			}
+		}
+	default:
+		// All other events are applied by the vplayer once the previous
+		// transactions are committed.
+		if pa.held != nil {
+			held := pa.held
+			pa.held = nil
+			if err := pa.dispatch(ctx, held); err != nil {
+				return err
+			}
+		}
+		if err := pa.drain(); err != nil {
+			return err
+		}
+		if err := vp.applyEvent(ctx, event, false); err != nil {
+			return err
+		}
+		if event.Type == binlogdatapb.VEventType_DDL {
+			// The DDL may have added or dropped a unique or foreign key.
+			return pa.loadTableKeys()
+		}
+	}
+	return nil
+}
+
+// current returns the transaction that is being read from the relay log.
+func (pa *parallelApplier) current() *parallelTrx {
+	if pa.cur == nil {
+		pa.cur = &parallelTrx{
+			rowKeys:        make(map[string]struct{}),
+			updateFKChecks: pa.vp.mustUpdateFKCheck(),
+		}
+	}
+	return pa.cur
+}
+
+// addRowKey adds the key of the row to the transaction. It returns false if
+// the row cannot be keyed.
+func (pa *parallelApplier) addRowKey(trx *parallelTrx, tplan *TablePlan, row *querypb.Row) bool {
+	if row == nil {
+		return true
+	}
+	fields, ok := pa.pkFields[tplan]
+	if !ok {
+		for _, pkref := range tplan.PKReferences {
+			idx := -1
+			for i, field := range tplan.Fields {
+				if field.Name == pkref {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				fields = nil
+				break
+			}
+			fields = append(fields, idx)
+		}
+		pa.pkFields[tplan] = fields
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	key := []byte(tplan.TargetName)
+	vals := sqltypes.MakeRowTrusted(tplan.Fields, row)
+	for _, idx := range fields {
+		raw := vals[idx].Raw()
+		// Values which are equal according to the collation of the column,
+		// e.g. 'a' and 'A', identify the same row.
+		if field := tplan.Fields[idx]; sqltypes.IsText(field.Type) {
+			if coll := colldata.Lookup(collations.ID(field.Charset)); coll != nil {
+				raw = coll.WeightString(nil, raw, 0)
+			}
+		}
+		key = binary.AppendUvarint(key, uint64(len(raw)))
+		key = append(key, raw...)
+	}
+	trx.rowKeys[string(key)] = struct{}{}
+	return true
+}
+
+// conflictsWith returns true if the transactions cannot be applied concurrently.
+func (trx *parallelTrx) conflictsWith(other *parallelTrx) bool {
+	if trx.exclusive || other.exclusive {
+		return true
+	}
+	for key := range other.rowKeys {
+		if _, ok := trx.rowKeys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// merge appends the later transaction other to trx.
+func (trx *parallelTrx) merge(other *parallelTrx) {
+	trx.events = append(trx.events, other.events...)
+	if other.exclusive {
+		trx.exclusive = true
+		trx.rowKeys = nil
+	}
+	for key := range other.rowKeys {
+		if !trx.exclusive {
+			trx.rowKeys[key] = struct{}{}
+		}
+	}
+	trx.updateFKChecks = other.updateFKChecks
+	trx.pos = other.pos
+	trx.timestamp = other.timestamp
+}
+
+// dispatch waits until the transaction does not conflict with any in-flight
+// transaction and applies it on an idle connection.
+func (pa *parallelApplier) dispatch(ctx context.Context, trx *parallelTrx) error {
+	var conn *parallelConn
+	select {
+	case conn = <-pa.conns:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	pa.mu.Lock()
+	for pa.err == nil && !pa.canStart(trx) {
+		pa.cond.Wait()
+	}
+	if pa.err != nil {
+		pa.mu.Unlock()
+		pa.conns <- conn
+		return pa.err
+	}
+	pa.seq++
+	trx.seq = pa.seq
+	pa.inflight[trx.seq] = trx
+	for key := range trx.rowKeys {
+		pa.rowKeys[key] = struct{}{}
+	}
+	if trx.exclusive {
+		pa.exclusive = true
+	}
+	pa.mu.Unlock()
+
+	pa.wg.Add(1)
+	go func() {
+		defer pa.wg.Done()
+		defer func() { pa.conns <- conn }()
+		pa.run(ctx, conn, trx)
+	}()
+	return nil
+}
+
+// canStart returns true if the transaction can be applied concurrently with
+// the in-flight transactions. It must be called with mu held.
+func (pa *parallelApplier) canStart(trx *parallelTrx) bool {
+	if pa.serializing > 0 || pa.exclusive {
+		return false
+	}
+	if trx.exclusive {
+		return len(pa.inflight) == 0
+	}
+	for key := range trx.rowKeys {
+		if _, ok := pa.rowKeys[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// drain waits until all in-flight transactions are committed.
+func (pa *parallelApplier) drain() error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	for pa.err == nil && len(pa.inflight) > 0 {
+		pa.cond.Wait()
+	}
+	if pa.err != nil {
+		return pa.err
+	}
+	if pa.lastSaved.After(pa.vp.timeLastSaved) {
+		pa.vp.timeLastSaved = pa.lastSaved
+	}
+	return nil
+}
+
+// run applies and commits the transaction on conn.
+func (pa *parallelApplier) run(ctx context.Context, conn *parallelConn, trx *parallelTrx) {
+	err := pa.execute(ctx, conn, trx)
+	if isLockWaitError(err) {
+		log.Infof("Lock wait while applying transactions in parallel, applying them in commit order: %v", err)
+		err = conn.Rollback()
+		pa.mu.Lock()
+		for seq, other := range pa.inflight {
+			if seq >= trx.seq {
+				other.retry = true
+			}
+		}
+		pa.cond.Broadcast()
+		pa.mu.Unlock()
+	}
+	if err == nil {
+		err = pa.waitForTurn(ctx, conn, trx)
+	}
+	if err == nil {
+		err = pa.commit(conn, trx)
+	}
+	if err != nil {
+		_ = conn.Rollback()
+		pa.fail(vterrors.Wrapf(err, "error applying transaction at position %s", replication.EncodePosition(trx.pos)))
+		return
+	}
+
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.committed = trx.seq
+	delete(pa.inflight, trx.seq)
+	for key := range trx.rowKeys {
+		delete(pa.rowKeys, key)
+	}
+	if trx.exclusive {
+		pa.exclusive = false
+	}
+	if trx.serialized {
+		pa.serializing--
+	}
+	pa.lastSaved = time.Now()
+	pa.cond.Broadcast()
+}
+
+// waitForTurn waits until all previous transactions are committed. If the
+// transaction has to be retried, it is rolled back and re-applied once it
+// is its turn.
+func (pa *parallelApplier) waitForTurn(ctx context.Context, conn *parallelConn, trx *parallelTrx) error {
+	pa.mu.Lock()
+	for pa.err == nil && pa.committed != trx.seq-1 && !trx.retry {
+		pa.cond.Wait()
+	}
+	if pa.err != nil || !trx.retry {
+		defer pa.mu.Unlock()
+		return pa.err
+	}
+	// Release the locks of this transaction, and do not start any new
+	// transactions until it is re-applied.
+	trx.serialized = true
+	pa.serializing++
+	pa.mu.Unlock()
+	if err := conn.Rollback(); err != nil {
+		return err
+	}
+
+	pa.mu.Lock()
+	for pa.err == nil && pa.committed != trx.seq-1 {
+		pa.cond.Wait()
+	}
+	err := pa.err
+	pa.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for {
+		// No other transaction of the applier holds locks which this one
+		// waits for, but other sessions may. The whole transaction is
+		// re-applied then, since a deadlock rolls it back.
+		err := pa.execute(ctx, conn, trx)
+		if !isLockWaitError(err) {
+			return err
+		}
+		log.Infof("Lock wait while applying a transaction in commit order, waiting for %v and retrying: %v", dbLockRetryDelay, err)
+		if err := conn.Rollback(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dbLockRetryDelay):
+		}
+	}
+}
+
+// execute applies the events of the transaction on conn.
+func (pa *parallelApplier) execute(ctx context.Context, conn *parallelConn, trx *parallelTrx) error {
+	vp := pa.vp
+	query := func(ctx context.Context, sql string) (*sqltypes.Result, error) {
+		return conn.Execute(sql)
+	}
+	applyFunc := vp.rowChangeExecutor(ctx, query)
+
+	if err := conn.Begin(); err != nil {
+		return err
+	}
+	for _, pe := range trx.events {
+		if pe.tplan == nil {
+			sql := pe.event.Statement
+			if sql == "" {
+				sql = pe.event.Dml
+			}
+			start := time.Now()
+			_, err := query(ctx, sql)
+			vp.vr.stats.QueryTimings.Record(vp.phase, start)
+			vp.vr.stats.QueryCount.Add(vp.phase, 1)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		rowEvent := pe.event.RowEvent
+		if trx.updateFKChecks {
+			enabled := rowEvent.Flags&NoForeignKeyCheckFlagBitmask == 0
+			if enabled != conn.foreignKeyChecksEnabled {
+				if _, err := query(ctx, "set @@session.foreign_key_checks="+strconv.FormatBool(enabled)); err != nil {
+					return fmt.Errorf("failed to set session foreign_key_checks: %w", err)
+				}
+				conn.foreignKeyChecksEnabled = enabled
+			}
+		}
+		for _, change := range rowEvent.RowChanges {
+			if _, err := pe.tplan.applyChange(change, applyFunc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// commit saves the position of the transaction and commits it.
+func (pa *parallelApplier) commit(conn *parallelConn, trx *parallelTrx) error {
+	vr := pa.vp.vr
+	update := binlogplayer.GenerateUpdatePos(vr.id, trx.pos, time.Now().Unix(), trx.timestamp, vr.stats.CopyRowCount.Get(), vr.workflowConfig.StoreCompressedGTID)
+	if _, err := conn.Execute(update); err != nil {
+		return fmt.Errorf("error %v updating position", err)
+	}
+	if err := conn.Commit(); err != nil {
+		return err
+	}
+	vr.stats.SetLastPosition(trx.pos)
+	return nil
+}
+
+// isLockWaitError returns true if the error is a lock wait timeout or a deadlock.
+func isLockWaitError(err error) bool {
+	var sqlErr *sqlerror.SQLError
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+	return sqlErr.Number() == sqlerror.ERLockDeadlock || sqlErr.Number() == sqlerror.ERLockWaitTimeout
+}
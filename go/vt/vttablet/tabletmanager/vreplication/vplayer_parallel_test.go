@@ -0,0 +1,46 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestParallelTableKeys(t *testing.T) {
+	uniqueKeyTables := sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name", "varchar"), "uk")
+	foreignKeys := sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|referenced_table_name", "varchar|varchar"),
+		"child1|parent",
+		"child2|parent",
+		"parent|uk",
+	)
+	tableKeys := parallelTableKeys(uniqueKeyTables, foreignKeys)
+	require.Equal(t, map[string][]string{
+		// The transactions changing a table with a secondary unique key
+		// conflict with each other.
+		"uk": {"\x00uk"},
+		// The transactions changing a child table conflict with those
+		// changing its parent table, and with its sibling tables.
+		"child1": {"\x00parent"},
+		"child2": {"\x00parent"},
+		"parent": {"\x00parent", "\x00uk"},
+	}, tableKeys)
+	require.Empty(t, tableKeys["other"])
+}
@@ -19,6 +19,7 @@ package vreplication
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -116,6 +117,7 @@ type vreplicator struct {
 
 	throttleUpdatesRateLimiter *timer.RateLimiter
 	workflowConfig             *vttablet.VReplicationConfig
+	copyLimiter                *copyLimiter
 }
 
 // newVReplicator creates a new vreplicator. The valid fields from the source are:
@@ -265,6 +267,11 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 	vr.throttleUpdatesRateLimiter = timer.NewRateLimiter(time.Second)
 	defer vr.throttleUpdatesRateLimiter.Stop()
 
+	vr.copyLimiter, err = newCopyLimiter(vr.workflowConfig)
+	if err != nil {
+		return err
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -345,7 +352,14 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 				vr.stats.ErrorCounts.Add([]string{"Replicate"}, 1)
 				return err
 			}
-			return newVPlayer(vr, settings, nil, replication.Position{}, "replicate").play(ctx)
+			err := newVPlayer(vr, settings, nil, replication.Position{}, "replicate").play(ctx)
+			if !errors.Is(err, errOnlineDDLComplete) {
+				return err
+			}
+			// Restart the vplayer with the new schema of the altered table.
+			if vr.colInfoMap, err = vr.buildColInfoMap(ctx); err != nil {
+				return err
+			}
 		}
 	}
 }
@@ -1119,10 +1133,11 @@ func (vr *vreplicator) execPostCopyActions(ctx context.Context, tableName string
 
 // supportsDeferredSecondaryKeys tells you if related work should be done
 // for the workflow. Deferring secondary index generation is only supported
-// with MoveTables, Migrate, and Reshard.
+// with MoveTables, Migrate, Replicate, and Reshard.
 func (vr *vreplicator) supportsDeferredSecondaryKeys() bool {
 	return vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_MoveTables) ||
 		vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_Migrate) ||
+		vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_Replicate) ||
 		vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_Reshard)
 }
 
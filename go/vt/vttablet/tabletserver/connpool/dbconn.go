@@ -43,6 +43,10 @@ import (
 
 const defaultKillTimeout = 5 * time.Second
 
+// rowsExaminedQuery returns the rows examined by the statement executing on
+// a connection, given its id.
+const rowsExaminedQuery = "select max(s.rows_examined) from performance_schema.events_statements_current s join performance_schema.threads t on s.thread_id = t.thread_id where t.processlist_id = %d"
+
 // Conn is a db connection for tabletserver.
 // It performs automatic reconnects as needed.
 // Its Execute function has a timeout that can kill
@@ -540,6 +544,31 @@ func (dbc *Conn) killQuery(ctx context.Context, reason string, elapsed time.Dura
 	}
 }
 
+// RowsExamined returns the number of rows examined so far by the statement
+// executing on the connection, as reported by the performance schema. It
+// runs on a connection of the dba pool, so it can be called while the
+// statement runs.
+func (dbc *Conn) RowsExamined(ctx context.Context) (int64, error) {
+	conn, err := dbc.dbaPool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Recycle()
+
+	sql := fmt.Sprintf(rowsExaminedQuery, dbc.conn.ID())
+	qr, err := conn.Conn.ExecuteFetch(sql, 1, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for %s: %v", sql, qr.Rows)
+	}
+	if qr.Rows[0][0].IsNull() {
+		return 0, nil
+	}
+	return qr.Rows[0][0].ToCastInt64()
+}
+
 // Current returns the currently executing query.
 func (dbc *Conn) Current() string {
 	if q := dbc.current.Load(); q != nil {
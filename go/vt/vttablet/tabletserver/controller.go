@@ -125,6 +125,12 @@ type Controller interface {
 
 	// IsDiskStalled returns if the disk is stalled.
 	IsDiskStalled() bool
+
+	// DrainTransactions stops new transactions and waits, up to the timeout, for the open ones to finish.
+	DrainTransactions(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdata.DrainedTransaction, error)
+
+	// StopDraining allows new transactions again after DrainTransactions.
+	StopDraining()
 }
 
 // Ensure TabletServer satisfies Controller interface.
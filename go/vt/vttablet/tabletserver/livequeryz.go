@@ -38,6 +38,7 @@ var (
 			<th>Duration</th>
 			<th>Start</th>
 			<th>ConnectionID</th>
+			<th>Consumer Lag</th>
 			<th>Terminate</th>
 		</tr>
         </thead>
@@ -50,6 +51,7 @@ var (
 			<td>{{.Duration}}</td>
 			<td>{{.Start}}</td>
 			<td>{{.ConnID}}</td>
+			<td>{{.ConsumerLag}}</td>
 			<td><a href='terminate?connID={{.ConnID}}'>Terminate</a></td>
 		</tr>
 	`))
@@ -141,9 +141,113 @@ func analyzeInsert(ins *sqlparser.Insert, tables map[string]*schema.Table) (plan
 	}
 
 	plan.Table = lookupTables(sqlparser.TableExprs{ins.Table}, tables)
+	if len(ins.OnDup) == 0 {
+		return plan, nil
+	}
+
+	// Store the primary key of a single-row upsert as WHERE clause for the
+	// hot row protection (txserializer). It is formatted like the WHERE
+	// clause of an UPDATE on the same row so that both serialize together.
+	row, ok := upsertRow(ins, plan.Table)
+	if !ok {
+		return plan, nil
+	}
+	var pk []sqlparser.Expr
+	for _, col := range plan.Table.PKColumns {
+		name := plan.Table.Fields[col].Name
+		idx := ins.Columns.FindColumn(sqlparser.NewIdentifierCI(name))
+		if idx < 0 {
+			return plan, nil
+		}
+		switch row[idx].(type) {
+		case *sqlparser.Literal, *sqlparser.Argument:
+		default:
+			return plan, nil
+		}
+		pk = append(pk, &sqlparser.ComparisonExpr{
+			Operator: sqlparser.EqualOp,
+			Left:     sqlparser.NewColName(name),
+			Right:    row[idx],
+		})
+	}
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("%v", sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(pk...)))
+	plan.WhereClause = buf.ParsedQuery()
+	plan.UpsertCounters = upsertCounters(ins, row)
 	return plan, nil
 }
 
+// upsertRow returns the values of a single-row INSERT with an explicit
+// column list into a table with a primary key.
+func upsertRow(ins *sqlparser.Insert, table *schema.Table) (sqlparser.ValTuple, bool) {
+	if table == nil || !table.HasPrimary() || ins.Action != sqlparser.InsertAct {
+		return nil, false
+	}
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok || len(rows) != 1 || len(rows[0]) != len(ins.Columns) {
+		return nil, false
+	}
+	return rows[0], true
+}
+
+// upsertCounters returns the counters of an upsert if every ON DUPLICATE KEY
+// UPDATE expression increments a column by a bind variable, e.g.
+// "cnt = cnt + values(cnt)" or "cnt = cnt + :v". Such upserts can be
+// coalesced by summing up their increments. It returns nil otherwise.
+func upsertCounters(ins *sqlparser.Insert, row sqlparser.ValTuple) []UpsertCounter {
+	var counters []UpsertCounter
+	for _, upd := range ins.OnDup {
+		if !upd.Name.Qualifier.IsEmpty() {
+			return nil
+		}
+		idx := ins.Columns.FindColumn(upd.Name.Name)
+		if idx < 0 {
+			return nil
+		}
+		insertArg, ok := row[idx].(*sqlparser.Argument)
+		if !ok {
+			return nil
+		}
+		increment, ok := upsertIncrement(ins, upd, insertArg.Name)
+		if !ok {
+			return nil
+		}
+		counters = append(counters, UpsertCounter{Insert: insertArg.Name, Increment: increment})
+	}
+	return counters
+}
+
+// upsertIncrement returns the bind variable name that upd increments its
+// column by. insertArg is returned if the column is incremented by its
+// inserted value. It returns false if upd is not an increment.
+func upsertIncrement(ins *sqlparser.Insert, upd *sqlparser.UpdateExpr, insertArg string) (string, bool) {
+	sum, ok := upd.Expr.(*sqlparser.BinaryExpr)
+	if !ok || sum.Operator != sqlparser.PlusOp {
+		return "", false
+	}
+	left, right := sum.Left, sum.Right
+	if col, ok := right.(*sqlparser.ColName); ok && col.Equal(upd.Name) {
+		left, right = right, left
+	}
+	if col, ok := left.(*sqlparser.ColName); !ok || !col.Equal(upd.Name) {
+		return "", false
+	}
+	switch right := right.(type) {
+	case *sqlparser.Argument:
+		return right.Name, true
+	case *sqlparser.ValuesFuncExpr:
+		if right.Name.Qualifier.IsEmpty() && right.Name.Name.Equal(upd.Name.Name) {
+			return insertArg, true
+		}
+	case *sqlparser.ColName:
+		alias := ins.RowAlias
+		if alias != nil && len(alias.Columns) == 0 && right.Qualifier.Name == alias.TableName && right.Qualifier.Qualifier.IsEmpty() && right.Name.Equal(upd.Name.Name) {
+			return insertArg, true
+		}
+	}
+	return "", false
+}
+
 func analyzeShow(show *sqlparser.Show, dbName string) (plan *Plan, err error) {
 	switch showInternal := show.Internal.(type) {
 	case *sqlparser.ShowBasic:
@@ -41,7 +41,7 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(128)
+		size += int64(144)
 	}
 	// field Table *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.Table
 	size += cached.Table.CachedSize(true)
@@ -67,9 +67,30 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	}
 	// field WhereClause *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
 	size += cached.WhereClause.CachedSize(true)
+	// field UpsertCounters []vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder.UpsertCounter
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.UpsertCounters)) * int64(32))
+		for _, elem := range cached.UpsertCounters {
+			size += elem.CachedSize(false)
+		}
+	}
 	// field FullStmt vitess.io/vitess/go/vt/sqlparser.Statement
 	if cc, ok := cached.FullStmt.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
 	return size
 }
+func (cached *UpsertCounter) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(32)
+	}
+	// field Insert string
+	size += hack.RuntimeAllocSize(int64(len(cached.Insert)))
+	// field Increment string
+	size += hack.RuntimeAllocSize(int64(len(cached.Increment)))
+	return size
+}
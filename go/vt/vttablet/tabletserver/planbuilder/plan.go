@@ -170,14 +170,31 @@ type Plan struct {
 	NextCount evalengine.Expr
 
 	// WhereClause is set for DMLs. It is used by the hot row protection
-	// to serialize e.g. UPDATEs going to the same row.
+	// to serialize e.g. UPDATEs going to the same row. For single-row
+	// upserts, it is derived from the inserted primary key.
 	WhereClause *sqlparser.ParsedQuery
 
+	// UpsertCounters is set for single-row upserts which only increment
+	// counters. It is used by the hot row protection to coalesce them.
+	UpsertCounters []UpsertCounter
+
 	// FullStmt can be used when the query does not operate on tables
 	FullStmt sqlparser.Statement
 
 	// NeedsReservedConn indicates at a reserved connection is needed to execute this plan
 	NeedsReservedConn bool
+
+	// UsesSessionState is set if the query reads or changes user variables
+	// or locks, so that its result depends on the session it runs in.
+	UsesSessionState bool
+}
+
+// UpsertCounter is a counter column of an upsert. Insert is the bind
+// variable of the inserted value and Increment the one of the value added
+// on a duplicate key. They can be the same.
+type UpsertCounter struct {
+	Insert    string
+	Increment string
 }
 
 // TableName returns the table name for the plan.
@@ -261,6 +278,7 @@ func Build(env *vtenv.Environment, statement sqlparser.Statement, tables map[str
 	}
 	plan.AllTables = lookupAllTables(statement, tables)
 	plan.Permissions = BuildPermissions(statement)
+	plan.UsesSessionState = usesSessionState(statement)
 	return plan, nil
 }
 
@@ -284,6 +302,7 @@ func BuildStreaming(statement sqlparser.Statement, tables map[string]*schema.Tab
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s not allowed for streaming", sqlparser.ASTToStatementType(statement))
 	}
+	plan.UsesSessionState = usesSessionState(statement)
 	plan.AllTables = lookupAllTables(statement, tables)
 	return plan, nil
 }
@@ -325,6 +344,22 @@ func hasLockFunc(sel *sqlparser.Select) bool {
 	return found
 }
 
+// usesSessionState returns true if the statement reads or assigns user
+// variables, or uses locking functions.
+func usesSessionState(statement sqlparser.Statement) bool {
+	var found bool
+	_ = sqlparser.Walk(func(in sqlparser.SQLNode) (bool, error) {
+		switch in := in.(type) {
+		case *sqlparser.Variable:
+			found = in.Scope == sqlparser.VariableScope
+		case *sqlparser.LockingFunc, *sqlparser.AssignmentExpr, *sqlparser.SelectInto:
+			found = true
+		}
+		return !found, nil
+	}, statement)
+	return found
+}
+
 // BuildSettingQuery builds a query for system settings.
 func BuildSettingQuery(settings []string, parser *sqlparser.Parser) (query string, resetQuery string, err error) {
 	if len(settings) == 0 {
@@ -47,13 +47,15 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		FullQuery         *sqlparser.ParsedQuery `json:",omitempty"`
 		NextCount         string                 `json:",omitempty"`
 		WhereClause       *sqlparser.ParsedQuery `json:",omitempty"`
+		UpsertCounters    []UpsertCounter        `json:",omitempty"`
 		NeedsReservedConn bool                   `json:",omitempty"`
 	}{
-		PlanID:      p.PlanID,
-		TableName:   p.TableName(),
-		Permissions: p.Permissions,
-		FullQuery:   p.FullQuery,
-		WhereClause: p.WhereClause,
+		PlanID:         p.PlanID,
+		TableName:      p.TableName(),
+		Permissions:    p.Permissions,
+		FullQuery:      p.FullQuery,
+		WhereClause:    p.WhereClause,
+		UpsertCounters: p.UpsertCounters,
 	}
 	if p.NextCount != nil {
 		mplan.NextCount = sqlparser.String(p.NextCount)
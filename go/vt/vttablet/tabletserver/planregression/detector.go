@@ -0,0 +1,392 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package planregression detects changes of the MySQL execution plan of the
+// normalized queries served by vttablet, and reports the ones which come with
+// a latency regression. See the Detector struct for details.
+package planregression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// maxEvents is the number of regression events kept for /debug/plan_regressions.
+const maxEvents = 100
+
+// ExplainFunc runs EXPLAIN for the given query and returns the result.
+type ExplainFunc func(ctx context.Context, sql string) (*sqltypes.Result, error)
+
+// Detector remembers the MySQL execution plan fingerprint of each normalized
+// query and the latencies observed while the query ran under that plan.
+//
+// The plan of a query is sampled at most once per check interval by running
+// EXPLAIN for one of its executions. When the fingerprint changes, e.g. after
+// ANALYZE TABLE or an online DDL, the latencies recorded for the previous plan
+// are kept around. Once both plans have seen enough executions, their mean
+// latencies are compared and the change is reported as a regression if the
+// new plan is slower by more than the configured ratio.
+type Detector struct {
+	env     tabletenv.Env
+	explain ExplainFunc
+
+	// Immutable fields.
+	checkInterval time.Duration
+	latencyRatio  float64
+	minSamples    int64
+	maxQueries    int
+
+	// planChanges counts per table how many times the MySQL execution plan of
+	// a query changed.
+	// regressions counts per table how many of those changes came with a
+	// latency regression.
+	// explainErrors counts how many times sampling the plan failed.
+	planChanges, regressions *stats.CountersWithSingleLabel
+	explainErrors            *stats.Counter
+
+	log *logutil.ThrottledLogger
+
+	// sem limits the number of EXPLAIN queries running concurrently to one.
+	sem chan struct{}
+
+	mu      sync.Mutex
+	queries map[string]*queryHistory
+	events  []*Event
+}
+
+// AccessPath describes how MySQL accesses one table in an execution plan.
+// It corresponds to one row in the output of EXPLAIN.
+type AccessPath struct {
+	Table string
+	Type  string
+	Key   string
+}
+
+// Fingerprint identifies a MySQL execution plan.
+type Fingerprint struct {
+	Hash   string
+	Access []AccessPath
+}
+
+// String returns a human readable representation of the plan.
+func (fp *Fingerprint) String() string {
+	var parts []string
+	for _, ap := range fp.Access {
+		key := ap.Key
+		if key == "" {
+			key = "<none>"
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s:%s", ap.Table, ap.Type, key))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Event describes a detected plan regression.
+type Event struct {
+	Time           time.Time
+	Query          string
+	Table          string
+	OldPlan        string
+	NewPlan        string
+	OldMeanLatency time.Duration
+	NewMeanLatency time.Duration
+	// SuggestedHint is an optimizer hint which pins the index used by the
+	// previous plan. It can be applied with an OPTIMIZER_HINT query rule.
+	SuggestedHint string `json:",omitempty"`
+}
+
+type planStats struct {
+	fingerprint *Fingerprint
+	count       int64
+	total       time.Duration
+}
+
+func (ps *planStats) mean() time.Duration {
+	if ps.count == 0 {
+		return 0
+	}
+	return ps.total / time.Duration(ps.count)
+}
+
+type queryHistory struct {
+	table     string
+	current   *planStats
+	previous  *planStats
+	lastCheck time.Time
+	// checking is true while an EXPLAIN for this query is in flight.
+	checking bool
+	// reported is true once the current plan change was evaluated.
+	reported bool
+}
+
+// New returns a Detector object.
+func New(env tabletenv.Env, explain ExplainFunc) *Detector {
+	config := env.Config()
+	return &Detector{
+		env:           env,
+		explain:       explain,
+		checkInterval: config.PlanRegression.CheckInterval,
+		latencyRatio:  config.PlanRegression.LatencyRatio,
+		minSamples:    int64(config.PlanRegression.MinSamples),
+		maxQueries:    config.PlanRegression.MaxQueries,
+		planChanges: env.Exporter().NewCountersWithSingleLabel(
+			"QueryPlanChanges",
+			"Number of times the MySQL execution plan of a query changed",
+			"table_name"),
+		regressions: env.Exporter().NewCountersWithSingleLabel(
+			"QueryPlanRegressions",
+			"Number of MySQL execution plan changes which came with a latency regression",
+			"table_name"),
+		explainErrors: env.Exporter().NewCounter(
+			"QueryPlanExplainErrors",
+			"Number of times sampling the MySQL execution plan of a query failed"),
+		log:     logutil.NewThrottledLogger("PlanRegression", 5*time.Second),
+		sem:     make(chan struct{}, 1),
+		queries: make(map[string]*queryHistory),
+	}
+}
+
+// Record adds the latency of one execution of query to the plan the query
+// currently runs with. It returns true if the caller should sample the
+// execution plan by calling Check.
+func (d *Detector) Record(query, table string, latency time.Duration) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.queries[query]
+	if !ok {
+		if len(d.queries) >= d.maxQueries {
+			return false
+		}
+		h = &queryHistory{table: table}
+		d.queries[query] = h
+	}
+	if h.current != nil {
+		h.current.count++
+		h.current.total += latency
+		d.evaluateLocked(query, h)
+	}
+	if h.checking || time.Since(h.lastCheck) < d.checkInterval {
+		return false
+	}
+	h.checking = true
+	h.lastCheck = time.Now()
+	return true
+}
+
+// Check samples the execution plan of query by running EXPLAIN for sql, which
+// must be one execution of query with all bind variables substituted.
+// It must only be called after Record returned true.
+func (d *Detector) Check(ctx context.Context, query, sql string) {
+	fp, err := d.sample(ctx, sql)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.queries[query]
+	if !ok {
+		return
+	}
+	h.checking = false
+	if err != nil {
+		d.explainErrors.Add(1)
+		d.log.Warningf("Failed to sample the MySQL execution plan: %v", err)
+		return
+	}
+	if fp == nil {
+		// Another EXPLAIN was running. Retry with the next execution.
+		h.lastCheck = time.Time{}
+		return
+	}
+	switch {
+	case h.current == nil:
+		h.current = &planStats{fingerprint: fp}
+	case h.current.fingerprint.Hash != fp.Hash:
+		d.planChanges.Add(h.table, 1)
+		h.previous = h.current
+		h.current = &planStats{fingerprint: fp}
+		h.reported = false
+		d.log.Infof("MySQL execution plan for table %s changed from [%s] to [%s]", h.table, h.previous.fingerprint.String(), fp.String())
+	}
+}
+
+// Skip gives up a sample which Record asked for, e.g. because the query could
+// not be generated. The plan is sampled again with the next execution.
+func (d *Detector) Skip(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if h, ok := d.queries[query]; ok {
+		h.checking = false
+		h.lastCheck = time.Time{}
+	}
+}
+
+// sample runs EXPLAIN for sql and returns the fingerprint of the plan. A nil
+// fingerprint and error are returned if another EXPLAIN is already running.
+func (d *Detector) sample(ctx context.Context, sql string) (*Fingerprint, error) {
+	select {
+	case d.sem <- struct{}{}:
+	default:
+		return nil, nil
+	}
+	defer func() { <-d.sem }()
+
+	qr, err := d.explain(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return FingerprintFromExplain(qr)
+}
+
+// evaluateLocked compares the latencies of the previous and the current plan
+// once both have enough samples. The method has the suffix "Locked" to clarify
+// that "d.mu" must be locked.
+func (d *Detector) evaluateLocked(query string, h *queryHistory) {
+	if h.previous == nil || h.reported {
+		return
+	}
+	if h.previous.count < d.minSamples || h.current.count < d.minSamples {
+		return
+	}
+	h.reported = true
+
+	oldMean, newMean := h.previous.mean(), h.current.mean()
+	if float64(newMean) <= float64(oldMean)*d.latencyRatio {
+		return
+	}
+
+	d.regressions.Add(h.table, 1)
+	ev := &Event{
+		Time:           time.Now(),
+		Query:          query,
+		Table:          h.table,
+		OldPlan:        h.previous.fingerprint.String(),
+		NewPlan:        h.current.fingerprint.String(),
+		OldMeanLatency: oldMean,
+		NewMeanLatency: newMean,
+		SuggestedHint:  suggestHint(h.previous.fingerprint, h.current.fingerprint),
+	}
+	if d.env.Config().SanitizeLogMessages {
+		d.log.Warningf("MySQL execution plan regression for table %s: mean latency went from %v with plan [%s] to %v with plan [%s]", ev.Table, oldMean, ev.OldPlan, newMean, ev.NewPlan)
+	} else {
+		d.log.Warningf("MySQL execution plan regression for query %q: mean latency went from %v with plan [%s] to %v with plan [%s]", query, oldMean, ev.OldPlan, newMean, ev.NewPlan)
+	}
+	if len(d.events) >= maxEvents {
+		d.events = d.events[1:]
+	}
+	d.events = append(d.events, ev)
+}
+
+// suggestHint returns an optimizer hint which forces the indexes used by the
+// previous plan for the tables whose index changed.
+func suggestHint(previous, current *Fingerprint) string {
+	currentKeys := make(map[string]string, len(current.Access))
+	for _, ap := range current.Access {
+		currentKeys[ap.Table] = ap.Key
+	}
+	var hints []string
+	for _, ap := range previous.Access {
+		if ap.Key == "" {
+			continue
+		}
+		if key, ok := currentKeys[ap.Table]; ok && key == ap.Key {
+			continue
+		}
+		hints = append(hints, fmt.Sprintf("INDEX(%s %s)", ap.Table, ap.Key))
+	}
+	return strings.Join(hints, " ")
+}
+
+// FingerprintFromExplain builds the fingerprint of the plan described by the
+// result of a traditional EXPLAIN. Only the table, access type and chosen key
+// of every row are taken into account, so that changing row estimates do not
+// count as a plan change.
+func FingerprintFromExplain(qr *sqltypes.Result) (*Fingerprint, error) {
+	tableIdx, typeIdx, keyIdx := -1, -1, -1
+	for i, field := range qr.Fields {
+		switch strings.ToLower(field.Name) {
+		case "table":
+			tableIdx = i
+		case "type":
+			typeIdx = i
+		case "key":
+			keyIdx = i
+		}
+	}
+	if tableIdx == -1 || typeIdx == -1 || keyIdx == -1 {
+		return nil, fmt.Errorf("unexpected EXPLAIN output: missing table, type or key column")
+	}
+
+	fp := &Fingerprint{}
+	hash := fnv.New64a()
+	for _, row := range qr.Rows {
+		ap := AccessPath{
+			Table: row[tableIdx].ToString(),
+			Type:  row[typeIdx].ToString(),
+			Key:   row[keyIdx].ToString(),
+		}
+		fp.Access = append(fp.Access, ap)
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", ap.Table, ap.Type, ap.Key)
+	}
+	fp.Hash = fmt.Sprintf("%016x", hash.Sum64())
+	return fp, nil
+}
+
+// Events returns the most recent plan regressions, oldest first.
+func (d *Detector) Events() []*Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	events := make([]*Event, len(d.events))
+	copy(events, d.events)
+	return events
+}
+
+// ServeHTTP lists the most recent plan regressions.
+func (d *Detector) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	events := d.Events()
+	if d.env.Config().SanitizeLogMessages {
+		for i, ev := range events {
+			redacted := *ev
+			redacted.Query = ""
+			events[i] = &redacted
+		}
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		response.Write([]byte(err.Error()))
+		return
+	}
+	response.Write(b)
+}
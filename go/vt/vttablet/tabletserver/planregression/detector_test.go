@@ -0,0 +1,171 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planregression
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func explainResult(rows ...string) *sqltypes.Result {
+	fields := sqltypes.MakeTestFields("id|select_type|table|type|possible_keys|key|rows", "int64|varchar|varchar|varchar|varchar|varchar|int64")
+	return sqltypes.MakeTestResult(fields, rows...)
+}
+
+type fakeExplainer struct {
+	result *sqltypes.Result
+	err    error
+	sqls   []string
+}
+
+func (fe *fakeExplainer) explain(ctx context.Context, sql string) (*sqltypes.Result, error) {
+	fe.sqls = append(fe.sqls, sql)
+	return fe.result, fe.err
+}
+
+func newTestDetector(t *testing.T, fe *fakeExplainer) *Detector {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.PlanRegression.Enable = true
+	cfg.PlanRegression.CheckInterval = time.Hour
+	cfg.PlanRegression.LatencyRatio = 2
+	cfg.PlanRegression.MinSamples = 3
+	cfg.PlanRegression.MaxQueries = 2
+	d := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, t.Name()), fe.explain)
+	d.planChanges.ResetAll()
+	d.regressions.ResetAll()
+	d.explainErrors.Reset()
+	return d
+}
+
+func TestFingerprintFromExplain(t *testing.T) {
+	fp1, err := FingerprintFromExplain(explainResult("1|SIMPLE|t|ref|idx_a,idx_b|idx_a|10"))
+	require.NoError(t, err)
+	assert.Equal(t, "t:ref:idx_a", fp1.String())
+
+	// Changing row estimates don't change the plan.
+	fp2, err := FingerprintFromExplain(explainResult("1|SIMPLE|t|ref|idx_a,idx_b|idx_a|10000"))
+	require.NoError(t, err)
+	assert.Equal(t, fp1.Hash, fp2.Hash)
+
+	fp3, err := FingerprintFromExplain(explainResult("1|SIMPLE|t|ALL|idx_a,idx_b||10000"))
+	require.NoError(t, err)
+	assert.NotEqual(t, fp1.Hash, fp3.Hash)
+	assert.Equal(t, "t:ALL:<none>", fp3.String())
+
+	_, err = FingerprintFromExplain(sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64")))
+	assert.ErrorContains(t, err, "unexpected EXPLAIN output")
+}
+
+func TestDetectorRegression(t *testing.T) {
+	fe := &fakeExplainer{result: explainResult("1|SIMPLE|t|ref|idx_a|idx_a|10")}
+	d := newTestDetector(t, fe)
+	ctx := context.Background()
+	query := "select * from t where a = :a"
+
+	// The first execution asks for a sample, the following ones don't until
+	// the check interval passed.
+	require.True(t, d.Record(query, "t", time.Millisecond))
+	d.Check(ctx, query, "select * from t where a = 1")
+	assert.Equal(t, []string{"select * from t where a = 1"}, fe.sqls)
+	for range 5 {
+		require.False(t, d.Record(query, "t", time.Millisecond))
+	}
+
+	// The plan flips to a full table scan.
+	fe.result = explainResult("1|SIMPLE|t|ALL|idx_a||10000")
+	d.queries[query].lastCheck = time.Time{}
+	require.True(t, d.Record(query, "t", time.Millisecond))
+	d.Check(ctx, query, "select * from t where a = 2")
+	assert.EqualValues(t, 1, d.planChanges.Counts()["t"])
+
+	for range 3 {
+		d.Record(query, "t", 10*time.Millisecond)
+	}
+	assert.EqualValues(t, 1, d.regressions.Counts()["t"])
+
+	events := d.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, query, events[0].Query)
+	assert.Equal(t, "t:ref:idx_a", events[0].OldPlan)
+	assert.Equal(t, "t:ALL:<none>", events[0].NewPlan)
+	assert.Equal(t, "INDEX(t idx_a)", events[0].SuggestedHint)
+
+	// The regression is only reported once per plan change.
+	for range 10 {
+		d.Record(query, "t", 10*time.Millisecond)
+	}
+	assert.EqualValues(t, 1, d.regressions.Counts()["t"])
+
+	response := httptest.NewRecorder()
+	d.ServeHTTP(response, httptest.NewRequest("GET", "/debug/plan_regressions", nil))
+	assert.True(t, strings.Contains(response.Body.String(), `"SuggestedHint": "INDEX(t idx_a)"`), response.Body.String())
+}
+
+func TestDetectorPlanChangeWithoutRegression(t *testing.T) {
+	fe := &fakeExplainer{result: explainResult("1|SIMPLE|t|ref|idx_a|idx_a|10")}
+	d := newTestDetector(t, fe)
+	ctx := context.Background()
+	query := "select * from t where a = :a"
+
+	require.True(t, d.Record(query, "t", time.Millisecond))
+	d.Check(ctx, query, "select * from t where a = 1")
+	for range 3 {
+		d.Record(query, "t", 10*time.Millisecond)
+	}
+
+	fe.result = explainResult("1|SIMPLE|t|ref|idx_b|idx_b|10")
+	d.queries[query].lastCheck = time.Time{}
+	require.True(t, d.Record(query, "t", 10*time.Millisecond))
+	d.Check(ctx, query, "select * from t where a = 1")
+	for range 3 {
+		d.Record(query, "t", 5*time.Millisecond)
+	}
+	assert.EqualValues(t, 1, d.planChanges.Counts()["t"])
+	assert.EqualValues(t, 0, d.regressions.Counts()["t"])
+	assert.Empty(t, d.Events())
+}
+
+func TestDetectorLimitsAndErrors(t *testing.T) {
+	fe := &fakeExplainer{err: errors.New("explain failed")}
+	d := newTestDetector(t, fe)
+	ctx := context.Background()
+
+	require.True(t, d.Record("q1", "t", time.Millisecond))
+	// An in-flight sample is not requested twice.
+	require.False(t, d.Record("q1", "t", time.Millisecond))
+	d.Check(ctx, "q1", "select 1")
+	assert.EqualValues(t, 1, d.explainErrors.Get())
+
+	require.True(t, d.Record("q2", "t", time.Millisecond))
+	d.Skip("q2")
+	require.True(t, d.Record("q2", "t", time.Millisecond))
+
+	// Only MaxQueries distinct queries are tracked.
+	require.False(t, d.Record("q3", "t", time.Millisecond))
+	assert.Len(t, d.queries, 2)
+}
@@ -32,6 +32,7 @@ import (
 	"vitess.io/vitess/go/cache/theine"
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/sync2"
@@ -47,6 +48,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planregression"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -69,8 +71,23 @@ type TabletPlan struct {
 	RowsAffected uint64
 	RowsReturned uint64
 	ErrorCount   uint64
+
+	// downgrade is set by DOWNGRADE_PRIORITY query rules. A nil or expired
+	// downgrade means the plan is not downgraded.
+	downgrade atomic.Pointer[priorityDowngrade]
+}
+
+// priorityDowngrade is the workload priority assigned to a plan by a
+// DOWNGRADE_PRIORITY query rule, until expiry.
+type priorityDowngrade struct {
+	priority int
+	expiry   time.Time
 }
 
+// priorityDowngradeTTL is how long a plan stays downgraded after the last
+// execution going over the limits of a DOWNGRADE_PRIORITY query rule.
+var priorityDowngradeTTL = 1 * time.Minute
+
 // AddStats updates the stats for the current TabletPlan.
 func (ep *TabletPlan) AddStats(queryCount uint64, duration, mysqlTime time.Duration, rowsAffected, rowsReturned, errorCount uint64) {
 	atomic.AddUint64(&ep.QueryCount, queryCount)
@@ -92,6 +109,32 @@ func (ep *TabletPlan) Stats() (queryCount uint64, duration, mysqlTime time.Durat
 	return
 }
 
+// DowngradePriority lowers the workload priority of the executions of the
+// plan in the next priorityDowngradeTTL. While a downgrade is active, the
+// priority of the plan is never raised back.
+func (ep *TabletPlan) DowngradePriority(priority int) {
+	now := time.Now()
+	for {
+		current := ep.downgrade.Load()
+		if current != nil && now.Before(current.expiry) {
+			priority = max(priority, current.priority)
+		}
+		if ep.downgrade.CompareAndSwap(current, &priorityDowngrade{priority: priority, expiry: now.Add(priorityDowngradeTTL)}) {
+			return
+		}
+	}
+}
+
+// DowngradedPriority returns the workload priority assigned by
+// DowngradePriority, if it did not expire yet.
+func (ep *TabletPlan) DowngradedPriority() (int, bool) {
+	downgrade := ep.downgrade.Load()
+	if downgrade == nil || !time.Now().Before(downgrade.expiry) {
+		return 0, false
+	}
+	return downgrade.priority, true
+}
+
 // buildAuthorized builds 'Authorized', which is the runtime part for 'Permissions'.
 func (ep *TabletPlan) buildAuthorized() {
 	ep.Authorized = make([]*tableacl.ACLResult, len(ep.Permissions))
@@ -168,6 +211,17 @@ type QueryEngine struct {
 	// that we start more than one transaction per hot row (range).
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
+	// upsertCoalescer merges concurrent autocommit upserts which increment
+	// counters of the same hot row into one statement.
+	// It is nil if upsert coalescing is disabled.
+	upsertCoalescer *txserializer.Coalescer
+	// streamPacer measures how fast the clients of streaming queries receive
+	// results and terminates streams which exceed their limits.
+	streamPacer *streamPacer
+	// planRegression remembers the MySQL execution plan of each SELECT query
+	// and reports plan changes which make the query slower.
+	// It is nil if plan regression detection is disabled.
+	planRegression *planregression.Detector
 
 	// Vars
 	maxResultSize    atomic.Int64
@@ -193,8 +247,14 @@ type QueryEngine struct {
 	// stats flags
 	enablePerWorkloadTableMetrics bool
 
+	// queryRuleGuardrails counts the queries which went over the limits of
+	// a KILL or DOWNGRADE_PRIORITY query rule, and queryRuleDryRuns the
+	// matches of dry-run query rules.
+	queryRuleGuardrails, queryRuleDryRuns *stats.CountersWithMultiLabels
+
 	// Loggers
 	accessCheckerLogger *logutil.ThrottledLogger
+	queryRuleLogger     *logutil.ThrottledLogger
 
 	redactUIQuery bool
 }
@@ -236,10 +296,18 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		log.Infof("Stream consolidator is enabled with query size set to %d and total size set to %d.",
 			config.ConsolidatorStreamQuerySize, config.ConsolidatorStreamTotalSize)
 		qe.streamConsolidator = NewStreamConsolidator(config.ConsolidatorStreamTotalSize, config.ConsolidatorStreamQuerySize, returnStreamResult)
+		env.Exporter().NewCounterFunc("StreamConsolidatorLaggedFollowers", "Consolidated streams dropped because their client received results too slowly", qe.streamConsolidator.LaggedFollowers)
 	} else {
 		log.Info("Stream consolidator is not enabled.")
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.streamPacer = newStreamPacer(env)
+	if config.HotRowProtection.Mode == tabletenv.Enable && config.HotRowProtection.CoalesceUpserts {
+		qe.upsertCoalescer = txserializer.NewCoalescer(env)
+	}
+	if config.PlanRegression.Enable {
+		qe.planRegression = planregression.New(env, qe.explain)
+	}
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -266,6 +334,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	planbuilder.PassthroughDMLs = config.PassthroughDML
 
 	qe.accessCheckerLogger = logutil.NewThrottledLogger("accessChecker", 1*time.Second)
+	qe.queryRuleLogger = logutil.NewThrottledLogger("queryRules", 1*time.Second)
 
 	env.Exporter().NewGaugeFunc("MaxResultSize", "Query engine max result size", qe.maxResultSize.Load)
 	env.Exporter().NewGaugeFunc("WarnResultSize", "Query engine warn result size", qe.warnResultSize.Load)
@@ -309,6 +378,8 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	qe.queryTextCharsProcessed = env.Exporter().NewCountersWithMultiLabels("QueryTextCharactersProcessed", "query text characters processed", labels)
 	qe.queryErrorCounts = env.Exporter().NewCountersWithMultiLabels("QueryErrorCounts", "query error counts", labels)
 	qe.queryErrorCountsWithCode = env.Exporter().NewCountersWithMultiLabels("QueryErrorCountsWithCode", "query error counts with error code", []string{"Table", "Plan", "Code"})
+	qe.queryRuleGuardrails = env.Exporter().NewCountersWithMultiLabels("QueryRuleGuardrails", "queries over the limits of a query rule", []string{"Rule", "Action", "DryRun"})
+	qe.queryRuleDryRuns = env.Exporter().NewCountersWithMultiLabels("QueryRuleDryRuns", "queries matching a dry-run query rule", []string{"Rule", "Action"})
 
 	env.Exporter().HandleFunc("/debug/hotrows", qe.txSerializer.ServeHTTP)
 	env.Exporter().HandleFunc("/debug/tablet_plans", qe.handleHTTPQueryPlans)
@@ -316,6 +387,9 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	env.Exporter().HandleFunc("/debug/query_rules", qe.handleHTTPQueryRules)
 	env.Exporter().HandleFunc("/debug/consolidations", qe.handleHTTPConsolidations)
 	env.Exporter().HandleFunc("/debug/acl", qe.handleHTTPAclJSON)
+	if qe.planRegression != nil {
+		env.Exporter().HandleFunc("/debug/plan_regressions", qe.planRegression.ServeHTTP)
+	}
 
 	return qe
 }
@@ -561,6 +635,17 @@ func (qe *QueryEngine) schemaChanged(tables map[string]*schema.Table, created, a
 	})
 }
 
+// explain runs EXPLAIN for the given query on a connection of the regular pool.
+// It is used to sample the MySQL execution plan of queries.
+func (qe *QueryEngine) explain(ctx context.Context, sql string) (*sqltypes.Result, error) {
+	conn, err := qe.conns.Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+	return conn.Conn.Exec(ctx, "explain "+sql, 1000, true)
+}
+
 // QueryPlanCacheCap returns the capacity of the query cache.
 func (qe *QueryEngine) QueryPlanCacheCap() int {
 	return qe.plans.MaxCapacity()
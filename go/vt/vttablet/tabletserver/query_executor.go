@@ -21,6 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +33,7 @@ import (
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/callinfo"
@@ -67,6 +71,18 @@ type QueryExecutor struct {
 	// The target type we requested might be different from tsv's tablet type, if we had a change to the tablet type recently.
 	targetTabletType topodatapb.TabletType
 	setting          *smartconnpool.Setting
+	// optimizerHints are added to SELECT queries by OPTIMIZER_HINT query rules.
+	optimizerHints []string
+	// guardrails are the KILL and DOWNGRADE_PRIORITY query rules matching a
+	// SELECT query. Their limits are checked once the query has run, and
+	// the rows scanned by KILL rules also while it runs.
+	guardrails []*rules.Rule
+	// rowsScanned is the number of rows read by MySQL to run the query, as
+	// last sampled by watchRowsScanned. It is only measured if a guardrail
+	// needs it.
+	rowsScanned int64
+	// stream is the progress of a streaming query.
+	stream *streamProgress
 }
 
 const (
@@ -74,8 +90,15 @@ const (
 	resetLastIDQuery  = "select last_insert_id(18446744073709547416)"
 	resetLastIDValue  = 18446744073709547416
 	userLabelDisabled = "UserLabelDisabled"
+	// planSampleTimeout bounds the EXPLAIN queries run by the plan regression detector.
+	planSampleTimeout = 10 * time.Second
 )
 
+// rowsScannedPollInterval is how often the rows scanned by a query are read
+// while it runs, if a guardrail caps them. Queries running for less than
+// the interval are not measured.
+var rowsScannedPollInterval = 1 * time.Second
+
 var (
 	streamResultPool = sync.Pool{New: func() any {
 		return &sqltypes.Result{
@@ -88,7 +111,8 @@ var (
 			Type: sqltypes.Int64,
 		},
 	}
-	errTxThrottled = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "Transaction throttled")
+	errTxThrottled    = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "Transaction throttled")
+	errQueryThrottled = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "Query throttled: priority downgraded by query rule")
 )
 
 func returnStreamResult(result *sqltypes.Result) error {
@@ -149,6 +173,9 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 
 		qre.tsv.qe.AddStats(qre.plan, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, int64(reply.RowsAffected), int64(len(reply.Rows)), 0, errCode)
 		qre.plan.AddStats(1, duration, mysqlTime, reply.RowsAffected, uint64(len(reply.Rows)), 0)
+		if qre.plan.PlanID == p.PlanSelect && qre.connID == 0 {
+			qre.trackMySQLPlan(tableName, duration)
+		}
 		qre.logStats.RowsAffected = int(reply.RowsAffected)
 		qre.logStats.Rows = reply.Rows
 		qre.tsv.Stats().ResultHistogram.Add(int64(len(reply.Rows)))
@@ -170,6 +197,7 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 			return nil, err
 		}
 		defer conn.Unlock()
+		qre.trackSessionState(conn)
 		if qre.setting != nil {
 			applied, err := conn.ApplySetting(qre.ctx, qre.setting)
 			if err != nil {
@@ -191,6 +219,9 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
 			qre.bindVars[sqltypes.BvSchemaName] = sqltypes.StringBindVariable(qre.tsv.config.DB.DBName)
 		}
+		if err := qre.throttleDowngraded(); err != nil {
+			return nil, err
+		}
 		qr, err := qre.execSelect()
 		if err != nil {
 			return nil, err
@@ -198,10 +229,18 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if err := qre.verifyRowCount(int64(len(qr.Rows)), maxrows); err != nil {
 			return nil, err
 		}
+		if err := qre.checkGuardrails(qr); err != nil {
+			return nil, err
+		}
 		return qr, nil
 	case p.PlanOtherRead, p.PlanOtherAdmin, p.PlanFlush, p.PlanSavepoint, p.PlanRelease, p.PlanSRollback:
 		return qre.execOther()
-	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanInsertMessage, p.PlanLoad:
+	case p.PlanInsert:
+		if qre.tsv.qe.upsertCoalescer != nil && len(qre.plan.UpsertCounters) > 0 {
+			return qre.execUpsert()
+		}
+		return qre.execAutocommit(qre.txConnExec)
+	case p.PlanUpdate, p.PlanDelete, p.PlanInsertMessage, p.PlanLoad:
 		return qre.execAutocommit(qre.txConnExec)
 	case p.PlanDDL:
 		return qre.execDDL(nil)
@@ -255,6 +294,81 @@ func (qre *QueryExecutor) execAutocommit(f func(conn *StatefulConnection) (*sqlt
 	return f(conn)
 }
 
+// execUpsert executes an autocommit upsert which only increments counters.
+// It is merged with concurrent upserts of the same row if all of them
+// insert the same values as they increment by.
+func (qre *QueryExecutor) execUpsert() (*sqltypes.Result, error) {
+	key, increments, ok, err := qre.upsertKey()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return qre.execAutocommit(qre.txConnExec)
+	}
+	return qre.tsv.qe.upsertCoalescer.Do(qre.ctx, key, qre.plan.TableName().String(), increments, func(ctx context.Context, increments []int64) (*sqltypes.Result, error) {
+		exec := qre
+		if ctx != qre.ctx {
+			// The upserts are executed as a batch which may outlive this
+			// request, so it must not share its state.
+			batch := *qre
+			batch.ctx = ctx
+			batch.logStats = tabletenv.NewLogStats(ctx, "Execute", streamlog.GetQueryLogConfig())
+			exec = &batch
+		}
+		bindVars := maps.Clone(qre.bindVars)
+		for i, counter := range qre.plan.UpsertCounters {
+			bindVars[counter.Insert] = sqltypes.Int64BindVariable(increments[i])
+			bindVars[counter.Increment] = sqltypes.Int64BindVariable(increments[i])
+		}
+		exec.bindVars = bindVars
+		return exec.execAutocommit(exec.txConnExec)
+	})
+}
+
+// upsertKey returns the key and the increment of each counter under which
+// the upsert can be coalesced. Upserts which only differ in their
+// increments share the same key. It returns false if an increment is not an
+// integer or differs from the inserted value.
+func (qre *QueryExecutor) upsertKey() (string, []int64, bool, error) {
+	if qre.setting != nil {
+		return "", nil, false, nil
+	}
+	bindVars := maps.Clone(qre.bindVars)
+	increments := make([]int64, 0, len(qre.plan.UpsertCounters))
+	for _, counter := range qre.plan.UpsertCounters {
+		inserted, ok := qre.bindVarInt64(counter.Insert)
+		if !ok {
+			return "", nil, false, nil
+		}
+		increment, ok := qre.bindVarInt64(counter.Increment)
+		if !ok || increment != inserted {
+			return "", nil, false, nil
+		}
+		increments = append(increments, increment)
+		bindVars[counter.Insert] = sqltypes.Int64BindVariable(0)
+		bindVars[counter.Increment] = sqltypes.Int64BindVariable(0)
+	}
+	key, err := qre.plan.FullQuery.GenerateQuery(bindVars, nil)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return key, increments, true, nil
+}
+
+// bindVarInt64 returns the value of the integral bind variable name.
+func (qre *QueryExecutor) bindVarInt64(name string) (int64, bool) {
+	bv, ok := qre.bindVars[name]
+	if !ok {
+		return 0, false
+	}
+	v, err := sqltypes.BindVariableToValue(bv)
+	if err != nil || !v.IsIntegral() {
+		return 0, false
+	}
+	i, err := v.ToInt64()
+	return i, err == nil
+}
+
 func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
 	if qre.tsv.txThrottler.Throttle(qre.tsv.getPriorityFromOptions(qre.options), qre.options.GetWorkloadName()) {
 		return nil, errTxThrottled
@@ -316,13 +430,16 @@ func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
 			qre.bindVars[sqltypes.BvSchemaName] = sqltypes.StringBindVariable(qre.tsv.config.DB.DBName)
 		}
-		qr, err := qre.txFetch(conn, false)
+		qr, err := qre.txSelect(conn)
 		if err != nil {
 			return nil, err
 		}
 		if err := qre.verifyRowCount(int64(len(qr.Rows)), maxrows); err != nil {
 			return nil, err
 		}
+		if err := qre.checkGuardrails(qr); err != nil {
+			return nil, err
+		}
 		return qr, nil
 	case p.PlanDDL:
 		return qre.execDDL(conn)
@@ -337,7 +454,7 @@ func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result
 }
 
 // Stream performs a streaming query execution.
-func (qre *QueryExecutor) Stream(callback StreamCallback) error {
+func (qre *QueryExecutor) Stream(callback StreamCallback) (err error) {
 	qre.logStats.PlanType = qre.plan.PlanID.String()
 
 	defer func(start time.Time) {
@@ -355,8 +472,24 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
 			qre.bindVars[sqltypes.BvSchemaName] = sqltypes.StringBindVariable(qre.tsv.config.DB.DBName)
 		}
+		if err := qre.throttleDowngraded(); err != nil {
+			return err
+		}
+		if len(qre.guardrails) > 0 {
+			callback = qre.streamGuardrails(callback)
+		}
 	}
 
+	pacedCtx, progress, callback, finish := qre.tsv.qe.streamPacer.pace(qre.ctx, qre.options.GetWorkloadName(), callback)
+	defer func() {
+		err = finish(err)
+	}()
+	qre.stream = progress
+	// Kill the MySQL query if the stream gets terminated. If the query is
+	// consolidated, the streams that follow this one fail with it, since they
+	// can't get its results anymore.
+	qre.ctx = pacedCtx
+
 	sql, sqlWithoutComments, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
 	if err != nil {
 		return err
@@ -398,6 +531,7 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 			return err
 		}
 		defer txConn.Unlock()
+		qre.trackSessionState(txConn)
 		if qre.setting != nil {
 			if _, err = txConn.ApplySetting(qre.ctx, qre.setting); err != nil {
 				return vterrors.Wrap(err, "failed to execute system setting on the connection")
@@ -455,6 +589,154 @@ func (qre *QueryExecutor) MessageStream(callback StreamCallback) error {
 	return nil
 }
 
+// trackMySQLPlan feeds the latency of this execution to the plan regression
+// detector, and samples the MySQL execution plan in the background when due.
+func (qre *QueryExecutor) trackMySQLPlan(tableName string, duration time.Duration) {
+	detector := qre.tsv.qe.planRegression
+	if detector == nil || !detector.Record(qre.plan.Original, tableName, duration) {
+		return
+	}
+	sql, err := qre.plan.FullQuery.GenerateQuery(qre.bindVars, nil)
+	if err != nil {
+		detector.Skip(qre.plan.Original)
+		return
+	}
+	sql = addOptimizerHints(sql, qre.optimizerHints)
+	query := qre.plan.Original
+	go func() {
+		ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), planSampleTimeout)
+		defer cancel()
+		detector.Check(ctx, query, sql)
+	}()
+}
+
+// throttleDowngraded throttles the query if its plan was downgraded to a
+// lower workload priority by a DOWNGRADE_PRIORITY query rule.
+func (qre *QueryExecutor) throttleDowngraded() error {
+	priority, ok := qre.plan.DowngradedPriority()
+	if !ok {
+		return nil
+	}
+	priority = max(priority, qre.tsv.getPriorityFromOptions(qre.options))
+	if qre.tsv.txThrottler.Throttle(priority, qre.options.GetWorkloadName()) {
+		return errQueryThrottled
+	}
+	return nil
+}
+
+// checkGuardrails applies the guardrail query rules whose limits were
+// exceeded by the query which returned qr.
+func (qre *QueryExecutor) checkGuardrails(qr *sqltypes.Result) error {
+	if len(qre.guardrails) == 0 {
+		return nil
+	}
+	return qre.applyGuardrails(qr.CachedSize(true))
+}
+
+// streamGuardrails wraps callback to apply the guardrail query rules as the
+// results of a streaming query come in. Streaming queries are only checked
+// against MaxResultBytes.
+func (qre *QueryExecutor) streamGuardrails(callback StreamCallback) StreamCallback {
+	var resultBytes int64
+	return func(result *sqltypes.Result) error {
+		resultBytes += result.CachedSize(true)
+		if err := qre.applyGuardrails(resultBytes); err != nil {
+			return err
+		}
+		return callback(result)
+	}
+}
+
+// applyGuardrails applies the guardrail query rules whose limits are
+// exceeded. It returns an error if the query has to be killed. A rule
+// which fired is not checked again for the same query.
+func (qre *QueryExecutor) applyGuardrails(resultBytes int64) error {
+	remaining := qre.guardrails[:0]
+	defer func() {
+		qre.guardrails = remaining
+	}()
+	for i, qr := range qre.guardrails {
+		if !qr.Exceeded(qre.rowsScanned, resultBytes) {
+			remaining = append(remaining, qr)
+			continue
+		}
+		qre.tsv.qe.queryRuleGuardrails.Add([]string{qr.Name, qr.Action().String(), strconv.FormatBool(qr.DryRun())}, 1)
+		if qr.DryRun() {
+			qre.tsv.qe.queryRuleLogger.Infof("query on table %s exceeded the limits of dry-run rule %s with action %s: %d rows scanned, %d result bytes",
+				qre.plan.TableName(), qr.Name, qr.Action(), qre.rowsScanned, resultBytes)
+			continue
+		}
+		switch qr.Action() {
+		case rules.QRKill:
+			remaining = append(remaining, qre.guardrails[i+1:]...)
+			return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query killed due to rule: %s: %d rows scanned, %d result bytes", qr.Description, qre.rowsScanned, resultBytes)
+		case rules.QRDowngradePriority:
+			qre.plan.DowngradePriority(qr.Priority())
+		}
+	}
+	return nil
+}
+
+// watchRowsScanned samples the rows scanned by the query running on conn
+// every rowsScannedPollInterval, if a guardrail caps them. The query must
+// run with the returned context, which gets canceled as soon as the query
+// goes over the limit of a KILL rule. The returned function must be called
+// with the result of the query once it ran: it stops the watchdog and
+// turns the cancellation into the error of the KILL rule.
+func (qre *QueryExecutor) watchRowsScanned(ctx context.Context, conn *connpool.Conn) (context.Context, func(error) error) {
+	if !slices.ContainsFunc(qre.guardrails, func(qr *rules.Rule) bool { return qr.MaxRowsScanned() > 0 }) {
+		return ctx, func(err error) error { return err }
+	}
+	// The watchdog only reads the guardrails: they are updated by
+	// applyGuardrails after it stopped.
+	var kill *rules.Rule
+	for _, qr := range qre.guardrails {
+		if qr.Action() == rules.QRKill && !qr.DryRun() && qr.MaxRowsScanned() > 0 {
+			kill = qr
+			break
+		}
+	}
+
+	execCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	var killed bool
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(rowsScannedPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			rows, err := conn.RowsExamined(execCtx)
+			if err != nil {
+				qre.tsv.qe.queryRuleLogger.Warningf("could not measure rows scanned: %v", err)
+				continue
+			}
+			qre.rowsScanned = rows
+			if kill != nil && kill.Exceeded(rows, 0) {
+				killed = true
+				cancel()
+				return
+			}
+		}
+	}()
+	return execCtx, func(err error) error {
+		close(done)
+		wg.Wait()
+		cancel()
+		if !killed {
+			return err
+		}
+		qre.tsv.qe.queryRuleGuardrails.Add([]string{kill.Name, kill.Action().String(), "false"}, 1)
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "query killed due to rule: %s: %d rows scanned", kill.Description, qre.rowsScanned)
+	}
+}
+
 // checkPermissions returns an error if the query does not pass all checks
 // (denied query, table ACL).
 func (qre *QueryExecutor) checkPermissions() error {
@@ -473,6 +755,14 @@ func (qre *QueryExecutor) checkPermissions() error {
 	}
 
 	action, ruleCancelCtx, timeout, desc := qre.plan.Rules.GetAction(remoteAddr, username, qre.bindVars, qre.marginComments)
+	qre.optimizerHints = qre.plan.Rules.OptimizerHints(remoteAddr, username, qre.bindVars, qre.marginComments)
+	if qre.plan.PlanID == p.PlanSelect || qre.plan.PlanID == p.PlanSelectStream {
+		qre.guardrails = qre.plan.Rules.Guardrails(remoteAddr, username, qre.bindVars, qre.marginComments)
+	}
+	for _, qr := range qre.plan.Rules.DryRunMatches(remoteAddr, username, qre.bindVars, qre.marginComments) {
+		qre.tsv.qe.queryRuleDryRuns.Add([]string{qr.Name, qr.Action().String()}, 1)
+		qre.tsv.qe.queryRuleLogger.Infof("query on table %s matched dry-run rule %s with action %s", qre.plan.TableName(), qr.Name, qr.Action())
+	}
 
 	bufferingTimeoutCtx, cancel := context.WithTimeout(qre.ctx, timeout) // aborts buffering at given timeout
 	defer cancel()
@@ -733,46 +1023,138 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	execute := func() (*sqltypes.Result, error) {
+		conn, err := qre.getConn()
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Recycle()
+		return qre.execDBConn(conn.Conn, sql, true)
+	}
 	// Check tablet type.
 	if qre.shouldConsolidate() {
-		q, original := qre.tsv.qe.consolidator.Create(sqlWithoutComments)
-		if original {
-			defer q.Broadcast()
-			conn, err := qre.getConn()
+		return qre.consolidate(settingsComment(qre.setting, "")+sqlWithoutComments, execute)
+	}
+	return execute()
+}
 
-			if err != nil {
-				q.SetErr(err)
-			} else {
-				defer conn.Recycle()
-				res, err := qre.execDBConn(conn.Conn, sql, true)
-				q.SetResult(res)
-				q.SetErr(err)
-			}
-		} else {
-			waiterCap := qre.tsv.config.ConsolidatorQueryWaiterCap
-			if waiterCap == 0 || *q.AddWaiterCounter(0) <= waiterCap {
-				qre.logStats.QuerySources |= tabletenv.QuerySourceConsolidator
-				startTime := time.Now()
-				q.Wait()
-				qre.tsv.stats.WaitTimings.Record("Consolidations", startTime)
-			}
-			q.AddWaiterCounter(-1)
-		}
-		if q.Err() != nil {
-			return nil, q.Err()
-		}
-		return q.Result(), nil
+// txSelect runs a SELECT on a stateful connection. With --consolidator-stateful-reads,
+// it is consolidated with identical reads of connections in the same state.
+func (qre *QueryExecutor) txSelect(conn *StatefulConnection) (*sqltypes.Result, error) {
+	state, ok := qre.statefulConsolidationState(conn)
+	if !ok {
+		return qre.txFetch(conn, false)
 	}
-	conn, err := qre.getConn()
+	sql, sqlWithoutComments, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Recycle()
-	res, err := qre.execDBConn(conn.Conn, sql, true)
+	return qre.consolidate(state+sqlWithoutComments, func() (*sqltypes.Result, error) {
+		return qre.execTxQuery(conn, sql, false)
+	})
+}
+
+// statefulConsolidationState returns a description of the state of conn
+// which identical reads must share to be consolidated. Reads are only
+// consolidated on connections which hold no other session state than their
+// settings, and in transactions which read committed data: autocommit
+// transactions and read only READ COMMITTED transactions.
+func (qre *QueryExecutor) statefulConsolidationState(conn *StatefulConnection) (string, bool) {
+	if !qre.tsv.config.ConsolidatorStatefulReads || !qre.shouldConsolidate() || conn.sessionState {
+		return "", false
+	}
+	setting := qre.setting
+	if conn.IsTainted() {
+		// The settings of a reserved connection are the ones it was
+		// reserved with, which must be exactly those of the query if any.
+		reservedSetting, ok := qre.reservedSetting(conn)
+		if !ok {
+			return "", false
+		}
+		if setting != nil && (reservedSetting == nil || setting.ApplyQuery() != reservedSetting.ApplyQuery()) {
+			return "", false
+		}
+		setting = reservedSetting
+	}
+	txProps := conn.TxProperties()
+	if txProps == nil || txProps.Autocommit {
+		return settingsComment(setting, ""), true
+	}
+	if !txProps.ReadOnly || txProps.Isolation != querypb.ExecuteOptions_READ_COMMITTED {
+		return "", false
+	}
+	return settingsComment(setting, txProps.Isolation.String()), true
+}
+
+// reservedSetting returns the setting of the system settings conn was
+// reserved with, or nil if it was reserved without any. It returns false
+// if they are not only system settings.
+func (qre *QueryExecutor) reservedSetting(conn *StatefulConnection) (*smartconnpool.Setting, bool) {
+	if len(conn.reservedSettings) == 0 {
+		return nil, true
+	}
+	setting, err := qre.tsv.qe.GetConnSetting(qre.ctx, conn.reservedSettings)
 	if err != nil {
-		return nil, err
+		return nil, false
+	}
+	return setting, true
+}
+
+// trackSessionState records on conn that the query may leave session state
+// behind, unless it is a plain read. Reads of user variables are not plain
+// reads either, since their result depends on the session.
+func (qre *QueryExecutor) trackSessionState(conn *StatefulConnection) {
+	if conn.sessionState {
+		return
+	}
+	switch qre.plan.PlanID {
+	case p.PlanSelect, p.PlanSelectImpossible, p.PlanSelectNoLimit, p.PlanSelectStream, p.PlanShow:
+		if !qre.plan.UsesSessionState {
+			return
+		}
 	}
-	return res, nil
+	conn.sessionState = true
+}
+
+// settingsComment prefixes consolidated queries to tell apart the results of
+// connections with different settings or transaction isolation levels.
+func settingsComment(setting *smartconnpool.Setting, isolation string) string {
+	var parts []string
+	if isolation != "" {
+		parts = append(parts, "isolation "+isolation)
+	}
+	if setting != nil {
+		parts = append(parts, setting.ApplyQuery())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "/* " + strings.Join(parts, "; ") + " */ "
+}
+
+// consolidate runs execute unless an identical query, as identified by key,
+// is already running. In that case, it waits for that query and shares its result.
+func (qre *QueryExecutor) consolidate(key string, execute func() (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	q, original := qre.tsv.qe.consolidator.Create(key)
+	if original {
+		defer q.Broadcast()
+		res, err := execute()
+		q.SetResult(res)
+		q.SetErr(err)
+	} else {
+		waiterCap := qre.tsv.config.ConsolidatorQueryWaiterCap
+		if waiterCap == 0 || *q.AddWaiterCounter(0) <= waiterCap {
+			qre.logStats.QuerySources |= tabletenv.QuerySourceConsolidator
+			startTime := time.Now()
+			q.Wait()
+			qre.tsv.stats.WaitTimings.Record("Consolidations", startTime)
+		}
+		q.AddWaiterCounter(-1)
+	}
+	if q.Err() != nil {
+		return nil, q.Err()
+	}
+	return q.Result(), nil
 }
 
 func (qre *QueryExecutor) execDMLLimit(conn *StatefulConnection) (*sqltypes.Result, error) {
@@ -894,6 +1276,7 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	if err != nil {
 		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
 	}
+	query = addOptimizerHints(query, qre.optimizerHints)
 	if qre.tsv.config.AnnotateQueries {
 		username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 		if username == "" {
@@ -923,6 +1306,26 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	return buf.String(), query, nil
 }
 
+// addOptimizerHints adds the given optimizer hints to a SELECT query. MySQL
+// only honors hints in a comment which directly follows the SELECT keyword.
+// Other queries are returned unchanged.
+func addOptimizerHints(query string, hints []string) string {
+	if len(hints) == 0 || len(query) < len("select ") || !strings.EqualFold(query[:len("select ")], "select ") {
+		return query
+	}
+	var buf strings.Builder
+	buf.Grow(len(query) + 8 + len(hints)*32)
+	buf.WriteString(query[:len("select ")])
+	buf.WriteString("/*+ ")
+	for _, hint := range hints {
+		buf.WriteString(hint)
+		buf.WriteString(" ")
+	}
+	buf.WriteString("*/ ")
+	buf.WriteString(query[len("select "):])
+	return buf.String()
+}
+
 func rewriteOUTParamError(err error) error {
 	sqlErr, ok := err.(*sqlerror.SQLError)
 	if !ok {
@@ -1165,8 +1568,9 @@ func (qre *QueryExecutor) execDBConn(conn *connpool.Conn, sql string, wantfields
 		return nil, err
 	}
 
-	exec, err := conn.Exec(ctx, sql, int(qre.tsv.qe.maxResultSize.Load()), wantfields)
-	if err != nil {
+	execCtx, stopWatch := qre.watchRowsScanned(ctx, conn)
+	exec, err := conn.Exec(execCtx, sql, int(qre.tsv.qe.maxResultSize.Load()), wantfields)
+	if err := stopWatch(err); err != nil {
 		return nil, err
 	}
 
@@ -1193,8 +1597,9 @@ func (qre *QueryExecutor) execStatefulConn(conn *StatefulConnection, sql string,
 		return nil, err
 	}
 
-	exec, err := conn.Exec(ctx, sql, qre.getMaxResultSize(), wantfields)
-	if err != nil {
+	execCtx, stopWatch := qre.watchRowsScanned(ctx, conn.UnderlyingDBConn().Conn)
+	exec, err := conn.Exec(execCtx, sql, qre.getMaxResultSize(), wantfields)
+	if err := stopWatch(err); err != nil {
 		return nil, err
 	}
 
@@ -1260,6 +1665,7 @@ func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction
 	// This change will ensure that long-running streaming stateful queries get gracefully shutdown during ServingTypeChange
 	// once their grace period is over.
 	qd := NewQueryDetail(qre.logStats.Ctx, conn.Conn)
+	qd.stream = qre.stream
 
 	if err := qre.resetLastInsertIDIfNeeded(ctx, conn.Conn); err != nil {
 		return err
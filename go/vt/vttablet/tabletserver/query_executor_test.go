@@ -17,11 +17,13 @@ limitations under the License.
 package tabletserver
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
 	"math/rand/v2"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,6 +38,7 @@ import (
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/callerid"
@@ -50,6 +53,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/txserializer"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txthrottler"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -1270,6 +1274,306 @@ func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorOptimizerHintRule(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery("select /*+ INDEX(test_table idx_a) */ * from test_table limit 10001", want)
+
+	hintRule := rules.NewQueryRule("pin index", "pin index", rules.QROptimizerHint)
+	require.NoError(t, hintRule.SetOptimizerHint("INDEX(test_table idx_a)"))
+	hintRule.AddPlanCond(planbuilder.PlanSelect)
+	hintRule.AddTableCond("test_table")
+
+	rulesName := "optimizerHintRules"
+	rules := rules.New()
+	rules.Add(hintRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules(rulesName, rules))
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	defer tsv.StopService()
+
+	assert.Equal(t, planbuilder.PlanSelect, qre.plan.PlanID)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestQueryExecutorGuardrailRules(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)},
+		},
+	}
+	db.AddQuery("select * from test_table limit 10001", want)
+	// The query runs long enough for its rows scanned to be sampled while it runs.
+	defer func(interval time.Duration) {
+		rowsScannedPollInterval = interval
+	}(rowsScannedPollInterval)
+	rowsScannedPollInterval = 10 * time.Millisecond
+	db.SetBeforeFunc("select * from test_table limit 10001", func() {
+		time.Sleep(200 * time.Millisecond)
+	})
+	db.AddQueryPattern(`select max\(s\.rows_examined\) from performance_schema\.events_statements_current .*`, sqltypes.MakeTestResult(sqltypes.MakeTestFields("max(s.rows_examined)", "int64"), "500"))
+	db.AddQueryPattern(`kill query \d+`, &sqltypes.Result{})
+
+	killRule := rules.NewQueryRule("too many rows scanned", "kill", rules.QRKill)
+	killRule.SetMaxRowsScanned(100)
+	killRule.AddTableCond("test_table")
+
+	downgradeRule := rules.NewQueryRule("result too large", "downgrade", rules.QRDowngradePriority)
+	downgradeRule.SetMaxResultBytes(1)
+	require.NoError(t, downgradeRule.SetPriority(90))
+	downgradeRule.AddTableCond("test_table")
+
+	rulesName := "guardrailRules"
+	qrs := rules.New()
+	qrs.Add(killRule)
+	qrs.Add(downgradeRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleGuardrails.ResetAll()
+
+	// In dry-run mode, the rules only count the queries going over their limits.
+	killRule.SetDryRun(true)
+	downgradeRule.SetDryRun(true)
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules(rulesName, qrs))
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.EqualValues(t, 500, qre.rowsScanned)
+	_, downgraded := qre.plan.DowngradedPriority()
+	assert.False(t, downgraded)
+	assert.Equal(t, map[string]int64{"kill.KILL.true": 1, "downgrade.DOWNGRADE_PRIORITY.true": 1}, tsv.qe.queryRuleGuardrails.Counts())
+
+	// DOWNGRADE_PRIORITY rules are rejected if the transaction throttler is disabled.
+	killRule.SetDryRun(false)
+	downgradeRule.SetDryRun(false)
+	require.ErrorContains(t, tsv.SetQueryRules(rulesName, qrs), "query rule downgrade: DOWNGRADE_PRIORITY requires the transaction throttler")
+	tsv.config.EnableTxThrottler = true
+	require.NoError(t, tsv.SetQueryRules(rulesName, qrs))
+
+	// The query is killed while it runs, by the first rule it goes over.
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err = qre.Execute()
+	require.ErrorContains(t, err, "query killed due to rule: too many rows scanned: 500 rows scanned")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.qe.queryRuleGuardrails.Counts()["kill.KILL.false"])
+
+	// Without the kill rule, the plan gets downgraded and throttled thereafter.
+	qrs.Delete("kill")
+	require.NoError(t, tsv.SetQueryRules(rulesName, qrs))
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	priority, downgraded := qre.plan.DowngradedPriority()
+	assert.True(t, downgraded)
+	assert.Equal(t, 90, priority)
+
+	tsv.txThrottler = mockTxThrottler{throttle: true}
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err = qre.Execute()
+	assert.Equal(t, errQueryThrottled, err)
+}
+
+func TestQueryExecutorStreamGuardrailRules(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	db.AddQuery(query, &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)},
+		},
+	})
+
+	killRule := rules.NewQueryRule("result too large", "kill", rules.QRKill)
+	killRule.SetMaxResultBytes(1)
+	killRule.AddTableCond("test_table")
+
+	rulesName := "streamGuardrailRules"
+	qrs := rules.New()
+	qrs.Add(killRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules(rulesName, qrs))
+
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	assert.Equal(t, planbuilder.PlanSelectStream, qre.plan.PlanID)
+	callbacks := 0
+	err := qre.Stream(func(*sqltypes.Result) error {
+		callbacks++
+		return nil
+	})
+	require.ErrorContains(t, err, "query killed due to rule: result too large")
+	assert.Zero(t, callbacks)
+}
+
+func TestQueryExecutorStreamIdleTimeout(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	db.AddQuery(query, &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)},
+		},
+	})
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.config.StreamPacing.IdleTimeoutPerWorkload = tabletenv.WorkloadDurations{"slow": 10 * time.Millisecond}
+	terminated := tsv.qe.streamPacer.terminated.Counts()["slow.IdleTimeout"]
+
+	// Streams of other workloads are not limited.
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{WorkloadName: "fast"}
+	err := qre.Stream(func(*sqltypes.Result) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+
+	qre = newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{WorkloadName: "slow"}
+	err = qre.Stream(func(*sqltypes.Result) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+	require.EqualError(t, err, "stream client did not receive a result within the idle timeout of 10ms")
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.qe.streamPacer.terminated.Counts()["slow.IdleTimeout"]-terminated)
+}
+
+func TestQueryExecutorStreamConsolidatedMaxDuration(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	db.AddQuery(query, &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)},
+		},
+	})
+	// The query only returns once it is killed.
+	unblock := make(chan struct{})
+	var unblockOnce sync.Once
+	db.SetBeforeFunc(query, func() {
+		<-unblock
+	})
+	db.AddQueryPatternWithCallback(`kill query \d+`, &sqltypes.Result{}, func(string) {
+		unblockOnce.Do(func() { close(unblock) })
+	})
+	defer unblockOnce.Do(func() { close(unblock) })
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.streamConsolidator = NewStreamConsolidator(128*1024*1024, 2*1024*1024, returnStreamResult)
+	tsv.config.StreamPacing.MaxDurationPerWorkload = tabletenv.WorkloadDurations{"olap": 10 * time.Millisecond}
+
+	// The stream leading the consolidated query is terminated even though
+	// MySQL doesn't return any result.
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{WorkloadName: "olap", Consolidator: querypb.ExecuteOptions_CONSOLIDATOR_ENABLED}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- qre.Stream(func(*sqltypes.Result) error {
+			return nil
+		})
+	}()
+	select {
+	case err := <-errCh:
+		require.EqualError(t, err, "stream exceeded its max duration of 10ms")
+		assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "consolidated stream was not terminated")
+	}
+}
+
+func TestTabletPlanDowngradePriority(t *testing.T) {
+	plan := &TabletPlan{}
+	_, downgraded := plan.DowngradedPriority()
+	assert.False(t, downgraded)
+
+	plan.DowngradePriority(0)
+	priority, downgraded := plan.DowngradedPriority()
+	assert.True(t, downgraded)
+	assert.Equal(t, 0, priority)
+
+	plan.DowngradePriority(80)
+	plan.DowngradePriority(50)
+	priority, _ = plan.DowngradedPriority()
+	assert.Equal(t, 80, priority)
+
+	// Once a downgrade expires, the plan gets its priority back.
+	defer func(ttl time.Duration) {
+		priorityDowngradeTTL = ttl
+	}(priorityDowngradeTTL)
+	priorityDowngradeTTL = 0
+	plan.DowngradePriority(50)
+	_, downgraded = plan.DowngradedPriority()
+	assert.False(t, downgraded)
+	priorityDowngradeTTL = time.Hour
+	plan.DowngradePriority(50)
+	priority, downgraded = plan.DowngradedPriority()
+	assert.True(t, downgraded)
+	assert.Equal(t, 50, priority)
+}
+
+func TestAddOptimizerHints(t *testing.T) {
+	testcases := []struct {
+		query string
+		hints []string
+		want  string
+	}{{
+		query: "select a from t",
+		want:  "select a from t",
+	}, {
+		query: "select a from t",
+		hints: []string{"INDEX(t idx_a)"},
+		want:  "select /*+ INDEX(t idx_a) */ a from t",
+	}, {
+		query: "SELECT a from t",
+		hints: []string{"INDEX(t idx_a)", "MAX_EXECUTION_TIME(100)"},
+		want:  "SELECT /*+ INDEX(t idx_a) MAX_EXECUTION_TIME(100) */ a from t",
+	}, {
+		query: "update t set a = 1",
+		hints: []string{"INDEX(t idx_a)"},
+		want:  "update t set a = 1",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			assert.Equal(t, tc.want, addOptimizerHints(tc.query, tc.hints))
+		})
+	}
+}
+
 func TestReplaceSchemaName(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1466,6 +1770,228 @@ func TestQueryExecutorShouldConsolidate(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorConsolidateStatefulReads(t *testing.T) {
+	setting := smartconnpool.NewSetting("set @@sql_mode = ''", "set @@sql_mode = default")
+	otherSetting := smartconnpool.NewSetting("set @@sql_mode = 'ANSI'", "set @@sql_mode = default")
+	readOnly := &querypb.ExecuteOptions{TransactionAccessMode: []querypb.ExecuteOptions_TransactionAccessMode{querypb.ExecuteOptions_READ_ONLY}}
+	readCommitted := &querypb.ExecuteOptions{
+		TransactionAccessMode: []querypb.ExecuteOptions_TransactionAccessMode{querypb.ExecuteOptions_READ_ONLY},
+		TransactionIsolation:  querypb.ExecuteOptions_READ_COMMITTED,
+	}
+	testcases := []struct {
+		name          string
+		statefulReads bool
+		options       *querypb.ExecuteOptions
+		reserved      bool
+		// reservedSettings are the settings the connection is reserved with.
+		reservedSettings []string
+		// before are run on the connection before the query.
+		before  []string
+		query   string
+		setting *smartconnpool.Setting
+		// wantKey is empty if the query must not be consolidated.
+		wantKey string
+	}{{
+		name:    "disabled",
+		options: readCommitted,
+	}, {
+		name:          "read only read committed transaction",
+		statefulReads: true,
+		options:       readCommitted,
+		wantKey:       "/* isolation READ_COMMITTED */ select * from test_table limit 10001",
+	}, {
+		name:          "read only repeatable read transaction",
+		statefulReads: true,
+		options:       readOnly,
+	}, {
+		name:          "read write transaction",
+		statefulReads: true,
+		options:       &querypb.ExecuteOptions{},
+	}, {
+		name:          "consistent snapshot",
+		statefulReads: true,
+		options:       &querypb.ExecuteOptions{TransactionIsolation: querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY},
+	}, {
+		name:          "read only read committed transaction with settings",
+		statefulReads: true,
+		options:       readCommitted,
+		setting:       setting,
+		wantKey:       "/* isolation READ_COMMITTED; set @@sql_mode = '' */ select * from test_table limit 10001",
+	}, {
+		name:          "reserved connection",
+		statefulReads: true,
+		reserved:      true,
+		wantKey:       "select * from test_table limit 10001",
+	}, {
+		name:             "reserved connection with settings",
+		statefulReads:    true,
+		reserved:         true,
+		reservedSettings: []string{"set @@sql_mode = ''"},
+		wantKey:          "/* set @@sql_mode = '' */ select * from test_table limit 10001",
+	}, {
+		name:             "reserved connection with the settings of the query",
+		statefulReads:    true,
+		reserved:         true,
+		reservedSettings: []string{"set @@sql_mode = ''"},
+		setting:          setting,
+		wantKey:          "/* set @@sql_mode = '' */ select * from test_table limit 10001",
+	}, {
+		name:             "reserved connection with other settings than the query",
+		statefulReads:    true,
+		reserved:         true,
+		reservedSettings: []string{"set @@sql_mode = ''"},
+		setting:          otherSetting,
+	}, {
+		name:             "reserved connection with other session state",
+		statefulReads:    true,
+		reserved:         true,
+		reservedSettings: []string{"set @a = 1"},
+	}, {
+		name:          "reserved connection with user variables",
+		statefulReads: true,
+		reserved:      true,
+		before:        []string{"set @a = 1"},
+	}, {
+		name:          "reserved connection with a temporary table",
+		statefulReads: true,
+		reserved:      true,
+		before:        []string{"create temporary table temp_t(id bigint)"},
+	}, {
+		name:          "read of user variables",
+		statefulReads: true,
+		reserved:      true,
+		query:         "select @a from test_table",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			db := setUpQueryExecutorTest(t)
+			defer db.Close()
+			result := &sqltypes.Result{Fields: getTestTableFields()}
+			db.AddQuery("select * from test_table limit 10001", result)
+			db.AddQuery("select @a from test_table limit 10001", result)
+			db.AddQuery("set @@sql_mode = ''", &sqltypes.Result{})
+			db.AddQuery("set @@sql_mode = 'ANSI'", &sqltypes.Result{})
+			db.AddQuery("set @a = 1", &sqltypes.Result{})
+			db.AddQueryPattern(`create temporary table temp_t.*`, &sqltypes.Result{})
+			db.AddQuery("start transaction read only", &sqltypes.Result{})
+			db.AddQuery("set transaction isolation level repeatable read", &sqltypes.Result{})
+			db.AddQuery("set transaction isolation level read committed", &sqltypes.Result{})
+			db.AddQuery("start transaction with consistent snapshot, read only", &sqltypes.Result{})
+
+			ctx := context.Background()
+			tsv := newTestTabletServer(ctx, enableConsolidator, db)
+			defer tsv.StopService()
+			tsv.config.ConsolidatorStatefulReads = tcase.statefulReads
+			fakeConsolidator := sync2.NewFakeConsolidator()
+			fakeConsolidator.CreateReturn = &sync2.FakeConsolidatorCreateReturn{
+				Created:       true,
+				PendingResult: &sync2.FakePendingResult{},
+			}
+			tsv.qe.consolidator = fakeConsolidator
+
+			var connID int64
+			if tcase.reserved {
+				var err error
+				connID, err = tsv.te.Reserve(ctx, nil, 0, tcase.reservedSettings)
+				require.NoError(t, err)
+				defer tsv.Release(ctx, tsv.sm.Target(), 0, connID)
+			} else {
+				connID = newTransaction(tsv, tcase.options)
+				defer tsv.Rollback(ctx, tsv.sm.Target(), connID)
+			}
+			for _, query := range tcase.before {
+				_, err := newTestQueryExecutor(ctx, tsv, query, connID).Execute()
+				require.NoError(t, err)
+			}
+
+			query := cmp.Or(tcase.query, "select * from test_table")
+			qre := newTestQueryExecutor(ctx, tsv, query, connID)
+			qre.setting = tcase.setting
+			got, err := qre.Execute()
+			require.NoError(t, err)
+			assert.Equal(t, result, got)
+			if tcase.wantKey == "" {
+				assert.Empty(t, fakeConsolidator.CreateCalls)
+			} else {
+				assert.Equal(t, []string{tcase.wantKey}, fakeConsolidator.CreateCalls)
+			}
+		})
+	}
+}
+
+func TestQueryExecutorCoalesceUpserts(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	merged := "insert into test_table(pk, cnt) values (1, 3) on duplicate key update cnt = cnt + values(cnt)"
+	db.AddQuery(merged, &sqltypes.Result{RowsAffected: 2})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.upsertCoalescer = txserializer.NewCoalescer(tsv)
+
+	testcases := []struct {
+		name     string
+		query    string
+		bindVars map[string]*querypb.BindVariable
+		// key is empty if the upsert must not be coalesced.
+		key        string
+		increments []int64
+	}{{
+		name:  "increment by inserted value",
+		query: "insert into test_table(pk, cnt) values (:pk, :cnt) on duplicate key update cnt = cnt + values(cnt)",
+		bindVars: map[string]*querypb.BindVariable{
+			"pk":  sqltypes.Int64BindVariable(1),
+			"cnt": sqltypes.Int64BindVariable(3),
+		},
+		key:        "insert into test_table(pk, cnt) values (1, 0) on duplicate key update cnt = cnt + values(cnt)",
+		increments: []int64{3},
+	}, {
+		name:  "same increment as inserted value",
+		query: "insert into test_table(pk, cnt) values (:pk, :v1) on duplicate key update cnt = cnt + :v2",
+		bindVars: map[string]*querypb.BindVariable{
+			"pk": sqltypes.Int64BindVariable(1),
+			"v1": sqltypes.Int64BindVariable(3),
+			"v2": sqltypes.Int64BindVariable(3),
+		},
+		key:        "insert into test_table(pk, cnt) values (1, 0) on duplicate key update cnt = cnt + 0",
+		increments: []int64{3},
+	}, {
+		name:  "different increment than inserted value",
+		query: "insert into test_table(pk, cnt) values (:pk, :v1) on duplicate key update cnt = cnt + :v2",
+		bindVars: map[string]*querypb.BindVariable{
+			"pk": sqltypes.Int64BindVariable(1),
+			"v1": sqltypes.Int64BindVariable(0),
+			"v2": sqltypes.Int64BindVariable(3),
+		},
+	}, {
+		name:  "non-integral increment",
+		query: "insert into test_table(pk, cnt) values (:pk, :cnt) on duplicate key update cnt = cnt + values(cnt)",
+		bindVars: map[string]*querypb.BindVariable{
+			"pk":  sqltypes.Int64BindVariable(1),
+			"cnt": sqltypes.StringBindVariable("3"),
+		},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			qre := newTestQueryExecutor(ctx, tsv, tcase.query, 0)
+			qre.bindVars = tcase.bindVars
+			key, increments, ok, err := qre.upsertKey()
+			require.NoError(t, err)
+			assert.Equal(t, tcase.key != "", ok)
+			assert.Equal(t, tcase.key, key)
+			assert.Equal(t, tcase.increments, increments)
+		})
+	}
+
+	qre := newTestQueryExecutor(ctx, tsv, testcases[0].query, 0)
+	qre.bindVars = testcases[0].bindVars
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, got.RowsAffected)
+	assert.Equal(t, 1, db.GetQueryCalledNum(merged))
+}
+
 func TestGetConnectionLogStats(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -38,6 +38,8 @@ type QueryDetail struct {
 	conn   killable
 	connID int64
 	start  time.Time
+	// stream is the progress of a streaming query, if any.
+	stream *streamProgress
 }
 
 type killable interface {
@@ -175,6 +177,12 @@ type QueryDetailzRow struct {
 	ConnID            int64
 	State             string
 	ShowTerminateLink bool
+	// The following fields are only set for streaming queries.
+	Workload       string        `json:",omitempty"`
+	RowsSent       int64         `json:",omitempty"`
+	BytesSent      int64         `json:",omitempty"`
+	BytesPerSecond int64         `json:",omitempty"`
+	ConsumerLag    time.Duration `json:",omitempty"`
 }
 
 type byStartTime []QueryDetailzRow
@@ -200,6 +208,13 @@ func (ql *QueryList) AppendQueryzRows(rows []QueryDetailzRow) []QueryDetailzRow
 				Duration:    time.Since(qd.start),
 				ConnID:      qd.connID,
 			}
+			if qd.stream != nil {
+				row.Workload = qd.stream.workload
+				row.RowsSent = qd.stream.rows.Load()
+				row.BytesSent = qd.stream.bytes.Load()
+				row.BytesPerSecond = qd.stream.bytesPerSecond()
+				row.ConsumerLag = qd.stream.lag()
+			}
 			rows = append(rows, row)
 		}
 	}
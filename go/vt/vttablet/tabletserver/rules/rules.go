@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -181,6 +182,11 @@ func (qrs *Rules) GetAction(
 	timeout time.Duration,
 	desc string) {
 	for _, qr := range qrs.rules {
+		if qr.act == QROptimizerHint || qr.IsGuardrail() || qr.dryRun {
+			// These rules don't decide the fate of the query before it runs.
+			// See OptimizerHints, Guardrails and DryRunMatches.
+			continue
+		}
 		if act := qr.GetAction(ip, user, bindVars, marginComments); act != QRContinue {
 			return act, qr.cancelCtx, qr.timeout, qr.Description
 		}
@@ -188,6 +194,64 @@ func (qrs *Rules) GetAction(
 	return QRContinue, nil, 0, ""
 }
 
+// OptimizerHints returns the optimizer hints of all OPTIMIZER_HINT rules which
+// match the input, in rule order.
+func (qrs *Rules) OptimizerHints(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) (hints []string) {
+	for _, qr := range qrs.rules {
+		if qr.act != QROptimizerHint || qr.dryRun {
+			continue
+		}
+		if qr.GetAction(ip, user, bindVars, marginComments) == QROptimizerHint {
+			hints = append(hints, qr.optimizerHint)
+		}
+	}
+	return hints
+}
+
+// Guardrails returns the KILL and DOWNGRADE_PRIORITY rules which match the
+// input, in rule order. Their limits can only be checked once the query has
+// run, see Rule.Exceeded. Dry-run guardrails are returned as well.
+func (qrs *Rules) Guardrails(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) (guardrails []*Rule) {
+	for _, qr := range qrs.rules {
+		if !qr.IsGuardrail() {
+			continue
+		}
+		if qr.GetAction(ip, user, bindVars, marginComments) != QRContinue {
+			guardrails = append(guardrails, qr)
+		}
+	}
+	return guardrails
+}
+
+// DryRunMatches returns the dry-run rules, other than guardrails, which match
+// the input. Their action is not applied: callers are expected to only log them.
+func (qrs *Rules) DryRunMatches(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) (matches []*Rule) {
+	for _, qr := range qrs.rules {
+		if !qr.dryRun || qr.IsGuardrail() {
+			continue
+		}
+		if qr.GetAction(ip, user, bindVars, marginComments) != QRContinue {
+			matches = append(matches, qr)
+		}
+	}
+	return matches
+}
+
 // -----------------------------------------------
 
 // Rule represents one rule (conditions-action).
@@ -224,6 +288,19 @@ type Rule struct {
 
 	// a rule can timeout.
 	timeout time.Duration
+
+	// optimizerHint is added to the query by the OPTIMIZER_HINT action.
+	optimizerHint string
+
+	// Limits checked after execution by the KILL and DOWNGRADE_PRIORITY
+	// actions. Zero means no limit.
+	maxRowsScanned, maxResultBytes int64
+
+	// priority is the workload priority assigned by DOWNGRADE_PRIORITY.
+	priority int
+
+	// A dry-run rule never applies its action. Matches are only logged.
+	dryRun bool
 }
 
 type namedRegexp struct {
@@ -269,6 +346,11 @@ func (qr *Rule) Equal(other *Rule) bool {
 		qr.leadingComment.Equal(other.leadingComment) &&
 		qr.trailingComment.Equal(other.trailingComment) &&
 		qr.timeout == other.timeout &&
+		qr.optimizerHint == other.optimizerHint &&
+		qr.maxRowsScanned == other.maxRowsScanned &&
+		qr.maxResultBytes == other.maxResultBytes &&
+		qr.priority == other.priority &&
+		qr.dryRun == other.dryRun &&
 		reflect.DeepEqual(qr.plans, other.plans) &&
 		reflect.DeepEqual(qr.tableNames, other.tableNames) &&
 		reflect.DeepEqual(qr.bindVarConds, other.bindVarConds) &&
@@ -288,6 +370,11 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		act:             qr.act,
 		cancelCtx:       qr.cancelCtx,
 		timeout:         qr.timeout,
+		optimizerHint:   qr.optimizerHint,
+		maxRowsScanned:  qr.maxRowsScanned,
+		maxResultBytes:  qr.maxResultBytes,
+		priority:        qr.priority,
+		dryRun:          qr.dryRun,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]planbuilder.PlanType, len(qr.plans))
@@ -339,6 +426,21 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.timeout != 0 {
 		safeEncode(b, `,"Timeout":`, qr.timeout)
 	}
+	if qr.optimizerHint != "" {
+		safeEncode(b, `,"OptimizerHint":`, qr.optimizerHint)
+	}
+	if qr.maxRowsScanned != 0 {
+		safeEncode(b, `,"MaxRowsScanned":`, qr.maxRowsScanned)
+	}
+	if qr.maxResultBytes != 0 {
+		safeEncode(b, `,"MaxResultBytes":`, qr.maxResultBytes)
+	}
+	if qr.act == QRDowngradePriority {
+		safeEncode(b, `,"Priority":`, qr.priority)
+	}
+	if qr.dryRun {
+		safeEncode(b, `,"DryRun":`, qr.dryRun)
+	}
 	_, _ = b.WriteString("}")
 	return b.Bytes(), nil
 }
@@ -394,6 +496,84 @@ func (qr *Rule) SetTrailingCommentCond(pattern string) (err error) {
 	return
 }
 
+// SetOptimizerHint sets the optimizer hint which the OPTIMIZER_HINT action
+// adds to the query, e.g. "INDEX(t idx_a)". The hint is given without the
+// surrounding /*+ */ comment markers.
+func (qr *Rule) SetOptimizerHint(hint string) error {
+	if strings.Contains(hint, "*/") || strings.Contains(hint, "/*") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "optimizer hint must not contain comment markers: %s", hint)
+	}
+	qr.optimizerHint = strings.TrimSpace(hint)
+	return nil
+}
+
+// SetMaxRowsScanned sets the number of rows the query may read from the
+// storage engine before the KILL or DOWNGRADE_PRIORITY action fires.
+func (qr *Rule) SetMaxRowsScanned(rows int64) {
+	qr.maxRowsScanned = rows
+}
+
+// SetMaxResultBytes sets the size of the result set before the KILL or
+// DOWNGRADE_PRIORITY action fires.
+func (qr *Rule) SetMaxResultBytes(size int64) {
+	qr.maxResultBytes = size
+}
+
+// SetPriority sets the workload priority the DOWNGRADE_PRIORITY action
+// assigns to the query. See sqlparser.DirectivePriority.
+func (qr *Rule) SetPriority(priority int) error {
+	if priority < 0 || priority > sqlparser.MaxPriorityValue {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "priority must be between 0 and %d: %d", sqlparser.MaxPriorityValue, priority)
+	}
+	qr.priority = priority
+	return nil
+}
+
+// SetDryRun makes the rule only log matching queries instead of applying its action.
+func (qr *Rule) SetDryRun(dryRun bool) {
+	qr.dryRun = dryRun
+}
+
+// Action returns the action of the rule.
+func (qr *Rule) Action() Action {
+	return qr.act
+}
+
+// MaxRowsScanned returns the rows scanned limit of the rule.
+func (qr *Rule) MaxRowsScanned() int64 {
+	return qr.maxRowsScanned
+}
+
+// MaxResultBytes returns the result size limit of the rule.
+func (qr *Rule) MaxResultBytes() int64 {
+	return qr.maxResultBytes
+}
+
+// Priority returns the priority assigned by a DOWNGRADE_PRIORITY rule.
+func (qr *Rule) Priority() int {
+	return qr.priority
+}
+
+// DryRun returns true if the rule only logs matching queries.
+func (qr *Rule) DryRun() bool {
+	return qr.dryRun
+}
+
+// IsGuardrail returns true if the action of the rule depends on the
+// resources used by the query, which are only known after execution.
+func (qr *Rule) IsGuardrail() bool {
+	return qr.act == QRKill || qr.act == QRDowngradePriority
+}
+
+// Exceeded returns true if rowsScanned or resultBytes is over the limits
+// of the rule. A negative value means the quantity was not measured.
+func (qr *Rule) Exceeded(rowsScanned, resultBytes int64) bool {
+	if qr.maxRowsScanned > 0 && rowsScanned > qr.maxRowsScanned {
+		return true
+	}
+	return qr.maxResultBytes > 0 && resultBytes > qr.maxResultBytes
+}
+
 // makeExact forces a full string match for the regex instead of substring
 func makeExact(pattern string) string {
 	return fmt.Sprintf("^%s$", pattern)
@@ -573,23 +753,40 @@ const (
 	QRFail
 	QRFailRetry
 	QRBuffer
+	// QROptimizerHint adds an optimizer hint to matching SELECT queries and
+	// lets them continue.
+	QROptimizerHint
+	// QRKill fails queries which go over MaxRowsScanned or MaxResultBytes.
+	QRKill
+	// QRDowngradePriority lowers the workload priority of queries which go
+	// over MaxRowsScanned or MaxResultBytes.
+	QRDowngradePriority
 )
 
-// MarshalJSON marshals to JSON.
-func (act Action) MarshalJSON() ([]byte, error) {
+// String returns the name of the action, as used in the JSON rules.
+func (act Action) String() string {
 	// If we add more actions, we'll need to use a map.
-	var str string
 	switch act {
 	case QRFail:
-		str = "FAIL"
+		return "FAIL"
 	case QRFailRetry:
-		str = "FAIL_RETRY"
+		return "FAIL_RETRY"
 	case QRBuffer:
-		str = "BUFFER"
+		return "BUFFER"
+	case QROptimizerHint:
+		return "OPTIMIZER_HINT"
+	case QRKill:
+		return "KILL"
+	case QRDowngradePriority:
+		return "DOWNGRADE_PRIORITY"
 	default:
-		str = "INVALID"
+		return "INVALID"
 	}
-	return json.Marshal(str)
+}
+
+// MarshalJSON marshals to JSON.
+func (act Action) MarshalJSON() ([]byte, error) {
+	return json.Marshal(act.String())
 }
 
 // BindVarCond represents a bind var condition.
@@ -871,12 +1068,15 @@ func MapStrOperator(strop string) (op Operator, err error) {
 // BuildQueryRule builds a query rule from a ruleInfo.
 func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 	qr = NewQueryRule("", "", QRFail)
+	hasPriority := false
 	for k, v := range ruleInfo {
 		var sv string
 		var lv []any
+		var nv int64
+		var bv bool
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment":
+		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment", "OptimizerHint":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for %s", k)
@@ -886,6 +1086,20 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for %s", k)
 			}
+		case "MaxRowsScanned", "MaxResultBytes", "Priority":
+			num, ok := v.(json.Number)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want number for %s", k)
+			}
+			nv, err = num.Int64()
+			if err != nil || nv < 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want non-negative integer for %s: %s", k, num)
+			}
+		case "DryRun":
+			bv, ok = v.(bool)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want bool for %s", k)
+			}
 		default:
 			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unrecognized tag %s", k)
 		}
@@ -919,6 +1133,22 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 			if err != nil {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set TrailingComment condition: %v", sv)
 			}
+		case "OptimizerHint":
+			err = qr.SetOptimizerHint(sv)
+			if err != nil {
+				return nil, err
+			}
+		case "MaxRowsScanned":
+			qr.SetMaxRowsScanned(nv)
+		case "MaxResultBytes":
+			qr.SetMaxResultBytes(nv)
+		case "Priority":
+			if err = qr.SetPriority(int(nv)); err != nil {
+				return nil, err
+			}
+			hasPriority = true
+		case "DryRun":
+			qr.SetDryRun(bv)
 		case "Plans":
 			for _, p := range lv {
 				pv, ok := p.(string)
@@ -958,11 +1188,30 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 				qr.act = QRFailRetry
 			case "BUFFER":
 				qr.act = QRBuffer
+			case "OPTIMIZER_HINT":
+				qr.act = QROptimizerHint
+			case "KILL":
+				qr.act = QRKill
+			case "DOWNGRADE_PRIORITY":
+				qr.act = QRDowngradePriority
 			default:
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid Action %s", sv)
 			}
 		}
 	}
+	if qr.act == QROptimizerHint && qr.optimizerHint == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "OptimizerHint missing for OPTIMIZER_HINT action")
+	}
+	hasLimit := qr.maxRowsScanned != 0 || qr.maxResultBytes != 0
+	if qr.IsGuardrail() && !hasLimit {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "MaxRowsScanned or MaxResultBytes missing for KILL and DOWNGRADE_PRIORITY actions")
+	}
+	if !qr.IsGuardrail() && hasLimit {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "MaxRowsScanned and MaxResultBytes are only valid for KILL and DOWNGRADE_PRIORITY actions")
+	}
+	if (qr.act == QRDowngradePriority) != hasPriority {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Priority must be set if and only if the action is DOWNGRADE_PRIORITY")
+	}
 	return qr, nil
 }
 
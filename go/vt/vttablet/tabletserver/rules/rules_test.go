@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -586,6 +587,88 @@ func TestAction(t *testing.T) {
 	assert.Equalf(t, desc, "rule 5", "want rule 5, got %s", desc)
 }
 
+func TestOptimizerHints(t *testing.T) {
+	qrs := New()
+
+	qr1 := NewQueryRule("rule 1", "r1", QROptimizerHint)
+	require.NoError(t, qr1.SetOptimizerHint("INDEX(t idx_a)"))
+	qr1.SetUserCond("user1")
+
+	qr2 := NewQueryRule("rule 2", "r2", QROptimizerHint)
+	require.NoError(t, qr2.SetOptimizerHint(" MAX_EXECUTION_TIME(1000) "))
+
+	qr3 := NewQueryRule("rule 3", "r3", QRFail)
+	qr3.SetUserCond("user2")
+
+	qrs.Add(qr1)
+	qrs.Add(qr2)
+	qrs.Add(qr3)
+
+	mc := sqlparser.MarginComments{}
+
+	// Optimizer hint rules never decide the fate of the query.
+	action, _, _, _ := qrs.GetAction("123", "user1", nil, mc)
+	assert.Equal(t, QRContinue, action)
+	action, _, _, desc := qrs.GetAction("123", "user2", nil, mc)
+	assert.Equal(t, QRFail, action)
+	assert.Equal(t, "rule 3", desc)
+
+	assert.Equal(t, []string{"INDEX(t idx_a)", "MAX_EXECUTION_TIME(1000)"}, qrs.OptimizerHints("123", "user1", nil, mc))
+	assert.Equal(t, []string{"MAX_EXECUTION_TIME(1000)"}, qrs.OptimizerHints("123", "user2", nil, mc))
+
+	// The hint survives plan filtering and copying.
+	filtered := qrs.FilterByPlan("select * from t", planbuilder.PlanSelect, "t")
+	assert.True(t, filtered.Equal(qrs.Copy()))
+	assert.Equal(t, []string{"INDEX(t idx_a)", "MAX_EXECUTION_TIME(1000)"}, filtered.OptimizerHints("123", "user1", nil, mc))
+}
+
+func TestGuardrails(t *testing.T) {
+	qrs := New()
+
+	qr1 := NewQueryRule("rule 1", "r1", QRKill)
+	qr1.SetMaxRowsScanned(1000)
+	qr1.SetUserCond("user1")
+
+	qr2 := NewQueryRule("rule 2", "r2", QRDowngradePriority)
+	qr2.SetMaxResultBytes(100)
+	require.NoError(t, qr2.SetPriority(90))
+	qr2.SetDryRun(true)
+
+	qr3 := NewQueryRule("rule 3", "r3", QRFail)
+	qr3.SetUserCond("user2")
+	qr3.SetDryRun(true)
+
+	qrs.Add(qr1)
+	qrs.Add(qr2)
+	qrs.Add(qr3)
+
+	mc := sqlparser.MarginComments{}
+
+	// Neither guardrails nor dry-run rules decide the fate of the query upfront.
+	action, _, _, _ := qrs.GetAction("123", "user1", nil, mc)
+	assert.Equal(t, QRContinue, action)
+	action, _, _, _ = qrs.GetAction("123", "user2", nil, mc)
+	assert.Equal(t, QRContinue, action)
+
+	assert.Equal(t, []*Rule{qr1, qr2}, qrs.Guardrails("123", "user1", nil, mc))
+	assert.Equal(t, []*Rule{qr2}, qrs.Guardrails("123", "user2", nil, mc))
+	assert.Empty(t, qrs.DryRunMatches("123", "user1", nil, mc))
+	assert.Equal(t, []*Rule{qr3}, qrs.DryRunMatches("123", "user2", nil, mc))
+
+	assert.False(t, qr1.Exceeded(1000, 1<<30))
+	assert.True(t, qr1.Exceeded(1001, 0))
+	assert.False(t, qr1.Exceeded(-1, 0))
+	assert.False(t, qr2.Exceeded(1<<30, 100))
+	assert.True(t, qr2.Exceeded(-1, 101))
+	assert.Equal(t, 90, qr2.Priority())
+	assert.True(t, qr2.DryRun())
+
+	assert.EqualError(t, qr2.SetPriority(101), "priority must be between 0 and 100: 101")
+
+	filtered := qrs.FilterByPlan("select * from t", planbuilder.PlanSelect, "t")
+	assert.True(t, filtered.Equal(qrs.Copy()))
+}
+
 func TestImport(t *testing.T) {
 	var qrs = New()
 	jsondata := `[{
@@ -612,6 +695,24 @@ func TestImport(t *testing.T) {
 		"Description": "desc2",
 		"Name": "name2",
 		"Action": "FAIL"
+	},{
+		"Description": "desc3",
+		"Name": "name3",
+		"Action": "OPTIMIZER_HINT",
+		"OptimizerHint": "INDEX(a idx_a)"
+	},{
+		"Description": "desc4",
+		"Name": "name4",
+		"Action": "KILL",
+		"MaxRowsScanned": 100000,
+		"MaxResultBytes": 1048576,
+		"DryRun": true
+	},{
+		"Description": "desc5",
+		"Name": "name5",
+		"Action": "DOWNGRADE_PRIORITY",
+		"MaxRowsScanned": 1000,
+		"Priority": 90
 	}]`
 	err := qrs.UnmarshalJSON([]byte(jsondata))
 	if err != nil {
@@ -734,6 +835,15 @@ var invalidjsons = []InvalidJSONCase{
 	{`[{"BindVarConds": [{"Name": "a", "OnAbsent": true, "OnMismatch": true, "Operator": "NOMATCH", "Value": "["}]}]`, "processing [: error parsing regexp: missing closing ]: `[$`"},
 	{`[{"Action": 1 }]`, "want string for Action"},
 	{`[{"Action": "foo" }]`, "invalid Action foo"},
+	{`[{"Action": "OPTIMIZER_HINT" }]`, "OptimizerHint missing for OPTIMIZER_HINT action"},
+	{`[{"Action": "OPTIMIZER_HINT", "OptimizerHint": "INDEX(t a) */ select 1 /*" }]`, "optimizer hint must not contain comment markers: INDEX(t a) */ select 1 /*"},
+	{`[{"Action": "KILL" }]`, "MaxRowsScanned or MaxResultBytes missing for KILL and DOWNGRADE_PRIORITY actions"},
+	{`[{"Action": "FAIL", "MaxRowsScanned": 10 }]`, "MaxRowsScanned and MaxResultBytes are only valid for KILL and DOWNGRADE_PRIORITY actions"},
+	{`[{"Action": "KILL", "MaxRowsScanned": "10" }]`, "want number for MaxRowsScanned"},
+	{`[{"Action": "KILL", "MaxResultBytes": -1 }]`, "want non-negative integer for MaxResultBytes: -1"},
+	{`[{"Action": "DOWNGRADE_PRIORITY", "MaxRowsScanned": 10 }]`, "Priority must be set if and only if the action is DOWNGRADE_PRIORITY"},
+	{`[{"Action": "DOWNGRADE_PRIORITY", "MaxRowsScanned": 10, "Priority": 101 }]`, "priority must be between 0 and 100: 101"},
+	{`[{"Action": "KILL", "MaxRowsScanned": 10, "DryRun": "yes" }]`, "want bool for DryRun"},
 }
 
 func TestInvalidJSON(t *testing.T) {
@@ -1045,8 +1045,35 @@ func (se *Engine) GetSchema() map[string]*Table {
 	return tables
 }
 
+// GetTableDefinitionForPos returns the version of the schema at a specific gtid, which is the
+// id of the schema_version row tracking it, and the CREATE TABLE statement of the table in that
+// version. Both are only known when schema versions are tracked: otherwise it returns 0 and an
+// empty statement.
+func (se *Engine) GetTableDefinitionForPos(tableName sqlparser.IdentifierCS, gtid string) (int64, string, error) {
+	return se.historian.GetTableDefinitionForPos(tableName, gtid)
+}
+
+// GetTablesForPos returns the tables in the schema at a specific gtid, sorted by name, as
+// tracked by the historian. It fails if the schema is not tracked for the gtid, e.g. when
+// schema versions are not tracked or the gtid is older than the tracked schemas, rather
+// than returning a schema the gtid may not be at.
+func (se *Engine) GetTablesForPos(gtid string) ([]*binlogdatapb.MinimalTable, error) {
+	tables, err := se.historian.GetSchemaForPos(gtid)
+	if err != nil {
+		return nil, err
+	}
+	if tables == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the schema at position %s is not tracked: schema snapshots require --track_schema_versions and a position within the tracked schema history", gtid)
+	}
+	return tables, nil
+}
+
 // MarshalMinimalSchema returns a protobuf encoded binlogdata.MinimalSchema
 func (se *Engine) MarshalMinimalSchema() ([]byte, error) {
+	return se.minimalSchema().MarshalVT()
+}
+
+func (se *Engine) minimalSchema() *binlogdatapb.MinimalSchema {
 	se.mu.Lock()
 	defer se.mu.Unlock()
 	dbSchema := &binlogdatapb.MinimalSchema{
@@ -1055,7 +1082,7 @@ func (se *Engine) MarshalMinimalSchema() ([]byte, error) {
 	for _, table := range se.tables {
 		dbSchema.Tables = append(dbSchema.Tables, newMinimalTable(table))
 	}
-	return dbSchema.MarshalVT()
+	return dbSchema
 }
 
 func newMinimalTable(st *Table) *binlogdatapb.MinimalTable {
@@ -41,6 +41,8 @@ const vl = 10
 
 // trackedSchema has the snapshot of the table at a given pos (reached by ddl)
 type trackedSchema struct {
+	// id is the id of the schema_version row, which is used as the version of the schema
+	id          int64
 	schema      map[string]*binlogdatapb.MinimalTable
 	pos         replication.Position
 	ddl         string
@@ -159,6 +161,83 @@ func (h *historian) GetTableForPos(tableName sqlparser.IdentifierCS, gtid string
 	return t, nil
 }
 
+// GetTableDefinitionForPos returns the version of the schema at a specific gtid and the
+// CREATE TABLE statement of the table in that version. A gtid past the last tracked schema
+// is at the last tracked version. It returns 0 when the schema is not tracked for the gtid.
+func (h *historian) GetTableDefinitionForPos(tableName sqlparser.IdentifierCS, gtid string) (int64, string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isOpen || gtid == "" || len(h.schemas) == 0 {
+		return 0, "", nil
+	}
+	pos, err := replication.DecodePosition(gtid)
+	if err != nil {
+		return 0, "", err
+	}
+	ts := h.getSchemaFromHistoryForPos(pos)
+	if ts == nil {
+		if !pos.AtLeast(h.schemas[len(h.schemas)-1].pos) {
+			return 0, "", nil
+		}
+		ts = h.schemas[len(h.schemas)-1]
+	}
+	return ts.id, ts.schema[tableName.String()].GetCreateStatement(), nil
+}
+
+// GetSchemaForPos returns the tables of the schema tracked for a specific gtid. A gtid past
+// the last tracked schema is at the last tracked version. It returns nil when the schema is
+// not tracked for the gtid, e.g. when the gtid is older than the tracked schemas.
+func (h *historian) GetSchemaForPos(gtid string) ([]*binlogdatapb.MinimalTable, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isOpen || gtid == "" || len(h.schemas) == 0 {
+		return nil, nil
+	}
+	pos, err := replication.DecodePosition(gtid)
+	if err != nil {
+		return nil, err
+	}
+	ts := h.getSchemaFromHistoryForPos(pos)
+	if ts == nil {
+		if !pos.AtLeast(h.schemas[len(h.schemas)-1].pos) {
+			return nil, nil
+		}
+		ts = h.schemas[len(h.schemas)-1]
+	}
+	tables := make([]*binlogdatapb.MinimalTable, 0, len(ts.schema))
+	for _, t := range ts.schema {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+	return tables, nil
+}
+
+// latestCreateStatements returns the CREATE TABLE statements of the tables in the most
+// recently tracked schema. It first loads the rows it does not have as yet, so that the
+// tracker always builds on the schema it recorded last.
+func (h *historian) latestCreateStatements(ctx context.Context) (map[string]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isOpen {
+		return nil, nil
+	}
+	if err := h.loadFromDB(ctx); err != nil {
+		return nil, err
+	}
+	if len(h.schemas) == 0 {
+		return nil, nil
+	}
+	statements := make(map[string]string)
+	for name, t := range h.schemas[len(h.schemas)-1].schema {
+		if t.CreateStatement != "" {
+			statements[name] = t.CreateStatement
+		}
+	}
+	return statements, nil
+}
+
 // loadFromDB loads all rows from the schema_version table that the historian does not have as yet
 // caller should have locked h.mu
 func (h *historian) loadFromDB(ctx context.Context) error {
@@ -238,6 +317,7 @@ func (h *historian) readRow(row []sqltypes.Value) (*trackedSchema, int64, error)
 		tables[t.Name] = t
 	}
 	tSchema := &trackedSchema{
+		id:          id,
 		schema:      tables,
 		pos:         pos,
 		ddl:         ddl,
@@ -281,16 +361,25 @@ func (h *historian) sortSchemas() {
 
 // getTableFromHistoryForPos looks in the cache for a schema for a specific gtid
 func (h *historian) getTableFromHistoryForPos(tableName sqlparser.IdentifierCS, pos replication.Position) *binlogdatapb.MinimalTable {
+	ts := h.getSchemaFromHistoryForPos(pos)
+	if ts == nil {
+		log.Infof("Schema not found in cache for %s with pos %s", tableName, pos)
+		return nil
+	}
+	return ts.schema[tableName.String()]
+}
+
+// getSchemaFromHistoryForPos looks in the cache for the tracked schema for a specific gtid
+func (h *historian) getSchemaFromHistoryForPos(pos replication.Position) *trackedSchema {
 	idx := sort.Search(len(h.schemas), func(i int) bool {
 		return pos.Equal(h.schemas[i].pos) || !pos.AtLeast(h.schemas[i].pos)
 	})
 	if idx >= len(h.schemas) || idx == 0 && !pos.Equal(h.schemas[idx].pos) { // beyond the range of the cache
-		log.Infof("Schema not found in cache for %s with pos %s", tableName, pos)
 		return nil
 	}
 	if pos.Equal(h.schemas[idx].pos) { //exact match to a cache entry
-		return h.schemas[idx].schema[tableName.String()]
+		return h.schemas[idx]
 	}
 	//not an exact match, so based on our sort algo idx is one less than found: from 40,44,48 : 43 < 44 but we want 40
-	return h.schemas[idx-1].schema[tableName.String()]
+	return h.schemas[idx-1]
 }
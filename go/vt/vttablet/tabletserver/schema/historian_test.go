@@ -258,3 +258,72 @@ func TestHistorianPurgeOldSchemas(t *testing.T) {
 	require.Equal(t, exp2, fmt.Sprintf("%v", tab))
 	require.Equal(t, 1, len(se.historian.schemas))
 }
+
+func TestHistorianTableDefinitions(t *testing.T) {
+	se, db, cancel := getTestSchemaEngine(t, 0)
+	defer cancel()
+
+	gtidPrefix := "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:"
+	gtid1, gtid2 := gtidPrefix+"1-10", gtidPrefix+"1-20"
+	t1 := getTable("t1", []string{"id1"}, []querypb.Type{querypb.Type_INT32}, []int64{0})
+	t1.CreateStatement = "create table t1 (id1 int, primary key(id1))"
+	t2 := getTable("t2", []string{"id2"}, []querypb.Type{querypb.Type_INT32}, []int64{0})
+	t2.CreateStatement = "create table t2 (id2 int, primary key(id2))"
+	t1v2 := t1.CloneVT()
+	t1v2.CreateStatement = "create table t1 (id1 int, val int, primary key(id1))"
+	blob := func(tables ...*binlogdatapb.MinimalTable) sqltypes.Value {
+		b, err := (&binlogdatapb.MinimalSchema{Tables: tables}).MarshalVT()
+		require.NoError(t, err)
+		return sqltypes.NewVarBinary(string(b))
+	}
+	db.AddQuery("select id, pos, ddl, time_updated, schemax from _vt.schema_version where id > 0 order by id asc",
+		&sqltypes.Result{
+			Fields: sqltypes.MakeTestFields("id|pos|ddl|time_updated|schemax", "int32|varbinary|varbinary|int32|blob"),
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewInt32(4), sqltypes.NewVarBinary(gtid1), sqltypes.NewVarBinary("create table t2 (id2 int)"), sqltypes.NewInt32(1), blob(t1, t2)},
+				{sqltypes.NewInt32(7), sqltypes.NewVarBinary(gtid2), sqltypes.NewVarBinary("alter table t1 add column val int"), sqltypes.NewInt32(2), blob(t1v2, t2)},
+			},
+		})
+	require.NoError(t, se.EnableHistorian(true))
+
+	testcases := []struct {
+		gtid       string
+		table      string
+		version    int64
+		definition string
+	}{
+		{gtidPrefix + "1-5", "t1", 0, ""},
+		{gtid1, "t1", 4, t1.CreateStatement},
+		{gtidPrefix + "1-15", "t1", 4, t1.CreateStatement},
+		{gtidPrefix + "1-15", "t2", 4, t2.CreateStatement},
+		{gtid2, "t1", 7, t1v2.CreateStatement},
+		{gtidPrefix + "1-30", "t1", 7, t1v2.CreateStatement},
+		{gtidPrefix + "1-30", "t3", 7, ""},
+	}
+	for _, tc := range testcases {
+		version, definition, err := se.GetTableDefinitionForPos(sqlparser.NewIdentifierCS(tc.table), tc.gtid)
+		require.NoError(t, err)
+		require.Equal(t, tc.version, version, "%s at %s", tc.table, tc.gtid)
+		require.Equal(t, tc.definition, definition, "%s at %s", tc.table, tc.gtid)
+	}
+
+	tables, err := se.GetTablesForPos(gtidPrefix + "1-15")
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	require.Equal(t, t1.CreateStatement, tables[0].CreateStatement)
+	require.Equal(t, "t2", tables[1].Name)
+
+	// Past the last tracked schema, the tables are those of the last version.
+	tables, err = se.GetTablesForPos(gtidPrefix + "1-30")
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	require.Equal(t, t1v2.CreateStatement, tables[0].CreateStatement)
+
+	// Before the first tracked schema, the tables are not known.
+	_, err = se.GetTablesForPos(gtidPrefix + "1-5")
+	require.ErrorContains(t, err, "the schema at position "+gtidPrefix+"1-5 is not tracked")
+
+	statements, err := se.historian.latestCreateStatements(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"t1": t1v2.CreateStatement, "t2": t2.CreateStatement}, statements)
+}
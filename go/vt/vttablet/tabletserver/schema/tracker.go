@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
 
@@ -220,7 +221,8 @@ func (tr *Tracker) schemaUpdated(gtid string, ddl string, timestamp int64) error
 }
 
 func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string, timestamp int64) error {
-	blob, err := tr.engine.MarshalMinimalSchema()
+	dbSchema := tr.engine.minimalSchema()
+	previous, err := tr.engine.historian.latestCreateStatements(ctx)
 	if err != nil {
 		return err
 	}
@@ -231,6 +233,12 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 	}
 	defer conn.Recycle()
 
+	tr.addCreateStatements(ctx, conn.Conn, dbSchema, ddl, previous)
+	blob, err := dbSchema.MarshalVT()
+	if err != nil {
+		return err
+	}
+
 	// We serialize a blob here, encodeString is for strings only
 	// and should not be used for binary data.
 	blobVal := sqltypes.MakeTrusted(sqltypes.VarBinary, blob)
@@ -247,6 +255,58 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 	return nil
 }
 
+// addCreateStatements sets the CREATE TABLE statements of the tables in dbSchema. The
+// statements of the tables not changed by the ddl are carried forward from the previously
+// tracked schema, the others are read from the database.
+func (tr *Tracker) addCreateStatements(ctx context.Context, conn *connpool.Conn, dbSchema *binlogdatapb.MinimalSchema,
+	ddl string, previous map[string]string) {
+	dbname := tr.engine.cp.DBName()
+	changed, ok := ddlTableNames(ddl, dbname, tr.env.Environment().Parser())
+	for _, table := range dbSchema.Tables {
+		if table.Name == "dual" {
+			continue
+		}
+		if stmt, found := previous[table.Name]; found && ok && !changed[table.Name] {
+			table.CreateStatement = stmt
+			continue
+		}
+		stmt, err := getCreateStatement(ctx, conn, sqlparser.String(sqlparser.NewIdentifierCS(table.Name)))
+		if err != nil {
+			// The table may have been dropped since the schema was loaded. The version
+			// is still saved, without the definition of that table.
+			log.Warningf("Could not read the definition of table %s for the schema version: %v", table.Name, err)
+			continue
+		}
+		table.CreateStatement = stmt
+	}
+}
+
+// ddlTableNames returns the names of the tables of the database dbname that are changed by
+// the ddl. It returns false if the ddl could not be parsed, in which case any table may
+// have changed.
+func ddlTableNames(sql string, dbname string, parser *sqlparser.Parser) (map[string]bool, bool) {
+	if sql == "" {
+		return nil, false
+	}
+	ast, err := parser.Parse(sql)
+	if err != nil {
+		return nil, false
+	}
+	names := make(map[string]bool)
+	if stmt, ok := ast.(sqlparser.DDLStatement); ok {
+		tables := []sqlparser.TableName{stmt.GetTable()}
+		tables = append(tables, stmt.GetFromTables()...)
+		tables = append(tables, stmt.GetToTables()...)
+		for _, table := range tables {
+			if table.IsEmpty() || table.Qualifier.NotEmpty() && table.Qualifier.String() != dbname {
+				continue
+			}
+			names[table.Name.String()] = true
+		}
+	}
+	return names, true
+}
+
 func encodeString(in string) string {
 	return sqltypes.EncodeStringSQL(in)
 }
@@ -176,3 +176,56 @@ func TestMustReloadSchemaOnDDL(t *testing.T) {
 		})
 	}
 }
+
+func TestDDLTableNames(t *testing.T) {
+	testcases := []struct {
+		query string
+		want  map[string]bool
+		ok    bool
+	}{
+		{"", nil, false},
+		{"bad", nil, false},
+		{"create table x(i int)", map[string]bool{"x": true}, true},
+		{"alter table db1.x add column j int", map[string]bool{"x": true}, true},
+		{"rename table x to y", map[string]bool{"x": true, "y": true}, true},
+		{"drop table x, db2.y, z", map[string]bool{"x": true, "z": true}, true},
+		{"create table db2.x(i int)", map[string]bool{}, true},
+		{"create database db1", map[string]bool{}, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			names, ok := ddlTableNames(tc.query, "db1", sqlparser.NewTestParser())
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.want, names)
+		})
+	}
+}
+
+func TestTrackerAddCreateStatements(t *testing.T) {
+	ctx := context.Background()
+	se, db, cancel := getTestSchemaEngine(t, 0)
+	defer cancel()
+	showCreateFields := sqltypes.MakeTestFields("Table|Create Table", "varchar|varchar")
+	db.AddQuery("show create table t1", sqltypes.MakeTestResult(showCreateFields, "t1|create table t1 (id int, val int)"))
+	db.AddQuery("show create table t3", sqltypes.MakeTestResult(showCreateFields, "t3|create table t3 (id int)"))
+
+	tracker := NewTracker(se.env, nil, se)
+	conn, err := se.GetConnection(ctx)
+	require.NoError(t, err)
+	defer conn.Recycle()
+
+	dbSchema := &binlogdatapb.MinimalSchema{
+		Tables: []*binlogdatapb.MinimalTable{{Name: "t1"}, {Name: "t2"}, {Name: "t3"}, {Name: "t4"}},
+	}
+	previous := map[string]string{
+		"t1": "create table t1 (id int)",
+		"t2": "create table t2 (id int)",
+	}
+	tracker.addCreateStatements(ctx, conn.Conn, dbSchema, "alter table t1 add column val int", previous)
+	// t1 was changed by the DDL, t2 was not, t3 was not tracked as yet and t4 has been
+	// dropped since the schema was loaded.
+	require.Equal(t, "create table t1 (id int, val int)", dbSchema.Tables[0].CreateStatement)
+	require.Equal(t, "create table t2 (id int)", dbSchema.Tables[1].CreateStatement)
+	require.Equal(t, "create table t3 (id int)", dbSchema.Tables[2].CreateStatement)
+	require.Empty(t, dbSchema.Tables[3].CreateStatement)
+}
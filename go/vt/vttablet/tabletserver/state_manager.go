@@ -96,6 +96,7 @@ type stateManager struct {
 	retrying             bool
 	replHealthy          bool
 	demotePrimaryStalled bool
+	draining             bool
 	lameduck             bool
 	diskHealthMonitor    DiskHealthMonitor
 	alsoAllow            []topodatapb.TabletType
@@ -674,6 +675,8 @@ func (sm *stateManager) setState(tabletType topodatapb.TabletType, state serving
 		_, _ = sm.refreshReplHealthLocked()
 	}
 	sm.state = state
+	// Any transition ends draining, see TxEngine.Drain.
+	sm.draining = false
 	// Broadcast also obtains a lock. Trigger in a goroutine to avoid a deadlock.
 	go sm.hcticks.Trigger()
 }
@@ -721,7 +724,7 @@ func (sm *stateManager) Broadcast() {
 		// If we are stalled while demoting primary, we should send an error for it.
 		err = vterrors.VT09031()
 	}
-	sm.hs.ChangeState(sm.target.TabletType, sm.ptsTimestamp, lag, err, sm.isServingLocked())
+	sm.hs.ChangeState(sm.target.TabletType, sm.ptsTimestamp, lag, err, sm.isServingLocked(), sm.draining)
 }
 
 func (sm *stateManager) refreshReplHealthLocked() (time.Duration, error) {
@@ -40,13 +40,19 @@ import (
 // This is used for transactions and reserved connections.
 // NOTE: After use, if must be returned either by doing a Unlock() or a Release().
 type StatefulConnection struct {
-	pool           *StatefulConnectionPool
-	dbConn         *connpool.PooledConn
-	ConnID         tx.ConnID
-	env            tabletenv.Env
-	txProps        *tx.Properties
-	reservedProps  *Properties
-	tainted        bool
+	pool          *StatefulConnectionPool
+	dbConn        *connpool.PooledConn
+	ConnID        tx.ConnID
+	env           tabletenv.Env
+	txProps       *tx.Properties
+	reservedProps *Properties
+	tainted       bool
+	// reservedSettings are the queries which were run to reserve the connection.
+	reservedSettings []string
+	// sessionState is true once a statement which may leave session state
+	// behind, like temporary tables, user variables or locks, ran on the
+	// connection.
+	sessionState   bool
 	enforceTimeout bool
 	timeout        time.Duration
 	expiryTime     time.Time
@@ -221,6 +221,17 @@ func (sf *StatefulConnectionPool) ForAllTxProperties(f func(*tx.Properties)) {
 	}
 }
 
+// openTransactions returns the connections that have an open transaction.
+func (sf *StatefulConnectionPool) openTransactions() []*StatefulConnection {
+	var conns []*StatefulConnection
+	for _, connection := range mapToTxConn(sf.active.GetAll()) {
+		if connection.IsInTransaction() {
+			conns = append(conns, connection)
+		}
+	}
+	return conns
+}
+
 // Unregister forgets the specified connection.  If the connection is not present, it's ignored.
 func (sf *StatefulConnectionPool) unregister(id tx.ConnID, reason string) {
 	sf.active.Unregister(id, reason)
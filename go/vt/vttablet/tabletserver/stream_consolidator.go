@@ -39,6 +39,9 @@ type StreamConsolidator struct {
 	maxMemoryTotal, maxMemoryQuery int64
 	blocking                       bool
 	cleanup                        StreamCallback
+	// laggedFollowers counts the followers which were dropped because their
+	// clients received results too slowly to keep up with the stream.
+	laggedFollowers atomic.Int64
 }
 
 // NewStreamConsolidator allocates a stream consolidator. The consolidator will use up to maxMemoryTotal
@@ -57,6 +60,12 @@ func NewStreamConsolidator(maxMemoryTotal, maxMemoryQuery int64, cleanup StreamC
 // StreamCallback is a function that is called with every Result object from a streaming query
 type StreamCallback func(result *sqltypes.Result) error
 
+// LaggedFollowers returns the number of followers which were dropped
+// because their clients received results too slowly.
+func (sc *StreamConsolidator) LaggedFollowers() int64 {
+	return sc.laggedFollowers.Load()
+}
+
 // SetBlocking sets whether fanning out should block to wait for slower clients to
 // catch up, or should immediately disconnect clients that are taking too long to process the
 // consolidated stream. By default, blocking is only enabled when running with the race detector.
@@ -156,8 +165,9 @@ func (sc *StreamConsolidator) Consolidate(waitTimings *servenv.TimingsWrapper, l
 		// update the live consolidated stream; this will fan out the Result to all our active followers
 		// and tell us how much more memory we're using by temporarily storing the result so other followers
 		// in the future can catch up to this stream
-		memChange := inflight.update(result, sc.blocking, sc.maxMemoryQuery, sc.maxMemoryTotal-atomic.LoadInt64(&sc.memory))
+		memChange, lagged := inflight.update(result, sc.blocking, sc.maxMemoryQuery, sc.maxMemoryTotal-atomic.LoadInt64(&sc.memory))
 		atomic.AddInt64(&sc.memory, memChange)
+		sc.laggedFollowers.Add(lagged)
 
 		// yield the result to the very first client that started the query; this client is not listening
 		// on a follower channel.
@@ -248,9 +258,10 @@ func (s *streamInFlight) shouldContinueStreaming() bool {
 }
 
 // update fans out the given result to all the active followers for the stream and
-// returns the amount of memory that is being used by the catchup buffer
-func (s *streamInFlight) update(result *sqltypes.Result, block bool, maxMemoryQuery, maxMemoryTotal int64) int64 {
-	var memoryChange int64
+// returns the amount of memory that is being used by the catchup buffer, as well as
+// the number of followers which were dropped because they lagged behind
+func (s *streamInFlight) update(result *sqltypes.Result, block bool, maxMemoryQuery, maxMemoryTotal int64) (int64, int64) {
+	var memoryChange, lagged int64
 	resultSize := result.CachedSize(true)
 
 	s.mu.Lock()
@@ -287,12 +298,13 @@ func (s *streamInFlight) update(result *sqltypes.Result, block bool, maxMemoryQu
 					// client will receive an error.
 					s.fanout[follower] = false
 					close(follower)
+					lagged++
 				}
 			}
 		}
 	}
 
-	return memoryChange
+	return memoryChange, lagged
 }
 
 // finishLeader terminates this consolidated stream by storing the final error result from
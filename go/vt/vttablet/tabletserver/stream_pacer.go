@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// streamPacer keeps track of how fast the clients of streaming queries
+// receive their results. A slow client pins a MySQL connection and, within
+// a transaction, an open snapshot which prevents InnoDB from purging its
+// history. Therefore, the streamPacer terminates streams which run for
+// longer than their max duration or whose client takes longer than the idle
+// timeout to receive a result. Both limits can be set per workload name.
+type streamPacer struct {
+	env tabletenv.Env
+
+	// consumerWaits records per workload how long streams waited for their
+	// client to receive a result.
+	consumerWaits *servenv.TimingsWrapper
+	// bytesSent counts per workload the bytes received by clients.
+	bytesSent *stats.CountersWithSingleLabel
+	// terminated counts per workload and reason the terminated streams.
+	terminated *stats.CountersWithMultiLabels
+
+	mu      sync.Mutex
+	streams map[*streamProgress]struct{}
+}
+
+// streamProgress is the progress of a single streaming query.
+type streamProgress struct {
+	workload string
+	start    time.Time
+	rows     atomic.Int64
+	bytes    atomic.Int64
+	// waitingSince is the time in unix nanoseconds since when the stream has
+	// been waiting for its client to receive a result. It is zero if the
+	// stream does not wait for its client.
+	waitingSince atomic.Int64
+	// err is set when the stream was terminated.
+	err atomic.Pointer[error]
+}
+
+func newStreamPacer(env tabletenv.Env) *streamPacer {
+	sp := &streamPacer{
+		env: env,
+		consumerWaits: env.Exporter().NewTimings(
+			"StreamConsumerWaits",
+			"Time streaming queries waited for their client to receive a result",
+			"Workload"),
+		bytesSent: env.Exporter().NewCountersWithSingleLabel(
+			"StreamBytesSent",
+			"Bytes received by the clients of streaming queries",
+			"Workload"),
+		terminated: env.Exporter().NewCountersWithMultiLabels(
+			"StreamsTerminated",
+			"Streaming queries terminated because they exceeded their max duration or idle timeout",
+			[]string{"Workload", "Reason"}),
+		streams: make(map[*streamProgress]struct{}),
+	}
+	env.Exporter().NewGaugesFuncWithMultiLabels(
+		"StreamConsumerLagSeconds",
+		"Longest time an active streaming query has been waiting for its client to receive a result",
+		[]string{"Workload"},
+		sp.maxLagSeconds)
+	return sp
+}
+
+// pace wraps the callback of a streaming query of the given workload name.
+// The returned context is canceled when the stream gets terminated. Because
+// a client may be stuck receiving a result, the stream must watch the
+// context to kill its MySQL query. The returned callback fails once the
+// stream is terminated. finish must be called when the stream is done and
+// returns the error which terminated the stream, or err otherwise.
+func (sp *streamPacer) pace(ctx context.Context, workload string, callback StreamCallback) (context.Context, *streamProgress, StreamCallback, func(err error) error) {
+	progress := &streamProgress{workload: workload, start: time.Now()}
+	ctx, cancel := context.WithCancel(ctx)
+	terminate := func(reason string, err error) {
+		if progress.err.CompareAndSwap(nil, &err) {
+			sp.terminated.Add([]string{workload, reason}, 1)
+			cancel()
+		}
+	}
+
+	maxDuration, idleTimeout := sp.env.Config().StreamPacing.Limits(workload)
+	var maxDurationTimer, idleTimer *time.Timer
+	if maxDuration > 0 {
+		maxDurationTimer = time.AfterFunc(maxDuration, func() {
+			terminate("MaxDuration", vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "stream exceeded its max duration of %v", maxDuration))
+		})
+	}
+	if idleTimeout > 0 {
+		idleTimer = time.AfterFunc(idleTimeout, func() {
+			terminate("IdleTimeout", vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "stream client did not receive a result within the idle timeout of %v", idleTimeout))
+		})
+		idleTimer.Stop()
+	}
+
+	sp.mu.Lock()
+	sp.streams[progress] = struct{}{}
+	sp.mu.Unlock()
+
+	paced := func(result *sqltypes.Result) error {
+		if err := progress.terminated(); err != nil {
+			return err
+		}
+		size := result.CachedSize(true)
+		start := time.Now()
+		progress.waitingSince.Store(start.UnixNano())
+		if idleTimer != nil {
+			idleTimer.Reset(idleTimeout)
+		}
+		err := callback(result)
+		if idleTimer != nil {
+			idleTimer.Stop()
+		}
+		progress.waitingSince.Store(0)
+		sp.consumerWaits.Record(workload, start)
+		if err != nil {
+			return err
+		}
+		progress.rows.Add(int64(len(result.Rows)))
+		progress.bytes.Add(size)
+		sp.bytesSent.Add(workload, size)
+		return progress.terminated()
+	}
+
+	finish := func(err error) error {
+		if maxDurationTimer != nil {
+			maxDurationTimer.Stop()
+		}
+		if idleTimer != nil {
+			idleTimer.Stop()
+		}
+		sp.mu.Lock()
+		delete(sp.streams, progress)
+		sp.mu.Unlock()
+		cancel()
+
+		if terr := progress.terminated(); terr != nil {
+			return terr
+		}
+		return err
+	}
+	return ctx, progress, paced, finish
+}
+
+// maxLagSeconds returns per workload the longest lag of the active streams.
+func (sp *streamPacer) maxLagSeconds() map[string]int64 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	lags := make(map[string]int64)
+	for progress := range sp.streams {
+		lag := int64(progress.lag().Seconds())
+		if lag >= lags[progress.workload] {
+			lags[progress.workload] = lag
+		}
+	}
+	return lags
+}
+
+// terminated returns the error which terminated the stream, if any.
+func (p *streamProgress) terminated() error {
+	if err := p.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// lag returns how long the stream has been waiting for its client to
+// receive the current result.
+func (p *streamProgress) lag() time.Duration {
+	since := p.waitingSince.Load()
+	if since == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, since))
+}
+
+// bytesPerSecond returns the throughput of the client of the stream.
+func (p *streamProgress) bytesPerSecond() int64 {
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(p.bytes.Load()) / elapsed)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestStreamPacer(pacing tabletenv.StreamPacingConfig) *streamPacer {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.StreamPacing = pacing
+	sp := newStreamPacer(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "StreamPacerTest"))
+	sp.terminated.ResetAll()
+	sp.bytesSent.ResetAll()
+	return sp
+}
+
+func TestStreamPacerProgress(t *testing.T) {
+	sp := newTestStreamPacer(tabletenv.StreamPacingConfig{})
+
+	result := &sqltypes.Result{Rows: [][]sqltypes.Value{{sqltypes.NewInt64(1)}, {sqltypes.NewInt64(2)}}}
+	inCallback := make(chan struct{})
+	release := make(chan struct{})
+	ctx, progress, callback, finish := sp.pace(context.Background(), "olap", func(*sqltypes.Result) error {
+		close(inCallback)
+		<-release
+		return nil
+	})
+
+	errs := make(chan error)
+	go func() {
+		errs <- callback(result)
+	}()
+	<-inCallback
+	time.Sleep(10 * time.Millisecond)
+	assert.GreaterOrEqual(t, progress.lag(), 10*time.Millisecond)
+	assert.Contains(t, sp.maxLagSeconds(), "olap")
+
+	close(release)
+	require.NoError(t, <-errs)
+	assert.Zero(t, progress.lag())
+	assert.EqualValues(t, 2, progress.rows.Load())
+	assert.Equal(t, result.CachedSize(true), progress.bytes.Load())
+	assert.Equal(t, result.CachedSize(true), sp.bytesSent.Counts()["olap"])
+	assert.Positive(t, progress.bytesPerSecond())
+
+	require.NoError(t, finish(nil))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Empty(t, sp.maxLagSeconds())
+}
+
+func TestStreamPacerMaxDuration(t *testing.T) {
+	sp := newTestStreamPacer(tabletenv.StreamPacingConfig{
+		MaxDuration:            time.Hour,
+		MaxDurationPerWorkload: tabletenv.WorkloadDurations{"olap": 10 * time.Millisecond},
+	})
+
+	ctx, _, callback, finish := sp.pace(context.Background(), "olap", func(*sqltypes.Result) error {
+		return nil
+	})
+	<-ctx.Done()
+
+	// The stream fails even if it did not notice the canceled context.
+	err := callback(&sqltypes.Result{})
+	require.EqualError(t, err, "stream exceeded its max duration of 10ms")
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	require.EqualError(t, finish(errors.New("query killed")), "stream exceeded its max duration of 10ms")
+	assert.EqualValues(t, 1, sp.terminated.Counts()["olap.MaxDuration"])
+}
+
+func TestStreamPacerIdleTimeout(t *testing.T) {
+	sp := newTestStreamPacer(tabletenv.StreamPacingConfig{IdleTimeout: 10 * time.Millisecond})
+
+	// A slow database does not make the stream idle.
+	ctx, _, callback, finish := sp.pace(context.Background(), "olap", func(*sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, callback(&sqltypes.Result{}))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, callback(&sqltypes.Result{}))
+	require.NoError(t, ctx.Err())
+	require.NoError(t, finish(nil))
+
+	// A slow client does.
+	ctx, _, callback, finish = sp.pace(context.Background(), "olap", func(*sqltypes.Result) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	err := callback(&sqltypes.Result{})
+	require.EqualError(t, err, "stream client did not receive a result within the idle timeout of 10ms")
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.Equal(t, err, finish(nil))
+	assert.EqualValues(t, 1, sp.terminated.Counts()["olap.IdleTimeout"])
+}
@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -112,6 +114,44 @@ func (t *TxThrottlerConfigFlag) Set(arg string) error {
 
 func (t *TxThrottlerConfigFlag) Type() string { return "string" }
 
+// WorkloadDurations maps workload names to durations. As a flag, it accepts
+// a comma-separated list of workload:duration pairs.
+type WorkloadDurations map[string]time.Duration
+
+// Set is part of the pflag.Value interface.
+func (wd *WorkloadDurations) Set(arg string) error {
+	durations := make(WorkloadDurations)
+	for _, pair := range strings.Split(arg, ",") {
+		if pair == "" {
+			continue
+		}
+		workload, value, ok := strings.Cut(pair, ":")
+		if !ok || workload == "" {
+			return fmt.Errorf("invalid workload:duration pair: %q", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration for workload %q: %w", workload, err)
+		}
+		durations[workload] = d
+	}
+	*wd = durations
+	return nil
+}
+
+// String is part of the pflag.Value interface.
+func (wd WorkloadDurations) String() string {
+	pairs := make([]string, 0, len(wd))
+	for workload, d := range wd {
+		pairs = append(pairs, workload+":"+d.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (wd WorkloadDurations) Type() string { return "WorkloadDurations" }
+
 // RegisterTabletEnvFlags is a public API to register tabletenv flags for use by test cases that expect
 // some flags to be set with default values
 func RegisterTabletEnvFlags(fs *pflag.FlagSet) {
@@ -180,6 +220,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&currentConfig.HotRowProtection.MaxQueueSize, "hot_row_protection_max_queue_size", defaultConfig.HotRowProtection.MaxQueueSize, "Maximum number of BeginExecute RPCs which will be queued for the same row (range).")
 	fs.IntVar(&currentConfig.HotRowProtection.MaxGlobalQueueSize, "hot_row_protection_max_global_queue_size", defaultConfig.HotRowProtection.MaxGlobalQueueSize, "Global queue limit across all row (ranges). Useful to prevent that the queue can grow unbounded.")
 	fs.IntVar(&currentConfig.HotRowProtection.MaxConcurrency, "hot_row_protection_concurrent_transactions", defaultConfig.HotRowProtection.MaxConcurrency, "Number of concurrent transactions let through to the txpool/MySQL for the same hot row. Should be > 1 to have enough 'ready' transactions in MySQL and benefit from a pipelining effect.")
+	fs.BoolVar(&currentConfig.HotRowProtection.CoalesceUpserts, "hot-row-protection-coalesce-upserts", defaultConfig.HotRowProtection.CoalesceUpserts, "If true and hot row protection is enabled, concurrent autocommit upserts which only increment counters of the same row are merged into one statement.")
 
 	fs.BoolVar(&currentConfig.EnableTransactionLimit, "enable_transaction_limit", defaultConfig.EnableTransactionLimit, "If true, limit on number of transactions open at the same time will be enforced for all users. User trying to open a new transaction after exhausting their limit will receive an error immediately, regardless of whether there are available slots or not.")
 	fs.BoolVar(&currentConfig.EnableTransactionLimitDryRun, "enable_transaction_limit_dry_run", defaultConfig.EnableTransactionLimitDryRun, "If true, limit on number of transactions open at the same time will be tracked for all users, but not enforced.")
@@ -200,6 +241,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&currentConfig.ConsolidatorStreamTotalSize, "consolidator-stream-total-size", defaultConfig.ConsolidatorStreamTotalSize, "Configure the stream consolidator total size in bytes. Setting to 0 disables the stream consolidator.")
 
 	fs.Int64Var(&currentConfig.ConsolidatorQueryWaiterCap, "consolidator-query-waiter-cap", 0, "Configure the maximum number of clients allowed to wait on the consolidator.")
+	fs.BoolVar(&currentConfig.ConsolidatorStatefulReads, "consolidator-stateful-reads", defaultConfig.ConsolidatorStatefulReads, "Extend the query consolidator to SELECTs in autocommit and read-only READ COMMITTED transactions, and on reserved connections which only hold system settings. Results are only shared between connections with exactly the same settings.")
 	fs.DurationVar(&healthCheckInterval, "health_check_interval", defaultConfig.Healthcheck.Interval, "Interval between health checks")
 	fs.DurationVar(&degradedThreshold, "degraded_threshold", defaultConfig.Healthcheck.DegradedThreshold, "replication lag after which a replica is considered degraded")
 	fs.DurationVar(&unhealthyThreshold, "unhealthy_threshold", defaultConfig.Healthcheck.UnhealthyThreshold, "replication lag after which a replica is considered unhealthy")
@@ -218,6 +260,17 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.EnablePerWorkloadTableMetrics, "enable-per-workload-table-metrics", defaultConfig.EnablePerWorkloadTableMetrics, "If true, query counts and query error metrics include a label that identifies the workload")
 	fs.BoolVar(&currentConfig.SkipUserMetrics, "skip-user-metrics", defaultConfig.SkipUserMetrics, "If true, user based stats are not recorded.")
 
+	fs.BoolVar(&currentConfig.PlanRegression.Enable, "queryserver-plan-regression-detection", defaultConfig.PlanRegression.Enable, "If true, vttablet periodically samples the MySQL execution plan of SELECT queries and reports plan changes that come with a latency regression.")
+	fs.DurationVar(&currentConfig.PlanRegression.CheckInterval, "queryserver-plan-regression-check-interval", defaultConfig.PlanRegression.CheckInterval, "How often the MySQL execution plan of a given query is sampled when plan regression detection is enabled.")
+	fs.Float64Var(&currentConfig.PlanRegression.LatencyRatio, "queryserver-plan-regression-latency-ratio", defaultConfig.PlanRegression.LatencyRatio, "A MySQL execution plan change is reported as a regression when the mean latency under the new plan exceeds the mean latency under the previous plan by this factor.")
+	fs.IntVar(&currentConfig.PlanRegression.MinSamples, "queryserver-plan-regression-min-samples", defaultConfig.PlanRegression.MinSamples, "Minimum number of executions under both the previous and the new MySQL execution plan before their latencies are compared.")
+	fs.IntVar(&currentConfig.PlanRegression.MaxQueries, "queryserver-plan-regression-max-queries", defaultConfig.PlanRegression.MaxQueries, "Maximum number of distinct queries whose MySQL execution plans are tracked.")
+
+	fs.DurationVar(&currentConfig.StreamPacing.MaxDuration, "queryserver-stream-max-duration", defaultConfig.StreamPacing.MaxDuration, "Maximum duration of a streaming query. Streams running for longer are terminated and their MySQL query is killed. 0 means unlimited.")
+	fs.DurationVar(&currentConfig.StreamPacing.IdleTimeout, "queryserver-stream-idle-timeout", defaultConfig.StreamPacing.IdleTimeout, "Maximum time a streaming query waits for its client to receive a result. Streams of slower clients are terminated and their MySQL query is killed. 0 means unlimited.")
+	fs.Var(&currentConfig.StreamPacing.MaxDurationPerWorkload, "queryserver-stream-max-duration-per-workload", "Comma-separated list of workload:duration pairs which override --queryserver-stream-max-duration for streaming queries of the given workload names.")
+	fs.Var(&currentConfig.StreamPacing.IdleTimeoutPerWorkload, "queryserver-stream-idle-timeout-per-workload", "Comma-separated list of workload:duration pairs which override --queryserver-stream-idle-timeout for streaming queries of the given workload names.")
+
 	fs.BoolVar(&currentConfig.Unmanaged, "unmanaged", false, "Indicates an unmanaged tablet, i.e. using an external mysql-compatible database")
 }
 
@@ -330,6 +383,7 @@ type TabletConfig struct {
 	ConsolidatorStreamTotalSize int64         `json:"consolidatorStreamTotalSize,omitempty"`
 	ConsolidatorStreamQuerySize int64         `json:"consolidatorStreamQuerySize,omitempty"`
 	ConsolidatorQueryWaiterCap  int64         `json:"consolidatorMaxQueryWait,omitempty"`
+	ConsolidatorStatefulReads   bool          `json:"consolidatorStatefulReads,omitempty"`
 	QueryCacheMemory            int64         `json:"queryCacheMemory,omitempty"`
 	QueryCacheDoorkeeper        bool          `json:"queryCacheDoorkeeper,omitempty"`
 	SchemaReloadInterval        time.Duration `json:"schemaReloadIntervalSeconds,omitempty"`
@@ -372,6 +426,10 @@ type TabletConfig struct {
 
 	EnablePerWorkloadTableMetrics bool `json:"-"`
 	SkipUserMetrics               bool `json:"-"`
+
+	PlanRegression PlanRegressionConfig `json:"-"`
+
+	StreamPacing StreamPacingConfig `json:"-"`
 }
 
 func (cfg *TabletConfig) MarshalJSON() ([]byte, error) {
@@ -616,6 +674,41 @@ type HotRowProtectionConfig struct {
 	MaxQueueSize       int    `json:"maxQueueSize,omitempty"`
 	MaxGlobalQueueSize int    `json:"maxGlobalQueueSize,omitempty"`
 	MaxConcurrency     int    `json:"maxConcurrency,omitempty"`
+	// CoalesceUpserts merges concurrent autocommit upserts which only
+	// increment counters of the same row into one statement.
+	CoalesceUpserts bool `json:"coalesceUpserts,omitempty"`
+}
+
+// PlanRegressionConfig contains the config for the detection of MySQL
+// execution plan regressions.
+type PlanRegressionConfig struct {
+	Enable        bool
+	CheckInterval time.Duration
+	LatencyRatio  float64
+	MinSamples    int
+	MaxQueries    int
+}
+
+// StreamPacingConfig contains the limits for streaming queries whose
+// clients receive results slowly. A zero duration means unlimited.
+type StreamPacingConfig struct {
+	MaxDuration            time.Duration
+	IdleTimeout            time.Duration
+	MaxDurationPerWorkload WorkloadDurations
+	IdleTimeoutPerWorkload WorkloadDurations
+}
+
+// Limits returns the max duration and idle timeout of streaming queries of
+// the given workload name.
+func (c *StreamPacingConfig) Limits(workload string) (maxDuration, idleTimeout time.Duration) {
+	maxDuration, idleTimeout = c.MaxDuration, c.IdleTimeout
+	if d, ok := c.MaxDurationPerWorkload[workload]; ok {
+		maxDuration = d
+	}
+	if d, ok := c.IdleTimeoutPerWorkload[workload]; ok {
+		idleTimeout = d
+	}
+	return maxDuration, idleTimeout
 }
 
 // SemiSyncMonitorConfig contains the config for the semi-sync monitor.
@@ -913,6 +1006,53 @@ func (c *TabletConfig) Verify() error {
 	if v := c.HotRowProtection.MaxConcurrency; v <= 0 {
 		return fmt.Errorf("--hot_row_protection_concurrent_transactions must be > 0 (specified value: %v)", v)
 	}
+	if err := c.verifyPlanRegressionConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyStreamPacingConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// verifyStreamPacingConfig checks the limits of streaming queries for sanity.
+func (c *TabletConfig) verifyStreamPacingConfig() error {
+	if v := c.StreamPacing.MaxDuration; v < 0 {
+		return fmt.Errorf("--queryserver-stream-max-duration must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.StreamPacing.IdleTimeout; v < 0 {
+		return fmt.Errorf("--queryserver-stream-idle-timeout must be >= 0 (specified value: %v)", v)
+	}
+	for workload, v := range c.StreamPacing.MaxDurationPerWorkload {
+		if v < 0 {
+			return fmt.Errorf("--queryserver-stream-max-duration-per-workload must be >= 0 (specified value for %v: %v)", workload, v)
+		}
+	}
+	for workload, v := range c.StreamPacing.IdleTimeoutPerWorkload {
+		if v < 0 {
+			return fmt.Errorf("--queryserver-stream-idle-timeout-per-workload must be >= 0 (specified value for %v: %v)", workload, v)
+		}
+	}
+	return nil
+}
+
+// verifyPlanRegressionConfig checks the plan regression detection config for sanity.
+func (c *TabletConfig) verifyPlanRegressionConfig() error {
+	if !c.PlanRegression.Enable {
+		return nil
+	}
+	if v := c.PlanRegression.CheckInterval; v <= 0 {
+		return fmt.Errorf("--queryserver-plan-regression-check-interval must be > 0 (specified value: %v)", v)
+	}
+	if v := c.PlanRegression.LatencyRatio; v < 1 {
+		return fmt.Errorf("--queryserver-plan-regression-latency-ratio must be >= 1 (specified value: %v)", v)
+	}
+	if v := c.PlanRegression.MinSamples; v <= 0 {
+		return fmt.Errorf("--queryserver-plan-regression-min-samples must be > 0 (specified value: %v)", v)
+	}
+	if v := c.PlanRegression.MaxQueries; v <= 0 {
+		return fmt.Errorf("--queryserver-plan-regression-max-queries must be > 0 (specified value: %v)", v)
+	}
 	return nil
 }
 
@@ -1118,6 +1258,14 @@ var defaultConfig = TabletConfig{
 	EnablePerWorkloadTableMetrics: false,
 
 	TwoPCAbandonAge: 15 * time.Minute,
+
+	PlanRegression: PlanRegressionConfig{
+		Enable:        false,
+		CheckInterval: time.Minute,
+		LatencyRatio:  2,
+		MinSamples:    20,
+		MaxQueries:    10000,
+	},
 }
 
 // defaultTxThrottlerConfig returns the default TxThrottlerConfigFlag object based on
@@ -503,3 +503,25 @@ func TestVerifyUnmanagedTabletConfig(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "testPassword", config.DB.App.Password)
 }
+
+func TestWorkloadDurations(t *testing.T) {
+	var wd WorkloadDurations
+	require.NoError(t, wd.Set("olap:1h,batch:30s"))
+	assert.Equal(t, WorkloadDurations{"olap": time.Hour, "batch": 30 * time.Second}, wd)
+	assert.Equal(t, "batch:30s,olap:1h0m0s", wd.String())
+
+	require.Error(t, wd.Set("olap"))
+	require.Error(t, wd.Set("olap:1 hour"))
+
+	pacing := StreamPacingConfig{
+		MaxDuration:            time.Minute,
+		IdleTimeout:            time.Second,
+		IdleTimeoutPerWorkload: WorkloadDurations{"olap": 0},
+	}
+	maxDuration, idleTimeout := pacing.Limits("olap")
+	assert.Equal(t, time.Minute, maxDuration)
+	assert.Zero(t, idleTimeout)
+	maxDuration, idleTimeout = pacing.Limits("oltp")
+	assert.Equal(t, time.Minute, maxDuration)
+	assert.Equal(t, time.Second, idleTimeout)
+}
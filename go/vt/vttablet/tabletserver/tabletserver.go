@@ -352,6 +352,9 @@ func (tsv *TabletServer) UnRegisterQueryRuleSource(ruleSource string) {
 
 // SetQueryRules sets the query rules for a registered ruleSource.
 func (tsv *TabletServer) SetQueryRules(ruleSource string, qrs *rules.Rules) error {
+	if err := tsv.validateQueryRules(qrs); err != nil {
+		return err
+	}
 	err := tsv.qe.queryRuleSources.SetRules(ruleSource, qrs)
 	if err != nil {
 		return err
@@ -360,6 +363,21 @@ func (tsv *TabletServer) SetQueryRules(ruleSource string, qrs *rules.Rules) erro
 	return nil
 }
 
+// validateQueryRules returns an error if qrs has rules which can't be
+// enforced by this tablet server.
+func (tsv *TabletServer) validateQueryRules(qrs *rules.Rules) error {
+	if tsv.config.EnableTxThrottler {
+		return nil
+	}
+	for _, qr := range qrs.CopyUnderlying() {
+		// A downgraded priority is only enforced by the transaction throttler.
+		if qr.Action() == rules.QRDowngradePriority && !qr.DryRun() {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "query rule %s: DOWNGRADE_PRIORITY requires the transaction throttler, see --enable-tx-throttler", qr.Name)
+		}
+	}
+	return nil
+}
+
 func (tsv *TabletServer) initACL(tableACLConfigFile string) error {
 	// tabletacl.Init loads ACL from file if *tableACLConfig is not empty
 	return tableacl.Init(
@@ -769,6 +787,35 @@ func (tsv *TabletServer) SetDemotePrimaryStalled(val bool) {
 	tsv.BroadcastHealth()
 }
 
+// DrainTransactions stops the tablet from opening new transactions and waits,
+// up to the given timeout, for the open ones to finish. The tablet keeps
+// draining until StopDraining is called or the query service changes state.
+func (tsv *TabletServer) DrainTransactions(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	if tsv.sm.Target().TabletType != topodatapb.TabletType_PRIMARY {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot drain transactions on a %v tablet", tsv.sm.Target().TabletType)
+	}
+	tsv.setDraining(true)
+	txs, err := tsv.te.Drain(ctx, timeout)
+	if err != nil {
+		tsv.StopDraining()
+		return nil, err
+	}
+	return txs, nil
+}
+
+// StopDraining lets the tablet open new transactions again after DrainTransactions.
+func (tsv *TabletServer) StopDraining() {
+	tsv.te.StopDraining()
+	tsv.setDraining(false)
+}
+
+func (tsv *TabletServer) setDraining(val bool) {
+	tsv.sm.mu.Lock()
+	tsv.sm.draining = val
+	tsv.sm.mu.Unlock()
+	tsv.BroadcastHealth()
+}
+
 // IsDiskStalled returns if the disk is stalled or not.
 func (tsv *TabletServer) IsDiskStalled() bool {
 	return tsv.sm.diskHealthMonitor.IsDiskStalled()
@@ -1093,7 +1140,7 @@ func (tsv *TabletServer) BeginStreamExecute(
 
 func (tsv *TabletServer) beginWaitForSameRangeTransactions(ctx context.Context, target *querypb.Target, options *querypb.ExecuteOptions, sql string, bindVariables map[string]*querypb.BindVariable) (txserializer.DoneFunc, error) {
 	// Serialize the creation of new transactions *if* the first
-	// UPDATE or DELETE query has the same WHERE clause as a query which is
+	// UPDATE, DELETE or upsert query has the same WHERE clause as a query which is
 	// already running in a transaction (only other BeginExecute() calls are
 	// considered). This avoids exhausting all txpool slots due to a hot row.
 	//
@@ -1145,9 +1192,10 @@ func (tsv *TabletServer) computeTxSerializerKey(ctx context.Context, logStats *t
 	}
 
 	switch plan.PlanID {
-	// Serialize only UPDATE or DELETE queries.
+	// Serialize only UPDATE, DELETE or single-row upsert queries.
 	case planbuilder.PlanUpdate, planbuilder.PlanUpdateLimit,
-		planbuilder.PlanDelete, planbuilder.PlanDeleteLimit:
+		planbuilder.PlanDelete, planbuilder.PlanDeleteLimit,
+		planbuilder.PlanInsert:
 	default:
 		return "", ""
 	}
@@ -195,6 +195,43 @@ func TestTabletServerPrimaryToReplica(t *testing.T) {
 	<-ch
 }
 
+func TestTabletServerDrainTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+	isDraining := func() bool {
+		tsv.hs.fieldsMu.Lock()
+		defer tsv.hs.fieldsMu.Unlock()
+		return tsv.hs.state.RealtimeStats.Draining
+	}
+
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	state, err := tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+
+	txs, err := tsv.DrainTransactions(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, state.TransactionID, txs[0].TransactionId)
+	assert.Empty(t, txs[0].Outcome)
+	assert.True(t, isDraining())
+
+	_, err = tsv.Begin(ctx, &target, nil)
+	require.ErrorContains(t, err, vterrors.PrimaryDraining)
+	assert.Equal(t, vtrpcpb.Code_CLUSTER_EVENT, vterrors.Code(err))
+
+	_, err = tsv.Rollback(ctx, &target, state.TransactionID)
+	require.NoError(t, err)
+	tsv.StopDraining()
+	assert.False(t, isDraining())
+	state, err = tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	_, err = tsv.Rollback(ctx, &target, state.TransactionID)
+	require.NoError(t, err)
+}
+
 func TestTabletServerRedoLogIsKeptBetweenRestarts(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1165,6 +1202,47 @@ func TestDMLQueryWithoutWhereClause(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestComputeTxSerializerKeyUpsert(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.HotRowProtection.Mode = tabletenv.Enable
+	db, tsv := setupTabletServerTestCustom(t, ctx, cfg, "", vtenv.NewTestEnv())
+	defer tsv.StopService()
+	defer db.Close()
+
+	bv := map[string]*querypb.BindVariable{
+		"pk":  sqltypes.Int64BindVariable(1),
+		"cnt": sqltypes.Int64BindVariable(1),
+	}
+	logStats := tabletenv.NewLogStats(ctx, "TestComputeTxSerializerKeyUpsert", streamlog.NewQueryLogConfigForTest())
+
+	testcases := []struct {
+		query string
+		key   string
+	}{{
+		query: "update test_table set cnt = cnt + :cnt where pk = :pk",
+		key:   "test_table where pk = 1",
+	}, {
+		// An upsert of the same row is serialized with the update.
+		query: "insert into test_table(pk, cnt) values (:pk, :cnt) on duplicate key update cnt = cnt + values(cnt)",
+		key:   "test_table where pk = 1",
+	}, {
+		query: "insert into test_table(pk, cnt) values (:pk, :cnt)",
+	}, {
+		query: "insert into test_table(pk, cnt) values (:pk, :cnt), (2, 1) on duplicate key update cnt = cnt + values(cnt)",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.query, func(t *testing.T) {
+			key, table := tsv.computeTxSerializerKey(ctx, logStats, tc.query, bv)
+			assert.Equal(t, tc.key, key)
+			if tc.key != "" {
+				assert.Equal(t, "test_table", table)
+			}
+		})
+	}
+}
+
 func TestSerializeTransactionsSameRow_ConcurrentTransactions(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
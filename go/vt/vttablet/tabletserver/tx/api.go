@@ -55,6 +55,10 @@ type (
 		Autocommit      bool
 		Conclusion      string
 		LogToFile       bool
+		// ReadOnly is true if MySQL rejects writes in the transaction.
+		ReadOnly bool
+		// Isolation is the isolation level the transaction was started with.
+		Isolation querypb.ExecuteOptions_TransactionIsolation
 
 		Stats *servenv.TimingsWrapper
 	}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// txDrain tracks the transactions that were open when the TxEngine started
+// draining, and records how each of them ended.
+type txDrain struct {
+	mu sync.Mutex
+	// txs holds the drained transactions by transaction id.
+	txs map[tx.ConnID]*tabletmanagerdatapb.DrainedTransaction
+	// finished holds the outcome of the transactions that ended after
+	// draining started but before txs was populated.
+	finished map[tx.ConnID]string
+	tracked  bool
+	// changed is signalled every time a drained transaction ends.
+	changed chan struct{}
+}
+
+func newTxDrain() *txDrain {
+	return &txDrain{
+		txs:      make(map[tx.ConnID]*tabletmanagerdatapb.DrainedTransaction),
+		finished: make(map[tx.ConnID]string),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// track starts tracking the transactions open on the given connections.
+func (d *txDrain) track(conns []*StatefulConnection) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, conn := range conns {
+		props := conn.TxProperties()
+		if props == nil {
+			continue
+		}
+		id := conn.ReservedID()
+		if _, ok := d.txs[id]; ok {
+			continue
+		}
+		caller := callerid.GetPrincipal(props.EffectiveCaller)
+		if caller == "" {
+			caller = callerid.GetUsername(props.ImmediateCaller)
+		}
+		d.txs[id] = &tabletmanagerdatapb.DrainedTransaction{
+			TransactionId: id,
+			Caller:        caller,
+			StartTime:     protoutil.TimeToProto(props.StartTime),
+			Outcome:       d.finished[id],
+		}
+	}
+	d.tracked = true
+	d.finished = nil
+}
+
+// complete records how a transaction ended.
+func (d *txDrain) complete(id tx.ConnID, reason tx.ReleaseReason) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.tracked {
+		d.finished[id] = reason.Name()
+		return
+	}
+	t, ok := d.txs[id]
+	if !ok {
+		return
+	}
+	t.Outcome = reason.Name()
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+// pending returns the number of drained transactions that are still open.
+func (d *txDrain) pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	count := 0
+	for _, t := range d.txs {
+		if t.Outcome == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// transactions returns the drained transactions ordered by id.
+func (d *txDrain) transactions() []*tabletmanagerdatapb.DrainedTransaction {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	txs := make([]*tabletmanagerdatapb.DrainedTransaction, 0, len(d.txs))
+	for _, t := range d.txs {
+		txs = append(txs, t.CloneVT())
+	}
+	slices.SortFunc(txs, func(a, b *tabletmanagerdatapb.DrainedTransaction) int {
+		return cmp.Compare(a.TransactionId, b.TransactionId)
+	})
+	return txs
+}
+
+// Drain stops the TxEngine from opening new transactions and reserved
+// connections, then waits until the transactions that are already open end,
+// the timeout expires or ctx is done. It returns every transaction that was
+// open when draining started along with how it ended; the ones that are
+// still open have no outcome. The TxEngine keeps draining until StopDraining
+// is called or it changes state.
+func (te *TxEngine) Drain(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	te.stateLock.Lock()
+	if te.state != AcceptingReadAndWrite {
+		te.stateLock.Unlock()
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot drain transactions in state %v", te.state)
+	}
+	drain := te.drain
+	if drain == nil {
+		drain = newTxDrain()
+		te.drain = drain
+		te.txPool.drain.Store(drain)
+	}
+	te.stateLock.Unlock()
+
+	// Begin requests admitted before draining started may still be opening
+	// their transactions. No new ones can be admitted from here on.
+	te.beginRequests.Wait()
+	drain.track(te.txPool.scp.openTransactions())
+
+	tmr := time.NewTimer(timeout)
+	defer tmr.Stop()
+	for drain.pending() > 0 {
+		select {
+		case <-drain.changed:
+		case <-tmr.C:
+			return drain.transactions(), nil
+		case <-ctx.Done():
+			return nil, vterrors.Wrap(ctx.Err(), "failed to wait for transactions to drain")
+		}
+	}
+	return drain.transactions(), nil
+}
+
+// StopDraining lets the TxEngine open new transactions again after Drain.
+func (te *TxEngine) StopDraining() {
+	te.stateLock.Lock()
+	defer te.stateLock.Unlock()
+	te.stopDrainingLocked()
+}
+
+func (te *TxEngine) stopDrainingLocked() {
+	te.drain = nil
+	te.txPool.drain.Store(nil)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestTxEngineDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.DB = newDBConfigs(db)
+	cfg.GracePeriods.Shutdown = 0
+	te := NewTxEngine(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"), nil)
+
+	_, err := te.Drain(ctx, time.Second)
+	require.ErrorContains(t, err, "cannot drain transactions in state NotServing")
+
+	te.AcceptReadWrite()
+	defer te.Close()
+
+	begin := func() (int64, error) {
+		txID, _, _, err := te.Begin(ctx, 0, nil, &querypb.ExecuteOptions{})
+		return txID, err
+	}
+	tx1, err := begin()
+	require.NoError(t, err)
+	tx2, err := begin()
+	require.NoError(t, err)
+	tx3, err := begin()
+	require.NoError(t, err)
+
+	type drainResult struct {
+		txs []int64
+		out map[int64]string
+		err error
+	}
+	drain := func(timeout time.Duration) chan drainResult {
+		ch := make(chan drainResult, 1)
+		go func() {
+			txs, err := te.Drain(ctx, timeout)
+			res := drainResult{out: make(map[int64]string), err: err}
+			for _, t := range txs {
+				res.txs = append(res.txs, t.TransactionId)
+				res.out[t.TransactionId] = t.Outcome
+			}
+			ch <- res
+		}()
+		return ch
+	}
+	isDraining := func() bool {
+		te.stateLock.Lock()
+		defer te.stateLock.Unlock()
+		return te.drain != nil
+	}
+
+	// The drain times out with tx3 still open.
+	ch := drain(200 * time.Millisecond)
+	require.Eventually(t, isDraining, 5*time.Second, time.Millisecond)
+	_, _, err = te.Commit(ctx, tx1)
+	require.NoError(t, err)
+	_, err = te.Rollback(ctx, tx2)
+	require.NoError(t, err)
+	res := <-ch
+	require.NoError(t, res.err)
+	assert.Equal(t, []int64{tx1, tx2, tx3}, res.txs)
+	assert.Equal(t, map[int64]string{tx1: "commit", tx2: "rollback", tx3: ""}, res.out)
+
+	// New transactions and reserved connections are rejected while draining.
+	_, err = begin()
+	require.ErrorContains(t, err, vterrors.PrimaryDraining)
+	assert.Equal(t, vtrpcpb.Code_CLUSTER_EVENT, vterrors.Code(err))
+	_, _, err = te.ReserveBegin(ctx, &querypb.ExecuteOptions{}, nil)
+	require.ErrorContains(t, err, vterrors.PrimaryDraining)
+
+	// Draining again returns as soon as the last transaction ends.
+	ch = drain(time.Minute)
+	_, _, err = te.Commit(ctx, tx3)
+	require.NoError(t, err)
+	select {
+	case res = <-ch:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "drain did not finish when the transactions ended")
+	}
+	require.NoError(t, res.err)
+	assert.Equal(t, map[int64]string{tx1: "commit", tx2: "rollback", tx3: "commit"}, res.out)
+
+	te.StopDraining()
+	tx4, err := begin()
+	require.NoError(t, err)
+
+	// A canceled drain returns an error and keeps draining.
+	cancelCtx, cancelDrain := context.WithCancel(ctx)
+	cancelDrain()
+	_, err = te.Drain(cancelCtx, time.Minute)
+	require.ErrorContains(t, err, "context canceled")
+	assert.True(t, isDraining())
+
+	// A state transition ends draining.
+	_, err = te.Rollback(ctx, tx4)
+	require.NoError(t, err)
+	te.AcceptReadOnly()
+	assert.False(t, isDraining())
+	_, err = begin()
+	require.NoError(t, err)
+}
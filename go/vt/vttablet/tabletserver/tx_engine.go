@@ -94,6 +94,10 @@ type TxEngine struct {
 	preparedPool *TxPreparedPool
 	twoPC        *TwoPC
 	dxNotify     func()
+
+	// drain is set while the TxEngine drains transactions before a planned
+	// reparent. It is protected by stateLock.
+	drain *txDrain
 }
 
 // TwoPC can be disallowed for various reasons. These are the reasons we keep track off
@@ -166,6 +170,7 @@ func (te *TxEngine) transition(state txEngineState) {
 	}
 
 	log.Infof("TxEngine transition: %v", state)
+	te.stopDrainingLocked()
 
 	// When we are transitioning from read write state, we should close all transactions.
 	if te.state == AcceptingReadAndWrite {
@@ -249,6 +254,7 @@ func (te *TxEngine) Close() {
 	}
 
 	log.Infof("TxEngine - starting shutdown")
+	te.stopDrainingLocked()
 	te.shutdownLocked()
 	log.Info("TxEngine: closed")
 }
@@ -261,6 +267,10 @@ func (te *TxEngine) isTxPoolAvailable(addToWaitGroup func(int)) error {
 	if !canOpenTransactions {
 		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, vterrors.TxEngineClosed, te.state)
 	}
+	if te.drain != nil {
+		// This specific error needs to be returned for vtgate buffering to work.
+		return vterrors.New(vtrpcpb.Code_CLUSTER_EVENT, vterrors.PrimaryDraining)
+	}
 	addToWaitGroup(1)
 	return nil
 }
@@ -697,6 +707,7 @@ func (te *TxEngine) taintConn(ctx context.Context, conn *StatefulConnection, pre
 	if err != nil {
 		return err
 	}
+	conn.reservedSettings = preQueries
 	for _, query := range preQueries {
 		_, err := conn.Exec(ctx, query, 0 /*maxrows*/, false /*wantFields*/)
 		if err != nil {
@@ -18,8 +18,10 @@ package tabletserver
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"vitess.io/vitess/go/pools/smartconnpool"
@@ -72,6 +74,10 @@ type (
 		logMu   sync.Mutex
 		lastLog time.Time
 		txStats *servenv.TimingsWrapper
+
+		// drain is notified of every completed transaction while the
+		// TxEngine drains.
+		drain atomic.Pointer[txDrain]
 	}
 )
 
@@ -283,9 +289,19 @@ func (tp *TxPool) begin(ctx context.Context, options *querypb.ExecuteOptions, re
 		return "", "", err
 	}
 	conn.txProps = tp.NewTxProps(immediateCaller, effectiveCaller, autocommit)
+	conn.txProps.ReadOnly = readOnly || isReadOnlyTransaction(options)
+	conn.txProps.Isolation = options.GetTransactionIsolation()
 	return beginQueries, sessionStateChanges, nil
 }
 
+// isReadOnlyTransaction returns true if options start a read only transaction.
+func isReadOnlyTransaction(options *querypb.ExecuteOptions) bool {
+	if options.GetTransactionIsolation() == querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY {
+		return true
+	}
+	return slices.Contains(options.GetTransactionAccessMode(), querypb.ExecuteOptions_READ_ONLY)
+}
+
 func (tp *TxPool) createConn(ctx context.Context, options *querypb.ExecuteOptions, setting *smartconnpool.Setting) (*StatefulConnection, error) {
 	conn, err := tp.scp.NewConn(ctx, options, setting)
 	if err != nil {
@@ -441,6 +457,9 @@ func (tp *TxPool) LogActive() {
 
 func (tp *TxPool) txComplete(conn *StatefulConnection, reason tx.ReleaseReason) {
 	conn.LogTransaction(reason)
+	if drain := tp.drain.Load(); drain != nil {
+		drain.complete(conn.ReservedID(), reason)
+	}
 	tp.limiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller)
 	conn.CleanTxState()
 }
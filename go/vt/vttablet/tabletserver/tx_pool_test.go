@@ -19,6 +19,7 @@ package tabletserver
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -781,6 +782,8 @@ func TestTxPoolBeginStatements(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
+			require.Equal(t, tc.readOnly || strings.Contains(beginSQL, "read only"), conn.TxProperties().ReadOnly)
+			require.Equal(t, tc.txIsolationLevel, conn.TxProperties().Isolation)
 			conn.Release(tx.ConnRelease)
 			require.Equal(t, tc.expBeginSQL, beginSQL)
 		})
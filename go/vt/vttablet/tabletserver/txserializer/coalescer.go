@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txserializer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Coalescer merges concurrent upserts which increment the counters of the
+// same row into one statement.
+//
+// At most one statement per row is executed at a time. Upserts which arrive
+// while a statement is in flight are collected in a pending batch, up to
+// the max queue size of the hot row protection. Once the in-flight
+// statement is done, the batch executes with the sum of all increments and
+// every upsert of the batch gets the same result.
+//
+// The batch does not belong to any of its upserts: it executes with a
+// context detached from theirs, bounded by the query timeout. An upsert
+// whose context is done before the batch executes leaves the batch and its
+// increments are not applied. Once the batch executes, the outcome of an
+// upsert leaving it is unknown, like for a statement killed in flight.
+//
+// Unlike the TxSerializer, the Coalescer only applies to autocommit
+// statements because merged upserts must not be part of different
+// transactions.
+type Coalescer struct {
+	maxQueueSize int
+	timeout      time.Duration
+
+	// coalesced counts per table how many upserts were merged into the
+	// statement of another upsert.
+	//
+	// queueExceeded counts per table how many upserts were rejected because
+	// the pending batch of their row was full.
+	coalesced, queueExceeded *stats.CountersWithSingleLabel
+
+	mu   sync.Mutex
+	rows map[string]*upsertRow
+}
+
+// upsertRow is the state of a row which has an upsert in flight.
+type upsertRow struct {
+	// idle is closed when the in-flight statement is done.
+	idle chan struct{}
+	// pending is the batch which executes next. It is nil if there is none.
+	pending *upsertBatch
+}
+
+// upsertBatch is a set of upserts which are executed as one statement.
+type upsertBatch struct {
+	increments []int64
+	size       int
+	// started is set once the batch executes. From then on, its increments
+	// and size don't change anymore.
+	started bool
+
+	// done is closed when result and err are set.
+	done   chan struct{}
+	result *sqltypes.Result
+	err    error
+}
+
+// NewCoalescer returns a Coalescer object.
+func NewCoalescer(env tabletenv.Env) *Coalescer {
+	config := env.Config()
+	return &Coalescer{
+		maxQueueSize: config.HotRowProtection.MaxQueueSize,
+		timeout:      config.Oltp.QueryTimeout,
+		coalesced: env.Exporter().NewCountersWithSingleLabel(
+			"TxSerializerUpsertsCoalesced",
+			"Number of upserts which were merged into a concurrent upsert of the same row",
+			"table_name"),
+		queueExceeded: env.Exporter().NewCountersWithSingleLabel(
+			"TxSerializerUpsertsQueueExceeded",
+			"Number of upserts which were rejected because the max queue size per row was exceeded",
+			"table_name"),
+		rows: make(map[string]*upsertRow),
+	}
+}
+
+// Do executes the upsert identified by key. All upserts with the same key
+// must only differ in their increments.
+// exec is called with the increments to apply, and the context to apply them
+// with. If the upsert was merged into the batch of other upserts, exec may
+// be the one of another upsert of the batch, which is called with a context
+// detached from ctx, and the result of the batch is returned.
+func (c *Coalescer) Do(ctx context.Context, key, table string, increments []int64, exec func(ctx context.Context, increments []int64) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	c.mu.Lock()
+	row, ok := c.rows[key]
+	if !ok {
+		// No statement in flight for this row i.e. we execute right away.
+		row = &upsertRow{idle: make(chan struct{})}
+		c.rows[key] = row
+		c.mu.Unlock()
+
+		qr, err := exec(ctx, increments)
+		c.release(key, row)
+		return qr, err
+	}
+
+	b := row.pending
+	switch {
+	case b == nil:
+		// Start a new batch and execute it once the in-flight statement is done.
+		b = &upsertBatch{
+			increments: make([]int64, len(increments)),
+			done:       make(chan struct{}),
+		}
+		row.pending = b
+		go c.execBatch(ctx, key, table, row, b, exec)
+	case b.size >= c.maxQueueSize:
+		c.mu.Unlock()
+		c.queueExceeded.Add(table, 1)
+		return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED,
+			"hot row protection: too many queued upserts (%d >= %d) for the same row of table %s", b.size, c.maxQueueSize, table)
+	}
+	b.add(increments, 1)
+	c.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.shared()
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !b.started {
+		b.add(increments, -1)
+		return nil, vterrors.Wrapf(ctx.Err(), "upsert canceled while waiting for a concurrent upsert of the same row")
+	}
+	return nil, vterrors.Wrapf(ctx.Err(), "upsert canceled while merged into a concurrent upsert of the same row, its outcome is unknown")
+}
+
+// execBatch executes b once the in-flight statement of row is done.
+func (c *Coalescer) execBatch(ctx context.Context, key, table string, row *upsertRow, b *upsertBatch, exec func(ctx context.Context, increments []int64) (*sqltypes.Result, error)) {
+	defer close(b.done)
+
+	<-row.idle
+
+	c.mu.Lock()
+	row.pending = nil
+	row.idle = make(chan struct{})
+	b.started = true
+	c.mu.Unlock()
+
+	if b.size == 0 {
+		// Every upsert left the batch.
+		c.release(key, row)
+		return
+	}
+	if b.size > 1 {
+		c.coalesced.Add(table, int64(b.size-1))
+	}
+
+	// The batch must not be canceled with the upsert which started it.
+	execCtx := context.WithoutCancel(ctx)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, c.timeout)
+		defer cancel()
+	}
+	b.result, b.err = exec(execCtx, b.increments)
+	c.release(key, row)
+}
+
+// add adds n times increments to the batch.
+func (b *upsertBatch) add(increments []int64, n int) {
+	for i, inc := range increments {
+		b.increments[i] += int64(n) * inc
+	}
+	b.size += n
+}
+
+// shared returns a copy of the result of the batch. It must only be called
+// after the batch is done.
+func (b *upsertBatch) shared() (*sqltypes.Result, error) {
+	if b.result == nil {
+		return nil, b.err
+	}
+	return b.result.ShallowCopy(), b.err
+}
+
+// release marks the in-flight statement of row as done.
+func (c *Coalescer) release(key string, row *upsertRow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	close(row.idle)
+	if row.pending == nil {
+		delete(c.rows, key)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txserializer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestCoalescer() *Coalescer {
+	c := NewCoalescer(tabletenv.NewEnv(vtenv.NewTestEnv(), tabletenv.NewDefaultConfig(), "CoalescerTest"))
+	c.coalesced.ResetAll()
+	c.queueExceeded.ResetAll()
+	return c
+}
+
+func TestCoalescerNoHotRow(t *testing.T) {
+	c := newTestCoalescer()
+
+	var got []int64
+	qr, err := c.Do(context.Background(), "t1 where1", "t1", []int64{1, 2}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+		got = increments
+		return &sqltypes.Result{RowsAffected: 1}, nil
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, qr.RowsAffected)
+	assert.Equal(t, []int64{1, 2}, got)
+	assert.Empty(t, c.rows)
+	assert.Zero(t, c.coalesced.Counts()["t1"])
+}
+
+func TestCoalescerHotRow(t *testing.T) {
+	c := newTestCoalescer()
+
+	// The first upsert blocks until it is released.
+	release := make(chan struct{})
+	firstDone := make(chan struct{})
+	go func() {
+		defer close(firstDone)
+		_, err := c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+			<-release
+			return &sqltypes.Result{RowsAffected: 1}, nil
+		})
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.rows["t1 where1"] != nil
+	}, 5*time.Second, time.Millisecond)
+
+	// All other upserts are merged into one statement.
+	var mu sync.Mutex
+	var execs [][]int64
+	var wg sync.WaitGroup
+	for _, inc := range []int64{2, 3, 4} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			qr, err := c.Do(context.Background(), "t1 where1", "t1", []int64{inc}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				execs = append(execs, increments)
+				return &sqltypes.Result{RowsAffected: 2}, nil
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, 2, qr.RowsAffected)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		b := c.rows["t1 where1"].pending
+		return b != nil && b.size == 3
+	}, 5*time.Second, time.Millisecond)
+
+	close(release)
+	<-firstDone
+	wg.Wait()
+
+	assert.Equal(t, [][]int64{{9}}, execs)
+	assert.EqualValues(t, 2, c.coalesced.Counts()["t1"])
+	assert.Empty(t, c.rows)
+}
+
+func TestCoalescerSharesError(t *testing.T) {
+	c := newTestCoalescer()
+
+	release := make(chan struct{})
+	go func() {
+		_, _ = c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+			<-release
+			return &sqltypes.Result{}, nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.rows["t1 where1"] != nil
+	}, 5*time.Second, time.Millisecond)
+
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+				return nil, errors.New("duplicate entry")
+			})
+			errs <- err
+		}()
+	}
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		b := c.rows["t1 where1"].pending
+		return b != nil && b.size == 2
+	}, 5*time.Second, time.Millisecond)
+
+	close(release)
+	for range 2 {
+		assert.EqualError(t, <-errs, "duplicate entry")
+	}
+}
+
+// blockRow starts an upsert of row "t1 where1" which is in flight until
+// release is closed.
+func blockRow(t *testing.T, c *Coalescer) (release chan struct{}) {
+	release = make(chan struct{})
+	go func() {
+		_, _ = c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+			<-release
+			return &sqltypes.Result{}, nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.rows["t1 where1"] != nil
+	}, 5*time.Second, time.Millisecond)
+	return release
+}
+
+// waitBatchSize waits until the pending batch of row "t1 where1" has size upserts.
+func waitBatchSize(t *testing.T, c *Coalescer, size int) {
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		b := c.rows["t1 where1"].pending
+		return b != nil && b.size == size
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestCoalescerCanceledUpsert(t *testing.T) {
+	c := newTestCoalescer()
+	release := blockRow(t, c)
+
+	// The first upsert of the batch executes it, but leaves it before it runs.
+	type execution struct {
+		canceled   bool
+		increments []int64
+	}
+	execs := make(chan execution, 2)
+	exec := func(ctx context.Context, increments []int64) (*sqltypes.Result, error) {
+		execs <- execution{canceled: ctx.Err() != nil, increments: increments}
+		return &sqltypes.Result{RowsAffected: 2}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := c.Do(ctx, "t1 where1", "t1", []int64{2}, exec)
+		canceled <- err
+	}()
+	waitBatchSize(t, c, 1)
+	merged := make(chan error)
+	go func() {
+		qr, err := c.Do(context.Background(), "t1 where1", "t1", []int64{3}, exec)
+		if err == nil {
+			assert.EqualValues(t, 2, qr.RowsAffected)
+		}
+		merged <- err
+	}()
+	waitBatchSize(t, c, 2)
+
+	cancel()
+	err := <-canceled
+	require.EqualError(t, err, "upsert canceled while waiting for a concurrent upsert of the same row: context canceled")
+	assert.Equal(t, vtrpcpb.Code_CANCELED, vterrors.Code(err))
+	waitBatchSize(t, c, 1)
+
+	// The batch is not canceled with the upsert which started it, and only
+	// applies the increments of the remaining upsert.
+	close(release)
+	require.NoError(t, <-merged)
+	assert.Equal(t, execution{increments: []int64{3}}, <-execs)
+	assert.Empty(t, execs)
+	assert.Zero(t, c.coalesced.Counts()["t1"])
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.rows) == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestCoalescerAllUpsertsCanceled(t *testing.T) {
+	c := newTestCoalescer()
+	release := blockRow(t, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := c.Do(ctx, "t1 where1", "t1", []int64{2}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+			assert.Fail(t, "the batch must not execute")
+			return nil, nil
+		})
+		errs <- err
+	}()
+	waitBatchSize(t, c, 1)
+	cancel()
+	require.ErrorContains(t, <-errs, "context canceled")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.rows) == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestCoalescerQueueExceeded(t *testing.T) {
+	c := newTestCoalescer()
+	c.maxQueueSize = 2
+	release := blockRow(t, c)
+
+	for range 2 {
+		go func() {
+			_, _ = c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+				return &sqltypes.Result{}, nil
+			})
+		}()
+	}
+	waitBatchSize(t, c, 2)
+
+	_, err := c.Do(context.Background(), "t1 where1", "t1", []int64{1}, func(_ context.Context, increments []int64) (*sqltypes.Result, error) {
+		assert.Fail(t, "the upsert must be rejected")
+		return nil, nil
+	})
+	require.EqualError(t, err, "hot row protection: too many queued upserts (2 >= 2) for the same row of table t1")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualValues(t, 1, c.queueExceeded.Counts()["t1"])
+
+	close(release)
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.rows) == 0
+	}, 5*time.Second, time.Millisecond)
+}
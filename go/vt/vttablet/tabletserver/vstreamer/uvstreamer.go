@@ -490,6 +490,15 @@ func (uvs *uvstreamer) getVSchema() *localVSchema {
 	return uvs.vschema
 }
 
+// schemaVersionOptions returns the options of the catchup and fastforward vstreamers,
+// which only carry over whether schema versions are included in the field events.
+func (uvs *uvstreamer) schemaVersionOptions() *binlogdatapb.VStreamOptions {
+	if !uvs.options.GetIncludeSchemaVersions() {
+		return nil
+	}
+	return &binlogdatapb.VStreamOptions{IncludeSchemaVersions: true}
+}
+
 func (uvs *uvstreamer) setCopyState(tableName string, qr *querypb.QueryResult) {
 	uvs.plans[tableName].tablePK.Lastpk = qr
 }
@@ -208,6 +208,11 @@ func (vs *vstreamer) replicate(ctx context.Context) error {
 	if err != nil {
 		return wrapError(err, vs.pos, vs.vse)
 	}
+	if vs.options.GetSchemaSnapshot() {
+		if err := vs.sendSchemaSnapshot(); err != nil {
+			return wrapError(err, vs.pos, vs.vse)
+		}
+	}
 	err = vs.parseEvents(vs.ctx, events, errs)
 	return wrapError(err, vs.pos, vs.vse)
 }
@@ -821,18 +826,86 @@ func (vs *vstreamer) buildTablePlan(id uint64, tm *mysql.TableMap) (*binlogdatap
 		Plan:     plan,
 		TableMap: tm,
 	}
+	fieldEvent := &binlogdatapb.FieldEvent{
+		TableName: plan.Table.Name,
+		Fields:    plan.fields(),
+		Keyspace:  vs.vse.keyspace,
+		Shard:     vs.vse.shard,
+		// This mapping will be done, if needed, in the vstreamer when we process
+		// and build ROW events.
+		EnumSetStringValues: len(plan.EnumSetValuesMap) > 0,
+	}
+	if err := setSchemaVersion(vs.se, vs.options, fieldEvent, replication.EncodePosition(vs.pos)); err != nil {
+		return nil, err
+	}
 	return &binlogdatapb.VEvent{
-		Type: binlogdatapb.VEventType_FIELD,
-		FieldEvent: &binlogdatapb.FieldEvent{
+		Type:       binlogdatapb.VEventType_FIELD,
+		FieldEvent: fieldEvent,
+	}, nil
+}
+
+// sendSchemaSnapshot sends a FIELD event for every table matching the filter in the
+// schema at the start position, wrapped in a BEGIN and a COMMIT. The plans are not
+// cached: the FIELD event of a table is sent again before its first ROW event.
+func (vs *vstreamer) sendSchemaSnapshot() error {
+	gtid := replication.EncodePosition(vs.pos)
+	tables, err := vs.se.GetTablesForPos(gtid)
+	if err != nil {
+		return err
+	}
+	vevents := []*binlogdatapb.VEvent{{
+		Type:     binlogdatapb.VEventType_BEGIN,
+		Keyspace: vs.vse.keyspace,
+		Shard:    vs.vse.shard,
+	}}
+	for _, table := range tables {
+		if vtschema.IsInternalOperationTableName(table.Name) {
+			continue
+		}
+		plan, err := buildPlan(vs.se.Environment(), &Table{Name: table.Name, Fields: table.Fields}, vs.vschema, vs.filter)
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			continue
+		}
+		fieldEvent := &binlogdatapb.FieldEvent{
 			TableName: plan.Table.Name,
 			Fields:    plan.fields(),
 			Keyspace:  vs.vse.keyspace,
 			Shard:     vs.vse.shard,
-			// This mapping will be done, if needed, in the vstreamer when we process
-			// and build ROW events.
-			EnumSetStringValues: len(plan.EnumSetValuesMap) > 0,
-		},
-	}, nil
+		}
+		if err := setSchemaVersion(vs.se, vs.options, fieldEvent, gtid); err != nil {
+			return err
+		}
+		vevents = append(vevents, &binlogdatapb.VEvent{
+			Type:       binlogdatapb.VEventType_FIELD,
+			FieldEvent: fieldEvent,
+			Keyspace:   vs.vse.keyspace,
+			Shard:      vs.vse.shard,
+		})
+	}
+	vevents = append(vevents, &binlogdatapb.VEvent{
+		Type:     binlogdatapb.VEventType_COMMIT,
+		Keyspace: vs.vse.keyspace,
+		Shard:    vs.vse.shard,
+	})
+	return vs.send(vevents)
+}
+
+// setSchemaVersion sets the schema version of the field event at gtid and the definition
+// of its table, if the options ask for them.
+func setSchemaVersion(se *schema.Engine, options *binlogdatapb.VStreamOptions, fieldEvent *binlogdatapb.FieldEvent, gtid string) error {
+	if !options.GetIncludeSchemaVersions() {
+		return nil
+	}
+	version, definition, err := se.GetTableDefinitionForPos(sqlparser.NewIdentifierCS(fieldEvent.TableName), gtid)
+	if err != nil {
+		return err
+	}
+	fieldEvent.SchemaVersion = version
+	fieldEvent.TableDefinition = definition
+	return nil
 }
 
 func (vs *vstreamer) buildTableColumns(tm *mysql.TableMap) ([]*querypb.Field, error) {
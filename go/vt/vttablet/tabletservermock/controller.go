@@ -285,6 +285,17 @@ func (tqsc *Controller) IsDiskStalled() bool {
 	return false
 }
 
+// DrainTransactions is part of the tabletserver.Controller interface
+func (tqsc *Controller) DrainTransactions(context.Context, time.Duration) ([]*tabletmanagerdata.DrainedTransaction, error) {
+	tqsc.MethodCalled["DrainTransactions"] = true
+	return nil, nil
+}
+
+// StopDraining is part of the tabletserver.Controller interface
+func (tqsc *Controller) StopDraining() {
+	tqsc.MethodCalled["StopDraining"] = true
+}
+
 // EnterLameduck implements tabletserver.Controller.
 func (tqsc *Controller) EnterLameduck() {
 	tqsc.mu.Lock()
@@ -248,6 +248,15 @@ type TabletManagerClient interface {
 	// reparent_journal table.
 	InitReplica(ctx context.Context, tablet *topodatapb.Tablet, parent *topodatapb.TabletAlias, replicationPosition string, timeCreatedNS int64, semiSync bool) error
 
+	// DrainPrimary tells the primary to stop starting new transactions, and
+	// waits up to timeout for the open ones to finish. It returns the
+	// transactions that were open, and how each of them ended.
+	DrainPrimary(ctx context.Context, tablet *topodatapb.Tablet, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error)
+
+	// UndoDrainPrimary lets a primary start new transactions again after
+	// DrainPrimary, if it is not going to be demoted after all.
+	UndoDrainPrimary(ctx context.Context, tablet *topodatapb.Tablet) error
+
 	// DemotePrimary tells the soon-to-be-former primary it's going to change,
 	// and it should go read-only and return its current position.
 	DemotePrimary(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.PrimaryStatus, error)
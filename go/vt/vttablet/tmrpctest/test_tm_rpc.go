@@ -1246,6 +1246,52 @@ func tmRPCTestInitReplicaPanic(ctx context.Context, t *testing.T, client tmclien
 	expectHandleRPCPanic(t, "InitReplica", true /*verbose*/, err)
 }
 
+var testDrainTimeout = 10 * time.Second
+var testDrainedTransactions = []*tabletmanagerdatapb.DrainedTransaction{{
+	TransactionId: 1,
+	Caller:        "user",
+	StartTime:     protoutil.TimeToProto(time.Unix(1700000000, 0)),
+	Outcome:       "commit",
+}}
+
+func (fra *fakeRPCTM) DrainPrimary(ctx context.Context, timeout time.Duration) ([]*tabletmanagerdatapb.DrainedTransaction, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "DrainPrimary timeout", timeout, testDrainTimeout)
+	return testDrainedTransactions, nil
+}
+
+func tmRPCTestDrainPrimary(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	txs, err := client.DrainPrimary(ctx, tablet, testDrainTimeout)
+	compareError(t, "DrainPrimary", err, txs, testDrainedTransactions)
+}
+
+func tmRPCTestDrainPrimaryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.DrainPrimary(ctx, tablet, testDrainTimeout)
+	expectHandleRPCPanic(t, "DrainPrimary", true /*verbose*/, err)
+}
+
+var testUndoDrainPrimaryCalled = false
+
+func (fra *fakeRPCTM) UndoDrainPrimary(ctx context.Context) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	testUndoDrainPrimaryCalled = true
+	return nil
+}
+
+func tmRPCTestUndoDrainPrimary(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.UndoDrainPrimary(ctx, tablet)
+	compareError(t, "UndoDrainPrimary", err, true, testUndoDrainPrimaryCalled)
+}
+
+func tmRPCTestUndoDrainPrimaryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.UndoDrainPrimary(ctx, tablet)
+	expectHandleRPCPanic(t, "UndoDrainPrimary", true /*verbose*/, err)
+}
+
 func (fra *fakeRPCTM) DemotePrimary(ctx context.Context) (*replicationdatapb.PrimaryStatus, error) {
 	if fra.panics {
 		panic(fmt.Errorf("test-triggered panic"))
@@ -1592,6 +1638,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestInitPrimary(ctx, t, client, tablet)
 	tmRPCTestPopulateReparentJournal(ctx, t, client, tablet)
 	tmRPCTestReadReparentJournalInfo(ctx, t, client, tablet)
+	tmRPCTestDrainPrimary(ctx, t, client, tablet)
+	tmRPCTestUndoDrainPrimary(ctx, t, client, tablet)
 	tmRPCTestDemotePrimary(ctx, t, client, tablet)
 	tmRPCTestUndoDemotePrimary(ctx, t, client, tablet)
 	tmRPCTestSetReplicationSource(ctx, t, client, tablet)
@@ -1654,6 +1702,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestPopulateReparentJournalPanic(ctx, t, client, tablet)
 	tmRPCTestReadReparentJournalInfoPanic(ctx, t, client, tablet)
 	tmRPCTestWaitForPositionPanic(ctx, t, client, tablet)
+	tmRPCTestDrainPrimaryPanic(ctx, t, client, tablet)
+	tmRPCTestUndoDrainPrimaryPanic(ctx, t, client, tablet)
 	tmRPCTestDemotePrimaryPanic(ctx, t, client, tablet)
 	tmRPCTestUndoDemotePrimaryPanic(ctx, t, client, tablet)
 	tmRPCTestSetReplicationSourcePanic(ctx, t, client, tablet)
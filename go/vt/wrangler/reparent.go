@@ -127,12 +127,12 @@ func (wr *Wrangler) TabletExternallyReparented(ctx context.Context, newPrimaryAl
 	if tablet.Type != topodatapb.TabletType_PRIMARY {
 		log.Infof("TabletExternallyReparented: executing tablet type change to PRIMARY")
 
-		durabilityName, err := wr.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+		durabilityName, durabilityConfig, err := wr.ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
 		if err != nil {
 			return err
 		}
 		log.Infof("Getting a new durability policy for %v", durabilityName)
-		durability, err := policy.GetDurabilityPolicy(durabilityName)
+		durability, err := policy.GetDurabilityPolicyWithConfig(durabilityName, durabilityConfig)
 		if err != nil {
 			return err
 		}